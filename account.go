@@ -0,0 +1,136 @@
+package p2pkh
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const ErrAccountLevel Error = "wallet path has no account level"
+
+// Account is a BIP44 account of a wallet, at m/purpose'/coin'/account',
+// whose external chain holds the receive addresses and internal chain the
+// change addresses. Its Receive and Change methods produce the single-key
+// wallets of the account. An Account is immutable.
+type Account struct {
+	wallet *Wallet
+	index  uint32
+}
+
+// NewAccount returns the account of a wallet whose path holds at least the
+// purpose, coin type and account levels, e.g. m/44'/0'/0' for a wallet at
+// m/44'/0'/0'/0/5.
+func NewAccount(wallet *Wallet) (*Account, error) {
+	levels := wallet.levels
+	if len(levels) < accountLevels || wallet.root == nil {
+		return nil, ErrAccountLevel
+	}
+	index, _ := wallet.Account()
+	account, err := wallet.rootWallet("m" + formatPathLevels(levels[:accountLevels]))
+	if err != nil {
+		return nil, err
+	}
+	return &Account{wallet: account, index: index}, nil
+}
+
+// AccountAt returns another account of the wallet, e.g. the account at
+// m/44'/0'/3' for index 3 of a wallet at m/44'/0'/0'/0. Only wallets holding
+// their master private key derive accounts other than their own.
+func (s *Wallet) AccountAt(index uint32) (*Account, error) {
+	wallet, err := s.accountWallet(index)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{wallet: wallet, index: index}, nil
+}
+
+// Index returns the account number, e.g. 3 for m/44'/0'/3'.
+func (s *Account) Index() uint32 {
+	return s.index
+}
+
+// Path returns the path of the account, e.g. m/44'/0'/3'.
+func (s *Account) Path() string {
+	return s.wallet.path
+}
+
+// Network returns the network of the account.
+func (s *Account) Network() Network {
+	return s.wallet.network
+}
+
+// Wallet returns the wallet of the account key itself.
+func (s *Account) Wallet() *Wallet {
+	return s.wallet
+}
+
+// XPub returns the extended public key of the account, from which watch-only
+// wallets derive its addresses.
+func (s *Account) XPub() (string, error) {
+	return s.wallet.ExtendedPublicKey()
+}
+
+// Receive returns the wallet of the receive address at index, e.g.
+// m/44'/0'/0'/0/5 for index 5.
+func (s *Account) Receive(index uint32) (*Wallet, error) {
+	return s.wallet.At(0, index)
+}
+
+// Change returns the wallet of the change address at index, e.g.
+// m/44'/0'/0'/1/5 for index 5.
+func (s *Account) Change(index uint32) (*Wallet, error) {
+	return s.wallet.At(1, index)
+}
+
+// NextUnused returns the wallet of the first receive address without
+// transaction history, or balance if the backend is not an
+// AddressHistoryProvider. A nil backend selects the backend of the wallet
+// the account comes from.
+func (s *Account) NextUnused(ctx context.Context, backend ChainBackend) (*Wallet, error) {
+	if backend == nil {
+		backend = s.wallet.backend
+	}
+	if backend == nil {
+		return nil, ErrNoBackend
+	}
+	for index := uint32(0); ; index++ {
+		wallet, err := s.Receive(index)
+		if err != nil {
+			return nil, err
+		}
+		_, used, err := addressUsage(ctx, backend, wallet.AddressInfo().String())
+		if err != nil {
+			return nil, err
+		}
+		if !used {
+			return wallet, nil
+		}
+	}
+}
+
+// accountWallet returns the wallet at the level of an account of the wallet
+// purpose and coin type.
+func (s *Wallet) accountWallet(account uint32) (*Wallet, error) {
+	if account >= hdkeychain.HardenedKeyStart {
+		return nil, ErrIndexRange
+	}
+	if own, ok := s.Account(); (!ok || own != account) && !s.privateAccounts() {
+		return nil, ErrAccountPrivate
+	}
+	purpose, ok := s.Purpose()
+	coinType, hasCoinType := s.CoinType()
+	if !ok || !hasCoinType {
+		return nil, ErrAccountPath
+	}
+	if s.root == nil {
+		return nil, ErrInvalidPath
+	}
+	return s.rootWallet(fmt.Sprintf("m/%d'/%d'/%d'", purpose, coinType, account))
+}
+
+// privateAccounts reports whether the wallet can derive the hardened keys of
+// its other accounts.
+func (s *Wallet) privateAccounts() bool {
+	return s.root != nil && s.root.IsPrivate() && s.origin == nil
+}
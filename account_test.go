@@ -0,0 +1,100 @@
+package p2pkh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewAccount(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(5)
+	assert.NoError(t, err)
+	account, err := NewAccount(child)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), account.Index())
+	assert.Equal(t, `m/44'/0'/0'`, account.Path())
+	assert.Equal(t, NetworkMainnet, account.Network())
+
+	receive, err := account.Receive(5)
+	assert.NoError(t, err)
+	assert.Equal(t, child.Path(), receive.Path())
+	assert.Equal(t, child.AddressHex(), receive.AddressHex())
+	change, err := account.Change(2)
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/1/2`, change.Path())
+
+	xpub, err := account.XPub()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	watchChild, err := watch.DerivePath("0/5")
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressHex(), watchChild.AddressHex())
+
+	_, err = NewAccount(watch)
+	assert.ErrorIs(t, err, ErrAccountLevel)
+	purposeOnly, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	_, err = NewAccount(purposeOnly)
+	assert.ErrorIs(t, err, ErrAccountLevel)
+}
+
+func Test_Wallet_AccountAt(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	account, err := wallet.AccountAt(3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), account.Index())
+	assert.Equal(t, `m/44'/0'/3'`, account.Path())
+	receive, err := account.Receive(0)
+	assert.NoError(t, err)
+	expected, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/3'/0/0`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, expected.AddressHex(), receive.AddressHex())
+	assert.Equal(t, account.Wallet().Path(), account.Path())
+
+	// A watch-only wallet only has its own account.
+	descs, err := wallet.descriptors()
+	assert.NoError(t, err)
+	watch, err := (&CoreDescriptor{Desc: descs[0]}).Wallet()
+	assert.NoError(t, err)
+	own, err := watch.AccountAt(0)
+	assert.NoError(t, err)
+	ownReceive, err := own.Receive(0)
+	assert.NoError(t, err)
+	first, err := wallet.At(0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, first.AddressHex(), ownReceive.AddressHex())
+	_, err = watch.AccountAt(1)
+	assert.ErrorIs(t, err, ErrAccountPrivate)
+	_, err = wallet.AccountAt(1 << 31)
+	assert.ErrorIs(t, err, ErrIndexRange)
+}
+
+func Test_Account_NextUnused(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	account, err := NewAccount(wallet)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = account.NextUnused(ctx, nil)
+	assert.ErrorIs(t, err, ErrNoBackend)
+
+	backend := newFakeBackend()
+	for index := uint32(0); index < 2; index++ {
+		used, err := account.Receive(index)
+		assert.NoError(t, err)
+		backend.addHistory(used.AddressInfo().String(), "spent")
+	}
+	next, err := account.NextUnused(ctx, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/2`, next.Path())
+
+	// The backend of the wallet is used by default.
+	account, err = NewAccount(wallet.WithBackend(backend))
+	assert.NoError(t, err)
+	next, err = account.NextUnused(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/2`, next.Path())
+}
@@ -0,0 +1,169 @@
+package p2pkh
+
+import (
+	"context"
+)
+
+const (
+	ErrAccountPath    Error = "wallet path has no purpose and coin type levels"
+	ErrAccountGap     Error = "gap limit and maximum accounts cannot be negative"
+	ErrAccountPrivate Error = "other accounts require the private key of the wallet"
+
+	defaultMaxAccounts = 100
+)
+
+// AccountConfig configures an AccountManager. Zero values select defaults.
+type AccountConfig struct {
+	// GapLimit is the number of consecutive unused addresses ending the scan
+	// of a chain, 20 by default.
+	GapLimit int
+	// MaxAccounts bounds the account discovery, 100 by default.
+	MaxAccounts int
+}
+
+// AccountManager discovers the accounts of a wallet, following the BIP44
+// account discovery: accounts are scanned in order until one has no used
+// address. The wallet purpose and coin type levels select the accounts, e.g.
+// m/44'/0'/N' for a wallet at m/44'/0'/0'/0. A watch-only wallet only
+// manages its own account.
+type AccountManager struct {
+	wallet  *Wallet
+	backend BalanceProvider
+	config  AccountConfig
+}
+
+// NewAccountManager creates an AccountManager querying the given backend.
+// Addresses with a balance are used; when the backend also implements
+// AddressHistoryProvider, so are emptied addresses.
+func NewAccountManager(wallet *Wallet, backend BalanceProvider, config *AccountConfig) (*AccountManager, error) {
+	cfg := AccountConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.GapLimit < 0 || cfg.MaxAccounts < 0 {
+		return nil, ErrAccountGap
+	}
+	if cfg.GapLimit == 0 {
+		cfg.GapLimit = defaultGapLimit
+	}
+	if cfg.MaxAccounts == 0 {
+		cfg.MaxAccounts = defaultMaxAccounts
+	}
+	if _, ok := wallet.CoinType(); !ok {
+		return nil, ErrAccountPath
+	}
+	return &AccountManager{wallet: wallet, backend: backend, config: cfg}, nil
+}
+
+// Account returns the wallet of an account, e.g. at m/44'/0'/3' for account 3.
+func (s *AccountManager) Account(account uint32) (*Wallet, error) {
+	return s.wallet.accountWallet(account)
+}
+
+// private reports whether the manager can derive the hardened account keys.
+func (s *AccountManager) private() bool {
+	return s.wallet.privateAccounts()
+}
+
+// AccountBalance is the balance of an account, by chain.
+type AccountBalance struct {
+	Account  uint32
+	External Balance
+	Internal Balance
+	// Used is the number of used addresses of both chains.
+	Used int
+}
+
+// Total returns the balance of both chains.
+func (s AccountBalance) Total() Balance {
+	total := s.External
+	total.add(s.Internal)
+	return total
+}
+
+// PortfolioBalance is the balance of every discovered account.
+type PortfolioBalance struct {
+	Balance
+	Accounts []AccountBalance
+}
+
+// TotalBalance discovers the accounts of the wallet and sums the balances of
+// both chains of each, with a per-account breakdown. Account 0 is always
+// reported; the first unused account after it ends the discovery.
+func (s *AccountManager) TotalBalance(ctx context.Context) (*PortfolioBalance, error) {
+	accounts := []uint32{}
+	if s.private() {
+		for account := uint32(0); account < uint32(s.config.MaxAccounts); account++ {
+			accounts = append(accounts, account)
+		}
+	} else {
+		account, ok := s.wallet.Account()
+		if !ok {
+			return nil, ErrAccountPrivate
+		}
+		accounts = append(accounts, account)
+	}
+
+	portfolio := &PortfolioBalance{}
+	for _, account := range accounts {
+		balance, err := s.accountBalance(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Used == 0 && len(portfolio.Accounts) > 0 {
+			break
+		}
+		portfolio.Accounts = append(portfolio.Accounts, *balance)
+		portfolio.add(balance.Total())
+	}
+	return portfolio, nil
+}
+
+// accountBalance scans both chains of an account up to the gap limit.
+func (s *AccountManager) accountBalance(ctx context.Context, account uint32) (*AccountBalance, error) {
+	wallet, err := s.Account(account)
+	if err != nil {
+		return nil, err
+	}
+	balance := &AccountBalance{Account: account}
+	for chain, total := range []*Balance{&balance.External, &balance.Internal} {
+		for index, gap := uint32(0), 0; gap < s.config.GapLimit; index++ {
+			address, err := wallet.At(uint32(chain), index)
+			if err != nil {
+				return nil, err
+			}
+			addressBalance, used, err := s.addressBalance(ctx, address.AddressInfo().String())
+			if err != nil {
+				return nil, err
+			}
+			if !used {
+				gap++
+				continue
+			}
+			gap = 0
+			balance.Used++
+			total.add(*addressBalance)
+		}
+	}
+	return balance, nil
+}
+
+// addressBalance returns the balance of an address and whether it is used.
+func (s *AccountManager) addressBalance(ctx context.Context, address string) (*Balance, bool, error) {
+	balance, err := s.backend.AddressBalance(ctx, address)
+	if err != nil {
+		return nil, false, err
+	}
+	if balance.Confirmed != 0 || balance.Unconfirmed != 0 {
+		return balance, true, nil
+	}
+	history, ok := s.backend.(AddressHistoryProvider)
+	if !ok {
+		return balance, false, nil
+	}
+	txids, err := history.AddressTxIDs(ctx, address)
+	if err != nil {
+		return nil, false, err
+	}
+	return balance, len(txids) > 0, nil
+}
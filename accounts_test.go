@@ -0,0 +1,78 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// accountAddress returns the address at chain/index of an account.
+func accountAddress(t *testing.T, manager *AccountManager, account, chain, index uint32) string {
+	wallet, err := manager.Account(account)
+	assert.NoError(t, err)
+	address, err := wallet.At(chain, index)
+	assert.NoError(t, err)
+	return address.AddressInfo().String()
+}
+
+func Test_AccountManager_TotalBalance(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	backend := newFakeBackend()
+	manager, err := NewAccountManager(wallet, backend, &AccountConfig{GapLimit: 5})
+	assert.NoError(t, err)
+
+	backend.setBalance(accountAddress(t, manager, 0, 0, 0), 1000, 0)
+	backend.setBalance(accountAddress(t, manager, 0, 0, 4), 500, 200)
+	backend.setBalance(accountAddress(t, manager, 0, 1, 2), 300, -100)
+	// Emptied addresses are used, and extend the scan.
+	backend.addHistory(accountAddress(t, manager, 1, 0, 3), "spent")
+	backend.setBalance(accountAddress(t, manager, 1, 0, 7), 50, 0)
+	// Beyond the gap limit, or after an unused account.
+	backend.setBalance(accountAddress(t, manager, 1, 0, 13), 1, 0)
+	backend.setBalance(accountAddress(t, manager, 3, 0, 0), 1, 0)
+
+	portfolio, err := manager.TotalBalance(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Balance{Confirmed: 1850, Unconfirmed: 100}, portfolio.Balance)
+	assert.Equal(t, []AccountBalance{
+		{Account: 0, External: Balance{1500, 200}, Internal: Balance{300, -100}, Used: 3},
+		{Account: 1, External: Balance{50, 0}, Used: 2},
+	}, portfolio.Accounts)
+	assert.Equal(t, Balance{1800, 100}, portfolio.Accounts[0].Total())
+
+	backend.err = errors.New("backend down")
+	_, err = manager.TotalBalance(context.Background())
+	assert.EqualError(t, err, "backend down")
+}
+
+func Test_AccountManager_WatchOnly(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	descs, err := wallet.descriptors()
+	assert.NoError(t, err)
+	watch, err := (&CoreDescriptor{Desc: descs[0]}).Wallet()
+	assert.NoError(t, err)
+
+	backend := newFakeBackend()
+	manager, err := NewAccountManager(watch, backend, nil)
+	assert.NoError(t, err)
+	change, err := wallet.At(1, 0)
+	assert.NoError(t, err)
+	backend.setBalance(change.AddressInfo().String(), 700, 0)
+
+	portfolio, err := manager.TotalBalance(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(700), portfolio.Total())
+	assert.Len(t, portfolio.Accounts, 1)
+
+	_, err = manager.Account(1)
+	assert.ErrorIs(t, err, ErrAccountPrivate)
+
+	short, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	_, err = NewAccountManager(short, backend, nil)
+	assert.ErrorIs(t, err, ErrAccountPath)
+	_, err = NewAccountManager(wallet, backend, &AccountConfig{GapLimit: -1})
+	assert.ErrorIs(t, err, ErrAccountGap)
+}
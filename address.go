@@ -0,0 +1,272 @@
+package p2pkh
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	ErrAddressMalformed      Error = "malformed address"
+	ErrAddressChecksum       Error = "invalid address checksum"
+	ErrAddressPrefix         Error = "unknown address prefix"
+	ErrAddressNetwork        Error = "address does not belong to the network"
+	ErrAddressWitnessVersion Error = "unsupported witness version"
+)
+
+// AddressErrorKind is the category of an address decoding failure.
+type AddressErrorKind int
+
+const (
+	AddressErrorMalformed AddressErrorKind = iota
+	AddressErrorChecksum
+	AddressErrorPrefix
+	AddressErrorNetwork
+	AddressErrorWitnessVersion
+)
+
+// String returns a stable identifier of the kind, e.g. to look up a
+// translated message.
+func (s AddressErrorKind) String() string {
+	switch s {
+	case AddressErrorChecksum:
+		return "checksum"
+	case AddressErrorPrefix:
+		return "prefix"
+	case AddressErrorNetwork:
+		return "network"
+	case AddressErrorWitnessVersion:
+		return "witness-version"
+	}
+	return "malformed"
+}
+
+// AddressError is returned when an address cannot be decoded. Kind classifies
+// the failure; Err is the underlying error.
+type AddressError struct {
+	Kind    AddressErrorKind
+	Address string
+	Err     error
+}
+
+// Error returns the message of the underlying error.
+func (s *AddressError) Error() string {
+	return s.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (s *AddressError) Unwrap() error {
+	return s.Err
+}
+
+// addressError returns an *AddressError of an underlying error.
+func addressError(kind AddressErrorKind, address string, err error) *AddressError {
+	return &AddressError{Kind: kind, Address: address, Err: err}
+}
+
+// Address is an encoded Bitcoin address along with the script type it pays
+// to and the network it belongs to.
+type Address struct {
+	encoded  string
+	addrType AddressType
+	network  Network
+}
+
+// ParseAddress decodes an address of the network. Failures are reported as
+// an *AddressError classifying them.
+func ParseAddress(address string, network Network) (Address, error) {
+	parsed, _, err := parseAddressScript(address, network)
+	return parsed, err
+}
+
+// ClassifyAddress decodes an address of the network like ParseAddress, and
+// returns the type of script it pays to (P2PKH, P2SH, P2WPKH, P2WSH, P2TR or
+// a registered scheme) along with the scriptPubKey of the outputs paying to
+// it, e.g. to build outputs to arbitrary customer addresses.
+func ClassifyAddress(address string, network Network) (AddressType, []byte, error) {
+	parsed, script, err := parseAddressScript(address, network)
+	if err != nil {
+		return "", nil, err
+	}
+	return parsed.Type(), script, nil
+}
+
+// parseAddressScript decodes an address of the network and returns the
+// scriptPubKey paying to it.
+func parseAddressScript(address string, network Network) (Address, []byte, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return Address{}, nil, err
+	}
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		return Address{}, nil, err
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return Address{}, nil, err
+	}
+	addrType, err := ScriptAddressType(script)
+	if err != nil {
+		return Address{}, nil, &AddressError{Kind: AddressErrorWitnessVersion, Address: address, Err: err}
+	}
+	return Address{encoded: addr.EncodeAddress(), addrType: addrType, network: network}, script, nil
+}
+
+// String returns the encoded address, base58 or bech32.
+func (s Address) String() string {
+	return s.encoded
+}
+
+// Type returns the script type the address pays to.
+func (s Address) Type() AddressType {
+	return s.addrType
+}
+
+// Network returns the network of the address.
+func (s Address) Network() Network {
+	return s.network
+}
+
+// AddressInfo returns the address of the wallet as an Address, of the type
+// its path purpose selects, see AddressType: the P2PKH address of a BIP44
+// wallet, the native SegWit address of a BIP84 one. The backends, the
+// change and the discovery of the wallet all use this address.
+func (s *Wallet) AddressInfo() Address {
+	if addrType := s.AddressType(); addrType != AddressTypeP2PKH {
+		return s.builtinAddress(addrType)
+	}
+	return Address{encoded: s.address.EncodeAddress(), addrType: AddressTypeP2PKH, network: s.network}
+}
+
+// AddressOfType returns the address of the given type paying to the wallet
+// key, such as AddressTypeP2WPKH for SegWit wallets.
+func (s *Wallet) AddressOfType(addrType AddressType) (Address, error) {
+	addr, err := publicKeyAddress(s.publicKey, addrType, s.params)
+	if err != nil {
+		return Address{}, err
+	}
+	return Address{encoded: addr.EncodeAddress(), addrType: addrType, network: s.network}, nil
+}
+
+// AddressP2WPKH returns the native SegWit address of the wallet key, bech32
+// encoded (bc1q...).
+func (s *Wallet) AddressP2WPKH() Address {
+	return s.builtinAddress(AddressTypeP2WPKH)
+}
+
+// AddressNestedSegwit returns the P2SH-P2WPKH address of the wallet key, the
+// wrapped SegWit address (3...) of BIP49 wallets.
+func (s *Wallet) AddressNestedSegwit() Address {
+	return s.builtinAddress(AddressTypeP2SH)
+}
+
+// AddressTaproot returns the BIP86 taproot address of the wallet key, a
+// bech32m (bc1p...) key-path-only P2TR address of the x-only tweaked key.
+// Wallets derive taproot keys at DerivationPath(AddressTypeP2TR, ...).
+func (s *Wallet) AddressTaproot() Address {
+	return s.builtinAddress(AddressTypeP2TR)
+}
+
+// builtinAddress returns the address of a built-in address type, which
+// cannot fail for the compressed key of a wallet.
+func (s *Wallet) builtinAddress(addrType AddressType) Address {
+	address, _ := s.AddressOfType(addrType)
+	return address
+}
+
+// knownNetParams are the networks whose addresses are told apart from
+// unknown prefixes.
+var knownNetParams = []*chaincfg.Params{
+	&chaincfg.MainNetParams,
+	&chaincfg.TestNet3Params,
+	&chaincfg.RegressionNetParams,
+	&chaincfg.SigNetParams,
+}
+
+// decodeAddress decodes an address of the network, classifying failures as
+// an *AddressError.
+func decodeAddress(address string, params *chaincfg.Params) (btcutil.Address, error) {
+	if hrp, ok := bech32Prefix(address); ok {
+		if err := decodeSegwitPrefix(address, hrp, params); err != nil {
+			return nil, err
+		}
+	} else if err := decodeBase58Prefix(address, params); err != nil {
+		return nil, err
+	}
+
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil {
+		var version btcutil.UnsupportedWitnessVerError
+		if errors.As(err, &version) {
+			return nil, &AddressError{Kind: AddressErrorWitnessVersion, Address: address, Err: err}
+		}
+		return nil, &AddressError{Kind: AddressErrorMalformed, Address: address, Err: err}
+	}
+	if !addr.IsForNet(params) {
+		return nil, addressError(AddressErrorNetwork, address, ErrAddressNetwork)
+	}
+	return addr, nil
+}
+
+// bech32Prefix returns the human-readable part of an address looking like
+// bech32: a known segwit prefix, or any string decoding as bech32.
+func bech32Prefix(address string) (string, bool) {
+	lower := strings.ToLower(address)
+	i := strings.LastIndexByte(lower, '1')
+	if i < 1 {
+		return "", false
+	}
+	if chaincfg.IsBech32SegwitPrefix(lower[:i+1]) {
+		return lower[:i], true
+	}
+	if hrp, _, _, err := bech32.DecodeGeneric(address); err == nil {
+		return hrp, true
+	}
+	return "", false
+}
+
+// decodeSegwitPrefix checks the prefix and encoding of a bech32 address.
+func decodeSegwitPrefix(address, hrp string, params *chaincfg.Params) error {
+	if hrp != params.Bech32HRPSegwit {
+		for _, net := range knownNetParams {
+			if hrp == net.Bech32HRPSegwit {
+				return addressError(AddressErrorNetwork, address, ErrAddressNetwork)
+			}
+		}
+		return addressError(AddressErrorPrefix, address, ErrAddressPrefix)
+	}
+	return checkSegwitAddress(address, params)
+}
+
+// decodeBase58Prefix checks the checksum and version byte of a base58 address.
+func decodeBase58Prefix(address string, params *chaincfg.Params) error {
+	payload, version, err := base58.CheckDecode(address)
+	switch {
+	case errors.Is(err, base58.ErrChecksum):
+		return addressError(AddressErrorChecksum, address, ErrAddressChecksum)
+	case err != nil:
+		return &AddressError{Kind: AddressErrorMalformed, Address: address, Err: err}
+	case len(payload) != 20:
+		return addressError(AddressErrorMalformed, address, ErrAddressMalformed)
+	case version == params.PubKeyHashAddrID || version == params.ScriptHashAddrID:
+		return nil
+	}
+	for _, net := range knownNetParams {
+		if version == net.PubKeyHashAddrID || version == net.ScriptHashAddrID {
+			return addressError(AddressErrorNetwork, address, ErrAddressNetwork)
+		}
+	}
+	return addressError(AddressErrorPrefix, address, ErrAddressPrefix)
+}
+
+// validateNetworkAddress checks that an address is valid and belongs to the network.
+func validateNetworkAddress(address string, params *chaincfg.Params) error {
+	_, err := decodeAddress(address, params)
+	return err
+}
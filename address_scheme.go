@@ -0,0 +1,219 @@
+package p2pkh
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	ErrAddressSchemeExists Error = "an address scheme is already registered with this type"
+	ErrAddressSchemeType   Error = "address scheme type is required"
+)
+
+// SigHashVersion is the signature hash algorithm of the inputs of a scheme.
+type SigHashVersion string
+
+const (
+	// SigHashLegacy is the original algorithm, of non-witness inputs.
+	SigHashLegacy SigHashVersion = "legacy"
+	// SigHashWitnessV0 is the BIP143 algorithm, of witness v0 inputs.
+	SigHashWitnessV0 SigHashVersion = "witness_v0"
+	// SigHashTaproot is the BIP341 algorithm, of witness v1 inputs.
+	SigHashTaproot SigHashVersion = "taproot"
+)
+
+// AddressScheme builds and recognizes the scripts and addresses of an address
+// type. New schemes, such as future witness versions, are added by registering
+// an AddressScheme, without touching the wallet or the transaction code.
+type AddressScheme interface {
+	// Type is the unique address type the scheme is registered under.
+	Type() AddressType
+	// Address returns the address paying to a single public key. Schemes
+	// without single-key form return ErrUnsupportedAddressType.
+	Address(publicKey *btcec.PublicKey, params *chaincfg.Params) (btcutil.Address, error)
+	// Script returns the scriptPubKey paying to a single public key.
+	Script(publicKey *btcec.PublicKey, params *chaincfg.Params) ([]byte, error)
+	// Match reports whether a scriptPubKey is of the scheme.
+	Match(pkScript []byte) bool
+	// InputWeight is the weight of an input spending a single-key script of
+	// the scheme, zero when the spending script is unknown.
+	InputWeight() int64
+	// OutputWeight is the weight of an output paying to the scheme.
+	OutputWeight() int64
+	// SigHash is the signature hash algorithm of the inputs of the scheme.
+	SigHash() SigHashVersion
+}
+
+var (
+	addressSchemesMu sync.RWMutex
+	addressSchemes   = map[AddressType]AddressScheme{}
+)
+
+func init() {
+	for _, scheme := range builtinAddressSchemes {
+		if err := RegisterAddressScheme(scheme); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterAddressScheme makes an address scheme available under its type.
+func RegisterAddressScheme(scheme AddressScheme) error {
+	if scheme.Type() == "" {
+		return ErrAddressSchemeType
+	}
+
+	addressSchemesMu.Lock()
+	defer addressSchemesMu.Unlock()
+
+	if _, ok := addressSchemes[scheme.Type()]; ok {
+		return ErrAddressSchemeExists
+	}
+	addressSchemes[scheme.Type()] = scheme
+	return nil
+}
+
+// LookupAddressScheme returns the scheme registered under an address type.
+func LookupAddressScheme(addrType AddressType) (AddressScheme, bool) {
+	addressSchemesMu.RLock()
+	defer addressSchemesMu.RUnlock()
+
+	scheme, ok := addressSchemes[addrType]
+	return scheme, ok
+}
+
+// AddressSchemes returns the types of the registered schemes, sorted.
+func AddressSchemes() []AddressType {
+	addressSchemesMu.RLock()
+	defer addressSchemesMu.RUnlock()
+
+	types := make([]AddressType, 0, len(addressSchemes))
+	for addrType := range addressSchemes {
+		types = append(types, addrType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// addressScheme returns the scheme of an address type, or
+// ErrUnsupportedAddressType.
+func addressScheme(addrType AddressType) (AddressScheme, error) {
+	scheme, ok := LookupAddressScheme(addrType)
+	if !ok {
+		return nil, ErrUnsupportedAddressType
+	}
+	return scheme, nil
+}
+
+// standardScheme is a built-in scheme, recognized by its txscript class.
+type standardScheme struct {
+	addrType     AddressType
+	class        txscript.ScriptClass
+	inputWeight  int64
+	outputWeight int64
+	sigHash      SigHashVersion
+	address      func(publicKey *btcec.PublicKey, params *chaincfg.Params) (btcutil.Address, error)
+}
+
+// builtinAddressSchemes are the schemes of the standard output types. Input
+// weights assume single-key scripts (P2SH is P2SH-P2WPKH) and 72 bytes
+// signatures.
+var builtinAddressSchemes = []AddressScheme{
+	&standardScheme{
+		addrType:     AddressTypeP2PKH,
+		class:        txscript.PubKeyHashTy,
+		inputWeight:  148 * 4,
+		outputWeight: 34 * 4,
+		sigHash:      SigHashLegacy,
+		address: func(publicKey *btcec.PublicKey, params *chaincfg.Params) (btcutil.Address, error) {
+			return btcutil.NewAddressPubKeyHash(btcutil.Hash160(publicKey.SerializeCompressed()), params)
+		},
+	},
+	&standardScheme{
+		addrType:     AddressTypeP2SH,
+		class:        txscript.ScriptHashTy,
+		inputWeight:  64*4 + 108,
+		outputWeight: 32 * 4,
+		sigHash:      SigHashWitnessV0,
+		address: func(publicKey *btcec.PublicKey, params *chaincfg.Params) (btcutil.Address, error) {
+			hash := btcutil.Hash160(publicKey.SerializeCompressed())
+			witnessProgram := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, hash...)
+			return btcutil.NewAddressScriptHash(witnessProgram, params)
+		},
+	},
+	&standardScheme{
+		addrType:     AddressTypeP2WPKH,
+		class:        txscript.WitnessV0PubKeyHashTy,
+		inputWeight:  41*4 + 108,
+		outputWeight: 31 * 4,
+		sigHash:      SigHashWitnessV0,
+		address: func(publicKey *btcec.PublicKey, params *chaincfg.Params) (btcutil.Address, error) {
+			return btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(publicKey.SerializeCompressed()), params)
+		},
+	},
+	&standardScheme{
+		addrType:     AddressTypeP2WSH,
+		class:        txscript.WitnessV0ScriptHashTy,
+		outputWeight: 43 * 4,
+		sigHash:      SigHashWitnessV0,
+	},
+	&standardScheme{
+		addrType:     AddressTypeP2TR,
+		class:        txscript.WitnessV1TaprootTy,
+		inputWeight:  41*4 + 66,
+		outputWeight: 43 * 4,
+		sigHash:      SigHashTaproot,
+		address: func(publicKey *btcec.PublicKey, params *chaincfg.Params) (btcutil.Address, error) {
+			outputKey := txscript.ComputeTaprootKeyNoScript(publicKey)
+			return btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), params)
+		},
+	},
+}
+
+// Type implements AddressScheme.
+func (s *standardScheme) Type() AddressType {
+	return s.addrType
+}
+
+// Address implements AddressScheme.
+func (s *standardScheme) Address(publicKey *btcec.PublicKey, params *chaincfg.Params) (btcutil.Address, error) {
+	if s.address == nil {
+		return nil, ErrUnsupportedAddressType
+	}
+	return s.address(publicKey, params)
+}
+
+// Script implements AddressScheme.
+func (s *standardScheme) Script(publicKey *btcec.PublicKey, params *chaincfg.Params) ([]byte, error) {
+	addr, err := s.Address(publicKey, params)
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// Match implements AddressScheme.
+func (s *standardScheme) Match(pkScript []byte) bool {
+	return txscript.GetScriptClass(pkScript) == s.class
+}
+
+// InputWeight implements AddressScheme.
+func (s *standardScheme) InputWeight() int64 {
+	return s.inputWeight
+}
+
+// OutputWeight implements AddressScheme.
+func (s *standardScheme) OutputWeight() int64 {
+	return s.outputWeight
+}
+
+// SigHash implements AddressScheme.
+func (s *standardScheme) SigHash() SigHashVersion {
+	return s.sigHash
+}
@@ -0,0 +1,83 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/assert"
+)
+
+// anchorScheme is a plugin scheme for pay-to-anchor outputs, spendable by
+// anyone with an empty witness.
+type anchorScheme struct{}
+
+var anchorScript = []byte{txscript.OP_1, txscript.OP_DATA_2, 0x4e, 0x73}
+
+func (anchorScheme) Type() AddressType { return "p2a" }
+func (anchorScheme) Address(*btcec.PublicKey, *chaincfg.Params) (btcutil.Address, error) {
+	return nil, assert.AnError
+}
+func (anchorScheme) Script(*btcec.PublicKey, *chaincfg.Params) ([]byte, error) {
+	return anchorScript, nil
+}
+func (anchorScheme) Match(pkScript []byte) bool { return string(pkScript) == string(anchorScript) }
+func (anchorScheme) InputWeight() int64         { return 41 * 4 }
+func (anchorScheme) OutputWeight() int64        { return 13 * 4 }
+func (anchorScheme) SigHash() SigHashVersion    { return SigHashTaproot }
+
+// untypedScheme is a scheme without type.
+type untypedScheme struct{ anchorScheme }
+
+func (untypedScheme) Type() AddressType { return "" }
+
+func Test_AddressSchemes_Builtins(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	for _, addrType := range []AddressType{AddressTypeP2PKH, AddressTypeP2SH, AddressTypeP2WPKH, AddressTypeP2WSH, AddressTypeP2TR} {
+		scheme, ok := LookupAddressScheme(addrType)
+		assert.True(t, ok, "scheme %s should be registered", addrType)
+		assert.Equal(t, addrType, scheme.Type())
+		assert.NotZero(t, scheme.OutputWeight())
+
+		script, err := scheme.Script(wallet.PublicKey(), &chaincfg.MainNetParams)
+		if addrType == AddressTypeP2WSH {
+			assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.True(t, scheme.Match(script))
+	}
+	assert.Subset(t, AddressSchemes(), []AddressType{AddressTypeP2PKH, AddressTypeP2TR})
+
+	scheme, _ := LookupAddressScheme(AddressTypeP2PKH)
+	addr, err := scheme.Address(wallet.PublicKey(), &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), addr.EncodeAddress())
+	assert.Equal(t, SigHashLegacy, scheme.SigHash())
+}
+
+func Test_RegisterAddressScheme(t *testing.T) {
+	assert.NoError(t, RegisterAddressScheme(anchorScheme{}))
+	t.Cleanup(func() {
+		addressSchemesMu.Lock()
+		delete(addressSchemes, "p2a")
+		addressSchemesMu.Unlock()
+	})
+	assert.ErrorIs(t, RegisterAddressScheme(anchorScheme{}), ErrAddressSchemeExists)
+	assert.ErrorIs(t, RegisterAddressScheme(untypedScheme{}), ErrAddressSchemeType)
+	assert.Contains(t, AddressSchemes(), AddressType("p2a"))
+
+	// The plugin is used by script classification and size estimation.
+	addrType, err := ScriptAddressType(anchorScript)
+	assert.NoError(t, err)
+	assert.Equal(t, AddressType("p2a"), addrType)
+
+	vsize, err := EstimateVSize([]AddressType{AddressTypeP2PKH, "p2a"}, []AddressType{"p2a"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64((10*4+148*4+41*4+2+13*4+3)/4), vsize)
+
+	_, err = EstimateVSize([]AddressType{AddressTypeP2WSH}, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+}
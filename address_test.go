@@ -0,0 +1,198 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddressInfo(t *testing.T) {
+	for _, network := range []Network{NetworkMainnet, NetworkTestnet} {
+		t.Run(string(network), func(t *testing.T) {
+			wallet := createKnownWallet(t, network)
+			address := wallet.AddressInfo()
+			assert.Equal(t, wallet.Address().EncodeAddress(), address.String())
+			assert.Equal(t, wallet.AddressHex(), address.String())
+			assert.Equal(t, AddressTypeP2PKH, address.Type())
+			assert.Equal(t, network, address.Network())
+		})
+	}
+
+	assert.Equal(t, "1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", createKnownWallet(t, NetworkMainnet).AddressInfo().String())
+}
+
+func Test_AddressSegwit(t *testing.T) {
+	// BIP84 and BIP49 test vectors.
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	native, err := New(&Config{Mnemonic: mnemonic, Path: `m/84'/0'/0'/0/0`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	address := native.AddressP2WPKH()
+	assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", address.String())
+	assert.Equal(t, AddressTypeP2WPKH, address.Type())
+	assert.Equal(t, NetworkMainnet, address.Network())
+
+	nested, err := New(&Config{Mnemonic: mnemonic, Path: `m/49'/1'/0'/0/0`, Network: NetworkTestnet})
+	assert.NoError(t, err)
+	address = nested.AddressNestedSegwit()
+	assert.Equal(t, "2Mww8dCYPUpKHofjgcXcBCEGmniw9CoaiD2", address.String())
+	assert.Equal(t, AddressTypeP2SH, address.Type())
+
+	parsed, err := ParseAddress(address.String(), NetworkTestnet)
+	assert.NoError(t, err)
+	assert.Equal(t, address, parsed)
+
+	_, err = native.AddressOfType(AddressTypeP2WSH)
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+}
+
+func Test_AddressTaproot(t *testing.T) {
+	// BIP86 test vector.
+	path, err := DerivationPath(AddressTypeP2TR, NetworkMainnet, 0)
+	assert.NoError(t, err)
+	wallet, err := New(&Config{
+		Mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		Path:     path,
+		Network:  NetworkMainnet,
+	})
+	assert.NoError(t, err)
+	child, err := wallet.Derive(0)
+	assert.NoError(t, err)
+	address := child.AddressTaproot()
+	assert.Equal(t, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", address.String())
+	assert.Equal(t, AddressTypeP2TR, address.Type())
+
+	parsed, err := ParseAddress(address.String(), NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, address, parsed)
+}
+
+func Test_ParseAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		network  Network
+		addrType AddressType
+		kind     AddressErrorKind
+		err      error
+	}{
+		{"P2PKH", "1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", NetworkMainnet, AddressTypeP2PKH, 0, nil},
+		{"P2SH", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", NetworkMainnet, AddressTypeP2SH, 0, nil},
+		{"P2WPKH", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", NetworkMainnet, AddressTypeP2WPKH, 0, nil},
+		{"P2TR", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", NetworkMainnet, AddressTypeP2TR, 0, nil},
+		{"Testnet", "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", NetworkTestnet, AddressTypeP2PKH, 0, nil},
+		{"Base58Checksum", "1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozs", NetworkMainnet, "", AddressErrorChecksum, ErrAddressChecksum},
+		{"Bech32Checksum", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", NetworkMainnet, "", AddressErrorChecksum, nil},
+		{"Bech32Variant", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", NetworkMainnet, "", AddressErrorChecksum, ErrSegwitV0Checksum},
+		{"Base58Network", "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", NetworkMainnet, "", AddressErrorNetwork, ErrAddressNetwork},
+		{"Bech32Network", "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", NetworkMainnet, "", AddressErrorNetwork, ErrAddressNetwork},
+		{"Base58Prefix", "LaMT348PWRnrqeeWArpwQPbuanpXDZGEUz", NetworkMainnet, "", AddressErrorPrefix, ErrAddressPrefix},
+		{"Bech32Prefix", "ltc1qg42tkwuuxefutzxezdkdel39gfstuap288mfea", NetworkMainnet, "", AddressErrorPrefix, ErrAddressPrefix},
+		{"WitnessVersion", "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", NetworkMainnet, "", AddressErrorWitnessVersion, nil},
+		{"Malformed", "InvalidBitcoinAddress", NetworkMainnet, "", AddressErrorMalformed, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			address, err := ParseAddress(test.address, test.network)
+			if test.addrType != "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.addrType, address.Type())
+				assert.Equal(t, test.network, address.Network())
+				return
+			}
+			var addrErr *AddressError
+			if assert.True(t, errors.As(err, &addrErr), "%v", err) {
+				assert.Equal(t, test.kind, addrErr.Kind, addrErr.Kind.String())
+				assert.Equal(t, test.address, addrErr.Address)
+			}
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
+
+func Test_ClassifyAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		addrType AddressType
+		script   string
+	}{
+		{"1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", AddressTypeP2PKH, "76a914ff6812ef21de2f4f899530808a228931fd6f369588ac"},
+		{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", AddressTypeP2SH, "a914b472a266d0bd89c13706a4132ccfb16f7c3b9fcb87"},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", AddressTypeP2WPKH, "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3", AddressTypeP2WSH, "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", AddressTypeP2TR, "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+	}
+	for _, test := range tests {
+		addrType, script, err := ClassifyAddress(test.address, NetworkMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, test.addrType, addrType)
+		assert.Equal(t, test.script, hex.EncodeToString(script))
+	}
+
+	_, _, err := ClassifyAddress("mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", NetworkMainnet)
+	var addrErr *AddressError
+	assert.True(t, errors.As(err, &addrErr))
+	assert.Equal(t, AddressErrorNetwork, addrErr.Kind)
+	_, _, err = ClassifyAddress("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", "regtest")
+	assert.ErrorIs(t, err, ErrUnsupportedNet)
+}
+
+func Test_ValidateAddress_Network(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	for _, address := range []string{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"} {
+		valid, err := wallet.ValidateAddress(address)
+		assert.NoError(t, err)
+		assert.False(t, valid)
+	}
+}
+
+func Test_ValidateAddressDetailed(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+
+	validation, err := wallet.ValidateAddressDetailed("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	assert.NoError(t, err)
+	assert.True(t, validation.Valid)
+	assert.Equal(t, NetworkMainnet, validation.Address.Network())
+
+	validation, err = wallet.ValidateAddressDetailed("tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx")
+	assert.NoError(t, err)
+	assert.False(t, validation.Valid)
+	assert.Equal(t, NetworkTestnet, validation.Address.Network())
+	assert.Equal(t, AddressTypeP2WPKH, validation.Address.Type())
+
+	testnet := createKnownWallet(t, NetworkTestnet)
+	validation, err = testnet.ValidateAddressDetailed(wallet.AddressInfo().String())
+	assert.NoError(t, err)
+	assert.False(t, validation.Valid)
+	assert.Equal(t, NetworkMainnet, validation.Address.Network())
+	assert.Equal(t, AddressTypeP2PKH, validation.Address.Type())
+
+	// Regtest addresses belong to no supported network.
+	_, err = wallet.ValidateAddressDetailed("bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080")
+	assert.ErrorIs(t, err, ErrAddressNetwork)
+
+	_, err = wallet.ValidateAddressDetailed("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozs")
+	assert.ErrorIs(t, err, ErrAddressChecksum)
+}
+
+func FuzzParseAddress(f *testing.F) {
+	f.Add("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr")
+	f.Add("3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy")
+	f.Add("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	f.Add("BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4")
+	f.Add("bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0")
+	f.Add("")
+	f.Fuzz(func(t *testing.T, input string) {
+		address, err := ParseAddress(input, NetworkMainnet)
+		if err != nil {
+			return
+		}
+		again, err := ParseAddress(address.String(), NetworkMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, address, again)
+	})
+}
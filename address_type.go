@@ -0,0 +1,42 @@
+package p2pkh
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// AddressType is the kind of script an address or an output pays to.
+type AddressType string
+
+const (
+	AddressTypeP2PKH  AddressType = "p2pkh"
+	AddressTypeP2SH   AddressType = "p2sh"
+	AddressTypeP2WPKH AddressType = "p2wpkh"
+	AddressTypeP2WSH  AddressType = "p2wsh"
+	AddressTypeP2TR   AddressType = "p2tr"
+
+	ErrUnsupportedAddressType Error = "unsupported address type"
+	ErrUnknownScript          Error = "unknown script type"
+)
+
+// ScriptAddressType returns the address type of a scriptPubKey, among the
+// registered address schemes.
+func ScriptAddressType(pkScript []byte) (AddressType, error) {
+	for _, addrType := range AddressSchemes() {
+		if scheme, ok := LookupAddressScheme(addrType); ok && scheme.Match(pkScript) {
+			return addrType, nil
+		}
+	}
+	return "", ErrUnknownScript
+}
+
+// publicKeyAddress returns the address of the given type paying to a single
+// public key. P2SH is P2SH-P2WPKH and P2TR a BIP86 key-path-only output.
+func publicKeyAddress(publicKey *btcec.PublicKey, addrType AddressType, params *chaincfg.Params) (btcutil.Address, error) {
+	scheme, err := addressScheme(addrType)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.Address(publicKey, params)
+}
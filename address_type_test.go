@@ -0,0 +1,52 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/assert"
+)
+
+// payToAddrScript returns the scriptPubKey of an address, failing the test on error.
+func payToAddrScript(t *testing.T, addr btcutil.Address) []byte {
+	script, err := txscript.PayToAddrScript(addr)
+	assert.NoError(t, err)
+	return script
+}
+
+func Test_ScriptAddressType(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	hash20 := make([]byte, 20)
+	hash32 := make([]byte, 32)
+
+	p2pkh, _ := btcutil.NewAddressPubKeyHash(hash20, params)
+	p2sh, _ := btcutil.NewAddressScriptHashFromHash(hash20, params)
+	p2wpkh, _ := btcutil.NewAddressWitnessPubKeyHash(hash20, params)
+	p2wsh, _ := btcutil.NewAddressWitnessScriptHash(hash32, params)
+	p2tr, _ := btcutil.NewAddressTaproot(hash32, params)
+
+	tests := []struct {
+		name     string
+		script   []byte
+		expected AddressType
+	}{
+		{"P2PKH", payToAddrScript(t, p2pkh), AddressTypeP2PKH},
+		{"P2SH", payToAddrScript(t, p2sh), AddressTypeP2SH},
+		{"P2WPKH", payToAddrScript(t, p2wpkh), AddressTypeP2WPKH},
+		{"P2WSH", payToAddrScript(t, p2wsh), AddressTypeP2WSH},
+		{"P2TR", payToAddrScript(t, p2tr), AddressTypeP2TR},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addressType, err := ScriptAddressType(test.script)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, addressType)
+		})
+	}
+
+	_, err := ScriptAddressType([]byte{txscript.OP_RETURN})
+	assert.ErrorIs(t, err, ErrUnknownScript)
+}
@@ -0,0 +1,240 @@
+package p2pkh
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ErrAddressBookName     Error = "address book name cannot be empty"
+	ErrAddressBookExists   Error = "address book entry already exists with another address"
+	ErrAddressBookNotFound Error = "address book entry not found"
+
+	// addressBookStorageKey is the storage key of the address book of a network.
+	addressBookStorageKey = "addressbook-%s.json"
+)
+
+// AddressBookEntry is a named address.
+type AddressBookEntry struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// AddressBook maps names to addresses of a network. It is persisted in a
+// Storage after every change.
+type AddressBook struct {
+	mu      sync.RWMutex
+	network Network
+	params  *chaincfg.Params
+	storage Storage
+	entries map[string]string
+}
+
+// NewAddressBook opens the address book of a network kept in storage. A nil
+// storage keeps the address book in memory.
+func NewAddressBook(network Network, storage Storage) (*AddressBook, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+	book := &AddressBook{
+		network: network,
+		params:  params,
+		storage: storage,
+		entries: make(map[string]string),
+	}
+
+	data, err := storage.Get(book.storageKey())
+	switch {
+	case err != nil && errors.Is(err, ErrStorageNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		var entries []AddressBookEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+		if err := book.add(entries); err != nil {
+			return nil, err
+		}
+	}
+	return book, nil
+}
+
+// Add names an address, which must belong to the network of the book.
+// Adding an existing entry again is a no-op.
+func (s *AddressBook) Add(name, address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.add([]AddressBookEntry{{Name: name, Address: address}}); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// Remove removes the entry of a name.
+func (s *AddressBook) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name = strings.TrimSpace(name)
+	if _, ok := s.entries[name]; !ok {
+		return ErrAddressBookNotFound
+	}
+	delete(s.entries, name)
+	return s.save()
+}
+
+// Lookup returns the address of a name.
+func (s *AddressBook) Lookup(name string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	address, ok := s.entries[strings.TrimSpace(name)]
+	if !ok {
+		return "", ErrAddressBookNotFound
+	}
+	return address, nil
+}
+
+// Entries returns the entries of the book sorted by name.
+func (s *AddressBook) Entries() []AddressBookEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedEntries()
+}
+
+// sortedEntries returns the entries sorted by name. It must be called with
+// the lock held.
+func (s *AddressBook) sortedEntries() []AddressBookEntry {
+	entries := make([]AddressBookEntry, 0, len(s.entries))
+	for name, address := range s.entries {
+		entries = append(entries, AddressBookEntry{Name: name, Address: address})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// PayTo returns a draft output paying amount satoshis to a named address.
+func (s *AddressBook) PayTo(name string, amount Amount) (DraftOutput, error) {
+	address, err := s.Lookup(name)
+	if err != nil {
+		return DraftOutput{}, fmt.Errorf("%w: %q", ErrAddressBookNotFound, name)
+	}
+	if amount <= 0 {
+		return DraftOutput{}, ErrDraftInvalidAmount
+	}
+	return DraftOutput{Address: address, Amount: amount}, nil
+}
+
+// ExportJSON writes the entries as a JSON array.
+func (s *AddressBook) ExportJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s.Entries())
+}
+
+// ImportJSON adds the entries of a JSON array written by ExportJSON. Either
+// every entry is valid and added, or none is.
+func (s *AddressBook) ImportJSON(r io.Reader) error {
+	var entries []AddressBookEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	return s.importEntries(entries)
+}
+
+// ExportCSV writes the entries as CSV records "name,address" after a header.
+func (s *AddressBook) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "address"}); err != nil {
+		return err
+	}
+	for _, entry := range s.Entries() {
+		if err := cw.Write([]string{entry.Name, entry.Address}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportCSV adds the "name,address" records of a CSV document, whose header
+// line is optional. Either every entry is valid and added, or none is.
+func (s *AddressBook) ImportCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "name") && strings.EqualFold(records[0][1], "address") {
+		records = records[1:]
+	}
+
+	entries := make([]AddressBookEntry, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, AddressBookEntry{Name: record[0], Address: record[1]})
+	}
+	return s.importEntries(entries)
+}
+
+// importEntries adds entries atomically and saves the book.
+func (s *AddressBook) importEntries(entries []AddressBookEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := make(map[string]string, len(s.entries))
+	for name, address := range s.entries {
+		previous[name] = address
+	}
+	if err := s.add(entries); err != nil {
+		s.entries = previous
+		return err
+	}
+	return s.save()
+}
+
+// add validates and adds entries. It must be called with the lock held.
+func (s *AddressBook) add(entries []AddressBookEntry) error {
+	for _, entry := range entries {
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			return ErrAddressBookName
+		}
+		address := strings.TrimSpace(entry.Address)
+		if err := validateNetworkAddress(address, s.params); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if existing, ok := s.entries[name]; ok && existing != address {
+			return fmt.Errorf("%w: %s", ErrAddressBookExists, name)
+		}
+		s.entries[name] = address
+	}
+	return nil
+}
+
+// save persists the entries. It must be called with the lock held.
+func (s *AddressBook) save() error {
+	data, err := json.Marshal(s.sortedEntries())
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(s.storageKey(), data)
+}
+
+// storageKey returns the storage key of the book.
+func (s *AddressBook) storageKey() string {
+	return fmt.Sprintf(addressBookStorageKey, s.network)
+}
@@ -0,0 +1,67 @@
+package p2pkh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddressBook(t *testing.T) {
+	storage := NewMemoryStorage()
+	book, err := NewAddressBook(NetworkMainnet, storage)
+	assert.NoError(t, err)
+
+	assert.NoError(t, book.Add("alice", "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A"))
+	assert.NoError(t, book.Add(" bob ", "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"))
+	assert.NoError(t, book.Add("alice", "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A"))
+	assert.ErrorIs(t, book.Add("alice", "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv"), ErrAddressBookExists)
+	assert.ErrorIs(t, book.Add("", "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv"), ErrAddressBookName)
+	assert.Error(t, book.Add("carol", "mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j"), "Testnet address must be rejected")
+
+	address, err := book.Lookup("bob")
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", address)
+
+	out, err := book.PayTo("alice", 15000)
+	assert.NoError(t, err)
+	assert.Equal(t, DraftOutput{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 15000}, out)
+	_, err = book.PayTo("carol", 15000)
+	assert.ErrorIs(t, err, ErrAddressBookNotFound)
+	_, err = book.PayTo("alice", 0)
+	assert.ErrorIs(t, err, ErrDraftInvalidAmount)
+
+	// The book is persisted in its storage.
+	reopened, err := NewAddressBook(NetworkMainnet, storage)
+	assert.NoError(t, err)
+	assert.Equal(t, book.Entries(), reopened.Entries())
+
+	assert.NoError(t, reopened.Remove("bob"))
+	assert.ErrorIs(t, reopened.Remove("bob"), ErrAddressBookNotFound)
+	assert.Len(t, reopened.Entries(), 1)
+}
+
+func Test_AddressBook_ImportExport(t *testing.T) {
+	book, err := NewAddressBook(NetworkMainnet, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, book.ImportCSV(strings.NewReader("name,address\nalice,1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A\n\"Bob, Inc.\", 1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv\n")))
+	assert.Len(t, book.Entries(), 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, book.ExportCSV(&buf))
+	assert.Equal(t, "name,address\n\"Bob, Inc.\",1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv\nalice,1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A\n", buf.String())
+
+	copied, err := NewAddressBook(NetworkMainnet, nil)
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.NoError(t, book.ExportJSON(&buf))
+	assert.NoError(t, copied.ImportJSON(&buf))
+	assert.Equal(t, book.Entries(), copied.Entries())
+
+	// A single invalid entry rejects the whole import.
+	err = copied.ImportCSV(strings.NewReader("carol,1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A\ndave,invalid\n"))
+	assert.ErrorContains(t, err, "dave")
+	_, err = copied.Lookup("carol")
+	assert.ErrorIs(t, err, ErrAddressBookNotFound)
+}
@@ -0,0 +1,230 @@
+package p2pkh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	ErrAllocatorGapLimit Error = "too many unused receive addresses handed out"
+	ErrAllocatorChain    Error = "chain must be 0 (receive) or 1 (change)"
+
+	// allocatorStorageKey is the storage key of the allocations of an
+	// account: master key fingerprint, purpose, coin type and account.
+	allocatorStorageKey = "addresses-%08x-%d-%d-%d.json"
+)
+
+// AllocatorConfig configures an AddressAllocator. Zero values select
+// sensible defaults.
+type AllocatorConfig struct {
+	// GapLimit bounds the receive addresses handed out past the last one used
+	// on chain, 20 by default as wallets restoring the account stop looking
+	// for payments after as many unused addresses. A negative value disables
+	// the limit.
+	GapLimit int
+}
+
+// allocatorState is the persisted state of an AddressAllocator: the next
+// index of each chain and the indexes seen used on chain.
+type allocatorState struct {
+	Next [2]uint32   `json:"next"`
+	Used [2][]uint32 `json:"used,omitempty"`
+}
+
+// AddressAllocator hands out the addresses of an account, each once: it
+// persists in a Storage the next index of the receive and change chains, and
+// the addresses used on chain, so that concurrent requests and restarts never
+// reuse an address. It is safe for concurrent use.
+type AddressAllocator struct {
+	mu       sync.Mutex
+	account  *Account
+	storage  Storage
+	key      string
+	gapLimit int
+	state    allocatorState
+	used     [2]map[uint32]bool
+}
+
+// NewAddressAllocator opens the allocations of an account kept in storage.
+// A nil storage keeps them in memory.
+func NewAddressAllocator(account *Account, storage Storage, config *AllocatorConfig) (*AddressAllocator, error) {
+	cfg := AllocatorConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.GapLimit == 0 {
+		cfg.GapLimit = defaultGapLimit
+	}
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+	fingerprint, err := account.wallet.masterFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	purpose, _ := account.wallet.Purpose()
+	coinType, _ := account.wallet.CoinType()
+	allocator := &AddressAllocator{
+		account:  account,
+		storage:  storage,
+		key:      fmt.Sprintf(allocatorStorageKey, fingerprint, purpose, coinType, account.Index()),
+		gapLimit: cfg.GapLimit,
+		used:     [2]map[uint32]bool{{}, {}},
+	}
+
+	data, err := storage.Get(allocator.key)
+	switch {
+	case err != nil && errors.Is(err, ErrStorageNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &allocator.state); err != nil {
+			return nil, err
+		}
+		for chain, indexes := range allocator.state.Used {
+			for _, index := range indexes {
+				allocator.used[chain][index] = true
+			}
+		}
+	}
+	return allocator, nil
+}
+
+// NextReceiveAddress returns the wallet of the next receive address never
+// handed out, e.g. for the deposit address of a customer. The allocation is
+// persisted before the address is returned. It fails with
+// ErrAllocatorGapLimit once GapLimit addresses are handed out past the last
+// used one.
+func (s *AddressAllocator) NextReceiveAddress() (*Wallet, error) {
+	return s.next(0)
+}
+
+// NextChangeAddress returns the wallet of the next change address never
+// handed out, e.g. for TxBuilder.WithChangeIndex.
+func (s *AddressAllocator) NextChangeAddress() (*Wallet, error) {
+	return s.next(1)
+}
+
+// MarkUsed records that the address at an index of a chain (0 receive, 1
+// change) is used on chain. Addresses up to it are no longer handed out.
+func (s *AddressAllocator) MarkUsed(chain, index uint32) error {
+	if chain > 1 {
+		return ErrAllocatorChain
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.markUsed(map[uint32][]uint32{chain: {index}})
+}
+
+// IsUsed reports whether the address at an index of a chain was marked
+// used.
+func (s *AddressAllocator) IsUsed(chain, index uint32) bool {
+	if chain > 1 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used[chain][index]
+}
+
+// Sync discovers the addresses of the account used on chain through the
+// backend, e.g. paid by customers or spent from by another instance, and
+// marks them used.
+func (s *AddressAllocator) Sync(ctx context.Context, backend ChainBackend) error {
+	gapLimit := s.gapLimit
+	if gapLimit < 0 {
+		gapLimit = defaultGapLimit
+	}
+	discovery, err := s.account.wallet.WithBackend(backend).Discover(ctx, gapLimit)
+	if err != nil {
+		return err
+	}
+	used := make(map[uint32][]uint32)
+	for _, address := range discovery.Used {
+		used[address.Chain] = append(used[address.Chain], address.Index)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.markUsed(used)
+}
+
+// next hands out the next address of a chain.
+func (s *AddressAllocator) next(chain uint32) (*Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := s.state.Next[chain]
+	if chain == 0 && s.gapLimit > 0 && index-s.firstUnused(chain) >= uint32(s.gapLimit) {
+		return nil, ErrAllocatorGapLimit
+	}
+	wallet, err := s.account.wallet.At(chain, index)
+	if err != nil {
+		return nil, err
+	}
+	s.state.Next[chain]++
+	if err := s.save(); err != nil {
+		s.state.Next[chain]--
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// markUsed marks used the indexes of the chains, moving the next index of
+// each chain past them, and saves the state. It must be called with the lock
+// held.
+func (s *AddressAllocator) markUsed(indexes map[uint32][]uint32) error {
+	// The used indexes are copied before being extended, so that the
+	// previous state stays intact.
+	previous := s.state
+	var added [2][]uint32
+	for chain, chainIndexes := range indexes {
+		for _, index := range chainIndexes {
+			if index >= s.state.Next[chain] {
+				s.state.Next[chain] = index + 1
+			}
+			if !s.used[chain][index] {
+				added[chain] = append(added[chain], index)
+			}
+		}
+	}
+	for chain := range added {
+		if len(added[chain]) == 0 {
+			continue
+		}
+		used := append(append([]uint32(nil), s.state.Used[chain]...), added[chain]...)
+		sort.Slice(used, func(i, j int) bool { return used[i] < used[j] })
+		s.state.Used[chain] = used
+	}
+	if err := s.save(); err != nil {
+		s.state = previous
+		return err
+	}
+	for chain := range added {
+		for _, index := range added[chain] {
+			s.used[chain][index] = true
+		}
+	}
+	return nil
+}
+
+// firstUnused returns the index following the last used address of a
+// chain. It must be called with the lock held.
+func (s *AddressAllocator) firstUnused(chain uint32) uint32 {
+	used := s.state.Used[chain]
+	if len(used) == 0 {
+		return 0
+	}
+	return used[len(used)-1] + 1
+}
+
+// save persists the state. It must be called with the lock held.
+func (s *AddressAllocator) save() error {
+	data, err := json.Marshal(&s.state)
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(s.key, data)
+}
@@ -0,0 +1,158 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStorage is a MemoryStorage whose writes fail once err is set.
+type failingStorage struct {
+	*MemoryStorage
+	err error
+}
+
+func (s *failingStorage) Put(key string, value []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.MemoryStorage.Put(key, value)
+}
+
+func newTestAllocator(t *testing.T, storage Storage, config *AllocatorConfig) (*Account, *AddressAllocator) {
+	account, err := NewAccount(createKnownWallet(t, NetworkMainnet))
+	assert.NoError(t, err)
+	allocator, err := NewAddressAllocator(account, storage, config)
+	assert.NoError(t, err)
+	return account, allocator
+}
+
+func Test_AddressAllocator(t *testing.T) {
+	storage := NewMemoryStorage()
+	account, allocator := newTestAllocator(t, storage, nil)
+
+	for _, path := range []string{`m/44'/0'/0'/0/0`, `m/44'/0'/0'/0/1`} {
+		wallet, err := allocator.NextReceiveAddress()
+		assert.NoError(t, err)
+		assert.Equal(t, path, wallet.Path())
+	}
+	change, err := allocator.NextChangeAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/1/0`, change.Path())
+
+	keys, err := storage.Keys("addresses-")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	// Reopened, the allocator goes on where it stopped.
+	reopened, err := NewAddressAllocator(account, storage, nil)
+	assert.NoError(t, err)
+	wallet, err := reopened.NextReceiveAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/2`, wallet.Path())
+	change, err = reopened.NextChangeAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/1/1`, change.Path())
+
+	// Another account is allocated separately.
+	other, err := account.wallet.AccountAt(1)
+	assert.NoError(t, err)
+	otherAllocator, err := NewAddressAllocator(other, storage, nil)
+	assert.NoError(t, err)
+	wallet, err = otherAllocator.NextReceiveAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/1'/0/0`, wallet.Path())
+}
+
+func Test_AddressAllocator_Concurrent(t *testing.T) {
+	_, allocator := newTestAllocator(t, nil, &AllocatorConfig{GapLimit: -1})
+
+	var mu sync.Mutex
+	addresses := make(map[string]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wallet, err := allocator.NextReceiveAddress()
+			assert.NoError(t, err)
+			mu.Lock()
+			addresses[wallet.AddressHex()] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, addresses, 40)
+}
+
+func Test_AddressAllocator_GapLimit(t *testing.T) {
+	_, allocator := newTestAllocator(t, nil, &AllocatorConfig{GapLimit: 2})
+
+	for i := 0; i < 2; i++ {
+		_, err := allocator.NextReceiveAddress()
+		assert.NoError(t, err)
+	}
+	_, err := allocator.NextReceiveAddress()
+	assert.ErrorIs(t, err, ErrAllocatorGapLimit)
+	// Change addresses are not bound by the gap limit.
+	_, err = allocator.NextChangeAddress()
+	assert.NoError(t, err)
+
+	assert.NoError(t, allocator.MarkUsed(0, 0))
+	assert.True(t, allocator.IsUsed(0, 0))
+	assert.False(t, allocator.IsUsed(0, 1))
+	wallet, err := allocator.NextReceiveAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/2`, wallet.Path())
+
+	// Addresses used beyond the handed out ones are skipped.
+	assert.NoError(t, allocator.MarkUsed(0, 6))
+	wallet, err = allocator.NextReceiveAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/7`, wallet.Path())
+
+	assert.ErrorIs(t, allocator.MarkUsed(2, 0), ErrAllocatorChain)
+	assert.False(t, allocator.IsUsed(2, 0))
+}
+
+func Test_AddressAllocator_Sync(t *testing.T) {
+	ctx := context.Background()
+	account, allocator := newTestAllocator(t, nil, nil)
+	backend := newFakeBackend()
+	receive, err := account.Receive(3)
+	assert.NoError(t, err)
+	backend.addHistory(receive.AddressHex(), "aa")
+	change, err := account.Change(0)
+	assert.NoError(t, err)
+	backend.addHistory(change.AddressHex(), "bb")
+
+	assert.NoError(t, allocator.Sync(ctx, backend))
+	assert.True(t, allocator.IsUsed(0, 3))
+	assert.True(t, allocator.IsUsed(1, 0))
+	wallet, err := allocator.NextReceiveAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/4`, wallet.Path())
+	wallet, err = allocator.NextChangeAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/1/1`, wallet.Path())
+}
+
+func Test_AddressAllocator_StorageError(t *testing.T) {
+	storage := &failingStorage{MemoryStorage: NewMemoryStorage()}
+	_, allocator := newTestAllocator(t, storage, nil)
+
+	storage.err = errors.New("disk full")
+	_, err := allocator.NextReceiveAddress()
+	assert.ErrorIs(t, err, storage.err)
+	assert.ErrorIs(t, allocator.MarkUsed(0, 5), storage.err)
+	assert.False(t, allocator.IsUsed(0, 5))
+
+	// A failed allocation hands out its address again.
+	storage.err = nil
+	wallet, err := allocator.NextReceiveAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/0`, wallet.Path())
+}
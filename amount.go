@@ -0,0 +1,176 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	ErrAmountInvalid  Error = "invalid amount: expected BTC such as \"0.001\" or satoshis such as \"100000 sat\""
+	ErrAmountOverflow Error = "amount overflow"
+	ErrAmountRange    Error = "amount exceeds 21 million bitcoins"
+	ErrAmountMsat     Error = "amount is not a whole number of satoshis"
+
+	// MaxAmount is the total supply of bitcoins, in satoshis.
+	MaxAmount Amount = 21e6 * btcutil.SatoshiPerBitcoin
+
+	amountDecimals = 8
+	// msatPerSat is the number of millisatoshis, the unit of the Lightning
+	// amounts, in a satoshi.
+	msatPerSat = 1000
+)
+
+// Amount is an amount of satoshis. Amounts are never floating point numbers:
+// parse user input with ParseAmount and combine amounts with the checked
+// Add, Sub and Mul.
+type Amount int64
+
+// ParseAmount strictly parses an amount in BTC, e.g. "0.001" or "0.001 BTC",
+// in satoshis, e.g. "100000 sat", or in whole satoshis of millisatoshis,
+// e.g. "100000000 msat". BTC amounts have at most 8 decimals; exponents and
+// negative amounts are rejected.
+func ParseAmount(s string) (Amount, error) {
+	value, unit, _ := strings.Cut(strings.TrimSpace(s), " ")
+	unit = strings.TrimSpace(unit)
+	switch strings.ToLower(unit) {
+	case "sat", "sats", "satoshi", "satoshis":
+		if value == "" || strings.Trim(value, "0123456789") != "" {
+			return 0, ErrAmountInvalid
+		}
+		sats, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, ErrAmountInvalid
+		}
+		return checkAmountRange(Amount(sats))
+	case "msat", "msats":
+		if value == "" || strings.Trim(value, "0123456789") != "" {
+			return 0, ErrAmountInvalid
+		}
+		msat, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, ErrAmountInvalid
+		}
+		amount, err := AmountFromMilliSats(msat)
+		if err != nil {
+			return 0, err
+		}
+		return checkAmountRange(amount)
+	case "", "btc":
+		return parseBTC(value)
+	default:
+		return 0, ErrAmountInvalid
+	}
+}
+
+// parseBTC parses a decimal amount of bitcoins without going through floats.
+func parseBTC(value string) (Amount, error) {
+	whole, fraction, hasPoint := strings.Cut(value, ".")
+	if whole == "" && fraction == "" || hasPoint && fraction == "" || len(fraction) > amountDecimals ||
+		strings.Trim(whole, "0123456789") != "" || strings.Trim(fraction, "0123456789") != "" {
+		return 0, ErrAmountInvalid
+	}
+	if len(whole) > 8 {
+		return 0, ErrAmountRange
+	}
+	digits := whole + fraction + strings.Repeat("0", amountDecimals-len(fraction))
+	sats, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, ErrAmountInvalid
+	}
+	return checkAmountRange(Amount(sats))
+}
+
+// checkAmountRange checks that an amount does not exceed the supply.
+func checkAmountRange(amount Amount) (Amount, error) {
+	if amount > MaxAmount {
+		return 0, ErrAmountRange
+	}
+	return amount, nil
+}
+
+// Sats returns the amount in satoshis.
+func (a Amount) Sats() int64 {
+	return int64(a)
+}
+
+// AmountFromMilliSats returns the amount of a number of millisatoshis, which
+// must be whole satoshis.
+func AmountFromMilliSats(msat int64) (Amount, error) {
+	if msat%msatPerSat != 0 {
+		return 0, ErrAmountMsat
+	}
+	return Amount(msat / msatPerSat), nil
+}
+
+// MilliSats returns the amount in millisatoshis, or an error on overflow.
+func (a Amount) MilliSats() (int64, error) {
+	msat, err := a.Mul(msatPerSat)
+	return int64(msat), err
+}
+
+// BTC formats the amount in BTC without trailing zeros, e.g. "0.001".
+func (a Amount) BTC() string {
+	return formatBTC(int64(a))
+}
+
+// String formats the amount in BTC with its unit, e.g. "0.001 BTC".
+func (a Amount) String() string {
+	return a.BTC() + " BTC"
+}
+
+// Add returns a + b, or an error on overflow.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if b > 0 && a > math.MaxInt64-b || b < 0 && a < math.MinInt64-b {
+		return 0, ErrAmountOverflow
+	}
+	return a + b, nil
+}
+
+// Sub returns a - b, or an error on overflow.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b < 0 && a > math.MaxInt64+b || b > 0 && a < math.MinInt64+b {
+		return 0, ErrAmountOverflow
+	}
+	return a - b, nil
+}
+
+// Mul returns a * n, or an error on overflow.
+func (a Amount) Mul(n int64) (Amount, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	product := a * Amount(n)
+	if product/Amount(n) != a || a == -1 && n == math.MinInt64 || n == -1 && a == math.MinInt64 {
+		return 0, ErrAmountOverflow
+	}
+	return product, nil
+}
+
+// UnmarshalJSON decodes an amount encoded as an integer number of satoshis,
+// or as a string accepted by ParseAmount. Floating point numbers, negative
+// amounts and amounts above MaxAmount are rejected, as ParseAmount does.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		amount, err := ParseAmount(s)
+		if err != nil {
+			return err
+		}
+		*a = amount
+		return nil
+	}
+	sats, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || sats < 0 {
+		return ErrAmountInvalid
+	}
+	amount, err := checkAmountRange(Amount(sats))
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
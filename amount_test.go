@@ -0,0 +1,123 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseAmount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Amount
+		err      error
+	}{
+		{"0.001", 100000, nil},
+		{"0.001 BTC", 100000, nil},
+		{"1", 100000000, nil},
+		{".5", 50000000, nil},
+		{"0.00000001", 1, nil},
+		{"100000 sat", 100000, nil},
+		{"1 sats", 1, nil},
+		{"100000000 msat", 100000, nil},
+		{"1500 msat", 0, ErrAmountMsat},
+		{"21000000", MaxAmount, nil},
+		{"21000000.00000001", 0, ErrAmountRange},
+		{"2100000000000001 sat", 0, ErrAmountRange},
+		{"0.000000001", 0, ErrAmountInvalid},
+		{"1e-3", 0, ErrAmountInvalid},
+		{"-1", 0, ErrAmountInvalid},
+		{"1.", 0, ErrAmountInvalid},
+		{"0.5 sat", 0, ErrAmountInvalid},
+		{"1 EUR", 0, ErrAmountInvalid},
+		{"", 0, ErrAmountInvalid},
+	}
+	for _, test := range tests {
+		amount, err := ParseAmount(test.input)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err, test.input)
+			continue
+		}
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, amount, test.input)
+	}
+}
+
+func Test_Amount_Arithmetic(t *testing.T) {
+	sum, err := Amount(1000).Add(500)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(1500), sum)
+	diff, err := Amount(1000).Sub(1500)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(-500), diff)
+	product, err := Amount(1000).Mul(3)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(3000), product)
+
+	_, err = Amount(math.MaxInt64).Add(1)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = Amount(math.MinInt64).Sub(1)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = Amount(math.MaxInt64 / 2).Mul(3)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = Amount(-1).Mul(math.MinInt64)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func Test_Amount_Format(t *testing.T) {
+	assert.Equal(t, "0.001 BTC", Amount(100000).String())
+	assert.Equal(t, "21000000", MaxAmount.BTC())
+	assert.Equal(t, int64(1), Amount(1).Sats())
+}
+
+func Test_Amount_MilliSats(t *testing.T) {
+	msat, err := Amount(1500).MilliSats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500000), msat)
+	_, err = Amount(math.MaxInt64 / 100).MilliSats()
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+
+	amount, err := AmountFromMilliSats(2000)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(2), amount)
+	_, err = AmountFromMilliSats(2001)
+	assert.ErrorIs(t, err, ErrAmountMsat)
+}
+
+func Test_Amount_JSON(t *testing.T) {
+	var out DraftOutput
+	assert.NoError(t, json.Unmarshal([]byte(`{"address": "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", "amount": 1500}`), &out))
+	assert.Equal(t, Amount(1500), out.Amount)
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": "0.001"}`), &out))
+	assert.Equal(t, Amount(100000), out.Amount)
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": 0.001}`), &out))
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount": -1}`), &out), ErrAmountInvalid)
+	assert.ErrorIs(t, json.Unmarshal([]byte(fmt.Sprintf(`{"amount": %d}`, MaxAmount+1)), &out), ErrAmountRange)
+	assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"amount": %d}`, MaxAmount)), &out))
+	assert.Equal(t, MaxAmount, out.Amount)
+
+	data, err := json.Marshal(DraftOutput{Amount: 1500})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"amount":1500`)
+}
+
+func FuzzParseAmount(f *testing.F) {
+	f.Add("0.001")
+	f.Add("0.001 BTC")
+	f.Add("100000 sat")
+	f.Add("100000000 msat")
+	f.Add("21000000.00000001")
+	f.Fuzz(func(t *testing.T, input string) {
+		amount, err := ParseAmount(input)
+		if err != nil {
+			return
+		}
+		assert.True(t, amount >= 0 && amount <= MaxAmount)
+		again, err := ParseAmount(amount.BTC())
+		assert.NoError(t, err)
+		assert.Equal(t, amount, again)
+	})
+}
@@ -0,0 +1,106 @@
+package p2pkh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Events logged by wallets with a logger, see Config.Logger. Their attributes
+// never hold private keys, mnemonics nor passphrases; every event carries
+// the path and address of the wallet logging it.
+const (
+	// EventDerive is logged at debug level for each wallet derived, e.g. by
+	// Derive or At.
+	EventDerive = "p2pkh.derive"
+	// EventAddresses is logged at debug level for each batch of addresses
+	// generated by Addresses, with its first index and count.
+	EventAddresses = "p2pkh.addresses"
+	// EventSign is logged for each signature produced, with its kind
+	// ("input", "multisig_input", "psbt_input", "inheritance_input",
+	// "digest", "message", "ownership_proof" or "reserve_proof"), the path of
+	// the signing key and what is signed: the input and the outpoint it
+	// spends, the digest, or the hash of the message.
+	EventSign = "p2pkh.sign"
+	// EventBroadcast is logged for each transaction broadcast by the wallet,
+	// with its txid and size, or the error of the backend.
+	EventBroadcast = "p2pkh.broadcast"
+
+	// redacted replaces the secrets in the log values.
+	redacted = "[REDACTED]"
+)
+
+// WithLogger returns a copy of the wallet logging its events, e.g. to an
+// audit trail of the signatures of a service. The children derived from the
+// copy share its logger.
+func (s *Wallet) WithLogger(logger *slog.Logger) *Wallet {
+	wallet := *s
+	wallet.logger = logger
+	return &wallet
+}
+
+// LogValue logs a wallet as its path, address and network, never its keys.
+func (s *Wallet) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("path", s.path),
+		slog.String("address", s.AddressInfo().String()),
+		slog.String("network", string(s.network)),
+		slog.Bool("watch_only", s.IsWatchOnly()),
+	)
+}
+
+// LogValue logs a configuration with its mnemonic and passphrase redacted.
+func (s Config) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("path", s.Path),
+		slog.String("network", string(s.Network)),
+	}
+	if s.Mnemonic != "" {
+		attrs = append(attrs, slog.String("mnemonic", redacted))
+	}
+	if s.Passphrase != "" {
+		attrs = append(attrs, slog.String("passphrase", redacted))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// logEvent logs an event of the wallet, if it has a logger.
+func (s *Wallet) logEvent(ctx context.Context, level slog.Level, event string, attrs ...slog.Attr) {
+	if s.logger == nil || !s.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs = append([]slog.Attr{
+		slog.String("path", s.path),
+		slog.String("address", s.AddressInfo().String()),
+	}, attrs...)
+	s.logger.LogAttrs(ctx, level, event, attrs...)
+}
+
+// logSign logs a signature of the key at path, the wallet key when path is
+// empty.
+func (s *Wallet) logSign(kind, path string, attrs ...slog.Attr) {
+	if s.logger == nil {
+		return
+	}
+	if path == "" {
+		path = s.path
+	}
+	attrs = append([]slog.Attr{slog.String("kind", kind), slog.String("key_path", path)}, attrs...)
+	s.logEvent(context.Background(), slog.LevelInfo, EventSign, attrs...)
+}
+
+// inputAttrs returns the attributes of the signature of an input: its index
+// and the outpoint it spends.
+func inputAttrs(tx *wire.MsgTx, i int) []slog.Attr {
+	return []slog.Attr{slog.Int("input", i), slog.String("outpoint", tx.TxIn[i].PreviousOutPoint.String())}
+}
+
+// messageAttr returns the attribute of the signature of a message: its
+// SHA-256 hash, so that the message itself stays out of the logs.
+func messageAttr(message string) slog.Attr {
+	sum := sha256.Sum256([]byte(message))
+	return slog.String("message_hash", hex.EncodeToString(sum[:]))
+}
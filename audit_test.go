@@ -0,0 +1,88 @@
+package p2pkh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logEvents returns the events logged as JSON lines.
+func logEvents(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var events []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		event := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func Test_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	backend := newFakeBackend()
+	wallet, err := NewWallet(testMnemonic, WithPassphrase("secret passphrase"), WithLogger(logger), WithBackend(backend))
+	assert.NoError(t, err)
+
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	rawTx, err := child.NewTransaction().
+		AddInput(walletUTXO(t, child, 1, 60000)).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 50000).
+		Sign()
+	assert.NoError(t, err)
+	txid, err := child.Broadcast(context.Background(), rawTx)
+	assert.NoError(t, err)
+	_, err = wallet.SignMessage("hello")
+	assert.NoError(t, err)
+
+	events := logEvents(t, &buf)
+	assert.Len(t, events, 4)
+	assert.Equal(t, EventDerive, events[0]["msg"])
+	assert.Equal(t, "DEBUG", events[0]["level"])
+	assert.Equal(t, child.Path(), events[0]["path"])
+	assert.Equal(t, child.AddressHex(), events[0]["address"])
+
+	assert.Equal(t, EventSign, events[1]["msg"])
+	assert.Equal(t, "input", events[1]["kind"])
+	assert.Equal(t, child.Path(), events[1]["key_path"])
+	assert.Equal(t, testTxID+":1", events[1]["outpoint"])
+
+	assert.Equal(t, EventBroadcast, events[2]["msg"])
+	assert.Equal(t, txid, events[2]["txid"])
+
+	assert.Equal(t, EventSign, events[3]["msg"])
+	assert.Equal(t, "message", events[3]["kind"])
+	assert.Equal(t, wallet.Path(), events[3]["key_path"])
+	assert.NotContains(t, buf.String(), "hello")
+
+	// Secrets are never logged, even when logging a wallet or its config.
+	logger.Info("wallet", "wallet", wallet, "config", Config{Mnemonic: testMnemonic, Passphrase: "secret passphrase"})
+	wif, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+	xprv, err := wallet.ExtendedPrivateKey()
+	assert.NoError(t, err)
+	for _, secret := range []string{testMnemonic, "secret passphrase", wif, xprv} {
+		assert.NotContains(t, buf.String(), secret)
+	}
+	assert.Contains(t, buf.String(), redacted)
+
+	// Failed broadcasts are logged as errors.
+	buf.Reset()
+	backend.err = ErrBackendUnavailable
+	_, err = child.Broadcast(context.Background(), rawTx)
+	assert.Error(t, err)
+	events = logEvents(t, &buf)
+	assert.Equal(t, "ERROR", events[0]["level"])
+	assert.Contains(t, events[0]["error"], string(ErrBackendUnavailable))
+
+	// Wallets without a logger log nothing.
+	_, err = wallet.WithLogger(nil).SignMessage("hello")
+	assert.NoError(t, err)
+	assert.Len(t, logEvents(t, &buf), 1)
+}
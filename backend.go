@@ -0,0 +1,154 @@
+package p2pkh
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// UTXO is an unspent transaction output. Amount is expressed in satoshis.
+type UTXO struct {
+	TxID     string
+	Vout     uint32
+	Amount   Amount
+	PkScript []byte
+}
+
+// outpoint returns the "txid:vout" reference of the UTXO.
+func (s UTXO) outpoint() string {
+	return fmt.Sprintf("%s:%d", s.TxID, s.Vout)
+}
+
+// utxoJSON is the JSON representation of an UTXO, with a hex encoded script.
+type utxoJSON struct {
+	TxID     string `json:"txid"`
+	Vout     uint32 `json:"vout"`
+	Amount   Amount `json:"amount"`
+	PkScript string `json:"pkScript"`
+}
+
+// MarshalJSON encodes the UTXO with a hex encoded scriptPubKey.
+func (s UTXO) MarshalJSON() ([]byte, error) {
+	return json.Marshal(utxoJSON{
+		TxID:     s.TxID,
+		Vout:     s.Vout,
+		Amount:   s.Amount,
+		PkScript: hex.EncodeToString(s.PkScript),
+	})
+}
+
+// UnmarshalJSON decodes an UTXO encoded by MarshalJSON.
+func (s *UTXO) UnmarshalJSON(data []byte) error {
+	var v utxoJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	script, err := hex.DecodeString(v.PkScript)
+	if err != nil {
+		return err
+	}
+	*s = UTXO{TxID: v.TxID, Vout: v.Vout, Amount: v.Amount, PkScript: script}
+	return nil
+}
+
+// TxStatus describes what a chain backend currently knows about a transaction.
+// A transaction that is neither in the mempool nor confirmed is unknown to the
+// backend (never seen, evicted or dropped).
+type TxStatus struct {
+	InMempool bool
+	// Conflicted is set when a conflicting transaction spending the same
+	// inputs has been accepted instead.
+	Conflicted    bool
+	Confirmations int
+	BlockHeight   int64
+	BlockHash     string
+}
+
+// Confirmed reports whether the transaction has been included in a block.
+func (s *TxStatus) Confirmed() bool {
+	return s.Confirmations > 0
+}
+
+// Known reports whether the backend has seen the transaction at all.
+func (s *TxStatus) Known() bool {
+	return s.InMempool || s.Confirmed()
+}
+
+// TxStatusProvider is the part of a chain backend able to report the status
+// of a transaction.
+type TxStatusProvider interface {
+	// TxStatus returns the current status of the given transaction.
+	TxStatus(ctx context.Context, txid string) (*TxStatus, error)
+}
+
+// Broadcaster is the part of a chain backend able to publish raw transactions
+// and to report their status.
+type Broadcaster interface {
+	TxStatusProvider
+	// Broadcast publishes a serialized transaction and returns its txid.
+	Broadcast(ctx context.Context, rawTx []byte) (string, error)
+}
+
+// UTXOProvider is the part of a chain backend able to list the unspent
+// outputs of an address.
+type UTXOProvider interface {
+	// AddressUTXOs returns the unspent outputs paying to the given address.
+	AddressUTXOs(ctx context.Context, address string) ([]UTXO, error)
+}
+
+// MempoolAcceptResult is the verdict of a backend mempool on a transaction,
+// as returned by the testmempoolaccept RPC.
+type MempoolAcceptResult struct {
+	Allowed bool
+	// RejectReason is the policy rule rejecting the transaction, e.g.
+	// "min relay fee not met".
+	RejectReason string
+}
+
+// MempoolAcceptor is the part of a chain backend able to test whether its
+// mempool would accept a transaction, without broadcasting it.
+type MempoolAcceptor interface {
+	// TestMempoolAccept tests a serialized transaction against the mempool policy.
+	TestMempoolAccept(ctx context.Context, rawTx []byte) (*MempoolAcceptResult, error)
+}
+
+// MempoolEntry is a transaction waiting in the mempool of a backend.
+type MempoolEntry struct {
+	Fee   Amount
+	VSize int64
+}
+
+// MempoolProvider is the part of a chain backend able to list the fees and
+// sizes of the transactions of its mempool.
+type MempoolProvider interface {
+	// MempoolEntries returns the transactions currently in the mempool.
+	MempoolEntries(ctx context.Context) ([]MempoolEntry, error)
+}
+
+// Balance is the balance of an address in satoshis, split between confirmed
+// and mempool funds.
+type Balance struct {
+	Confirmed   Amount
+	Unconfirmed Amount
+}
+
+// Total returns the confirmed and unconfirmed funds.
+func (s Balance) Total() Amount {
+	return s.Confirmed + s.Unconfirmed
+}
+
+// add adds another balance to the balance.
+func (s *Balance) add(other Balance) {
+	s.Confirmed += other.Confirmed
+	s.Unconfirmed += other.Unconfirmed
+}
+
+// BalanceProvider is the part of a chain backend able to report the balance
+// of an address.
+type BalanceProvider interface {
+	// AddressBalance returns the confirmed and unconfirmed balance of the
+	// given address. Unconfirmed is negative when mempool transactions spend
+	// confirmed funds.
+	AddressBalance(ctx context.Context, address string) (*Balance, error)
+}
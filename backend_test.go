@@ -0,0 +1,172 @@
+package p2pkh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBackend is an in-memory chain backend used by tests.
+type fakeBackend struct {
+	mu         sync.Mutex
+	statuses   map[string]*TxStatus
+	history    map[string][]string
+	utxos      map[string][]UTXO
+	balances   map[string]Balance
+	mempool    []MempoolEntry
+	broadcasts [][]byte
+	reject     string
+	feeRate    FeeRate
+	err        error
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		statuses: make(map[string]*TxStatus),
+		history:  make(map[string][]string),
+		utxos:    make(map[string][]UTXO),
+		balances: make(map[string]Balance),
+	}
+}
+
+// fakeTxID returns a deterministic txid for a raw transaction.
+func fakeTxID(rawTx []byte) string {
+	sum := sha256.Sum256(rawTx)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *fakeBackend) Broadcast(_ context.Context, rawTx []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", s.err
+	}
+	s.broadcasts = append(s.broadcasts, rawTx)
+	txid := fakeTxID(rawTx)
+	if _, ok := s.statuses[txid]; !ok {
+		s.statuses[txid] = &TxStatus{InMempool: true}
+	}
+	return txid, nil
+}
+
+func (s *fakeBackend) TxStatus(_ context.Context, txid string) (*TxStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	if status, ok := s.statuses[txid]; ok {
+		copied := *status
+		return &copied, nil
+	}
+	return &TxStatus{}, nil
+}
+
+func (s *fakeBackend) AddressTxIDs(_ context.Context, address string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]string(nil), s.history[address]...), nil
+}
+
+func (s *fakeBackend) TestMempoolAccept(_ context.Context, _ []byte) (*MempoolAcceptResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &MempoolAcceptResult{Allowed: s.reject == "", RejectReason: s.reject}, nil
+}
+
+func (s *fakeBackend) MempoolEntries(_ context.Context) ([]MempoolEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]MempoolEntry(nil), s.mempool...), nil
+}
+
+func (s *fakeBackend) AddressUTXOs(_ context.Context, address string) ([]UTXO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]UTXO(nil), s.utxos[address]...), nil
+}
+
+func (s *fakeBackend) AddressBalance(_ context.Context, address string) (*Balance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	balance := s.balances[address]
+	return &balance, nil
+}
+
+func (s *fakeBackend) EstimateFeeRate(_ context.Context, _ int) (FeeRate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.feeRate, nil
+}
+
+func (s *fakeBackend) setBalance(address string, confirmed, unconfirmed Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[address] = Balance{Confirmed: confirmed, Unconfirmed: unconfirmed}
+}
+
+func (s *fakeBackend) addUTXOs(address string, utxos ...UTXO) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.utxos[address] = append(s.utxos[address], utxos...)
+}
+
+func (s *fakeBackend) addHistory(address string, txids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[address] = append(s.history[address], txids...)
+}
+
+func (s *fakeBackend) setStatus(txid string, status *TxStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[txid] = status
+}
+
+func (s *fakeBackend) broadcastCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.broadcasts)
+}
+
+func Test_TxStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    TxStatus
+		confirmed bool
+		known     bool
+	}{
+		{"Unknown", TxStatus{}, false, false},
+		{"Mempool", TxStatus{InMempool: true}, false, true},
+		{"Confirmed", TxStatus{Confirmations: 3, BlockHeight: 100}, true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.confirmed, test.status.Confirmed())
+			assert.Equal(t, test.known, test.status.Known())
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package p2pkh
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ErrOutputDuplicate Error = "address is already paid by the transaction"
+	ErrBatchLimit      Error = "transaction exceeds the batch limits"
+)
+
+// BatchLimits bounds a transaction paying many recipients, see
+// WithBatchLimits. Zero values select defaults.
+type BatchLimits struct {
+	// MaxOutputs and MaxVSize bound the size of the transaction, by default
+	// 250 outputs, change included, and 100000 vB, the largest standard
+	// transaction.
+	MaxOutputs int
+	MaxVSize   int64
+	// MaxFee bounds the fee of the transaction. It defaults to no bound
+	// beyond the maximum fee of WithMaxFee.
+	MaxFee Amount
+}
+
+// AddOutputs pays several recipients at once, e.g. a payroll, each address
+// of outputs its amount. The outputs are appended in the order of their
+// addresses, so that the same recipients always build the same transaction.
+// Addresses of another network, addresses the transaction already pays and
+// amounts that are not positive are reported when the transaction is built.
+func (s *TxBuilder) AddOutputs(outputs map[string]Amount) *TxBuilder {
+	addresses := make([]string, 0, len(outputs))
+	for address := range outputs {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	paid := make(map[string]bool, len(s.outputs)+len(addresses))
+	for _, out := range s.outputs {
+		paid[out.Address] = true
+	}
+	for _, address := range addresses {
+		if err := s.checkOutput(address, outputs[address], paid); err != nil {
+			if s.err == nil {
+				s.err = err
+			}
+			return s
+		}
+	}
+	for _, address := range addresses {
+		s.outputs = append(s.outputs, DraftOutput{Address: address, Amount: outputs[address]})
+	}
+	return s
+}
+
+// WithBIP69 sorts the inputs and outputs of the transaction as BIP69
+// specifies, the inputs by outpoint and the outputs by amount then script,
+// so that their order reveals nothing, e.g. which output is the change.
+func (s *TxBuilder) WithBIP69() *TxBuilder {
+	s.bip69 = true
+	return s
+}
+
+// WithBatchLimits bounds the size and fee of the transaction, which is then
+// rejected with ErrBatchLimit beyond them, e.g. to split a payroll into
+// standard transactions.
+func (s *TxBuilder) WithBatchLimits(limits BatchLimits) *TxBuilder {
+	if limits.MaxOutputs <= 0 {
+		limits.MaxOutputs = defaultPayoutMaxOutputs
+	}
+	if limits.MaxVSize <= 0 {
+		limits.MaxVSize = defaultPayoutMaxVSize
+	}
+	s.batchLimits = &limits
+	return s
+}
+
+// checkOutput validates an output added by AddOutputs against the outputs
+// already paid, and records its address.
+func (s *TxBuilder) checkOutput(address string, amount Amount, paid map[string]bool) error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: %s pays %s", ErrDraftInvalidAmount, address, amount)
+	}
+	if _, _, err := s.addressScript(address); err != nil {
+		return fmt.Errorf("%w %q: %w", ErrOutputAddress, address, err)
+	}
+	if paid[address] {
+		return fmt.Errorf("%w: %s", ErrOutputDuplicate, address)
+	}
+	paid[address] = true
+	return nil
+}
+
+// checkBatch checks a draft against the batch limits.
+func (s *TxBuilder) checkBatch(draft *Draft) error {
+	limits := s.batchLimits
+	if len(draft.Outputs) > limits.MaxOutputs {
+		return fmt.Errorf("%w: %d outputs, above %d", ErrBatchLimit, len(draft.Outputs), limits.MaxOutputs)
+	}
+	vsize, err := s.draftVSize(draft)
+	if err != nil {
+		return err
+	}
+	if vsize > limits.MaxVSize {
+		return fmt.Errorf("%w: %d vB, above %d", ErrBatchLimit, vsize, limits.MaxVSize)
+	}
+	if limits.MaxFee > 0 && draft.Fee > limits.MaxFee {
+		return fmt.Errorf("%w: fee %s above %s", ErrBatchLimit, draft.Fee, limits.MaxFee)
+	}
+	return nil
+}
+
+// sortBIP69 sorts the inputs of a draft by txid then output index, and its
+// outputs by amount then scriptPubKey, as BIP69 specifies.
+func (s *TxBuilder) sortBIP69(draft *Draft) error {
+	inputs := append([]DraftInput(nil), draft.Inputs...)
+	sort.SliceStable(inputs, func(i, j int) bool {
+		if txid, other := strings.ToLower(inputs[i].TxID), strings.ToLower(inputs[j].TxID); txid != other {
+			return txid < other
+		}
+		return inputs[i].Vout < inputs[j].Vout
+	})
+
+	scripts := make(map[string][]byte, len(draft.Outputs))
+	for _, out := range draft.Outputs {
+		script, _, err := s.addressScript(out.Address)
+		if err != nil {
+			return err
+		}
+		scripts[out.Address] = script
+	}
+	outputs := append([]DraftOutput(nil), draft.Outputs...)
+	sort.SliceStable(outputs, func(i, j int) bool {
+		if outputs[i].Amount != outputs[j].Amount {
+			return outputs[i].Amount < outputs[j].Amount
+		}
+		return bytes.Compare(scripts[outputs[i].Address], scripts[outputs[j].Address]) < 0
+	})
+	draft.Inputs, draft.Outputs = inputs, outputs
+	return nil
+}
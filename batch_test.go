@@ -0,0 +1,108 @@
+package p2pkh
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRecipients returns count recipients paid from 10000 sats up, at
+// addresses of the receive chain of another wallet.
+func batchRecipients(t *testing.T, count int) map[string]Amount {
+	other, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/1'/0`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	recipients := make(map[string]Amount, count)
+	for i := 0; i < count; i++ {
+		child, err := other.Derive(uint32(i))
+		assert.NoError(t, err)
+		recipients[child.AddressInfo().String()] = Amount(10000 + i)
+	}
+	return recipients
+}
+
+func Test_TxBuilder_AddOutputs(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 5000000)}
+	recipients := batchRecipients(t, 300)
+
+	// The outputs follow the order of the addresses, whatever the map order.
+	var rawTxs [][]byte
+	for i := 0; i < 2; i++ {
+		draft, err := wallet.NewTransaction().AddInput(utxos[0]).AddOutputs(recipients).WithFeeRate(2).Draft()
+		assert.NoError(t, err)
+		assert.Len(t, draft.Outputs, 301)
+		addresses := make([]string, 0, 300)
+		for _, out := range draft.Outputs[:300] {
+			assert.Equal(t, recipients[out.Address], out.Amount)
+			addresses = append(addresses, out.Address)
+		}
+		assert.True(t, sort.StringsAreSorted(addresses))
+		assert.True(t, draft.Outputs[300].Change)
+		rawTx, err := wallet.SignDraft(draft)
+		assert.NoError(t, err)
+		rawTxs = append(rawTxs, rawTx)
+	}
+	assert.Equal(t, rawTxs[0], rawTxs[1])
+	verifyTx(t, rawTxs[0], utxos)
+
+	// The batch limits bound the number of outputs, the size and the fee.
+	_, err := wallet.NewTransaction().AddInput(utxos[0]).AddOutputs(recipients).WithFeeRate(2).
+		WithBatchLimits(BatchLimits{}).Draft()
+	assert.ErrorIs(t, err, ErrBatchLimit)
+	builder := wallet.NewTransaction().AddInput(utxos[0]).AddOutputs(recipients).WithFeeRate(2)
+	vsize, err := builder.VSize()
+	assert.NoError(t, err)
+	_, err = builder.WithBatchLimits(BatchLimits{MaxOutputs: 400, MaxVSize: vsize - 1}).Draft()
+	assert.ErrorIs(t, err, ErrBatchLimit)
+	_, err = builder.WithBatchLimits(BatchLimits{MaxOutputs: 400, MaxFee: 1000}).Draft()
+	assert.ErrorIs(t, err, ErrBatchLimit)
+	draft, err := builder.WithBatchLimits(BatchLimits{MaxOutputs: 400, MaxVSize: vsize}).Draft()
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(2*vsize), draft.Fee)
+
+	// Invalid outputs are reported when the transaction is built.
+	_, err = wallet.NewTransaction().AddInput(utxos[0]).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 10000).
+		AddOutputs(map[string]Amount{"1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A": 20000}).Draft()
+	assert.ErrorIs(t, err, ErrOutputDuplicate)
+	_, err = wallet.NewTransaction().AddInput(utxos[0]).
+		AddOutputs(map[string]Amount{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn": 20000}).Draft()
+	assert.ErrorIs(t, err, ErrOutputAddress)
+	_, err = wallet.NewTransaction().AddInput(utxos[0]).
+		AddOutputs(map[string]Amount{"1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A": 0}).Draft()
+	assert.ErrorIs(t, err, ErrDraftInvalidAmount)
+}
+
+func Test_TxBuilder_WithBIP69(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	first := walletUTXO(t, wallet, 1, 30000)
+	second := walletUTXO(t, wallet, 0, 30000)
+	third := walletUTXO(t, wallet, 0, 30000)
+	third.TxID = "0e53ec5dfb2cb8a71fec32dc9a634a35b7e24799295ddd5278217822e0b31f57"
+	utxos := []UTXO{first, second, third}
+
+	draft, err := wallet.NewTransaction().AddInput(first).AddInput(second).AddInput(third).
+		AddOutputs(map[string]Amount{
+			"1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A": 40000,
+			"1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv": 20000,
+		}).
+		WithFeeRate(5).
+		WithBIP69().
+		Draft()
+	assert.NoError(t, err)
+	assert.Equal(t, []UTXO{third, second, first}, []UTXO{draft.Inputs[0].UTXO, draft.Inputs[1].UTXO, draft.Inputs[2].UTXO})
+	assert.Len(t, draft.Outputs, 3)
+	for i := 1; i < len(draft.Outputs); i++ {
+		assert.LessOrEqual(t, draft.Outputs[i-1].Amount, draft.Outputs[i].Amount)
+	}
+
+	rawTx, err := wallet.SignDraft(draft)
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, []UTXO{third, second, first})
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Len(t, tx.TxIn, len(utxos))
+}
@@ -0,0 +1,185 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrMessageSignature Error = "invalid message signature"
+
+	// bip322Tag is the tag of the BIP322 message hash.
+	bip322Tag = "BIP0322-signed-message"
+)
+
+// bip322MessageHash returns the tagged hash of a BIP322 message.
+func bip322MessageHash(message []byte) *chainhash.Hash {
+	return chainhash.TaggedHash([]byte(bip322Tag), message)
+}
+
+// bip322ToSpend builds the virtual transaction whose output is spent by a
+// BIP322 signature of message for pkScript.
+func bip322ToSpend(pkScript []byte, message []byte) (*wire.MsgTx, error) {
+	sigScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(bip322MessageHash(message)[:]).
+		Script()
+	if err != nil {
+		return nil, err
+	}
+	tx := wire.NewMsgTx(0)
+	in := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), sigScript, nil)
+	in.Sequence = 0
+	tx.AddTxIn(in)
+	tx.AddTxOut(wire.NewTxOut(0, pkScript))
+	return tx, nil
+}
+
+// bip322ToSign builds the virtual transaction carrying a BIP322 signature.
+func bip322ToSign(toSpend *wire.MsgTx) *wire.MsgTx {
+	hash := toSpend.TxHash()
+	tx := wire.NewMsgTx(0)
+	in := wire.NewTxIn(wire.NewOutPoint(&hash, 0), nil, nil)
+	in.Sequence = 0
+	tx.AddTxIn(in)
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	return tx
+}
+
+// signMessageBIP322 signs a message for the P2PKH address of a key, with the
+// BIP322 "full" format: the base64 serialized to_sign transaction.
+func signMessageBIP322(key *btcec.PrivateKey, message string, params *chaincfg.Params) (string, error) {
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(key.PubKey().SerializeCompressed()), params)
+	if err != nil {
+		return "", err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", err
+	}
+	toSpend, err := bip322ToSpend(pkScript, []byte(message))
+	if err != nil {
+		return "", err
+	}
+	toSign := bip322ToSign(toSpend)
+	if toSign.TxIn[0].SignatureScript, err = txscript.SignatureScript(toSign, 0, pkScript, txscript.SigHashAll, key, true); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := toSign.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// SignMessageBIP322 signs a message with the wallet key, proving control of
+// the wallet address. The signature uses the BIP322 "full" format required
+// for P2PKH addresses.
+func (s *Wallet) SignMessageBIP322(message string) (string, error) {
+	key, err := s.extendedKey.ECPrivKey()
+	if err != nil {
+		return "", err
+	}
+	s.logSign("message", "", messageAttr(message))
+	return signMessageBIP322(key, message, s.params)
+}
+
+// VerifyMessageBIP322 verifies a BIP322 signature of a message by an address
+// of the network. Both the "full" format and, for segwit addresses, the
+// "simple" format (a serialized witness) are accepted.
+func VerifyMessageBIP322(address, message, signature string, network Network) error {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return err
+	}
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		return err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMessageSignature, err)
+	}
+
+	toSpend, err := bip322ToSpend(pkScript, []byte(message))
+	if err != nil {
+		return err
+	}
+	expected := bip322ToSign(toSpend)
+	toSign, err := parseBIP322Signature(data, expected)
+	if err != nil {
+		return err
+	}
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	vm, err := txscript.NewEngine(pkScript, toSign, 0, txscript.StandardVerifyFlags,
+		nil, txscript.NewTxSigHashes(toSign, fetcher), 0, fetcher)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMessageSignature, err)
+	}
+	if err := vm.Execute(); err != nil {
+		return fmt.Errorf("%w: %w", ErrMessageSignature, err)
+	}
+	return nil
+}
+
+// parseBIP322Signature decodes a full signature, checking that it is the
+// expected to_sign transaction, or a simple signature, whose witness is set
+// on the expected transaction.
+func parseBIP322Signature(data []byte, expected *wire.MsgTx) (*wire.MsgTx, error) {
+	toSign := wire.NewMsgTx(0)
+	if err := toSign.Deserialize(bytes.NewReader(data)); err == nil {
+		if len(toSign.TxIn) != 1 || len(toSign.TxOut) != 1 ||
+			toSign.Version != expected.Version || toSign.LockTime != expected.LockTime ||
+			toSign.TxIn[0].PreviousOutPoint != expected.TxIn[0].PreviousOutPoint ||
+			toSign.TxIn[0].Sequence != expected.TxIn[0].Sequence ||
+			toSign.TxOut[0].Value != 0 || !bytes.Equal(toSign.TxOut[0].PkScript, expected.TxOut[0].PkScript) {
+			return nil, ErrMessageSignature
+		}
+		return toSign, nil
+	}
+
+	witness, err := readWitness(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMessageSignature, err)
+	}
+	expected.TxIn[0].Witness = witness
+	return expected, nil
+}
+
+// readWitness decodes a serialized witness stack, as in a BIP322 simple signature.
+func readWitness(data []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(data)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(data)) {
+		return nil, ErrMessageSignature
+	}
+	witness := make(wire.TxWitness, 0, count)
+	for i := uint64(0); i < count; i++ {
+		item, err := wire.ReadVarBytes(r, 0, uint32(len(data)), "witness item")
+		if err != nil {
+			return nil, err
+		}
+		witness = append(witness, item)
+	}
+	if r.Len() != 0 {
+		return nil, ErrMessageSignature
+	}
+	return witness, nil
+}
@@ -0,0 +1,39 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BIP322MessageHash(t *testing.T) {
+	assert.Equal(t, "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1",
+		hex.EncodeToString(bip322MessageHash([]byte(""))[:]))
+	assert.Equal(t, "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a",
+		hex.EncodeToString(bip322MessageHash([]byte("Hello World"))[:]))
+}
+
+func Test_VerifyMessageBIP322_Simple(t *testing.T) {
+	// Test vector of BIP322.
+	address := "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	signature := "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	assert.NoError(t, VerifyMessageBIP322(address, "", signature, NetworkMainnet))
+	assert.ErrorIs(t, VerifyMessageBIP322(address, "Hello World", signature, NetworkMainnet), ErrMessageSignature)
+	assert.ErrorIs(t, VerifyMessageBIP322(address, "", "not base64!", NetworkMainnet), ErrMessageSignature)
+}
+
+func Test_SignMessageBIP322(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	signature, err := wallet.SignMessageBIP322("Hello World")
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyMessageBIP322(wallet.AddressHex(), "Hello World", signature, NetworkMainnet))
+
+	assert.ErrorIs(t, VerifyMessageBIP322(wallet.AddressHex(), "Hello", signature, NetworkMainnet), ErrMessageSignature)
+	other, err := wallet.Derive(1)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, VerifyMessageBIP322(other.AddressHex(), "Hello World", signature, NetworkMainnet), ErrMessageSignature)
+
+	testnet := createKnownWallet(t, NetworkTestnet)
+	assert.Error(t, VerifyMessageBIP322(testnet.AddressHex(), "Hello World", signature, NetworkMainnet))
+}
@@ -0,0 +1,156 @@
+package p2pkh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	ErrLabelInvalid Error = "invalid BIP329 label"
+
+	// maxLabelLine bounds the lines of the imported label files.
+	maxLabelLine = 1 << 20
+)
+
+// LabelType is the type of the reference of a BIP329 label.
+type LabelType string
+
+// The BIP329 label types.
+const (
+	LabelTx      LabelType = "tx"
+	LabelAddress LabelType = "addr"
+	LabelPubKey  LabelType = "pubkey"
+	LabelInput   LabelType = "input"
+	LabelOutput  LabelType = "output"
+	LabelXPub    LabelType = "xpub"
+)
+
+// Label is a BIP329 label, the format in which Sparrow, Bitcoin Core and
+// other wallets exchange the labels of transactions, addresses, inputs,
+// outputs and keys.
+type Label struct {
+	Type LabelType `json:"type"`
+	// Ref is the labeled object: a txid, an address, a hex public key, an
+	// outpoint "txid:vout" for inputs and outputs, or an extended public key.
+	Ref   string `json:"ref"`
+	Label string `json:"label,omitempty"`
+	// Origin is the optional abbreviated descriptor of the account the
+	// reference belongs to, e.g. "pkh([d34db33f/44'/0'/0'])".
+	Origin string `json:"origin,omitempty"`
+	// Spendable optionally freezes (false) or unfreezes an output.
+	Spendable *bool `json:"spendable,omitempty"`
+}
+
+// Validate checks the type and reference of the label.
+func (s Label) Validate() error {
+	switch s.Type {
+	case LabelTx:
+		if len(s.Ref) != chainhash.MaxHashStringSize {
+			return fmt.Errorf("%w: txid %q", ErrLabelInvalid, s.Ref)
+		}
+		if _, err := chainhash.NewHashFromStr(s.Ref); err != nil {
+			return fmt.Errorf("%w: %w", ErrLabelInvalid, err)
+		}
+	case LabelInput, LabelOutput:
+		txid, vout, ok := strings.Cut(s.Ref, ":")
+		if _, err := strconv.ParseUint(vout, 10, 32); !ok || err != nil {
+			return fmt.Errorf("%w: outpoint %q", ErrLabelInvalid, s.Ref)
+		}
+		if err := (Label{Type: LabelTx, Ref: txid}).Validate(); err != nil {
+			return err
+		}
+	case LabelAddress, LabelPubKey, LabelXPub:
+		if s.Ref == "" {
+			return fmt.Errorf("%w: empty %s reference", ErrLabelInvalid, s.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrLabelInvalid, s.Type)
+	}
+	if s.Spendable != nil && s.Type != LabelOutput {
+		return fmt.Errorf("%w: spendable applies to outputs only", ErrLabelInvalid)
+	}
+	return nil
+}
+
+// ExportLabels encodes labels in the BIP329 format: one JSON object per
+// line.
+func ExportLabels(labels []Label) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, label := range labels {
+		if err := label.Validate(); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(label)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportLabels decodes labels in the BIP329 format. Blank lines and the
+// labels of types this version does not know are skipped, as BIP329
+// requires, so that files of newer wallets still import.
+func ImportLabels(data []byte) ([]Label, error) {
+	var labels []Label
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, maxLabelLine)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var label Label
+		if err := json.Unmarshal(text, &label); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrLabelInvalid, line, err)
+		}
+		if !knownLabelType(label.Type) {
+			continue
+		}
+		if err := label.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		labels = append(labels, label)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLabelInvalid, err)
+	}
+	return labels, nil
+}
+
+// knownLabelType reports whether a label type is one of BIP329.
+func knownLabelType(labelType LabelType) bool {
+	switch labelType {
+	case LabelTx, LabelAddress, LabelPubKey, LabelInput, LabelOutput, LabelXPub:
+		return true
+	}
+	return false
+}
+
+// LabelOrigin returns the BIP329 origin of the labels of the wallet: the
+// abbreviated descriptor of its account, e.g. "pkh([d34db33f/44'/0'/0'])".
+func (s *Wallet) LabelOrigin() (string, error) {
+	origin, err := s.keyOrigin()
+	if err != nil {
+		return "", err
+	}
+	return formatDescriptor(s.AddressType(), "["+origin.String()+"]")
+}
+
+// AddressLabel returns the BIP329 label of the address of the wallet of the
+// type its path purpose selects, see PurposeAddress, with its origin.
+func (s *Wallet) AddressLabel(label string) (Label, error) {
+	origin, err := s.LabelOrigin()
+	if err != nil {
+		return Label{}, err
+	}
+	return Label{Type: LabelAddress, Ref: s.PurposeAddress().String(), Label: label, Origin: origin}, nil
+}
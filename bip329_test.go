@@ -0,0 +1,104 @@
+package p2pkh
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testLabels are BIP329 labels from the examples of the BIP.
+const testLabels = `{ "type": "tx", "ref": "f91d0a8a78462bc59398f2c5d7a84fcff491c26ba54c4833478b202796c8aafd", "label": "Transaction", "origin": "wpkh([d34db33f/84'/0'/0'])" }
+{ "type": "addr", "ref": "bc1q34aq5drpuwy3wgl9lhup9892qp6svr8ldzyy7c", "label": "Address" }
+{ "type": "pubkey", "ref": "0283409659355b6d1cc3c32decd5d561abaac86c37a353b52895a5e6c196d6f448", "label": "Public Key" }
+{ "type": "input", "ref": "f91d0a8a78462bc59398f2c5d7a84fcff491c26ba54c4833478b202796c8aafd:0", "label": "Input" }
+
+{ "type": "output", "ref": "f91d0a8a78462bc59398f2c5d7a84fcff491c26ba54c4833478b202796c8aafd:1", "label": "Output" , "spendable" : false }
+{ "type": "xpub", "ref": "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8", "label": "Extended Public Key" }
+{ "type": "future", "ref": "unknown", "label": "Skipped" }
+`
+
+func Test_ImportLabels(t *testing.T) {
+	labels, err := ImportLabels([]byte(testLabels))
+	assert.NoError(t, err)
+	assert.Len(t, labels, 6)
+	assert.Equal(t, Label{
+		Type:   LabelTx,
+		Ref:    "f91d0a8a78462bc59398f2c5d7a84fcff491c26ba54c4833478b202796c8aafd",
+		Label:  "Transaction",
+		Origin: "wpkh([d34db33f/84'/0'/0'])",
+	}, labels[0])
+	assert.Equal(t, LabelOutput, labels[4].Type)
+	assert.NotNil(t, labels[4].Spendable)
+	assert.False(t, *labels[4].Spendable)
+
+	// Exported labels import back.
+	data, err := ExportLabels(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, strings.Count(string(data), "\n"))
+	again, err := ImportLabels(data)
+	assert.NoError(t, err)
+	assert.Equal(t, labels, again)
+
+	for _, line := range []string{
+		`{"type": "tx", "ref": "f91d", "label": "short txid"}`,
+		`{"type": "input", "ref": "f91d0a8a78462bc59398f2c5d7a84fcff491c26ba54c4833478b202796c8aafd", "label": "no vout"}`,
+		`{"type": "addr", "ref": "", "label": "no ref"}`,
+		`{"type": "addr", "ref": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "spendable": true}`,
+		`not json`,
+	} {
+		_, err := ImportLabels([]byte(line))
+		assert.ErrorIs(t, err, ErrLabelInvalid, line)
+	}
+	_, err = ExportLabels([]Label{{Type: "future", Ref: "x"}})
+	assert.ErrorIs(t, err, ErrLabelInvalid)
+}
+
+func Test_AddressLabel(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(7)
+	assert.NoError(t, err)
+	fingerprint, err := wallet.masterFingerprint()
+	assert.NoError(t, err)
+
+	label, err := child.AddressLabel("donations")
+	assert.NoError(t, err)
+	assert.Equal(t, LabelAddress, label.Type)
+	assert.Equal(t, child.AddressInfo().String(), label.Ref)
+	assert.Equal(t, "donations", label.Label)
+	assert.Equal(t, fmt.Sprintf("pkh([%08x/44'/0'/0'])", fingerprint), label.Origin)
+}
+
+func Test_Manager_Labels(t *testing.T) {
+	fastKeystoreScrypt(t)
+	manager := NewManager(nil)
+	assert.NoError(t, manager.Create("main", createKnownWallet(t, NetworkMainnet), "password"))
+
+	count, err := manager.ImportLabels("main", []byte(testLabels))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+	// Importing again replaces the labels of the same references, and inputs
+	// and outputs of the same outpoint are distinct.
+	count, err = manager.ImportLabels("main", []byte(`{"type": "input", "ref": "f91d0a8a78462bc59398f2c5d7a84fcff491c26ba54c4833478b202796c8aafd:1", "label": "Spent"}
+{"type": "tx", "ref": "f91d0a8a78462bc59398f2c5d7a84fcff491c26ba54c4833478b202796c8aafd", "label": "Renamed"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	labels, err := manager.Labels("main")
+	assert.NoError(t, err)
+	assert.Len(t, labels, 7)
+	assert.Equal(t, "Renamed", labels[0].Label)
+	assert.Equal(t, "Output", labels[4].Label)
+	assert.Equal(t, "Spent", labels[6].Label)
+
+	data, err := manager.ExportLabels("main")
+	assert.NoError(t, err)
+	exported, err := ImportLabels(data)
+	assert.NoError(t, err)
+	assert.Equal(t, labels, exported)
+
+	_, err = manager.ImportLabels("main", []byte("{"))
+	assert.ErrorIs(t, err, ErrLabelInvalid)
+	_, err = manager.ExportLabels("missing")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
@@ -0,0 +1,141 @@
+package p2pkh
+
+import (
+	"bytes"
+	"crypto/aes"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	ErrBIP38Invalid    Error = "invalid BIP38 encrypted key"
+	ErrBIP38Passphrase Error = "invalid BIP38 passphrase"
+	ErrBIP38ECMultiply Error = "EC-multiplied BIP38 keys are not supported"
+
+	bip38KeyLen     = 39
+	bip38FlagNoEC   = 0xc0
+	bip38Compressed = 0x20
+)
+
+// bip38Prefix is the prefix of non EC-multiplied BIP38 keys, "6P" in base58.
+var bip38Prefix = []byte{0x01, 0x42}
+
+// bip38DerivedKey derives the key encrypting a private key from a passphrase
+// and the checksum of its address. Passphrases should be NFC normalized.
+func bip38DerivedKey(passphrase string, addressHash []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), addressHash, 16384, 8, 8, 64)
+}
+
+// bip38AddressHash returns the first 4 bytes of the double SHA256 of the P2PKH
+// address of a key.
+func bip38AddressHash(publicKey *btcec.PublicKey, compressed bool, params *chaincfg.Params) ([]byte, error) {
+	serialized := publicKey.SerializeUncompressed()
+	if compressed {
+		serialized = publicKey.SerializeCompressed()
+	}
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(serialized), params)
+	if err != nil {
+		return nil, err
+	}
+	return chainhash.DoubleHashB([]byte(addr.EncodeAddress()))[:4], nil
+}
+
+// EncryptBIP38 encrypts a private key with a passphrase, as a BIP38 "6P..."
+// string suitable for paper wallets.
+func EncryptBIP38(wif *btcutil.WIF, passphrase string, network Network) (string, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return "", err
+	}
+	addressHash, err := bip38AddressHash(wif.PrivKey.PubKey(), wif.CompressPubKey, params)
+	if err != nil {
+		return "", err
+	}
+	derived, err := bip38DerivedKey(passphrase, addressHash)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(derived[32:])
+	if err != nil {
+		return "", err
+	}
+
+	key := wif.PrivKey.Serialize()
+	encrypted := make([]byte, 32)
+	for i := range key {
+		key[i] ^= derived[i]
+	}
+	block.Encrypt(encrypted[:16], key[:16])
+	block.Encrypt(encrypted[16:], key[16:])
+
+	flag := byte(bip38FlagNoEC)
+	if wif.CompressPubKey {
+		flag |= bip38Compressed
+	}
+	data := append(append([]byte(nil), bip38Prefix...), flag)
+	data = append(append(data, addressHash...), encrypted...)
+	return base58.Encode(append(data, chainhash.DoubleHashB(data)[:4]...)), nil
+}
+
+// PrivateKeyBIP38 returns the private key of the wallet, flagged with the
+// compression of its public key as PrivateKey, encrypted with a passphrase as
+// a BIP38 "6P..." string. It is decrypted with DecryptBIP38.
+func (s *Wallet) PrivateKeyBIP38(passphrase string) (string, error) {
+	wif, err := s.wif(!s.uncompressed)
+	if err != nil {
+		return "", err
+	}
+	return EncryptBIP38(wif, passphrase, s.network)
+}
+
+// DecryptBIP38 decrypts a BIP38 encrypted private key.
+func DecryptBIP38(encrypted, passphrase string, network Network) (*btcutil.WIF, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	data := base58.Decode(encrypted)
+	if len(data) != bip38KeyLen+4 ||
+		!bytes.Equal(chainhash.DoubleHashB(data[:bip38KeyLen])[:4], data[bip38KeyLen:]) {
+		return nil, ErrBIP38Invalid
+	}
+	if data[0] == 0x01 && data[1] == 0x43 {
+		return nil, ErrBIP38ECMultiply
+	}
+	flag := data[2]
+	if !bytes.Equal(data[:2], bip38Prefix) || flag&bip38FlagNoEC != bip38FlagNoEC {
+		return nil, ErrBIP38Invalid
+	}
+	addressHash := data[3:7]
+
+	derived, err := bip38DerivedKey(passphrase, addressHash)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived[32:])
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	block.Decrypt(key[:16], data[7:23])
+	block.Decrypt(key[16:], data[23:39])
+	for i := range key {
+		key[i] ^= derived[i]
+	}
+
+	privateKey, _ := btcec.PrivKeyFromBytes(key)
+	compressed := flag&bip38Compressed != 0
+	check, err := bip38AddressHash(privateKey.PubKey(), compressed, params)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(check, addressHash) {
+		return nil, ErrBIP38Passphrase
+	}
+	return btcutil.NewWIF(privateKey, params, compressed)
+}
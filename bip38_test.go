@@ -0,0 +1,70 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BIP38(t *testing.T) {
+	// Test vectors of BIP38, without EC multiplication.
+	tests := []struct {
+		name      string
+		wif       string
+		encrypted string
+	}{
+		{"Uncompressed", "5KN7MzqK5wt2TP1fQCYyHBtDrXdJuXbUzm4A9rKAteGu3Qi5CVR", "6PRVWUbkzzsbcVac2qwfssoUJAN1Xhrg6bNk8J7Nzm5H7kxEbn2Nh2ZoGg"},
+		{"Compressed", "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP", "6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wif, err := btcutil.DecodeWIF(test.wif)
+			assert.NoError(t, err)
+			encrypted, err := EncryptBIP38(wif, "TestingOneTwoThree", NetworkMainnet)
+			assert.NoError(t, err)
+			assert.Equal(t, test.encrypted, encrypted)
+
+			decrypted, err := DecryptBIP38(test.encrypted, "TestingOneTwoThree", NetworkMainnet)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wif, decrypted.String())
+
+			_, err = DecryptBIP38(test.encrypted, "wrong", NetworkMainnet)
+			assert.ErrorIs(t, err, ErrBIP38Passphrase)
+		})
+	}
+
+	_, err := DecryptBIP38("6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeA", "TestingOneTwoThree", NetworkMainnet)
+	assert.ErrorIs(t, err, ErrBIP38Invalid)
+	_, err = DecryptBIP38("6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX", "TestingOneTwoThree", NetworkMainnet)
+	assert.ErrorIs(t, err, ErrBIP38ECMultiply)
+}
+
+func Test_PrivateKeyBIP38(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkTestnet)
+	encrypted, err := wallet.PrivateKeyBIP38("TestingOneTwoThree")
+	assert.NoError(t, err)
+	assert.Equal(t, "6P", encrypted[:2])
+
+	decrypted, err := DecryptBIP38(encrypted, "TestingOneTwoThree", NetworkTestnet)
+	assert.NoError(t, err)
+	wif, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, wif, decrypted.String())
+
+	// The key of an uncompressed wallet imports as its address.
+	uncompressed, err := NewWallet(testMnemonic, WithUncompressedKeys())
+	assert.NoError(t, err)
+	encrypted, err = uncompressed.PrivateKeyBIP38("TestingOneTwoThree")
+	assert.NoError(t, err)
+	decrypted, err = DecryptBIP38(encrypted, "TestingOneTwoThree", NetworkMainnet)
+	assert.NoError(t, err)
+	assert.False(t, decrypted.CompressPubKey)
+	assert.NoError(t, uncompressed.ValidateWIF(decrypted.String()))
+
+	watch, err := NewFromExtendedPublicKey(wallet.root.String(), NetworkTestnet)
+	assert.NoError(t, err)
+	_, err = watch.PrivateKeyBIP38("TestingOneTwoThree")
+	assert.ErrorIs(t, err, ErrWatchOnly)
+}
@@ -0,0 +1,86 @@
+package p2pkh
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const (
+	ErrBIP85Master Error = "BIP85 derivation requires the master private key"
+	ErrBIP85Path   Error = "BIP85 path must be fully hardened below m/83696968'"
+	ErrBIP85Words  Error = "BIP85 mnemonics have 12, 18 or 24 words"
+
+	// bip85Purpose is the purpose level of the BIP85 paths.
+	bip85Purpose = 83696968
+	// bip85BIP39App is the application number of the BIP39 mnemonics.
+	bip85BIP39App = 39
+	// bip85English is the language code of the English word list.
+	bip85English = 0
+	// bip85HMACKey is the HMAC-SHA512 key turning a derived private key into
+	// entropy.
+	bip85HMACKey = "bip-entropy-from-k"
+)
+
+// DeriveBIP85Mnemonic derives from the master key of the wallet the English
+// mnemonic of 12, 18 or 24 words at an index, following BIP85: each index
+// yields an independent mnemonic seeding the wallet of another application,
+// so that the backup of the master mnemonic restores them all. The child
+// mnemonics reveal nothing about the master key.
+func (s *Wallet) DeriveBIP85Mnemonic(index uint32, words int) (string, error) {
+	if words != 12 && words != 18 && words != 24 {
+		return "", ErrBIP85Words
+	}
+	if index >= hdkeychain.HardenedKeyStart {
+		return "", ErrIndexRange
+	}
+	path := fmt.Sprintf("m/%d'/%d'/%d'/%d'/%d'", bip85Purpose, bip85BIP39App, bip85English, words, index)
+	entropy, err := s.DeriveBIP85Entropy(path)
+	if err != nil {
+		return "", err
+	}
+	defer zero(entropy)
+	// 12 words encode 16 bytes of entropy, 24 words 32 bytes.
+	return mnemonicFromEntropy(entropy[:words*4/3], LanguageEnglish)
+}
+
+// DeriveBIP85Entropy returns the 64 bytes of BIP85 entropy of a path of the
+// master key, e.g. m/83696968'/128169'/32'/0' for the hex application, for
+// the BIP85 applications DeriveBIP85Mnemonic does not cover.
+func (s *Wallet) DeriveBIP85Entropy(path string) ([]byte, error) {
+	levels, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(levels) < 2 || levels[purposeLevel] != hdkeychain.HardenedKeyStart+bip85Purpose {
+		return nil, ErrBIP85Path
+	}
+	for _, level := range levels {
+		if level < hdkeychain.HardenedKeyStart {
+			return nil, ErrBIP85Path
+		}
+	}
+	if s.root == nil || s.origin != nil || s.root.Depth() != 0 || !s.root.IsPrivate() {
+		return nil, ErrBIP85Master
+	}
+
+	key := s.root
+	for _, level := range levels {
+		if key, err = deriveKey(key, level); err != nil {
+			return nil, err
+		}
+	}
+	privateKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Zero()
+	k := privateKey.Key.Bytes()
+	defer zero(k[:])
+
+	mac := hmac.New(sha512.New, []byte(bip85HMACKey))
+	mac.Write(k[:])
+	return mac.Sum(nil), nil
+}
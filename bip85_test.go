@@ -0,0 +1,71 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bip85Master is the master key of the BIP85 test vectors.
+const bip85Master = "xprv9s21ZrQH143K2LBWUUQRFXhucrQqBpKdRRxNVq2zBqsx8HVqFk2uYo8kmbaLLHRdqtQpUm98uKfu3vca1LqdGhUtyoFnCNkfmXRyPXLjbKb"
+
+func Test_DeriveBIP85Entropy(t *testing.T) {
+	wallet, err := NewFromExtendedPrivateKey(bip85Master, NetworkMainnet)
+	assert.NoError(t, err)
+
+	entropy, err := wallet.DeriveBIP85Entropy("m/83696968'/0'/0'")
+	assert.NoError(t, err)
+	assert.Equal(t, "efecfbccffea313214232d29e71563d941229afb4338c21f9517c41aaa0d16f00b83d2a09ef747e7a64e8e2bd5a14869e693da66ce94ac2da570ab7ee48618f7", hex.EncodeToString(entropy))
+	entropy, err = wallet.DeriveBIP85Entropy("m/83696968'/0'/1'")
+	assert.NoError(t, err)
+	assert.Equal(t, "70c6e3e8ebee8dc4c0dbba66076819bb8c09672527c4277ca8729532ad711872218f826919f6b67218adde99018a6df9095ab2b58d803b5b93ec9802085a690e", hex.EncodeToString(entropy))
+
+	_, err = wallet.DeriveBIP85Entropy("m/44'/0'/0'")
+	assert.ErrorIs(t, err, ErrBIP85Path)
+	_, err = wallet.DeriveBIP85Entropy("m/83696968'/0'/0")
+	assert.ErrorIs(t, err, ErrBIP85Path)
+}
+
+func Test_DeriveBIP85Mnemonic(t *testing.T) {
+	wallet, err := NewFromExtendedPrivateKey(bip85Master, NetworkMainnet)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		words    int
+		mnemonic string
+	}{
+		{12, "girl mad pet galaxy egg matter matrix prison refuse sense ordinary nose"},
+		{18, "near account window bike charge season chef number sketch tomorrow excuse sniff circle vital hockey outdoor supply token"},
+		{24, "puppy ocean match cereal symbol another shed magic wrap hammer bulb intact gadget divorce twin tonight reason outdoor destroy simple truth cigar social volcano"},
+	}
+	for _, test := range tests {
+		mnemonic, err := wallet.DeriveBIP85Mnemonic(0, test.words)
+		assert.NoError(t, err)
+		assert.Equal(t, test.mnemonic, mnemonic)
+	}
+
+	// Each index yields another mnemonic, which seeds a wallet.
+	other, err := wallet.DeriveBIP85Mnemonic(1, 12)
+	assert.NoError(t, err)
+	assert.NotEqual(t, tests[0].mnemonic, other)
+	_, err = New(&Config{Mnemonic: other, Network: NetworkMainnet})
+	assert.NoError(t, err)
+
+	_, err = wallet.DeriveBIP85Mnemonic(0, 15)
+	assert.ErrorIs(t, err, ErrBIP85Words)
+
+	// Only the master private key derives BIP85 entropy.
+	account, err := NewAccount(createKnownWallet(t, NetworkMainnet))
+	assert.NoError(t, err)
+	xprv, err := account.Wallet().ExtendedPrivateKey()
+	assert.NoError(t, err)
+	accountWallet, err := NewFromExtendedPrivateKey(xprv, NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = accountWallet.DeriveBIP85Mnemonic(0, 12)
+	assert.ErrorIs(t, err, ErrBIP85Master)
+	watch, err := wallet.Neuter()
+	assert.NoError(t, err)
+	_, err = watch.DeriveBIP85Mnemonic(0, 12)
+	assert.ErrorIs(t, err, ErrBIP85Master)
+}
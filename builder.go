@@ -0,0 +1,144 @@
+package p2pkh
+
+import (
+	"log/slog"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// WalletBuilder composes the options of a Wallet. Wallets being immutable,
+// changing the path, network or mnemonic of a wallet means building a new
+// one, e.g. wallet.Builder().WithPath(path).Build(). A builder is not safe
+// for concurrent use.
+//
+// A builder keeps the master key of the last mnemonic it built, so that
+// building wallets at many paths of one mnemonic, e.g. for a load test,
+// stretches its seed once: the PBKDF2 of BIP39 dominates the cost of New.
+type WalletBuilder struct {
+	config Config
+	master *builderMaster
+}
+
+// builderMaster is the master key of a mnemonic, passphrase and network.
+type builderMaster struct {
+	mnemonic   string
+	passphrase string
+	network    Network
+	key        *hdkeychain.ExtendedKey
+}
+
+// NewWalletBuilder returns an empty builder.
+func NewWalletBuilder() *WalletBuilder {
+	return &WalletBuilder{}
+}
+
+// Builder returns a builder initialized with the mnemonic, passphrase, path,
+// network, backend, logger and key compression of the wallet. Building requires a
+// mnemonic, which watch-only and imported wallets do not have.
+func (s *Wallet) Builder() *WalletBuilder {
+	var master *builderMaster
+	if s.mnemonic != "" && s.origin == nil && s.root != nil && s.root.Depth() == 0 {
+		master = &builderMaster{mnemonic: s.mnemonic, passphrase: s.passphrase, network: s.network, key: s.root}
+	}
+	return &WalletBuilder{master: master, config: Config{
+		Mnemonic:         s.mnemonic,
+		Passphrase:       s.passphrase,
+		Path:             s.path,
+		Network:          s.network,
+		Backend:          s.backend,
+		UncompressedKeys: s.uncompressed,
+		Logger:           s.logger,
+		LowRSignatures:   s.lowR,
+	}}
+}
+
+// WithMnemonic sets the BIP39 mnemonic of the wallet.
+func (s *WalletBuilder) WithMnemonic(mnemonic string) *WalletBuilder {
+	s.config.Mnemonic = mnemonic
+	return s
+}
+
+// WithPassphrase sets the BIP39 passphrase of the mnemonic.
+func (s *WalletBuilder) WithPassphrase(passphrase string) *WalletBuilder {
+	s.config.Passphrase = passphrase
+	return s
+}
+
+// WithPath sets the derivation path of the wallet; empty selects the
+// default path of the network.
+func (s *WalletBuilder) WithPath(path string) *WalletBuilder {
+	s.config.Path = path
+	return s
+}
+
+// WithPurpose sets the purpose of the wallet, which selects its default path
+// and the type of its PurposeAddress.
+func (s *WalletBuilder) WithPurpose(purpose Purpose) *WalletBuilder {
+	s.config.Purpose = purpose
+	return s
+}
+
+// WithCompletePath completes account-level paths to their external chain.
+func (s *WalletBuilder) WithCompletePath(complete bool) *WalletBuilder {
+	s.config.CompletePath = complete
+	return s
+}
+
+// WithNetwork sets the network of the wallet.
+func (s *WalletBuilder) WithNetwork(network Network) *WalletBuilder {
+	s.config.Network = network
+	return s
+}
+
+// WithBackend sets the chain backend of the wallet.
+func (s *WalletBuilder) WithBackend(backend ChainBackend) *WalletBuilder {
+	s.config.Backend = backend
+	return s
+}
+
+// WithLogger sets the logger receiving the events of the wallet.
+func (s *WalletBuilder) WithLogger(logger *slog.Logger) *WalletBuilder {
+	s.config.Logger = logger
+	return s
+}
+
+// WithLowRSignatures grinds the signatures of the wallet to a low R value.
+func (s *WalletBuilder) WithLowRSignatures(lowR bool) *WalletBuilder {
+	s.config.LowRSignatures = lowR
+	return s
+}
+
+// WithUncompressedKeys derives the P2PKH addresses of the wallet from its
+// uncompressed public keys.
+func (s *WalletBuilder) WithUncompressedKeys(uncompressed bool) *WalletBuilder {
+	s.config.UncompressedKeys = uncompressed
+	return s
+}
+
+// Build creates the wallet. The builder can be reused to build more wallets.
+func (s *WalletBuilder) Build() (*Wallet, error) {
+	config := s.config
+	master := s.master
+	if master == nil || master.mnemonic != config.Mnemonic || master.passphrase != config.Passphrase ||
+		master.network != config.Network {
+		wallet, err := New(&config)
+		if err != nil {
+			return nil, err
+		}
+		s.master = &builderMaster{mnemonic: config.Mnemonic, passphrase: config.Passphrase, network: config.Network, key: wallet.root}
+		return wallet, nil
+	}
+
+	if config.WipeOnCollect {
+		zeroOnCollect(master.key)
+	}
+	wallet, err := newFromMasterKey(master.key, &config)
+	if err != nil {
+		return nil, err
+	}
+	if !config.ForgetMnemonic {
+		wallet.mnemonic = config.Mnemonic
+		wallet.passphrase = config.Passphrase
+	}
+	return wallet, nil
+}
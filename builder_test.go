@@ -0,0 +1,111 @@
+package p2pkh
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WalletBuilder(t *testing.T) {
+	wallet, err := NewWalletBuilder().WithMnemonic(testMnemonic).WithNetwork(NetworkMainnet).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, createKnownWallet(t, NetworkMainnet).AddressHex(), wallet.AddressHex())
+
+	// Variants are new wallets, the original one is unchanged.
+	builder := wallet.Builder().WithPath(`m/44'/0'/1'/0`)
+	variant, err := builder.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/1'/0`, variant.Path())
+	assert.Equal(t, `m/44'/0'/0'/0`, wallet.Path())
+	assert.NotEqual(t, wallet.AddressHex(), variant.AddressHex())
+
+	testnet, err := builder.WithNetwork(NetworkTestnet).WithPath("").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/1'/0'/0`, testnet.Path())
+
+	_, err = NewWalletBuilder().WithNetwork(NetworkMainnet).Build()
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+}
+
+func Test_New_ConfigUntouched(t *testing.T) {
+	config := &Config{Mnemonic: testMnemonic, Network: NetworkMainnet}
+	_, err := New(config)
+	assert.NoError(t, err)
+	assert.Empty(t, config.Path)
+}
+
+func Test_Wallet_Concurrent(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	expected := wallet.AddressHex()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child, err := wallet.Derive(i)
+			assert.NoError(t, err)
+			assert.Same(t, wallet, child.Parent())
+			_, err = wallet.PrivateKey()
+			assert.NoError(t, err)
+			_, err = wallet.ExtendedPublicKey()
+			assert.NoError(t, err)
+			assert.Equal(t, expected, wallet.AddressInfo().String())
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_Wallet_AddressCopy(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	wallet.Address().SetFormat(btcutil.PKFUncompressed)
+	assert.Equal(t, btcutil.PKFCompressed, wallet.Address().Format())
+}
+
+func Test_WalletBuilder_ReusesMasterKey(t *testing.T) {
+	builder := NewWalletBuilder().WithMnemonic(testMnemonic).WithNetwork(NetworkMainnet)
+	first, err := builder.Build()
+	assert.NoError(t, err)
+	second, err := builder.WithPath(`m/84'/0'/0'/0`).Build()
+	assert.NoError(t, err)
+	assert.Same(t, first.root, second.root)
+	expected, err := New(&Config{Mnemonic: testMnemonic, Path: `m/84'/0'/0'/0`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, expected.AddressHex(), second.AddressHex())
+	assert.Equal(t, testMnemonic, second.Mnemonic())
+
+	// Another passphrase or network derives another master key.
+	third, err := builder.WithPassphrase("TREZOR").Build()
+	assert.NoError(t, err)
+	assert.NotSame(t, first.root, third.root)
+	assert.NotEqual(t, second.AddressHex(), third.AddressHex())
+	fourth, err := builder.WithPassphrase("").WithNetwork(NetworkTestnet).WithPath("").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, createKnownWallet(t, NetworkTestnet).AddressHex(), fourth.AddressHex())
+
+	// The builder of a wallet starts with its master key.
+	fifth, err := first.Builder().WithPath(`m/44'/0'/1'/0`).Build()
+	assert.NoError(t, err)
+	assert.Same(t, first.root, fifth.root)
+}
+
+func BenchmarkNew(b *testing.B) {
+	config := &Config{Mnemonic: testMnemonic, Network: NetworkMainnet}
+	for i := 0; i < b.N; i++ {
+		if _, err := New(config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWalletBuilder_Build(b *testing.B) {
+	builder := NewWalletBuilder().WithMnemonic(testMnemonic).WithNetwork(NetworkMainnet)
+	for i := 0; i < b.N; i++ {
+		if _, err := builder.WithPath(fmt.Sprintf(`m/44'/0'/%d'/0`, i%1000)).Build(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package p2pkh
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+)
+
+const (
+	ErrBytewordsInvalid  Error = "invalid bytewords"
+	ErrBytewordsChecksum Error = "invalid bytewords checksum"
+)
+
+// bytewordsList is the concatenation of the 256 four letters bytewords
+// (BCR-2020-012), in byte order.
+const bytewordsList = "" +
+	"ableacidalsoapexaquaarchatomauntawayaxisbackbaldbarnbeltbetabias" +
+	"bluebodybragbrewbulbbuzzcalmcashcatschefcityclawcodecolacookcost" +
+	"cruxcurlcuspcyandarkdatadaysdelidicedietdoordowndrawdropdrumdull" +
+	"dutyeacheasyechoedgeepicevenexamexiteyesfactfairfernfigsfilmfish" +
+	"fizzflapflewfluxfoxyfreefrogfuelfundgalagamegeargemsgiftgirlglow" +
+	"goodgraygrimgurugushgyrohalfhanghardhawkheathelphighhillholyhope" +
+	"hornhutsicedideaidleinchinkyintoirisironitemjadejazzjoinjoltjowl" +
+	"judojugsjumpjunkjurykeepkenokeptkeyskickkilnkingkitekiwiknoblamb" +
+	"lavalazyleaflegsliarlimplionlistlogoloudloveluaulucklungmainmany" +
+	"mathmazememomenumeowmildmintmissmonknailnavyneednewsnextnoonnote" +
+	"numbobeyoboeomitonyxopenovalowlspaidpartpeckplaypluspoempoolpose" +
+	"puffpumapurrquadquizraceramprealredorichroadrockroofrubyruinruns" +
+	"rustsafesagascarsetssilkskewslotsoapsolosongstubsurfswantacotask" +
+	"taxitenttiedtimetinytoiltombtoystriptunatwinuglyundouniturgeuser" +
+	"vastveryvetovialvibeviewvisavoidvowswallwandwarmwaspwavewaxywebs" +
+	"whatwhenwhizwolfworkyankyawnyellyogayurtzapszerozestzinczonezoom"
+
+// bytewordsMinimal maps the first and last letters of each byteword to its byte.
+var bytewordsMinimal = func() map[string]byte {
+	m := make(map[string]byte, 256)
+	for i := 0; i < 256; i++ {
+		word := bytewordsList[i*4 : i*4+4]
+		m[word[:1]+word[3:]] = byte(i)
+	}
+	return m
+}()
+
+// encodeBytewordsMinimal encodes data followed by its CRC32 checksum using the
+// minimal bytewords style (first and last letters of each word), as in URs.
+func encodeBytewordsMinimal(data []byte) string {
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(data))
+
+	var b strings.Builder
+	b.Grow((len(data) + 4) * 2)
+	for _, c := range append(append([]byte(nil), data...), checksum...) {
+		word := bytewordsList[int(c)*4 : int(c)*4+4]
+		b.WriteByte(word[0])
+		b.WriteByte(word[3])
+	}
+	return b.String()
+}
+
+// decodeBytewordsMinimal decodes a minimal bytewords string and verifies its
+// trailing CRC32 checksum. Decoding is case insensitive.
+func decodeBytewordsMinimal(s string) ([]byte, error) {
+	s = strings.ToLower(s)
+	if len(s)%2 != 0 || len(s) < 8 {
+		return nil, ErrBytewordsInvalid
+	}
+
+	data := make([]byte, 0, len(s)/2)
+	for i := 0; i < len(s); i += 2 {
+		c, ok := bytewordsMinimal[s[i:i+2]]
+		if !ok {
+			return nil, ErrBytewordsInvalid
+		}
+		data = append(data, c)
+	}
+
+	body, checksum := data[:len(data)-4], data[len(data)-4:]
+	if binary.BigEndian.Uint32(checksum) != crc32.ChecksumIEEE(body) {
+		return nil, ErrBytewordsChecksum
+	}
+	return body, nil
+}
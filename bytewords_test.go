@@ -0,0 +1,26 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Bytewords(t *testing.T) {
+	data := []byte{0, 1, 2, 128, 255}
+	encoded := encodeBytewordsMinimal(data)
+	assert.Equal(t, "aeadaolazmjendeoti", encoded)
+
+	decoded, err := decodeBytewordsMinimal(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	_, err = decodeBytewordsMinimal("aeadaolazmjendeota")
+	assert.ErrorIs(t, err, ErrBytewordsChecksum)
+
+	_, err = decodeBytewordsMinimal("aeadao")
+	assert.ErrorIs(t, err, ErrBytewordsInvalid)
+
+	_, err = decodeBytewordsMinimal("xxadaolazmjendeoti")
+	assert.ErrorIs(t, err, ErrBytewordsInvalid)
+}
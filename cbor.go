@@ -0,0 +1,235 @@
+package p2pkh
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+const (
+	ErrCBORTruncated   Error = "truncated CBOR data"
+	ErrCBORUnsupported Error = "unsupported CBOR item"
+	ErrCBORTrailing    Error = "trailing bytes after CBOR item"
+
+	cborMajorUint  = 0
+	cborMajorBytes = 2
+	cborMajorText  = 3
+	cborMajorArray = 4
+	cborMajorMap   = 5
+	cborMajorTag   = 6
+	cborMajorOther = 7
+
+	cborFalse = 20
+	cborTrue  = 21
+	cborNull  = 22
+
+	// cborMaxDepth bounds the nesting of decoded items.
+	cborMaxDepth = 32
+)
+
+// cborTag is a tagged CBOR item.
+type cborTag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// cborMap is a CBOR map with unsigned integer keys, the only kind used by the
+// Blockchain Commons registry types.
+type cborMap map[uint64]interface{}
+
+// cborEncode encodes a value made of uint64, int, bool, nil, []byte, string,
+// []interface{}, cborMap and cborTag. Map keys are sorted (canonical CBOR).
+func cborEncode(v interface{}) ([]byte, error) {
+	return cborAppend(nil, v)
+}
+
+// cborAppend appends the encoding of v to buf.
+func cborAppend(buf []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case uint64:
+		return cborAppendHead(buf, cborMajorUint, v), nil
+	case uint32:
+		return cborAppendHead(buf, cborMajorUint, uint64(v)), nil
+	case int:
+		if v < 0 {
+			return nil, ErrCBORUnsupported
+		}
+		return cborAppendHead(buf, cborMajorUint, uint64(v)), nil
+	case bool:
+		if v {
+			return append(buf, cborMajorOther<<5|cborTrue), nil
+		}
+		return append(buf, cborMajorOther<<5|cborFalse), nil
+	case nil:
+		return append(buf, cborMajorOther<<5|cborNull), nil
+	case []byte:
+		return append(cborAppendHead(buf, cborMajorBytes, uint64(len(v))), v...), nil
+	case string:
+		return append(cborAppendHead(buf, cborMajorText, uint64(len(v))), v...), nil
+	case []interface{}:
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if buf, err = cborAppend(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case cborMap:
+		keys := make([]uint64, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		buf = cborAppendHead(buf, cborMajorMap, uint64(len(v)))
+		for _, k := range keys {
+			buf = cborAppendHead(buf, cborMajorUint, k)
+			var err error
+			if buf, err = cborAppend(buf, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case cborTag:
+		return cborAppend(cborAppendHead(buf, cborMajorTag, v.Number), v.Content)
+	default:
+		return nil, ErrCBORUnsupported
+	}
+}
+
+// cborAppendHead appends the initial byte and argument of an item.
+func cborAppendHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major<<5|byte(n))
+	case n <= 0xff:
+		return append(buf, major<<5|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(buf, major<<5|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(buf, major<<5|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major<<5|27), n)
+	}
+}
+
+// cborDecode decodes a single CBOR item spanning the whole input.
+func cborDecode(data []byte) (interface{}, error) {
+	v, rest, err := cborDecodeItem(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, ErrCBORTrailing
+	}
+	return v, nil
+}
+
+// cborDecodeItem decodes the first item of data and returns the remaining bytes.
+func cborDecodeItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > cborMaxDepth {
+		return nil, nil, ErrCBORUnsupported
+	}
+	major, n, data, err := cborDecodeHead(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborMajorUint:
+		return n, data, nil
+	case cborMajorBytes, cborMajorText:
+		if uint64(len(data)) < n {
+			return nil, nil, ErrCBORTruncated
+		}
+		if major == cborMajorText {
+			return string(data[:n]), data[n:], nil
+		}
+		return append([]byte(nil), data[:n]...), data[n:], nil
+	case cborMajorArray:
+		if uint64(len(data)) < n {
+			return nil, nil, ErrCBORTruncated
+		}
+		items := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			var item interface{}
+			if item, data, err = cborDecodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case cborMajorMap:
+		if uint64(len(data)) < 2*n {
+			return nil, nil, ErrCBORTruncated
+		}
+		m := make(cborMap, n)
+		for i := uint64(0); i < n; i++ {
+			var key, value interface{}
+			if key, data, err = cborDecodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			k, ok := key.(uint64)
+			if !ok {
+				return nil, nil, ErrCBORUnsupported
+			}
+			if value, data, err = cborDecodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[k] = value
+		}
+		return m, data, nil
+	case cborMajorTag:
+		content, data, err := cborDecodeItem(data, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cborTag{Number: n, Content: content}, data, nil
+	case cborMajorOther:
+		switch n {
+		case cborFalse:
+			return false, data, nil
+		case cborTrue:
+			return true, data, nil
+		case cborNull:
+			return nil, data, nil
+		}
+	}
+	return nil, nil, ErrCBORUnsupported
+}
+
+// cborDecodeHead decodes the initial byte and argument of an item.
+func cborDecodeHead(data []byte) (byte, uint64, []byte, error) {
+	if len(data) == 0 {
+		return 0, 0, nil, ErrCBORTruncated
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, nil, ErrCBORUnsupported
+	}
+	if major == cborMajorOther {
+		return 0, 0, nil, ErrCBORUnsupported
+	}
+	if len(data) < size {
+		return 0, 0, nil, ErrCBORTruncated
+	}
+
+	var n uint64
+	for _, c := range data[:size] {
+		n = n<<8 | uint64(c)
+	}
+	return major, n, data[size:], nil
+}
@@ -0,0 +1,36 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CBOR(t *testing.T) {
+	encoded, err := cborEncode(uint64(1000))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x19, 0x03, 0xe8}, encoded)
+
+	value := cborMap{
+		1: uint64(0xd34db33f),
+		2: []interface{}{
+			cborTag{Number: 403, Content: cborMap{3: []byte{1, 2, 3}, 4: "text", 5: true}},
+			false,
+			nil,
+		},
+	}
+	encoded, err = cborEncode(value)
+	assert.NoError(t, err)
+	decoded, err := cborDecode(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	_, err = cborDecode(encoded[:len(encoded)-1])
+	assert.ErrorIs(t, err, ErrCBORTruncated)
+
+	_, err = cborDecode(append(encoded, 0))
+	assert.ErrorIs(t, err, ErrCBORTrailing)
+
+	_, err = cborEncode(1.5)
+	assert.ErrorIs(t, err, ErrCBORUnsupported)
+}
@@ -0,0 +1,84 @@
+package p2pkh
+
+import (
+	"context"
+	"log/slog"
+)
+
+const ErrNoBackend Error = "wallet has no chain backend"
+
+// ChainBackend is a connection to the bitcoin network, such as an Esplora
+// REST API or an Electrum server: it lists the unspent outputs of addresses,
+// publishes transactions and estimates fees. Backends may also implement the
+// optional interfaces BalanceProvider, AddressHistoryProvider,
+// MempoolAcceptor and MempoolProvider.
+type ChainBackend interface {
+	UTXOProvider
+	Broadcaster
+	FeeEstimator
+}
+
+// WithBackend returns a copy of the wallet connected to a chain backend.
+// The children derived from the copy share its backend.
+func (s *Wallet) WithBackend(backend ChainBackend) *Wallet {
+	wallet := *s
+	wallet.backend = backend
+	return &wallet
+}
+
+// Backend returns the chain backend of the wallet, nil when it has none.
+func (s *Wallet) Backend() ChainBackend {
+	return s.backend
+}
+
+// UTXOs returns the unspent outputs paying to the wallet address.
+func (s *Wallet) UTXOs(ctx context.Context) ([]UTXO, error) {
+	if s.backend == nil {
+		return nil, ErrNoBackend
+	}
+	return s.backend.AddressUTXOs(ctx, s.AddressInfo().String())
+}
+
+// Balance returns the balance of the wallet address. When the backend is not
+// a BalanceProvider, the balance is the sum of the unspent outputs, counted
+// as confirmed.
+func (s *Wallet) Balance(ctx context.Context) (*Balance, error) {
+	if s.backend == nil {
+		return nil, ErrNoBackend
+	}
+	return chainBalance(ctx, s.backend, s.AddressInfo().String())
+}
+
+// chainBalance returns the balance of an address, summing its unspent
+// outputs when the backend is not a BalanceProvider.
+func chainBalance(ctx context.Context, backend ChainBackend, address string) (*Balance, error) {
+	if provider, ok := backend.(BalanceProvider); ok {
+		return provider.AddressBalance(ctx, address)
+	}
+	utxos, err := backend.AddressUTXOs(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	balance := &Balance{}
+	for _, utxo := range utxos {
+		if balance.Confirmed, err = balance.Confirmed.Add(utxo.Amount); err != nil {
+			return nil, err
+		}
+	}
+	return balance, nil
+}
+
+// Broadcast publishes a serialized transaction, such as returned by
+// TxBuilder.Sign, and returns its txid.
+func (s *Wallet) Broadcast(ctx context.Context, rawTx []byte) (string, error) {
+	if s.backend == nil {
+		return "", ErrNoBackend
+	}
+	txid, err := s.backend.Broadcast(ctx, rawTx)
+	if err != nil {
+		s.logEvent(ctx, slog.LevelError, EventBroadcast, slog.Int("size", len(rawTx)), slog.String("error", err.Error()))
+		return "", err
+	}
+	s.logEvent(ctx, slog.LevelInfo, EventBroadcast, slog.String("txid", txid), slog.Int("size", len(rawTx)))
+	return txid, nil
+}
@@ -0,0 +1,69 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// utxoBackend hides the optional interfaces of a backend.
+type utxoBackend struct {
+	ChainBackend
+}
+
+func Test_WalletBackend(t *testing.T) {
+	ctx := context.Background()
+	wallet := createKnownWallet(t, NetworkMainnet)
+	_, err := wallet.UTXOs(ctx)
+	assert.ErrorIs(t, err, ErrNoBackend)
+	_, err = wallet.Balance(ctx)
+	assert.ErrorIs(t, err, ErrNoBackend)
+	_, err = wallet.Broadcast(ctx, []byte{1})
+	assert.ErrorIs(t, err, ErrNoBackend)
+
+	backend := newFakeBackend()
+	connected := wallet.WithBackend(backend)
+	assert.Nil(t, wallet.Backend())
+	assert.Equal(t, backend, connected.Backend())
+
+	address := wallet.AddressHex()
+	backend.addUTXOs(address, walletUTXO(t, wallet, 0, 30000), walletUTXO(t, wallet, 1, 20000))
+	backend.setBalance(address, 50000, -20000)
+
+	utxos, err := connected.UTXOs(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 2)
+
+	balance, err := connected.Balance(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Balance{Confirmed: 50000, Unconfirmed: -20000}, *balance)
+
+	// Without a BalanceProvider, the balance is the sum of the UTXOs.
+	balance, err = wallet.WithBackend(utxoBackend{backend}).Balance(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Balance{Confirmed: 50000}, *balance)
+
+	txid, err := connected.Broadcast(ctx, []byte{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeTxID([]byte{1, 2, 3}), txid)
+	assert.Equal(t, 1, backend.broadcastCount())
+
+	// Derived wallets share the backend.
+	child, err := connected.Derive(1)
+	assert.NoError(t, err)
+	assert.Equal(t, backend, child.Backend())
+	backend.addUTXOs(child.AddressHex(), walletUTXO(t, child, 0, 1000))
+	utxos, err = child.UTXOs(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 1)
+
+	built, err := connected.Builder().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, backend, built.Backend())
+
+	backend.err = errors.New("unreachable")
+	_, err = connected.Balance(ctx)
+	assert.EqualError(t, err, "unreachable")
+}
@@ -0,0 +1,118 @@
+package p2pkh
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// childCacheSize is the number of child keys a wallet keeps, e.g. the
+// receive addresses recently handed out by a web server.
+const childCacheSize = 256
+
+// childCache is a least recently used cache of the child keys of a wallet,
+// keyed by index, safe for concurrent use. Its map is allocated on the first
+// derivation, so that wallets never deriving children pay nothing.
+type childCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	keys  map[uint32]*list.Element
+}
+
+// childCacheEntry is a cached child key.
+type childCacheEntry struct {
+	index uint32
+	key   *hdkeychain.ExtendedKey
+}
+
+// newChildCache returns an empty cache holding up to size keys.
+func newChildCache(size int) *childCache {
+	return &childCache{size: size}
+}
+
+// get returns the cached child key at index, marking it recently used.
+func (s *childCache) get(index uint32) (*hdkeychain.ExtendedKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.keys[index]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*childCacheEntry).key, true
+}
+
+// add caches the child key at index, evicting the least recently used key
+// when the cache is full.
+func (s *childCache) add(index uint32, key *hdkeychain.ExtendedKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		s.keys = make(map[uint32]*list.Element)
+		s.order = list.New()
+	}
+	if element, ok := s.keys[index]; ok {
+		s.order.MoveToFront(element)
+		return
+	}
+	s.keys[index] = s.order.PushFront(&childCacheEntry{index: index, key: key})
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.keys, oldest.Value.(*childCacheEntry).index)
+	}
+}
+
+// len returns the number of cached keys.
+func (s *childCache) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.keys)
+}
+
+// wipe zeroes the cached keys and empties the cache.
+func (s *childCache) wipe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, element := range s.keys {
+		element.Value.(*childCacheEntry).key.Zero()
+	}
+	s.keys = nil
+	s.order = nil
+}
+
+// childKey returns the child extended key of the wallet at index, derived
+// once and then served from the wallet cache.
+func (s *Wallet) childKey(index uint32) (*hdkeychain.ExtendedKey, error) {
+	if s.children == nil {
+		return s.deriveChildKey(index)
+	}
+	if key, ok := s.children.get(index); ok {
+		return key, nil
+	}
+	key, err := s.deriveChildKey(index)
+	if err != nil {
+		return nil, err
+	}
+	s.children.add(index, key)
+	return key, nil
+}
+
+// deriveChildKey derives the child extended key of the wallet at index.
+func (s *Wallet) deriveChildKey(index uint32) (*hdkeychain.ExtendedKey, error) {
+	key, err := deriveKey(s.extendedKey, index)
+	if err != nil {
+		return nil, err
+	}
+	// A private extended key memoizes its public key on first use; computing
+	// it now leaves the key read-only once shared between goroutines.
+	if _, err := key.ECPubKey(); err != nil {
+		return nil, err
+	}
+	if s.wipeOnCollect {
+		zeroOnCollect(key)
+	}
+	return key, nil
+}
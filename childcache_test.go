@@ -0,0 +1,80 @@
+package p2pkh
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_childCache(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	cache := newChildCache(2)
+	_, ok := cache.get(0)
+	assert.False(t, ok)
+
+	for index := uint32(0); index < 3; index++ {
+		key, err := wallet.deriveChildKey(index)
+		assert.NoError(t, err)
+		cache.add(index, key)
+		if index == 1 {
+			// Index 0 becomes the most recently used, so that index 1 is evicted.
+			_, ok = cache.get(0)
+			assert.True(t, ok)
+		}
+	}
+	assert.Equal(t, 2, cache.len())
+	_, ok = cache.get(1)
+	assert.False(t, ok)
+	key, ok := cache.get(2)
+	assert.True(t, ok)
+	expected, err := wallet.deriveChildKey(2)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.String(), key.String())
+}
+
+func Test_Wallet_Derive_Cached(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	first, err := wallet.Derive(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, wallet.children.len())
+	second, err := wallet.Derive(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, wallet.children.len())
+	assert.Same(t, first.extendedKey, second.extendedKey)
+	assert.Equal(t, first.AddressHex(), second.AddressHex())
+
+	// Copies of the wallet share its cache, their key being the same.
+	assert.Same(t, wallet.children, wallet.WithBackend(newFakeBackend()).children)
+}
+
+func Test_Wallet_Derive_Concurrent(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	expected, err := wallet.Addresses(0, 8)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	addresses := make([][]string, 16)
+	errs := make([]error, len(addresses))
+	for i := range addresses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for index := range expected {
+				child, err := wallet.Derive(index)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				addresses[i] = append(addresses[i], child.AddressHex())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range addresses {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, expected, addresses[i])
+	}
+	assert.Equal(t, len(expected), wallet.children.len())
+}
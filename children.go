@@ -0,0 +1,35 @@
+package p2pkh
+
+import (
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// Children calls fn with the non-hardened children of the wallet, in order
+// from start, until fn returns false or the last non-hardened index is
+// reached. Children are derived one at a time and bypass the child key cache
+// of the wallet, so that millions of deposit addresses can be streamed, e.g.
+// into a database, without being held in memory. Its signature follows the
+// one of a range function:
+//
+//	err := wallet.Children(0, func(index uint32, child *p2pkh.Wallet) bool {
+//		return store(index, child.AddressInfo().String()) == nil
+//	})
+func (s *Wallet) Children(start uint32, fn func(index uint32, child *Wallet) bool) error {
+	if start >= hdkeychain.HardenedKeyStart {
+		return ErrIndexRange
+	}
+	for index := start; index < hdkeychain.HardenedKeyStart; index++ {
+		key, err := s.deriveChildKey(index)
+		if err != nil {
+			return err
+		}
+		child, err := s.childWallet(index, key)
+		if err != nil {
+			return err
+		}
+		if !fn(index, child) {
+			return nil
+		}
+	}
+	return nil
+}
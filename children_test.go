@@ -0,0 +1,56 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Children(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	expected, err := wallet.Addresses(5, 3)
+	assert.NoError(t, err)
+
+	var indexes []uint32
+	var addresses []string
+	err = wallet.Children(5, func(index uint32, child *Wallet) bool {
+		indexes = append(indexes, index)
+		addresses = append(addresses, child.AddressInfo().String())
+		assert.Equal(t, wallet, child.Parent())
+		return len(indexes) < 3
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{5, 6, 7}, indexes)
+	assert.Equal(t, expected, addresses)
+	assert.Equal(t, 0, wallet.children.len())
+}
+
+func Test_Children_Path(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	err := wallet.Children(2, func(index uint32, child *Wallet) bool {
+		derived, err := wallet.Derive(index)
+		assert.NoError(t, err)
+		assert.Equal(t, derived.Path(), child.Path())
+		assert.Equal(t, derived.AddressInfo().String(), child.AddressInfo().String())
+		return false
+	})
+	assert.NoError(t, err)
+}
+
+func Test_Children_IndexRange(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	err := wallet.Children(hdkeychain.HardenedKeyStart, func(uint32, *Wallet) bool {
+		t.Fatal("unexpected child")
+		return false
+	})
+	assert.ErrorIs(t, err, ErrIndexRange)
+
+	var last uint32
+	err = wallet.Children(hdkeychain.HardenedKeyStart-2, func(index uint32, _ *Wallet) bool {
+		last = index
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(hdkeychain.HardenedKeyStart-1), last)
+}
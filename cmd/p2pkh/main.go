@@ -0,0 +1,255 @@
+// Command p2pkh is a command-line front end of the p2pkh library, for ops
+// scripts and key ceremonies.
+//
+// Usage:
+//
+//	p2pkh new [-bits 128] [-lang english]
+//	p2pkh derive [-network mainnet] [-purpose 44] [-path P] [-start 0] [-count 1]
+//	p2pkh sign-message [-network mainnet] [-purpose 44] [-path P] message
+//	p2pkh validate [-network mainnet] address...
+//	p2pkh xpub [-network mainnet] [-purpose 44] [-path P]
+//
+// The commands needing keys read the mnemonic from the P2PKH_MNEMONIC
+// environment variable or, when it is unset, from the first line of the
+// standard input, so that it never appears in the process arguments.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	p2pkh "github.com/ariden83/p2pkh.go"
+)
+
+const (
+	// mnemonicEnv is the environment variable holding the mnemonic.
+	mnemonicEnv = "P2PKH_MNEMONIC"
+
+	usage = `usage: p2pkh <command> [flags]
+
+commands:
+  new           generate a mnemonic
+  derive        print the addresses of a range of children
+  sign-message  sign a message with the key at a path
+  validate      validate addresses
+  xpub          print the extended public key of an account or path
+`
+)
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run runs the command of args and returns the exit code of the process.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	commands := map[string]func(*command) error{
+		"new":          runNew,
+		"derive":       runDerive,
+		"sign-message": runSignMessage,
+		"validate":     runValidate,
+		"xpub":         runXPub,
+	}
+	fn, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "p2pkh: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+
+	cmd := &command{
+		flags:  flag.NewFlagSet(args[0], flag.ContinueOnError),
+		args:   args[1:],
+		stdin:  stdin,
+		stdout: stdout,
+		getenv: getenv,
+	}
+	cmd.flags.SetOutput(stderr)
+	if err := fn(cmd); err != nil {
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "p2pkh %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// command is a subcommand being run, with its flags and streams.
+type command struct {
+	flags  *flag.FlagSet
+	args   []string
+	stdin  io.Reader
+	stdout io.Writer
+	getenv func(string) string
+
+	network string
+	purpose uint
+	path    string
+}
+
+// walletFlags declares the flags selecting the key of a wallet.
+func (s *command) walletFlags() {
+	s.flags.StringVar(&s.network, "network", string(p2pkh.NetworkMainnet), "network of the wallet")
+	s.flags.UintVar(&s.purpose, "purpose", 0, "BIP43 purpose of the default path: 44, 49, 84 or 86")
+	s.flags.StringVar(&s.path, "path", "", "derivation path, the default path of the purpose when empty")
+}
+
+// parse parses the flags of the command. The flag set reports the errors.
+func (s *command) parse() error {
+	if err := s.flags.Parse(s.args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// mnemonic reads the mnemonic from the environment or the standard input.
+func (s *command) mnemonic() (string, error) {
+	if mnemonic := strings.TrimSpace(s.getenv(mnemonicEnv)); mnemonic != "" {
+		return mnemonic, nil
+	}
+	line, err := bufio.NewReader(s.stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return "", fmt.Errorf("no mnemonic: set %s or write it to the standard input", mnemonicEnv)
+	}
+	return line, nil
+}
+
+// wallet creates the wallet of the mnemonic selected by the flags.
+func (s *command) wallet() (*p2pkh.Wallet, error) {
+	mnemonic, err := s.mnemonic()
+	if err != nil {
+		return nil, err
+	}
+	return p2pkh.New(&p2pkh.Config{
+		Mnemonic:       mnemonic,
+		Path:           s.path,
+		Network:        p2pkh.Network(s.network),
+		Purpose:        p2pkh.Purpose(s.purpose),
+		ForgetMnemonic: true,
+	})
+}
+
+func runNew(cmd *command) error {
+	bits := cmd.flags.Int("bits", 128, "entropy bits, from 128 (12 words) to 256 (24 words)")
+	lang := cmd.flags.String("lang", string(p2pkh.LanguageEnglish), "language of the word list")
+	if err := cmd.parse(); err != nil {
+		return err
+	}
+	mnemonic, err := p2pkh.GenerateMnemonic(*bits, p2pkh.Language(*lang))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.stdout, mnemonic)
+	return nil
+}
+
+func runDerive(cmd *command) error {
+	cmd.walletFlags()
+	start := cmd.flags.Uint("start", 0, "index of the first child")
+	count := cmd.flags.Uint("count", 1, "number of children")
+	if err := cmd.parse(); err != nil {
+		return err
+	}
+	wallet, err := cmd.wallet()
+	if err != nil {
+		return err
+	}
+	for index := *start; index < *start+*count; index++ {
+		child, err := wallet.Derive(index)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.stdout, "%s\t%s\n", child.Path(), child.PurposeAddress())
+	}
+	return nil
+}
+
+func runSignMessage(cmd *command) error {
+	cmd.walletFlags()
+	if err := cmd.parse(); err != nil {
+		return err
+	}
+	if cmd.flags.NArg() == 0 {
+		fmt.Fprintln(cmd.flags.Output(), "usage: p2pkh sign-message [flags] message")
+		return errUsage
+	}
+	wallet, err := cmd.wallet()
+	if err != nil {
+		return err
+	}
+	signature, err := wallet.SignMessage(strings.Join(cmd.flags.Args(), " "))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.stdout, "%s\t%s\n", wallet.AddressHex(), signature)
+	return nil
+}
+
+func runValidate(cmd *command) error {
+	network := cmd.flags.String("network", string(p2pkh.NetworkMainnet), "network of the addresses")
+	if err := cmd.parse(); err != nil {
+		return err
+	}
+	if cmd.flags.NArg() == 0 {
+		fmt.Fprintln(cmd.flags.Output(), "usage: p2pkh validate [flags] address...")
+		return errUsage
+	}
+	invalid := 0
+	for _, address := range cmd.flags.Args() {
+		addrType, _, err := p2pkh.ClassifyAddress(address, p2pkh.Network(*network))
+		if err != nil {
+			invalid++
+			fmt.Fprintf(cmd.stdout, "%s\tinvalid\t%v\n", address, err)
+			continue
+		}
+		fmt.Fprintf(cmd.stdout, "%s\t%s\n", address, addrType)
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d invalid addresses", invalid)
+	}
+	return nil
+}
+
+func runXPub(cmd *command) error {
+	cmd.walletFlags()
+	if err := cmd.parse(); err != nil {
+		return err
+	}
+	wallet, err := cmd.wallet()
+	if err != nil {
+		return err
+	}
+	// Without path, the account of the default path is exported, as wallet
+	// software expects.
+	if cmd.path == "" {
+		account, err := p2pkh.NewAccount(wallet)
+		if err != nil {
+			return err
+		}
+		xpub, err := account.XPub()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.stdout, "%s\t%s\n", account.Path(), xpub)
+		return nil
+	}
+	xpub, err := wallet.ExtendedPublicKey()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.stdout, "%s\t%s\n", wallet.Path(), xpub)
+	return nil
+}
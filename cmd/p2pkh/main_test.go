@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	p2pkh "github.com/ariden83/p2pkh.go"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// runCmd runs a command with an input and an environment, and returns its
+// exit code, standard output and standard error.
+func runCmd(stdin string, env map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr, func(key string) string {
+		return env[key]
+	})
+	return code, stdout.String(), stderr.String()
+}
+
+// runMnemonic runs a command with the test mnemonic on the standard input.
+func runMnemonic(args ...string) (int, string, string) {
+	return runCmd(testMnemonic+"\n", nil, args...)
+}
+
+func Test_run_New(t *testing.T) {
+	code, out, _ := runMnemonic("new", "-bits", "256")
+	assert.Equal(t, 0, code)
+	mnemonic := strings.TrimSpace(out)
+	assert.Len(t, strings.Fields(mnemonic), 24)
+	assert.NoError(t, p2pkh.ValidateMnemonic(mnemonic, p2pkh.LanguageEnglish))
+
+	code, _, errOut := runMnemonic("new", "-bits", "100")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, p2pkh.ErrMnemonicEntropy.Error())
+}
+
+func Test_run_Derive(t *testing.T) {
+	code, out, _ := runMnemonic("derive", "-count", "2")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "m/44'/0'/0'/0/0\t1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA\n"+
+		"m/44'/0'/0'/0/1\t1Ak8PffB2meyfYnbXZR9EGfLfFZVpzJvQP\n", out)
+
+	// The mnemonic may come from the environment, and the purpose selects
+	// the default path and the address type.
+	code, out, _ = runCmd("", map[string]string{mnemonicEnv: testMnemonic}, "derive", "-purpose", "84")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "m/84'/0'/0'/0/0\tbc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu\n", out)
+
+	code, _, errOut := runCmd("", nil, "derive")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "no mnemonic")
+}
+
+func Test_run_SignMessage(t *testing.T) {
+	code, out, _ := runMnemonic("sign-message", "hello", "world")
+	assert.Equal(t, 0, code)
+	fields := strings.Split(strings.TrimSpace(out), "\t")
+	assert.Len(t, fields, 2)
+	valid, err := p2pkh.VerifyMessage(fields[0], fields[1], "hello world")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	code, _, _ = runMnemonic("sign-message")
+	assert.Equal(t, 2, code)
+}
+
+func Test_run_Validate(t *testing.T) {
+	code, out, _ := runCmd("", nil, "validate", "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA\tp2pkh\nbc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu\tp2wpkh\n", out)
+
+	code, out, errOut := runCmd("", nil, "validate", "-network", "testnet", "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "invalid")
+	assert.Contains(t, errOut, "1 invalid addresses")
+}
+
+func Test_run_XPub(t *testing.T) {
+	code, out, _ := runMnemonic("xpub")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "m/44'/0'/0'\txpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj\n", out)
+
+	code, out, _ = runMnemonic("xpub", "-path", "m/44'/0'/0'/0")
+	assert.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(out, "m/44'/0'/0'/0\txpub"))
+}
+
+func Test_run_Usage(t *testing.T) {
+	code, _, errOut := runCmd("", nil)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "usage")
+	code, _, errOut = runCmd("", nil, "unknown")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, `unknown command "unknown"`)
+	code, _, _ = runCmd("", nil, "derive", "-nope")
+	assert.Equal(t, 2, code)
+}
@@ -0,0 +1,344 @@
+package p2pkh
+
+import (
+	"sort"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+const (
+	ErrNoChangelessSelection Error = "no coin selection avoids a change output"
+	ErrCoinSelectionFeeRate  Error = "coin selection requires a fee rate"
+
+	defaultBnBMaxTries = 100000
+)
+
+// Coin is a UTXO the transaction builder may spend.
+type Coin struct {
+	DraftInput
+	// Fee is the fee of the input spending the coin at the fee rate of the
+	// transaction.
+	Fee Amount
+}
+
+// EffectiveValue returns the amount the coin adds to the transaction once
+// the fee of its input is paid.
+func (s Coin) EffectiveValue() Amount {
+	return s.Amount - s.Fee
+}
+
+// CoinSelector picks the coins funding a transaction. target is the
+// effective value to reach: the amount of the outputs and the fee of the
+// transaction without its inputs. costOfChange is the fee of creating a
+// change output and spending it later; a selection exceeding target by less
+// is best left without change.
+type CoinSelector interface {
+	SelectCoins(coins []Coin, target, costOfChange Amount) ([]Coin, error)
+}
+
+// LargestFirst selects the coins of largest amount first until the target
+// is reached. It spends few inputs, at the price of consolidating the
+// largest coins and leaving the dust.
+type LargestFirst struct{}
+
+// SelectCoins implements CoinSelector.
+func (s *LargestFirst) SelectCoins(coins []Coin, target, _ Amount) ([]Coin, error) {
+	sorted := append([]Coin(nil), coins...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Amount > sorted[j].Amount
+	})
+	var selected []Coin
+	var total Amount
+	for _, coin := range sorted {
+		if coin.EffectiveValue() <= 0 {
+			continue
+		}
+		selected = append(selected, coin)
+		var err error
+		if total, err = total.Add(coin.EffectiveValue()); err != nil {
+			return nil, err
+		}
+		if total >= target {
+			return selected, nil
+		}
+	}
+	return nil, ErrInsufficientFunds
+}
+
+// BranchAndBound searches, as Bitcoin Core does, for a selection whose
+// effective value exceeds the target by less than the cost of change, so
+// that the transaction needs no change output. Among the selections found,
+// the one with the least excess wins.
+type BranchAndBound struct {
+	// MaxTries bounds the search, 100000 steps by default.
+	MaxTries int
+	// Fallback selects the coins when no changeless selection exists. When
+	// nil, the selection fails with ErrNoChangelessSelection.
+	Fallback CoinSelector
+}
+
+// SelectCoins implements CoinSelector.
+func (s *BranchAndBound) SelectCoins(coins []Coin, target, costOfChange Amount) ([]Coin, error) {
+	sorted := make([]Coin, 0, len(coins))
+	var available Amount
+	for _, coin := range coins {
+		if coin.EffectiveValue() > 0 {
+			sorted = append(sorted, coin)
+			var err error
+			if available, err = available.Add(coin.EffectiveValue()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if available < target {
+		return nil, ErrInsufficientFunds
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EffectiveValue() > sorted[j].EffectiveValue()
+	})
+
+	upper, err := target.Add(costOfChange)
+	if err != nil {
+		return nil, err
+	}
+	search := &bnbSearch{
+		coins:    sorted,
+		target:   target,
+		upper:    upper,
+		tries:    s.MaxTries,
+		selected: make([]bool, len(sorted)),
+	}
+	if search.tries <= 0 {
+		search.tries = defaultBnBMaxTries
+	}
+	search.run(0, 0, available)
+
+	if search.best == nil {
+		if s.Fallback != nil {
+			return s.Fallback.SelectCoins(coins, target, costOfChange)
+		}
+		return nil, ErrNoChangelessSelection
+	}
+	var selected []Coin
+	for i, in := range search.best {
+		if in {
+			selected = append(selected, sorted[i])
+		}
+	}
+	return selected, nil
+}
+
+// bnbSearch is the depth-first search of BranchAndBound over coins sorted by
+// decreasing effective value, each either included or omitted.
+type bnbSearch struct {
+	coins      []Coin
+	target     Amount
+	upper      Amount
+	tries      int
+	selected   []bool
+	best       []bool
+	bestExcess Amount
+}
+
+// run explores the selections of the coins from i, given the effective value
+// already selected and the one of the coins left.
+func (s *bnbSearch) run(i int, total, remaining Amount) {
+	if s.tries <= 0 || total > s.upper || total+remaining < s.target {
+		return
+	}
+	s.tries--
+	if total >= s.target {
+		if excess := total - s.target; s.best == nil || excess < s.bestExcess {
+			s.best = append([]bool(nil), s.selected...)
+			s.bestExcess = excess
+		}
+		return
+	}
+	if i == len(s.coins) {
+		return
+	}
+
+	value := s.coins[i].EffectiveValue()
+	s.selected[i] = true
+	s.run(i+1, total+value, remaining-value)
+	s.selected[i] = false
+	// Omitting a coin of the same value as an omitted one explores the same
+	// selections again.
+	if i > 0 && !s.selected[i-1] && s.coins[i-1].EffectiveValue() == value {
+		return
+	}
+	s.run(i+1, total, remaining-value)
+}
+
+// selectCoins selects, among the coins of the builder, the inputs funding
+// the outputs and the fee beyond what the explicit inputs provide.
+func (s *TxBuilder) selectCoins() ([]DraftInput, error) {
+	// Without fee rate, there is no change and the excess of the selection
+	// would be lost to the fee.
+	if s.feeRate <= 0 {
+		return nil, ErrCoinSelectionFeeRate
+	}
+	// The transaction without inputs, counted as segwit to stay on the safe
+	// side.
+	weight := int64(txOverheadWeight + segwitMarkerWeight)
+	var target Amount
+	for _, out := range s.outputs {
+		_, outputType, err := s.addressScript(out.Address)
+		if err != nil {
+			return nil, err
+		}
+		scheme, err := addressScheme(outputType)
+		if err != nil {
+			return nil, err
+		}
+		weight += scheme.OutputWeight()
+		if target, err = target.Add(out.Amount); err != nil {
+			return nil, err
+		}
+	}
+	fee, err := s.feeRate.FeeForWeight(weight)
+	if err != nil {
+		return nil, err
+	}
+	if target, err = target.Add(fee); err != nil {
+		return nil, err
+	}
+	for _, in := range s.inputs {
+		inputWeight, err := s.inputWeight(in)
+		if err != nil {
+			return nil, err
+		}
+		inputFee, err := s.feeRate.FeeForWeight(inputWeight)
+		if err != nil {
+			return nil, err
+		}
+		effectiveValue, err := in.Amount.Sub(inputFee)
+		if err != nil {
+			return nil, err
+		}
+		if target, err = target.Sub(effectiveValue); err != nil {
+			return nil, err
+		}
+	}
+	if target <= 0 {
+		return nil, nil
+	}
+
+	address, _, err := s.changeDestination()
+	if err != nil {
+		return nil, err
+	}
+	_, changeType, err := s.addressScript(address)
+	if err != nil {
+		return nil, err
+	}
+	changeScheme, err := addressScheme(changeType)
+	if err != nil {
+		return nil, err
+	}
+	costOfChange, err := s.feeRate.FeeForWeight(changeScheme.OutputWeight() + changeScheme.InputWeight())
+	if err != nil {
+		return nil, err
+	}
+
+	coins := make([]Coin, 0, len(s.coins))
+	for _, in := range s.coins {
+		inputWeight, err := s.inputWeight(in)
+		if err != nil {
+			return nil, err
+		}
+		inputFee, err := s.feeRate.FeeForWeight(inputWeight)
+		if err != nil {
+			return nil, err
+		}
+		coins = append(coins, Coin{DraftInput: in, Fee: inputFee})
+	}
+	selector := s.selector
+	if selector == nil {
+		selector = &BranchAndBound{Fallback: &LargestFirst{}}
+	}
+	for {
+		selected, err := selector.SelectCoins(coins, target, costOfChange)
+		if err != nil {
+			return nil, err
+		}
+		inputs := make([]DraftInput, 0, len(selected))
+		for _, coin := range selected {
+			inputs = append(inputs, coin.DraftInput)
+		}
+		// The fees of the coins are rounded up each, the fee of the
+		// transaction as a whole: a selection short of the latter is made
+		// again for the difference.
+		shortfall, err := s.selectionShortfall(append(s.inputs[:len(s.inputs):len(s.inputs)], inputs...))
+		if err != nil {
+			return nil, err
+		}
+		if shortfall <= 0 {
+			return inputs, nil
+		}
+		if target, err = target.Add(shortfall); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// selectionShortfall returns what the inputs spent lack to pay the outputs
+// and the fee of the transaction without change, as change computes it.
+func (s *TxBuilder) selectionShortfall(spent []DraftInput) (Amount, error) {
+	var funds Amount
+	var err error
+	for _, in := range spent {
+		if funds, err = funds.Add(in.Amount); err != nil {
+			return 0, err
+		}
+	}
+	outputs := make([]AddressType, 0, len(s.outputs))
+	for _, out := range s.outputs {
+		_, outputType, err := s.addressScript(out.Address)
+		if err != nil {
+			return 0, err
+		}
+		outputs = append(outputs, outputType)
+		if funds, err = funds.Sub(out.Amount); err != nil {
+			return 0, err
+		}
+	}
+	vsize, err := s.vsize(spent, outputs)
+	if err != nil {
+		return 0, err
+	}
+	fee, err := s.feeRate.Fee(vsize)
+	if err != nil {
+		return 0, err
+	}
+	return fee.Sub(funds)
+}
+
+// inputWeight returns the weight of an input or coin of the builder.
+func (s *TxBuilder) inputWeight(in DraftInput) (int64, error) {
+	if multisig, ok := s.multisigs[in.outpoint()]; ok {
+		return multisig.inputWeight(), nil
+	}
+	inputType, err := ScriptAddressType(in.PkScript)
+	if err != nil {
+		return 0, err
+	}
+	scheme, err := addressScheme(inputType)
+	if err != nil {
+		return 0, err
+	}
+	if scheme.InputWeight() == 0 {
+		return 0, ErrUnsupportedAddressType
+	}
+	return scheme.InputWeight() + s.uncompressedKeyWeight(inputType), nil
+}
+
+// uncompressedKeyWeight returns the weight an input of a type adds to the
+// estimates when the wallet signs it with its uncompressed key, 32 bytes
+// larger than the compressed key they assume.
+func (s *TxBuilder) uncompressedKeyWeight(inputType AddressType) int64 {
+	if inputType != AddressTypeP2PKH || !s.wallet.uncompressed || s.signer != nil {
+		return 0
+	}
+	return (secp256k1.PubKeyBytesLenUncompressed - secp256k1.PubKeyBytesLenCompressed) * 4
+}
@@ -0,0 +1,149 @@
+package p2pkh
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCoins returns coins of the given amounts, with a fee of 100 each.
+func testCoins(amounts ...Amount) []Coin {
+	coins := make([]Coin, 0, len(amounts))
+	for i, amount := range amounts {
+		coins = append(coins, Coin{DraftInput: DraftInput{UTXO: UTXO{TxID: testTxID, Vout: uint32(i), Amount: amount}}, Fee: 100})
+	}
+	return coins
+}
+
+// coinAmounts returns the amounts of coins.
+func coinAmounts(coins []Coin) []Amount {
+	amounts := make([]Amount, 0, len(coins))
+	for _, coin := range coins {
+		amounts = append(amounts, coin.Amount)
+	}
+	return amounts
+}
+
+func Test_LargestFirst(t *testing.T) {
+	coins := testCoins(1000, 50000, 20000, 90)
+	selected, err := (&LargestFirst{}).SelectCoins(coins, 60000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []Amount{50000, 20000}, coinAmounts(selected))
+
+	_, err = (&LargestFirst{}).SelectCoins(coins, 80000, 0)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	huge := testCoins(math.MaxInt64-100, math.MaxInt64-100)
+	_, err = (&LargestFirst{}).SelectCoins(huge, math.MaxInt64, 0)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func Test_BranchAndBound(t *testing.T) {
+	coins := testCoins(50000, 30100, 20100, 10100, 5100)
+
+	// 30000 + 10000 matches exactly, without change.
+	selected, err := (&BranchAndBound{}).SelectCoins(coins, 40000, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, []Amount{30100, 10100}, coinAmounts(selected))
+
+	// The least excess wins: 30000 + 5000 over 20000 + 10000 + 5000.
+	selected, err = (&BranchAndBound{}).SelectCoins(coins, 34800, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, []Amount{30100, 5100}, coinAmounts(selected))
+
+	_, err = (&BranchAndBound{}).SelectCoins(coins, 41000, 500)
+	assert.ErrorIs(t, err, ErrNoChangelessSelection)
+	selected, err = (&BranchAndBound{Fallback: &LargestFirst{}}).SelectCoins(coins, 41000, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, []Amount{50000}, coinAmounts(selected))
+
+	_, err = (&BranchAndBound{}).SelectCoins(coins, 200000, 500)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = (&BranchAndBound{}).SelectCoins(testCoins(math.MaxInt64-100, math.MaxInt64-100), 1000, 500)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = (&BranchAndBound{}).SelectCoins(testCoins(math.MaxInt64-100), math.MaxInt64-200, 500)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+
+	// Coins worth less than their fee are never selected.
+	selected, err = (&BranchAndBound{}).SelectCoins(testCoins(50, 1100), 1000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []Amount{1100}, coinAmounts(selected))
+}
+
+func Test_TxBuilder_CoinSelection(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	coins := []UTXO{walletUTXO(t, wallet, 0, 80000), walletUTXO(t, wallet, 1, 30000), walletUTXO(t, child, 2, 20000)}
+
+	// The default selector spends the largest coin with change.
+	draft, err := wallet.NewTransaction().
+		AddCoins(coins[:2]...).
+		AddCoinsAt(child.Path(), coins[2]).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 50000).
+		WithFeeRate(10).
+		Draft()
+	assert.NoError(t, err)
+	assert.Len(t, draft.Inputs, 1)
+	assert.Equal(t, Amount(80000), draft.Inputs[0].Amount)
+	assert.Len(t, draft.Outputs, 2)
+
+	// A changeless selection is preferred: 30000 + 20000 pay the output and
+	// the fee, leaving 100 satoshis to the fee rather than a dust change.
+	vsize, err := EstimateVSize([]AddressType{AddressTypeP2PKH, AddressTypeP2PKH}, []AddressType{AddressTypeP2PKH})
+	assert.NoError(t, err)
+	rawTx, err := wallet.NewTransaction().
+		AddCoins(coins[:2]...).
+		AddCoinsAt(child.Path(), coins[2]).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 50000-Amount(vsize*10)-100).
+		WithFeeRate(10).
+		Sign()
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, []UTXO{coins[1], coins[2]})
+
+	// Explicit inputs are always spent, coins only complete them.
+	draft, err = wallet.NewTransaction().
+		AddInput(coins[1]).
+		AddCoins(coins[0]).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).
+		WithFeeRate(10).
+		WithCoinSelector(&LargestFirst{}).
+		Draft()
+	assert.NoError(t, err)
+	assert.Len(t, draft.Inputs, 1)
+
+	_, err = wallet.NewTransaction().AddCoins(coins...).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 200000).WithFeeRate(10).Draft()
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = wallet.NewTransaction().AddCoins(coins...).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 1000).Draft()
+	assert.ErrorIs(t, err, ErrCoinSelectionFeeRate)
+	_, err = wallet.NewTransaction().AddCoins(coins...).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 1000).WithFeeRate(math.MaxInt64 / 100).Draft()
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func Test_TxBuilder_CoinSelection_FeeRounding(t *testing.T) {
+	// The fees of the coins, each rounded up, total 329 satoshis where the
+	// transaction of 110 vB pays 330: the exact match of 10329 falls short
+	// and the 500000 coin is selected instead.
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, Purpose: PurposeNativeSegwit})
+	assert.NoError(t, err)
+	_, script, err := ClassifyAddress(wallet.AddressHex(), NetworkMainnet)
+	assert.NoError(t, err)
+	coins := []UTXO{
+		{TxID: testTxID, Vout: 0, Amount: 10329, PkScript: script},
+		{TxID: testTxID, Vout: 1, Amount: 500000, PkScript: script},
+	}
+	draft, err := wallet.NewTransaction().
+		AddCoins(coins...).
+		AddOutput("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", 10000).
+		WithFeeRate(3).
+		Draft()
+	assert.NoError(t, err)
+	assert.Len(t, draft.Inputs, 1)
+	assert.Equal(t, Amount(500000), draft.Inputs[0].Amount)
+	assert.Len(t, draft.Outputs, 2)
+	vsize, err := EstimateVSize([]AddressType{AddressTypeP2WPKH}, []AddressType{AddressTypeP2WPKH, AddressTypeP2WPKH})
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(3*vsize), draft.Fee)
+}
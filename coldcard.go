@@ -0,0 +1,71 @@
+package p2pkh
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	ErrColdcardName Error = "coldcard wallet names must be 1 to 20 printable ASCII characters"
+
+	maxColdcardNameLength = 20
+)
+
+// coldcardFormats maps address types to the Coldcard "Format" values.
+var coldcardFormats = map[AddressType]string{
+	AddressTypeP2SH:  "P2SH",
+	AddressTypeP2WSH: "P2WSH",
+}
+
+// ExportColdcard writes the multisig setup text file imported by Coldcard
+// and compatible hardware cosigners: name, policy, derivation, and the xpub
+// of every cosigner prefixed by its master fingerprint.
+func (s *MultisigWallet) ExportColdcard(w io.Writer) error {
+	if !validColdcardName(s.name) {
+		return ErrColdcardName
+	}
+	format, ok := coldcardFormats[s.addrType]
+	if !ok {
+		return ErrUnsupportedAddressType
+	}
+
+	var b strings.Builder
+	b.WriteString("# Multisig setup file (created by p2pkh.go)\n#\n")
+	fmt.Fprintf(&b, "Name: %s\n", s.name)
+	fmt.Fprintf(&b, "Policy: %d of %d\n", s.required, len(s.cosigners))
+
+	// A single Derivation line is enough when all cosigners share the same
+	// path, otherwise each xpub is preceded by its own Derivation line.
+	shared := true
+	for _, cosigner := range s.cosigners {
+		shared = shared && cosigner.Path == s.cosigners[0].Path
+	}
+	if shared {
+		fmt.Fprintf(&b, "Derivation: %s\n", s.cosigners[0].Path)
+	}
+	fmt.Fprintf(&b, "Format: %s\n\n", format)
+
+	for _, cosigner := range s.cosigners {
+		if !shared {
+			fmt.Fprintf(&b, "Derivation: %s\n", cosigner.Path)
+		}
+		fmt.Fprintf(&b, "%08X: %s\n", cosigner.Fingerprint, cosigner.XPub)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// validColdcardName checks the constraints Coldcard puts on wallet names.
+func validColdcardName(name string) bool {
+	if name == "" || len(name) > maxColdcardNameLength {
+		return false
+	}
+	for _, r := range name {
+		if r < 0x20 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
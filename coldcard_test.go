@@ -0,0 +1,55 @@
+package p2pkh
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MultisigWallet_ExportColdcard(t *testing.T) {
+	cosigners := createTestCosigners(t, 3, NetworkMainnet, `m/45'`)
+	wallet, err := NewMultisigWallet("Treasury", 2, cosigners, AddressTypeP2SH, NetworkMainnet)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.ExportColdcard(&buf))
+
+	expected := "# Multisig setup file (created by p2pkh.go)\n#\n" +
+		"Name: Treasury\n" +
+		"Policy: 2 of 3\n" +
+		"Derivation: m/45'\n" +
+		"Format: P2SH\n\n"
+	for _, cosigner := range cosigners {
+		expected += fmt.Sprintf("%08X: %s\n", cosigner.Fingerprint, cosigner.XPub)
+	}
+	assert.Equal(t, expected, buf.String())
+}
+
+func Test_MultisigWallet_ExportColdcard_MixedPaths(t *testing.T) {
+	cosigners := append(
+		createTestCosigners(t, 1, NetworkMainnet, `m/48'/0'/0'/2'`),
+		createTestCosigners(t, 1, NetworkMainnet, `m/48'/0'/1'/2'`)...,
+	)
+	wallet, err := NewMultisigWallet("Mixed", 1, cosigners, AddressTypeP2WSH, NetworkMainnet)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.ExportColdcard(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "Format: P2WSH\n")
+	assert.Contains(t, out, "Derivation: m/48'/0'/0'/2'\n"+fmt.Sprintf("%08X: ", cosigners[0].Fingerprint))
+	assert.Contains(t, out, "Derivation: m/48'/0'/1'/2'\n"+fmt.Sprintf("%08X: ", cosigners[1].Fingerprint))
+}
+
+func Test_MultisigWallet_ExportColdcard_InvalidName(t *testing.T) {
+	cosigners := createTestCosigners(t, 1, NetworkMainnet, `m/45'`)
+
+	for _, name := range []string{"", strings.Repeat("a", 21), "café"} {
+		wallet, err := NewMultisigWallet(name, 1, cosigners, AddressTypeP2SH, NetworkMainnet)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, wallet.ExportColdcard(&bytes.Buffer{}), ErrColdcardName)
+	}
+}
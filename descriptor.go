@@ -0,0 +1,408 @@
+package p2pkh
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const (
+	ErrInvalidDescriptorChar     Error = "invalid character in descriptor"
+	ErrInvalidDescriptor         Error = "invalid descriptor"
+	ErrInvalidDescriptorChecksum Error = "invalid descriptor checksum"
+	ErrDescriptorNetwork         Error = "descriptor key does not belong to the network"
+
+	// descriptorInputCharset is the character set accepted in descriptors (BIP380).
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	// descriptorChecksumCharset is the character set of descriptor checksums.
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// keyOrigin is the BIP32 origin of the key exported in a descriptor: the
+// fingerprint of the master key, the hardened derivation levels leading to the
+// exported extended key, and the unhardened levels left below it.
+type keyOrigin struct {
+	fingerprint uint32
+	path        Path
+	key         *hdkeychain.ExtendedKey
+	suffix      Path
+}
+
+// keyOrigin splits the wallet derivation path at its last hardened level,
+// which is the deepest public key that can be exported (usually the account).
+func (s *Wallet) keyOrigin() (*keyOrigin, error) {
+	dpath := s.levels
+	split := 0
+	for i, n := range dpath {
+		if n >= hdkeychain.HardenedKeyStart {
+			split = i + 1
+		}
+	}
+
+	levels := dpath[:split]
+	if s.origin != nil {
+		if split < len(s.origin.path) {
+			split = len(s.origin.path)
+		}
+		levels = dpath[len(s.origin.path):split]
+	}
+
+	key := s.root
+	var err error
+	for _, n := range levels {
+		if key, err = deriveKey(key, n); err != nil {
+			return nil, err
+		}
+	}
+
+	fingerprint, err := s.masterFingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyOrigin{
+		fingerprint: fingerprint,
+		path:        dpath[:split],
+		key:         key,
+		suffix:      dpath[split:],
+	}, nil
+}
+
+// masterFingerprint returns the fingerprint of the master key of the wallet.
+func (s *Wallet) masterFingerprint() (uint32, error) {
+	if s.origin != nil {
+		return s.origin.fingerprint, nil
+	}
+	return keyFingerprint(s.root)
+}
+
+// keyFingerprint returns the BIP32 fingerprint of an extended key.
+func keyFingerprint(key *hdkeychain.ExtendedKey) (uint32, error) {
+	publicKey, err := key.ECPubKey()
+	if err != nil {
+		return 0, err
+	}
+	fp := btcutil.Hash160(publicKey.SerializeCompressed())
+	return binary.BigEndian.Uint32(fp[:4]), nil
+}
+
+// String formats the origin as in descriptors, e.g. "d34db33f/44'/0'/0'".
+func (s *keyOrigin) String() string {
+	return fmt.Sprintf("%08x", s.fingerprint) + formatPathLevels(s.path)
+}
+
+// formatPathLevels formats derivation levels as "/44'/0'/0'".
+func formatPathLevels(path Path) string {
+	var b strings.Builder
+	for _, n := range path {
+		if n >= hdkeychain.HardenedKeyStart {
+			fmt.Fprintf(&b, "/%d'", n-hdkeychain.HardenedKeyStart)
+		} else {
+			fmt.Fprintf(&b, "/%d", n)
+		}
+	}
+	return b.String()
+}
+
+// Descriptor returns the output descriptor of the wallet with its checksum,
+// of the address type its path purpose selects, e.g.
+// "pkh([d34db33f/44'/0'/0']xpub.../0/*)#checksum" or wpkh() for a BIP84
+// wallet, to import the wallet as watch-only into Bitcoin Core or Sparrow. A
+// wallet at the chain level (m/44'/0'/0'/0) yields a ranged descriptor
+// covering every address index of the chain.
+func (s *Wallet) Descriptor() (string, error) {
+	return s.DescriptorOfType(s.AddressType())
+}
+
+// DescriptorOfType returns the output descriptor of the wallet for the given
+// address type, such as wpkh() for AddressTypeP2WPKH or tr() for
+// AddressTypeP2TR, like Descriptor.
+func (s *Wallet) DescriptorOfType(addrType AddressType) (string, error) {
+	descs, err := s.descriptorsOfType(addrType)
+	if err != nil {
+		return "", err
+	}
+	return descs[0], nil
+}
+
+// descriptors returns the output descriptors of the wallet, of the address
+// type its path purpose selects.
+func (s *Wallet) descriptors() ([]string, error) {
+	return s.descriptorsOfType(s.AddressType())
+}
+
+// descriptorsOfType returns the output descriptors of the wallet for an
+// address type with their checksums. A wallet at the account or external
+// chain level of a BIP44 tree yields the ranged receive descriptor followed
+// by the ranged change one.
+func (s *Wallet) descriptorsOfType(addrType AddressType) ([]string, error) {
+	origin, err := s.keyOrigin()
+	if err != nil {
+		return nil, err
+	}
+	xpub, err := origin.key.Neuter()
+	if err != nil {
+		return nil, err
+	}
+
+	keyExprs := []string{fmt.Sprintf("[%s]%s%s", origin, xpub, formatPathLevels(origin.suffix))}
+	if len(origin.path) == accountLevels && len(origin.suffix) <= 1 {
+		chains := []uint32{0, 1}
+		if len(origin.suffix) == 1 && origin.suffix[0] != 0 {
+			chains = origin.suffix[:1]
+		}
+		keyExprs = keyExprs[:0]
+		for _, chain := range chains {
+			keyExprs = append(keyExprs, fmt.Sprintf("[%s]%s/%d/*", origin, xpub, chain))
+		}
+	}
+
+	descs := make([]string, 0, len(keyExprs))
+	for _, keyExpr := range keyExprs {
+		desc, err := formatDescriptor(addrType, keyExpr)
+		if err != nil {
+			return nil, err
+		}
+		if desc, err = AddDescriptorChecksum(desc); err != nil {
+			return nil, err
+		}
+		descs = append(descs, desc)
+	}
+	return descs, nil
+}
+
+// descriptorFunctions are the script expressions of single-key descriptors.
+var descriptorFunctions = []struct {
+	addrType AddressType
+	prefix   string
+	suffix   string
+}{
+	{AddressTypeP2PKH, "pkh(", ")"},
+	{AddressTypeP2SH, "sh(wpkh(", "))"},
+	{AddressTypeP2WPKH, "wpkh(", ")"},
+	{AddressTypeP2TR, "tr(", ")"},
+}
+
+// formatDescriptor formats the single-key descriptor of an address type
+// around a key expression, without checksum.
+func formatDescriptor(addrType AddressType, keyExpr string) (string, error) {
+	for _, function := range descriptorFunctions {
+		if function.addrType == addrType {
+			return function.prefix + keyExpr + function.suffix, nil
+		}
+	}
+	return "", ErrUnsupportedAddressType
+}
+
+// parseDescriptor parses a single-key descriptor, checking its checksum when
+// present. It returns the address type of the descriptor, the origin of its
+// key expression, the key itself being its origin when the descriptor omits
+// it, and whether the descriptor is ranged.
+func parseDescriptor(desc string) (AddressType, *keyOrigin, bool, error) {
+	if i := strings.LastIndex(desc, "#"); i >= 0 {
+		checksum, err := DescriptorChecksum(desc[:i])
+		if err != nil {
+			return "", nil, false, err
+		}
+		if checksum != desc[i+1:] {
+			return "", nil, false, ErrInvalidDescriptorChecksum
+		}
+		desc = desc[:i]
+	}
+
+	for _, function := range descriptorFunctions {
+		if strings.HasPrefix(desc, function.prefix) && strings.HasSuffix(desc, function.suffix) {
+			keyExpr := desc[len(function.prefix) : len(desc)-len(function.suffix)]
+			if strings.ContainsAny(keyExpr, "(),") {
+				break
+			}
+			origin, ranged, err := parseDescriptorKey(keyExpr, function.addrType)
+			return function.addrType, origin, ranged, err
+		}
+	}
+	if strings.Contains(desc, "(") {
+		return "", nil, false, ErrUnsupportedAddressType
+	}
+	return "", nil, false, ErrInvalidDescriptor
+}
+
+// parsePKHDescriptor parses a pkh() descriptor like parseDescriptor.
+func parsePKHDescriptor(desc string) (*keyOrigin, bool, error) {
+	addrType, origin, ranged, err := parseDescriptor(desc)
+	if err != nil {
+		return nil, false, err
+	}
+	if addrType != AddressTypeP2PKH {
+		return nil, false, ErrUnsupportedAddressType
+	}
+	return origin, ranged, nil
+}
+
+// parseDescriptorKey parses an extended key expression such as
+// "[d34db33f/44'/0'/0']xpub.../0/*" used for addresses of addrType.
+func parseDescriptorKey(expr string, addrType AddressType) (*keyOrigin, bool, error) {
+	origin := &keyOrigin{}
+	hasOrigin := strings.HasPrefix(expr, "[")
+	if hasOrigin {
+		end := strings.Index(expr, "]")
+		if end < 0 {
+			return nil, false, ErrInvalidDescriptor
+		}
+		levels := strings.Split(expr[1:end], "/")
+		fp, err := hex.DecodeString(levels[0])
+		if err != nil || len(fp) != 4 {
+			return nil, false, ErrInvalidDescriptor
+		}
+		origin.fingerprint = binary.BigEndian.Uint32(fp)
+		if origin.path, err = parsePathLevels(levels[1:]); err != nil {
+			return nil, false, err
+		}
+		expr = expr[end+1:]
+	}
+
+	levels := strings.Split(expr, "/")
+	ranged := levels[len(levels)-1] == "*"
+	if ranged {
+		levels = levels[:len(levels)-1]
+	}
+	key, _, err := parseExtendedKey(levels[0], addrType)
+	if err != nil {
+		return nil, false, err
+	}
+	origin.key = key
+	if origin.suffix, err = parsePathLevels(levels[1:]); err != nil {
+		return nil, false, err
+	}
+	if !hasOrigin {
+		if origin.fingerprint, err = keyFingerprint(key); err != nil {
+			return nil, false, err
+		}
+	}
+	return origin, ranged, nil
+}
+
+// parsePathLevels parses derivation levels such as ["44'", "0h", "0"].
+func parsePathLevels(levels []string) (Path, error) {
+	path := make(Path, 0, len(levels))
+	for _, level := range levels {
+		hardened := strings.HasSuffix(level, "'") || strings.HasSuffix(level, "h")
+		if hardened {
+			level = level[:len(level)-1]
+		}
+		n, err := strconv.ParseUint(level, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPath, err)
+		}
+		if hardened {
+			n += hdkeychain.HardenedKeyStart
+		}
+		path = append(path, uint32(n))
+	}
+	return path, nil
+}
+
+// DescriptorAddress derives the address of a single-key descriptor at index.
+// The index is ignored by descriptors that are not ranged.
+func DescriptorAddress(desc string, network Network, index uint32) (string, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return "", err
+	}
+	addrType, origin, ranged, err := parseDescriptor(desc)
+	if err != nil {
+		return "", err
+	}
+	if !origin.key.IsForNet(params) {
+		return "", ErrDescriptorNetwork
+	}
+
+	path := origin.suffix
+	if ranged {
+		path = append(path[:len(path):len(path)], index)
+	}
+	key := origin.key
+	for _, n := range path {
+		if key, err = deriveKey(key, n); err != nil {
+			return "", err
+		}
+	}
+	publicKey, err := key.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+	addr, err := publicKeyAddress(publicKey, addrType, params)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// DescriptorChecksum computes the BIP380 checksum of a descriptor.
+func DescriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clscount := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos == -1 {
+			return "", ErrInvalidDescriptorChar
+		}
+		c = descriptorPolymod(c, pos&31)
+		cls = cls*3 + (pos >> 5)
+		clscount++
+		if clscount == 3 {
+			c = descriptorPolymod(c, cls)
+			cls, clscount = 0, 0
+		}
+	}
+	if clscount > 0 {
+		c = descriptorPolymod(c, cls)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(checksum), nil
+}
+
+// AddDescriptorChecksum returns the descriptor followed by "#" and its checksum.
+func AddDescriptorChecksum(desc string) (string, error) {
+	checksum, err := DescriptorChecksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + checksum, nil
+}
+
+// descriptorPolymod is the BCH code generator used by descriptor checksums.
+func descriptorPolymod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
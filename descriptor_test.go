@@ -0,0 +1,170 @@
+package p2pkh
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DescriptorChecksum(t *testing.T) {
+	tests := []struct {
+		desc     string
+		expected string
+	}{
+		{"raw(deadbeef)", "89f8spxm"},
+		{"addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)", "02wpgw69"},
+		{"pkh([d6043800/0'/0'/18']03efdee34c0009fd175f3b20b5e5a5517fd5d16746f2e635b44617adafeaebc388)", "4ahsl9pk"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			checksum, err := DescriptorChecksum(test.desc)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, checksum)
+
+			desc, err := AddDescriptorChecksum(test.desc)
+			assert.NoError(t, err)
+			assert.Equal(t, test.desc+"#"+test.expected, desc)
+		})
+	}
+
+	_, err := DescriptorChecksum("raw(dé)")
+	assert.ErrorIs(t, err, ErrInvalidDescriptorChar)
+}
+
+func Test_Wallet_keyOrigin(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+	account, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/0'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	accountXPub, err := account.ExtendedPublicKey()
+	assert.NoError(t, err)
+
+	origin, err := root.keyOrigin()
+	assert.NoError(t, err)
+	xpub, err := origin.key.Neuter()
+	assert.NoError(t, err)
+	assert.Equal(t, accountXPub, xpub.String())
+	assert.Equal(t, "/0", formatPathLevels(origin.suffix))
+	assert.True(t, strings.HasSuffix(origin.String(), "/44'/0'/0'"))
+
+	// Derived wallets keep the master key linkage.
+	child, err := root.Derive(7)
+	assert.NoError(t, err)
+	childOrigin, err := child.keyOrigin()
+	assert.NoError(t, err)
+	assert.Equal(t, origin.String(), childOrigin.String())
+	assert.Equal(t, "/0/7", formatPathLevels(childOrigin.suffix))
+}
+
+func Test_Wallet_Descriptor(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+	origin, err := root.keyOrigin()
+	assert.NoError(t, err)
+	xpub, err := origin.key.Neuter()
+	assert.NoError(t, err)
+
+	desc, err := root.Descriptor()
+	assert.NoError(t, err)
+	expected, err := AddDescriptorChecksum(fmt.Sprintf("pkh([%s]%s/0/*)", origin, xpub))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, desc)
+
+	child, err := root.Derive(2)
+	assert.NoError(t, err)
+	desc, err = child.Descriptor()
+	assert.NoError(t, err)
+	expected, err = AddDescriptorChecksum(fmt.Sprintf("pkh([%s]%s/0/2)", origin, xpub))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, desc)
+
+	// The descriptor type follows the purpose of the path.
+	segwit, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, Purpose: PurposeNativeSegwit})
+	assert.NoError(t, err)
+	origin, err = segwit.keyOrigin()
+	assert.NoError(t, err)
+	xpub, err = origin.key.Neuter()
+	assert.NoError(t, err)
+	desc, err = segwit.Descriptor()
+	assert.NoError(t, err)
+	expected, err = AddDescriptorChecksum(fmt.Sprintf("wpkh([%s]%s/0/*)", origin, xpub))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, desc)
+	assert.Contains(t, desc, "/84'/0'/0']")
+}
+
+func Test_Wallet_DescriptorOfType(t *testing.T) {
+	// BIP86 test vector of the "abandon ... about" mnemonic.
+	wallet, err := New(&Config{
+		Mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		Path:     `m/86'/0'/0'/0`,
+		Network:  NetworkMainnet,
+	})
+	assert.NoError(t, err)
+	desc, err := wallet.DescriptorOfType(AddressTypeP2TR)
+	assert.NoError(t, err)
+	expected, err := AddDescriptorChecksum("tr([73c5da0a/86'/0'/0']xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ/0/*)")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, desc)
+
+	root := createKnownWallet(t, NetworkMainnet)
+	desc, err = root.DescriptorOfType(AddressTypeP2WPKH)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(desc, "wpkh(["))
+	child, err := root.Derive(3)
+	assert.NoError(t, err)
+	address, err := DescriptorAddress(desc, NetworkMainnet, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressP2WPKH().String(), address)
+
+	_, err = root.DescriptorOfType(AddressType("p2a"))
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+}
+
+func Test_Wallet_descriptors(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+	origin, err := root.keyOrigin()
+	assert.NoError(t, err)
+	xpub, err := origin.key.Neuter()
+	assert.NoError(t, err)
+
+	receive, err := AddDescriptorChecksum(fmt.Sprintf("pkh([%s]%s/0/*)", origin, xpub))
+	assert.NoError(t, err)
+	change, err := AddDescriptorChecksum(fmt.Sprintf("pkh([%s]%s/1/*)", origin, xpub))
+	assert.NoError(t, err)
+
+	descs, err := root.descriptors()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{receive, change}, descs)
+
+	changeWallet, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/0'/1`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	descs, err = changeWallet.descriptors()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{change}, descs)
+}
+
+func Test_DescriptorAddress(t *testing.T) {
+	// BIP86 test vector of the "abandon ... about" mnemonic.
+	desc := "tr([73c5da0a/86'/0'/0']xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ/0/*)"
+	address, err := DescriptorAddress(desc, NetworkMainnet, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", address)
+
+	wallet := createKnownWallet(t, NetworkMainnet)
+	descs, err := wallet.descriptors()
+	assert.NoError(t, err)
+	child, err := wallet.Derive(7)
+	assert.NoError(t, err)
+	address, err = DescriptorAddress(descs[0], NetworkMainnet, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressHex(), address)
+
+	_, err = DescriptorAddress(descs[0], NetworkTestnet, 7)
+	assert.ErrorIs(t, err, ErrDescriptorNetwork)
+	_, err = DescriptorAddress("multi(1,"+desc+")", NetworkMainnet, 0)
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+	_, err = DescriptorAddress(descs[0][:len(descs[0])-1]+"x", NetworkMainnet, 0)
+	assert.ErrorIs(t, err, ErrInvalidDescriptorChecksum)
+}
@@ -0,0 +1,97 @@
+package p2pkh
+
+import (
+	"context"
+)
+
+const ErrDiscoveryGapLimit Error = "discovery gap limit cannot be negative"
+
+// UsedAddress is an address of the wallet account found used by Discover.
+type UsedAddress struct {
+	// Chain is 0 for the external chain, 1 for the change chain.
+	Chain   uint32
+	Index   uint32
+	Address string
+	Balance Balance
+}
+
+// Discovery is the result of the address discovery of an account.
+type Discovery struct {
+	// Used lists the used addresses, external chain first, by index.
+	Used []UsedAddress
+	// NextExternal and NextInternal are the indexes following the last used
+	// address of each chain, the next addresses to hand out.
+	NextExternal uint32
+	NextInternal uint32
+}
+
+// Balance returns the balance of the used addresses.
+func (s *Discovery) Balance() Balance {
+	var total Balance
+	for _, used := range s.Used {
+		total.add(used.Balance)
+	}
+	return total
+}
+
+// Discover scans the external and change chains of the wallet account
+// through the wallet backend, following the BIP44 address discovery: a chain
+// ends after gapLimit consecutive unused addresses, 20 when gapLimit is 0.
+// An address is used when it has a transaction history, if the backend is an
+// AddressHistoryProvider, or else a balance.
+func (s *Wallet) Discover(ctx context.Context, gapLimit int) (*Discovery, error) {
+	if s.backend == nil {
+		return nil, ErrNoBackend
+	}
+	if gapLimit < 0 {
+		return nil, ErrDiscoveryGapLimit
+	}
+	if gapLimit == 0 {
+		gapLimit = defaultGapLimit
+	}
+
+	discovery := &Discovery{}
+	for chain, next := range []*uint32{&discovery.NextExternal, &discovery.NextInternal} {
+		for index, gap := uint32(0), 0; gap < gapLimit; index++ {
+			wallet, err := s.At(uint32(chain), index)
+			if err != nil {
+				return nil, err
+			}
+			address := wallet.AddressInfo().String()
+			balance, used, err := addressUsage(ctx, s.backend, address)
+			if err != nil {
+				return nil, err
+			}
+			if !used {
+				gap++
+				continue
+			}
+			gap = 0
+			*next = index + 1
+			discovery.Used = append(discovery.Used, UsedAddress{
+				Chain:   uint32(chain),
+				Index:   index,
+				Address: address,
+				Balance: *balance,
+			})
+		}
+	}
+	return discovery, nil
+}
+
+// addressUsage returns the balance of an address and whether it is used.
+func addressUsage(ctx context.Context, backend ChainBackend, address string) (*Balance, bool, error) {
+	balance, err := chainBalance(ctx, backend, address)
+	if err != nil {
+		return nil, false, err
+	}
+	history, ok := backend.(AddressHistoryProvider)
+	if !ok {
+		return balance, balance.Confirmed != 0 || balance.Unconfirmed != 0, nil
+	}
+	txids, err := history.AddressTxIDs(ctx, address)
+	if err != nil {
+		return nil, false, err
+	}
+	return balance, len(txids) > 0, nil
+}
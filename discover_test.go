@@ -0,0 +1,58 @@
+package p2pkh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Discover(t *testing.T) {
+	ctx := context.Background()
+	wallet := createKnownWallet(t, NetworkMainnet)
+	backend := newFakeBackend()
+	at := func(chain, index uint32) *Wallet {
+		child, err := wallet.At(chain, index)
+		assert.NoError(t, err)
+		return child
+	}
+
+	backend.addHistory(at(0, 0).AddressHex(), "aa")
+	backend.addHistory(at(0, 3).AddressHex(), "bb")
+	backend.setBalance(at(0, 3).AddressHex(), 5000, 0)
+	backend.addHistory(at(1, 1).AddressHex(), "cc")
+	// Beyond the gap limit of the external chain.
+	backend.addHistory(at(0, 9).AddressHex(), "dd")
+
+	_, err := wallet.Discover(ctx, 5)
+	assert.ErrorIs(t, err, ErrNoBackend)
+
+	discovery, err := wallet.WithBackend(backend).Discover(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4), discovery.NextExternal)
+	assert.Equal(t, uint32(2), discovery.NextInternal)
+	assert.Equal(t, []UsedAddress{
+		{Chain: 0, Index: 0, Address: at(0, 0).AddressHex()},
+		{Chain: 0, Index: 3, Address: at(0, 3).AddressHex(), Balance: Balance{Confirmed: 5000}},
+		{Chain: 1, Index: 1, Address: at(1, 1).AddressHex()},
+	}, discovery.Used)
+	assert.Equal(t, Balance{Confirmed: 5000}, discovery.Balance())
+
+	// The default gap limit reaches the address at index 9.
+	discovery, err = wallet.WithBackend(backend).Discover(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), discovery.NextExternal)
+
+	// Without history, addresses are used when they hold funds.
+	discovery, err = wallet.WithBackend(utxoBackend{backend}).Discover(ctx, 5)
+	assert.NoError(t, err)
+	assert.Empty(t, discovery.Used)
+	backend.addUTXOs(at(1, 2).AddressHex(), walletUTXO(t, at(1, 2), 0, 1000))
+	discovery, err = wallet.WithBackend(utxoBackend{backend}).Discover(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), discovery.NextExternal)
+	assert.Equal(t, uint32(3), discovery.NextInternal)
+
+	_, err = wallet.WithBackend(backend).Discover(ctx, -1)
+	assert.ErrorIs(t, err, ErrDiscoveryGapLimit)
+}
@@ -0,0 +1,425 @@
+package p2pkh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrDraftNoInputs       Error = "draft has no inputs"
+	ErrDraftNoOutputs      Error = "draft has no outputs"
+	ErrDraftInvalidAmount  Error = "draft amounts must be positive"
+	ErrDraftFeeMismatch    Error = "draft fee does not match inputs minus outputs"
+	ErrDraftNetwork        Error = "draft network does not match the wallet network"
+	ErrInvalidTxID         Error = "invalid transaction id"
+	ErrForeignInput        Error = "input key is not derived from this wallet"
+	ErrInputScriptMismatch Error = "input script does not match the wallet key"
+	ErrOutputAddress       Error = "invalid output address"
+
+	draftTxVersion = 2
+)
+
+// DraftInput is an UTXO spent by a Draft. Path is the derivation path of the
+// key able to sign it; an empty path designates the key of the signing wallet.
+type DraftInput struct {
+	UTXO
+	Path string `json:"path,omitempty"`
+}
+
+// MarshalJSON encodes the input as a flat object.
+func (s DraftInput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		utxoJSON
+		Path string `json:"path,omitempty"`
+	}{
+		utxoJSON: utxoJSON{
+			TxID:     s.TxID,
+			Vout:     s.Vout,
+			Amount:   s.Amount,
+			PkScript: hex.EncodeToString(s.PkScript),
+		},
+		Path: s.Path,
+	})
+}
+
+// UnmarshalJSON decodes an input encoded by MarshalJSON.
+func (s *DraftInput) UnmarshalJSON(data []byte) error {
+	var path struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(data, &path); err != nil {
+		return err
+	}
+	if err := s.UTXO.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	s.Path = path.Path
+	return nil
+}
+
+// DraftOutput is a payment of Amount satoshis to Address. Change marks the
+// outputs returning funds to the wallet, Path the derivation path of their
+// address when it is not the wallet address, e.g. on the change chain.
+type DraftOutput struct {
+	Address string `json:"address"`
+	Amount  Amount `json:"amount"`
+	Change  bool   `json:"change,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// Draft is an unsigned but fully specified transaction. It separates the
+// construction of a transaction from its authorization: a draft can be
+// serialized, reviewed and approved out-of-band, then signed by the wallet.
+type Draft struct {
+	Network  Network       `json:"network"`
+	Version  int32         `json:"version"`
+	LockTime uint32        `json:"locktime"`
+	Inputs   []DraftInput  `json:"inputs"`
+	Outputs  []DraftOutput `json:"outputs"`
+	Fee      Amount        `json:"fee"`
+	// RBF signals that the transaction may be replaced by one paying a
+	// higher fee (BIP125).
+	RBF bool `json:"rbf,omitempty"`
+}
+
+// NewDraft creates a Draft spending the inputs to the outputs. The fee is
+// whatever the inputs provide on top of the outputs.
+func NewDraft(network Network, inputs []DraftInput, outputs []DraftOutput) (*Draft, error) {
+	d := &Draft{
+		Network: network,
+		Version: draftTxVersion,
+		Inputs:  inputs,
+		Outputs: outputs,
+	}
+	d.Fee = d.InputAmount() - d.OutputAmount()
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ParseDraft decodes and validates a Draft serialized with json.Marshal.
+func ParseDraft(data []byte) (*Draft, error) {
+	d := &Draft{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// InputAmount returns the total amount spent by the draft, in satoshis.
+func (s *Draft) InputAmount() Amount {
+	var total Amount
+	for _, in := range s.Inputs {
+		total += in.Amount
+	}
+	return total
+}
+
+// OutputAmount returns the total amount paid by the draft, in satoshis.
+func (s *Draft) OutputAmount() Amount {
+	var total Amount
+	for _, out := range s.Outputs {
+		total += out.Amount
+	}
+	return total
+}
+
+// Validate checks that the draft is complete and consistent.
+func (s *Draft) Validate() error {
+	if len(s.Inputs) == 0 {
+		return ErrDraftNoInputs
+	}
+	if len(s.Outputs) == 0 {
+		return ErrDraftNoOutputs
+	}
+	params, err := selectNetworkParams(s.Network)
+	if err != nil {
+		return err
+	}
+	for _, in := range s.Inputs {
+		if in.Amount <= 0 {
+			return ErrDraftInvalidAmount
+		}
+		if _, err := chainhash.NewHashFromStr(in.TxID); err != nil || len(in.TxID) != chainhash.MaxHashStringSize {
+			return ErrInvalidTxID
+		}
+	}
+	for _, out := range s.Outputs {
+		if out.Amount <= 0 {
+			return ErrDraftInvalidAmount
+		}
+		if _, err := decodeAddress(out.Address, params); err != nil {
+			return fmt.Errorf("%w %q: %w", ErrOutputAddress, out.Address, err)
+		}
+	}
+	if err := s.checkTotals(); err != nil {
+		return err
+	}
+	if s.Fee < 0 || s.Fee != s.InputAmount()-s.OutputAmount() {
+		return ErrDraftFeeMismatch
+	}
+	return nil
+}
+
+// checkTotals checks that the amounts of the inputs and outputs add up
+// without overflow, and to no more than the supply.
+func (s *Draft) checkTotals() error {
+	var total Amount
+	var err error
+	for _, in := range s.Inputs {
+		if total, err = total.Add(in.Amount); err != nil {
+			return err
+		}
+	}
+	if _, err := checkAmountRange(total); err != nil {
+		return err
+	}
+	total = 0
+	for _, out := range s.Outputs {
+		if total, err = total.Add(out.Amount); err != nil {
+			return err
+		}
+	}
+	_, err = checkAmountRange(total)
+	return err
+}
+
+// Hash returns a digest of the draft content, suitable to bind an out-of-band
+// approval to the exact transaction that will be signed.
+func (s *Draft) Hash() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Tx builds the unsigned transaction described by the draft.
+func (s *Draft) Tx() (*wire.MsgTx, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := selectNetworkParams(s.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(s.Version)
+	tx.LockTime = s.LockTime
+	for _, in := range s.Inputs {
+		hash, err := chainhash.NewHashFromStr(in.TxID)
+		if err != nil {
+			return nil, ErrInvalidTxID
+		}
+		txIn := wire.NewTxIn(wire.NewOutPoint(hash, in.Vout), nil, nil)
+		if s.RBF {
+			txIn.Sequence = rbfSequence
+		}
+		tx.AddTxIn(txIn)
+	}
+	for _, out := range s.Outputs {
+		addr, err := btcutil.DecodeAddress(out.Address, params)
+		if err != nil {
+			return nil, err
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddTxOut(wire.NewTxOut(int64(out.Amount), script))
+	}
+	return tx, nil
+}
+
+// SignDraft signs every input of the draft with the wallet key, or with the
+// child key designated by the input path, and returns the serialized
+// transaction ready to be broadcast.
+func (s *Wallet) SignDraft(draft *Draft) ([]byte, error) {
+	if draft.Network != s.network {
+		return nil, ErrDraftNetwork
+	}
+	tx, err := draft.Tx()
+	if err != nil {
+		return nil, err
+	}
+
+	sigHashes := draftSigHashes(tx, draft.Inputs)
+	for i, in := range draft.Inputs {
+		if err := s.signDraftInput(tx, sigHashes, i, in); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// draftSigHashes returns the signature hashes of the transaction of a draft,
+// which commit to the outputs spent by its inputs.
+func draftSigHashes(tx *wire.MsgTx, inputs []DraftInput) *txscript.TxSigHashes {
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, in := range inputs {
+		fetcher.AddPrevOut(tx.TxIn[i].PreviousOutPoint, wire.NewTxOut(int64(in.Amount), in.PkScript))
+	}
+	return txscript.NewTxSigHashes(tx, fetcher)
+}
+
+// signDraftInput signs the single-key input i of tx, spending the draft input
+// in, with the signature hash algorithm of its address scheme: a scriptSig
+// for P2PKH, a BIP143 witness for P2WPKH and P2SH-P2WPKH, whose scriptSig
+// pushes the redeemScript, and a BIP341 key path witness for P2TR.
+func (s *Wallet) signDraftInput(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, i int, in DraftInput) error {
+	key, err := s.inputKey(in.Path)
+	if err != nil {
+		return err
+	}
+	privateKey, err := key.ECPrivKey()
+	if err != nil {
+		return err
+	}
+	// Uncompressed keys only have P2PKH scripts.
+	if s.uncompressed {
+		if err := checkP2PKHScript(in.PkScript, privateKey.PubKey(), false); err != nil {
+			return err
+		}
+		return s.signP2PKHDraftInput(tx, i, in, privateKey)
+	}
+
+	addrType, err := ScriptAddressType(in.PkScript)
+	if err != nil {
+		return err
+	}
+	scheme, err := addressScheme(addrType)
+	if err != nil {
+		return err
+	}
+	script, err := scheme.Script(privateKey.PubKey(), s.params)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(script, in.PkScript) {
+		return ErrInputScriptMismatch
+	}
+
+	switch scheme.SigHash() {
+	case SigHashLegacy:
+		return s.signP2PKHDraftInput(tx, i, in, privateKey)
+	case SigHashWitnessV0:
+		signature, err := s.witnessV0Signature(tx, sigHashes, i, int64(in.Amount), txscript.SigHashAll, privateKey)
+		if err != nil {
+			return err
+		}
+		publicKey := privateKey.PubKey().SerializeCompressed()
+		if addrType == AddressTypeP2SH {
+			redeemScript := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, btcutil.Hash160(publicKey)...)
+			sigScript, err := txscript.NewScriptBuilder().AddData(redeemScript).Script()
+			if err != nil {
+				return err
+			}
+			tx.TxIn[i].SignatureScript = sigScript
+		}
+		tx.TxIn[i].Witness = wire.TxWitness{signature, publicKey}
+	case SigHashTaproot:
+		witness, err := txscript.TaprootWitnessSignature(tx, sigHashes, i, int64(in.Amount), in.PkScript, txscript.SigHashDefault, privateKey)
+		if err != nil {
+			return err
+		}
+		tx.TxIn[i].Witness = witness
+	default:
+		return ErrUnsupportedAddressType
+	}
+	s.logSign("input", in.Path, inputAttrs(tx, i)...)
+	return nil
+}
+
+// signP2PKHDraftInput signs the P2PKH input i of tx, spending the draft
+// input in.
+func (s *Wallet) signP2PKHDraftInput(tx *wire.MsgTx, i int, in DraftInput, privateKey *btcec.PrivateKey) error {
+	sigScript, err := s.p2pkhSignatureScript(tx, i, in.PkScript, privateKey)
+	if err != nil {
+		return err
+	}
+	tx.TxIn[i].SignatureScript = sigScript
+	s.logSign("input", in.Path, inputAttrs(tx, i)...)
+	return nil
+}
+
+// inputKey returns the extended key designated by an input derivation path,
+// which must be the wallet path or one of its descendants. Hardened levels
+// are marked with "'" or "h", as ParsePath accepts.
+func (s *Wallet) inputKey(path string) (*hdkeychain.ExtendedKey, error) {
+	if path == "" || path == s.path {
+		return s.extendedKey, nil
+	}
+	levels, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if !s.descendantLevels(levels) {
+		return nil, ErrForeignInput
+	}
+
+	key := s.extendedKey
+	for _, index := range levels[len(s.levels):] {
+		if key, err = deriveKey(key, index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// descendantLevels reports whether parsed path levels are the wallet path or
+// one of its descendants, whatever the hardened marker of either path.
+func (s *Wallet) descendantLevels(levels Path) bool {
+	return len(levels) >= len(s.levels) && formatPathLevels(levels[:len(s.levels)]) == formatPathLevels(s.levels)
+}
+
+// pathKey returns the extended key at path: the wallet key, one of its
+// descendants, or another key of the wallet root such as a change key.
+func (s *Wallet) pathKey(path string) (*hdkeychain.ExtendedKey, error) {
+	key, err := s.inputKey(path)
+	if err == nil || !errors.Is(err, ErrForeignInput) || s.root == nil {
+		return key, err
+	}
+	wallet, err := s.rootWallet(path)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.extendedKey, nil
+}
+
+// checkP2PKHScript checks that a scriptPubKey pays to the public key,
+// compressed or not.
+func checkP2PKHScript(pkScript []byte, publicKey *btcec.PublicKey, compressed bool) error {
+	serializedKey := publicKey.SerializeUncompressed()
+	if compressed {
+		serializedKey = publicKey.SerializeCompressed()
+	}
+	expected, err := p2pkhScript(serializedKey)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(expected, pkScript) {
+		return ErrInputScriptMismatch
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMnemonic = "romance trash engine during cliff verify tunnel memory vault chief fluid fox"
+
+// testTxID is an arbitrary previous transaction id used by tests.
+var testTxID = strings.Repeat("ab", 32)
+
+// createKnownWallet creates a wallet from the fixed test mnemonic.
+func createKnownWallet(t *testing.T, network Network) *Wallet {
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Network: network})
+	assert.NoError(t, err, "Failed to create wallet")
+	return wallet
+}
+
+// walletUTXO returns an UTXO paying to the wallet P2PKH address.
+func walletUTXO(t *testing.T, wallet *Wallet, vout uint32, amount Amount) UTXO {
+	script, err := txscript.PayToAddrScript(wallet.Address().AddressPubKeyHash())
+	assert.NoError(t, err)
+	return UTXO{TxID: testTxID, Vout: vout, Amount: amount, PkScript: script}
+}
+
+// verifyTx executes the scripts of every input of a signed transaction.
+func verifyTx(t *testing.T, rawTx []byte, utxos []UTXO) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, utxo := range utxos {
+		fetcher.AddPrevOut(tx.TxIn[i].PreviousOutPoint, wire.NewTxOut(int64(utxo.Amount), utxo.PkScript))
+	}
+	for i, utxo := range utxos {
+		vm, err := txscript.NewEngine(utxo.PkScript, tx, i, txscript.StandardVerifyFlags,
+			nil, txscript.NewTxSigHashes(tx, fetcher), int64(utxo.Amount), fetcher)
+		assert.NoError(t, err)
+		assert.NoError(t, vm.Execute(), "input %d does not verify", i)
+	}
+}
+
+func Test_NewDraft(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 100000)
+
+	draft, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: utxo}},
+		[]DraftOutput{
+			{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 60000},
+			{Address: wallet.AddressHex(), Amount: 39000, Change: true},
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(1000), draft.Fee)
+	assert.Equal(t, Amount(100000), draft.InputAmount())
+	assert.Equal(t, Amount(99000), draft.OutputAmount())
+}
+
+func Test_NewDraft_Errors(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 1000)
+	out := DraftOutput{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 500}
+
+	_, err := NewDraft(NetworkMainnet, nil, []DraftOutput{out})
+	assert.ErrorIs(t, err, ErrDraftNoInputs)
+
+	_, err = NewDraft(NetworkMainnet, []DraftInput{{UTXO: utxo}}, nil)
+	assert.ErrorIs(t, err, ErrDraftNoOutputs)
+
+	_, err = NewDraft(NetworkMainnet, []DraftInput{{UTXO: utxo}}, []DraftOutput{{Address: out.Address, Amount: 2000}})
+	assert.ErrorIs(t, err, ErrDraftFeeMismatch)
+
+	_, err = NewDraft(NetworkMainnet, []DraftInput{{UTXO: utxo}}, []DraftOutput{{Address: out.Address, Amount: 0}})
+	assert.ErrorIs(t, err, ErrDraftInvalidAmount)
+
+	huge := walletUTXO(t, wallet, 1, math.MaxInt64)
+	_, err = NewDraft(NetworkMainnet, []DraftInput{{UTXO: utxo}, {UTXO: huge}}, []DraftOutput{out})
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+
+	bad := utxo
+	bad.TxID = "xyz"
+	_, err = NewDraft(NetworkMainnet, []DraftInput{{UTXO: bad}}, []DraftOutput{out})
+	assert.ErrorIs(t, err, ErrInvalidTxID)
+
+	_, err = NewDraft(NetworkTestnet, []DraftInput{{UTXO: utxo}}, []DraftOutput{out})
+	assert.Error(t, err, "Mainnet address must be rejected on testnet")
+
+	_, err = NewDraft(Network("invalid"), []DraftInput{{UTXO: utxo}}, []DraftOutput{out})
+	assert.ErrorIs(t, err, ErrUnsupportedNet)
+}
+
+func Test_Draft_JSON(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	draft, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: walletUTXO(t, wallet, 1, 50000), Path: `m/44'/0'/0'/0`}},
+		[]DraftOutput{{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 49000}},
+	)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(draft)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"pkScript":"76a914`)
+
+	parsed, err := ParseDraft(data)
+	assert.NoError(t, err)
+	assert.Equal(t, draft, parsed)
+
+	hash, err := draft.Hash()
+	assert.NoError(t, err)
+	parsedHash, err := parsed.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, parsedHash)
+
+	// Tampering with the draft is rejected or changes its hash.
+	parsed.Outputs[0].Amount = 48000
+	assert.ErrorIs(t, parsed.Validate(), ErrDraftFeeMismatch)
+	parsed.Fee = 2000
+	tamperedHash, err := parsed.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, tamperedHash)
+
+	_, err = ParseDraft([]byte(`{"network":"mainnet"}`))
+	assert.ErrorIs(t, err, ErrDraftNoInputs)
+}
+
+func Test_Wallet_SignDraft(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+	child, err := root.Derive(0)
+	assert.NoError(t, err)
+
+	utxos := []UTXO{walletUTXO(t, root, 0, 70000), walletUTXO(t, child, 1, 30000)}
+	draft, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: utxos[0]}, {UTXO: utxos[1], Path: child.Path()}},
+		[]DraftOutput{{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: 99000}},
+	)
+	assert.NoError(t, err)
+
+	rawTx, err := root.SignDraft(draft)
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+
+	// Hardened levels may be marked with "h", as ParsePath accepts.
+	draft.Inputs[1].Path = strings.ReplaceAll(child.Path(), "'", "h")
+	rawTx, err = root.SignDraft(draft)
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+}
+
+func Test_Wallet_SignDraft_Errors(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+	child, err := root.Derive(0)
+	assert.NoError(t, err)
+	out := []DraftOutput{{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: 900}}
+
+	draft, err := NewDraft(NetworkMainnet, []DraftInput{{UTXO: walletUTXO(t, child, 0, 1000)}}, out)
+	assert.NoError(t, err)
+	_, err = root.SignDraft(draft)
+	assert.ErrorIs(t, err, ErrInputScriptMismatch)
+
+	draft.Inputs[0].Path = `m/44'/1'/0'/0`
+	_, err = root.SignDraft(draft)
+	assert.ErrorIs(t, err, ErrForeignInput)
+
+	draft.Inputs[0].Path = root.Path() + "/x"
+	_, err = root.SignDraft(draft)
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	// A watch-only wallet derives no hardened descendant.
+	xpub, err := root.extendedKey.Neuter()
+	assert.NoError(t, err)
+	watch, err := newWalletFromKey(xpub, root.Path(), root.params, NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = watch.inputKey(root.Path() + "/0h")
+	assert.ErrorIs(t, err, ErrHardenedPublic)
+	key, err := watch.inputKey(root.Path() + "/0")
+	assert.NoError(t, err)
+	childXPub, err := child.ExtendedPublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, childXPub, key.String())
+
+	draft.Network = NetworkTestnet
+	_, err = root.SignDraft(draft)
+	assert.ErrorIs(t, err, ErrDraftNetwork)
+}
@@ -0,0 +1,543 @@
+// Package electrum is a chain backend speaking the Electrum protocol, the
+// JSON-RPC of ElectrumX, electrs and Fulcrum servers, over TCP or TLS.
+package electrum
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	p2pkh "github.com/ariden83/p2pkh.go"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	ErrRequest     p2pkh.Error = "electrum request failed"
+	ErrNoEstimate  p2pkh.Error = "electrum has no fee estimate"
+	ErrTargetRange p2pkh.Error = "fee estimate target must be positive"
+	ErrClosed      p2pkh.Error = "electrum connection closed"
+
+	// ProtocolVersion is the version of the protocol negotiated with the
+	// server, which must support it.
+	ProtocolVersion = "1.4"
+	// DefaultTimeout bounds the connection and the requests without
+	// deadline.
+	DefaultTimeout = 30 * time.Second
+
+	clientName = "p2pkh.go"
+	// subscriptionBuffer is the number of status changes of an address kept
+	// for a slow subscriber; older ones are dropped.
+	subscriptionBuffer = 16
+)
+
+// Config configures the connection to a server. The zero value connects
+// over TCP without TLS to a mainnet server.
+type Config struct {
+	// Network is the network of the addresses, mainnet by default.
+	Network p2pkh.Network
+	// TLS enables TLS, e.g. &tls.Config{} for a server with a certificate
+	// signed by a known authority, usually on port 50002. Nil connects over
+	// plain TCP, usually on port 50001.
+	TLS *tls.Config
+	// Proxy routes the connection, e.g. p2pkh.TorProxy(); nil connects
+	// directly.
+	Proxy *p2pkh.ProxyConfig
+	// Timeout bounds the connection and the requests whose context has no
+	// deadline, DefaultTimeout when zero. With a Retrier, it bounds each
+	// attempt.
+	Timeout time.Duration
+	// Retrier rate limits the requests to the server and retries the failed
+	// ones, e.g. timed out; nil sends each request once. Errors returned by
+	// the server and a closed connection are not retried.
+	Retrier *p2pkh.Retrier
+}
+
+// Client is a ChainBackend connected to an Electrum server. It is safe for
+// concurrent use; requests are multiplexed over its single connection.
+type Client struct {
+	conn    net.Conn
+	address string
+	config  Config
+	params  *chaincfg.Params
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	nextID        uint64
+	pending       map[uint64]chan *response
+	subscriptions map[string][]chan string
+	err           error
+	done          chan struct{}
+}
+
+var (
+	_ p2pkh.ChainBackend           = (*Client)(nil)
+	_ p2pkh.BalanceProvider        = (*Client)(nil)
+	_ p2pkh.AddressHistoryProvider = (*Client)(nil)
+)
+
+// request is a JSON-RPC request.
+type request struct {
+	ID     uint64        `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// response is a JSON-RPC response, or a notification when Method is set.
+type response struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// RPCError is returned when the server answers a request with an error.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns the code and the message of the server.
+func (s *RPCError) Error() string {
+	return fmt.Sprintf("%s: %d %s", ErrRequest, s.Code, s.Message)
+}
+
+// Unwrap returns ErrRequest, so that errors.Is(err, ErrRequest) holds.
+func (s *RPCError) Unwrap() error {
+	return ErrRequest
+}
+
+// Dial connects to the server at address, host:port, and negotiates the
+// protocol version.
+func Dial(ctx context.Context, address string, config *Config) (*Client, error) {
+	cfg := Config{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Network == "" {
+		cfg.Network = p2pkh.NetworkMainnet
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	conn, err := cfg.Proxy.DialContext(dialCtx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TLS != nil {
+		tlsConfig := cfg.TLS.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(dialCtx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	client := newClient(conn, cfg, networkParams(cfg.Network))
+	client.address = address
+	var versions []string
+	if err := client.call(ctx, "server.version", &versions, clientName, ProtocolVersion); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// newClient starts reading the responses of a connection.
+func newClient(conn net.Conn, config Config, params *chaincfg.Params) *Client {
+	client := &Client{
+		conn:          conn,
+		config:        config,
+		params:        params,
+		pending:       make(map[uint64]chan *response),
+		subscriptions: make(map[string][]chan string),
+		done:          make(chan struct{}),
+	}
+	go client.read()
+	return client
+}
+
+// Close closes the connection, failing the pending requests and closing
+// the subscription channels.
+func (s *Client) Close() error {
+	err := s.conn.Close()
+	<-s.done
+	return err
+}
+
+// Ping checks that the connection is alive. Servers close the connections
+// idle for a few minutes, which a periodic ping keeps open.
+func (s *Client) Ping(ctx context.Context) error {
+	return s.call(ctx, "server.ping", nil)
+}
+
+// AddressUTXOs returns the unspent outputs paying to the address, mempool
+// ones included.
+func (s *Client) AddressUTXOs(ctx context.Context, address string) ([]p2pkh.UTXO, error) {
+	script, scriptHash, err := s.scriptHash(address)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []struct {
+		TxHash string `json:"tx_hash"`
+		TxPos  uint32 `json:"tx_pos"`
+		Value  int64  `json:"value"`
+	}
+	if err := s.call(ctx, "blockchain.scripthash.listunspent", &outputs, scriptHash); err != nil {
+		return nil, err
+	}
+	utxos := make([]p2pkh.UTXO, 0, len(outputs))
+	for _, output := range outputs {
+		utxos = append(utxos, p2pkh.UTXO{
+			TxID:     output.TxHash,
+			Vout:     output.TxPos,
+			Amount:   p2pkh.Amount(output.Value),
+			PkScript: script,
+		})
+	}
+	return utxos, nil
+}
+
+// AddressBalance returns the confirmed and mempool balance of the address.
+func (s *Client) AddressBalance(ctx context.Context, address string) (*p2pkh.Balance, error) {
+	_, scriptHash, err := s.scriptHash(address)
+	if err != nil {
+		return nil, err
+	}
+	var balance struct {
+		Confirmed   int64 `json:"confirmed"`
+		Unconfirmed int64 `json:"unconfirmed"`
+	}
+	if err := s.call(ctx, "blockchain.scripthash.get_balance", &balance, scriptHash); err != nil {
+		return nil, err
+	}
+	return &p2pkh.Balance{
+		Confirmed:   p2pkh.Amount(balance.Confirmed),
+		Unconfirmed: p2pkh.Amount(balance.Unconfirmed),
+	}, nil
+}
+
+// AddressTxIDs returns the txids of the transactions paying to or spending
+// from the address, the confirmed ones by height, then the mempool ones.
+func (s *Client) AddressTxIDs(ctx context.Context, address string) ([]string, error) {
+	_, scriptHash, err := s.scriptHash(address)
+	if err != nil {
+		return nil, err
+	}
+	var history []struct {
+		TxHash string `json:"tx_hash"`
+	}
+	if err := s.call(ctx, "blockchain.scripthash.get_history", &history, scriptHash); err != nil {
+		return nil, err
+	}
+	txids := make([]string, 0, len(history))
+	for _, tx := range history {
+		txids = append(txids, tx.TxHash)
+	}
+	return txids, nil
+}
+
+// SubscribeAddress subscribes to the changes of the history of the address.
+// The channel receives the current status of the address, a hash of its
+// history empty for an unused address, then the new status after every
+// change, e.g. a payment entering the mempool or confirming. It is closed
+// with the client.
+func (s *Client) SubscribeAddress(ctx context.Context, address string) (<-chan string, error) {
+	_, scriptHash, err := s.scriptHash(address)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(chan string, subscriptionBuffer)
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return nil, s.err
+	}
+	s.subscriptions[scriptHash] = append(s.subscriptions[scriptHash], statuses)
+	s.mu.Unlock()
+
+	var status *string
+	if err := s.call(ctx, "blockchain.scripthash.subscribe", &status, scriptHash); err != nil {
+		s.unsubscribe(scriptHash, statuses)
+		return nil, err
+	}
+	if status == nil {
+		status = new(string)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	notify(statuses, *status)
+	return statuses, nil
+}
+
+// Broadcast publishes a serialized transaction and returns its txid.
+func (s *Client) Broadcast(ctx context.Context, rawTx []byte) (string, error) {
+	var txid string
+	if err := s.call(ctx, "blockchain.transaction.broadcast", &txid, hex.EncodeToString(rawTx)); err != nil {
+		return "", err
+	}
+	return txid, nil
+}
+
+// TxStatus returns the status of a transaction. Transactions unknown to the
+// server have an empty status. The server must return verbose transactions,
+// which electrs does when its bitcoind has a transaction index.
+func (s *Client) TxStatus(ctx context.Context, txid string) (*p2pkh.TxStatus, error) {
+	var tx struct {
+		Confirmations int    `json:"confirmations"`
+		BlockHash     string `json:"blockhash"`
+	}
+	err := s.call(ctx, "blockchain.transaction.get", &tx, txid, true)
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && isNotFound(rpcErr) {
+		return &p2pkh.TxStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tx.Confirmations <= 0 {
+		return &p2pkh.TxStatus{InMempool: true}, nil
+	}
+
+	var tip struct {
+		Height int64 `json:"height"`
+	}
+	if err := s.call(ctx, "blockchain.headers.subscribe", &tip); err != nil {
+		return nil, err
+	}
+	return &p2pkh.TxStatus{
+		Confirmations: tx.Confirmations,
+		BlockHeight:   tip.Height - int64(tx.Confirmations) + 1,
+		BlockHash:     tx.BlockHash,
+	}, nil
+}
+
+// EstimateFeeRate returns the estimate of the server, in sat/vbyte rounded
+// up, for a confirmation within targetBlocks.
+func (s *Client) EstimateFeeRate(ctx context.Context, targetBlocks int) (p2pkh.FeeRate, error) {
+	if targetBlocks <= 0 {
+		return 0, ErrTargetRange
+	}
+	// The estimate is in BTC/kvB, -1 when the server has none.
+	var estimate float64
+	if err := s.call(ctx, "blockchain.estimatefee", &estimate, targetBlocks); err != nil {
+		return 0, err
+	}
+	if estimate <= 0 {
+		return 0, ErrNoEstimate
+	}
+	return p2pkh.FeeRate(math.Ceil(estimate * btcutil.SatoshiPerBitcoin / 1000)), nil
+}
+
+// networkParams returns the parameters of the network of the addresses.
+func networkParams(network p2pkh.Network) *chaincfg.Params {
+	params, err := network.Params()
+	if err != nil {
+		return &chaincfg.MainNetParams
+	}
+	return params
+}
+
+// scriptHash returns the scriptPubKey paid by an address of the network,
+// and its script hash: the reversed SHA256 of the script, in hex, which
+// designates the address in the protocol.
+func (s *Client) scriptHash(address string) ([]byte, string, error) {
+	addr, err := btcutil.DecodeAddress(address, s.params)
+	if err != nil {
+		return nil, "", err
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	hash := p2pkh.ElectrumScriptHash(script)
+	return script, hex.EncodeToString(hash[:]), nil
+}
+
+// call sends a request, through the retrier if any, and decodes its result
+// into result, unless nil.
+func (s *Client) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if s.config.Retrier == nil {
+		return s.send(ctx, method, result, params)
+	}
+	return s.config.Retrier.Do(ctx, s.address, func(ctx context.Context) error {
+		err := s.send(ctx, method, result, params)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) || errors.Is(err, ErrClosed) {
+			return p2pkh.Permanent(err)
+		}
+		return err
+	})
+}
+
+// send sends a request once and decodes its result into result, unless nil.
+func (s *Client) send(ctx context.Context, method string, result interface{}, params []interface{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancel()
+	}
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	responses := make(chan *response, 1)
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return s.err
+	}
+	s.nextID++
+	id := s.nextID
+	s.pending[id] = responses
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	data, err := json.Marshal(&request{ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	s.writeMu.Lock()
+	_ = s.conn.SetWriteDeadline(deadline)
+	_, err = s.conn.Write(append(data, '\n'))
+	s.writeMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.err
+	case resp := <-responses:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+}
+
+// read dispatches the responses and notifications of the server until the
+// connection fails or is closed.
+func (s *Client) read() {
+	reader := bufio.NewReader(s.conn)
+	var err error
+	for {
+		var line []byte
+		if line, err = reader.ReadBytes('\n'); err != nil {
+			break
+		}
+		resp := &response{}
+		if err = json.Unmarshal(line, resp); err != nil {
+			break
+		}
+		s.dispatch(resp)
+	}
+
+	s.mu.Lock()
+	s.err = fmt.Errorf("%w: %w", ErrClosed, err)
+	for _, subscribers := range s.subscriptions {
+		for _, statuses := range subscribers {
+			close(statuses)
+		}
+	}
+	s.subscriptions = nil
+	s.mu.Unlock()
+	s.conn.Close()
+	close(s.done)
+}
+
+// dispatch delivers a response to its request, or a notification to the
+// subscribers of its address.
+func (s *Client) dispatch(resp *response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resp.ID != nil {
+		if responses, ok := s.pending[*resp.ID]; ok {
+			responses <- resp
+		}
+		return
+	}
+	if resp.Method != "blockchain.scripthash.subscribe" {
+		return
+	}
+	var params []*string
+	if err := json.Unmarshal(resp.Params, &params); err != nil || len(params) != 2 || params[0] == nil {
+		return
+	}
+	status := ""
+	if params[1] != nil {
+		status = *params[1]
+	}
+	for _, statuses := range s.subscriptions[*params[0]] {
+		notify(statuses, status)
+	}
+}
+
+// unsubscribe removes a subscriber of a script hash.
+func (s *Client) unsubscribe(scriptHash string, statuses chan string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscribers := s.subscriptions[scriptHash]
+	for i, subscriber := range subscribers {
+		if subscriber == statuses {
+			s.subscriptions[scriptHash] = append(subscribers[:i], subscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+// notify sends a status to a subscriber, dropping its oldest status when
+// its buffer is full.
+func notify(statuses chan string, status string) {
+	for {
+		select {
+		case statuses <- status:
+			return
+		default:
+		}
+		select {
+		case <-statuses:
+		default:
+		}
+	}
+}
+
+// isNotFound reports whether an error of the server is about an unknown
+// transaction.
+func isNotFound(err *RPCError) bool {
+	message := strings.ToLower(err.Message)
+	return strings.Contains(message, "no such mempool or blockchain transaction") ||
+		strings.Contains(message, "not found")
+}
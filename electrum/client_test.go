@@ -0,0 +1,301 @@
+package electrum
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	p2pkh "github.com/ariden83/p2pkh.go"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr"
+
+// testServer is an Electrum server answering requests with the results of
+// its handlers, or their errors.
+type testServer struct {
+	listener net.Listener
+	handlers map[string]func(params []json.RawMessage) (interface{}, *RPCError)
+	conns    chan net.Conn
+}
+
+func newTestServer(t *testing.T) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := &testServer{
+		listener: listener,
+		handlers: map[string]func([]json.RawMessage) (interface{}, *RPCError){
+			"server.version": func([]json.RawMessage) (interface{}, *RPCError) {
+				return []string{"ElectrumX 1.16.0", ProtocolVersion}, nil
+			},
+		},
+		conns: make(chan net.Conn, 1),
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.conns <- conn
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(line, &req); err != nil {
+			return
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		handler, ok := s.handlers[req.Method]
+		if !ok {
+			resp["error"] = &RPCError{Code: -32601, Message: "unknown method"}
+		} else if result, rpcErr := handler(req.Params); rpcErr != nil {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+		data, _ := json.Marshal(resp)
+		if _, err := conn.Write(append(data, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+func (s *testServer) dial(t *testing.T) *Client {
+	client, err := Dial(context.Background(), s.listener.Addr().String(), &Config{Timeout: time.Second})
+	assert.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func Test_Client_ScriptHash(t *testing.T) {
+	client := &Client{params: networkParams(p2pkh.NetworkMainnet)}
+	script, scriptHash, err := client.scriptHash(testAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, "76a914ff6812ef21de2f4f899530808a228931fd6f369588ac", hex.EncodeToString(script))
+	assert.Len(t, scriptHash, 64)
+
+	// The script hash of the genesis output script, from the protocol
+	// documentation.
+	_, scriptHash, err = client.scriptHash("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa")
+	assert.NoError(t, err)
+	assert.Equal(t, "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161", scriptHash)
+
+	_, _, err = client.scriptHash("mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn")
+	assert.Error(t, err)
+}
+
+func Test_Client_Address(t *testing.T) {
+	server := newTestServer(t)
+	var scriptHash string
+	server.handlers["blockchain.scripthash.listunspent"] = func(params []json.RawMessage) (interface{}, *RPCError) {
+		_ = json.Unmarshal(params[0], &scriptHash)
+		return []map[string]interface{}{
+			{"tx_hash": "aa", "tx_pos": 1, "height": 100, "value": 30000},
+			{"tx_hash": "bb", "tx_pos": 0, "height": 0, "value": 2000},
+		}, nil
+	}
+	server.handlers["blockchain.scripthash.get_balance"] = func([]json.RawMessage) (interface{}, *RPCError) {
+		return map[string]int64{"confirmed": 30000, "unconfirmed": 2000}, nil
+	}
+	server.handlers["blockchain.scripthash.get_history"] = func([]json.RawMessage) (interface{}, *RPCError) {
+		return []map[string]interface{}{{"tx_hash": "aa", "height": 100}, {"tx_hash": "bb", "height": 0}}, nil
+	}
+	client := server.dial(t)
+	ctx := context.Background()
+
+	utxos, err := client.AddressUTXOs(ctx, testAddress)
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 2)
+	assert.Equal(t, p2pkh.UTXO{TxID: "aa", Vout: 1, Amount: 30000, PkScript: utxos[0].PkScript}, utxos[0])
+	assert.Equal(t, "76a914ff6812ef21de2f4f899530808a228931fd6f369588ac", hex.EncodeToString(utxos[0].PkScript))
+	_, expected, err := client.scriptHash(testAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, scriptHash)
+
+	balance, err := client.AddressBalance(ctx, testAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, p2pkh.Balance{Confirmed: 30000, Unconfirmed: 2000}, *balance)
+
+	txids, err := client.AddressTxIDs(ctx, testAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aa", "bb"}, txids)
+}
+
+func Test_Client_Tx(t *testing.T) {
+	server := newTestServer(t)
+	server.handlers["blockchain.transaction.broadcast"] = func(params []json.RawMessage) (interface{}, *RPCError) {
+		var rawTx string
+		_ = json.Unmarshal(params[0], &rawTx)
+		if rawTx != "0102" {
+			return nil, &RPCError{Code: 1, Message: "the transaction was rejected by network rules"}
+		}
+		return "cc", nil
+	}
+	server.handlers["blockchain.transaction.get"] = func(params []json.RawMessage) (interface{}, *RPCError) {
+		var txid string
+		_ = json.Unmarshal(params[0], &txid)
+		switch txid {
+		case "aa":
+			return map[string]interface{}{"txid": "aa", "confirmations": 3, "blockhash": "00ff"}, nil
+		case "bb":
+			return map[string]interface{}{"txid": "bb"}, nil
+		}
+		return nil, &RPCError{Code: 2, Message: "daemon error: No such mempool or blockchain transaction"}
+	}
+	server.handlers["blockchain.headers.subscribe"] = func([]json.RawMessage) (interface{}, *RPCError) {
+		return map[string]interface{}{"height": 102, "hex": "00"}, nil
+	}
+	client := server.dial(t)
+	ctx := context.Background()
+
+	txid, err := client.Broadcast(ctx, []byte{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "cc", txid)
+	_, err = client.Broadcast(ctx, []byte{3})
+	assert.ErrorIs(t, err, ErrRequest)
+	assert.Contains(t, err.Error(), "rejected")
+
+	status, err := client.TxStatus(ctx, "aa")
+	assert.NoError(t, err)
+	assert.Equal(t, p2pkh.TxStatus{Confirmations: 3, BlockHeight: 100, BlockHash: "00ff"}, *status)
+	status, err = client.TxStatus(ctx, "bb")
+	assert.NoError(t, err)
+	assert.True(t, status.InMempool)
+	status, err = client.TxStatus(ctx, "dd")
+	assert.NoError(t, err)
+	assert.False(t, status.Known())
+}
+
+func Test_Client_EstimateFeeRate(t *testing.T) {
+	server := newTestServer(t)
+	server.handlers["blockchain.estimatefee"] = func(params []json.RawMessage) (interface{}, *RPCError) {
+		var target int
+		_ = json.Unmarshal(params[0], &target)
+		if target > 100 {
+			return -1, nil
+		}
+		return 0.00012345, nil
+	}
+	client := server.dial(t)
+	ctx := context.Background()
+
+	rate, err := client.EstimateFeeRate(ctx, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, p2pkh.FeeRate(13), rate)
+	_, err = client.EstimateFeeRate(ctx, 1008)
+	assert.ErrorIs(t, err, ErrNoEstimate)
+	_, err = client.EstimateFeeRate(ctx, 0)
+	assert.ErrorIs(t, err, ErrTargetRange)
+}
+
+func Test_Client_SubscribeAddress(t *testing.T) {
+	server := newTestServer(t)
+	server.handlers["blockchain.scripthash.subscribe"] = func([]json.RawMessage) (interface{}, *RPCError) {
+		return nil, nil
+	}
+	client := server.dial(t)
+	conn := <-server.conns
+
+	statuses, err := client.SubscribeAddress(context.Background(), testAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, "", <-statuses)
+
+	_, scriptHash, err := client.scriptHash(testAddress)
+	assert.NoError(t, err)
+	notification, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "blockchain.scripthash.subscribe",
+		"params":  []string{scriptHash, "f1e2"},
+	})
+	assert.NoError(t, err)
+	_, err = conn.Write(append(notification, '\n'))
+	assert.NoError(t, err)
+	assert.Equal(t, "f1e2", <-statuses)
+
+	// Closing the connection closes the subscriptions and fails the
+	// requests.
+	assert.NoError(t, client.Close())
+	_, ok := <-statuses
+	assert.False(t, ok)
+	err = client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func Test_Client_Ping(t *testing.T) {
+	server := newTestServer(t)
+	server.handlers["server.ping"] = func([]json.RawMessage) (interface{}, *RPCError) {
+		return nil, nil
+	}
+	client := server.dial(t)
+	assert.NoError(t, client.Ping(context.Background()))
+}
+
+func Test_Dial_Error(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = Dial(context.Background(), address, nil)
+	assert.Error(t, err)
+}
+
+func Test_Client_Retrier(t *testing.T) {
+	server := newTestServer(t)
+	var calls int32
+	server.handlers["server.ping"] = func([]json.RawMessage) (interface{}, *RPCError) {
+		// The first attempt times out, the next ones succeed.
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return nil, nil
+	}
+	var broadcasts int32
+	server.handlers["blockchain.transaction.broadcast"] = func([]json.RawMessage) (interface{}, *RPCError) {
+		atomic.AddInt32(&broadcasts, 1)
+		return nil, &RPCError{Code: 1, Message: "bad-txns-inputs-missingorspent"}
+	}
+	retrier := p2pkh.NewRetrier(&p2pkh.RetryConfig{BaseDelay: time.Millisecond})
+	client, err := Dial(context.Background(), server.listener.Addr().String(),
+		&Config{Timeout: 30 * time.Millisecond, Retrier: retrier})
+	assert.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	assert.NoError(t, client.Ping(context.Background()))
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(2))
+
+	// Errors of the server are not retried.
+	_, err = client.Broadcast(context.Background(), []byte{1})
+	assert.ErrorIs(t, err, ErrRequest)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&broadcasts))
+
+	// Nor are the requests of a closed connection.
+	assert.NoError(t, client.Close())
+	err = client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.NotErrorIs(t, err, p2pkh.ErrBackendUnavailable)
+}
@@ -0,0 +1,11 @@
+package p2pkh
+
+// Error is an error of the package. Errors are constants, which callers
+// compare with errors.Is, also when they wrap an underlying cause, e.g.
+// errors.Is(err, ErrKeyDerivation).
+type Error string
+
+// Error returns the message of the error.
+func (s Error) Error() string {
+	return string(s)
+}
@@ -0,0 +1,28 @@
+package p2pkh
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Error(t *testing.T) {
+	assert.Equal(t, "mnemonic is required", ErrInvalidMnemonic.Error())
+
+	wrapped := fmt.Errorf("%w: %w", ErrKeyDerivation, errors.New("cause"))
+	assert.ErrorIs(t, wrapped, ErrKeyDerivation)
+	assert.NotErrorIs(t, wrapped, ErrInvalidMnemonic)
+}
+
+func Test_Error_Wrapped(t *testing.T) {
+	_, err := NewFromSeed([]byte{1, 2, 3}, &Config{Path: `m/44'/0'/0'/0`, Network: NetworkMainnet})
+	assert.ErrorIs(t, err, ErrMasterKey)
+
+	err = &BackendUnavailableError{Host: "example.com", Err: errors.New("timeout")}
+	assert.ErrorIs(t, err, ErrBackendUnavailable)
+
+	err = &PolicyError{Violations: []PolicyViolation{{Code: "dust", Message: "dust output"}}}
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+}
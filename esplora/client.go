@@ -0,0 +1,343 @@
+// Package esplora is a chain backend querying the Esplora REST API of
+// Blockstream and mempool.space, or of a self-hosted electrs instance.
+package esplora
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	p2pkh "github.com/ariden83/p2pkh.go"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	ErrRequest     p2pkh.Error = "esplora request failed"
+	ErrNoEstimate  p2pkh.Error = "esplora has no fee estimate"
+	ErrTargetRange p2pkh.Error = "fee estimate target must be positive"
+
+	// DefaultMainnetURL and DefaultTestnetURL are the Blockstream APIs.
+	DefaultMainnetURL = "https://blockstream.info/api"
+	DefaultTestnetURL = "https://blockstream.info/testnet/api"
+	// DefaultTestnet4URL is the mempool.space API, Blockstream not serving
+	// testnet4.
+	DefaultTestnet4URL = "https://mempool.space/testnet4/api"
+
+	// DefaultTimeout bounds the requests of the default HTTP client.
+	DefaultTimeout = 30 * time.Second
+
+	// chainPageSize is the number of confirmed transactions of an address
+	// the server returns per page.
+	chainPageSize = 25
+)
+
+// Client is a ChainBackend querying an Esplora API. The zero value queries
+// the mainnet API of Blockstream directly.
+type Client struct {
+	// BaseURL defaults to the public API of Network: Blockstream, or
+	// mempool.space for testnet4.
+	BaseURL string
+	// Network is the network of the addresses, mainnet by default.
+	Network p2pkh.Network
+	// Client defaults to a client connecting through Proxy, whose requests
+	// time out after Timeout.
+	Client *http.Client
+	// Proxy routes the requests, e.g. p2pkh.TorProxy(); nil connects directly.
+	Proxy *p2pkh.ProxyConfig
+	// Timeout bounds the requests of the default client, DefaultTimeout
+	// when zero.
+	Timeout time.Duration
+	// Retrier rate limits the requests to the server and retries the failed
+	// ones, e.g. p2pkh.NewRetrier(&p2pkh.RetryConfig{RequestsPerSecond: 5})
+	// to stay within the limits of the public APIs. Rejected requests, such
+	// as invalid transactions, are not retried. Nil sends every request once.
+	Retrier *p2pkh.Retrier
+}
+
+var (
+	_ p2pkh.ChainBackend           = (*Client)(nil)
+	_ p2pkh.BalanceProvider        = (*Client)(nil)
+	_ p2pkh.AddressHistoryProvider = (*Client)(nil)
+)
+
+// AddressUTXOs returns the unspent outputs paying to the address, mempool
+// ones included.
+func (s *Client) AddressUTXOs(ctx context.Context, address string) ([]p2pkh.UTXO, error) {
+	script, err := s.addressScript(address)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []struct {
+		TxID  string `json:"txid"`
+		Vout  uint32 `json:"vout"`
+		Value int64  `json:"value"`
+	}
+	if err := s.get(ctx, "/address/"+address+"/utxo", &outputs); err != nil {
+		return nil, err
+	}
+	utxos := make([]p2pkh.UTXO, 0, len(outputs))
+	for _, output := range outputs {
+		utxos = append(utxos, p2pkh.UTXO{
+			TxID:     output.TxID,
+			Vout:     output.Vout,
+			Amount:   p2pkh.Amount(output.Value),
+			PkScript: script,
+		})
+	}
+	return utxos, nil
+}
+
+// addressStats are the funding and spending totals of an address.
+type addressStats struct {
+	Funded int64 `json:"funded_txo_sum"`
+	Spent  int64 `json:"spent_txo_sum"`
+}
+
+// AddressBalance returns the confirmed and mempool balance of the address.
+func (s *Client) AddressBalance(ctx context.Context, address string) (*p2pkh.Balance, error) {
+	var info struct {
+		Chain   addressStats `json:"chain_stats"`
+		Mempool addressStats `json:"mempool_stats"`
+	}
+	if err := s.get(ctx, "/address/"+address, &info); err != nil {
+		return nil, err
+	}
+	return &p2pkh.Balance{
+		Confirmed:   p2pkh.Amount(info.Chain.Funded - info.Chain.Spent),
+		Unconfirmed: p2pkh.Amount(info.Mempool.Funded - info.Mempool.Spent),
+	}, nil
+}
+
+// AddressTxIDs returns the txids of the transactions paying to or spending
+// from the address, mempool ones first, then the confirmed ones from the
+// most recent, fetched page by page.
+func (s *Client) AddressTxIDs(ctx context.Context, address string) ([]string, error) {
+	var txs []struct {
+		TxID   string `json:"txid"`
+		Status struct {
+			Confirmed bool `json:"confirmed"`
+		} `json:"status"`
+	}
+	if err := s.get(ctx, "/address/"+address+"/txs", &txs); err != nil {
+		return nil, err
+	}
+	var txids []string
+	for {
+		confirmed := 0
+		for _, tx := range txs {
+			txids = append(txids, tx.TxID)
+			if tx.Status.Confirmed {
+				confirmed++
+			}
+		}
+		if confirmed < chainPageSize {
+			return txids, nil
+		}
+		last := txs[len(txs)-1].TxID
+		txs = txs[:0]
+		if err := s.get(ctx, "/address/"+address+"/txs/chain/"+last, &txs); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Broadcast publishes a serialized transaction and returns its txid.
+func (s *Client) Broadcast(ctx context.Context, rawTx []byte) (string, error) {
+	body, err := s.do(ctx, http.MethodPost, "/tx", hex.EncodeToString(rawTx))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// TxStatus returns the status of a transaction. Transactions unknown to the
+// server have an empty status.
+func (s *Client) TxStatus(ctx context.Context, txid string) (*p2pkh.TxStatus, error) {
+	var status struct {
+		Confirmed   bool   `json:"confirmed"`
+		BlockHeight int64  `json:"block_height"`
+		BlockHash   string `json:"block_hash"`
+	}
+	err := s.get(ctx, "/tx/"+txid+"/status", &status)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+		return &p2pkh.TxStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !status.Confirmed {
+		return &p2pkh.TxStatus{InMempool: true}, nil
+	}
+
+	body, err := s.do(ctx, http.MethodGet, "/blocks/tip/height", "")
+	if err != nil {
+		return nil, err
+	}
+	tip, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &p2pkh.TxStatus{
+		Confirmations: int(tip-status.BlockHeight) + 1,
+		BlockHeight:   status.BlockHeight,
+		BlockHash:     status.BlockHash,
+	}, nil
+}
+
+// EstimateFeeRate returns the estimate, in sat/vbyte rounded up, of the
+// largest confirmation target the server reports within targetBlocks, or of
+// its smallest target when none is.
+func (s *Client) EstimateFeeRate(ctx context.Context, targetBlocks int) (p2pkh.FeeRate, error) {
+	if targetBlocks <= 0 {
+		return 0, ErrTargetRange
+	}
+	var estimates map[string]float64
+	if err := s.get(ctx, "/fee-estimates", &estimates); err != nil {
+		return 0, err
+	}
+	targets := make([]int, 0, len(estimates))
+	for target := range estimates {
+		if n, err := strconv.Atoi(target); err == nil && n > 0 {
+			targets = append(targets, n)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, ErrNoEstimate
+	}
+	sort.Ints(targets)
+	target := targets[0]
+	for _, n := range targets {
+		if n <= targetBlocks {
+			target = n
+		}
+	}
+	return p2pkh.FeeRate(math.Ceil(estimates[strconv.Itoa(target)])), nil
+}
+
+// StatusError is returned when the server answers with an error status.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+// Error returns the status and the message of the server.
+func (s *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", ErrRequest, s.Code, s.Message)
+}
+
+// Unwrap returns ErrRequest, so that errors.Is(err, ErrRequest) holds.
+func (s *StatusError) Unwrap() error {
+	return ErrRequest
+}
+
+// addressScript returns the scriptPubKey paid by an address of the network.
+func (s *Client) addressScript(address string) ([]byte, error) {
+	params, err := s.Network.Params()
+	if err != nil {
+		params = &chaincfg.MainNetParams
+	}
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// baseURL returns the URL of the API without its trailing slash.
+func (s *Client) baseURL() string {
+	switch {
+	case s.BaseURL != "":
+		return strings.TrimSuffix(s.BaseURL, "/")
+	case s.Network == p2pkh.NetworkTestnet:
+		return DefaultTestnetURL
+	case s.Network == p2pkh.NetworkTestnet4:
+		return DefaultTestnet4URL
+	}
+	return DefaultMainnetURL
+}
+
+// get decodes the JSON response of a GET request.
+func (s *Client) get(ctx context.Context, path string, v interface{}) error {
+	body, err := s.do(ctx, http.MethodGet, path, "")
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// do sends a request, through the retrier if any, and returns the body of
+// its successful response.
+func (s *Client) do(ctx context.Context, method, path string, body string) ([]byte, error) {
+	client := s.Client
+	if client == nil {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		client = p2pkh.NewHTTPClient(s.Proxy, timeout)
+		defer client.CloseIdleConnections()
+	}
+	if s.Retrier == nil {
+		return s.send(ctx, client, method, path, body)
+	}
+
+	base, err := url.Parse(s.baseURL())
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	err = s.Retrier.Do(ctx, base.Host, func(ctx context.Context) error {
+		var err error
+		data, err = s.send(ctx, client, method, path, body)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.Code < http.StatusInternalServerError &&
+			statusErr.Code != http.StatusTooManyRequests {
+			return p2pkh.Permanent(err)
+		}
+		return err
+	})
+	return data, err
+}
+
+// send sends a request once and returns the body of its successful
+// response.
+func (s *Client) send(ctx context.Context, client *http.Client, method, path string, body string) ([]byte, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL()+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode, Message: string(bytes.TrimSpace(data))}
+	}
+	return data, nil
+}
@@ -0,0 +1,211 @@
+package esplora
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	p2pkh "github.com/ariden83/p2pkh.go"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr"
+
+func newTestClient(t *testing.T) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/address/"+testAddress+"/utxo", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"txid": "aa", "vout": 1, "value": 30000, "status": {"confirmed": true}}, {"txid": "bb", "vout": 0, "value": 2000, "status": {"confirmed": false}}]`))
+	})
+	mux.HandleFunc("/address/"+testAddress, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"chain_stats": {"funded_txo_sum": 50000, "spent_txo_sum": 20000}, "mempool_stats": {"funded_txo_sum": 2000, "spent_txo_sum": 0}}`))
+	})
+	mux.HandleFunc("/tx", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if string(body) != "0102" {
+			http.Error(w, "sendrawtransaction RPC error: bad-txns", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("cc\n"))
+	})
+	mux.HandleFunc("/tx/aa/status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"confirmed": true, "block_height": 100, "block_hash": "00ff"}`))
+	})
+	mux.HandleFunc("/tx/bb/status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"confirmed": false}`))
+	})
+	mux.HandleFunc("/tx/dd/status", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("/blocks/tip/height", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("102"))
+	})
+	mux.HandleFunc("/fee-estimates", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"1": 20.5, "3": 10.2, "6": 5.0, "144": 1.1}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return &Client{BaseURL: server.URL + "/", Client: server.Client()}
+}
+
+func Test_Client_Address(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	utxos, err := client.AddressUTXOs(ctx, testAddress)
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 2)
+	assert.Equal(t, "aa", utxos[0].TxID)
+	assert.Equal(t, uint32(1), utxos[0].Vout)
+	assert.Equal(t, p2pkh.Amount(30000), utxos[0].Amount)
+	assert.Equal(t, "76a914ff6812ef21de2f4f899530808a228931fd6f369588ac", hex.EncodeToString(utxos[0].PkScript))
+
+	balance, err := client.AddressBalance(ctx, testAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, p2pkh.Balance{Confirmed: 30000, Unconfirmed: 2000}, *balance)
+
+	// Testnet addresses are rejected before any request.
+	_, err = client.AddressUTXOs(ctx, "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn")
+	assert.Error(t, err)
+}
+
+func Test_Client_Tx(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	txid, err := client.Broadcast(ctx, []byte{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "cc", txid)
+
+	_, err = client.Broadcast(ctx, []byte{3})
+	var statusErr *StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusBadRequest, statusErr.Code)
+	assert.Contains(t, err.Error(), "bad-txns")
+
+	status, err := client.TxStatus(ctx, "aa")
+	assert.NoError(t, err)
+	assert.Equal(t, p2pkh.TxStatus{Confirmations: 3, BlockHeight: 100, BlockHash: "00ff"}, *status)
+
+	status, err = client.TxStatus(ctx, "bb")
+	assert.NoError(t, err)
+	assert.True(t, status.InMempool)
+
+	status, err = client.TxStatus(ctx, "dd")
+	assert.NoError(t, err)
+	assert.False(t, status.Known())
+}
+
+func Test_Client_AddressTxIDs(t *testing.T) {
+	page := func(prefix string, count int, confirmed bool) string {
+		txs := make([]string, count)
+		for i := range txs {
+			txs[i] = `{"txid": "` + prefix + strconv.Itoa(i) + `", "status": {"confirmed": ` + strconv.FormatBool(confirmed) + `}}`
+		}
+		return "[" + strings.Join(txs, ",") + "]"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/address/"+testAddress+"/txs", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("[" + strings.Trim(page("m", 2, false), "[]") + "," + strings.Trim(page("a", 25, true), "[]") + "]"))
+	})
+	mux.HandleFunc("/address/"+testAddress+"/txs/chain/a24", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(page("b", 3, true)))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client := &Client{BaseURL: server.URL, Client: server.Client()}
+
+	txids, err := client.AddressTxIDs(context.Background(), testAddress)
+	assert.NoError(t, err)
+	assert.Len(t, txids, 30)
+	assert.Equal(t, []string{"m0", "m1", "a0"}, txids[:3])
+	assert.Equal(t, []string{"a24", "b0", "b1", "b2"}, txids[26:])
+}
+
+func Test_Client_Retrier(t *testing.T) {
+	calls := map[string]int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blocks/tip/height", func(w http.ResponseWriter, r *http.Request) {
+		if calls[r.URL.Path]++; calls[r.URL.Path] < 3 {
+			http.Error(w, "busy", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("102"))
+	})
+	mux.HandleFunc("/tx", func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		http.Error(w, "bad-txns", http.StatusBadRequest)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client := &Client{
+		BaseURL: server.URL,
+		Client:  server.Client(),
+		Retrier: p2pkh.NewRetrier(&p2pkh.RetryConfig{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	}
+	ctx := context.Background()
+
+	body, err := client.do(ctx, http.MethodGet, "/blocks/tip/height", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "102", string(body))
+	assert.Equal(t, 3, calls["/blocks/tip/height"])
+
+	// Rejected transactions are not retried.
+	_, err = client.Broadcast(ctx, []byte{1})
+	assert.ErrorIs(t, err, ErrRequest)
+	assert.Equal(t, 1, calls["/tx"])
+}
+
+func Test_Client_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := &Client{BaseURL: server.URL, Timeout: 20 * time.Millisecond}
+
+	_, err := client.TxStatus(context.Background(), "aa")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRequest)
+}
+
+func Test_Client_EstimateFeeRate(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		target int
+		rate   p2pkh.FeeRate
+	}{
+		{1, 21},
+		{2, 21},
+		{3, 11},
+		{10, 5},
+		{1008, 2},
+	}
+	for _, test := range tests {
+		rate, err := client.EstimateFeeRate(ctx, test.target)
+		assert.NoError(t, err)
+		assert.Equal(t, test.rate, rate, "target %d", test.target)
+	}
+
+	_, err := client.EstimateFeeRate(ctx, 0)
+	assert.ErrorIs(t, err, ErrTargetRange)
+}
+
+func Test_Client_BaseURL(t *testing.T) {
+	assert.Equal(t, DefaultMainnetURL, (&Client{}).baseURL())
+	assert.Equal(t, DefaultTestnetURL, (&Client{Network: p2pkh.NetworkTestnet}).baseURL())
+	assert.Equal(t, DefaultTestnet4URL, (&Client{Network: p2pkh.NetworkTestnet4}).baseURL())
+	assert.Equal(t, "http://localhost:3000", (&Client{BaseURL: "http://localhost:3000/"}).baseURL())
+}
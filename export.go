@@ -0,0 +1,85 @@
+package p2pkh
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+const (
+	ErrExporterExists   Error = "an exporter is already registered with this name"
+	ErrExporterNotFound Error = "no exporter registered with this name"
+	ErrExporterName     Error = "exporter name is required"
+)
+
+// Exporter writes a wallet in a format understood by other wallet software.
+// New target formats are added by registering an Exporter, without touching
+// the core of the package.
+type Exporter interface {
+	// Name is the unique name the exporter is registered under.
+	Name() string
+	// MIMEType is the media type of the exported document.
+	MIMEType() string
+	// Export writes the public (watch-only) description of the wallet.
+	Export(wallet *Wallet, w io.Writer) error
+}
+
+var (
+	exportersMu sync.RWMutex
+	exporters   = map[string]Exporter{}
+)
+
+func init() {
+	for _, e := range []Exporter{&CoreExporter{}, &ElectrumExporter{}, &SparrowExporter{}} {
+		if err := RegisterExporter(e); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterExporter makes an exporter available under its name.
+func RegisterExporter(e Exporter) error {
+	if e.Name() == "" {
+		return ErrExporterName
+	}
+
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+
+	if _, ok := exporters[e.Name()]; ok {
+		return ErrExporterExists
+	}
+	exporters[e.Name()] = e
+	return nil
+}
+
+// LookupExporter returns the exporter registered under the given name.
+func LookupExporter(name string) (Exporter, bool) {
+	exportersMu.RLock()
+	defer exportersMu.RUnlock()
+
+	e, ok := exporters[name]
+	return e, ok
+}
+
+// Exporters returns the names of the registered exporters, sorted.
+func Exporters() []string {
+	exportersMu.RLock()
+	defer exportersMu.RUnlock()
+
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Export writes the wallet with the exporter registered under the given name.
+func (s *Wallet) Export(format string, w io.Writer) error {
+	e, ok := LookupExporter(format)
+	if !ok {
+		return ErrExporterNotFound
+	}
+	return e.Export(s, w)
+}
@@ -0,0 +1,69 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const defaultCoreRange = 1000
+
+// coreDescriptorRequest is an entry of the Bitcoin Core importdescriptors RPC.
+type coreDescriptorRequest struct {
+	Desc      string      `json:"desc"`
+	Timestamp interface{} `json:"timestamp"`
+	Active    bool        `json:"active,omitempty"`
+	Internal  bool        `json:"internal,omitempty"`
+	Range     []int       `json:"range,omitempty"`
+}
+
+// CoreExporter exports the wallet as the JSON argument of the Bitcoin Core
+// importdescriptors RPC, to be loaded into a blank descriptor wallet.
+type CoreExporter struct {
+	// Timestamp is the UNIX time of the wallet creation, from which Core
+	// rescans. Zero means "now", i.e. no rescan.
+	Timestamp int64
+	// Range is the number of addresses Core derives for ranged descriptors.
+	Range int
+}
+
+// Name implements Exporter.
+func (s *CoreExporter) Name() string {
+	return "core"
+}
+
+// MIMEType implements Exporter.
+func (s *CoreExporter) MIMEType() string {
+	return "application/json"
+}
+
+// Export implements Exporter.
+func (s *CoreExporter) Export(wallet *Wallet, w io.Writer) error {
+	descs, err := wallet.descriptors()
+	if err != nil {
+		return err
+	}
+
+	var timestamp interface{} = "now"
+	if s.Timestamp > 0 {
+		timestamp = s.Timestamp
+	}
+	size := s.Range
+	if size <= 0 {
+		size = defaultCoreRange
+	}
+
+	requests := make([]coreDescriptorRequest, 0, len(descs))
+	for i, desc := range descs {
+		request := coreDescriptorRequest{Desc: desc, Timestamp: timestamp}
+		if len(descs) > 1 {
+			request.Active = true
+			request.Internal = i == 1
+			request.Range = []int{0, size - 1}
+		}
+		requests = append(requests, request)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(requests)
+}
@@ -0,0 +1,40 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CoreExporter(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	descs, err := wallet.descriptors()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.Export("core", &buf))
+
+	var requests []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &requests))
+	assert.Len(t, requests, 2)
+	assert.Equal(t, descs[0], requests[0]["desc"])
+	assert.Equal(t, "now", requests[0]["timestamp"])
+	assert.Equal(t, true, requests[0]["active"])
+	assert.Nil(t, requests[0]["internal"])
+	assert.Equal(t, []interface{}{0.0, 999.0}, requests[0]["range"])
+	assert.Equal(t, descs[1], requests[1]["desc"])
+	assert.Equal(t, true, requests[1]["internal"])
+
+	buf.Reset()
+	exporter := &CoreExporter{Timestamp: 1700000000, Range: 20}
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.Export(child, &buf))
+	requests = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &requests))
+	assert.Len(t, requests, 1)
+	assert.Equal(t, 1700000000.0, requests[0]["timestamp"])
+	assert.Nil(t, requests[0]["range"])
+}
@@ -0,0 +1,64 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const electrumSeedVersion = 17
+
+// electrumKeystore is the keystore section of an Electrum wallet file.
+type electrumKeystore struct {
+	Type            string `json:"type"`
+	XPub            string `json:"xpub"`
+	Derivation      string `json:"derivation"`
+	RootFingerprint string `json:"root_fingerprint"`
+}
+
+// electrumWallet is an Electrum wallet file.
+type electrumWallet struct {
+	Keystore      electrumKeystore `json:"keystore"`
+	WalletType    string           `json:"wallet_type"`
+	SeedVersion   int              `json:"seed_version"`
+	UseEncryption bool             `json:"use_encryption"`
+}
+
+// ElectrumExporter exports the wallet account as a watch-only Electrum wallet
+// file. Electrum derives receive and change addresses below the account xpub.
+type ElectrumExporter struct{}
+
+// Name implements Exporter.
+func (s *ElectrumExporter) Name() string {
+	return "electrum"
+}
+
+// MIMEType implements Exporter.
+func (s *ElectrumExporter) MIMEType() string {
+	return "application/json"
+}
+
+// Export implements Exporter.
+func (s *ElectrumExporter) Export(wallet *Wallet, w io.Writer) error {
+	origin, err := wallet.keyOrigin()
+	if err != nil {
+		return err
+	}
+	xpub, err := origin.key.Neuter()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&electrumWallet{
+		Keystore: electrumKeystore{
+			Type:            "bip32",
+			XPub:            xpub.String(),
+			Derivation:      "m" + formatPathLevels(origin.path),
+			RootFingerprint: fmt.Sprintf("%08x", origin.fingerprint),
+		},
+		WalletType:  "standard",
+		SeedVersion: electrumSeedVersion,
+	})
+}
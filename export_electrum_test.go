@@ -0,0 +1,31 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ElectrumExporter(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	account, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/0'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	accountXPub, err := account.ExtendedPublicKey()
+	assert.NoError(t, err)
+	origin, err := wallet.keyOrigin()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.Export("electrum", &buf))
+
+	var file electrumWallet
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &file))
+	assert.Equal(t, "standard", file.WalletType)
+	assert.Equal(t, "bip32", file.Keystore.Type)
+	assert.Equal(t, accountXPub, file.Keystore.XPub)
+	assert.Equal(t, `m/44'/0'/0'`, file.Keystore.Derivation)
+	assert.Len(t, file.Keystore.RootFingerprint, 8)
+	assert.Equal(t, origin.String()[:8], file.Keystore.RootFingerprint)
+}
@@ -0,0 +1,48 @@
+package p2pkh
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// textExporter is a custom exporter writing the wallet address.
+type textExporter struct{}
+
+func (textExporter) Name() string     { return "test-text" }
+func (textExporter) MIMEType() string { return "text/plain" }
+func (textExporter) Export(wallet *Wallet, w io.Writer) error {
+	_, err := io.WriteString(w, wallet.AddressHex())
+	return err
+}
+
+// unnamedExporter is an exporter without name.
+type unnamedExporter struct{ textExporter }
+
+func (unnamedExporter) Name() string { return "" }
+
+func Test_Exporters_Builtins(t *testing.T) {
+	for _, name := range []string{"core", "electrum", "sparrow"} {
+		e, ok := LookupExporter(name)
+		assert.True(t, ok, "exporter %s should be registered", name)
+		assert.Equal(t, name, e.Name())
+		assert.Equal(t, "application/json", e.MIMEType())
+	}
+	assert.Subset(t, Exporters(), []string{"core", "electrum", "sparrow"})
+}
+
+func Test_RegisterExporter(t *testing.T) {
+	assert.NoError(t, RegisterExporter(textExporter{}))
+	assert.ErrorIs(t, RegisterExporter(textExporter{}), ErrExporterExists)
+	assert.ErrorIs(t, RegisterExporter(unnamedExporter{}), ErrExporterName)
+	assert.Contains(t, Exporters(), "test-text")
+
+	wallet := createKnownWallet(t, NetworkMainnet)
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.Export("test-text", &buf))
+	assert.Equal(t, wallet.AddressHex(), buf.String())
+
+	assert.ErrorIs(t, wallet.Export("unknown", &buf), ErrExporterNotFound)
+}
@@ -0,0 +1,97 @@
+package p2pkh
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	ErrInsufficientFunds Error = "insufficient funds to cover the fee"
+	ErrInvalidFeeRate    Error = "fee rate cannot be negative"
+
+	// txOverheadWeight is the weight of version, locktime and in/out counts.
+	txOverheadWeight = 10 * 4
+	// segwitMarkerWeight is the weight of the segwit marker and flag bytes.
+	segwitMarkerWeight = 2
+)
+
+// FeeEstimator estimates the fee rate, in sat/vbyte, for a transaction to
+// confirm within a number of blocks.
+type FeeEstimator interface {
+	EstimateFeeRate(ctx context.Context, targetBlocks int) (FeeRate, error)
+}
+
+// EstimateVSize estimates the virtual size, in vbytes, of a signed
+// transaction spending inputs of the given types to outputs of the given types,
+// from the weights of their address schemes.
+func EstimateVSize(inputs []AddressType, outputs []AddressType) (int64, error) {
+	weight := int64(txOverheadWeight)
+	segwit := false
+	for _, in := range inputs {
+		scheme, err := addressScheme(in)
+		if err != nil {
+			return 0, err
+		}
+		if scheme.InputWeight() == 0 {
+			return 0, ErrUnsupportedAddressType
+		}
+		weight += scheme.InputWeight()
+		segwit = segwit || scheme.SigHash() != SigHashLegacy
+	}
+	if segwit {
+		weight += segwitMarkerWeight
+	}
+	for _, out := range outputs {
+		scheme, err := addressScheme(out)
+		if err != nil {
+			return 0, err
+		}
+		weight += scheme.OutputWeight()
+	}
+	return (weight + 3) / 4, nil
+}
+
+// MaxSendable computes the largest amount, in satoshis, that can be sent to a
+// destination of the given type by spending every UTXO at feeRate sat/vbyte,
+// without change output. It is the figure a "send all" button should show.
+// An amount below the dust threshold of destType, which the network would
+// not relay, is ErrInsufficientFunds.
+func MaxSendable(utxos []UTXO, feeRate FeeRate, destType AddressType) (Amount, error) {
+	if feeRate < 0 {
+		return 0, ErrInvalidFeeRate
+	}
+
+	inputs := make([]AddressType, 0, len(utxos))
+	var total Amount
+	for _, utxo := range utxos {
+		inputType, err := ScriptAddressType(utxo.PkScript)
+		if err != nil {
+			return 0, err
+		}
+		inputs = append(inputs, inputType)
+		if total, err = total.Add(utxo.Amount); err != nil {
+			return 0, err
+		}
+	}
+
+	vsize, err := EstimateVSize(inputs, []AddressType{destType})
+	if err != nil {
+		return 0, err
+	}
+	fee, err := feeRate.Fee(vsize)
+	if err != nil {
+		return 0, err
+	}
+	amount, err := total.Sub(fee)
+	if err != nil {
+		return 0, err
+	}
+	dust, err := addressDustThreshold(destType, defaultDustFeeRate)
+	if err != nil {
+		return 0, err
+	}
+	if amount < dust {
+		return 0, fmt.Errorf("%w: %s left after the fee, below the dust threshold of %s", ErrInsufficientFunds, amount, dust)
+	}
+	return amount, nil
+}
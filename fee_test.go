@@ -0,0 +1,71 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EstimateVSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   []AddressType
+		outputs  []AddressType
+		expected int64
+	}{
+		{"Legacy 1-in 1-out", []AddressType{AddressTypeP2PKH}, []AddressType{AddressTypeP2PKH}, 192},
+		{"Legacy 1-in 2-out", []AddressType{AddressTypeP2PKH}, []AddressType{AddressTypeP2PKH, AddressTypeP2PKH}, 226},
+		{"Native segwit 1-in 2-out", []AddressType{AddressTypeP2WPKH}, []AddressType{AddressTypeP2WPKH, AddressTypeP2WPKH}, 141},
+		{"Nested segwit 1-in 1-out", []AddressType{AddressTypeP2SH}, []AddressType{AddressTypeP2SH}, 134},
+		{"Taproot 1-in 1-out", []AddressType{AddressTypeP2TR}, []AddressType{AddressTypeP2TR}, 111},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vsize, err := EstimateVSize(test.inputs, test.outputs)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, vsize)
+		})
+	}
+
+	_, err := EstimateVSize([]AddressType{AddressTypeP2WSH}, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+	_, err = EstimateVSize(nil, []AddressType{AddressType("invalid")})
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+}
+
+func Test_MaxSendable(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 60000), walletUTXO(t, wallet, 1, 40000)}
+
+	amount, err := MaxSendable(utxos, 10, AddressTypeP2PKH)
+	assert.NoError(t, err)
+	// 2 legacy inputs and 1 legacy output weigh 340 vbytes.
+	assert.Equal(t, Amount(100000-340*10), amount)
+
+	// The estimate covers the size of the signed transaction.
+	draft, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: utxos[0]}, {UTXO: utxos[1]}},
+		[]DraftOutput{{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: amount}},
+	)
+	assert.NoError(t, err)
+	rawTx, err := wallet.SignDraft(draft)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, int64(len(rawTx)), int64(340))
+
+	_, err = MaxSendable(utxos, 1000, AddressTypeP2PKH)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	// 720 satoshis are left at 292 sat/vB, 380 at 293: dust for a legacy
+	// output, whose threshold is 546.
+	amount, err = MaxSendable(utxos, 292, AddressTypeP2PKH)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(720), amount)
+	_, err = MaxSendable(utxos, 293, AddressTypeP2PKH)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = MaxSendable(utxos, -1, AddressTypeP2PKH)
+	assert.ErrorIs(t, err, ErrInvalidFeeRate)
+
+	_, err = MaxSendable([]UTXO{{Amount: 1000, PkScript: []byte{0x6a}}}, 1, AddressTypeP2PKH)
+	assert.ErrorIs(t, err, ErrUnknownScript)
+}
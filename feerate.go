@@ -0,0 +1,60 @@
+package p2pkh
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	ErrFeeRateInvalid Error = "invalid fee rate: expected sat/vB such as \"12\" or \"12 sat/vB\""
+)
+
+// FeeRate is a fee rate in satoshis per virtual byte, the unit of the fee
+// estimates of the backends. Fees are computed from it with the checked Fee
+// and FeeForWeight rather than by multiplying integers.
+type FeeRate int64
+
+// ParseFeeRate strictly parses a fee rate in sat/vB, e.g. "12" or
+// "12 sat/vB". Negative and fractional rates are rejected.
+func ParseFeeRate(s string) (FeeRate, error) {
+	value, unit, _ := strings.Cut(strings.TrimSpace(s), " ")
+	unit = strings.TrimSpace(unit)
+	if unit != "" && !strings.EqualFold(unit, "sat/vB") {
+		return 0, ErrFeeRateInvalid
+	}
+	if value == "" || strings.Trim(value, "0123456789") != "" {
+		return 0, ErrFeeRateInvalid
+	}
+	rate, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, ErrFeeRateInvalid
+	}
+	return FeeRate(rate), nil
+}
+
+// SatPerVByte returns the fee rate in sat/vB.
+func (r FeeRate) SatPerVByte() int64 {
+	return int64(r)
+}
+
+// String formats the fee rate with its unit, e.g. "12 sat/vB".
+func (r FeeRate) String() string {
+	return strconv.FormatInt(int64(r), 10) + " sat/vB"
+}
+
+// Fee returns the fee of a transaction of vsize virtual bytes at the rate,
+// or an error on overflow.
+func (r FeeRate) Fee(vsize int64) (Amount, error) {
+	return Amount(vsize).Mul(int64(r))
+}
+
+// FeeForWeight returns the fee of weight units at the rate, rounded up to
+// the next satoshi, or an error on overflow.
+func (r FeeRate) FeeForWeight(weight int64) (Amount, error) {
+	fee, err := Amount(weight).Mul(int64(r))
+	if err != nil || fee > math.MaxInt64-3 {
+		return 0, ErrAmountOverflow
+	}
+	return (fee + 3) / 4, nil
+}
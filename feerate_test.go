@@ -0,0 +1,54 @@
+package p2pkh
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseFeeRate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected FeeRate
+		err      error
+	}{
+		{"12", 12, nil},
+		{"12 sat/vB", 12, nil},
+		{" 1 sat/vb ", 1, nil},
+		{"0", 0, nil},
+		{"1.5", 0, ErrFeeRateInvalid},
+		{"-1", 0, ErrFeeRateInvalid},
+		{"12 sat/kvB", 0, ErrFeeRateInvalid},
+		{"99999999999999999999", 0, ErrFeeRateInvalid},
+		{"", 0, ErrFeeRateInvalid},
+	}
+	for _, test := range tests {
+		rate, err := ParseFeeRate(test.input)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err, test.input)
+			continue
+		}
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, rate, test.input)
+	}
+}
+
+func Test_FeeRate(t *testing.T) {
+	rate := FeeRate(12)
+	assert.Equal(t, "12 sat/vB", rate.String())
+	assert.Equal(t, int64(12), rate.SatPerVByte())
+
+	fee, err := rate.Fee(141)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(1692), fee)
+	// 565 weight units are 141.25 vB, rounded up.
+	fee, err = rate.FeeForWeight(565)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(1695), fee)
+
+	_, err = FeeRate(math.MaxInt64).Fee(2)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = FeeRate(math.MaxInt64 / 4).FeeForWeight(5)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+}
@@ -0,0 +1,187 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrFilterInvalid Error = "invalid compact block filter"
+	ErrBlockHash     Error = "invalid block hash"
+
+	// filterP and filterM are the Golomb-Rice parameter and the false
+	// positive rate inverse of the BIP158 basic filters.
+	filterP = 19
+	filterM = 784931
+)
+
+// FilterMatcher matches scripts against the BIP158 basic compact block
+// filters served by Neutrino peers and nodes with -blockfilterindex, so that
+// light clients only download the blocks that may pay to or spend from the
+// wallet. Filters have false positives, one block in 784931 for each script,
+// but no false negatives.
+type FilterMatcher struct {
+	scripts [][]byte
+}
+
+// NewFilterMatcher returns a matcher of scriptPubKeys.
+func NewFilterMatcher(scripts [][]byte) *FilterMatcher {
+	return &FilterMatcher{scripts: scripts}
+}
+
+// FilterMatcher returns a matcher of the scripts of count consecutive
+// children of the wallet from start, see Scripts.
+func (s *Wallet) FilterMatcher(start, count uint32) (*FilterMatcher, error) {
+	scripts, err := s.Scripts(start, count)
+	if err != nil {
+		return nil, err
+	}
+	return NewFilterMatcher(scripts), nil
+}
+
+// Match reports whether the basic filter of the block with a hash, in the
+// usual hex byte order, may contain one of the scripts of the matcher.
+func (s *FilterMatcher) Match(blockHash string, filter []byte) (bool, error) {
+	hash, err := chainhash.NewHashFromStr(blockHash)
+	if err != nil || len(blockHash) != chainhash.MaxHashStringSize {
+		return false, ErrBlockHash
+	}
+	reader := bytes.NewReader(filter)
+	n, err := wire.ReadVarInt(reader, 0)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrFilterInvalid, err)
+	}
+	if n == 0 || len(s.scripts) == 0 {
+		return false, nil
+	}
+	if n > uint64(len(filter))*8 {
+		return false, ErrFilterInvalid
+	}
+
+	// The items of the filter are the SipHash of the scripts, keyed with the
+	// block hash, mapped to [0, N*M).
+	k0 := binary.LittleEndian.Uint64(hash[0:8])
+	k1 := binary.LittleEndian.Uint64(hash[8:16])
+	modulus := n * filterM
+	queries := make([]uint64, len(s.scripts))
+	for i, script := range s.scripts {
+		queries[i], _ = bits.Mul64(sipHash24(k0, k1, script), modulus)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i] < queries[j] })
+
+	// The filter holds the Golomb-Rice coded differences of its sorted
+	// items, walked along the sorted queries.
+	stream := bitReader{reader: reader}
+	var value uint64
+	query := 0
+	for i := uint64(0); i < n; i++ {
+		delta, err := stream.golombRice()
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrFilterInvalid, err)
+		}
+		value += delta
+		for queries[query] < value {
+			if query++; query == len(queries) {
+				return false, nil
+			}
+		}
+		if queries[query] == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// bitReader reads a stream of bits, most significant first.
+type bitReader struct {
+	reader io.ByteReader
+	byte   byte
+	left   uint
+}
+
+// bit reads the next bit.
+func (s *bitReader) bit() (uint64, error) {
+	if s.left == 0 {
+		b, err := s.reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		s.byte, s.left = b, 8
+	}
+	s.left--
+	return uint64(s.byte>>s.left) & 1, nil
+}
+
+// golombRice reads a Golomb-Rice coded value: its quotient by 2^P in unary,
+// then its remainder on P bits.
+func (s *bitReader) golombRice() (uint64, error) {
+	var quotient uint64
+	for {
+		bit, err := s.bit()
+		if err != nil {
+			return 0, err
+		}
+		if bit == 0 {
+			break
+		}
+		quotient++
+	}
+	var remainder uint64
+	for i := 0; i < filterP; i++ {
+		bit, err := s.bit()
+		if err != nil {
+			return 0, err
+		}
+		remainder = remainder<<1 | bit
+	}
+	return quotient<<filterP | remainder, nil
+}
+
+// sipHash24 returns the SipHash-2-4 of data with the key k0, k1.
+func sipHash24(k0, k1 uint64, data []byte) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13) ^ v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16) ^ v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21) ^ v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17) ^ v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+	compress := func(m uint64) {
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+	}
+
+	length := len(data)
+	for ; len(data) >= 8; data = data[8:] {
+		compress(binary.LittleEndian.Uint64(data))
+	}
+	var last [8]byte
+	copy(last[:], data)
+	last[7] = byte(length)
+	compress(binary.LittleEndian.Uint64(last[:]))
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
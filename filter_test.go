@@ -0,0 +1,133 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+	"sort"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// testnetGenesis is the hash of the testnet3 genesis block, whose basic
+// filter holds its coinbase output script only.
+const testnetGenesis = "000000000933ea01ad0ee984209779baaec3ced90fa3f408719526f8d77f4943"
+
+// genesisScript is the output script of the genesis coinbase transaction.
+const genesisScript = "4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac"
+
+// buildFilter returns the BIP158 basic filter of a block holding scripts.
+func buildFilter(t *testing.T, blockHash string, scripts [][]byte) []byte {
+	hash, err := chainhash.NewHashFromStr(blockHash)
+	assert.NoError(t, err)
+	k0 := binary.LittleEndian.Uint64(hash[0:8])
+	k1 := binary.LittleEndian.Uint64(hash[8:16])
+	n := uint64(len(scripts))
+	values := make([]uint64, n)
+	for i, script := range scripts {
+		values[i], _ = bits.Mul64(sipHash24(k0, k1, script), n*filterM)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	var out []byte
+	var current byte
+	var used uint
+	writeBit := func(bit uint64) {
+		current = current<<1 | byte(bit)
+		if used++; used == 8 {
+			out, current, used = append(out, current), 0, 0
+		}
+	}
+	var last uint64
+	for _, value := range values {
+		delta := value - last
+		last = value
+		for q := delta >> filterP; q > 0; q-- {
+			writeBit(1)
+		}
+		writeBit(0)
+		for i := filterP - 1; i >= 0; i-- {
+			writeBit(delta >> uint(i) & 1)
+		}
+	}
+	if used > 0 {
+		out = append(out, current<<(8-used))
+	}
+	var prefix bytes.Buffer
+	assert.NoError(t, wire.WriteVarInt(&prefix, 0, n))
+	return append(prefix.Bytes(), out...)
+}
+
+func Test_SipHash24(t *testing.T) {
+	// The vector of the SipHash paper: key 00..0f, message 00..0e.
+	key := make([]byte, 16)
+	message := make([]byte, 15)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	for i := range message {
+		message[i] = byte(i)
+	}
+	k0 := binary.LittleEndian.Uint64(key[0:8])
+	k1 := binary.LittleEndian.Uint64(key[8:16])
+	assert.Equal(t, uint64(0xa129ca6149be45e5), sipHash24(k0, k1, message))
+}
+
+func Test_FilterMatcher(t *testing.T) {
+	script, err := hex.DecodeString(genesisScript)
+	assert.NoError(t, err)
+
+	// The basic filter of the testnet genesis block, from the BIP158 test
+	// vectors.
+	filter, err := hex.DecodeString("019dfca8")
+	assert.NoError(t, err)
+	assert.Equal(t, filter, buildFilter(t, testnetGenesis, [][]byte{script}))
+
+	other, err := hex.DecodeString("76a914ff6812ef21de2f4f899530808a228931fd6f369588ac")
+	assert.NoError(t, err)
+	match, err := NewFilterMatcher([][]byte{other, script}).Match(testnetGenesis, filter)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	match, err = NewFilterMatcher([][]byte{other}).Match(testnetGenesis, filter)
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	// The filter of a block paying to some of the wallet addresses.
+	wallet := createKnownWallet(t, NetworkMainnet)
+	scripts, err := wallet.Scripts(0, 40)
+	assert.NoError(t, err)
+	blockHash := "00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054"
+	filter = buildFilter(t, blockHash, [][]byte{script, scripts[25], other, scripts[3]})
+
+	matcher, err := wallet.FilterMatcher(0, 20)
+	assert.NoError(t, err)
+	match, err = matcher.Match(blockHash, filter)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	matcher, err = wallet.FilterMatcher(20, 5)
+	assert.NoError(t, err)
+	match, err = matcher.Match(blockHash, filter)
+	assert.NoError(t, err)
+	assert.False(t, match)
+	matcher, err = wallet.FilterMatcher(25, 1)
+	assert.NoError(t, err)
+	match, err = matcher.Match(blockHash, filter)
+	assert.NoError(t, err)
+	assert.True(t, match)
+
+	// Empty filters match nothing.
+	match, err = matcher.Match(blockHash, []byte{0})
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	_, err = matcher.Match("00ff", filter)
+	assert.ErrorIs(t, err, ErrBlockHash)
+	_, err = matcher.Match(blockHash, nil)
+	assert.ErrorIs(t, err, ErrFilterInvalid)
+	_, err = matcher.Match(blockHash, []byte{0x20, 0xff})
+	assert.ErrorIs(t, err, ErrFilterInvalid)
+}
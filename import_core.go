@@ -0,0 +1,286 @@
+package p2pkh
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ErrCoreDumpInvalid     Error = "invalid Bitcoin Core wallet dump"
+	ErrCoreDumpNoMasterKey Error = "wallet dump has no HD master key"
+	ErrCoreDumpNetwork     Error = "wallet dump mixes keys of different networks"
+
+	// CoreLegacyPath is the external chain of the HD wallets created by
+	// Bitcoin Core before descriptor wallets, whose keys are m/0'/0'/i'.
+	CoreLegacyPath = `m/0'/0'`
+	// CoreLegacyChangePath is the internal chain of legacy Core wallets.
+	CoreLegacyChangePath = `m/0'/1'`
+
+	coreDumpMasterKeyPrefix = "# extended private masterkey: "
+	coreDumpBestBlockPrefix = "# * Best block at time of backup was "
+)
+
+// CoreDumpKey is a private key listed by the Bitcoin Core dumpwallet RPC.
+type CoreDumpKey struct {
+	WIF       *btcutil.WIF
+	Created   time.Time
+	Addresses []string
+	Label     string
+	// KeyPath is the HD derivation path of the key, empty for imported keys.
+	KeyPath string
+	Change  bool
+	Reserve bool
+	// HDSeed marks the keys used as seed of the HD chains, active or not.
+	HDSeed bool
+}
+
+// CoreDumpScript is a watched script listed by the dumpwallet RPC.
+type CoreDumpScript struct {
+	Script    []byte
+	Created   time.Time
+	Addresses []string
+}
+
+// CoreDump is a parsed Bitcoin Core wallet dump.
+type CoreDump struct {
+	Network   Network
+	BestBlock int64
+	// MasterKey is the HD master key of the wallet, nil for non-HD wallets.
+	MasterKey *hdkeychain.ExtendedKey
+	Keys      []CoreDumpKey
+	Scripts   []CoreDumpScript
+}
+
+// ParseCoreDump parses the file written by the dumpwallet RPC of a legacy
+// Bitcoin Core wallet.
+func ParseCoreDump(r io.Reader) (*CoreDump, error) {
+	dump := &CoreDump{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		var err error
+		switch {
+		case strings.HasPrefix(text, coreDumpMasterKeyPrefix):
+			err = dump.parseMasterKey(strings.TrimPrefix(text, coreDumpMasterKeyPrefix))
+		case strings.HasPrefix(text, coreDumpBestBlockPrefix):
+			height := strings.Fields(strings.TrimPrefix(text, coreDumpBestBlockPrefix))
+			if len(height) > 0 {
+				dump.BestBlock, err = strconv.ParseInt(height[0], 10, 64)
+			}
+		case text == "" || strings.HasPrefix(text, "#"):
+		default:
+			err = dump.parseEntry(text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrCoreDumpInvalid, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if dump.MasterKey == nil && len(dump.Keys) == 0 && len(dump.Scripts) == 0 {
+		return nil, ErrCoreDumpInvalid
+	}
+	return dump, nil
+}
+
+// parseMasterKey parses the extended private master key of the dump.
+func (s *CoreDump) parseMasterKey(xprv string) error {
+	key, err := hdkeychain.NewKeyFromString(xprv)
+	if err != nil {
+		return err
+	}
+	if !key.IsPrivate() {
+		return ErrCoreDumpInvalid
+	}
+	s.MasterKey = key
+	return s.setNetwork(key.IsForNet)
+}
+
+// parseEntry parses a key or script line: "<key> <time> <flags> # addr=<addresses> [hdkeypath=<path>]".
+func (s *CoreDump) parseEntry(text string) error {
+	entry, comment, _ := strings.Cut(text, "#")
+	fields := strings.Fields(entry)
+	if len(fields) < 2 {
+		return ErrCoreDumpInvalid
+	}
+
+	var created time.Time
+	if fields[1] != "0" {
+		var err error
+		if created, err = time.Parse(time.RFC3339, fields[1]); err != nil {
+			return err
+		}
+	}
+
+	var addresses []string
+	var keyPath string
+	for _, field := range strings.Fields(comment) {
+		switch {
+		case strings.HasPrefix(field, "addr="):
+			addresses = strings.Split(strings.TrimPrefix(field, "addr="), ",")
+		case strings.HasPrefix(field, "hdkeypath="):
+			keyPath = strings.TrimPrefix(field, "hdkeypath=")
+		}
+	}
+
+	flags := fields[2:]
+	for _, flag := range flags {
+		if flag == "script=1" {
+			script, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return err
+			}
+			s.Scripts = append(s.Scripts, CoreDumpScript{Script: script, Created: created, Addresses: addresses})
+			return nil
+		}
+	}
+
+	wif, err := btcutil.DecodeWIF(fields[0])
+	if err != nil {
+		return err
+	}
+	if err := s.setNetwork(wif.IsForNet); err != nil {
+		return err
+	}
+
+	// A key listed with the address of its other compression would import
+	// as an empty key.
+	for _, address := range addresses {
+		if err := CheckWIFAddress(fields[0], address, s.Network); err != nil && errors.Is(err, ErrWIFCompression) {
+			return fmt.Errorf("%s: %w", address, err)
+		}
+	}
+
+	key := CoreDumpKey{WIF: wif, Created: created, Addresses: addresses, KeyPath: keyPath}
+	for _, flag := range flags {
+		name, value, _ := strings.Cut(flag, "=")
+		switch name {
+		case "label":
+			if key.Label, err = url.PathUnescape(value); err != nil {
+				return err
+			}
+		case "change":
+			key.Change = value == "1"
+		case "reserve":
+			key.Reserve = value == "1"
+		case "hdseed", "inactivehdseed":
+			key.HDSeed = value == "1"
+		}
+	}
+	s.Keys = append(s.Keys, key)
+	return nil
+}
+
+// setNetwork sets the network of the dump from the network of one of its keys.
+func (s *CoreDump) setNetwork(isForNet func(*chaincfg.Params) bool) error {
+	network := NetworkTestnet
+	if isForNet(&chaincfg.MainNetParams) {
+		network = NetworkMainnet
+	}
+	if s.Network != "" && s.Network != network {
+		return ErrCoreDumpNetwork
+	}
+	s.Network = network
+	return nil
+}
+
+// Wallet returns a signing wallet at path derived from the HD master key of
+// the dump, e.g. at CoreLegacyPath to spend the receive keys of a legacy
+// wallet.
+func (s *CoreDump) Wallet(path string) (*Wallet, error) {
+	if s.MasterKey == nil {
+		return nil, ErrCoreDumpNoMasterKey
+	}
+	return newWallet(s.MasterKey, nil, path, s.Network)
+}
+
+// ImportedKeys returns the keys that do not belong to the HD chains of the
+// dump, which can only be migrated one by one.
+func (s *CoreDump) ImportedKeys() []CoreDumpKey {
+	var keys []CoreDumpKey
+	for _, key := range s.Keys {
+		if key.KeyPath == "" && !key.HDSeed {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// CoreDescriptor is a descriptor listed by the Bitcoin Core listdescriptors
+// RPC, or passed to importdescriptors.
+type CoreDescriptor struct {
+	Desc string `json:"desc"`
+	// Timestamp is the UNIX time from which Core scans the descriptor, zero
+	// meaning "now".
+	Timestamp int64 `json:"-"`
+	Active    bool  `json:"active,omitempty"`
+	Internal  bool  `json:"internal,omitempty"`
+	Range     []int `json:"range,omitempty"`
+	Next      int   `json:"next,omitempty"`
+}
+
+// UnmarshalJSON decodes a descriptor whose timestamp is a number or "now".
+func (s *CoreDescriptor) UnmarshalJSON(data []byte) error {
+	type coreDescriptor CoreDescriptor
+	var desc struct {
+		coreDescriptor
+		Timestamp json.RawMessage `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &desc); err != nil {
+		return err
+	}
+	*s = CoreDescriptor(desc.coreDescriptor)
+	if len(desc.Timestamp) > 0 && string(desc.Timestamp) != `"now"` {
+		return json.Unmarshal(desc.Timestamp, &s.Timestamp)
+	}
+	return nil
+}
+
+// ParseCoreDescriptors parses the output of the listdescriptors RPC, or the
+// argument of importdescriptors such as produced by CoreExporter.
+func ParseCoreDescriptors(data []byte) ([]CoreDescriptor, error) {
+	var descs []CoreDescriptor
+	if err := json.Unmarshal(data, &descs); err != nil {
+		var list struct {
+			Descriptors []CoreDescriptor `json:"descriptors"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		descs = list.Descriptors
+	}
+	if len(descs) == 0 {
+		return nil, ErrInvalidDescriptor
+	}
+	return descs, nil
+}
+
+// Wallet returns the wallet of a pkh() descriptor: a signing wallet when the
+// descriptor holds an extended private key, a watch-only one otherwise. The
+// wallet of a ranged descriptor is the parent of its addresses.
+func (s *CoreDescriptor) Wallet() (*Wallet, error) {
+	origin, _, err := parsePKHDescriptor(s.Desc)
+	if err != nil {
+		return nil, err
+	}
+
+	network := NetworkTestnet
+	if origin.key.IsForNet(&chaincfg.MainNetParams) {
+		network = NetworkMainnet
+	}
+	path := "m" + formatPathLevels(append(origin.path[:len(origin.path):len(origin.path)], origin.suffix...))
+	return newWallet(origin.key, origin, path, network)
+}
@@ -0,0 +1,134 @@
+package p2pkh
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseCoreDump(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	legacy, err := newWallet(wallet.root, nil, CoreLegacyPath, NetworkMainnet)
+	assert.NoError(t, err)
+	receive, err := legacy.Derive(uint32(hdkeychain.HardenedKeyStart))
+	assert.NoError(t, err)
+	receiveWIF, err := receive.PrivateKey()
+	assert.NoError(t, err)
+	importedWIF, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+
+	dump := strings.Join([]string{
+		"# Wallet dump created by Bitcoin v0.21.1",
+		"# * Created on 2021-06-01T10:00:00Z",
+		"# * Best block at time of backup was 686000 (00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054),",
+		"#   mined on 2021-06-01T09:58:12Z",
+		"",
+		"# extended private masterkey: " + wallet.root.String(),
+		"",
+		fmt.Sprintf("%s 2021-06-01T10:00:00Z reserve=1 # addr=%s hdkeypath=m/0'/0'/0'", receiveWIF, receive.AddressHex()),
+		fmt.Sprintf("%s 2019-01-01T00:00:00Z label=Paper%%20wallet # addr=%s", importedWIF, wallet.AddressHex()),
+		"76a914000000000000000000000000000000000000000088ac 0 script=1 # addr=1111111111111111111114oLvT2",
+		"",
+		"# End of dump",
+	}, "\n")
+
+	parsed, err := ParseCoreDump(strings.NewReader(dump))
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkMainnet, parsed.Network)
+	assert.Equal(t, int64(686000), parsed.BestBlock)
+	assert.Len(t, parsed.Keys, 2)
+	assert.Len(t, parsed.Scripts, 1)
+	assert.True(t, parsed.Keys[0].Reserve)
+	assert.Equal(t, "m/0'/0'/0'", parsed.Keys[0].KeyPath)
+	assert.Equal(t, []string{receive.AddressHex()}, parsed.Keys[0].Addresses)
+
+	imported := parsed.ImportedKeys()
+	assert.Len(t, imported, 1)
+	assert.Equal(t, "Paper wallet", imported[0].Label)
+	assert.Equal(t, importedWIF, imported[0].WIF.String())
+
+	// The HD chain is reconstructed from the master key.
+	restored, err := parsed.Wallet(CoreLegacyPath)
+	assert.NoError(t, err)
+	child, err := restored.Derive(uint32(hdkeychain.HardenedKeyStart))
+	assert.NoError(t, err)
+	assert.Equal(t, receive.AddressHex(), child.AddressHex())
+
+	_, err = ParseCoreDump(strings.NewReader("# End of dump\n"))
+	assert.ErrorIs(t, err, ErrCoreDumpInvalid)
+
+	_, err = ParseCoreDump(strings.NewReader("notakey 2021-06-01T10:00:00Z # addr=1abc\n"))
+	assert.ErrorContains(t, err, "line 1")
+
+	testnet := createKnownWallet(t, NetworkTestnet)
+	testnetWIF, err := testnet.PrivateKey()
+	assert.NoError(t, err)
+	_, err = ParseCoreDump(strings.NewReader(importedWIF + " 0\n" + testnetWIF + " 0\n"))
+	assert.ErrorIs(t, err, ErrCoreDumpNetwork)
+
+	uncompressedWIF, err := wallet.PrivateKeyWIF(false)
+	assert.NoError(t, err)
+	_, err = ParseCoreDump(strings.NewReader(uncompressedWIF + " 0 # addr=" + wallet.AddressHex() + "\n"))
+	assert.ErrorIs(t, err, ErrWIFCompression)
+
+	noHD, err := ParseCoreDump(strings.NewReader(importedWIF + " 0\n"))
+	assert.NoError(t, err)
+	_, err = noHD.Wallet(CoreLegacyPath)
+	assert.ErrorIs(t, err, ErrCoreDumpNoMasterKey)
+}
+
+func Test_ParseCoreDescriptors(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.Export("core", &buf))
+
+	descs, err := ParseCoreDescriptors(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, descs, 2)
+	assert.Equal(t, int64(0), descs[0].Timestamp)
+	assert.True(t, descs[1].Internal)
+
+	listed := fmt.Sprintf(`{"wallet_name": "legacy", "descriptors": [{"desc": %q, "timestamp": 1600000000, "active": true, "range": [0, 999], "next": 12}]}`, descs[0].Desc)
+	descs, err = ParseCoreDescriptors([]byte(listed))
+	assert.NoError(t, err)
+	assert.Len(t, descs, 1)
+	assert.Equal(t, int64(1600000000), descs[0].Timestamp)
+	assert.Equal(t, 12, descs[0].Next)
+
+	// The watch-only wallet of the receive descriptor derives the addresses
+	// of the original wallet.
+	watch, err := descs[0].Wallet()
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.Path(), watch.Path())
+	assert.Equal(t, wallet.AddressHex(), watch.AddressHex())
+	watchChild, err := watch.Derive(5)
+	assert.NoError(t, err)
+	child, err := wallet.Derive(5)
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressHex(), watchChild.AddressHex())
+	_, err = watch.PrivateKey()
+	assert.Error(t, err)
+
+	// The key origin survives the import.
+	watchDescs, err := watch.descriptors()
+	assert.NoError(t, err)
+	walletDescs, err := wallet.descriptors()
+	assert.NoError(t, err)
+	assert.Equal(t, walletDescs, watchDescs)
+
+	bad := descs[0]
+	bad.Desc = strings.Replace(bad.Desc, "pkh(", "wpkh(", 1)
+	_, err = bad.Wallet()
+	assert.ErrorIs(t, err, ErrInvalidDescriptorChecksum)
+
+	bad.Desc = strings.Split(bad.Desc, "#")[0]
+	_, err = bad.Wallet()
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+
+	_, err = ParseCoreDescriptors([]byte(`{"descriptors": []}`))
+	assert.ErrorIs(t, err, ErrInvalidDescriptor)
+}
@@ -0,0 +1,394 @@
+package p2pkh
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	ErrElectrumInvalid     Error = "invalid Electrum wallet file"
+	ErrElectrumPassword    Error = "invalid or missing Electrum wallet password"
+	ErrElectrumUnsupported Error = "unsupported Electrum wallet type"
+
+	// electrumStorageMagic starts the base64-decoded content of Electrum
+	// wallet files encrypted with a user password.
+	electrumStorageMagic = "BIE1"
+)
+
+// electrumImportedTypes maps the script types of Electrum imported keys.
+var electrumImportedTypes = map[string]AddressType{
+	"p2pkh":       AddressTypeP2PKH,
+	"p2wpkh-p2sh": AddressTypeP2SH,
+	"p2wpkh":      AddressTypeP2WPKH,
+}
+
+// ElectrumKey is a private key of an Electrum "imported" wallet.
+type ElectrumKey struct {
+	AddressType AddressType
+	WIF         *btcutil.WIF
+}
+
+// ElectrumImport is the content of an Electrum wallet file. Depending on the
+// wallet type, exactly one of Wallet, Multisig, Keys or Addresses is set.
+type ElectrumImport struct {
+	// WalletType is the Electrum wallet type: "standard", "imported" or "MofN".
+	WalletType string
+	// Wallet is the wallet of a standard wallet, at the receive chain, of
+	// the script type of its keystore: P2PKH for xpub, P2SH-P2WPKH for ypub
+	// and P2WPKH for zpub keystores. It can sign when the file holds the
+	// xprv, and is watch-only otherwise.
+	Wallet *Wallet
+	// Multisig is the wallet of a multisig file.
+	Multisig *MultisigWallet
+	// Keys are the private keys of an imported keys wallet.
+	Keys []ElectrumKey
+	// Addresses are the addresses of an imported addresses (watch-only) wallet.
+	Addresses []string
+}
+
+// electrumFileKeystore is a keystore of an Electrum wallet file as read back.
+type electrumFileKeystore struct {
+	Type            string            `json:"type"`
+	XPub            string            `json:"xpub"`
+	XPrv            string            `json:"xprv"`
+	Derivation      string            `json:"derivation"`
+	RootFingerprint string            `json:"root_fingerprint"`
+	Keypairs        map[string]string `json:"keypairs"`
+}
+
+// ImportElectrum reads an Electrum wallet file. The password decrypts wallet
+// files and keystores encrypted by Electrum; it is ignored otherwise.
+func ImportElectrum(data []byte, password string) (*ElectrumImport, error) {
+	data = bytes.TrimSpace(data)
+	if encrypted, err := base64.StdEncoding.DecodeString(string(data)); err == nil && bytes.HasPrefix(encrypted, []byte(electrumStorageMagic)) {
+		decrypted, err := decryptElectrumStorage(encrypted, password)
+		if err != nil {
+			return nil, err
+		}
+		data = decrypted
+	}
+
+	var file map[string]json.RawMessage
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+	}
+	var walletType string
+	var encrypted bool
+	if err := json.Unmarshal(file["wallet_type"], &walletType); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+	}
+	if raw, ok := file["use_encryption"]; ok {
+		if err := json.Unmarshal(raw, &encrypted); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+		}
+	}
+	if !encrypted {
+		password = ""
+	} else if password == "" {
+		return nil, ErrElectrumPassword
+	}
+
+	result := &ElectrumImport{WalletType: walletType}
+	var err error
+	switch {
+	case walletType == "standard":
+		result.Wallet, err = importElectrumStandard(file["keystore"], password)
+	case walletType == "imported":
+		err = result.importElectrumImported(file, password)
+	case isElectrumMultisigType(walletType):
+		result.Multisig, err = importElectrumMultisig(file, walletType)
+	default:
+		err = ErrElectrumUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importElectrumStandard creates the wallet of a standard bip32 keystore.
+func importElectrumStandard(raw json.RawMessage, password string) (*Wallet, error) {
+	var keystore electrumFileKeystore
+	if err := json.Unmarshal(raw, &keystore); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+	}
+	if keystore.Type != "bip32" {
+		return nil, ErrElectrumUnsupported
+	}
+
+	encoded := keystore.XPub
+	if keystore.XPrv != "" {
+		xprv, err := decryptElectrumField(keystore.XPrv, password)
+		if err != nil {
+			return nil, err
+		}
+		encoded = xprv
+	}
+	key, network, addrType, err := parseSLIP132Key(encoded)
+	if err != nil {
+		return nil, err
+	}
+	switch addrType {
+	case AddressTypeP2PKH, AddressTypeP2SH, AddressTypeP2WPKH:
+	default:
+		return nil, ErrUnsupportedAddressType
+	}
+
+	origin, err := electrumOrigin(&keystore, key)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := newWallet(key, origin, "m"+formatPathLevels(origin.path)+"/0", network)
+	if err != nil {
+		return nil, err
+	}
+	// The keystore, not the derivation, sets the script type: Electrum seeds
+	// derive their segwit accounts at m/0', which has no purpose.
+	if addrType != wallet.AddressType() {
+		wallet.addrType = addrType
+	}
+	return wallet, nil
+}
+
+// importElectrumImported reads the keys or addresses of an imported wallet.
+func (s *ElectrumImport) importElectrumImported(file map[string]json.RawMessage, password string) error {
+	if raw, ok := file["keystore"]; ok {
+		var keystore electrumFileKeystore
+		if err := json.Unmarshal(raw, &keystore); err != nil {
+			return fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+		}
+		pubKeys := make([]string, 0, len(keystore.Keypairs))
+		for pubKey := range keystore.Keypairs {
+			pubKeys = append(pubKeys, pubKey)
+		}
+		sort.Strings(pubKeys)
+
+		for _, pubKey := range pubKeys {
+			value, err := decryptElectrumField(keystore.Keypairs[pubKey], password)
+			if err != nil {
+				return err
+			}
+			scriptType, encoded, found := strings.Cut(value, ":")
+			if !found {
+				scriptType, encoded = "p2pkh", value
+			}
+			addrType, ok := electrumImportedTypes[scriptType]
+			if !ok {
+				return ErrUnsupportedAddressType
+			}
+			wif, err := btcutil.DecodeWIF(encoded)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+			}
+			if hex.EncodeToString(wif.SerializePubKey()) != pubKey {
+				return ErrElectrumPassword
+			}
+			s.Keys = append(s.Keys, ElectrumKey{AddressType: addrType, WIF: wif})
+		}
+		return nil
+	}
+
+	var addresses map[string]json.RawMessage
+	if err := json.Unmarshal(file["addresses"], &addresses); err != nil {
+		return fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+	}
+	for address := range addresses {
+		s.Addresses = append(s.Addresses, address)
+	}
+	sort.Strings(s.Addresses)
+	return nil
+}
+
+// importElectrumMultisig creates the multisig wallet of an "MofN" wallet file,
+// whose keystores are stored under "x1/" to "xN/".
+func importElectrumMultisig(file map[string]json.RawMessage, walletType string) (*MultisigWallet, error) {
+	m, _ := strconv.Atoi(walletType[:strings.Index(walletType, "of")])
+	n, _ := strconv.Atoi(walletType[strings.Index(walletType, "of")+2:])
+
+	var network Network
+	var addrType AddressType
+	cosigners := make([]Cosigner, 0, n)
+	for i := 1; i <= n; i++ {
+		var keystore electrumFileKeystore
+		if err := json.Unmarshal(file[fmt.Sprintf("x%d/", i)], &keystore); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+		}
+		key, keyNetwork, keyType, err := parseSLIP132Key(keystore.XPub)
+		if err != nil {
+			return nil, err
+		}
+		switch keyType {
+		case AddressTypeP2PKH:
+			keyType = AddressTypeP2SH
+		case AddressTypeP2WSH:
+		default:
+			return nil, ErrUnsupportedAddressType
+		}
+		if i > 1 && (keyNetwork != network || keyType != addrType) {
+			return nil, ErrElectrumInvalid
+		}
+		network, addrType = keyNetwork, keyType
+
+		origin, err := electrumOrigin(&keystore, key)
+		if err != nil {
+			return nil, err
+		}
+		cosigners = append(cosigners, Cosigner{
+			Fingerprint: origin.fingerprint,
+			Path:        "m" + formatPathLevels(origin.path),
+			XPub:        key.String(),
+		})
+	}
+	return NewMultisigWallet("Electrum "+walletType, m, cosigners, addrType, network)
+}
+
+// isElectrumMultisigType checks whether a wallet type is of the form "MofN".
+func isElectrumMultisigType(walletType string) bool {
+	m, n, found := strings.Cut(walletType, "of")
+	if !found {
+		return false
+	}
+	required, err1 := strconv.Atoi(m)
+	total, err2 := strconv.Atoi(n)
+	return err1 == nil && err2 == nil && required >= 1 && required <= total && total <= maxMultisigCosigners
+}
+
+// electrumOrigin returns the origin of the account key of a keystore.
+func electrumOrigin(keystore *electrumFileKeystore, key *hdkeychain.ExtendedKey) (*keyOrigin, error) {
+	origin := &keyOrigin{key: key}
+	if keystore.Derivation == "" || keystore.RootFingerprint == "" {
+		fingerprint, err := keyFingerprint(key)
+		if err != nil {
+			return nil, err
+		}
+		origin.fingerprint = fingerprint
+		return origin, nil
+	}
+
+	fp, err := hex.DecodeString(keystore.RootFingerprint)
+	if err != nil || len(fp) != 4 {
+		return nil, ErrElectrumInvalid
+	}
+	origin.fingerprint = binary.BigEndian.Uint32(fp)
+	if origin.path, err = ParsePath(keystore.Derivation); err != nil {
+		return nil, err
+	}
+	if int(key.Depth()) != len(origin.path) {
+		return nil, ErrElectrumInvalid
+	}
+	return origin, nil
+}
+
+// decryptElectrumField decrypts a keystore secret encrypted by Electrum
+// (pw_encode, hash version 1): base64(iv || AES-256-CBC(sha256d(password))).
+// Fields are returned unchanged when the password is empty.
+func decryptElectrumField(field string, password string) (string, error) {
+	if password == "" {
+		return field, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(field)
+	if err != nil || len(data) < 2*aes.BlockSize {
+		return "", ErrElectrumPassword
+	}
+	secret := chainhash.DoubleHashB([]byte(password))
+	plain, err := aesCBCDecrypt(secret, data[:aes.BlockSize], data[aes.BlockSize:])
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// decryptElectrumStorage decrypts a wallet file encrypted with a user
+// password: an ECIES message ("BIE1") to a key derived from the password,
+// whose plaintext is the zlib-compressed JSON wallet.
+func decryptElectrumStorage(data []byte, password string) ([]byte, error) {
+	if password == "" {
+		return nil, ErrElectrumPassword
+	}
+	if len(data) < 4+33+aes.BlockSize+sha256.Size {
+		return nil, ErrElectrumInvalid
+	}
+	ephemeral, err := btcec.ParsePubKey(data[4:37])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+	}
+
+	key := electrumStorageKey(password)
+	var point, shared btcec.JacobianPoint
+	ephemeral.AsJacobian(&point)
+	btcec.ScalarMultNonConst(&key.Key, &point, &shared)
+	shared.ToAffine()
+	digest := sha512.Sum512(btcec.NewPublicKey(&shared.X, &shared.Y).SerializeCompressed())
+
+	mac := hmac.New(sha256.New, digest[32:])
+	mac.Write(data[:len(data)-sha256.Size])
+	if !hmac.Equal(mac.Sum(nil), data[len(data)-sha256.Size:]) {
+		return nil, ErrElectrumPassword
+	}
+	compressed, err := aesCBCDecrypt(digest[16:32], digest[:16], data[37:len(data)-sha256.Size])
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrElectrumInvalid, err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// electrumStorageKey derives the private key of the storage encryption from
+// the password, as Electrum does with PBKDF2-HMAC-SHA512.
+func electrumStorageKey(password string) *btcec.PrivateKey {
+	secret := pbkdf2.Key([]byte(password), nil, 1024, sha512.Size, sha512.New)
+	n := new(big.Int).Mod(new(big.Int).SetBytes(secret), btcec.S256().N)
+	key, _ := btcec.PrivKeyFromBytes(n.FillBytes(make([]byte, 32)))
+	return key
+}
+
+// aesCBCDecrypt decrypts AES-CBC data and removes its PKCS#7 padding. A bad
+// padding is reported as a wrong password.
+func aesCBCDecrypt(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, ErrElectrumPassword
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, ErrElectrumPassword
+	}
+	for _, b := range plain[len(plain)-padding:] {
+		if int(b) != padding {
+			return nil, ErrElectrumPassword
+		}
+	}
+	return plain[:len(plain)-padding], nil
+}
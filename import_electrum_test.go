@@ -0,0 +1,189 @@
+package p2pkh
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/assert"
+)
+
+// aesCBCEncrypt encrypts data with PKCS#7 padding, as Electrum does.
+func aesCBCEncrypt(t *testing.T, key, iv, data []byte) []byte {
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	data = append(append([]byte(nil), data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, data)
+	return out
+}
+
+// electrumEncryptField encrypts a keystore secret like Electrum pw_encode.
+func electrumEncryptField(t *testing.T, plain, password string) string {
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	data := aesCBCEncrypt(t, chainhash.DoubleHashB([]byte(password)), iv, []byte(plain))
+	return base64.StdEncoding.EncodeToString(append(iv, data...))
+}
+
+// electrumEncryptStorage encrypts a wallet file like Electrum storage encryption.
+func electrumEncryptStorage(t *testing.T, file, password string) []byte {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	_, err := w.Write([]byte(file))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	ephemeral, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{3}, 32))
+	var point, shared btcec.JacobianPoint
+	electrumStorageKey(password).PubKey().AsJacobian(&point)
+	btcec.ScalarMultNonConst(&ephemeral.Key, &point, &shared)
+	shared.ToAffine()
+	digest := sha512.Sum512(btcec.NewPublicKey(&shared.X, &shared.Y).SerializeCompressed())
+
+	data := append([]byte(electrumStorageMagic), ephemeral.PubKey().SerializeCompressed()...)
+	data = append(data, aesCBCEncrypt(t, digest[16:32], digest[:16], compressed.Bytes())...)
+	mac := hmac.New(sha256.New, digest[32:])
+	mac.Write(data)
+	return []byte(base64.StdEncoding.EncodeToString(mac.Sum(data)))
+}
+
+func Test_ImportElectrum_Standard(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	origin, err := wallet.keyOrigin()
+	assert.NoError(t, err)
+	xpub, err := origin.key.Neuter()
+	assert.NoError(t, err)
+
+	// Watch-only file, as produced by ElectrumExporter.
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.Export("electrum", &buf))
+	imported, err := ImportElectrum(buf.Bytes(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "standard", imported.WalletType)
+	assert.Equal(t, wallet.Path(), imported.Wallet.Path())
+	assert.Equal(t, wallet.AddressHex(), imported.Wallet.AddressHex())
+	_, err = imported.Wallet.PrivateKey()
+	assert.Error(t, err)
+
+	// Signing file with an encrypted keystore.
+	file := fmt.Sprintf(`{"keystore": {"type": "bip32", "xpub": %q, "xprv": %q, "derivation": "m/44'/0'/0'", "root_fingerprint": "%08x"}, "wallet_type": "standard", "use_encryption": true, "seed_version": 52}`,
+		xpub.String(), electrumEncryptField(t, origin.key.String(), "secret"), origin.fingerprint)
+	imported, err = ImportElectrum([]byte(file), "secret")
+	assert.NoError(t, err)
+	wif, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+	importedWIF, err := imported.Wallet.PrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, wif, importedWIF)
+
+	_, err = ImportElectrum([]byte(file), "")
+	assert.ErrorIs(t, err, ErrElectrumPassword)
+	_, err = ImportElectrum([]byte(file), "wrong")
+	assert.Error(t, err)
+
+	// The same file with storage encryption.
+	imported, err = ImportElectrum(electrumEncryptStorage(t, file, "secret"), "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), imported.Wallet.AddressHex())
+	_, err = ImportElectrum(electrumEncryptStorage(t, file, "secret"), "wrong")
+	assert.ErrorIs(t, err, ErrElectrumPassword)
+}
+
+func Test_ImportElectrum_StandardSegwit(t *testing.T) {
+	// The BIP84 account of the "abandon ... about" mnemonic, whose first
+	// receive address is bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu.
+	zpub := "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	file := fmt.Sprintf(`{"keystore": {"type": "bip32", "xpub": %q, "derivation": "m/84h/0h/0h", "root_fingerprint": "73c5da0a"}, "wallet_type": "standard", "seed_version": 52}`, zpub)
+	imported, err := ImportElectrum([]byte(file), "")
+	assert.NoError(t, err)
+	assert.Equal(t, `m/84'/0'/0'/0`, imported.Wallet.Path())
+	assert.Equal(t, AddressTypeP2WPKH, imported.Wallet.AddressType())
+	addresses, err := imported.Wallet.Addresses(0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"}, addresses)
+
+	// Electrum seeds derive their segwit account at m/0', which has no
+	// purpose: the zpub alone sets the script type, kept by the children.
+	file = fmt.Sprintf(`{"keystore": {"type": "bip32", "xpub": %q}, "wallet_type": "standard", "seed_version": 52}`, zpub)
+	imported, err = ImportElectrum([]byte(file), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "m/0", imported.Wallet.Path())
+	child, err := imported.Wallet.Derive(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", child.AddressHex())
+	data, err := json.Marshal(imported.Wallet)
+	assert.NoError(t, err)
+	var restored Wallet
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, AddressTypeP2WPKH, restored.AddressType())
+
+	// A ypub keystore is of nested segwit.
+	ypub, err := hdkeychain.NewKeyFromString(zpub)
+	assert.NoError(t, err)
+	ypub, err = ypub.CloneWithVersion([]byte{0x04, 0x9d, 0x7c, 0xb2})
+	assert.NoError(t, err)
+	file = fmt.Sprintf(`{"keystore": {"type": "bip32", "xpub": %q}, "wallet_type": "standard", "seed_version": 52}`, ypub.String())
+	imported, err = ImportElectrum([]byte(file), "")
+	assert.NoError(t, err)
+	assert.Equal(t, AddressTypeP2SH, imported.Wallet.AddressType())
+	assert.True(t, strings.HasPrefix(imported.Wallet.AddressHex(), "3"), imported.Wallet.AddressHex())
+}
+
+func Test_ImportElectrum_Imported(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	wif, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+	pubKey := hex.EncodeToString(wallet.PublicKey().SerializeCompressed())
+
+	file := fmt.Sprintf(`{"keystore": {"type": "imported", "keypairs": {%q: %q}}, "wallet_type": "imported", "use_encryption": true}`,
+		pubKey, electrumEncryptField(t, "p2pkh:"+wif, "secret"))
+	imported, err := ImportElectrum([]byte(file), "secret")
+	assert.NoError(t, err)
+	assert.Len(t, imported.Keys, 1)
+	assert.Equal(t, AddressTypeP2PKH, imported.Keys[0].AddressType)
+	assert.Equal(t, wif, imported.Keys[0].WIF.String())
+
+	file = `{"addresses": {"1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A": {}, "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv": {}}, "wallet_type": "imported"}`
+	imported, err = ImportElectrum([]byte(file), "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A"}, imported.Addresses)
+}
+
+func Test_ImportElectrum_Multisig(t *testing.T) {
+	cosigners := createTestCosigners(t, 3, NetworkMainnet, `m/48'/0'/0'/2'`)
+	keystores := ""
+	for i, cosigner := range cosigners {
+		key, err := hdkeychain.NewKeyFromString(cosigner.XPub)
+		assert.NoError(t, err)
+		zpub, err := key.CloneWithVersion([]byte{0x02, 0xaa, 0x7e, 0xd3})
+		assert.NoError(t, err)
+		keystores += fmt.Sprintf(`"x%d/": {"type": "bip32", "xpub": %q, "derivation": %q, "root_fingerprint": "%08x"}, `,
+			i+1, zpub.String(), cosigner.Path, cosigner.Fingerprint)
+	}
+
+	imported, err := ImportElectrum([]byte(`{`+keystores+`"wallet_type": "2of3"}`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, imported.Multisig.Required())
+	assert.Equal(t, AddressTypeP2WSH, imported.Multisig.AddressType())
+	assert.Equal(t, NetworkMainnet, imported.Multisig.Network())
+	assert.Equal(t, cosigners, imported.Multisig.Cosigners())
+
+	_, err = ImportElectrum([]byte(`{"wallet_type": "trustedcoin"}`), "")
+	assert.ErrorIs(t, err, ErrElectrumUnsupported)
+	_, err = ImportElectrum([]byte(`not json`), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,236 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrInheritanceTimelock Error = "inheritance timelock must be between 1 and 65535 blocks"
+	ErrInheritanceLockTime Error = "recovery lock time must be a future block height"
+	ErrInheritanceKey      Error = "key is not part of the inheritance plan"
+)
+
+// InheritancePlan is a dead-man switch between an owner and an heir. Funds
+// sent to its P2WSH address are spendable by the owner at any time, and by
+// the heir once they have not moved for Timelock blocks:
+//
+//	wsh(or_d(pk(OWNER),and_v(v:pk(HEIR),older(Timelock))))
+//
+// The owner keeps the heir locked out by refreshing the funds, i.e. sending
+// them back to the plan address, more often than every Timelock blocks.
+type InheritancePlan struct {
+	owner    *Wallet
+	heir     *btcec.PublicKey
+	timelock uint16
+	script   []byte
+	address  *btcutil.AddressWitnessScriptHash
+}
+
+// NewInheritancePlan creates the inheritance plan of the wallet key to the
+// heir public key, with a relative timelock in blocks.
+func (s *Wallet) NewInheritancePlan(heir *btcec.PublicKey, timelock uint16) (*InheritancePlan, error) {
+	if timelock == 0 {
+		return nil, ErrInheritanceTimelock
+	}
+	script, err := txscript.NewScriptBuilder().
+		AddData(s.publicKey.SerializeCompressed()).
+		AddOp(txscript.OP_CHECKSIG).
+		AddOp(txscript.OP_IFDUP).
+		AddOp(txscript.OP_NOTIF).
+		AddData(heir.SerializeCompressed()).
+		AddOp(txscript.OP_CHECKSIGVERIFY).
+		AddInt64(int64(timelock)).
+		AddOp(txscript.OP_CHECKSEQUENCEVERIFY).
+		AddOp(txscript.OP_ENDIF).
+		Script()
+	if err != nil {
+		return nil, err
+	}
+	hash := chainhash.HashB(script)
+	address, err := btcutil.NewAddressWitnessScriptHash(hash, s.params)
+	if err != nil {
+		return nil, err
+	}
+	return &InheritancePlan{owner: s, heir: heir, timelock: timelock, script: script, address: address}, nil
+}
+
+// Timelock returns the number of blocks after which the heir can spend.
+func (s *InheritancePlan) Timelock() uint16 {
+	return s.timelock
+}
+
+// Address returns the P2WSH address receiving the funds of the plan.
+func (s *InheritancePlan) Address() string {
+	return s.address.EncodeAddress()
+}
+
+// WitnessScript returns the script of the plan.
+func (s *InheritancePlan) WitnessScript() []byte {
+	return append([]byte(nil), s.script...)
+}
+
+// Descriptor returns the output descriptor of the plan, with the origin of
+// the owner key, to be handed to the heir and imported by any miniscript
+// aware wallet.
+func (s *InheritancePlan) Descriptor() (string, error) {
+	fingerprint, err := s.owner.masterFingerprint()
+	if err != nil {
+		return "", err
+	}
+	desc := fmt.Sprintf("wsh(or_d(pk([%08x%s]%s),and_v(v:pk(%s),older(%d))))",
+		fingerprint, formatPathLevels(s.owner.levels), hex.EncodeToString(s.owner.publicKey.SerializeCompressed()),
+		hex.EncodeToString(s.heir.SerializeCompressed()), s.timelock)
+	return AddDescriptorChecksum(desc)
+}
+
+// Refresh spends the plan funds back to the plan address with the owner key,
+// restarting the timelock and invalidating the pre-signed recovery
+// transactions. feeRate is in sat/vbyte.
+func (s *InheritancePlan) Refresh(utxos []UTXO, feeRate FeeRate) ([]byte, error) {
+	return s.OwnerSpend(utxos, s.Address(), feeRate)
+}
+
+// OwnerSpend spends the plan funds to an address with the owner key.
+func (s *InheritancePlan) OwnerSpend(utxos []UTXO, address string, feeRate FeeRate) ([]byte, error) {
+	return s.spend(utxos, address, feeRate, 0, wire.MaxTxInSequenceNum, s.ownerWitness)
+}
+
+// PresignRecovery returns a transaction signed by the owner sending the plan
+// funds to the heir address, which cannot be mined before the block height
+// lockTime. Handed to the heir, it lets them recover the funds even before
+// the timelock expires, unless the owner refreshes the funds first.
+func (s *InheritancePlan) PresignRecovery(utxos []UTXO, heirAddress string, feeRate FeeRate, lockTime uint32) ([]byte, error) {
+	if lockTime == 0 || DecodeLockTime(lockTime).IsTime() {
+		return nil, ErrInheritanceLockTime
+	}
+	return s.spend(utxos, heirAddress, feeRate, lockTime, wire.MaxTxInSequenceNum-1, s.ownerWitness)
+}
+
+// HeirClaim spends the plan funds to an address with the heir key, once the
+// UTXOs are at least Timelock blocks deep.
+func (s *InheritancePlan) HeirClaim(heirKey *btcec.PrivateKey, utxos []UTXO, address string, feeRate FeeRate) ([]byte, error) {
+	if !heirKey.PubKey().IsEqual(s.heir) {
+		return nil, ErrInheritanceKey
+	}
+	witness := func(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, i int, amount int64) (wire.TxWitness, error) {
+		sig, err := txscript.RawTxInWitnessSignature(tx, sigHashes, i, amount, s.script, txscript.SigHashAll, heirKey)
+		if err != nil {
+			return nil, err
+		}
+		return wire.TxWitness{sig, nil, s.script}, nil
+	}
+	sequence, err := RelativeBlocksSequence(s.timelock)
+	if err != nil {
+		return nil, err
+	}
+	return s.spend(utxos, address, feeRate, 0, sequence, witness)
+}
+
+// ownerWitness signs an input with the owner key.
+func (s *InheritancePlan) ownerWitness(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, i int, amount int64) (wire.TxWitness, error) {
+	key, err := s.owner.extendedKey.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.owner.rawTxInWitnessSignature(tx, sigHashes, i, amount, s.script, txscript.SigHashAll, key)
+	if err != nil {
+		return nil, err
+	}
+	s.owner.logSign("inheritance_input", "", inputAttrs(tx, i)...)
+	return wire.TxWitness{sig, s.script}, nil
+}
+
+// spend builds and signs a transaction sending every UTXO of the plan to an
+// address, minus the fee at feeRate.
+func (s *InheritancePlan) spend(utxos []UTXO, address string, feeRate FeeRate, lockTime, sequence uint32,
+	witness func(*wire.MsgTx, *txscript.TxSigHashes, int, int64) (wire.TxWitness, error)) ([]byte, error) {
+	if len(utxos) == 0 {
+		return nil, ErrDraftNoInputs
+	}
+	if feeRate < 0 {
+		return nil, ErrInvalidFeeRate
+	}
+	addr, err := decodeAddress(address, s.owner.params)
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	planScript, err := txscript.PayToAddrScript(s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(draftTxVersion)
+	tx.LockTime = lockTime
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	var total Amount
+	for _, utxo := range utxos {
+		if !bytes.Equal(utxo.PkScript, planScript) {
+			return nil, ErrInputScriptMismatch
+		}
+		hash, err := chainhash.NewHashFromStr(utxo.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTxID, err)
+		}
+		outPoint := wire.NewOutPoint(hash, utxo.Vout)
+		in := wire.NewTxIn(outPoint, nil, nil)
+		in.Sequence = sequence
+		tx.AddTxIn(in)
+		fetcher.AddPrevOut(*outPoint, wire.NewTxOut(int64(utxo.Amount), utxo.PkScript))
+		if total, err = total.Add(utxo.Amount); err != nil {
+			return nil, err
+		}
+	}
+	tx.AddTxOut(wire.NewTxOut(int64(total), pkScript))
+
+	// Sign once to measure the transaction, then again with the final fee.
+	sign := func() error {
+		sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+		for i, utxo := range utxos {
+			if tx.TxIn[i].Witness, err = witness(tx, sigHashes, i, int64(utxo.Amount)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := sign(); err != nil {
+		return nil, err
+	}
+	vsize := (int64(tx.SerializeSizeStripped()*3+tx.SerializeSize()) + 3) / 4
+	fee, err := feeRate.Fee(vsize)
+	if err != nil {
+		return nil, err
+	}
+	value, err := total.Sub(fee)
+	if err != nil {
+		return nil, err
+	}
+	tx.TxOut[0].Value = int64(value)
+	dust, err := dustThreshold(tx.TxOut[0], defaultDustFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	if value < dust {
+		return nil, ErrInsufficientFunds
+	}
+	if err := sign(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,81 @@
+package p2pkh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// planUTXOs returns UTXOs paying to the inheritance plan.
+func planUTXOs(t *testing.T, plan *InheritancePlan, amounts ...Amount) []UTXO {
+	addr, err := btcutil.DecodeAddress(plan.Address(), &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	script, err := txscript.PayToAddrScript(addr)
+	assert.NoError(t, err)
+	var utxos []UTXO
+	for i, amount := range amounts {
+		utxos = append(utxos, UTXO{TxID: testTxID, Vout: uint32(i), Amount: amount, PkScript: script})
+	}
+	return utxos
+}
+
+func Test_InheritancePlan(t *testing.T) {
+	owner := createKnownWallet(t, NetworkMainnet)
+	heirKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{9}, 32))
+	heirAddress := "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv"
+
+	plan, err := owner.NewInheritancePlan(heirKey.PubKey(), 52560)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plan.Address(), "bc1q"))
+	assert.Equal(t, uint16(52560), plan.Timelock())
+
+	desc, err := plan.Descriptor()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(desc, "wsh(or_d(pk(["))
+	assert.Contains(t, desc, "/44'/0'/0'/0]")
+	assert.Contains(t, desc, "older(52560))))#")
+
+	utxos := planUTXOs(t, plan, 60000, 40000)
+
+	// The owner spends at any time.
+	rawTx, err := plan.Refresh(utxos, 2)
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+
+	// The pre-signed recovery transaction is locked until the given height.
+	rawTx, err = plan.PresignRecovery(utxos, heirAddress, 2, 900000)
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Equal(t, uint32(900000), tx.LockTime)
+	assert.Equal(t, wire.MaxTxInSequenceNum-1, tx.TxIn[0].Sequence)
+	assert.Less(t, tx.TxOut[0].Value, int64(100000))
+
+	// The heir claims with a relative timelock.
+	rawTx, err = plan.HeirClaim(heirKey, utxos, heirAddress, 2)
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Equal(t, uint32(52560), tx.TxIn[1].Sequence)
+
+	otherKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{8}, 32))
+	_, err = plan.HeirClaim(otherKey, utxos, heirAddress, 2)
+	assert.ErrorIs(t, err, ErrInheritanceKey)
+	_, err = plan.PresignRecovery(utxos, heirAddress, 2, 600000000)
+	assert.ErrorIs(t, err, ErrInheritanceLockTime)
+	_, err = plan.OwnerSpend([]UTXO{walletUTXO(t, owner, 0, 10000)}, heirAddress, 2)
+	assert.ErrorIs(t, err, ErrInputScriptMismatch)
+	_, err = plan.OwnerSpend(planUTXOs(t, plan, 600), heirAddress, 2)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = owner.NewInheritancePlan(heirKey.PubKey(), 0)
+	assert.ErrorIs(t, err, ErrInheritanceTimelock)
+}
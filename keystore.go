@@ -0,0 +1,230 @@
+package p2pkh
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	ErrKeystoreInvalid  Error = "invalid keystore"
+	ErrKeystoreVersion  Error = "unsupported keystore version"
+	ErrKeystorePassword Error = "invalid keystore password"
+	ErrKeystoreParams   Error = "unsupported keystore encryption parameters"
+
+	keystoreVersion = 1
+	keystoreCipher  = "aes-256-gcm"
+	keystoreKDF     = "scrypt"
+	keystoreKeyLen  = 32
+	keystoreSaltLen = 32
+	// keystoreMaxMemory bounds the memory used by scrypt, 128*N*r bytes.
+	keystoreMaxMemory = 1 << 30
+	keystoreMnemonic  = "mnemonic"
+	keystoreXPrv      = "xprv"
+)
+
+// keystoreScrypt are the scrypt parameters of the exported keystores; tests
+// lower them.
+var keystoreScrypt = keystoreKDFParams{N: 1 << 18, R: 8, P: 1}
+
+// keystoreKDFParams are the scrypt parameters deriving the encryption key.
+type keystoreKDFParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// keystoreCrypto is the encrypted secret of a keystore.
+type keystoreCrypto struct {
+	Cipher     string            `json:"cipher"`
+	Nonce      string            `json:"nonce"`
+	Ciphertext string            `json:"ciphertext"`
+	KDF        string            `json:"kdf"`
+	KDFParams  keystoreKDFParams `json:"kdfparams"`
+}
+
+// keystoreOrigin is the origin of an extended private key that is not a
+// master key.
+type keystoreOrigin struct {
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+}
+
+// keystoreHeader is the plaintext part of a keystore, authenticated by the
+// encryption so that it cannot be altered.
+type keystoreHeader struct {
+	Version int             `json:"version"`
+	Network Network         `json:"network"`
+	Path    string          `json:"path"`
+	Type    string          `json:"type"`
+	Origin  *keystoreOrigin `json:"origin,omitempty"`
+	// Uncompressed is set for wallets of uncompressed keys.
+	Uncompressed bool `json:"uncompressed,omitempty"`
+	// AddressType is set for wallets whose address type is not the one of
+	// their path purpose.
+	AddressType AddressType `json:"address_type,omitempty"`
+}
+
+// keystoreFile is the JSON keystore written by ExportKeystore.
+type keystoreFile struct {
+	keystoreHeader
+	Crypto keystoreCrypto `json:"crypto"`
+}
+
+// ExportKeystore serializes the wallet as a JSON keystore whose secret, the
+// mnemonic or the root extended private key of wallets imported without one
+// or protected by a passphrase, is encrypted with AES-256-GCM under a key derived from password by scrypt.
+// The network, path and key compression are stored in clear but
+// authenticated.
+func (s *Wallet) ExportKeystore(password string) ([]byte, error) {
+	if s.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	header := keystoreHeader{Version: keystoreVersion, Network: s.network, Path: s.path, Type: keystoreMnemonic, Uncompressed: s.uncompressed, AddressType: s.addrType}
+	// The mnemonic of a passphrase protected wallet is not enough to restore
+	// it, and the passphrase is not stored.
+	secret := s.mnemonic
+	if secret == "" || s.passphrase != "" {
+		header.Type = keystoreXPrv
+		secret = s.root.String()
+		if s.origin != nil {
+			header.Origin = &keystoreOrigin{
+				Fingerprint: fmt.Sprintf("%08x", s.origin.fingerprint),
+				Path:        "m" + formatPathLevels(s.origin.path),
+			}
+		}
+	}
+
+	params := keystoreScrypt
+	salt := make([]byte, keystoreSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params.Salt = hex.EncodeToString(salt)
+	aead, err := keystoreAEAD(password, params)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	ad, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(keystoreFile{
+		keystoreHeader: header,
+		Crypto: keystoreCrypto{
+			Cipher:     keystoreCipher,
+			Nonce:      hex.EncodeToString(nonce),
+			Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, []byte(secret), ad)),
+			KDF:        keystoreKDF,
+			KDFParams:  params,
+		},
+	}, "", "  ")
+}
+
+// ImportKeystore decrypts a keystore written by ExportKeystore and restores
+// the wallet.
+func ImportKeystore(data []byte, password string) (*Wallet, error) {
+	var file keystoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeystoreInvalid, err)
+	}
+	if file.Version != keystoreVersion {
+		return nil, ErrKeystoreVersion
+	}
+	if file.Crypto.Cipher != keystoreCipher || file.Crypto.KDF != keystoreKDF {
+		return nil, ErrKeystoreParams
+	}
+
+	aead, err := keystoreAEAD(password, file.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(file.Crypto.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, ErrKeystoreInvalid
+	}
+	ciphertext, err := hex.DecodeString(file.Crypto.Ciphertext)
+	if err != nil {
+		return nil, ErrKeystoreInvalid
+	}
+	ad, err := json.Marshal(file.keystoreHeader)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, ErrKeystorePassword
+	}
+
+	switch file.Type {
+	case keystoreMnemonic:
+		return New(&Config{Mnemonic: string(secret), Path: file.Path, Network: file.Network, UncompressedKeys: file.Uncompressed})
+	case keystoreXPrv:
+		root, err := hdkeychain.NewKeyFromString(string(secret))
+		if err != nil {
+			return nil, err
+		}
+		origin, err := file.Origin.keyOrigin(root)
+		if err != nil {
+			return nil, err
+		}
+		wallet, err := newWallet(root, origin, file.Path, file.Network)
+		if err != nil {
+			return nil, err
+		}
+		wallet.setUncompressed(file.Uncompressed)
+		wallet.addrType = file.AddressType
+		return wallet, nil
+	}
+	return nil, ErrKeystoreInvalid
+}
+
+// keyOrigin returns the origin of root, nil for a master key.
+func (s *keystoreOrigin) keyOrigin(root *hdkeychain.ExtendedKey) (*keyOrigin, error) {
+	if s == nil {
+		return nil, nil
+	}
+	fingerprint, err := strconv.ParseUint(s.Fingerprint, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeystoreInvalid, err)
+	}
+	path, err := ParsePath(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &keyOrigin{fingerprint: uint32(fingerprint), path: path, key: root}, nil
+}
+
+// keystoreAEAD returns the AES-256-GCM cipher keyed by scrypt(password).
+// Parameters are bounded so that a crafted keystore cannot exhaust memory.
+func keystoreAEAD(password string, params keystoreKDFParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, ErrKeystoreInvalid
+	}
+	if params.N <= 0 || params.R <= 0 || params.P <= 0 || params.P > 16 || 128*params.N > keystoreMaxMemory/params.R {
+		return nil, ErrKeystoreParams
+	}
+	key, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, keystoreKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeystoreParams, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,113 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fastKeystoreScrypt lowers the scrypt cost of the keystores of a test.
+func fastKeystoreScrypt(t *testing.T) {
+	saved := keystoreScrypt
+	keystoreScrypt = keystoreKDFParams{N: 1 << 10, R: 8, P: 1}
+	t.Cleanup(func() { keystoreScrypt = saved })
+}
+
+func Test_Keystore(t *testing.T) {
+	fastKeystoreScrypt(t)
+	wallet := createKnownWallet(t, NetworkTestnet)
+
+	data, err := wallet.ExportKeystore("correct horse")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), testMnemonic)
+	assert.NotContains(t, string(data), strings.Fields(testMnemonic)[0])
+
+	imported, err := ImportKeystore(data, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.Path(), imported.Path())
+	assert.Equal(t, wallet.AddressHex(), imported.AddressHex())
+	wif, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+	importedWIF, err := imported.PrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, wif, importedWIF)
+
+	_, err = ImportKeystore(data, "wrong")
+	assert.ErrorIs(t, err, ErrKeystorePassword)
+
+	// The header is authenticated.
+	tampered := strings.Replace(string(data), wallet.Path(), `m/44'/1'/0'/1`, 1)
+	_, err = ImportKeystore([]byte(tampered), "correct horse")
+	assert.ErrorIs(t, err, ErrKeystorePassword)
+
+	var file map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &file))
+	file["version"] = 2
+	changed, err := json.Marshal(file)
+	assert.NoError(t, err)
+	_, err = ImportKeystore(changed, "correct horse")
+	assert.ErrorIs(t, err, ErrKeystoreVersion)
+
+	file["version"] = 1
+	file["crypto"].(map[string]interface{})["kdfparams"].(map[string]interface{})["n"] = 1 << 30
+	changed, err = json.Marshal(file)
+	assert.NoError(t, err)
+	_, err = ImportKeystore(changed, "correct horse")
+	assert.ErrorIs(t, err, ErrKeystoreParams)
+
+	_, err = ImportKeystore([]byte("not json"), "correct horse")
+	assert.ErrorIs(t, err, ErrKeystoreInvalid)
+}
+
+func Test_Keystore_ExtendedKey(t *testing.T) {
+	fastKeystoreScrypt(t)
+	wallet := createKnownWallet(t, NetworkMainnet)
+	origin, err := wallet.keyOrigin()
+	assert.NoError(t, err)
+	account, err := newWallet(origin.key, origin, wallet.Path(), NetworkMainnet)
+	assert.NoError(t, err)
+
+	data, err := account.ExportKeystore("secret")
+	assert.NoError(t, err)
+	imported, err := ImportKeystore(data, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), imported.AddressHex())
+	descs, err := imported.descriptors()
+	assert.NoError(t, err)
+	walletDescs, err := wallet.descriptors()
+	assert.NoError(t, err)
+	assert.Equal(t, walletDescs, descs)
+
+	watch, err := NewFromExtendedPublicKey(wallet.root.String(), NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = watch.ExportKeystore("secret")
+	assert.ErrorIs(t, err, ErrWatchOnly)
+}
+
+func Test_Keystore_Uncompressed(t *testing.T) {
+	fastKeystoreScrypt(t)
+	wallet, err := NewWallet(testMnemonic, WithUncompressedKeys())
+	assert.NoError(t, err)
+	data, err := wallet.ExportKeystore("secret")
+	assert.NoError(t, err)
+	imported, err := ImportKeystore(data, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), imported.AddressHex())
+
+	// The flag is authenticated.
+	tampered := strings.Replace(string(data), `"uncompressed": true`, `"uncompressed": false`, 1)
+	assert.NotEqual(t, string(data), tampered)
+	_, err = ImportKeystore([]byte(tampered), "secret")
+	assert.ErrorIs(t, err, ErrKeystorePassword)
+
+	// Passphrase protected wallets store their root key.
+	protected, err := New(&Config{Mnemonic: testMnemonic, Passphrase: "extra", Network: NetworkMainnet, UncompressedKeys: true})
+	assert.NoError(t, err)
+	data, err = protected.ExportKeystore("secret")
+	assert.NoError(t, err)
+	imported, err = ImportKeystore(data, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, protected.AddressHex(), imported.AddressHex())
+}
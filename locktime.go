@@ -0,0 +1,134 @@
+package p2pkh
+
+import (
+	"math"
+	"time"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrLockTimeHeight    Error = "lock time height must be below 500000000"
+	ErrLockTimeTimestamp Error = "lock time timestamp must be between 500000000 and 2^32-1"
+	ErrRelativeLockTime  Error = "relative lock time must be between 1 and 65535 units"
+
+	// RelativeLockTimeUnit is the granularity of BIP68 time-based relative
+	// lock times.
+	RelativeLockTimeUnit = 1 << wire.SequenceLockTimeGranularity * time.Second
+
+	// averageBlockInterval is the block interval targeted by the network.
+	averageBlockInterval = 10 * time.Minute
+)
+
+// LockTime is a decoded nLockTime: a block height, or a median-time-past
+// timestamp when Time is set. The zero LockTime does not lock anything.
+type LockTime struct {
+	Height uint32
+	Time   time.Time
+}
+
+// IsTime reports whether the lock time is a timestamp.
+func (s LockTime) IsTime() bool {
+	return !s.Time.IsZero()
+}
+
+// HeightLockTime returns the nLockTime preventing a transaction from being
+// mined before the block height.
+func HeightLockTime(height uint32) (uint32, error) {
+	if height >= txscript.LockTimeThreshold {
+		return 0, ErrLockTimeHeight
+	}
+	return height, nil
+}
+
+// TimeLockTime returns the nLockTime preventing a transaction from being
+// mined before the median time past of the chain reaches t (BIP113).
+func TimeLockTime(t time.Time) (uint32, error) {
+	if t.Unix() < txscript.LockTimeThreshold || t.Unix() > math.MaxUint32 {
+		return 0, ErrLockTimeTimestamp
+	}
+	return uint32(t.Unix()), nil
+}
+
+// DecodeLockTime decodes an nLockTime.
+func DecodeLockTime(lockTime uint32) LockTime {
+	if lockTime < txscript.LockTimeThreshold {
+		return LockTime{Height: lockTime}
+	}
+	return LockTime{Time: time.Unix(int64(lockTime), 0).UTC()}
+}
+
+// LockTimeSatisfied reports whether a transaction with the nLockTime can be
+// mined in the block at height, whose previous block has the median time
+// past. Transactions whose inputs all have final sequences ignore their
+// lock time.
+func LockTimeSatisfied(lockTime, height uint32, medianTimePast time.Time) bool {
+	if lockTime == 0 {
+		return true
+	}
+	if lockTime < txscript.LockTimeThreshold {
+		return int64(lockTime) < int64(height)
+	}
+	return int64(lockTime) < medianTimePast.Unix()
+}
+
+// RelativeLock is a decoded BIP68 relative lock time: a number of blocks,
+// or a duration in multiples of RelativeLockTimeUnit when IsTime is set.
+type RelativeLock struct {
+	Blocks   uint16
+	Duration time.Duration
+	IsTime   bool
+}
+
+// RelativeBlocksSequence returns the nSequence preventing an input from
+// being spent before its UTXO is blocks deep, as checked by older(blocks).
+func RelativeBlocksSequence(blocks uint16) (uint32, error) {
+	if blocks == 0 {
+		return 0, ErrRelativeLockTime
+	}
+	return uint32(blocks), nil
+}
+
+// RelativeTimeSequence returns the nSequence preventing an input from being
+// spent before its UTXO is d old, rounded up to RelativeLockTimeUnit.
+func RelativeTimeSequence(d time.Duration) (uint32, error) {
+	units := (d + RelativeLockTimeUnit - 1) / RelativeLockTimeUnit
+	if units <= 0 || units > wire.SequenceLockTimeMask {
+		return 0, ErrRelativeLockTime
+	}
+	return wire.SequenceLockTimeIsSeconds | uint32(units), nil
+}
+
+// DecodeSequence decodes the relative lock time of an nSequence, false when
+// the sequence disables it (BIP68).
+func DecodeSequence(sequence uint32) (RelativeLock, bool) {
+	if sequence&wire.SequenceLockTimeDisabled != 0 {
+		return RelativeLock{}, false
+	}
+	value := uint16(sequence & wire.SequenceLockTimeMask)
+	if sequence&wire.SequenceLockTimeIsSeconds != 0 {
+		return RelativeLock{Duration: time.Duration(value) * RelativeLockTimeUnit, IsTime: true}, true
+	}
+	return RelativeLock{Blocks: value}, true
+}
+
+// EstimateHeight estimates the height of the chain at t, from the height
+// and time of the tip, assuming 10 minute blocks. Pass the median time past
+// of the tip to convert between height and time-based lock times.
+func EstimateHeight(t time.Time, tipHeight uint32, tipTime time.Time) uint32 {
+	height := int64(tipHeight) + int64(t.Sub(tipTime)/averageBlockInterval)
+	if height < 0 {
+		return 0
+	}
+	if height > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(height)
+}
+
+// EstimateTime estimates when the chain reaches height, from the height and
+// time of the tip, assuming 10 minute blocks.
+func EstimateTime(height, tipHeight uint32, tipTime time.Time) time.Time {
+	return tipTime.Add(time.Duration(int64(height)-int64(tipHeight)) * averageBlockInterval)
+}
@@ -0,0 +1,71 @@
+package p2pkh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LockTime(t *testing.T) {
+	lockTime, err := HeightLockTime(840000)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(840000), lockTime)
+	assert.Equal(t, LockTime{Height: 840000}, DecodeLockTime(lockTime))
+	_, err = HeightLockTime(500000000)
+	assert.ErrorIs(t, err, ErrLockTimeHeight)
+
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	lockTime, err = TimeLockTime(deadline)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1893456000), lockTime)
+	decoded := DecodeLockTime(lockTime)
+	assert.True(t, decoded.IsTime())
+	assert.Equal(t, deadline, decoded.Time)
+	_, err = TimeLockTime(time.Unix(499999999, 0))
+	assert.ErrorIs(t, err, ErrLockTimeTimestamp)
+	_, err = TimeLockTime(time.Unix(1<<32, 0))
+	assert.ErrorIs(t, err, ErrLockTimeTimestamp)
+
+	assert.True(t, LockTimeSatisfied(0, 1, time.Time{}))
+	assert.False(t, LockTimeSatisfied(840000, 840000, time.Time{}))
+	assert.True(t, LockTimeSatisfied(840000, 840001, time.Time{}))
+	assert.False(t, LockTimeSatisfied(lockTime, 900000, deadline))
+	assert.True(t, LockTimeSatisfied(lockTime, 900000, deadline.Add(time.Second)))
+}
+
+func Test_RelativeLock(t *testing.T) {
+	sequence, err := RelativeBlocksSequence(144)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(144), sequence)
+	lock, ok := DecodeSequence(sequence)
+	assert.True(t, ok)
+	assert.Equal(t, RelativeLock{Blocks: 144}, lock)
+	_, err = RelativeBlocksSequence(0)
+	assert.ErrorIs(t, err, ErrRelativeLockTime)
+
+	// Durations are rounded up to 512 seconds.
+	sequence, err = RelativeTimeSequence(24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1<<22|169), sequence)
+	lock, ok = DecodeSequence(sequence)
+	assert.True(t, ok)
+	assert.True(t, lock.IsTime)
+	assert.Equal(t, 169*512*time.Second, lock.Duration)
+	_, err = RelativeTimeSequence(65536 * RelativeLockTimeUnit)
+	assert.ErrorIs(t, err, ErrRelativeLockTime)
+	_, err = RelativeTimeSequence(0)
+	assert.ErrorIs(t, err, ErrRelativeLockTime)
+
+	_, ok = DecodeSequence(0xffffffff)
+	assert.False(t, ok)
+}
+
+func Test_EstimateHeight(t *testing.T) {
+	tip := time.Date(2024, 4, 20, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, uint32(840144), EstimateHeight(tip.Add(24*time.Hour), 840000, tip))
+	assert.Equal(t, uint32(839856), EstimateHeight(tip.Add(-24*time.Hour), 840000, tip))
+	assert.Equal(t, uint32(0), EstimateHeight(tip.Add(-24*365*100*time.Hour), 840000, tip))
+	assert.Equal(t, tip.Add(24*time.Hour), EstimateTime(840144, 840000, tip))
+	assert.Equal(t, tip.Add(-time.Hour), EstimateTime(839994, 840000, tip))
+}
@@ -0,0 +1,355 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ErrWalletID       Error = "invalid wallet id: use letters, digits, '_' and '-'"
+	ErrWalletExists   Error = "wallet already exists"
+	ErrWalletNotFound Error = "wallet not found"
+	ErrWalletLocked   Error = "wallet is locked"
+
+	// managerStoragePrefix and managerStorageSuffix surround the id of a
+	// wallet in the storage key of its record.
+	managerStoragePrefix = "wallet-"
+	managerStorageSuffix = ".json"
+)
+
+// TxMetadata is what a wallet records of one of its transactions, beyond
+// what the chain knows.
+type TxMetadata struct {
+	// Account is the account the transaction spends from or pays to.
+	Account uint32 `json:"account"`
+	// Memo is a free note, e.g. the invoice the transaction pays.
+	Memo string `json:"memo,omitempty"`
+	// Created is when the wallet built or first saw the transaction.
+	Created time.Time `json:"created"`
+}
+
+// walletRecord is the persisted record of a managed wallet: its encrypted
+// keystore and its metadata, in clear.
+type walletRecord struct {
+	Keystore     json.RawMessage       `json:"keystore"`
+	Network      Network               `json:"network"`
+	Accounts     []uint32              `json:"accounts"`
+	Labels       []Label               `json:"labels,omitempty"`
+	Transactions map[string]TxMetadata `json:"transactions,omitempty"`
+}
+
+// Manager keeps several wallets, identified by ids, in a Storage: their
+// keys, encrypted with a password as keystores, the accounts in use, the
+// labels of their addresses and transactions and the metadata of their
+// transactions, and, through Allocator, the derivation indices handed out.
+// Wallets are unlocked with their password to sign; their metadata is
+// readable while locked. It is safe for concurrent use.
+type Manager struct {
+	mu       sync.Mutex
+	storage  Storage
+	unlocked map[string]*Wallet
+}
+
+// NewManager opens the wallets kept in storage. A nil storage keeps them in
+// memory.
+func NewManager(storage Storage) *Manager {
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+	return &Manager{storage: storage, unlocked: make(map[string]*Wallet)}
+}
+
+// Create adds a wallet under a new id, with its account 0, encrypting its
+// keys with password, and leaves it unlocked.
+func (s *Manager) Create(id string, wallet *Wallet, password string) error {
+	key, err := managerStorageKey(id)
+	if err != nil {
+		return err
+	}
+	keystore, err := wallet.ExportKeystore(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.storage.Get(key); err == nil {
+		return ErrWalletExists
+	} else if !errors.Is(err, ErrStorageNotFound) {
+		return err
+	}
+	record := &walletRecord{Keystore: keystore, Network: wallet.network, Accounts: []uint32{0}}
+	if err := s.save(key, record); err != nil {
+		return err
+	}
+	s.unlocked[id] = wallet
+	return nil
+}
+
+// IDs returns the sorted ids of the wallets.
+func (s *Manager) IDs() ([]string, error) {
+	keys, err := s.storage.Keys(managerStoragePrefix)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, key := range keys {
+		if id, ok := strings.CutSuffix(strings.TrimPrefix(key, managerStoragePrefix), managerStorageSuffix); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Unlock decrypts the keys of a wallet with its password, and keeps the
+// wallet unlocked until Lock.
+func (s *Manager) Unlock(id, password string) (*Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if wallet, ok := s.unlocked[id]; ok {
+		return wallet, nil
+	}
+	record, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := ImportKeystore(record.Keystore, password)
+	if err != nil {
+		return nil, err
+	}
+	s.unlocked[id] = wallet
+	return wallet, nil
+}
+
+// Lock forgets the unlocked wallet of an id.
+func (s *Manager) Lock(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.unlocked, id)
+}
+
+// Wallet returns the wallet of an id, or ErrWalletLocked until Unlock.
+func (s *Manager) Wallet(id string) (*Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if wallet, ok := s.unlocked[id]; ok {
+		return wallet, nil
+	}
+	if _, err := s.load(id); err != nil {
+		return nil, err
+	}
+	return nil, ErrWalletLocked
+}
+
+// Remove deletes a wallet and its metadata. The allocations of its accounts
+// stay in the storage.
+func (s *Manager) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.load(id); err != nil {
+		return err
+	}
+	delete(s.unlocked, id)
+	key, _ := managerStorageKey(id)
+	return s.storage.Delete(key)
+}
+
+// AddAccount records an account of a wallet as in use and returns it. The
+// wallet must be unlocked to derive the account.
+func (s *Manager) AddAccount(id string, index uint32) (*Account, error) {
+	wallet, err := s.Wallet(id)
+	if err != nil {
+		return nil, err
+	}
+	account, err := wallet.AccountAt(index)
+	if err != nil {
+		return nil, err
+	}
+	err = s.update(id, func(record *walletRecord) {
+		for _, existing := range record.Accounts {
+			if existing == index {
+				return
+			}
+		}
+		record.Accounts = append(record.Accounts, index)
+		sort.Slice(record.Accounts, func(i, j int) bool { return record.Accounts[i] < record.Accounts[j] })
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Accounts returns the indexes of the accounts of a wallet in use, sorted.
+func (s *Manager) Accounts(id string) ([]uint32, error) {
+	record, err := s.record(id)
+	if err != nil {
+		return nil, err
+	}
+	return record.Accounts, nil
+}
+
+// Allocator returns the AddressAllocator of an account of a wallet, which
+// persists its derivation indices in the storage of the manager. The wallet
+// must be unlocked to derive the account.
+func (s *Manager) Allocator(id string, index uint32, config *AllocatorConfig) (*AddressAllocator, error) {
+	account, err := s.AddAccount(id, index)
+	if err != nil {
+		return nil, err
+	}
+	return NewAddressAllocator(account, s.storage, config)
+}
+
+// SetLabel labels an address, transaction or other reference of a wallet,
+// e.g. from Wallet.AddressLabel, replacing the label of the same type and
+// reference. A label without text nor spendable flag removes it.
+func (s *Manager) SetLabel(id string, label Label) error {
+	if err := label.Validate(); err != nil {
+		return err
+	}
+	return s.update(id, func(record *walletRecord) {
+		record.setLabel(label)
+	})
+}
+
+// Labels returns the labels of a wallet, in the order they were first set.
+func (s *Manager) Labels(id string) ([]Label, error) {
+	record, err := s.record(id)
+	if err != nil {
+		return nil, err
+	}
+	return record.Labels, nil
+}
+
+// ExportLabels exports the labels of a wallet in the BIP329 format, e.g. for
+// Sparrow or Bitcoin Core.
+func (s *Manager) ExportLabels(id string) ([]byte, error) {
+	labels, err := s.Labels(id)
+	if err != nil {
+		return nil, err
+	}
+	return ExportLabels(labels)
+}
+
+// ImportLabels merges labels in the BIP329 format into those of a wallet,
+// the imported ones replacing those of the same type and reference, and
+// returns the number of labels imported.
+func (s *Manager) ImportLabels(id string, data []byte) (int, error) {
+	labels, err := ImportLabels(data)
+	if err != nil {
+		return 0, err
+	}
+	err = s.update(id, func(record *walletRecord) {
+		for _, label := range labels {
+			record.setLabel(label)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(labels), nil
+}
+
+// SetTxMetadata records the metadata of a transaction of a wallet.
+func (s *Manager) SetTxMetadata(id, txid string, metadata TxMetadata) error {
+	return s.update(id, func(record *walletRecord) {
+		if record.Transactions == nil {
+			record.Transactions = make(map[string]TxMetadata)
+		}
+		record.Transactions[txid] = metadata
+	})
+}
+
+// TxMetadata returns the metadata of a transaction of a wallet, and false
+// when none is recorded.
+func (s *Manager) TxMetadata(id, txid string) (TxMetadata, bool, error) {
+	record, err := s.record(id)
+	if err != nil {
+		return TxMetadata{}, false, err
+	}
+	metadata, ok := record.Transactions[txid]
+	return metadata, ok, nil
+}
+
+// setLabel replaces the label of the same type and reference as label, or
+// removes it when label is empty.
+func (s *walletRecord) setLabel(label Label) {
+	remove := label.Label == "" && label.Spendable == nil
+	for i, existing := range s.Labels {
+		if existing.Type == label.Type && existing.Ref == label.Ref {
+			if remove {
+				s.Labels = append(s.Labels[:i], s.Labels[i+1:]...)
+			} else {
+				s.Labels[i] = label
+			}
+			return
+		}
+	}
+	if !remove {
+		s.Labels = append(s.Labels, label)
+	}
+}
+
+// record loads the record of a wallet under the lock.
+func (s *Manager) record(id string) (*walletRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+// update applies a change to the record of a wallet and persists it.
+func (s *Manager) update(id string, change func(record *walletRecord)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, err := s.load(id)
+	if err != nil {
+		return err
+	}
+	change(record)
+	key, _ := managerStorageKey(id)
+	return s.save(key, record)
+}
+
+// load reads the record of a wallet. It must be called with the lock held.
+func (s *Manager) load(id string) (*walletRecord, error) {
+	key, err := managerStorageKey(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.storage.Get(key)
+	if errors.Is(err, ErrStorageNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record walletRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// save persists the record of a wallet. It must be called with the lock
+// held.
+func (s *Manager) save(key string, record *walletRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(key, data)
+}
+
+// managerStorageKey returns the storage key of the record of a wallet.
+func managerStorageKey(id string) (string, error) {
+	if id == "" || strings.Trim(id, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-") != "" {
+		return "", ErrWalletID
+	}
+	return fmt.Sprintf("%s%s%s", managerStoragePrefix, id, managerStorageSuffix), nil
+}
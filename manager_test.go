@@ -0,0 +1,88 @@
+package p2pkh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Manager(t *testing.T) {
+	fastKeystoreScrypt(t)
+	storage, err := NewFileStorage(t.TempDir())
+	assert.NoError(t, err)
+	manager := NewManager(storage)
+	wallet := createKnownWallet(t, NetworkMainnet)
+
+	assert.NoError(t, manager.Create("savings", wallet, "correct horse"))
+	assert.ErrorIs(t, manager.Create("savings", wallet, "correct horse"), ErrWalletExists)
+	assert.ErrorIs(t, manager.Create("../etc", wallet, "correct horse"), ErrWalletID)
+	assert.NoError(t, manager.Create("daily", createKnownWallet(t, NetworkTestnet), "battery staple"))
+	ids, err := manager.IDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"daily", "savings"}, ids)
+
+	account, err := manager.AddAccount("savings", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), account.Index())
+	allocator, err := manager.Allocator("savings", 0, nil)
+	assert.NoError(t, err)
+	first, err := allocator.NextReceiveAddress()
+	assert.NoError(t, err)
+	label, err := first.AddressLabel("invoice 42")
+	assert.NoError(t, err)
+	assert.NoError(t, manager.SetLabel("savings", label))
+	assert.ErrorIs(t, manager.SetLabel("savings", Label{Type: LabelTx, Ref: "00"}), ErrLabelInvalid)
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, manager.SetTxMetadata("savings", testTxID, TxMetadata{Account: 2, Memo: "rent", Created: created}))
+
+	// A new manager of the same storage finds everything, locked.
+	manager = NewManager(storage)
+	_, err = manager.Wallet("savings")
+	assert.ErrorIs(t, err, ErrWalletLocked)
+	_, err = manager.Wallet("missing")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+	accounts, err := manager.Accounts("savings")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{0, 2}, accounts)
+	labels, err := manager.Labels("savings")
+	assert.NoError(t, err)
+	assert.Equal(t, []Label{label}, labels)
+	metadata, ok, err := manager.TxMetadata("savings", testTxID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "rent", metadata.Memo)
+	assert.True(t, created.Equal(metadata.Created))
+	_, err = manager.AddAccount("savings", 3)
+	assert.ErrorIs(t, err, ErrWalletLocked)
+
+	_, err = manager.Unlock("savings", "wrong")
+	assert.ErrorIs(t, err, ErrKeystorePassword)
+	unlocked, err := manager.Unlock("savings", "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressInfo().String(), unlocked.AddressInfo().String())
+	same, err := manager.Wallet("savings")
+	assert.NoError(t, err)
+	assert.Same(t, unlocked, same)
+
+	// The allocations survive too.
+	allocator, err = manager.Allocator("savings", 0, nil)
+	assert.NoError(t, err)
+	second, err := allocator.NextReceiveAddress()
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Path(), second.Path())
+
+	assert.NoError(t, manager.SetLabel("savings", Label{Type: LabelAddress, Ref: label.Ref}))
+	labels, err = manager.Labels("savings")
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+
+	manager.Lock("savings")
+	_, err = manager.Wallet("savings")
+	assert.ErrorIs(t, err, ErrWalletLocked)
+	assert.NoError(t, manager.Remove("daily"))
+	assert.ErrorIs(t, manager.Remove("daily"), ErrWalletNotFound)
+	ids, err = manager.IDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"savings"}, ids)
+}
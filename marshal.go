@@ -0,0 +1,139 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const ErrWalletState Error = "invalid wallet state"
+
+// walletState is the public state of a wallet serialized by MarshalJSON.
+type walletState struct {
+	// XPub is the deepest extended public key above the wallet path that
+	// the wallet could export, usually the account key.
+	XPub string `json:"xpub"`
+	// Origin is the origin of XPub, absent for wallets rooted at their own
+	// key such as those of NewFromExtendedPublicKey.
+	Origin      *walletStateOrigin `json:"origin,omitempty"`
+	Path        string             `json:"path"`
+	Network     Network            `json:"network"`
+	AddressType AddressType        `json:"address_type"`
+	// AddressTypeOverride is set when AddressType is not the one of the
+	// path purpose, e.g. for the wallets of Electrum segwit keystores.
+	AddressTypeOverride bool `json:"address_type_override,omitempty"`
+	// Uncompressed is set for wallets of uncompressed keys.
+	Uncompressed bool `json:"uncompressed,omitempty"`
+}
+
+// walletStateOrigin is the master fingerprint and path of a wallet state key.
+type walletStateOrigin struct {
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+}
+
+var (
+	_ json.Marshaler   = (*Wallet)(nil)
+	_ json.Unmarshaler = (*Wallet)(nil)
+)
+
+// MarshalJSON serializes the public state of the wallet: its extended public
+// key with its origin, its path, network, address type and key compression.
+// No private material is serialized, so that the wallet restored by
+// UnmarshalJSON is watch-only, e.g. to cache the derived wallets of a server
+// without re-deriving them from the mnemonic.
+func (s *Wallet) MarshalJSON() ([]byte, error) {
+	state := walletState{
+		Path:                s.path,
+		Network:             s.network,
+		AddressType:         s.AddressType(),
+		AddressTypeOverride: s.addrType != "",
+		Uncompressed:        s.uncompressed,
+	}
+	key := s.extendedKey
+	if len(s.levels) > 0 && s.root != nil {
+		origin, err := s.keyOrigin()
+		if err != nil {
+			return nil, err
+		}
+		key = origin.key
+		state.Origin = &walletStateOrigin{
+			Fingerprint: fmt.Sprintf("%08x", origin.fingerprint),
+			Path:        "m" + formatPathLevels(origin.path),
+		}
+	}
+	xpub, err := key.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	state.XPub = xpub.String()
+	return json.Marshal(state)
+}
+
+// UnmarshalJSON restores a watch-only wallet serialized by MarshalJSON. The
+// wallet has no chain backend.
+func (s *Wallet) UnmarshalJSON(data []byte) error {
+	var state walletState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("%w: %w", ErrWalletState, err)
+	}
+	params, err := selectNetworkParams(state.Network)
+	if err != nil {
+		return err
+	}
+	key, err := hdkeychain.NewKeyFromString(state.XPub)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWalletState, err)
+	}
+	if !key.IsForNet(params) {
+		return ErrXPubNetwork
+	}
+	if key, err = key.Neuter(); err != nil {
+		return err
+	}
+
+	var wallet *Wallet
+	if state.Origin == nil {
+		if state.Path != "m" {
+			return ErrWalletState
+		}
+		if wallet, err = newWalletFromKey(key, state.Path, params, state.Network); err != nil {
+			return err
+		}
+		wallet.root = key
+	} else {
+		origin, err := state.Origin.keyOrigin(key)
+		if err != nil {
+			return err
+		}
+		if wallet, err = newWallet(key, origin, state.Path, state.Network); err != nil {
+			return err
+		}
+	}
+	if state.AddressTypeOverride {
+		if _, err := publicKeyAddress(wallet.publicKey, state.AddressType, params); err != nil {
+			return fmt.Errorf("%w: %w", ErrWalletState, err)
+		}
+		wallet.addrType = state.AddressType
+	} else if state.AddressType != wallet.AddressType() {
+		return ErrWalletState
+	}
+	wallet.setUncompressed(state.Uncompressed)
+	*s = *wallet
+	return nil
+}
+
+// keyOrigin returns the origin of the wallet state key.
+func (s *walletStateOrigin) keyOrigin(key *hdkeychain.ExtendedKey) (*keyOrigin, error) {
+	fingerprint, err := strconv.ParseUint(s.Fingerprint, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWalletState, err)
+	}
+	path, err := ParsePath(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &keyOrigin{fingerprint: uint32(fingerprint), path: path, key: key}, nil
+}
@@ -0,0 +1,73 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wallet_MarshalJSON(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(7)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(child)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "prv")
+	assert.NotContains(t, string(data), testMnemonic)
+
+	var state walletState
+	assert.NoError(t, json.Unmarshal(data, &state))
+	origin, err := wallet.keyOrigin()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/7`, state.Path)
+	assert.Equal(t, `m/44'/0'/0'`, state.Origin.Path)
+	assert.Equal(t, AddressTypeP2PKH, state.AddressType)
+	assert.Equal(t, origin.String(), state.Origin.Fingerprint+"/44'/0'/0'")
+
+	var restored Wallet
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.True(t, restored.IsWatchOnly())
+	assert.Equal(t, child.Path(), restored.Path())
+	assert.Equal(t, child.AddressHex(), restored.AddressHex())
+	// The restored wallet keeps its origin, e.g. for descriptors and the
+	// other addresses of its account.
+	desc, err := child.Descriptor()
+	assert.NoError(t, err)
+	restoredDesc, err := restored.Descriptor()
+	assert.NoError(t, err)
+	assert.Equal(t, desc, restoredDesc)
+	change, err := child.DeriveChange(2)
+	assert.NoError(t, err)
+	restoredChange, err := restored.DeriveChange(2)
+	assert.NoError(t, err)
+	assert.Equal(t, change.AddressHex(), restoredChange.AddressHex())
+
+	// A watch-only wallet rooted at its own key.
+	xpub, err := child.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	data, err = json.Marshal(watch)
+	assert.NoError(t, err)
+	restored = Wallet{}
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, "m", restored.Path())
+	assert.Equal(t, child.AddressHex(), restored.AddressHex())
+}
+
+func Test_Wallet_UnmarshalJSON_Invalid(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	data, err := json.Marshal(wallet)
+	assert.NoError(t, err)
+	var restored Wallet
+
+	assert.ErrorIs(t, restored.UnmarshalJSON([]byte(strings.Replace(string(data), `"p2pkh"`, `"p2wpkh"`, 1))), ErrWalletState)
+	assert.ErrorIs(t, restored.UnmarshalJSON([]byte(strings.Replace(string(data), `"mainnet"`, `"testnet"`, 1))), ErrXPubNetwork)
+	assert.ErrorIs(t, restored.UnmarshalJSON([]byte(strings.Replace(string(data), `"mainnet"`, `"regtest"`, 1))), ErrUnsupportedNet)
+	assert.ErrorIs(t, restored.UnmarshalJSON([]byte(strings.Replace(string(data), `"m/44'/0'/0'/0"`, `"m/49'/0'/0'/0"`, 1))), ErrInvalidPath)
+	assert.Error(t, restored.UnmarshalJSON([]byte(`{"xpub":"xpub123","network":"mainnet"}`)))
+	assert.Error(t, restored.UnmarshalJSON([]byte(`[]`)))
+}
@@ -0,0 +1,166 @@
+package p2pkh
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+const (
+	ErrInvalidTargetBlocks Error = "target blocks must be positive"
+	ErrInvalidConfidence   Error = "confidence must be between 0 and 1"
+
+	// maxBlockVSize is the capacity of a block, in vbytes.
+	maxBlockVSize = 1000000
+	// defaultFeeConfidence is the confirmation probability targeted by
+	// MempoolFeeEstimator.
+	defaultFeeConfidence = 0.9
+)
+
+// feeHistogramRates are the lower bounds of the histogram buckets, in sat/vB.
+var feeHistogramRates = []FeeRate{
+	1, 2, 3, 4, 5, 6, 8, 10, 12, 15, 20, 30, 40, 50, 60, 70, 80, 100, 120, 140,
+	170, 200, 250, 300, 400, 500, 600, 700, 800, 1000, 1200, 1400, 1700, 2000,
+}
+
+// FeeBucket is the mempool transactions paying at least FeeRate sat/vB, and
+// less than the rate of the next bucket.
+type FeeBucket struct {
+	FeeRate FeeRate
+	VSize   int64
+	Count   int
+}
+
+// FeeHistogram is the distribution of the mempool by fee rate, highest first.
+type FeeHistogram struct {
+	Buckets    []FeeBucket
+	TotalVSize int64
+	// BlockVSize is the capacity of a block, 1000000 vB by default.
+	BlockVSize int64
+	// InflowVSize is the size of the transactions entering the mempool per
+	// block, assumed distributed like the current mempool. It defaults to
+	// BlockVSize, a mempool in steady state.
+	InflowVSize int64
+}
+
+// NewFeeHistogram builds the fee histogram of mempool entries.
+func NewFeeHistogram(entries []MempoolEntry) *FeeHistogram {
+	histogram := &FeeHistogram{BlockVSize: maxBlockVSize, InflowVSize: maxBlockVSize}
+	buckets := make([]FeeBucket, len(feeHistogramRates))
+	for i, rate := range feeHistogramRates {
+		buckets[i].FeeRate = rate
+	}
+	for _, entry := range entries {
+		if entry.VSize <= 0 {
+			continue
+		}
+		rate := FeeRate(int64(entry.Fee) / entry.VSize)
+		i := sort.Search(len(feeHistogramRates), func(i int) bool { return feeHistogramRates[i] > rate }) - 1
+		if i < 0 {
+			i = 0
+		}
+		buckets[i].VSize += entry.VSize
+		buckets[i].Count++
+		histogram.TotalVSize += entry.VSize
+	}
+
+	for i := len(buckets) - 1; i >= 0; i-- {
+		if buckets[i].Count > 0 {
+			histogram.Buckets = append(histogram.Buckets, buckets[i])
+		}
+	}
+	return histogram
+}
+
+// VSizeAbove returns the size of the mempool transactions in the buckets
+// paying at least feeRate, which a transaction at feeRate waits behind.
+func (s *FeeHistogram) VSizeAbove(feeRate FeeRate) int64 {
+	var vsize int64
+	for _, bucket := range s.Buckets {
+		if bucket.FeeRate >= feeRate {
+			vsize += bucket.VSize
+		}
+	}
+	return vsize
+}
+
+// ConfirmationProbability estimates the probability that a transaction paying
+// feeRate confirms within the given number of blocks. The transactions ahead
+// are those of the mempool paying at least feeRate, plus the new arrivals
+// outbidding it, modeled as a normal variable whose standard deviation
+// equals its mean.
+func (s *FeeHistogram) ConfirmationProbability(feeRate FeeRate, blocks int) float64 {
+	if blocks <= 0 {
+		return 0
+	}
+	ahead := float64(s.VSizeAbove(feeRate))
+	capacity := float64(s.BlockVSize) * float64(blocks)
+	inflow := 0.0
+	if s.TotalVSize > 0 {
+		inflow = float64(s.InflowVSize) * ahead / float64(s.TotalVSize)
+	}
+
+	mean := ahead + inflow*float64(blocks)
+	if inflow == 0 {
+		if mean < capacity {
+			return 1
+		}
+		return 0
+	}
+	stddev := inflow * math.Sqrt(float64(blocks))
+	return 0.5 * math.Erfc(-(capacity-mean)/(stddev*math.Sqrt2))
+}
+
+// EstimateFeeRate returns the lowest bucket fee rate confirming within
+// targetBlocks with at least the given probability.
+func (s *FeeHistogram) EstimateFeeRate(targetBlocks int, confidence float64) (FeeRate, error) {
+	if targetBlocks <= 0 {
+		return 0, ErrInvalidTargetBlocks
+	}
+	if confidence <= 0 || confidence > 1 {
+		return 0, ErrInvalidConfidence
+	}
+	for _, rate := range feeHistogramRates {
+		if s.ConfirmationProbability(rate, targetBlocks) >= confidence {
+			return rate, nil
+		}
+	}
+	return feeHistogramRates[len(feeHistogramRates)-1], nil
+}
+
+// MempoolFeeEstimator is a FeeEstimator analyzing the mempool of a backend.
+type MempoolFeeEstimator struct {
+	provider   MempoolProvider
+	confidence float64
+}
+
+// NewMempoolFeeEstimator returns a FeeEstimator targeting the given
+// confirmation probability, 0.9 when zero.
+func NewMempoolFeeEstimator(provider MempoolProvider, confidence float64) (*MempoolFeeEstimator, error) {
+	if confidence == 0 {
+		confidence = defaultFeeConfidence
+	}
+	if confidence < 0 || confidence > 1 {
+		return nil, ErrInvalidConfidence
+	}
+	return &MempoolFeeEstimator{provider: provider, confidence: confidence}, nil
+}
+
+// Histogram returns the current fee histogram of the backend mempool.
+func (s *MempoolFeeEstimator) Histogram(ctx context.Context) (*FeeHistogram, error) {
+	entries, err := s.provider.MempoolEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewFeeHistogram(entries), nil
+}
+
+// EstimateFeeRate estimates the fee rate confirming within targetBlocks from
+// the current mempool.
+func (s *MempoolFeeEstimator) EstimateFeeRate(ctx context.Context, targetBlocks int) (FeeRate, error) {
+	histogram, err := s.Histogram(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return histogram.EstimateFeeRate(targetBlocks, s.confidence)
+}
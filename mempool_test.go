@@ -0,0 +1,78 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMempool returns 500 kvB at 50 sat/vB, 2 MvB at 10 sat/vB and 3 MvB at 2 sat/vB.
+func testMempool() []MempoolEntry {
+	var entries []MempoolEntry
+	for i := 0; i < 100; i++ {
+		entries = append(entries,
+			MempoolEntry{Fee: 5000 * 50, VSize: 5000},
+			MempoolEntry{Fee: 20000 * 10, VSize: 20000},
+			MempoolEntry{Fee: 30000*2 + 100, VSize: 30000},
+		)
+	}
+	return entries
+}
+
+func Test_NewFeeHistogram(t *testing.T) {
+	histogram := NewFeeHistogram(append(testMempool(), MempoolEntry{Fee: 10, VSize: 0}))
+	assert.Equal(t, int64(5500000), histogram.TotalVSize)
+	assert.Equal(t, []FeeBucket{
+		{FeeRate: 50, VSize: 500000, Count: 100},
+		{FeeRate: 10, VSize: 2000000, Count: 100},
+		{FeeRate: 2, VSize: 3000000, Count: 100},
+	}, histogram.Buckets)
+	assert.Equal(t, int64(2500000), histogram.VSizeAbove(10))
+	assert.Equal(t, int64(500000), histogram.VSizeAbove(11))
+}
+
+func Test_FeeHistogram_ConfirmationProbability(t *testing.T) {
+	histogram := NewFeeHistogram(testMempool())
+	assert.Greater(t, histogram.ConfirmationProbability(50, 1), 0.99)
+	assert.Less(t, histogram.ConfirmationProbability(10, 1), 0.01)
+	assert.Less(t, histogram.ConfirmationProbability(10, 3), histogram.ConfirmationProbability(10, 6))
+	assert.Less(t, histogram.ConfirmationProbability(2, 6), histogram.ConfirmationProbability(10, 6))
+	assert.Equal(t, 0.0, histogram.ConfirmationProbability(50, 0))
+
+	// Without inflow, the estimate is deterministic.
+	histogram.InflowVSize = 0
+	assert.Equal(t, 1.0, histogram.ConfirmationProbability(10, 3))
+	assert.Equal(t, 0.0, histogram.ConfirmationProbability(10, 2))
+}
+
+func Test_MempoolFeeEstimator(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeBackend()
+	estimator, err := NewMempoolFeeEstimator(backend, 0)
+	assert.NoError(t, err)
+	var _ FeeEstimator = estimator
+
+	// An empty mempool confirms anything.
+	rate, err := estimator.EstimateFeeRate(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, FeeRate(1), rate)
+
+	backend.mempool = testMempool()
+	rate, err = estimator.EstimateFeeRate(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, FeeRate(12), rate)
+	slow, err := estimator.EstimateFeeRate(ctx, 144)
+	assert.NoError(t, err)
+	assert.Less(t, slow, rate)
+
+	_, err = estimator.EstimateFeeRate(ctx, 0)
+	assert.ErrorIs(t, err, ErrInvalidTargetBlocks)
+	_, err = NewMempoolFeeEstimator(backend, 1.5)
+	assert.ErrorIs(t, err, ErrInvalidConfidence)
+
+	backend.err = errors.New("backend down")
+	_, err = estimator.EstimateFeeRate(ctx, 1)
+	assert.EqualError(t, err, "backend down")
+}
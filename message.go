@@ -0,0 +1,129 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// messageMagic prefixes the messages signed by the legacy "signmessage"
+	// RPC.
+	messageMagic = "Bitcoin Signed Message:\n"
+
+	// compactSigSize is the size of a compact signature: a header byte and
+	// the R and S values.
+	compactSigSize = 65
+	// The header of a compact signature is 27-30 for uncompressed keys and
+	// 31-34 for compressed keys; BIP137 adds 35-38 for P2SH-P2WPKH and 39-42
+	// for P2WPKH addresses.
+	compactSigMinHeader        = 27
+	compactSigCompressedHeader = 31
+	compactSigSegwitHeader     = 35
+	compactSigMaxHeader        = 42
+)
+
+// legacyMessageHash returns the hash signed by legacy message signatures:
+// the double SHA256 of the magic and the message, both length-prefixed.
+func legacyMessageHash(message string) []byte {
+	var buf bytes.Buffer
+	_ = wire.WriteVarString(&buf, 0, messageMagic)
+	_ = wire.WriteVarString(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+// signMessageLegacy signs a message hash with the compact, base64 encoded,
+// signature of the legacy format, for the compressed or uncompressed key.
+func signMessageLegacy(key *btcec.PrivateKey, hash []byte, compressed bool) string {
+	return base64.StdEncoding.EncodeToString(ecdsa.SignCompact(key, hash, compressed))
+}
+
+// recoverMessageLegacy returns the public key recovered from a legacy
+// signature of a message hash, and whether the signer used it compressed.
+// BIP137 headers of segwit addresses are accepted.
+func recoverMessageLegacy(signature string, hash []byte) (*btcec.PublicKey, bool, error) {
+	data, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrMessageSignature, err)
+	}
+	if len(data) != compactSigSize || data[0] < compactSigMinHeader || data[0] > compactSigMaxHeader {
+		return nil, false, ErrMessageSignature
+	}
+	if data[0] >= compactSigSegwitHeader {
+		// BIP137 segwit headers flag compressed keys.
+		data[0] = compactSigCompressedHeader + (data[0]-compactSigCompressedHeader)%4
+	}
+	publicKey, compressed, err := ecdsa.RecoverCompact(data, hash)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrMessageSignature, err)
+	}
+	return publicKey, compressed, nil
+}
+
+// SignMessage signs a message with the wallet key in the "Bitcoin Signed
+// Message" format of the signmessage RPC: a base64 compact signature proving
+// control of the wallet address.
+func (s *Wallet) SignMessage(message string) (string, error) {
+	if s.IsWatchOnly() {
+		return "", ErrWatchOnly
+	}
+	key, err := s.extendedKey.ECPrivKey()
+	if err != nil {
+		return "", err
+	}
+	s.logSign("message", "", messageAttr(message))
+	return signMessageLegacy(key, legacyMessageHash(message), !s.uncompressed), nil
+}
+
+// VerifyMessage verifies a "Bitcoin Signed Message" signature of a message by
+// an address of a supported network. Signatures of P2WPKH and P2SH-P2WPKH
+// addresses, with BIP137 or compressed key headers, are accepted. It reports
+// false when the signature is well-formed but not made by the address.
+func VerifyMessage(address, signature, message string) (bool, error) {
+	var parsed Address
+	var err error
+	for _, network := range Networks() {
+		if parsed, err = ParseAddress(address, network); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	params, err := selectNetworkParams(parsed.Network())
+	if err != nil {
+		return false, err
+	}
+
+	publicKey, compressed, err := recoverMessageLegacy(signature, legacyMessageHash(message))
+	if err != nil {
+		return false, err
+	}
+
+	var addr btcutil.Address
+	switch parsed.Type() {
+	case AddressTypeP2PKH:
+		serialized := publicKey.SerializeUncompressed()
+		if compressed {
+			serialized = publicKey.SerializeCompressed()
+		}
+		addr, err = btcutil.NewAddressPubKeyHash(btcutil.Hash160(serialized), params)
+	case AddressTypeP2WPKH, AddressTypeP2SH:
+		if !compressed {
+			return false, nil
+		}
+		addr, err = publicKeyAddress(publicKey, parsed.Type(), params)
+	default:
+		return false, ErrUnsupportedAddressType
+	}
+	if err != nil {
+		return false, err
+	}
+	return addr.EncodeAddress() == parsed.String(), nil
+}
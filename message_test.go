@@ -0,0 +1,106 @@
+package p2pkh
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SignMessageLegacy(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	key, err := wallet.extendedKey.ECPrivKey()
+	assert.NoError(t, err)
+
+	hash := legacyMessageHash("Hello World")
+	assert.NotEqual(t, hash, legacyMessageHash("Hello World!"))
+	signature := signMessageLegacy(key, hash, true)
+	assert.Equal(t, signature, signMessageLegacy(key, hash, true))
+
+	publicKey, compressed, err := recoverMessageLegacy(signature, hash)
+	assert.NoError(t, err)
+	assert.True(t, compressed)
+	assert.True(t, publicKey.IsEqual(wallet.PublicKey()))
+
+	publicKey, _, err = recoverMessageLegacy(signature, legacyMessageHash("Hello World!"))
+	if err == nil {
+		assert.False(t, publicKey.IsEqual(wallet.PublicKey()))
+	}
+
+	_, _, err = recoverMessageLegacy("not base64!", hash)
+	assert.ErrorIs(t, err, ErrMessageSignature)
+	_, _, err = recoverMessageLegacy(signature[:12], hash)
+	assert.ErrorIs(t, err, ErrMessageSignature)
+}
+
+func Test_SignMessage(t *testing.T) {
+	for _, network := range []Network{NetworkMainnet, NetworkTestnet} {
+		wallet := createKnownWallet(t, network)
+		signature, err := wallet.SignMessage("I own this address")
+		assert.NoError(t, err)
+
+		valid, err := VerifyMessage(wallet.AddressHex(), signature, "I own this address")
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		valid, err = VerifyMessage(wallet.AddressHex(), signature, "I own that address")
+		assert.NoError(t, err)
+		assert.False(t, valid)
+
+		// The key also signs for its segwit addresses.
+		valid, err = VerifyMessage(wallet.AddressP2WPKH().String(), signature, "I own this address")
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		valid, err = VerifyMessage(wallet.AddressNestedSegwit().String(), signature, "I own this address")
+		assert.NoError(t, err)
+		assert.True(t, valid)
+	}
+
+	wallet := createKnownWallet(t, NetworkMainnet)
+	signature, err := wallet.SignMessage("hello")
+	assert.NoError(t, err)
+	valid, err := VerifyMessage("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", signature, "hello")
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	// BIP137 header of a P2WPKH signature, 39-42 instead of 31-34.
+	data, err := base64.StdEncoding.DecodeString(signature)
+	assert.NoError(t, err)
+	data[0] += 8
+	valid, err = VerifyMessage(wallet.AddressP2WPKH().String(), base64.StdEncoding.EncodeToString(data), "hello")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// A signature of the uncompressed key is valid for its own address only.
+	key, err := wallet.extendedKey.ECPrivKey()
+	assert.NoError(t, err)
+	uncompressed := base64.StdEncoding.EncodeToString(ecdsa.SignCompact(key, legacyMessageHash("hello"), false))
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(key.PubKey().SerializeUncompressed()), &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	valid, err = VerifyMessage(addr.EncodeAddress(), uncompressed, "hello")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = VerifyMessage(wallet.AddressHex(), uncompressed, "hello")
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	valid, err = VerifyMessage(wallet.AddressP2WPKH().String(), uncompressed, "hello")
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = VerifyMessage("notanaddress", signature, "hello")
+	assert.Error(t, err)
+	_, err = VerifyMessage(wallet.AddressHex(), base64.StdEncoding.EncodeToString(data[:64]), "hello")
+	assert.ErrorIs(t, err, ErrMessageSignature)
+	_, err = VerifyMessage(wallet.AddressTaproot().String(), signature, "hello")
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = watch.SignMessage("hello")
+	assert.ErrorIs(t, err, ErrWatchOnly)
+}
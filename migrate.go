@@ -0,0 +1,160 @@
+package p2pkh
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const (
+	ErrMigrationGapLimit Error = "gap limit cannot be negative"
+
+	defaultGapLimit = 20
+)
+
+// LegacyConfig is the configuration of a legacy, pre-descriptor, watch-only
+// wallet: an account extended public key, the type of the addresses derived
+// from it, and the gap limit used to scan them.
+type LegacyConfig struct {
+	// XPub is the account extended public key. SLIP-132 keys (ypub, zpub...)
+	// are accepted and imply the address type.
+	XPub string
+	// AddressType is the type of the addresses. It defaults to the type
+	// implied by the version of XPub.
+	AddressType AddressType
+	// GapLimit is the number of unused addresses scanned ahead, 20 by default.
+	GapLimit int
+	// Fingerprint and Path are the optional origin of XPub, e.g. "m/84'/0'/0'".
+	Fingerprint uint32
+	Path        string
+}
+
+// MigrationAddress is an address derived both the legacy way and from the
+// migrated descriptor.
+type MigrationAddress struct {
+	Internal   bool
+	Index      uint32
+	Legacy     string
+	Descriptor string
+}
+
+// Migration is the descriptor setup equivalent to a LegacyConfig.
+type Migration struct {
+	// Descriptors are the receive and change descriptors, with checksums.
+	Descriptors []string
+	// Range is the range of indexes to import, covering the gap limit.
+	Range [2]int
+	// Addresses are the first addresses of both chains, derived both ways.
+	Addresses []MigrationAddress
+}
+
+// Consistent reports whether every checked address derived from the
+// descriptors matches the legacy one.
+func (s *Migration) Consistent() bool {
+	return len(s.Mismatches()) == 0
+}
+
+// Mismatches returns the checked addresses that differ between the legacy
+// derivation and the descriptors.
+func (s *Migration) Mismatches() []MigrationAddress {
+	var mismatches []MigrationAddress
+	for _, address := range s.Addresses {
+		if address.Legacy != address.Descriptor {
+			mismatches = append(mismatches, address)
+		}
+	}
+	return mismatches
+}
+
+// MigrateLegacy returns the descriptors replacing a legacy configuration, and
+// checks them by deriving the first n addresses of both chains the legacy way
+// and from the descriptors. n defaults to the gap limit.
+func MigrateLegacy(config *LegacyConfig, n int) (*Migration, error) {
+	key, network, addrType, err := parseSLIP132Key(config.XPub)
+	if err != nil {
+		return nil, err
+	}
+	if config.AddressType != "" {
+		if key, network, err = parseExtendedKey(config.XPub, config.AddressType); err != nil {
+			return nil, err
+		}
+		addrType = config.AddressType
+	}
+	if key, err = key.Neuter(); err != nil {
+		return nil, err
+	}
+	gapLimit := config.GapLimit
+	if gapLimit < 0 {
+		return nil, ErrMigrationGapLimit
+	}
+	if gapLimit == 0 {
+		gapLimit = defaultGapLimit
+	}
+	if n <= 0 {
+		n = gapLimit
+	}
+
+	keyExpr := key.String()
+	if config.Path != "" {
+		path, err := ParsePath(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		keyExpr = fmt.Sprintf("[%08x%s]%s", config.Fingerprint, formatPathLevels(path), keyExpr)
+	}
+
+	migration := &Migration{Range: [2]int{0, gapLimit - 1}}
+	for chain := uint32(0); chain < 2; chain++ {
+		desc, err := formatDescriptor(addrType, fmt.Sprintf("%s/%d/*", keyExpr, chain))
+		if err != nil {
+			return nil, err
+		}
+		if desc, err = AddDescriptorChecksum(desc); err != nil {
+			return nil, err
+		}
+		migration.Descriptors = append(migration.Descriptors, desc)
+
+		for index := uint32(0); index < uint32(n); index++ {
+			legacy, err := legacyAddress(key, addrType, network, chain, index)
+			if err != nil {
+				return nil, err
+			}
+			derived, err := DescriptorAddress(desc, network, index)
+			if err != nil {
+				return nil, err
+			}
+			migration.Addresses = append(migration.Addresses, MigrationAddress{
+				Internal:   chain == 1,
+				Index:      index,
+				Legacy:     legacy,
+				Descriptor: derived,
+			})
+		}
+	}
+	return migration, nil
+}
+
+// legacyAddress derives an address below an account key the way legacy
+// wallets do: account/chain/index, encoded as addrType.
+func legacyAddress(account *hdkeychain.ExtendedKey, addrType AddressType, network Network, chain, index uint32) (string, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return "", err
+	}
+	key, err := deriveKey(account, chain)
+	if err != nil {
+		return "", err
+	}
+	if key, err = deriveKey(key, index); err != nil {
+		return "", err
+	}
+	publicKey, err := key.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+	addr, err := publicKeyAddress(publicKey, addrType, params)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
@@ -0,0 +1,53 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MigrateLegacy(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	cosigner, err := wallet.Cosigner()
+	assert.NoError(t, err)
+
+	migration, err := MigrateLegacy(&LegacyConfig{
+		XPub:        cosigner.XPub,
+		Fingerprint: cosigner.Fingerprint,
+		Path:        cosigner.Path,
+		GapLimit:    5,
+	}, 0)
+	assert.NoError(t, err)
+	descs, err := wallet.descriptors()
+	assert.NoError(t, err)
+	assert.Equal(t, descs, migration.Descriptors)
+	assert.Equal(t, [2]int{0, 4}, migration.Range)
+	assert.Len(t, migration.Addresses, 10)
+	assert.True(t, migration.Consistent())
+
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressHex(), migration.Addresses[3].Legacy)
+	assert.True(t, migration.Addresses[5].Internal)
+
+	migration.Addresses[2].Descriptor = "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv"
+	assert.False(t, migration.Consistent())
+	assert.Len(t, migration.Mismatches(), 1)
+}
+
+func Test_MigrateLegacy_SLIP132(t *testing.T) {
+	// BIP84 test vector of the "abandon ... about" mnemonic.
+	zpub := "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	migration, err := MigrateLegacy(&LegacyConfig{XPub: zpub, Path: `m/84'/0'/0'`, Fingerprint: 0x73c5da0a}, 1)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^wpkh\(\[73c5da0a/84'/0'/0'\]xpub.*/0/\*\)#`, migration.Descriptors[0])
+	assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", migration.Addresses[0].Descriptor)
+	assert.Equal(t, "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el", migration.Addresses[1].Descriptor)
+	assert.True(t, migration.Consistent())
+
+	_, err = MigrateLegacy(&LegacyConfig{XPub: zpub, AddressType: AddressTypeP2TR}, 1)
+	assert.ErrorIs(t, err, ErrSLIP132Mismatch)
+
+	_, err = MigrateLegacy(&LegacyConfig{XPub: zpub, GapLimit: -1}, 1)
+	assert.ErrorIs(t, err, ErrMigrationGapLimit)
+}
@@ -0,0 +1,236 @@
+package p2pkh
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+const (
+	ErrMnemonicEntropy     Error = "mnemonic entropy must be 128 to 256 bits, a multiple of 32"
+	ErrMnemonicLanguage    Error = "unsupported mnemonic language"
+	ErrMnemonicLength      Error = "mnemonic must have 12, 15, 18, 21 or 24 words"
+	ErrMnemonicWord        Error = "mnemonic word not in the word list"
+	ErrMnemonicChecksum    Error = "invalid mnemonic checksum"
+	ErrMnemonicNoLanguage  Error = "mnemonic matches no word list"
+	mnemonicWordBits             = 11
+	mnemonicMinEntropyBits       = 128
+	mnemonicMaxEntropyBits       = 256
+)
+
+// Language is the language of the word list of a BIP39 mnemonic.
+type Language string
+
+const (
+	LanguageEnglish            Language = "english"
+	LanguageFrench             Language = "french"
+	LanguageSpanish            Language = "spanish"
+	LanguageItalian            Language = "italian"
+	LanguageJapanese           Language = "japanese"
+	LanguageKorean             Language = "korean"
+	LanguageCzech              Language = "czech"
+	LanguageChineseSimplified  Language = "chinese_simplified"
+	LanguageChineseTraditional Language = "chinese_traditional"
+)
+
+// mnemonicWordlist is the BIP39 word list of a language, with the index of
+// its words.
+type mnemonicWordlist struct {
+	language Language
+	words    []string
+	indexes  map[string]int
+}
+
+// mnemonicWordlists are the standard BIP39 word lists, in the order
+// MnemonicLanguage tries them. Words are NFKD normalized, as the mnemonics
+// hashed into seeds must be.
+var mnemonicWordlists = []*mnemonicWordlist{
+	newMnemonicWordlist(LanguageEnglish, wordlists.English),
+	newMnemonicWordlist(LanguageFrench, wordlists.French),
+	newMnemonicWordlist(LanguageSpanish, wordlists.Spanish),
+	newMnemonicWordlist(LanguageItalian, wordlists.Italian),
+	newMnemonicWordlist(LanguageJapanese, wordlists.Japanese),
+	newMnemonicWordlist(LanguageKorean, wordlists.Korean),
+	newMnemonicWordlist(LanguageCzech, wordlists.Czech),
+	newMnemonicWordlist(LanguageChineseSimplified, wordlists.ChineseSimplified),
+	newMnemonicWordlist(LanguageChineseTraditional, wordlists.ChineseTraditional),
+}
+
+// newMnemonicWordlist indexes the word list of a language.
+func newMnemonicWordlist(language Language, words []string) *mnemonicWordlist {
+	indexes := make(map[string]int, len(words))
+	for i, word := range words {
+		indexes[word] = i
+	}
+	return &mnemonicWordlist{language: language, words: words, indexes: indexes}
+}
+
+// Languages returns the languages of the supported word lists.
+func Languages() []Language {
+	languages := make([]Language, 0, len(mnemonicWordlists))
+	for _, wordlist := range mnemonicWordlists {
+		languages = append(languages, wordlist.language)
+	}
+	return languages
+}
+
+// GenerateMnemonic generates a BIP39 mnemonic of random entropy, from 128
+// bits (12 words) to 256 bits (24 words) by steps of 32 bits, in a language.
+// Japanese words are separated by ideographic spaces, as BIP39 recommends.
+func GenerateMnemonic(bits int, lang Language) (string, error) {
+	if bits < mnemonicMinEntropyBits || bits > mnemonicMaxEntropyBits || bits%32 != 0 {
+		return "", ErrMnemonicEntropy
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return mnemonicFromEntropy(entropy, lang)
+}
+
+// ValidateMnemonic checks that a mnemonic is made of words of the language
+// and that its checksum is valid.
+func ValidateMnemonic(mnemonic string, lang Language) error {
+	wordlist, err := lookupMnemonicWordlist(lang)
+	if err != nil {
+		return err
+	}
+	_, err = wordlist.entropy(strings.Fields(mnemonic))
+	return err
+}
+
+// MnemonicLanguage returns the language of a valid mnemonic.
+func MnemonicLanguage(mnemonic string) (Language, error) {
+	words := strings.Fields(mnemonic)
+	for _, wordlist := range mnemonicWordlists {
+		if _, err := wordlist.entropy(words); err == nil {
+			return wordlist.language, nil
+		}
+	}
+	if !mnemonicLength(len(words)) {
+		return "", ErrMnemonicLength
+	}
+	return "", ErrMnemonicNoLanguage
+}
+
+// ParseMnemonic parses a mnemonic typed or pasted by a user, whose words may
+// be separated by any whitespace and capitalized, and returns it in the
+// canonical form GenerateMnemonic produces, along with its language.
+// Failures are reported as ErrMnemonicLength, ErrMnemonicChecksum, or
+// ErrMnemonicNoLanguage when the words are not all of one word list.
+func ParseMnemonic(mnemonic string) (string, Language, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if !mnemonicLength(len(words)) {
+		return "", "", ErrMnemonicLength
+	}
+	// Words of a list failing the checksum are a typo rather than another
+	// language.
+	err := error(ErrMnemonicNoLanguage)
+	for _, wordlist := range mnemonicWordlists {
+		entropy, wordsErr := wordlist.entropy(words)
+		if wordsErr == nil {
+			canonical, err := mnemonicFromEntropy(entropy, wordlist.language)
+			return canonical, wordlist.language, err
+		}
+		if errors.Is(wordsErr, ErrMnemonicChecksum) {
+			err = wordsErr
+		}
+	}
+	return "", "", err
+}
+
+// mnemonicFromEntropy encodes entropy as a mnemonic of the language.
+func mnemonicFromEntropy(entropy []byte, lang Language) (string, error) {
+	wordlist, err := lookupMnemonicWordlist(lang)
+	if err != nil {
+		return "", err
+	}
+	bits := len(entropy) * 8
+	if bits < mnemonicMinEntropyBits || bits > mnemonicMaxEntropyBits || bits%32 != 0 {
+		return "", ErrMnemonicEntropy
+	}
+
+	// The checksum, the first bits/32 bits of the hash, follows the entropy.
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte(nil), entropy...), hash[0])
+	words := make([]string, (bits+bits/32)/mnemonicWordBits)
+	for i := range words {
+		words[i] = wordlist.words[readBits(data, i*mnemonicWordBits, mnemonicWordBits)]
+	}
+
+	separator := " "
+	if lang == LanguageJapanese {
+		separator = "\u3000"
+	}
+	return strings.Join(words, separator), nil
+}
+
+// entropy decodes the words of a mnemonic of the word list, checking their
+// checksum.
+func (s *mnemonicWordlist) entropy(words []string) ([]byte, error) {
+	if !mnemonicLength(len(words)) {
+		return nil, ErrMnemonicLength
+	}
+	totalBits := len(words) * mnemonicWordBits
+	data := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		index, ok := s.indexes[word]
+		if !ok {
+			return nil, ErrMnemonicWord
+		}
+		writeBits(data, i*mnemonicWordBits, mnemonicWordBits, index)
+	}
+
+	checksumBits := totalBits / 33
+	entropy := data[:(totalBits-checksumBits)/8]
+	hash := sha256.Sum256(entropy)
+	if readBits(data, len(entropy)*8, checksumBits) != readBits(hash[:], 0, checksumBits) {
+		return nil, ErrMnemonicChecksum
+	}
+	return entropy, nil
+}
+
+// lookupMnemonicWordlist returns the word list of a language.
+func lookupMnemonicWordlist(lang Language) (*mnemonicWordlist, error) {
+	for _, wordlist := range mnemonicWordlists {
+		if wordlist.language == lang {
+			return wordlist, nil
+		}
+	}
+	return nil, ErrMnemonicLanguage
+}
+
+// mnemonicLength reports whether a mnemonic may have count words.
+func mnemonicLength(count int) bool {
+	return count >= 12 && count <= 24 && count%3 == 0
+}
+
+// mnemonicSeedPhrase returns the mnemonic as hashed into the seed. BIP39
+// normalizes it with NFKD, which turns the ideographic spaces of Japanese
+// mnemonics into spaces; the words of the lists are already normalized.
+func mnemonicSeedPhrase(mnemonic string) string {
+	return strings.ReplaceAll(mnemonic, "\u3000", " ")
+}
+
+// readBits reads count bits of data, most significant first, from offset.
+func readBits(data []byte, offset, count int) int {
+	value := 0
+	for i := offset; i < offset+count; i++ {
+		value = value<<1 | int(data[i/8]>>(7-i%8)&1)
+	}
+	return value
+}
+
+// writeBits writes the count low bits of value to data, most significant
+// first, from offset.
+func writeBits(data []byte, offset, count, value int) {
+	for i := 0; i < count; i++ {
+		if value>>(count-1-i)&1 == 1 {
+			bit := offset + i
+			data[bit/8] |= 1 << (7 - bit%8)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package p2pkh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+func Test_mnemonicFromEntropy(t *testing.T) {
+	// BIP39 test vectors.
+	tests := []struct {
+		entropy  []byte
+		expected string
+	}{
+		{bytes.Repeat([]byte{0x00}, 16), "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"},
+		{bytes.Repeat([]byte{0x7f}, 16), "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+		{bytes.Repeat([]byte{0x80}, 24), "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always"},
+		{bytes.Repeat([]byte{0xff}, 32), strings.Repeat("zoo ", 23) + "vote"},
+	}
+	for _, test := range tests {
+		mnemonic, err := mnemonicFromEntropy(test.entropy, LanguageEnglish)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, mnemonic)
+		assert.NoError(t, ValidateMnemonic(mnemonic, LanguageEnglish))
+	}
+
+	_, err := mnemonicFromEntropy(make([]byte, 15), LanguageEnglish)
+	assert.ErrorIs(t, err, ErrMnemonicEntropy)
+	_, err = mnemonicFromEntropy(make([]byte, 16), Language("klingon"))
+	assert.ErrorIs(t, err, ErrMnemonicLanguage)
+}
+
+func Test_GenerateMnemonic(t *testing.T) {
+	for _, lang := range Languages() {
+		for bits := 128; bits <= 256; bits += 32 {
+			mnemonic, err := GenerateMnemonic(bits, lang)
+			assert.NoError(t, err)
+			assert.Len(t, strings.Fields(mnemonic), bits/32*3)
+			assert.NoError(t, ValidateMnemonic(mnemonic, lang))
+		}
+	}
+
+	for _, bits := range []int{0, 96, 136, 288} {
+		_, err := GenerateMnemonic(bits, LanguageEnglish)
+		assert.ErrorIs(t, err, ErrMnemonicEntropy)
+	}
+	_, err := GenerateMnemonic(128, Language("klingon"))
+	assert.ErrorIs(t, err, ErrMnemonicLanguage)
+}
+
+func Test_ValidateMnemonic(t *testing.T) {
+	assert.NoError(t, ValidateMnemonic(testMnemonic, LanguageEnglish))
+	assert.ErrorIs(t, ValidateMnemonic(testMnemonic, LanguageFrench), ErrMnemonicWord)
+	assert.ErrorIs(t, ValidateMnemonic(strings.Repeat("abandon ", 12), LanguageEnglish), ErrMnemonicChecksum)
+	assert.ErrorIs(t, ValidateMnemonic("abandon about", LanguageEnglish), ErrMnemonicLength)
+	assert.ErrorIs(t, ValidateMnemonic(testMnemonic, Language("klingon")), ErrMnemonicLanguage)
+}
+
+func Test_MnemonicLanguage(t *testing.T) {
+	lang, err := MnemonicLanguage(testMnemonic)
+	assert.NoError(t, err)
+	assert.Equal(t, LanguageEnglish, lang)
+
+	french, err := mnemonicFromEntropy(bytes.Repeat([]byte{0x7f}, 16), LanguageFrench)
+	assert.NoError(t, err)
+	lang, err = MnemonicLanguage(french)
+	assert.NoError(t, err)
+	assert.Equal(t, LanguageFrench, lang)
+
+	_, err = MnemonicLanguage(strings.Repeat("abandon ", 12))
+	assert.ErrorIs(t, err, ErrMnemonicNoLanguage)
+	_, err = MnemonicLanguage("abandon")
+	assert.ErrorIs(t, err, ErrMnemonicLength)
+}
+
+func Test_New_JapaneseMnemonic(t *testing.T) {
+	mnemonic, err := mnemonicFromEntropy(make([]byte, 16), LanguageJapanese)
+	assert.NoError(t, err)
+	words := strings.Split(mnemonic, "\u3000")
+	assert.Len(t, words, 12)
+	assert.Equal(t, wordlists.Japanese[0], words[0])
+
+	wallet, err := New(&Config{Mnemonic: mnemonic, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	spaced, err := New(&Config{Mnemonic: strings.Join(words, " "), Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, spaced.AddressHex(), wallet.AddressHex())
+}
+
+func Test_ParseMnemonic(t *testing.T) {
+	mnemonic, lang, err := ParseMnemonic("  Romance TRASH engine\tduring cliff verify\ntunnel memory vault chief fluid fox ")
+	assert.NoError(t, err)
+	assert.Equal(t, LanguageEnglish, lang)
+	assert.Equal(t, testMnemonic, mnemonic)
+
+	japanese, err := mnemonicFromEntropy(make([]byte, 16), LanguageJapanese)
+	assert.NoError(t, err)
+	mnemonic, lang, err = ParseMnemonic(strings.ReplaceAll(japanese, "　", " "))
+	assert.NoError(t, err)
+	assert.Equal(t, LanguageJapanese, lang)
+	assert.Equal(t, japanese, mnemonic)
+
+	_, _, err = ParseMnemonic(strings.Repeat("abandon ", 12))
+	assert.ErrorIs(t, err, ErrMnemonicChecksum)
+	_, _, err = ParseMnemonic(strings.Replace(testMnemonic, "fox", "fax", 1))
+	assert.ErrorIs(t, err, ErrMnemonicNoLanguage)
+	_, _, err = ParseMnemonic("")
+	assert.ErrorIs(t, err, ErrMnemonicLength)
+}
+
+func FuzzParseMnemonic(f *testing.F) {
+	f.Add(testMnemonic)
+	f.Add(strings.Repeat("abandon ", 11) + "about")
+	f.Add(strings.Repeat("zoo ", 24))
+	f.Add("")
+	f.Fuzz(func(t *testing.T, input string) {
+		mnemonic, lang, err := ParseMnemonic(input)
+		if err != nil {
+			return
+		}
+		assert.NoError(t, ValidateMnemonic(mnemonic, lang))
+		again, againLang, err := ParseMnemonic(mnemonic)
+		assert.NoError(t, err)
+		assert.Equal(t, mnemonic, again)
+		assert.Equal(t, lang, againLang)
+	})
+}
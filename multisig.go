@@ -0,0 +1,127 @@
+package p2pkh
+
+import (
+	"fmt"
+)
+
+const (
+	ErrMultisigThreshold   Error = "multisig threshold must be between 1 and the number of cosigners"
+	ErrMultisigCosigners   Error = "multisig wallets support between 1 and 15 cosigners"
+	ErrMultisigDuplicate   Error = "multisig cosigners must be distinct"
+	ErrMultisigXPubNetwork Error = "cosigner xpub does not belong to the wallet network"
+	ErrMultisigPrivateKey  Error = "cosigner key must be an extended public key"
+	ErrMultisigXPub        Error = "invalid cosigner xpub"
+
+	maxMultisigCosigners = 15
+)
+
+// Cosigner is a participant of a multisig wallet, identified by the extended
+// public key it contributes and the BIP32 origin of that key.
+type Cosigner struct {
+	// Fingerprint is the fingerprint of the cosigner master key.
+	Fingerprint uint32
+	// Path is the derivation path of XPub from the master key, e.g. "m/45'".
+	Path string
+	// XPub is the extended public key of the cosigner.
+	XPub string
+}
+
+// Cosigner returns the wallet as a multisig cosigner: its deepest hardened
+// extended public key and its origin.
+func (s *Wallet) Cosigner() (*Cosigner, error) {
+	origin, err := s.keyOrigin()
+	if err != nil {
+		return nil, err
+	}
+	xpub, err := origin.key.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	return &Cosigner{
+		Fingerprint: origin.fingerprint,
+		Path:        "m" + formatPathLevels(origin.path),
+		XPub:        xpub.String(),
+	}, nil
+}
+
+// MultisigWallet is an M-of-N multisig wallet coordinated between cosigners.
+type MultisigWallet struct {
+	name      string
+	required  int
+	cosigners []Cosigner
+	addrType  AddressType
+	network   Network
+}
+
+// NewMultisigWallet creates a multisig wallet requiring `required` signatures
+// among the cosigners. The address type is P2SH or P2WSH. Cosigner SLIP-132
+// keys (Zpub...) must match the address type and are stored as xpubs.
+func NewMultisigWallet(name string, required int, cosigners []Cosigner, addrType AddressType, network Network) (*MultisigWallet, error) {
+	if len(cosigners) == 0 || len(cosigners) > maxMultisigCosigners {
+		return nil, ErrMultisigCosigners
+	}
+	if required < 1 || required > len(cosigners) {
+		return nil, ErrMultisigThreshold
+	}
+	if addrType != AddressTypeP2SH && addrType != AddressTypeP2WSH {
+		return nil, ErrUnsupportedAddressType
+	}
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make([]Cosigner, 0, len(cosigners))
+	seen := make(map[string]bool, len(cosigners))
+	for _, cosigner := range cosigners {
+		key, _, err := parseExtendedKey(cosigner.XPub, addrType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMultisigXPub, err)
+		}
+		if key.IsPrivate() {
+			return nil, ErrMultisigPrivateKey
+		}
+		if !key.IsForNet(params) {
+			return nil, ErrMultisigXPubNetwork
+		}
+		cosigner.XPub = key.String()
+		if seen[cosigner.XPub] {
+			return nil, ErrMultisigDuplicate
+		}
+		seen[cosigner.XPub] = true
+		normalized = append(normalized, cosigner)
+	}
+
+	return &MultisigWallet{
+		name:      name,
+		required:  required,
+		cosigners: normalized,
+		addrType:  addrType,
+		network:   network,
+	}, nil
+}
+
+// Name returns the name of the multisig wallet.
+func (s *MultisigWallet) Name() string {
+	return s.name
+}
+
+// Required returns the number of signatures required to spend (M).
+func (s *MultisigWallet) Required() int {
+	return s.required
+}
+
+// Cosigners returns the cosigners of the wallet (N of them).
+func (s *MultisigWallet) Cosigners() []Cosigner {
+	return append([]Cosigner(nil), s.cosigners...)
+}
+
+// AddressType returns the script type of the wallet addresses.
+func (s *MultisigWallet) AddressType() AddressType {
+	return s.addrType
+}
+
+// Network returns the network of the wallet.
+func (s *MultisigWallet) Network() Network {
+	return s.network
+}
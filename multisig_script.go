@@ -0,0 +1,207 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrMultisigNetwork    Error = "multisig keys must belong to the same network"
+	ErrMultisigSignatures Error = "not enough cosigners to sign the multisig input"
+	ErrMultisigPublicKey  Error = "invalid multisig public key"
+
+	// multisigSignatureSize is the size of a DER signature with its sighash
+	// flag and push opcode, at most.
+	multisigSignatureSize = 1 + 72
+	// txInBaseSize is the size of an input without its signature script:
+	// outpoint and sequence.
+	txInBaseSize = 32 + 4 + 4
+)
+
+// Multisig is an M-of-N multisig script paid through a P2SH address. Public
+// keys are sorted as BIP67 prescribes, so that the address does not depend
+// on the order in which they are given.
+type Multisig struct {
+	required     int
+	publicKeys   []*btcec.PublicKey
+	redeemScript []byte
+	address      *btcutil.AddressScriptHash
+	network      Network
+}
+
+// NewMultisig creates the multisig script requiring `required` signatures of
+// the public keys.
+func NewMultisig(required int, publicKeys []*btcec.PublicKey, network Network) (*Multisig, error) {
+	if len(publicKeys) == 0 || len(publicKeys) > maxMultisigCosigners {
+		return nil, ErrMultisigCosigners
+	}
+	if required < 1 || required > len(publicKeys) {
+		return nil, ErrMultisigThreshold
+	}
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]*btcec.PublicKey(nil), publicKeys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].SerializeCompressed(), sorted[j].SerializeCompressed()) < 0
+	})
+	keys := make([]*btcutil.AddressPubKey, 0, len(sorted))
+	for i, publicKey := range sorted {
+		if i > 0 && sorted[i-1].IsEqual(publicKey) {
+			return nil, ErrMultisigDuplicate
+		}
+		key, err := btcutil.NewAddressPubKey(publicKey.SerializeCompressed(), params)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	redeemScript, err := txscript.MultiSigScript(keys, required)
+	if err != nil {
+		return nil, err
+	}
+	address, err := btcutil.NewAddressScriptHash(redeemScript, params)
+	if err != nil {
+		return nil, err
+	}
+	return &Multisig{
+		required:     required,
+		publicKeys:   sorted,
+		redeemScript: redeemScript,
+		address:      address,
+		network:      network,
+	}, nil
+}
+
+// NewMultisigFromHex creates a multisig script from hex encoded public keys,
+// compressed or not.
+func NewMultisigFromHex(required int, publicKeys []string, network Network) (*Multisig, error) {
+	keys := make([]*btcec.PublicKey, 0, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		data, err := hex.DecodeString(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMultisigPublicKey, err)
+		}
+		key, err := btcec.ParsePubKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMultisigPublicKey, err)
+		}
+		keys = append(keys, key)
+	}
+	return NewMultisig(required, keys, network)
+}
+
+// NewMultisigFromWallets creates a multisig script from the keys of wallets,
+// e.g. the wallets of three separate mnemonics at the same path.
+func NewMultisigFromWallets(required int, wallets ...*Wallet) (*Multisig, error) {
+	if len(wallets) == 0 {
+		return nil, ErrMultisigCosigners
+	}
+	keys := make([]*btcec.PublicKey, 0, len(wallets))
+	for _, wallet := range wallets {
+		if wallet.network != wallets[0].network {
+			return nil, ErrMultisigNetwork
+		}
+		keys = append(keys, wallet.publicKey)
+	}
+	return NewMultisig(required, keys, wallets[0].network)
+}
+
+// Required returns the number of signatures required to spend (M).
+func (s *Multisig) Required() int {
+	return s.required
+}
+
+// PublicKeys returns the public keys of the script (N of them), sorted.
+func (s *Multisig) PublicKeys() []*btcec.PublicKey {
+	return append([]*btcec.PublicKey(nil), s.publicKeys...)
+}
+
+// RedeemScript returns the multisig script whose hash the address pays to.
+func (s *Multisig) RedeemScript() []byte {
+	return append([]byte(nil), s.redeemScript...)
+}
+
+// Address returns the P2SH address of the script.
+func (s *Multisig) Address() string {
+	return s.address.EncodeAddress()
+}
+
+// PkScript returns the scriptPubKey paying to the address.
+func (s *Multisig) PkScript() []byte {
+	script, _ := txscript.PayToAddrScript(s.address)
+	return script
+}
+
+// Network returns the network of the address.
+func (s *Multisig) Network() Network {
+	return s.network
+}
+
+// inputWeight returns the weight of an input spending the script with the
+// required signatures.
+func (s *Multisig) inputWeight() int64 {
+	redeemLen := int64(len(s.redeemScript))
+	pushLen := int64(1)
+	switch {
+	case redeemLen > 0xff:
+		pushLen = 3
+	case redeemLen > txscript.OP_DATA_75:
+		pushLen = 2
+	}
+	// OP_0 works around the extra item popped by OP_CHECKMULTISIG.
+	sigScriptLen := 1 + int64(s.required)*multisigSignatureSize + pushLen + redeemLen
+	size := txInBaseSize + int64(wire.VarIntSerializeSize(uint64(sigScriptLen))) + sigScriptLen
+	return size * 4
+}
+
+// signInput signs the input i of tx, spending the script, with the first
+// required wallets holding one of its keys, in the order of the keys.
+func (s *Multisig) signInput(tx *wire.MsgTx, i int, pkScript []byte, signers []*Wallet) error {
+	if !bytes.Equal(pkScript, s.PkScript()) {
+		return ErrInputScriptMismatch
+	}
+	builder := txscript.NewScriptBuilder().AddOp(txscript.OP_0)
+	signatures := 0
+	for _, publicKey := range s.publicKeys {
+		if signatures == s.required {
+			break
+		}
+		for _, signer := range signers {
+			if signer.IsWatchOnly() || !signer.publicKey.IsEqual(publicKey) {
+				continue
+			}
+			privateKey, err := signer.extendedKey.ECPrivKey()
+			if err != nil {
+				return err
+			}
+			signature, err := signer.rawTxInSignature(tx, i, s.redeemScript, txscript.SigHashAll, privateKey)
+			if err != nil {
+				return err
+			}
+			builder.AddData(signature)
+			signer.logSign("multisig_input", "", inputAttrs(tx, i)...)
+			signatures++
+			break
+		}
+	}
+	if signatures < s.required {
+		return ErrMultisigSignatures
+	}
+	sigScript, err := builder.AddData(s.redeemScript).Script()
+	if err != nil {
+		return err
+	}
+	tx.TxIn[i].SignatureScript = sigScript
+	return nil
+}
@@ -0,0 +1,101 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewMultisig(t *testing.T) {
+	// Test vector of BIP67: the address does not depend on the key order.
+	keys := []string{
+		"02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8",
+		"02fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f",
+	}
+	multisig, err := NewMultisigFromHex(2, keys, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, "39bgKC7RFbpoCRbtD5KEdkYKtNyhpsNa3Z", multisig.Address())
+	assert.Equal(t, "522102fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f2102ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f852ae",
+		hex.EncodeToString(multisig.RedeemScript()))
+	assert.Equal(t, 2, multisig.Required())
+	assert.Len(t, multisig.PublicKeys(), 2)
+
+	reversed, err := NewMultisigFromHex(2, []string{keys[1], keys[0]}, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, multisig.Address(), reversed.Address())
+
+	tests := []struct {
+		name     string
+		required int
+		keys     []string
+		expected error
+	}{
+		{"No keys", 1, nil, ErrMultisigCosigners},
+		{"Threshold too high", 3, keys, ErrMultisigThreshold},
+		{"Threshold zero", 0, keys, ErrMultisigThreshold},
+		{"Duplicate", 1, []string{keys[0], keys[0]}, ErrMultisigDuplicate},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewMultisigFromHex(test.required, test.keys, NetworkMainnet)
+			assert.ErrorIs(t, err, test.expected)
+		})
+	}
+
+	_, err = NewMultisigFromHex(1, []string{"02ff"}, NetworkMainnet)
+	assert.Error(t, err)
+	_, err = NewMultisigFromWallets(1, createKnownWallet(t, NetworkMainnet), createKnownWallet(t, NetworkTestnet))
+	assert.ErrorIs(t, err, ErrMultisigNetwork)
+}
+
+func Test_TxBuilder_Multisig(t *testing.T) {
+	path := `m/45'/0/0`
+	wallets := []*Wallet{
+		createTestWallet(t, NetworkMainnet, path),
+		createTestWallet(t, NetworkMainnet, path),
+		createTestWallet(t, NetworkMainnet, path),
+	}
+	multisig, err := NewMultisigFromWallets(2, wallets...)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", multisig.Address()[:1])
+
+	payer := createKnownWallet(t, NetworkMainnet)
+	utxos := []UTXO{
+		{TxID: testTxID, Vout: 0, Amount: 100000, PkScript: multisig.PkScript()},
+		walletUTXO(t, wallets[0], 1, 20000),
+	}
+
+	// Two of the three cosigners sign, the first one building the transaction.
+	rawTx, err := wallets[0].NewTransaction().
+		AddMultisigInput(utxos[0], multisig).
+		AddInput(utxos[1]).
+		AddOutput(payer.AddressHex(), 50000).
+		WithChangeAddress(multisig.Address()).
+		WithFeeRate(10).
+		SignMultisig(wallets[2])
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+
+	// The change pays the multisig input at its real size.
+	draft, err := wallets[0].NewTransaction().
+		AddMultisigInput(utxos[0], multisig).
+		AddOutput(payer.AddressHex(), 50000).
+		WithChangeAddress(multisig.Address()).
+		WithFeeRate(10).
+		Draft()
+	assert.NoError(t, err)
+	assert.Greater(t, int64(draft.Fee), int64(2500))
+
+	_, err = wallets[0].NewTransaction().
+		AddMultisigInput(utxos[0], multisig).
+		AddOutput(payer.AddressHex(), 50000).
+		Sign()
+	assert.ErrorIs(t, err, ErrMultisigSignatures)
+
+	_, err = wallets[0].NewTransaction().
+		AddMultisigInput(utxos[1], multisig).
+		AddOutput(payer.AddressHex(), 10000).
+		SignMultisig(wallets[1])
+	assert.ErrorIs(t, err, ErrInputScriptMismatch)
+}
@@ -0,0 +1,77 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestCosigners returns n cosigners built from fresh mnemonics.
+func createTestCosigners(t *testing.T, n int, network Network, path string) []Cosigner {
+	cosigners := make([]Cosigner, 0, n)
+	for i := 0; i < n; i++ {
+		cosigner, err := createTestWallet(t, network, path).Cosigner()
+		assert.NoError(t, err)
+		cosigners = append(cosigners, *cosigner)
+	}
+	return cosigners
+}
+
+func Test_Wallet_Cosigner(t *testing.T) {
+	wallet := createTestWallet(t, NetworkMainnet, `m/45'`)
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+
+	cosigner, err := wallet.Cosigner()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/45'`, cosigner.Path)
+	assert.Equal(t, xpub, cosigner.XPub)
+	assert.NotZero(t, cosigner.Fingerprint)
+}
+
+func Test_NewMultisigWallet(t *testing.T) {
+	cosigners := createTestCosigners(t, 3, NetworkMainnet, `m/45'`)
+
+	wallet, err := NewMultisigWallet("Treasury", 2, cosigners, AddressTypeP2SH, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, "Treasury", wallet.Name())
+	assert.Equal(t, 2, wallet.Required())
+	assert.Equal(t, cosigners, wallet.Cosigners())
+	assert.Equal(t, AddressTypeP2SH, wallet.AddressType())
+	assert.Equal(t, NetworkMainnet, wallet.Network())
+}
+
+func Test_NewMultisigWallet_Errors(t *testing.T) {
+	cosigners := createTestCosigners(t, 2, NetworkMainnet, `m/45'`)
+
+	tests := []struct {
+		name      string
+		required  int
+		cosigners []Cosigner
+		addrType  AddressType
+		network   Network
+		expected  error
+	}{
+		{"No cosigners", 1, nil, AddressTypeP2SH, NetworkMainnet, ErrMultisigCosigners},
+		{"Threshold too high", 3, cosigners, AddressTypeP2SH, NetworkMainnet, ErrMultisigThreshold},
+		{"Threshold zero", 0, cosigners, AddressTypeP2SH, NetworkMainnet, ErrMultisigThreshold},
+		{"Duplicate", 1, []Cosigner{cosigners[0], cosigners[0]}, AddressTypeP2SH, NetworkMainnet, ErrMultisigDuplicate},
+		{"Wrong network", 1, cosigners, AddressTypeP2SH, NetworkTestnet, ErrMultisigXPubNetwork},
+		{"Unsupported type", 1, cosigners, AddressTypeP2PKH, NetworkMainnet, ErrUnsupportedAddressType},
+		{"Invalid network", 1, cosigners, AddressTypeP2SH, Network("invalid"), ErrUnsupportedNet},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewMultisigWallet("Vault", test.required, test.cosigners, test.addrType, test.network)
+			assert.ErrorIs(t, err, test.expected)
+		})
+	}
+
+	xprv := createTestWallet(t, NetworkMainnet, `m/45'`).extendedKey.String()
+	_, err := NewMultisigWallet("Vault", 1, []Cosigner{{XPub: xprv}}, AddressTypeP2SH, NetworkMainnet)
+	assert.ErrorIs(t, err, ErrMultisigPrivateKey)
+
+	_, err = NewMultisigWallet("Vault", 1, []Cosigner{{XPub: "xpub-invalid"}}, AddressTypeP2SH, NetworkMainnet)
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package p2pkh
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ErrNetworkName   Error = "network name is required"
+	ErrNetworkExists Error = "network is already registered"
+	ErrNetworkParams Error = "network parameters are required"
+)
+
+// networkInfo are the parameters of a network and its BIP44 coin type.
+type networkInfo struct {
+	params   *chaincfg.Params
+	coinType uint32
+}
+
+var (
+	networksMu sync.RWMutex
+	networks   = map[Network]networkInfo{
+		NetworkMainnet:  {params: &chaincfg.MainNetParams, coinType: 0},
+		NetworkTestnet:  {params: &chaincfg.TestNet3Params, coinType: 1},
+		NetworkTestnet4: {params: &testNet4Params, coinType: 1},
+	}
+	// networkNames are the supported networks in the order of registration.
+	networkNames = []Network{NetworkMainnet, NetworkTestnet, NetworkTestnet4}
+)
+
+// RegisterNetwork makes the network of another coin available under a name,
+// e.g. Litecoin or Dogecoin, so that wallets derive its addresses: params
+// hold its address version bytes, WIF prefix, bech32 prefix and extended key
+// versions, and coinType is its SLIP44 coin type, used by the default paths
+// such as m/44'/2'/0'/0 for Litecoin. Its parameters are also registered
+// with chaincfg, which the decoding of its addresses and keys requires.
+func RegisterNetwork(name string, params *chaincfg.Params, coinType uint32) error {
+	if name == "" {
+		return ErrNetworkName
+	}
+	if params == nil {
+		return ErrNetworkParams
+	}
+	if coinType >= hdkeychain.HardenedKeyStart {
+		return ErrIndexRange
+	}
+
+	networksMu.Lock()
+	defer networksMu.Unlock()
+
+	if _, ok := networks[Network(name)]; ok {
+		return ErrNetworkExists
+	}
+	// Networks sharing their magic with a registered one, such as the
+	// regression test network of btcd, are already known to chaincfg.
+	if err := chaincfg.Register(params); err != nil && !errors.Is(err, chaincfg.ErrDuplicateNet) {
+		return err
+	}
+	networks[Network(name)] = networkInfo{params: params, coinType: coinType}
+	networkNames = append(networkNames, Network(name))
+	return nil
+}
+
+// Networks returns the names of the supported networks, mainnet and the
+// testnets first, then the registered ones in the order of registration.
+func Networks() []Network {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	return append([]Network(nil), networkNames...)
+}
+
+// CoinType returns the SLIP44 coin type of the network, 0 for bitcoin and 1
+// for every test network.
+func (s Network) CoinType() (uint32, error) {
+	info, err := lookupNetwork(s)
+	if err != nil {
+		return 0, err
+	}
+	return info.coinType, nil
+}
+
+// Params returns the chain parameters of the network, e.g. to decode its
+// addresses with btcutil.
+func (s Network) Params() (*chaincfg.Params, error) {
+	info, err := lookupNetwork(s)
+	if err != nil {
+		return nil, err
+	}
+	return info.params, nil
+}
+
+// lookupNetwork returns the parameters of a supported network.
+func lookupNetwork(network Network) (networkInfo, error) {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+
+	info, ok := networks[network]
+	if !ok {
+		return networkInfo{}, ErrUnsupportedNet
+	}
+	return info, nil
+}
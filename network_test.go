@@ -0,0 +1,98 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// registerTestNetwork registers a network for the duration of a test.
+func registerTestNetwork(t *testing.T, name string, params *chaincfg.Params, coinType uint32) {
+	assert.NoError(t, RegisterNetwork(name, params, coinType))
+	t.Cleanup(func() {
+		networksMu.Lock()
+		defer networksMu.Unlock()
+		delete(networks, Network(name))
+		for i, network := range networkNames {
+			if network == Network(name) {
+				networkNames = append(networkNames[:i:i], networkNames[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
+func Test_RegisterNetwork(t *testing.T) {
+	litecoin := chaincfg.MainNetParams
+	litecoin.Name = "litecoin"
+	litecoin.Net = wire.BitcoinNet(0xdbb6c0fb)
+	litecoin.PubKeyHashAddrID = 0x30
+	litecoin.ScriptHashAddrID = 0x32
+	litecoin.PrivateKeyID = 0xb0
+	litecoin.Bech32HRPSegwit = "ltc"
+	registerTestNetwork(t, "litecoin", &litecoin, 2)
+
+	dogecoin := chaincfg.MainNetParams
+	dogecoin.Name = "dogecoin"
+	dogecoin.Net = wire.BitcoinNet(0xc0c0c0c0)
+	dogecoin.PubKeyHashAddrID = 0x1e
+	dogecoin.ScriptHashAddrID = 0x16
+	dogecoin.PrivateKeyID = 0x9e
+	dogecoin.HDPrivateKeyID = [4]byte{0x02, 0xfa, 0xc3, 0x98}
+	dogecoin.HDPublicKeyID = [4]byte{0x02, 0xfa, 0xca, 0xfd}
+	registerTestNetwork(t, "dogecoin", &dogecoin, 3)
+
+	assert.Equal(t, []Network{NetworkMainnet, NetworkTestnet, NetworkTestnet4, "litecoin", "dogecoin"}, Networks())
+	coinType, err := Network("litecoin").CoinType()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), coinType)
+
+	// The addresses of the "abandon ... about" mnemonic at the default paths.
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	tests := []struct {
+		network  Network
+		path     string
+		expected string
+	}{
+		{"litecoin", `m/44'/2'/0'/0/0`, "LUWPbpM43E2p7ZSh8cyTBEkvpHmr3cB8Ez"},
+		{"dogecoin", `m/44'/3'/0'/0/0`, "DBus3bamQjgJULBJtYXpEzDWQRwF5iwxgC"},
+	}
+	for _, test := range tests {
+		wallet, err := New(&Config{Mnemonic: mnemonic, Network: test.network})
+		assert.NoError(t, err)
+		child, err := wallet.Derive(0)
+		assert.NoError(t, err)
+		assert.Equal(t, test.path, child.Path())
+		assert.Equal(t, test.expected, child.AddressHex())
+		valid, err := child.ValidateAddress(test.expected)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		_, err = child.PrivateKey()
+		assert.NoError(t, err)
+	}
+
+	assert.ErrorIs(t, RegisterNetwork("litecoin", &litecoin, 2), ErrNetworkExists)
+	assert.ErrorIs(t, RegisterNetwork(string(NetworkMainnet), &chaincfg.MainNetParams, 0), ErrNetworkExists)
+	assert.ErrorIs(t, RegisterNetwork("", &litecoin, 2), ErrNetworkName)
+	assert.ErrorIs(t, RegisterNetwork("nothing", nil, 2), ErrNetworkParams)
+	assert.ErrorIs(t, RegisterNetwork("nothing", &litecoin, 1<<31), ErrIndexRange)
+
+	// Networks sharing their magic with a known one can be registered.
+	registerTestNetwork(t, "regtest", &chaincfg.RegressionNetParams, 1)
+}
+
+func Test_Network_CoinType(t *testing.T) {
+	coinType, err := NetworkMainnet.CoinType()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), coinType)
+	coinType, err = NetworkTestnet.CoinType()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), coinType)
+	coinType, err = NetworkTestnet4.CoinType()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), coinType)
+	_, err = Network("unknown").CoinType()
+	assert.ErrorIs(t, err, ErrUnsupportedNet)
+}
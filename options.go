@@ -0,0 +1,156 @@
+package p2pkh
+
+import (
+	"log/slog"
+)
+
+const ErrOptionPassphrase Error = "passphrase applies to mnemonic wallets only"
+
+// Option configures the wallet created by NewWallet or NewWalletFromSeed,
+// e.g. NewWallet(mnemonic, WithNetwork(NetworkTestnet), WithPassphrase(p)).
+type Option func(*Config) error
+
+// NewWallet creates a wallet from a BIP39 mnemonic and options, as New does
+// from a Config.
+func NewWallet(mnemonic string, opts ...Option) (*Wallet, error) {
+	config, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	config.Mnemonic = mnemonic
+	return New(config)
+}
+
+// NewWalletFromSeed creates a wallet from a raw BIP32 seed and options, as
+// NewFromSeed does. The seed has no mnemonic, hence no passphrase.
+func NewWalletFromSeed(seed []byte, opts ...Option) (*Wallet, error) {
+	config, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if config.Passphrase != "" {
+		return nil, ErrOptionPassphrase
+	}
+	return NewFromSeed(seed, config)
+}
+
+// applyOptions returns the configuration set by options, on mainnet unless
+// they say otherwise.
+func applyOptions(opts []Option) (*Config, error) {
+	config := &Config{Network: NetworkMainnet}
+	for _, opt := range opts {
+		if err := opt(config); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// WithNetwork sets the network of the wallet, mainnet by default.
+func WithNetwork(network Network) Option {
+	return func(config *Config) error {
+		config.Network = network
+		return nil
+	}
+}
+
+// WithPath sets the derivation path of the wallet, the default path of its
+// network and purpose by default.
+func WithPath(path string) Option {
+	return func(config *Config) error {
+		config.Path = path
+		return nil
+	}
+}
+
+// WithCompletePath completes a path stopping at the account level to its
+// external chain.
+func WithCompletePath() Option {
+	return func(config *Config) error {
+		config.CompletePath = true
+		return nil
+	}
+}
+
+// WithPassphrase sets the BIP39 passphrase of the mnemonic.
+func WithPassphrase(passphrase string) Option {
+	return func(config *Config) error {
+		config.Passphrase = passphrase
+		return nil
+	}
+}
+
+// WithPurpose sets the purpose of the wallet, which selects its default path
+// and the type of its PurposeAddress.
+func WithPurpose(purpose Purpose) Option {
+	return func(config *Config) error {
+		config.Purpose = purpose
+		return nil
+	}
+}
+
+// WithAddressType sets the purpose of the wallet from the type of its
+// addresses, e.g. AddressTypeP2WPKH for BIP84.
+func WithAddressType(addrType AddressType) Option {
+	return func(config *Config) error {
+		purpose, ok := purposes[addrType]
+		if !ok {
+			return ErrUnsupportedAddressType
+		}
+		config.Purpose = Purpose(purpose)
+		return nil
+	}
+}
+
+// WithBackend connects the wallet to the network through a chain backend.
+func WithBackend(backend ChainBackend) Option {
+	return func(config *Config) error {
+		config.Backend = backend
+		return nil
+	}
+}
+
+// WithLogger sends the events of the wallet, such as its signatures, to a
+// logger, with secrets redacted.
+func WithLogger(logger *slog.Logger) Option {
+	return func(config *Config) error {
+		config.Logger = logger
+		return nil
+	}
+}
+
+// WithLowRSignatures grinds the signatures of the wallet to a low R value,
+// saving a byte in half of them.
+func WithLowRSignatures() Option {
+	return func(config *Config) error {
+		config.LowRSignatures = true
+		return nil
+	}
+}
+
+// WithoutMnemonicRetention creates a wallet that does not retain its
+// mnemonic and passphrase once its keys are derived.
+func WithoutMnemonicRetention() Option {
+	return func(config *Config) error {
+		config.ForgetMnemonic = true
+		return nil
+	}
+}
+
+// WithUncompressedKeys derives the P2PKH addresses of the wallet from its
+// uncompressed public keys, e.g. to sweep the addresses of a very old wallet.
+func WithUncompressedKeys() Option {
+	return func(config *Config) error {
+		config.UncompressedKeys = true
+		return nil
+	}
+}
+
+// WithWipeOnCollect zeroes the private extended keys of the wallet once the
+// garbage collector reclaims them.
+func WithWipeOnCollect() Option {
+	return func(config *Config) error {
+		config.WipeOnCollect = true
+		return nil
+	}
+}
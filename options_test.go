@@ -0,0 +1,139 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewWallet(t *testing.T) {
+	wallet, err := NewWallet(testMnemonic)
+	assert.NoError(t, err)
+	known := createKnownWallet(t, NetworkMainnet)
+	assert.Equal(t, known.AddressHex(), wallet.AddressHex())
+	assert.Equal(t, testMnemonic, wallet.Mnemonic())
+
+	wallet, err = NewWallet(testMnemonic,
+		WithNetwork(NetworkTestnet),
+		WithAddressType(AddressTypeP2WPKH),
+		WithoutMnemonicRetention())
+	assert.NoError(t, err)
+	assert.Equal(t, `m/84'/1'/0'/0`, wallet.Path())
+	assert.Equal(t, AddressTypeP2WPKH, wallet.AddressType())
+	assert.Empty(t, wallet.Mnemonic())
+
+	wallet, err = NewWallet(testMnemonic, WithPath(`m/44'/0'/1'`), WithCompletePath())
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/1'/0`, wallet.Path())
+
+	_, err = NewWallet(testMnemonic, WithAddressType("p2unknown"))
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+	_, err = NewWallet("not a mnemonic")
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+}
+
+func Test_NewWallet_Passphrase(t *testing.T) {
+	// The seed of the BIP39 test mnemonic with the passphrase TREZOR.
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	seed, err := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	assert.NoError(t, err)
+
+	wallet, err := NewWallet(mnemonic, WithPassphrase("TREZOR"))
+	assert.NoError(t, err)
+	fromSeed, err := NewWalletFromSeed(seed)
+	assert.NoError(t, err)
+	assert.Equal(t, fromSeed.AddressHex(), wallet.AddressHex())
+	plain, err := NewWallet(mnemonic)
+	assert.NoError(t, err)
+	assert.NotEqual(t, plain.AddressHex(), wallet.AddressHex())
+
+	// Derived and rebuilt wallets keep the passphrase.
+	rebuilt, err := wallet.Builder().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), rebuilt.AddressHex())
+	account, err := wallet.AccountAt(1)
+	assert.NoError(t, err)
+	receive, err := account.Receive(0)
+	assert.NoError(t, err)
+	rebuilt, err = receive.Builder().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, receive.AddressHex(), rebuilt.AddressHex())
+
+	// The keystore of a passphrase protected wallet holds its root key.
+	data, err := wallet.ExportKeystore("password")
+	assert.NoError(t, err)
+	restored, err := ImportKeystore(data, "password")
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), restored.AddressHex())
+
+	_, err = NewWalletFromSeed(seed, WithPassphrase("TREZOR"))
+	assert.ErrorIs(t, err, ErrOptionPassphrase)
+}
+
+func Test_WithUncompressedKeys(t *testing.T) {
+	known := createKnownWallet(t, NetworkMainnet)
+	wallet, err := NewWallet(testMnemonic, WithUncompressedKeys())
+	assert.NoError(t, err)
+	expected, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wallet.PublicKey().SerializeUncompressed()), &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.EncodeAddress(), wallet.AddressInfo().String())
+	assert.NotEqual(t, known.AddressInfo().String(), wallet.AddressInfo().String())
+
+	// The WIF is flagged uncompressed, so that it imports as the address.
+	wif, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+	assert.NoError(t, wallet.ValidateWIF(wif))
+	decoded, err := btcutil.DecodeWIF(wif)
+	assert.NoError(t, err)
+	assert.False(t, decoded.CompressPubKey)
+
+	// Derived wallets and variants keep the uncompressed keys.
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	knownChild, err := known.Derive(3)
+	assert.NoError(t, err)
+	assert.NotEqual(t, knownChild.AddressInfo().String(), child.AddressInfo().String())
+	change, err := wallet.DeriveChange(0)
+	assert.NoError(t, err)
+	assert.True(t, change.uncompressed)
+	rebuilt, err := wallet.Builder().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressInfo().String(), rebuilt.AddressInfo().String())
+
+	signature, err := wallet.SignMessage("hello")
+	assert.NoError(t, err)
+	ok, err := VerifyMessage(wallet.AddressInfo().String(), signature, "hello")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Transactions spend the outputs of the uncompressed address, with fees
+	// estimated for the larger key.
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 60000), walletUTXO(t, child, 1, 40000)}
+	rawTx, err := wallet.NewTransaction().
+		AddInput(utxos[0]).
+		AddInputAt(utxos[1], child.Path()).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 90000).
+		WithFeeRate(1).
+		Sign()
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+	vsize, err := wallet.NewTransaction().AddInput(utxos[0]).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 50000).VSize()
+	assert.NoError(t, err)
+	knownVSize, err := known.NewTransaction().AddInput(walletUTXO(t, known, 0, 60000)).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 50000).VSize()
+	assert.NoError(t, err)
+	assert.Equal(t, knownVSize+32, vsize)
+
+	script, err := wallet.PkScript()
+	assert.NoError(t, err)
+	assert.Equal(t, utxos[0].PkScript, script)
+
+	data, err := json.Marshal(wallet)
+	assert.NoError(t, err)
+	var restored Wallet
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, wallet.AddressInfo().String(), restored.AddressInfo().String())
+}
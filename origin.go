@@ -0,0 +1,53 @@
+package p2pkh
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// KeyOrigin is the BIP32 origin of a key, as carried by PSBTs and
+// descriptors: the fingerprint of the master key and the derivation path
+// from it.
+type KeyOrigin struct {
+	Fingerprint [4]byte
+	Path        []uint32
+}
+
+// String formats the origin as in descriptors, e.g. "d34db33f/44'/0'/0'/0/7".
+func (s KeyOrigin) String() string {
+	return fmt.Sprintf("%x", s.Fingerprint) + formatPathLevels(s.Path)
+}
+
+// MasterFingerprint returns the fingerprint of the master key of the wallet,
+// the first 4 bytes of the HASH160 of its public key. Wallets rooted at an
+// extended key without known origin, e.g. those of NewFromExtendedPublicKey,
+// return the fingerprint of that key.
+func (s *Wallet) MasterFingerprint() [4]byte {
+	var fingerprint [4]byte
+	if value, err := s.masterFingerprint(); err == nil {
+		binary.BigEndian.PutUint32(fingerprint[:], value)
+	}
+	return fingerprint
+}
+
+// Depth returns the depth of the wallet key in the BIP32 tree, 0 for the
+// master key.
+func (s *Wallet) Depth() uint8 {
+	return s.extendedKey.Depth()
+}
+
+// ParentFingerprint returns the fingerprint of the parent of the wallet key,
+// zero for the master key.
+func (s *Wallet) ParentFingerprint() [4]byte {
+	var fingerprint [4]byte
+	binary.BigEndian.PutUint32(fingerprint[:], s.extendedKey.ParentFingerprint())
+	return fingerprint
+}
+
+// KeyOrigin returns the BIP32 origin of the wallet key: the master
+// fingerprint and the full path of the wallet, e.g. for the key origin
+// fields of the PSBTs built by hardware wallet coordinators.
+func (s *Wallet) KeyOrigin() KeyOrigin {
+	path := append([]uint32(nil), s.levels...)
+	return KeyOrigin{Fingerprint: s.MasterFingerprint(), Path: path}
+}
@@ -0,0 +1,47 @@
+package p2pkh
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wallet_KeyOrigin(t *testing.T) {
+	// BIP84 test vector of the "abandon ... about" mnemonic, whose master
+	// fingerprint is 73c5da0a.
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	wallet, err := New(&Config{Mnemonic: mnemonic, Path: `m/84'/0'/0'/0/5`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]byte{0x73, 0xc5, 0xda, 0x0a}, wallet.MasterFingerprint())
+	assert.Equal(t, uint8(5), wallet.Depth())
+
+	origin := wallet.KeyOrigin()
+	assert.Equal(t, "73c5da0a/84'/0'/0'/0/5", origin.String())
+	assert.Len(t, origin.Path, 5)
+
+	parent, err := New(&Config{Mnemonic: mnemonic, Path: `m/84'/0'/0'/0`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	parentFingerprint, err := keyFingerprint(parent.extendedKey)
+	assert.NoError(t, err)
+	fingerprint := wallet.ParentFingerprint()
+	assert.Equal(t, fmt.Sprintf("%08x", parentFingerprint), fmt.Sprintf("%x", fingerprint))
+
+	// The origin of a descriptor wallet is kept.
+	desc, err := parent.DescriptorOfType(AddressTypeP2WPKH)
+	assert.NoError(t, err)
+	account, err := NewAccount(parent)
+	assert.NoError(t, err)
+	xpub, err := account.XPub()
+	assert.NoError(t, err)
+	assert.Contains(t, desc, "[73c5da0a/84'/0'/0']"+xpub)
+
+	// Keys without origin are their own master.
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(3), watch.Depth())
+	accountFingerprint, err := keyFingerprint(account.Wallet().extendedKey)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%08x", accountFingerprint), watch.KeyOrigin().String())
+	assert.Empty(t, watch.KeyOrigin().Path)
+}
@@ -0,0 +1,258 @@
+package p2pkh
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ErrOwnershipEmpty   Error = "ownership proof has no entries"
+	ErrOwnershipFormat  Error = "unsupported ownership proof format"
+	ErrOwnershipNetwork Error = "ownership proof network does not match"
+	ErrOwnershipPath    Error = "ownership proof paths must be relative and non-hardened"
+	ErrOwnershipAddress Error = "ownership proof address does not match its path"
+)
+
+// OwnershipFormat is the format of the signatures of an ownership proof.
+type OwnershipFormat string
+
+const (
+	// OwnershipLegacy signatures are compact "signmessage" signatures, cheap
+	// to verify by public key recovery.
+	OwnershipLegacy OwnershipFormat = "legacy"
+	// OwnershipBIP322 signatures are BIP322 "full" signatures.
+	OwnershipBIP322 OwnershipFormat = "bip322"
+)
+
+// OwnershipEntry proves control of the P2PKH address derived at Path below
+// the proof extended public key.
+type OwnershipEntry struct {
+	Path      string `json:"path"`
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+}
+
+// OwnershipProof proves control of many addresses of an account at once:
+// every entry signs the same challenge, and every address is derived from
+// the covering extended public key, so the verifier needs no address list.
+type OwnershipProof struct {
+	Challenge string          `json:"challenge"`
+	Network   Network         `json:"network"`
+	Format    OwnershipFormat `json:"format"`
+	XPub      string          `json:"xpub"`
+	// Origin is the fingerprint and path of XPub, e.g. "0f056943/44'/0'/0'".
+	Origin  string           `json:"origin,omitempty"`
+	Entries []OwnershipEntry `json:"entries"`
+}
+
+// Addresses returns the addresses listed by the proof, verified or not.
+func (s *OwnershipProof) Addresses() []string {
+	addresses := make([]string, len(s.Entries))
+	for i, entry := range s.Entries {
+		addresses[i] = entry.Address
+	}
+	return addresses
+}
+
+// OwnershipPaths returns the paths of count addresses of a chain, from start,
+// relative to the account key covered by ownership proofs: "chain/index".
+func OwnershipPaths(chain, start, count uint32) []string {
+	paths := make([]string, count)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("%d/%d", chain, start+uint32(i))
+	}
+	return paths
+}
+
+// ProveOwnership signs challenge with the keys at the given paths, relative
+// to the account key of the wallet (the key at its last hardened level), such
+// as returned by OwnershipPaths.
+func (s *Wallet) ProveOwnership(challenge string, paths []string, format OwnershipFormat) (*OwnershipProof, error) {
+	if len(paths) == 0 {
+		return nil, ErrOwnershipEmpty
+	}
+	var hash []byte
+	switch format {
+	case OwnershipLegacy:
+		hash = legacyMessageHash(challenge)
+	case OwnershipBIP322:
+	default:
+		return nil, ErrOwnershipFormat
+	}
+
+	origin, err := s.keyOrigin()
+	if err != nil {
+		return nil, err
+	}
+	xpub, err := origin.key.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	proof := &OwnershipProof{
+		Challenge: challenge,
+		Network:   s.network,
+		Format:    format,
+		XPub:      xpub.String(),
+		Origin:    origin.String(),
+		Entries:   make([]OwnershipEntry, 0, len(paths)),
+	}
+
+	keys := newOwnershipKeys(origin.key)
+	for _, path := range paths {
+		key, err := keys.derive(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		privateKey, err := key.ECPrivKey()
+		if err != nil {
+			return nil, err
+		}
+		address, err := ownershipAddress(privateKey.PubKey().SerializeCompressed(), s.params)
+		if err != nil {
+			return nil, err
+		}
+		signature := ""
+		if format == OwnershipLegacy {
+			signature = signMessageLegacy(privateKey, hash, true)
+		} else if signature, err = signMessageBIP322(privateKey, challenge, s.params); err != nil {
+			return nil, err
+		}
+		s.logSign("ownership_proof", "m"+formatPathLevels(origin.path)+"/"+path, slog.String("challenge", challenge))
+		proof.Entries = append(proof.Entries, OwnershipEntry{Path: path, Address: address, Signature: signature})
+	}
+	return proof, nil
+}
+
+// VerifyOwnershipProof checks that every entry of a proof signs the expected
+// challenge with the key derived at its path from the proof extended public
+// key. It returns the proven addresses.
+func VerifyOwnershipProof(proof *OwnershipProof, challenge string, network Network) ([]string, error) {
+	if proof.Network != network {
+		return nil, ErrOwnershipNetwork
+	}
+	if len(proof.Entries) == 0 {
+		return nil, ErrOwnershipEmpty
+	}
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	if proof.Challenge != challenge {
+		return nil, ErrMessageSignature
+	}
+	var hash []byte
+	switch proof.Format {
+	case OwnershipLegacy:
+		hash = legacyMessageHash(challenge)
+	case OwnershipBIP322:
+	default:
+		return nil, ErrOwnershipFormat
+	}
+
+	xpub, err := hdkeychain.NewKeyFromString(proof.XPub)
+	if err != nil {
+		return nil, err
+	}
+	if !xpub.IsForNet(params) {
+		return nil, ErrOwnershipNetwork
+	}
+	if xpub, err = xpub.Neuter(); err != nil {
+		return nil, err
+	}
+
+	keys := newOwnershipKeys(xpub)
+	addresses := make([]string, 0, len(proof.Entries))
+	for _, entry := range proof.Entries {
+		if err := verifyOwnershipEntry(keys, proof, entry, hash, params); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Path, err)
+		}
+		addresses = append(addresses, entry.Address)
+	}
+	return addresses, nil
+}
+
+// verifyOwnershipEntry checks an entry of a proof. Legacy signatures are
+// checked against the derived key by recovery, without the script engine.
+func verifyOwnershipEntry(keys *ownershipKeys, proof *OwnershipProof, entry OwnershipEntry, hash []byte, params *chaincfg.Params) error {
+	key, err := keys.derive(entry.Path)
+	if err != nil {
+		return err
+	}
+	publicKey, err := key.ECPubKey()
+	if err != nil {
+		return err
+	}
+	address, err := ownershipAddress(publicKey.SerializeCompressed(), params)
+	if err != nil {
+		return err
+	}
+	if address != entry.Address {
+		return ErrOwnershipAddress
+	}
+
+	if proof.Format == OwnershipBIP322 {
+		return VerifyMessageBIP322(entry.Address, proof.Challenge, entry.Signature, proof.Network)
+	}
+	recovered, compressed, err := recoverMessageLegacy(entry.Signature, hash)
+	if err != nil {
+		return err
+	}
+	if !compressed || !recovered.IsEqual(publicKey) {
+		return ErrMessageSignature
+	}
+	return nil
+}
+
+// ownershipAddress returns the P2PKH address of a serialized public key.
+func ownershipAddress(publicKey []byte, params *chaincfg.Params) (string, error) {
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(publicKey), params)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// ownershipKeys derives the keys of an ownership proof below its covering
+// key, caching the intermediate levels shared by the paths so that each
+// chain key is derived once.
+type ownershipKeys struct {
+	key   *hdkeychain.ExtendedKey
+	cache map[string]*hdkeychain.ExtendedKey
+}
+
+// newOwnershipKeys returns the key deriver of a covering key.
+func newOwnershipKeys(key *hdkeychain.ExtendedKey) *ownershipKeys {
+	return &ownershipKeys{key: key, cache: make(map[string]*hdkeychain.ExtendedKey)}
+}
+
+// derive returns the key at a relative, non-hardened, path such as "0/5".
+func (s *ownershipKeys) derive(path string) (*hdkeychain.ExtendedKey, error) {
+	levels := strings.Split(path, "/")
+	indexes, err := parsePathLevels(levels)
+	if err != nil {
+		return nil, err
+	}
+	key := s.key
+	for i, index := range indexes {
+		if index >= hdkeychain.HardenedKeyStart {
+			return nil, ErrOwnershipPath
+		}
+		prefix := strings.Join(levels[:i+1], "/")
+		if cached, ok := s.cache[prefix]; ok {
+			key = cached
+			continue
+		}
+		if key, err = deriveKey(key, index); err != nil {
+			return nil, err
+		}
+		if i < len(indexes)-1 {
+			s.cache[prefix] = key
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,80 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OwnershipPaths(t *testing.T) {
+	assert.Equal(t, []string{"1/5", "1/6", "1/7"}, OwnershipPaths(1, 5, 3))
+	assert.Empty(t, OwnershipPaths(0, 0, 0))
+}
+
+func Test_ProveOwnership(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	paths := append(OwnershipPaths(0, 0, 50), OwnershipPaths(1, 0, 10)...)
+
+	for _, format := range []OwnershipFormat{OwnershipLegacy, OwnershipBIP322} {
+		proof, err := wallet.ProveOwnership("audit 2026-Q3", paths, format)
+		assert.NoError(t, err)
+		assert.Len(t, proof.Entries, 60)
+		assert.Contains(t, proof.Origin, "/44'/0'/0'")
+
+		first, err := wallet.At(0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, first.AddressHex(), proof.Entries[0].Address)
+		last, err := wallet.At(1, 9)
+		assert.NoError(t, err)
+		assert.Equal(t, last.AddressHex(), proof.Entries[59].Address)
+
+		// The proof survives a JSON round trip.
+		data, err := json.Marshal(proof)
+		assert.NoError(t, err)
+		var decoded OwnershipProof
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+
+		addresses, err := VerifyOwnershipProof(&decoded, "audit 2026-Q3", NetworkMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, proof.Addresses(), addresses)
+
+		_, err = VerifyOwnershipProof(&decoded, "audit 2026-Q4", NetworkMainnet)
+		assert.ErrorIs(t, err, ErrMessageSignature)
+		_, err = VerifyOwnershipProof(&decoded, "audit 2026-Q3", NetworkTestnet)
+		assert.ErrorIs(t, err, ErrOwnershipNetwork)
+
+		// A signature moved to another entry does not verify.
+		tampered := decoded
+		tampered.Entries = append([]OwnershipEntry(nil), decoded.Entries...)
+		tampered.Entries[1].Signature = tampered.Entries[0].Signature
+		_, err = VerifyOwnershipProof(&tampered, "audit 2026-Q3", NetworkMainnet)
+		assert.ErrorContains(t, err, "0/1")
+
+		// An address not derived at its path is rejected.
+		tampered.Entries = append([]OwnershipEntry(nil), decoded.Entries...)
+		tampered.Entries[0].Address = decoded.Entries[1].Address
+		_, err = VerifyOwnershipProof(&tampered, "audit 2026-Q3", NetworkMainnet)
+		assert.ErrorIs(t, err, ErrOwnershipAddress)
+	}
+
+	_, err := wallet.ProveOwnership("challenge", nil, OwnershipLegacy)
+	assert.ErrorIs(t, err, ErrOwnershipEmpty)
+	_, err = wallet.ProveOwnership("challenge", paths, "schnorr")
+	assert.ErrorIs(t, err, ErrOwnershipFormat)
+	_, err = wallet.ProveOwnership("challenge", []string{"0'/1"}, OwnershipLegacy)
+	assert.ErrorIs(t, err, ErrOwnershipPath)
+	_, err = wallet.ProveOwnership("challenge", []string{""}, OwnershipLegacy)
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	// Watch-only wallets cannot sign.
+	var buf bytes.Buffer
+	assert.NoError(t, wallet.Export("core", &buf))
+	descs, err := ParseCoreDescriptors(buf.Bytes())
+	assert.NoError(t, err)
+	watch, err := descs[0].Wallet()
+	assert.NoError(t, err)
+	_, err = watch.ProveOwnership("challenge", paths, OwnershipLegacy)
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package p2pkh
+
+import (
+	"bytes"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// OwnsAddress reports whether an address of the wallet network pays to one
+// of the first searchDepth non-hardened children of the wallet, and at which
+// index, e.g. whether a deposit address was handed out by a wallet at
+// m/44'/0'/0'/0. The address may be of any single-key type of the children:
+// P2PKH, P2SH-P2WPKH, P2WPKH or P2TR. Children are derived from the extended
+// public key, so that watch-only wallets search too. Change addresses are
+// searched from the wallet of the change chain, e.g. account.Wallet().Derive(1).
+func (s *Wallet) OwnsAddress(addr string, searchDepth uint32) (bool, uint32, error) {
+	if uint64(searchDepth) > hdkeychain.HardenedKeyStart {
+		return false, 0, ErrIndexRange
+	}
+	parsed, script, err := parseAddressScript(addr, s.network)
+	if err != nil {
+		return false, 0, err
+	}
+	scheme, err := addressScheme(parsed.Type())
+	if err != nil {
+		return false, 0, err
+	}
+	xpub, err := s.extendedKey.Neuter()
+	if err != nil {
+		return false, 0, err
+	}
+
+	for index := uint32(0); index < searchDepth; index++ {
+		child, err := deriveKey(xpub, index)
+		if err != nil {
+			return false, 0, err
+		}
+		publicKey, err := child.ECPubKey()
+		if err != nil {
+			return false, 0, err
+		}
+		var childScript []byte
+		if parsed.Type() == AddressTypeP2PKH && s.uncompressed {
+			childScript, err = p2pkhScript(publicKey.SerializeUncompressed())
+		} else {
+			childScript, err = scheme.Script(publicKey, s.params)
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		if bytes.Equal(childScript, script) {
+			return true, index, nil
+		}
+	}
+	return false, 0, nil
+}
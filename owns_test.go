@@ -0,0 +1,50 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OwnsAddress(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(7)
+	assert.NoError(t, err)
+
+	owned, index, err := wallet.OwnsAddress(child.AddressHex(), 20)
+	assert.NoError(t, err)
+	assert.True(t, owned)
+	assert.Equal(t, uint32(7), index)
+
+	// The other address types of the children are found too.
+	segwit, err := child.AddressOfType(AddressTypeP2WPKH)
+	assert.NoError(t, err)
+	owned, index, err = wallet.OwnsAddress(segwit.String(), 20)
+	assert.NoError(t, err)
+	assert.True(t, owned)
+	assert.Equal(t, uint32(7), index)
+
+	// Beyond the search depth, or on another chain, the address is not found.
+	owned, _, err = wallet.OwnsAddress(child.AddressHex(), 7)
+	assert.NoError(t, err)
+	assert.False(t, owned)
+	change, err := wallet.DeriveChange(2)
+	assert.NoError(t, err)
+	owned, _, err = wallet.OwnsAddress(change.AddressHex(), 20)
+	assert.NoError(t, err)
+	assert.False(t, owned)
+
+	// Watch-only wallets search their children too.
+	watch, err := wallet.Neuter()
+	assert.NoError(t, err)
+	owned, index, err = watch.OwnsAddress(child.AddressHex(), 20)
+	assert.NoError(t, err)
+	assert.True(t, owned)
+	assert.Equal(t, uint32(7), index)
+
+	_, _, err = wallet.OwnsAddress("mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", 20)
+	assert.Error(t, err)
+	owned, _, err = wallet.OwnsAddress("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", 20)
+	assert.NoError(t, err)
+	assert.False(t, owned)
+}
@@ -1,82 +1,323 @@
 package p2pkh
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/ethereum/go-ethereum/accounts"
 	bip39 "github.com/tyler-smith/go-bip39"
 )
 
 // Network represents the type of blockchain network the wallet operates on.
-// It can either be "mainnet" for the production Bitcoin network or "testnet"
-// for the test Bitcoin network, allowing for separate configurations and
-// behaviors for each network.
+// It can either be "mainnet" for the production Bitcoin network, "testnet"
+// for the test Bitcoin network (testnet3) or "testnet4" for its successor,
+// allowing for separate configurations and behaviors for each network.
 type Network string
 
 const (
-	NetworkMainnet Network = "mainnet"
-	NetworkTestnet Network = "testnet"
-
-	ErrInvalidMnemonic  = "mnemonic is required"
-	ErrUnsupportedNet   = "unsupported network type: choose either 'mainnet' or 'testnet'"
-	ErrInvalidPath      = "failed to parse derivation path"
-	ErrKeyDerivation    = "failed to derive key"
-	ErrIndexNegative    = "index cannot be negative"
-	ErrUnsupportedIndex = "unsupported index type"
+	NetworkMainnet  Network = "mainnet"
+	NetworkTestnet  Network = "testnet"
+	NetworkTestnet4 Network = "testnet4"
+
+	ErrInvalidMnemonic  Error = "mnemonic is required"
+	ErrUnsupportedNet   Error = "unsupported network type: choose 'mainnet', 'testnet', 'testnet4' or a registered network"
+	ErrInvalidPath      Error = "failed to parse derivation path"
+	ErrKeyDerivation    Error = "failed to derive key"
+	ErrMasterKey        Error = "failed to generate master key"
+	ErrIndexNegative    Error = "index cannot be negative"
+	ErrUnsupportedIndex Error = "unsupported index type"
+	ErrIndexRange       Error = "child index must be below 2^31"
+	ErrHardenedPublic   Error = "cannot derive a hardened child from a public key"
+	ErrWatchOnly        Error = "watch-only wallet has no private key"
+	ErrXPubNetwork      Error = "extended public key does not belong to the network"
+	ErrXPrvNetwork      Error = "extended private key does not belong to the network"
+	ErrXPrvPublic       Error = "extended key is not private"
+
+	// accountLevels is the number of levels of an account path: purpose,
+	// coin type and account.
+	accountLevels = 3
+)
+
+// ChildDerivation selects how DeriveChild derives a child key.
+type ChildDerivation int
+
+const (
+	// NonHardened children can also be derived from the extended public key.
+	NonHardened ChildDerivation = iota
+	// Hardened children require the extended private key.
+	Hardened
 )
 
 // Config represents the configuration necessary to create a Wallet.
 type Config struct {
 	Mnemonic string
-	Path     string
-	Network  Network
+	// Passphrase is the optional BIP39 passphrase, the "25th word", salting
+	// the seed of the mnemonic. Non-ASCII passphrases must be NFKD
+	// normalized.
+	Passphrase string
+	Path       string
+	Network    Network
+	// Purpose selects the default path, e.g. m/84'/0'/0'/0 for
+	// PurposeNativeSegwit, and the type of the PurposeAddress of the wallet.
+	// A Path of another purpose is rejected. It defaults to PurposeLegacy
+	// without Path, and to the purpose of Path otherwise.
+	Purpose Purpose
+	// CompletePath completes a path stopping at the account level, e.g.
+	// m/44'/0'/0', to its external chain m/44'/0'/0'/0.
+	CompletePath bool
+	// Backend optionally connects the wallet to the network.
+	Backend ChainBackend
+	// ForgetMnemonic creates a wallet that does not retain the mnemonic, nor
+	// the passphrase, once its keys are derived; Mnemonic then returns "".
+	ForgetMnemonic bool
+	// WipeOnCollect zeroes the private extended keys of the wallet, and of
+	// those derived from it, once the garbage collector reclaims them.
+	WipeOnCollect bool
+	// UncompressedKeys derives the P2PKH addresses of the wallet, and of those
+	// derived from it, from the uncompressed public keys, as very old wallets
+	// did. WIF exports and transaction and message signatures then use the
+	// uncompressed keys too; PSBTs and the other address types keep the
+	// compressed ones.
+	UncompressedKeys bool
+	// Logger optionally receives the events of the wallet, and of those
+	// derived from it: derivations, signatures and broadcasts, see
+	// EventSign. Secrets are never logged.
+	Logger *slog.Logger
+	// LowRSignatures grinds the transaction and digest signatures of the
+	// wallet, and of those derived from it, to a low R value, as Bitcoin
+	// Core does, saving a byte in half of them. Signatures are
+	// deterministic (RFC6979) and low-S either way.
+	LowRSignatures bool
 }
 
-// Wallet represents an HD wallet.
+// Wallet represents an HD wallet. A Wallet is immutable once created, and
+// therefore safe to share between goroutines and caches; use a WalletBuilder
+// to create variants of a wallet. Its methods are safe for concurrent use:
+// the only internal state, the cache of the child keys derived by Derive, is
+// guarded, so that a server handing out one address per request from a
+// shared wallet derives each child once.
 type Wallet struct {
-	mnemonic    string
-	path        string
+	mnemonic   string
+	passphrase string
+	path       string
+	// levels is the parsed path, shared with the key origins and never
+	// modified.
+	levels      Path
 	root        *hdkeychain.ExtendedKey
+	origin      *keyOrigin
 	extendedKey *hdkeychain.ExtendedKey
 	publicKey   *btcec.PublicKey
 	address     *btcutil.AddressPubKey
 	params      *chaincfg.Params
+	network     Network
+	parent      *Wallet
+	backend     ChainBackend
+	logger      *slog.Logger
+	children    *childCache
+	// wipeOnCollect, uncompressed, lowR and addrType are inherited by the
+	// wallets derived from the wallet.
+	wipeOnCollect bool
+	uncompressed  bool
+	lowR          bool
+	// addrType, when set, overrides the address type of the path purpose,
+	// e.g. for the segwit keystores of Electrum standard wallets, whose
+	// paths have no purpose.
+	addrType AddressType
 }
 
-// New creates a new Wallet from a configuration.
+// New creates a new Wallet from a configuration, which is left untouched.
 func New(config *Config) (*Wallet, error) {
 	if config.Mnemonic == "" || !validateMnemonic(config.Mnemonic) {
-		return nil, errors.New(ErrInvalidMnemonic)
+		return nil, ErrInvalidMnemonic
 	}
 
-	path, err := selectDerivationPath(config.Network, config.Path)
+	seed := bip39.NewSeed(mnemonicSeedPhrase(config.Mnemonic), config.Passphrase)
+	defer zero(seed)
+	wallet, err := NewFromSeed(seed, config)
 	if err != nil {
 		return nil, err
 	}
-	config.Path = path
+	if !config.ForgetMnemonic {
+		wallet.mnemonic = config.Mnemonic
+		wallet.passphrase = config.Passphrase
+	}
+	return wallet, nil
+}
 
+// NewFromSeed creates a new Wallet from a raw BIP32 seed of 16 to 64 bytes,
+// e.g. entropy held in an HSM, at the path and network of a configuration
+// whose mnemonic is ignored. The wallet has no mnemonic, so that
+// ExportKeystore exports its master extended private key.
+func NewFromSeed(seed []byte, config *Config) (*Wallet, error) {
 	params, err := selectNetworkParams(config.Network)
 	if err != nil {
 		return nil, err
 	}
 
-	seed := bip39.NewSeed(config.Mnemonic, "")
-
 	masterKey, err := generateMasterKey(seed, params)
 	if err != nil {
 		return nil, err
 	}
 
-	key, err := deriveKeyFromPath(masterKey, config.Path)
+	if config.WipeOnCollect {
+		zeroOnCollect(masterKey)
+	}
+	return newFromMasterKey(masterKey, config)
+}
+
+// newFromMasterKey creates the wallet of a configuration from the master key
+// of its seed and network, whose mnemonic is ignored.
+func newFromMasterKey(masterKey *hdkeychain.ExtendedKey, config *Config) (*Wallet, error) {
+	path, err := purposePath(config.Purpose, config.Network, config.Path)
+	if err != nil {
+		return nil, err
+	}
+	if config.CompletePath {
+		path = completePath(path)
+	}
+
+	wallet, err := newWallet(masterKey, nil, path, config.Network)
+	if err != nil {
+		return nil, err
+	}
+	wallet.backend = config.Backend
+	wallet.logger = config.Logger
+	wallet.wipeOnCollect = config.WipeOnCollect
+	wallet.setUncompressed(config.UncompressedKeys)
+	wallet.lowR = config.LowRSignatures
+	if config.WipeOnCollect && wallet.extendedKey != masterKey {
+		zeroOnCollect(wallet.extendedKey)
+	}
+	return wallet, nil
+}
+
+// NewFromExtendedPrivateKey creates a new Wallet from an extended private key
+// (xprv, tprv) of the network, e.g. an existing backup. A master key yields
+// the wallet at the default path of the network, as New does. Any other key
+// is the root of the wallet, whose path "m" is relative to it as in
+// NewFromExtendedPublicKey.
+func NewFromExtendedPrivateKey(xprv string, network Network) (*Wallet, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hdkeychain.NewKeyFromString(xprv)
+	if err != nil {
+		return nil, err
+	}
+	if !key.IsPrivate() {
+		return nil, ErrXPrvPublic
+	}
+	if !key.IsForNet(params) {
+		return nil, ErrXPrvNetwork
+	}
+
+	if key.Depth() == 0 {
+		path, err := selectDerivationPath(network, "")
+		if err != nil {
+			return nil, err
+		}
+		return newWallet(key, nil, path, network)
+	}
+
+	wallet, err := newWalletFromKey(key, "m", params, network)
+	if err != nil {
+		return nil, err
+	}
+	wallet.root = key
+	return wallet, nil
+}
+
+// NewFromExtendedPublicKey creates a watch-only Wallet from an extended
+// public key (xpub, tpub) of the network. The wallet derives addresses
+// without any private material; its path "m" is relative to the extended key,
+// so that its children are at "m/0", "m/1"... An extended private key is
+// accepted but neutered.
+func NewFromExtendedPublicKey(xpub string, network Network) (*Wallet, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hdkeychain.NewKeyFromString(xpub)
+	if err != nil {
+		return nil, err
+	}
+	if !key.IsForNet(params) {
+		return nil, ErrXPubNetwork
+	}
+	if key, err = key.Neuter(); err != nil {
+		return nil, err
+	}
+
+	wallet, err := newWalletFromKey(key, "m", params, network)
+	if err != nil {
+		return nil, err
+	}
+	wallet.root = key
+	return wallet, nil
+}
+
+// newWallet creates a Wallet at path from its root key. The root is usually
+// the master key; otherwise origin is the origin of root, e.g. an account key
+// imported from a descriptor, and path must start with origin.path.
+func newWallet(root *hdkeychain.ExtendedKey, origin *keyOrigin, path string, network Network) (*Wallet, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+
+	dpath, err := ParsePath(path)
 	if err != nil {
 		return nil, err
 	}
+	levels := dpath
+	if origin != nil {
+		if len(dpath) < len(origin.path) || formatPathLevels(dpath[:len(origin.path)]) != formatPathLevels(origin.path) {
+			return nil, ErrInvalidPath
+		}
+		levels = dpath[len(origin.path):]
+	}
+
+	key := root
+	for _, n := range levels {
+		if key, err = deriveKey(key, n); err != nil {
+			return nil, err
+		}
+	}
+
+	wallet, err := newWalletFromKey(key, path, params, network)
+	if err != nil {
+		return nil, err
+	}
+	wallet.root = root
+	wallet.origin = origin
+	return wallet, nil
+}
+
+// setUncompressed sets whether the wallet uses its uncompressed public key,
+// which its address is then derived from.
+func (s *Wallet) setUncompressed(uncompressed bool) {
+	s.uncompressed = uncompressed
+	if uncompressed {
+		s.address.SetFormat(btcutil.PKFUncompressed)
+	}
+}
 
+// newWalletFromKey creates the Wallet of an extended key at path, without
+// linkage to its root.
+func newWalletFromKey(key *hdkeychain.ExtendedKey, path string, params *chaincfg.Params, network Network) (*Wallet, error) {
+	levels, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
 	publicKey, err := key.ECPubKey()
 	if err != nil {
 		return nil, err
@@ -88,118 +329,285 @@ func New(config *Config) (*Wallet, error) {
 	}
 
 	return &Wallet{
-		mnemonic:    config.Mnemonic,
-		path:        config.Path,
-		root:        masterKey,
+		path:        path,
+		levels:      levels,
 		extendedKey: key,
 		publicKey:   publicKey,
 		address:     addr,
 		params:      params,
+		network:     network,
+		children:    newChildCache(childCacheSize),
 	}, nil
 }
 
-// selectDerivationPath selects the bypass path based on the network.
+// deriveKey derives the child of an extended key at index. Public keys,
+// e.g. the one of a wallet built from an xpub, derive their normal children
+// only: hardened ones fail with ErrHardenedPublic.
+func deriveKey(key *hdkeychain.ExtendedKey, index uint32) (*hdkeychain.ExtendedKey, error) {
+	if index >= hdkeychain.HardenedKeyStart && !key.IsPrivate() {
+		return nil, ErrHardenedPublic
+	}
+	child, err := key.Derive(index)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeyDerivation, err)
+	}
+	return child, nil
+}
+
+// selectDerivationPath selects the bypass path based on the network, e.g.
+// m/44'/0'/0'/0 on mainnet and m/44'/1'/0'/0 on testnet.
 func selectDerivationPath(network Network, path string) (string, error) {
 	if path == "" {
-		switch network {
-		case NetworkMainnet:
-			return `m/44'/0'/0'/0`, nil
-		case NetworkTestnet:
-			return `m/44'/1'/0'/0`, nil
-		default:
-			return "", errors.New(ErrUnsupportedNet)
-		}
+		return DerivationPath(AddressTypeP2PKH, network, 0)
 	}
 	return path, nil
 }
 
+// completePath appends the external chain to an account-level path
+// (purpose/coin/account). Other paths are returned unchanged.
+func completePath(path string) string {
+	if levels, err := ParsePath(path); err == nil && len(levels) == accountLevels {
+		return path + "/0"
+	}
+	return path
+}
+
 // selectNetworkParams selects network parameters based on configuration.
 func selectNetworkParams(network Network) (*chaincfg.Params, error) {
-	switch network {
-	case NetworkMainnet:
-		return &chaincfg.MainNetParams, nil
-	case NetworkTestnet:
-		return &chaincfg.TestNet3Params, nil
-	default:
-		return nil, errors.New(ErrUnsupportedNet)
-	}
+	return network.Params()
 }
 
 // generateMasterKey generates the master key from the seed and network parameters.
 func generateMasterKey(seed []byte, params *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
 	masterKey, err := hdkeychain.NewMaster(seed, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate master key: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrMasterKey, err)
 	}
 	return masterKey, nil
 }
 
-// deriveKeyFromPath derives a key from the specified derivation path.
-func deriveKeyFromPath(masterKey *hdkeychain.ExtendedKey, path string) (*hdkeychain.ExtendedKey, error) {
-	dpath, err := accounts.ParseDerivationPath(path)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", ErrInvalidPath, err)
-	}
-
-	key := masterKey
-	for _, n := range dpath {
-		key, err = key.Derive(n)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", ErrKeyDerivation, err)
-		}
-	}
-	return key, nil
-}
-
 // convertToUint32 converts different index types to uint32.
 func convertToUint32(index interface{}) (uint32, error) {
 	switch v := index.(type) {
 	case int:
 		if v < 0 {
-			return 0, errors.New(ErrIndexNegative)
+			return 0, ErrIndexNegative
 		}
 		return uint32(v), nil
 	case int64:
 		if v < 0 {
-			return 0, errors.New(ErrIndexNegative)
+			return 0, ErrIndexNegative
+		}
+		return uint32(v), nil
+	case uint:
+		if uint64(v) > math.MaxUint32 {
+			return 0, ErrUnsupportedIndex
 		}
 		return uint32(v), nil
-	case uint, uint32:
-		return uint32(v.(uint32)), nil
+	case uint32:
+		return v, nil
 	default:
-		return 0, errors.New(ErrUnsupportedIndex)
+		return 0, ErrUnsupportedIndex
 	}
 }
 
-// Derive derives a new portfolio from an index.
+// Derive derives a new portfolio from an index. Indexes from 2^31 are
+// hardened, e.g. hdkeychain.HardenedKeyStart + 3 is the child "3'". The
+// derived wallet shares the mnemonic and root of its parent, so that backups
+// of any wallet of the tree restore it, unless derived from a copy made by
+// WithoutMnemonic. It is linked to its parent, see Parent and ChildIndex.
+// The child key is cached by the parent, which derives it only once.
 func (s *Wallet) Derive(index interface{}) (*Wallet, error) {
 	idx, err := convertToUint32(index)
 	if err != nil {
 		return nil, err
 	}
+	derivedKey, err := s.childKey(idx)
+	if err != nil {
+		return nil, err
+	}
+	return s.childWallet(idx, derivedKey)
+}
 
-	derivedKey, err := s.extendedKey.Derive(idx)
+// childWallet returns the child wallet of the key derived at index, linked
+// to the wallet.
+func (s *Wallet) childWallet(idx uint32, derivedKey *hdkeychain.ExtendedKey) (*Wallet, error) {
+	wallet, err := newWalletFromKey(derivedKey, childPath(s.path, idx), s.params, s.network)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", ErrKeyDerivation, err)
+		return nil, err
+	}
+	wallet.mnemonic = s.mnemonic
+	wallet.passphrase = s.passphrase
+	wallet.backend = s.backend
+	wallet.logger = s.logger
+	wallet.wipeOnCollect = s.wipeOnCollect
+	wallet.setUncompressed(s.uncompressed)
+	wallet.lowR = s.lowR
+	wallet.addrType = s.addrType
+	wallet.root = s.root
+	wallet.origin = s.origin
+	wallet.parent = s
+	wallet.logEvent(context.Background(), slog.LevelDebug, EventDerive)
+	return wallet, nil
+}
+
+// childPath returns the path of the child at index of the wallet at path,
+// without the formatting machinery of fmt as children are derived by the
+// thousands.
+func childPath(path string, index uint32) string {
+	buf := make([]byte, 0, len(path)+12)
+	buf = append(append(buf, path...), '/')
+	if index >= hdkeychain.HardenedKeyStart {
+		return string(append(strconv.AppendUint(buf, uint64(index-hdkeychain.HardenedKeyStart), 10), '\''))
 	}
+	return string(strconv.AppendUint(buf, uint64(index), 10))
+}
 
-	publicKey, err := derivedKey.ECPubKey()
+// DeriveChild derives the normal or hardened child of a wallet at an index
+// below 2^31, e.g. DeriveChild(3, Hardened) for the child "3'".
+func (s *Wallet) DeriveChild(index uint32, derivation ChildDerivation) (*Wallet, error) {
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, ErrIndexRange
+	}
+	if derivation == Hardened {
+		index += hdkeychain.HardenedKeyStart
+	}
+	return s.Derive(index)
+}
+
+// DeriveHardened derives the hardened child of a wallet at an index below
+// 2^31, e.g. DeriveHardened(0) for the child "0'".
+func (s *Wallet) DeriveHardened(index uint32) (*Wallet, error) {
+	return s.DeriveChild(index, Hardened)
+}
+
+// DerivePath derives the descendant of a wallet at a path relative to it,
+// such as "0'/3", whose hardened levels end with "'" or "h". Each level is
+// derived with Derive, so the descendant is linked to the intermediate
+// wallets.
+func (s *Wallet) DerivePath(relativePath string) (*Wallet, error) {
+	levels, err := parsePathLevels(strings.Split(relativePath, "/"))
 	if err != nil {
 		return nil, err
 	}
+	wallet := s
+	for _, index := range levels {
+		if wallet, err = wallet.Derive(index); err != nil {
+			return nil, err
+		}
+	}
+	return wallet, nil
+}
 
-	addr, err := btcutil.NewAddressPubKey(publicKey.SerializeCompressed(), s.params)
+// Addresses returns the addresses of count consecutive non-hardened children
+// of the wallet from start, those Derive(index).AddressInfo() returns: of the
+// type the path purpose selects, and of the uncompressed keys for wallets of
+// uncompressed keys. They are derived from the extended public key, without
+// creating child wallets, e.g. to pre-generate receive addresses.
+func (s *Wallet) Addresses(start, count uint32) ([]string, error) {
+	if uint64(start)+uint64(count) > hdkeychain.HardenedKeyStart {
+		return nil, ErrIndexRange
+	}
+	xpub, err := s.extendedKey.Neuter()
 	if err != nil {
 		return nil, err
 	}
+	s.logEvent(context.Background(), slog.LevelDebug, EventAddresses, slog.Uint64("start", uint64(start)), slog.Uint64("count", uint64(count)))
+	addresses := make([]string, count)
+	for i := range addresses {
+		child, err := deriveKey(xpub, start+uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := child.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		addr, err := s.keyAddress(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = addr.EncodeAddress()
+	}
+	return addresses, nil
+}
 
-	return &Wallet{
-		path:        fmt.Sprintf("%s/%d", s.path, idx),
-		root:        s.extendedKey,
-		extendedKey: derivedKey,
-		address:     addr,
-		params:      s.params,
-	}, nil
+// keyAddress returns the address of a key of the wallet, of the type the
+// path purpose selects, or the P2PKH address of the uncompressed key for
+// wallets of uncompressed keys.
+func (s *Wallet) keyAddress(publicKey *btcec.PublicKey) (btcutil.Address, error) {
+	if s.uncompressed {
+		return btcutil.NewAddressPubKeyHash(btcutil.Hash160(publicKey.SerializeUncompressed()), s.params)
+	}
+	return publicKeyAddress(publicKey, s.AddressType(), s.params)
+}
+
+// At returns the wallet at an index of a chain (0 external, 1 internal) of
+// the account of the wallet, whose path must hold at least the purpose,
+// coin type and account levels, e.g. At(1, 5) of a wallet at m/44'/0'/0'/0
+// is at m/44'/0'/0'/1/5.
+func (s *Wallet) At(chain, index uint32) (*Wallet, error) {
+	if chain >= hdkeychain.HardenedKeyStart || index >= hdkeychain.HardenedKeyStart {
+		return nil, ErrIndexRange
+	}
+	if len(s.levels) < accountLevels || s.root == nil {
+		return nil, ErrInvalidPath
+	}
+	return s.rootWallet(fmt.Sprintf("m%s/%d/%d", formatPathLevels(s.levels[:accountLevels]), chain, index))
+}
+
+// rootWallet returns the wallet at another path of the wallet root, sharing
+// the mnemonic and passphrase, backend, logger, wipe behavior and key
+// compression of the wallet.
+func (s *Wallet) rootWallet(path string) (*Wallet, error) {
+	wallet, err := newWallet(s.root, s.origin, path, s.network)
+	if err != nil {
+		return nil, err
+	}
+	wallet.mnemonic = s.mnemonic
+	wallet.passphrase = s.passphrase
+	wallet.backend = s.backend
+	wallet.logger = s.logger
+	wallet.wipeOnCollect = s.wipeOnCollect
+	wallet.setUncompressed(s.uncompressed)
+	wallet.lowR = s.lowR
+	wallet.addrType = s.addrType
+	if s.wipeOnCollect && wallet.extendedKey != s.root {
+		zeroOnCollect(wallet.extendedKey)
+	}
+	wallet.logEvent(context.Background(), slog.LevelDebug, EventDerive)
+	return wallet, nil
+}
+
+// DeriveChange returns the wallet at an index of the change (internal) chain
+// of the wallet account, e.g. m/44'/0'/0'/1/3 for index 3. Change addresses
+// keep the funds returned by transactions off the receive addresses.
+func (s *Wallet) DeriveChange(index uint32) (*Wallet, error) {
+	return s.At(1, index)
+}
+
+// Parent returns the wallet this wallet was derived from, nil for wallets
+// not created by Derive.
+func (s *Wallet) Parent() *Wallet {
+	return s.parent
+}
+
+// ChildIndex returns the index the wallet key was derived at from its
+// parent key, the last level of its path, hardened from 2^31; 0 for a master
+// key. Unlike Index, it does not depend on the BIP44 layout of the path.
+func (s *Wallet) ChildIndex() uint32 {
+	return s.extendedKey.ChildIndex()
+}
+
+// WithoutMnemonic returns a copy of the wallet that does not hold the
+// mnemonic and passphrase, nor pass them on to the wallets derived from it,
+// e.g. to hand a subtree to a component that must not be able to back up
+// the whole wallet.
+func (s *Wallet) WithoutMnemonic() *Wallet {
+	wallet := *s
+	wallet.mnemonic = ""
+	wallet.passphrase = ""
+	return &wallet
 }
 
 // PublicKey returns the public key (ECDSA) associated with the wallet.
@@ -208,15 +616,19 @@ func (s *Wallet) PublicKey() *btcec.PublicKey {
 }
 
 // Address returns the Bitcoin P2PKH address (AddressPubKey) associated with the wallet's public key.
-// This address is in the native format of the btcutil library.
+// This address is in the native format of the btcutil library. It is a copy,
+// so that the wallet is unaffected by SetFormat.
 func (s *Wallet) Address() *btcutil.AddressPubKey {
-	return s.address
+	addr := *s.address
+	return &addr
 }
 
-// AddressHex returns the Bitcoin address in its encoded hexadecimal string format.
+// AddressHex returns the Bitcoin address in its encoded string format.
 // This is a human-readable format used for transactions and sharing the address.
+//
+// Deprecated: the address is base58, not hex. Use AddressInfo().String().
 func (s *Wallet) AddressHex() string {
-	return s.Address().EncodeAddress()
+	return s.AddressInfo().String()
 }
 
 // Path returns the derivation path used to generate the wallet.
@@ -224,26 +636,85 @@ func (s *Wallet) Path() string {
 	return s.path
 }
 
-// PrivateKey returns the private key associated with the wallet in WIF (Wallet Import Format).
+// IsWatchOnly reports whether the wallet holds no private key, such as
+// wallets created from an extended public key.
+func (s *Wallet) IsWatchOnly() bool {
+	return !s.extendedKey.IsPrivate()
+}
+
+// PrivateKey returns the private key associated with the wallet in WIF (Wallet Import Format),
+// flagged for the compression of the wallet address.
 func (s *Wallet) PrivateKey() (string, error) {
-	privateKey, err := s.extendedKey.ECPrivKey()
+	return s.PrivateKeyWIF(!s.uncompressed)
+}
+
+// PrivateKeyWIF returns the private key of the wallet in WIF, flagged for a
+// compressed or uncompressed public key. Software importing a WIF derives the
+// address of the public key it flags: a compression other than the one of
+// the wallet (compressed unless Config.UncompressedKeys) yields another
+// address, for legacy systems expecting it only.
+func (s *Wallet) PrivateKeyWIF(compressed bool) (string, error) {
+	wif, err := s.wif(compressed)
 	if err != nil {
 		return "", err
 	}
-	wif, err := btcutil.NewWIF(privateKey, s.params, true)
+	return wif.String(), nil
+}
+
+// wif returns the private key of the wallet as a WIF of the wallet network.
+func (s *Wallet) wif(compressed bool) (*btcutil.WIF, error) {
+	if s.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	privateKey, err := s.extendedKey.ECPrivKey()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return wif.String(), nil
+	return btcutil.NewWIF(privateKey, s.params, compressed)
 }
 
 // ValidateAddress checks if the provided address is valid for the current network.
 func (s *Wallet) ValidateAddress(address string) (bool, error) {
-	addr, err := btcutil.DecodeAddress(address, s.params)
-	if err != nil {
+	if _, err := decodeAddress(address, s.params); err != nil {
+		var addrErr *AddressError
+		if errors.As(err, &addrErr) && addrErr.Kind == AddressErrorNetwork {
+			return false, nil
+		}
 		return false, err
 	}
-	return addr.IsForNet(s.params), nil
+	return true, nil
+}
+
+// AddressValidation is the detailed result of an address validation.
+type AddressValidation struct {
+	// Valid reports whether the address belongs to the wallet network.
+	Valid bool
+	// Address is the decoded address. For structurally valid addresses of
+	// another network, it carries the detected network and type.
+	Address Address
+}
+
+// ValidateAddressDetailed validates an address like ValidateAddress, but
+// tells apart addresses of another network from malformed ones: the former
+// are decoded with their own network, the latter return an *AddressError.
+func (s *Wallet) ValidateAddressDetailed(address string) (*AddressValidation, error) {
+	parsed, err := ParseAddress(address, s.network)
+	if err == nil {
+		return &AddressValidation{Valid: true, Address: parsed}, nil
+	}
+	var addrErr *AddressError
+	if !errors.As(err, &addrErr) || addrErr.Kind != AddressErrorNetwork {
+		return nil, err
+	}
+	for _, network := range Networks() {
+		if network == s.network {
+			continue
+		}
+		if parsed, err := ParseAddress(address, network); err == nil {
+			return &AddressValidation{Address: parsed}, nil
+		}
+	}
+	return nil, addrErr
 }
 
 // ExtendedPublicKey returns the wallet's extended public key (xpub).
@@ -255,12 +726,49 @@ func (s *Wallet) ExtendedPublicKey() (string, error) {
 	return xpub.String(), nil
 }
 
+// ExtendedPrivateKey returns the wallet's extended private key (xprv, tprv),
+// e.g. the key of an account to import into other wallet software, which
+// NewFromExtendedPrivateKey reads back. Anyone holding it spends the funds
+// of every address derived from it: use Neuter to hand out watch-only
+// wallets instead.
+func (s *Wallet) ExtendedPrivateKey() (string, error) {
+	if s.IsWatchOnly() {
+		return "", ErrWatchOnly
+	}
+	return s.extendedKey.String(), nil
+}
+
+// Neuter returns a watch-only copy of the wallet, at the same path and with
+// the same key origin, which derives the addresses of the account but signs
+// nothing and exports only extended public keys.
+func (s *Wallet) Neuter() (*Wallet, error) {
+	origin, err := s.keyOrigin()
+	if err != nil {
+		return nil, err
+	}
+	if origin.key, err = origin.key.Neuter(); err != nil {
+		return nil, err
+	}
+	wallet, err := newWallet(origin.key, origin, s.path, s.network)
+	if err != nil {
+		return nil, err
+	}
+	wallet.backend = s.backend
+	wallet.logger = s.logger
+	wallet.setUncompressed(s.uncompressed)
+	wallet.lowR = s.lowR
+	wallet.addrType = s.addrType
+	return wallet, nil
+}
+
 // Mnemonic returns the mnemonic phrase used to generate the wallet.
 func (s *Wallet) Mnemonic() string {
 	return s.mnemonic
 }
 
-// ValidateMnemonic checks if the given mnemonic is valid according to BIP39.
+// validateMnemonic checks if the given mnemonic is valid according to BIP39,
+// in any of the supported languages.
 func validateMnemonic(mnemonic string) bool {
-	return bip39.IsMnemonicValid(mnemonic)
+	_, err := MnemonicLanguage(mnemonic)
+	return err == nil
 }
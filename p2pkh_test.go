@@ -3,6 +3,8 @@ package p2pkh
 import (
 	"testing"
 
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/stretchr/testify/assert"
@@ -126,7 +128,7 @@ func Test_Mnemonic(t *testing.T) {
 			Network:  NetworkMainnet,
 		}
 		wallet, err := New(config)
-		assert.EqualError(t, err, ErrInvalidMnemonic)
+		assert.ErrorIs(t, err, ErrInvalidMnemonic)
 		assert.Nil(t, wallet)
 	})
 
@@ -137,7 +139,7 @@ func Test_Mnemonic(t *testing.T) {
 			Network:  NetworkMainnet,
 		}
 		wallet, err := New(config)
-		assert.EqualError(t, err, ErrInvalidMnemonic)
+		assert.ErrorIs(t, err, ErrInvalidMnemonic)
 		assert.Nil(t, wallet)
 	})
 }
@@ -153,6 +155,24 @@ func Test_PrivateKey(t *testing.T) {
 	assert.True(t, privateKeyWIF[0] == '5' || privateKeyWIF[0] == 'L' || privateKeyWIF[0] == 'K')
 }
 
+func Test_PrivateKeyWIF(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+
+	compressed, err := wallet.PrivateKeyWIF(true)
+	assert.NoError(t, err)
+	privateKey, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey, compressed)
+
+	uncompressed, err := wallet.PrivateKeyWIF(false)
+	assert.NoError(t, err)
+	assert.Equal(t, byte('5'), uncompressed[0])
+	wif, err := btcutil.DecodeWIF(uncompressed)
+	assert.NoError(t, err)
+	assert.False(t, wif.CompressPubKey)
+	assert.True(t, wif.PrivKey.PubKey().IsEqual(wallet.PublicKey()))
+}
+
 func Test_ValidateAddress(t *testing.T) {
 	wallet := createTestWallet(t, NetworkMainnet, `m/44'/0'/0'/0/0`)
 
@@ -180,6 +200,57 @@ func Test_ExtendedPublicKey(t *testing.T) {
 	assert.True(t, extendedPublicKey[:4] == "xpub" || extendedPublicKey[:4] == "tpub")
 }
 
+func Test_ExtendedPrivateKey(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	account, err := NewAccount(wallet)
+	assert.NoError(t, err)
+
+	// The account xprv restores the addresses of the account.
+	xprv, err := account.Wallet().ExtendedPrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, "xprv", xprv[:4])
+	restored, err := NewFromExtendedPrivateKey(xprv, NetworkMainnet)
+	assert.NoError(t, err)
+	child, err := restored.DerivePath("1/3")
+	assert.NoError(t, err)
+	expected, err := wallet.At(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.AddressHex(), child.AddressHex())
+
+	watch, err := NewFromExtendedPublicKey(xprv, NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = watch.ExtendedPrivateKey()
+	assert.ErrorIs(t, err, ErrWatchOnly)
+}
+
+func Test_Neuter(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	watch, err := wallet.Neuter()
+	assert.NoError(t, err)
+	assert.True(t, watch.IsWatchOnly())
+	assert.False(t, wallet.IsWatchOnly())
+	assert.Equal(t, wallet.Path(), watch.Path())
+	assert.Equal(t, wallet.AddressHex(), watch.AddressHex())
+	assert.Equal(t, wallet.KeyOrigin(), watch.KeyOrigin())
+
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watchXPub, err := watch.ExtendedPublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, xpub, watchXPub)
+	_, err = watch.ExtendedPrivateKey()
+	assert.ErrorIs(t, err, ErrWatchOnly)
+	_, err = watch.PrivateKey()
+	assert.ErrorIs(t, err, ErrWatchOnly)
+
+	// The watch-only wallet still derives both chains of the account.
+	change, err := wallet.At(1, 3)
+	assert.NoError(t, err)
+	watchChange, err := watch.At(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, change.AddressHex(), watchChange.AddressHex())
+}
+
 func Test_New_with_mainnet(t *testing.T) {
 	mnemonic := "romance trash engine during cliff verify tunnel memory vault chief fluid fox"
 	root, err := New(&Config{
@@ -221,3 +292,340 @@ func Test_New_with_testnet(t *testing.T) {
 	assert.Equal(t, wallet.Path(), `m/44'/1'/0'/0/0`, "Derived path mismatch")
 	assert.Equal(t, wallet.AddressHex(), "mouZ8gxQsiexTYihidSEiRmQGCm2AauaXF", "Derived address hex mismatch")
 }
+
+func Test_Derive_FullState(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+	wallet, err := root.Derive(3)
+	assert.NoError(t, err)
+
+	fresh, err := New(&Config{Mnemonic: testMnemonic, Path: wallet.Path(), Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.NotNil(t, wallet.PublicKey())
+	assert.True(t, fresh.PublicKey().IsEqual(wallet.PublicKey()))
+	assert.Equal(t, fresh.AddressHex(), wallet.AddressHex())
+	assert.Equal(t, root.Mnemonic(), wallet.Mnemonic())
+	assert.Same(t, root, wallet.Parent())
+	assert.Nil(t, root.Parent())
+
+	// Hardened children keep a parseable path.
+	hardened, err := root.Derive(uint32(hdkeychain.HardenedKeyStart + 5))
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/5'`, hardened.Path())
+	fresh, err = New(&Config{Mnemonic: testMnemonic, Path: hardened.Path(), Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, fresh.AddressHex(), hardened.AddressHex())
+}
+
+func Test_Derive_Lineage(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+	assert.Equal(t, uint32(0), root.ChildIndex())
+	child, err := root.Derive(hdkeychain.HardenedKeyStart + 5)
+	assert.NoError(t, err)
+	grandchild, err := child.Derive(7)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(hdkeychain.HardenedKeyStart+5), child.ChildIndex())
+	assert.Equal(t, uint32(7), grandchild.ChildIndex())
+	assert.Same(t, child, grandchild.Parent())
+	assert.Same(t, root, grandchild.Parent().Parent())
+	assert.Equal(t, testMnemonic, grandchild.Mnemonic())
+
+	// Children of a copy without mnemonic do not carry it.
+	subtree := child.WithoutMnemonic()
+	assert.Empty(t, subtree.Mnemonic())
+	assert.Equal(t, testMnemonic, child.Mnemonic())
+	leaf, err := subtree.Derive(7)
+	assert.NoError(t, err)
+	assert.Empty(t, leaf.Mnemonic())
+	assert.Equal(t, grandchild.AddressInfo(), leaf.AddressInfo())
+}
+
+func Test_DeriveChild(t *testing.T) {
+	root := createKnownWallet(t, NetworkMainnet)
+
+	hardened, err := root.DeriveChild(3, Hardened)
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/3'`, hardened.Path())
+	fresh, err := New(&Config{Mnemonic: testMnemonic, Path: hardened.Path(), Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, fresh.AddressHex(), hardened.AddressHex())
+
+	normal, err := root.DeriveChild(3, NonHardened)
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/3`, normal.Path())
+	assert.NotEqual(t, hardened.AddressHex(), normal.AddressHex())
+
+	_, err = root.DeriveChild(hdkeychain.HardenedKeyStart, Hardened)
+	assert.ErrorIs(t, err, ErrIndexRange)
+
+	// Watch-only wallets only derive normal children.
+	xpub, err := root.extendedKey.Neuter()
+	assert.NoError(t, err)
+	watch, err := newWalletFromKey(xpub, root.Path(), root.params, NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = watch.DeriveChild(3, Hardened)
+	assert.ErrorIs(t, err, ErrHardenedPublic)
+	child, err := watch.DeriveChild(3, NonHardened)
+	assert.NoError(t, err)
+	assert.Equal(t, normal.AddressHex(), child.AddressHex())
+
+	child, err = root.Derive(uint(3))
+	assert.NoError(t, err)
+	assert.Equal(t, normal.AddressHex(), child.AddressHex())
+}
+
+func Test_Derive_WatchOnly(t *testing.T) {
+	known := createKnownWallet(t, NetworkMainnet)
+	xpub, err := known.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.True(t, watch.IsWatchOnly())
+
+	for _, index := range []uint32{0, 7, hdkeychain.HardenedKeyStart - 1} {
+		child, err := watch.Derive(index)
+		assert.NoError(t, err)
+		assert.True(t, child.IsWatchOnly())
+		expected, err := known.Derive(index)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.AddressHex(), child.AddressHex())
+	}
+
+	_, err = watch.Derive(hdkeychain.HardenedKeyStart)
+	assert.ErrorIs(t, err, ErrHardenedPublic)
+	_, err = newWallet(watch.extendedKey, nil, "m/1/2'", NetworkMainnet)
+	assert.ErrorIs(t, err, ErrHardenedPublic)
+	_, err = deriveKey(watch.extendedKey, hdkeychain.HardenedKeyStart+3)
+	assert.ErrorIs(t, err, ErrHardenedPublic)
+}
+
+func Test_DeriveHardened_DerivePath(t *testing.T) {
+	master, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+
+	account, err := master.DeriveHardened(0)
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'`, account.Path())
+
+	address, err := account.DerivePath("0/0")
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0/0`, address.Path())
+	assert.Equal(t, account, address.Parent().Parent())
+
+	known := createKnownWallet(t, NetworkMainnet)
+	sub, err := master.DerivePath("0h/0")
+	assert.NoError(t, err)
+	assert.Equal(t, known.AddressHex(), sub.AddressHex())
+	sub, err = master.DerivePath("0'/0/5")
+	assert.NoError(t, err)
+	child, err := known.Derive(5)
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressHex(), sub.AddressHex())
+
+	_, err = master.DerivePath("")
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	_, err = master.DerivePath("m/0")
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	xpub, err := known.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = watch.DerivePath("1/2'")
+	assert.ErrorIs(t, err, ErrHardenedPublic)
+}
+
+func Test_CompletePath(t *testing.T) {
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/0'`, Network: NetworkMainnet, CompletePath: true})
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/0`, wallet.Path())
+	assert.Equal(t, createKnownWallet(t, NetworkMainnet).AddressHex(), wallet.AddressHex())
+
+	wallet, err = New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/0'/1`, Network: NetworkMainnet, CompletePath: true})
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/1`, wallet.Path())
+
+	wallet, err = New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/0'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'`, wallet.Path())
+
+	wallet, err = NewWalletBuilder().WithMnemonic(testMnemonic).WithPath(`m/84'/0'/0'`).WithCompletePath(true).WithNetwork(NetworkMainnet).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/84'/0'/0'/0`, wallet.Path())
+}
+
+func Test_At(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+
+	change, err := wallet.At(1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/1/5`, change.Path())
+	assert.Equal(t, testMnemonic, change.Mnemonic())
+	fresh, err := New(&Config{Mnemonic: testMnemonic, Path: change.Path(), Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, fresh.AddressHex(), change.AddressHex())
+
+	receive, err := change.At(0, 2)
+	assert.NoError(t, err)
+	child, err := wallet.Derive(2)
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressHex(), receive.AddressHex())
+
+	_, err = wallet.At(hdkeychain.HardenedKeyStart, 0)
+	assert.ErrorIs(t, err, ErrIndexRange)
+
+	short, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	_, err = short.At(0, 0)
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func Test_NewFromSeed(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	seed := bip39.NewSeed(testMnemonic, "")
+
+	fromSeed, err := NewFromSeed(seed, &Config{Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.Path(), fromSeed.Path())
+	assert.Equal(t, wallet.AddressHex(), fromSeed.AddressHex())
+	assert.Empty(t, fromSeed.Mnemonic())
+	assert.False(t, fromSeed.IsWatchOnly())
+
+	backend := newFakeBackend()
+	fromSeed, err = NewFromSeed(seed, &Config{Path: `m/44'/0'/0'`, Network: NetworkMainnet, CompletePath: true, Backend: backend})
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), fromSeed.AddressHex())
+	assert.Equal(t, ChainBackend(backend), fromSeed.Backend())
+
+	_, err = NewFromSeed(seed[:8], &Config{Network: NetworkMainnet})
+	assert.ErrorIs(t, err, hdkeychain.ErrInvalidSeedLen)
+	_, err = NewFromSeed(seed, &Config{Network: "regtest"})
+	assert.ErrorIs(t, err, ErrUnsupportedNet)
+}
+
+func Test_NewFromExtendedPrivateKey(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+
+	fromMaster, err := NewFromExtendedPrivateKey(wallet.root.String(), NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.Path(), fromMaster.Path())
+	assert.Equal(t, wallet.AddressHex(), fromMaster.AddressHex())
+	assert.False(t, fromMaster.IsWatchOnly())
+
+	account, err := New(&Config{Mnemonic: testMnemonic, Path: `m/44'/0'/0'`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	fromAccount, err := NewFromExtendedPrivateKey(account.extendedKey.String(), NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, "m", fromAccount.Path())
+	child, err := fromAccount.DerivePath("0/3")
+	assert.NoError(t, err)
+	expected, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.AddressHex(), child.AddressHex())
+	_, err = child.PrivateKey()
+	assert.NoError(t, err)
+
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+	_, err = NewFromExtendedPrivateKey(xpub, NetworkMainnet)
+	assert.ErrorIs(t, err, ErrXPrvPublic)
+	_, err = NewFromExtendedPrivateKey(wallet.root.String(), NetworkTestnet)
+	assert.ErrorIs(t, err, ErrXPrvNetwork)
+	_, err = NewFromExtendedPrivateKey("xprv123", NetworkMainnet)
+	assert.Error(t, err)
+}
+
+func Test_NewFromExtendedPublicKey(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.True(t, watch.IsWatchOnly())
+	assert.False(t, wallet.IsWatchOnly())
+	assert.Equal(t, "m", watch.Path())
+	assert.Equal(t, wallet.AddressHex(), watch.AddressHex())
+	valid, err := watch.ValidateAddress(wallet.AddressHex())
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	watchChild, err := watch.Derive(4)
+	assert.NoError(t, err)
+	assert.Equal(t, "m/4", watchChild.Path())
+	child, err := wallet.Derive(4)
+	assert.NoError(t, err)
+	assert.Equal(t, child.AddressHex(), watchChild.AddressHex())
+	_, err = watch.DeriveChild(4, Hardened)
+	assert.ErrorIs(t, err, ErrHardenedPublic)
+
+	_, err = watch.PrivateKey()
+	assert.ErrorIs(t, err, ErrWatchOnly)
+	_, err = watchChild.PrivateKeyWIF(false)
+	assert.ErrorIs(t, err, ErrWatchOnly)
+
+	// Private material is dropped.
+	xprv := wallet.extendedKey.String()
+	watch, err = NewFromExtendedPublicKey(xprv, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.True(t, watch.IsWatchOnly())
+
+	_, err = NewFromExtendedPublicKey(xpub, NetworkTestnet)
+	assert.ErrorIs(t, err, ErrXPubNetwork)
+	_, err = NewFromExtendedPublicKey("xpub123", NetworkMainnet)
+	assert.Error(t, err)
+}
+
+func Test_Addresses(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	addresses, err := wallet.Addresses(10, 3)
+	assert.NoError(t, err)
+	assert.Len(t, addresses, 3)
+	for i, address := range addresses {
+		child, err := wallet.Derive(10 + i)
+		assert.NoError(t, err)
+		assert.Equal(t, child.AddressHex(), address)
+	}
+
+	// The addresses of uncompressed keys and of BIP84 paths are those of
+	// the children.
+	uncompressed, err := NewWallet(testMnemonic, WithUncompressedKeys())
+	assert.NoError(t, err)
+	segwit, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, Purpose: PurposeNativeSegwit})
+	assert.NoError(t, err)
+	for _, parent := range []*Wallet{uncompressed, segwit} {
+		addresses, err = parent.Addresses(0, 1)
+		assert.NoError(t, err)
+		child, err := parent.Derive(0)
+		assert.NoError(t, err)
+		assert.Equal(t, child.AddressHex(), addresses[0])
+	}
+
+	addresses, err = wallet.Addresses(0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, addresses)
+	_, err = wallet.Addresses(hdkeychain.HardenedKeyStart-1, 2)
+	assert.ErrorIs(t, err, ErrIndexRange)
+}
+
+func BenchmarkDerive(b *testing.B) {
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := wallet.Derive(uint32(i) % hdkeychain.HardenedKeyStart); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAddressType(b *testing.B) {
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Path: `m/84'/0'/0'/0/7`, Network: NetworkMainnet})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wallet.AddressType()
+	}
+}
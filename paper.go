@@ -0,0 +1,122 @@
+package p2pkh
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// PaperWalletOptions configures the generation of a paper wallet.
+type PaperWalletOptions struct {
+	// Passphrase, when set, encrypts the private key with BIP38.
+	Passphrase string
+	// Label is an optional title printed on the wallet.
+	Label string
+}
+
+// PaperWallet is the printable data of a wallet address and its key.
+type PaperWallet struct {
+	Label       string  `json:"label,omitempty"`
+	Network     Network `json:"network"`
+	Address     string  `json:"address"`
+	Path        string  `json:"path"`
+	Fingerprint string  `json:"fingerprint"`
+	// PrivateKey is the key in WIF, or BIP38 encrypted when Encrypted is set.
+	PrivateKey string `json:"privateKey"`
+	Encrypted  bool   `json:"encrypted"`
+	// AddressQR and PrivateKeyQR are the payloads of the QR codes printed on
+	// the wallet: a BIP21 URI and the private key.
+	AddressQR    string `json:"addressQR"`
+	PrivateKeyQR string `json:"privateKeyQR"`
+}
+
+// PaperWallet returns the data of a paper wallet holding the wallet key.
+func (s *Wallet) PaperWallet(opts *PaperWalletOptions) (*PaperWallet, error) {
+	if opts == nil {
+		opts = &PaperWalletOptions{}
+	}
+	wif, err := s.wif(true)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := s.masterFingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	paper := &PaperWallet{
+		Label:       opts.Label,
+		Network:     s.network,
+		Address:     s.AddressInfo().String(),
+		Path:        s.path,
+		Fingerprint: fmt.Sprintf("%08x", fingerprint),
+		PrivateKey:  wif.String(),
+	}
+	if opts.Passphrase != "" {
+		if paper.PrivateKey, err = EncryptBIP38(wif, opts.Passphrase, s.network); err != nil {
+			return nil, err
+		}
+		paper.Encrypted = true
+	}
+	paper.AddressQR = "bitcoin:" + paper.Address
+	paper.PrivateKeyQR = paper.PrivateKey
+	return paper, nil
+}
+
+// paperQRSize is the printed size of the QR codes, in SVG user units.
+const paperQRSize = 240
+
+// SVG renders the paper wallet as a printable SVG image: the address QR code
+// on the left, the private key one on the right.
+func (s *PaperWallet) SVG() ([]byte, error) {
+	var buf bytes.Buffer
+	const width, height = 2*paperQRSize + 60, paperQRSize + 140
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", width, height)
+
+	title := "Bitcoin paper wallet"
+	if s.Label != "" {
+		title = s.Label
+	}
+	fmt.Fprintf(&buf, `<text x="20" y="30" font-family="sans-serif" font-size="18">%s</text>`+"\n", html.EscapeString(title))
+
+	keyTitle := "Private key (WIF)"
+	if s.Encrypted {
+		keyTitle = "Private key (BIP38 encrypted)"
+	}
+	for i, part := range []struct{ title, payload, text string }{
+		{"Address", s.AddressQR, s.Address},
+		{keyTitle, s.PrivateKeyQR, s.PrivateKey},
+	} {
+		x := 20 + i*(paperQRSize+20)
+		fmt.Fprintf(&buf, `<text x="%d" y="60" font-family="sans-serif" font-size="14">%s</text>`+"\n", x, html.EscapeString(part.title))
+		if err := writeQRCodeSVG(&buf, part.payload, x, 70); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-family="monospace" font-size="8">%s</text>`+"\n", x, 70+paperQRSize+15, html.EscapeString(part.text))
+	}
+
+	fmt.Fprintf(&buf, `<text x="20" y="%d" font-family="monospace" font-size="10">[%s] %s %s</text>`+"\n",
+		height-20, s.Fingerprint, html.EscapeString(s.Path), s.Network)
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), nil
+}
+
+// writeQRCodeSVG writes the QR code of a payload as a SVG path at (x, y).
+func writeQRCodeSVG(buf *bytes.Buffer, payload string, x, y int) error {
+	code, err := qrEncode(payload)
+	if err != nil {
+		return err
+	}
+	scale := float64(paperQRSize) / float64(code.Size)
+	fmt.Fprintf(buf, `<path transform="translate(%d %d) scale(%.4f)" fill="#000" d="`, x, y, scale)
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; col++ {
+			if code.Black(col, row) {
+				fmt.Fprintf(buf, "M%d %dh1v1h-1z", col, row)
+			}
+		}
+	}
+	buf.WriteString(`"/>` + "\n")
+	return nil
+}
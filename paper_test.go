@@ -0,0 +1,42 @@
+package p2pkh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PaperWallet(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	wif, err := wallet.PrivateKey()
+	assert.NoError(t, err)
+
+	paper, err := wallet.PaperWallet(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), paper.Address)
+	assert.Equal(t, wallet.Path(), paper.Path)
+	assert.Equal(t, wif, paper.PrivateKey)
+	assert.False(t, paper.Encrypted)
+	assert.Equal(t, "bitcoin:"+wallet.AddressHex(), paper.AddressQR)
+	assert.Len(t, paper.Fingerprint, 8)
+
+	svg, err := paper.SVG()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(svg), "<svg"))
+	assert.Contains(t, string(svg), wallet.AddressHex())
+	assert.Contains(t, string(svg), "Private key (WIF)")
+
+	encrypted, err := wallet.PaperWallet(&PaperWalletOptions{Passphrase: "correct horse", Label: "Savings <2026>"})
+	assert.NoError(t, err)
+	assert.True(t, encrypted.Encrypted)
+	assert.True(t, strings.HasPrefix(encrypted.PrivateKey, "6P"))
+	decrypted, err := DecryptBIP38(encrypted.PrivateKey, "correct horse", NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, wif, decrypted.String())
+
+	svg, err = encrypted.SVG()
+	assert.NoError(t, err)
+	assert.Contains(t, string(svg), "Savings &lt;2026&gt;")
+	assert.NotContains(t, string(svg), wif)
+}
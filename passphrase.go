@@ -0,0 +1,215 @@
+package p2pkh
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	ErrPassphraseWeak Error = "passphrase is too weak"
+
+	// minPassphraseLength is the length below which a passphrase is flagged.
+	minPassphraseLength = 12
+)
+
+// PassphraseScore rates the strength of a passphrase, from PassphraseVeryWeak
+// to PassphraseVeryStrong.
+type PassphraseScore int
+
+const (
+	PassphraseVeryWeak PassphraseScore = iota
+	PassphraseWeak
+	PassphraseFair
+	PassphraseStrong
+	PassphraseVeryStrong
+)
+
+// passphraseScoreBits are the minimum entropies, in bits, of each score above
+// PassphraseVeryWeak.
+var passphraseScoreBits = []float64{28, 40, 60, 80}
+
+// String returns the name of the score.
+func (s PassphraseScore) String() string {
+	switch s {
+	case PassphraseVeryWeak:
+		return "very weak"
+	case PassphraseWeak:
+		return "weak"
+	case PassphraseFair:
+		return "fair"
+	case PassphraseStrong:
+		return "strong"
+	case PassphraseVeryStrong:
+		return "very strong"
+	}
+	return fmt.Sprintf("PassphraseScore(%d)", int(s))
+}
+
+// commonPassphrases are frequently used passwords, worth no entropy at all.
+var commonPassphrases = map[string]bool{
+	"123456": true, "1234": true, "12345678": true, "123456789": true, "1234567890": true,
+	"password": true, "passw0rd": true, "qwerty": true, "azerty": true, "abc123": true,
+	"111111": true, "000000": true, "letmein": true, "welcome": true, "iloveyou": true,
+	"admin": true, "monkey": true, "dragon": true, "bitcoin": true, "satoshi": true,
+	"trustno1": true, "hodl": true, "tothemoon": true, "secret": true, "changeme": true,
+}
+
+// keyboardRows are the keyboard sequences counted as predictable.
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "azertyuiop", "qsdfghjklm", "1234567890"}
+
+// PassphraseStrength is the estimated strength of a passphrase or password.
+type PassphraseStrength struct {
+	// Entropy is the estimated entropy, in bits.
+	Entropy float64
+	Score   PassphraseScore
+	// Feedback lists actionable advice to improve the passphrase.
+	Feedback []string
+}
+
+// EstimatePassphraseStrength estimates the entropy of a BIP39 passphrase, or
+// of a password encrypting keys. The estimate is conservative: common
+// passwords, repeated characters and sequences add little entropy, while
+// passphrases made of BIP39 words are counted as 11 bits per word.
+func EstimatePassphraseStrength(passphrase string) *PassphraseStrength {
+	strength := &PassphraseStrength{}
+	if passphrase == "" {
+		strength.Feedback = []string{"set a passphrase"}
+		return strength
+	}
+	if commonPassphrases[strings.ToLower(passphrase)] {
+		strength.Entropy = 1
+		strength.Feedback = []string{"this is a commonly used password, choose another one"}
+		return strength
+	}
+
+	if words := strings.Fields(passphrase); len(words) > 1 && bip39Words(words) {
+		strength.Entropy = float64(len(words)) * 11
+		if strength.score() < PassphraseStrong {
+			strength.Feedback = append(strength.Feedback, "add more random words")
+		}
+		strength.Score = strength.score()
+		return strength
+	}
+
+	runes := []rune(passphrase)
+	bits := math.Log2(float64(passphrasePoolSize(runes)))
+	predictable := false
+	for i, r := range runes {
+		if i > 0 && (r == runes[i-1] || sequential(runes[i-1], r)) {
+			strength.Entropy++
+			predictable = true
+			continue
+		}
+		strength.Entropy += bits
+	}
+	strength.Score = strength.score()
+
+	if len(runes) < minPassphraseLength {
+		strength.Feedback = append(strength.Feedback, fmt.Sprintf("use at least %d characters", minPassphraseLength))
+	}
+	if predictable {
+		strength.Feedback = append(strength.Feedback, "avoid repeated characters and sequences")
+	}
+	if strength.Score < PassphraseStrong && passphraseClasses(runes) < 3 {
+		strength.Feedback = append(strength.Feedback, "mix upper and lower case letters, digits and symbols, or use several random words")
+	}
+	return strength
+}
+
+// CheckPassphrase returns an error when a passphrase is rated below minScore,
+// listing the feedback to improve it.
+func CheckPassphrase(passphrase string, minScore PassphraseScore) error {
+	strength := EstimatePassphraseStrength(passphrase)
+	if strength.Score >= minScore {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrPassphraseWeak, strength.Score, strings.Join(strength.Feedback, ", "))
+}
+
+// score returns the score of the estimated entropy.
+func (s *PassphraseStrength) score() PassphraseScore {
+	score := PassphraseVeryWeak
+	for _, bits := range passphraseScoreBits {
+		if s.Entropy >= bits {
+			score++
+		}
+	}
+	return score
+}
+
+// bip39Words reports whether every word belongs to the BIP39 word list.
+func bip39Words(words []string) bool {
+	for _, word := range words {
+		if _, ok := bip39.GetWordIndex(strings.ToLower(word)); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// passphrasePoolSize returns the size of the alphabet the characters of a
+// passphrase are drawn from.
+func passphrasePoolSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			size += class.size
+		}
+	}
+	return size
+}
+
+// passphraseClasses returns the number of character classes of a passphrase.
+func passphraseClasses(runes []rune) int {
+	classes := make(map[int]bool)
+	for _, r := range runes {
+		switch {
+		case unicode.IsLower(r):
+			classes[0] = true
+		case unicode.IsUpper(r):
+			classes[1] = true
+		case unicode.IsDigit(r):
+			classes[2] = true
+		default:
+			classes[3] = true
+		}
+	}
+	return len(classes)
+}
+
+// sequential reports whether r follows prev in the alphabet, in either
+// direction, or on a keyboard row.
+func sequential(prev, r rune) bool {
+	prev, r = unicode.ToLower(prev), unicode.ToLower(r)
+	if r == prev+1 || r == prev-1 {
+		return true
+	}
+	for _, row := range keyboardRows {
+		if i := strings.IndexRune(row, prev); i >= 0 && i+1 < len(row) && rune(row[i+1]) == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EstimatePassphraseStrength(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		score      PassphraseScore
+	}{
+		{"Empty", "", PassphraseVeryWeak},
+		{"Common", "1234", PassphraseVeryWeak},
+		{"CommonUpperCase", "PASSWORD", PassphraseVeryWeak},
+		{"Repeated", "aaaaaaaaaaaa", PassphraseVeryWeak},
+		{"Sequence", "abcdefghijkl", PassphraseVeryWeak},
+		{"Keyboard", "qwertyuiop12", PassphraseVeryWeak},
+		{"ShortMixed", "Tr0ub4d&", PassphraseFair},
+		{"LongMixed", "Tr0ub4d&3-Xq!9zP", PassphraseVeryStrong},
+		{"FewWords", "abandon ability", PassphraseVeryWeak},
+		{"ManyWords", "orbit velvet canyon tiger ocean lumber", PassphraseStrong},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			strength := EstimatePassphraseStrength(test.passphrase)
+			assert.Equal(t, test.score, strength.Score, "entropy %.1f", strength.Entropy)
+			if test.score < PassphraseStrong {
+				assert.NotEmpty(t, strength.Feedback)
+			}
+		})
+	}
+
+	assert.Contains(t, EstimatePassphraseStrength("1234").Feedback[0], "commonly used")
+	assert.Contains(t, EstimatePassphraseStrength("aaaa").Feedback, "avoid repeated characters and sequences")
+	assert.Equal(t, "very strong", PassphraseVeryStrong.String())
+}
+
+func Test_CheckPassphrase(t *testing.T) {
+	assert.NoError(t, CheckPassphrase("Tr0ub4d&3-Xq!9zP", PassphraseStrong))
+	err := CheckPassphrase("1234", PassphraseFair)
+	assert.ErrorIs(t, err, ErrPassphraseWeak)
+	assert.ErrorContains(t, err, "very weak")
+}
@@ -0,0 +1,191 @@
+package p2pkh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// Levels of a BIP44-style path: m / purpose' / coin_type' / account' /
+// change / address_index.
+const (
+	purposeLevel = iota
+	coinTypeLevel
+	accountLevel
+	chainLevel
+	indexLevel
+)
+
+const (
+	ErrUnsupportedPurpose Error = "unsupported derivation purpose"
+	ErrPurposeMismatch    Error = "derivation path does not match the purpose"
+)
+
+// Purpose is the purpose level of a BIP44-style path, which selects the type
+// of the addresses of the account.
+type Purpose uint32
+
+const (
+	// PurposeLegacy is BIP44, P2PKH addresses (1...).
+	PurposeLegacy Purpose = 44
+	// PurposeNestedSegwit is BIP49, P2SH-P2WPKH addresses (3...).
+	PurposeNestedSegwit Purpose = 49
+	// PurposeNativeSegwit is BIP84, P2WPKH addresses (bc1q...).
+	PurposeNativeSegwit Purpose = 84
+	// PurposeTaproot is BIP86, P2TR addresses (bc1p...).
+	PurposeTaproot Purpose = 86
+)
+
+// AddressType returns the address type of the purpose.
+func (s Purpose) AddressType() (AddressType, error) {
+	for addrType, purpose := range purposes {
+		if Purpose(purpose) == s {
+			return addrType, nil
+		}
+	}
+	return "", ErrUnsupportedPurpose
+}
+
+// purposes are the BIP44-style purposes of the single-key address types:
+// BIP44, BIP49, BIP84 and BIP86.
+var purposes = map[AddressType]uint32{
+	AddressTypeP2PKH:  44,
+	AddressTypeP2SH:   49,
+	AddressTypeP2WPKH: 84,
+	AddressTypeP2TR:   86,
+}
+
+// DerivationPath returns the standard path of the external chain of an
+// account for an address type, e.g. m/86'/0'/0'/0 for BIP86 taproot
+// addresses on mainnet.
+func DerivationPath(addrType AddressType, network Network, account uint32) (string, error) {
+	purpose, ok := purposes[addrType]
+	if !ok {
+		return "", ErrUnsupportedAddressType
+	}
+	if account >= hdkeychain.HardenedKeyStart {
+		return "", ErrIndexRange
+	}
+	coinType, err := network.CoinType()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("m/%d'/%d'/%d'/0", purpose, coinType, account), nil
+}
+
+// Path is a BIP32 derivation path: the indexes of its levels below the master
+// key, hardened ones offset by hdkeychain.HardenedKeyStart. DerivationPath
+// names the function returning the standard paths, hence the shorter name.
+type Path []uint32
+
+// ParsePath parses a derivation path such as "m/84'/0'/0'/0", whose hardened
+// levels are marked with "'" or "h" as in "m/84h/0h/0h/0".
+func ParsePath(path string) (Path, error) {
+	levels := strings.Split(path, "/")
+	if levels[0] != "m" {
+		return nil, ErrInvalidPath
+	}
+	return parsePathLevels(levels[1:])
+}
+
+// String formats the path as "m/84'/0'/0'/0", which ParsePath parses back.
+func (s Path) String() string {
+	return "m" + formatPathLevels(s)
+}
+
+// purposePath returns the path of a wallet of the purpose: the default path
+// of the network for the purpose when path is empty, or else path, which must
+// then be of the purpose. A zero purpose accepts any path, and defaults to
+// PurposeLegacy.
+func purposePath(purpose Purpose, network Network, path string) (string, error) {
+	if purpose == 0 {
+		if path != "" {
+			return path, nil
+		}
+		purpose = PurposeLegacy
+	}
+	addrType, err := purpose.AddressType()
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return DerivationPath(addrType, network, 0)
+	}
+	levels, err := ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+	if len(levels) == 0 || levels[purposeLevel] != hdkeychain.HardenedKeyStart+uint32(purpose) {
+		return "", ErrPurposeMismatch
+	}
+	return path, nil
+}
+
+// AddressType returns the type of the addresses the purpose of the wallet
+// path selects, e.g. AddressTypeP2WPKH for m/84'/0'/0'/0, and
+// AddressTypeP2PKH for paths of another or no purpose. Wallets imported from
+// a keystore of another script type, such as the zpub of an Electrum
+// standard wallet, are of the type of the keystore.
+func (s *Wallet) AddressType() AddressType {
+	if s.addrType != "" {
+		return s.addrType
+	}
+	levels := s.levels
+	if len(levels) == 0 || levels[purposeLevel] < hdkeychain.HardenedKeyStart {
+		return AddressTypeP2PKH
+	}
+	addrType, err := Purpose(levels[purposeLevel] - hdkeychain.HardenedKeyStart).AddressType()
+	if err != nil {
+		return AddressTypeP2PKH
+	}
+	return addrType
+}
+
+// PurposeAddress returns the address of the wallet key of the type its path
+// purpose selects, e.g. the native SegWit address of a BIP84 wallet. It is
+// the address AddressInfo returns.
+func (s *Wallet) PurposeAddress() Address {
+	return s.AddressInfo()
+}
+
+// pathLevel returns a level of the path of the wallet without its hardened
+// flag, and false when the path does not have that level.
+func (s *Wallet) pathLevel(level int) (uint32, bool) {
+	levels := s.levels
+	if len(levels) <= level {
+		return 0, false
+	}
+	n := levels[level]
+	if n >= hdkeychain.HardenedKeyStart {
+		n -= hdkeychain.HardenedKeyStart
+	}
+	return n, true
+}
+
+// Purpose returns the purpose level of the path, e.g. 44 for m/44'/0'/0'/0.
+func (s *Wallet) Purpose() (uint32, bool) {
+	return s.pathLevel(purposeLevel)
+}
+
+// CoinType returns the coin type level of the path, 0 for bitcoin and 1 for
+// testnet.
+func (s *Wallet) CoinType() (uint32, bool) {
+	return s.pathLevel(coinTypeLevel)
+}
+
+// Account returns the account level of the path.
+func (s *Wallet) Account() (uint32, bool) {
+	return s.pathLevel(accountLevel)
+}
+
+// Chain returns the chain level of the path, 0 for the external chain and 1
+// for the internal (change) chain.
+func (s *Wallet) Chain() (uint32, bool) {
+	return s.pathLevel(chainLevel)
+}
+
+// Index returns the address index level of the path.
+func (s *Wallet) Index() (uint32, bool) {
+	return s.pathLevel(indexLevel)
+}
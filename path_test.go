@@ -0,0 +1,220 @@
+package p2pkh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PathLevels(t *testing.T) {
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Path: `m/84'/1'/2'/1/7`, Network: NetworkTestnet})
+	assert.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		level  func() (uint32, bool)
+		expect uint32
+	}{
+		{"Purpose", wallet.Purpose, 84},
+		{"CoinType", wallet.CoinType, 1},
+		{"Account", wallet.Account, 2},
+		{"Chain", wallet.Chain, 1},
+		{"Index", wallet.Index, 7},
+	} {
+		n, ok := test.level()
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.expect, n, test.name)
+	}
+
+	// The default wallet stops at the chain level.
+	wallet = createKnownWallet(t, NetworkMainnet)
+	chain, ok := wallet.Chain()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0), chain)
+	_, ok = wallet.Index()
+	assert.False(t, ok)
+
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	index, ok := child.Index()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(3), index)
+}
+
+func Test_DerivationPath(t *testing.T) {
+	for _, test := range []struct {
+		addrType AddressType
+		network  Network
+		account  uint32
+		expect   string
+	}{
+		{AddressTypeP2PKH, NetworkMainnet, 0, `m/44'/0'/0'/0`},
+		{AddressTypeP2SH, NetworkTestnet, 1, `m/49'/1'/1'/0`},
+		{AddressTypeP2WPKH, NetworkMainnet, 2, `m/84'/0'/2'/0`},
+		{AddressTypeP2TR, NetworkTestnet, 0, `m/86'/1'/0'/0`},
+	} {
+		path, err := DerivationPath(test.addrType, test.network, test.account)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expect, path)
+	}
+
+	_, err := DerivationPath(AddressTypeP2WSH, NetworkMainnet, 0)
+	assert.ErrorIs(t, err, ErrUnsupportedAddressType)
+	_, err = DerivationPath(AddressTypeP2TR, "regtest", 0)
+	assert.ErrorIs(t, err, ErrUnsupportedNet)
+	_, err = DerivationPath(AddressTypeP2TR, NetworkMainnet, 1<<31)
+	assert.ErrorIs(t, err, ErrIndexRange)
+}
+
+func Test_Purpose_AddressType(t *testing.T) {
+	tests := map[Purpose]AddressType{
+		PurposeLegacy:       AddressTypeP2PKH,
+		PurposeNestedSegwit: AddressTypeP2SH,
+		PurposeNativeSegwit: AddressTypeP2WPKH,
+		PurposeTaproot:      AddressTypeP2TR,
+	}
+	for purpose, expected := range tests {
+		addrType, err := purpose.AddressType()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, addrType)
+	}
+	_, err := Purpose(45).AddressType()
+	assert.ErrorIs(t, err, ErrUnsupportedPurpose)
+}
+
+func Test_Config_Purpose(t *testing.T) {
+	// BIP84 test vector of the "abandon ... about" mnemonic.
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	wallet, err := New(&Config{Mnemonic: mnemonic, Network: NetworkMainnet, Purpose: PurposeNativeSegwit})
+	assert.NoError(t, err)
+	assert.Equal(t, `m/84'/0'/0'/0`, wallet.Path())
+	assert.Equal(t, AddressTypeP2WPKH, wallet.AddressType())
+	child, err := wallet.Derive(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", child.PurposeAddress().String())
+
+	wallet, err = New(&Config{Mnemonic: mnemonic, Network: NetworkTestnet, Purpose: PurposeTaproot})
+	assert.NoError(t, err)
+	assert.Equal(t, `m/86'/1'/0'/0`, wallet.Path())
+	assert.Equal(t, AddressTypeP2TR, wallet.PurposeAddress().Type())
+
+	// Without purpose, the path selects the address type.
+	wallet, err = New(&Config{Mnemonic: mnemonic, Path: `m/49'/0'/0'/0/0`, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, AddressTypeP2SH, wallet.AddressType())
+	assert.Equal(t, wallet.AddressNestedSegwit(), wallet.PurposeAddress())
+	wallet, err = New(&Config{Mnemonic: mnemonic, Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, AddressTypeP2PKH, wallet.AddressType())
+	assert.Equal(t, wallet.AddressInfo(), wallet.PurposeAddress())
+
+	_, err = New(&Config{Mnemonic: mnemonic, Path: `m/84'/0'/0'/0`, Network: NetworkMainnet, Purpose: PurposeLegacy})
+	assert.ErrorIs(t, err, ErrPurposeMismatch)
+	wallet, err = New(&Config{Mnemonic: mnemonic, Path: `m/84'/0'/0'/0`, Network: NetworkMainnet, Purpose: PurposeNativeSegwit})
+	assert.NoError(t, err)
+	assert.Equal(t, AddressTypeP2WPKH, wallet.AddressType())
+	_, err = New(&Config{Mnemonic: mnemonic, Network: NetworkMainnet, Purpose: Purpose(45)})
+	assert.ErrorIs(t, err, ErrUnsupportedPurpose)
+
+	wallet, err = NewWalletBuilder().WithMnemonic(mnemonic).WithNetwork(NetworkMainnet).WithPurpose(PurposeNestedSegwit).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/49'/0'/0'/0`, wallet.Path())
+}
+
+func Test_Purpose_Transaction(t *testing.T) {
+	// The address, backends and change of a BIP84 wallet are native SegWit.
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, Purpose: PurposeNativeSegwit})
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressP2WPKH(), wallet.AddressInfo())
+	assert.Equal(t, wallet.AddressInfo().String(), wallet.AddressHex())
+
+	backend := newFakeBackend()
+	_, script, err := ClassifyAddress(wallet.AddressHex(), NetworkMainnet)
+	assert.NoError(t, err)
+	utxo := UTXO{TxID: testTxID, Vout: 0, Amount: 100000, PkScript: script}
+	backend.utxos[wallet.AddressHex()] = []UTXO{utxo}
+	utxos, err := wallet.WithBackend(backend).UTXOs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []UTXO{utxo}, utxos)
+
+	draft, err := wallet.NewTransaction().
+		AddInput(utxo).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).
+		WithFeeRate(5).
+		Draft()
+	assert.NoError(t, err)
+	assert.Len(t, draft.Outputs, 2)
+	change := draft.Outputs[1]
+	assert.True(t, change.Change)
+	assert.True(t, strings.HasPrefix(change.Address, "bc1q"), change.Address)
+	assert.Equal(t, `m/84'/0'/0'/1/0`, change.Path)
+	vsize, err := EstimateVSize([]AddressType{AddressTypeP2WPKH}, []AddressType{AddressTypeP2PKH, AddressTypeP2WPKH})
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(vsize*5), draft.Fee)
+
+	// The PSBT recognizes the change, derived with the wallet address type.
+	_, err = wallet.CreatePSBT(draft)
+	assert.NoError(t, err)
+
+	// The inputs of each purpose are signed with their scheme.
+	for _, purpose := range []Purpose{PurposeLegacy, PurposeNestedSegwit, PurposeNativeSegwit, PurposeTaproot} {
+		wallet, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, Purpose: purpose})
+		assert.NoError(t, err)
+		child, err := wallet.Derive(2)
+		assert.NoError(t, err)
+		var utxos []UTXO
+		for vout, owner := range []*Wallet{wallet, child} {
+			_, script, err := ClassifyAddress(owner.AddressHex(), NetworkMainnet)
+			assert.NoError(t, err)
+			utxos = append(utxos, UTXO{TxID: testTxID, Vout: uint32(vout), Amount: 50000, PkScript: script})
+		}
+		rawTx, err := wallet.NewTransaction().
+			AddInput(utxos[0]).
+			AddInputAt(utxos[1], child.Path()).
+			AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).
+			WithFeeRate(5).
+			Sign()
+		assert.NoError(t, err, "purpose %d", purpose)
+		verifyTx(t, rawTx, utxos)
+	}
+}
+
+func Test_ParsePath(t *testing.T) {
+	path, err := ParsePath(`m/84'/0h/2'/1/7`)
+	assert.NoError(t, err)
+	assert.Equal(t, Path{0x80000054, 0x80000000, 0x80000002, 1, 7}, path)
+	assert.Equal(t, `m/84'/0'/2'/1/7`, path.String())
+
+	path, err = ParsePath(path.String())
+	assert.NoError(t, err)
+	assert.Equal(t, `m/84'/0'/2'/1/7`, path.String())
+
+	path, err = ParsePath("m")
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+	assert.Equal(t, "m", path.String())
+
+	for _, invalid := range []string{"", "84'/0'/0'", "m/", "m/44'//0", "m/x", "m/2147483648", "m/-1", "m/0''"} {
+		_, err := ParsePath(invalid)
+		assert.ErrorIs(t, err, ErrInvalidPath, invalid)
+	}
+}
+
+func FuzzParsePath(f *testing.F) {
+	f.Add(`m/84'/0h/2'/1/7`)
+	f.Add("m")
+	f.Add("m/2147483647'")
+	f.Add("m/44'//0")
+	f.Fuzz(func(t *testing.T, input string) {
+		path, err := ParsePath(input)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrInvalidPath)
+			return
+		}
+		again, err := ParsePath(path.String())
+		assert.NoError(t, err)
+		assert.Equal(t, path.String(), again.String())
+	})
+}
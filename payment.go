@@ -0,0 +1,116 @@
+package p2pkh
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	ErrPaymentURI         Error = "invalid BIP21 payment URI"
+	ErrPaymentURIRequired Error = "unsupported required BIP21 parameter"
+
+	// paymentURIScheme is the scheme of BIP21 URIs, case insensitive.
+	paymentURIScheme = "bitcoin:"
+)
+
+// PaymentRequest is a BIP21 payment request. Amount is expressed in
+// satoshis, zero leaving the amount to the payer.
+type PaymentRequest struct {
+	Address string
+	Amount  Amount
+	Label   string
+	Message string
+}
+
+// PaymentRequest returns a request for a payment to the wallet address.
+func (s *Wallet) PaymentRequest(amount Amount, label, message string) *PaymentRequest {
+	return &PaymentRequest{Address: s.AddressInfo().String(), Amount: amount, Label: label, Message: message}
+}
+
+// URI returns the BIP21 URI of the request, e.g.
+// "bitcoin:1QHT...?amount=0.001&label=Order%2042".
+func (s *PaymentRequest) URI() string {
+	var params []string
+	if s.Amount > 0 {
+		params = append(params, "amount="+s.Amount.BTC())
+	}
+	if s.Label != "" {
+		params = append(params, "label="+escapeURIParam(s.Label))
+	}
+	if s.Message != "" {
+		params = append(params, "message="+escapeURIParam(s.Message))
+	}
+	uri := paymentURIScheme + s.Address
+	if len(params) > 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+	return uri
+}
+
+// ParsePaymentURI parses a BIP21 URI, e.g. scanned from a QR code, paying
+// to an address of the network. The amount is in BTC; unknown parameters are
+// ignored, except those prefixed with "req-" which the payer must understand
+// and are reported as ErrPaymentURIRequired. Other failures are reported as
+// ErrPaymentURI.
+func ParsePaymentURI(uri string, network Network) (*PaymentRequest, error) {
+	uri = strings.TrimSpace(uri)
+	if len(uri) < len(paymentURIScheme) || !strings.EqualFold(uri[:len(paymentURIScheme)], paymentURIScheme) {
+		return nil, fmt.Errorf("%w: missing %q scheme", ErrPaymentURI, paymentURIScheme)
+	}
+	address, query, _ := strings.Cut(uri[len(paymentURIScheme):], "?")
+	parsed, err := ParseAddress(address, network)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPaymentURI, err)
+	}
+
+	request := &PaymentRequest{Address: parsed.String()}
+	seen := make(map[string]bool)
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate parameter %q", ErrPaymentURI, name)
+		}
+		seen[name] = true
+		if value, err = url.PathUnescape(value); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPaymentURI, err)
+		}
+		switch name {
+		case "amount":
+			if request.Amount, err = parseBTC(value); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrPaymentURI, err)
+			}
+		case "label":
+			request.Label = value
+		case "message":
+			request.Message = value
+		default:
+			if strings.HasPrefix(name, "req-") {
+				return nil, fmt.Errorf("%w: %q", ErrPaymentURIRequired, name)
+			}
+		}
+	}
+	return request, nil
+}
+
+// escapeURIParam percent-encodes the value of a BIP21 parameter, including
+// the "&" and "=" separating parameters.
+func escapeURIParam(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// formatBTC formats an amount of satoshis in BTC, without trailing zeros
+// nor floating point rounding.
+func formatBTC(sats int64) string {
+	sign := ""
+	if sats < 0 {
+		sign, sats = "-", -sats
+	}
+	btc := fmt.Sprintf("%s%d.%08d", sign, sats/btcutil.SatoshiPerBitcoin, sats%btcutil.SatoshiPerBitcoin)
+	return strings.TrimSuffix(strings.TrimRight(btc, "0"), ".")
+}
@@ -0,0 +1,66 @@
+package p2pkh
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PaymentRequest_URI(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	address := wallet.AddressInfo().String()
+
+	assert.Equal(t, "bitcoin:"+address, wallet.PaymentRequest(0, "", "").URI())
+	assert.Equal(t, "bitcoin:"+address+"?amount=0.001&label=Order%2042&message=Thanks%21",
+		wallet.PaymentRequest(100000, "Order 42", "Thanks!").URI())
+	assert.Equal(t, "bitcoin:"+address+"?amount=21000000", wallet.PaymentRequest(21000000*100000000, "", "").URI())
+	assert.Equal(t, "bitcoin:"+address+"?amount=0.00000001", wallet.PaymentRequest(1, "", "").URI())
+}
+
+func Test_FormatBTC(t *testing.T) {
+	assert.Equal(t, "0", formatBTC(0))
+	assert.Equal(t, "1.5", formatBTC(150000000))
+	assert.Equal(t, "-0.0001", formatBTC(-10000))
+}
+
+func Test_ParsePaymentURI(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	request := wallet.PaymentRequest(100000, "Order 42 & co", "Thanks=merci")
+	parsed, err := ParsePaymentURI(request.URI(), NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, request, parsed)
+
+	parsed, err = ParsePaymentURI("BITCOIN:BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4?amount=1.5&pj=https://example.com&lightning=lnbc1", NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, &PaymentRequest{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", Amount: 150000000}, parsed)
+
+	_, err = ParsePaymentURI(wallet.AddressInfo().String(), NetworkMainnet)
+	assert.ErrorIs(t, err, ErrPaymentURI)
+	_, err = ParsePaymentURI(request.URI(), NetworkTestnet)
+	assert.ErrorIs(t, err, ErrAddressNetwork)
+	for _, invalid := range []string{"bitcoin:", "bitcoin:?amount=1", "bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr?amount=1e3",
+		"bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr?amount=1&amount=2", "bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr?label=%zz"} {
+		_, err = ParsePaymentURI(invalid, NetworkMainnet)
+		assert.ErrorIs(t, err, ErrPaymentURI, invalid)
+	}
+	_, err = ParsePaymentURI("bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr?req-somethingyoudontunderstand=50", NetworkMainnet)
+	assert.ErrorIs(t, err, ErrPaymentURIRequired)
+}
+
+func FuzzParsePaymentURI(f *testing.F) {
+	f.Add("bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr?amount=0.001&label=Order%2042&message=Thanks%21")
+	f.Add("BITCOIN:BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4?amount=50")
+	f.Add("bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr?req-somethingyoudontunderstand=50")
+	f.Add("bitcoin:")
+	f.Fuzz(func(t *testing.T, uri string) {
+		request, err := ParsePaymentURI(uri, NetworkMainnet)
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrPaymentURI) || errors.Is(err, ErrPaymentURIRequired), err)
+			return
+		}
+		again, err := ParsePaymentURI(request.URI(), NetworkMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, request, again)
+	})
+}
@@ -0,0 +1,343 @@
+package p2pkh
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrPayoutEmpty   Error = "payout has no recipients"
+	ErrPayoutColumns Error = "payout rows must have an address, an amount and an optional reference"
+	ErrPayoutDust    Error = "payout amount is below the dust threshold"
+	ErrPayoutTooBig  Error = "payout does not fit in a transaction"
+
+	defaultPayoutMaxOutputs = 250
+	// defaultPayoutMaxVSize is the largest standard transaction.
+	defaultPayoutMaxVSize = 100000
+)
+
+// PayoutRecipient is a payment of a payout. Reference is an identifier of
+// the payment in the caller's books, e.g. an invoice number.
+type PayoutRecipient struct {
+	Address   string `json:"address"`
+	Amount    Amount `json:"amount"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// ReadPayoutCSV reads recipients from CSV rows "address,amount[,reference]",
+// with amounts parsed by ParseAmount, e.g. "0.001" or "100000 sat". A
+// header row is skipped.
+func ReadPayoutCSV(r io.Reader) ([]PayoutRecipient, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var recipients []PayoutRecipient
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("%w: line %d", ErrPayoutColumns, line)
+		}
+		if line == 1 && strings.EqualFold(record[1], "amount") {
+			continue
+		}
+		amount, err := ParseAmount(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		recipient := PayoutRecipient{Address: strings.TrimSpace(record[0]), Amount: amount}
+		if len(record) == 3 {
+			recipient.Reference = record[2]
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) == 0 {
+		return nil, ErrPayoutEmpty
+	}
+	return recipients, nil
+}
+
+// ReadPayoutJSON reads recipients from a JSON array of PayoutRecipient, with
+// amounts in satoshis or as strings parsed by ParseAmount.
+func ReadPayoutJSON(r io.Reader) ([]PayoutRecipient, error) {
+	var recipients []PayoutRecipient
+	if err := json.NewDecoder(r).Decode(&recipients); err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, ErrPayoutEmpty
+	}
+	return recipients, nil
+}
+
+// PayoutConfig configures a payout. Zero values select defaults.
+type PayoutConfig struct {
+	// FeeRate is the fee rate of the transactions, in sat/vB.
+	FeeRate FeeRate
+	// MaxOutputs and MaxVSize bound the size of each transaction, by
+	// default 250 outputs and 100000 vB.
+	MaxOutputs int
+	MaxVSize   int64
+	// Unsigned leaves the transactions unsigned, to be approved and signed
+	// out-of-band from their drafts.
+	Unsigned bool
+}
+
+// PayoutBatch is a transaction of a payout. RawTx is empty for unsigned
+// payouts.
+type PayoutBatch struct {
+	Draft *Draft
+	RawTx []byte
+	VSize int64
+}
+
+// PayoutLine reconciles a recipient with the batch and output paying it.
+// Batch is -1 and Error set for recipients left unpaid.
+type PayoutLine struct {
+	PayoutRecipient
+	Batch int
+	Vout  uint32
+	Error error
+}
+
+// PayoutReport is the result of a payout.
+type PayoutReport struct {
+	Batches []PayoutBatch
+	// Lines has one line per recipient, in the order of the input.
+	Lines []PayoutLine
+	Paid  Amount
+	Fees  Amount
+}
+
+// Unpaid returns the lines of the recipients left unpaid.
+func (s *PayoutReport) Unpaid() []PayoutLine {
+	var unpaid []PayoutLine
+	for _, line := range s.Lines {
+		if line.Batch < 0 {
+			unpaid = append(unpaid, line)
+		}
+	}
+	return unpaid
+}
+
+// Payout pays recipients from the wallet UTXOs, batching them into as few
+// transactions as the size limits allow, the change returning to the wallet
+// address. Recipients with an address of another network or a dust amount
+// are reported unpaid, as are those left when the UTXOs run out.
+func (s *Wallet) Payout(recipients []PayoutRecipient, utxos []DraftInput, config *PayoutConfig) (*PayoutReport, error) {
+	cfg := PayoutConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.FeeRate < 0 {
+		return nil, ErrInvalidFeeRate
+	}
+	if cfg.MaxOutputs <= 0 {
+		cfg.MaxOutputs = defaultPayoutMaxOutputs
+	}
+	if cfg.MaxVSize <= 0 {
+		cfg.MaxVSize = defaultPayoutMaxVSize
+	}
+	if len(recipients) == 0 {
+		return nil, ErrPayoutEmpty
+	}
+
+	report := &PayoutReport{Lines: make([]PayoutLine, len(recipients))}
+	types := make([]AddressType, len(recipients))
+	var pending []int
+	for i, recipient := range recipients {
+		report.Lines[i] = PayoutLine{PayoutRecipient: recipient, Batch: -1}
+		addrType, err := s.payoutOutputType(recipient)
+		if err != nil {
+			report.Lines[i].Error = err
+			continue
+		}
+		types[i] = addrType
+		pending = append(pending, i)
+	}
+
+	pool := append([]DraftInput(nil), utxos...)
+	sort.SliceStable(pool, func(i, j int) bool { return pool[i].Amount > pool[j].Amount })
+	builder := &payoutBatch{wallet: s, config: &cfg}
+	for _, i := range pending {
+		if builder.add(pool, i, recipients[i].Amount, types[i]) {
+			continue
+		}
+		if len(builder.recipients) > 0 {
+			if err := builder.flush(report, recipients, &pool); err != nil {
+				return nil, err
+			}
+			if builder.add(pool, i, recipients[i].Amount, types[i]) {
+				continue
+			}
+		}
+		report.Lines[i].Error = builder.err
+	}
+	if len(builder.recipients) > 0 {
+		if err := builder.flush(report, recipients, &pool); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// payoutOutputType validates a recipient and returns the type of its output.
+func (s *Wallet) payoutOutputType(recipient PayoutRecipient) (AddressType, error) {
+	addr, err := decodeAddress(recipient.Address, s.params)
+	if err != nil {
+		return "", err
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", err
+	}
+	dust, err := dustThreshold(wire.NewTxOut(0, script), defaultDustFeeRate)
+	if err != nil {
+		return "", err
+	}
+	if recipient.Amount < dust {
+		return "", ErrPayoutDust
+	}
+	return ScriptAddressType(script)
+}
+
+// payoutBatch accumulates the recipients and inputs of a transaction.
+type payoutBatch struct {
+	wallet     *Wallet
+	config     *PayoutConfig
+	recipients []int
+	outputs    []AddressType
+	paid       Amount
+	inputs     int
+	funds      Amount
+	// err is the reason the last recipient could not be added.
+	err error
+}
+
+// add adds a recipient to the batch, taking inputs from the head of pool as
+// needed, and reports whether the batch still fits the limits.
+func (s *payoutBatch) add(pool []DraftInput, i int, amount Amount, addrType AddressType) bool {
+	paid, err := s.paid.Add(amount)
+	if err != nil {
+		s.err = err
+		return false
+	}
+	outputs := append(s.outputs[:len(s.outputs):len(s.outputs)], addrType)
+	inputs, funds := s.inputs, s.funds
+	for {
+		vsize, err := s.vsize(pool[:inputs], outputs)
+		if err != nil {
+			s.err = err
+			return false
+		}
+		if len(outputs) > s.config.MaxOutputs || vsize > s.config.MaxVSize {
+			s.err = ErrPayoutTooBig
+			return false
+		}
+		fee, err := s.config.FeeRate.Fee(vsize)
+		if err != nil {
+			s.err = err
+			return false
+		}
+		if funds >= paid+fee {
+			break
+		}
+		if inputs == len(pool) {
+			s.err = ErrInsufficientFunds
+			return false
+		}
+		if funds, err = funds.Add(pool[inputs].Amount); err != nil {
+			s.err = err
+			return false
+		}
+		inputs++
+	}
+	s.recipients = append(s.recipients, i)
+	s.outputs, s.paid, s.inputs, s.funds = outputs, paid, inputs, funds
+	return true
+}
+
+// vsize estimates the size of the batch spending inputs with a change output
+// to the wallet address.
+func (s *payoutBatch) vsize(inputs []DraftInput, outputs []AddressType) (int64, error) {
+	inputTypes := make([]AddressType, len(inputs))
+	for i, in := range inputs {
+		inputType, err := ScriptAddressType(in.PkScript)
+		if err != nil {
+			return 0, err
+		}
+		inputTypes[i] = inputType
+	}
+	return EstimateVSize(inputTypes, append(outputs[:len(outputs):len(outputs)], s.wallet.AddressType()))
+}
+
+// flush builds and signs the transaction of the batch, records it in the
+// report, removes its inputs from the pool and resets the batch.
+func (s *payoutBatch) flush(report *PayoutReport, recipients []PayoutRecipient, pool *[]DraftInput) error {
+	vsize, err := s.vsize((*pool)[:s.inputs], s.outputs)
+	if err != nil {
+		return err
+	}
+	var outputs []DraftOutput
+	for _, i := range s.recipients {
+		outputs = append(outputs, DraftOutput{Address: recipients[i].Address, Amount: recipients[i].Amount})
+	}
+	address := s.wallet.AddressInfo()
+	fee, err := s.config.FeeRate.Fee(vsize)
+	if err != nil {
+		return err
+	}
+	change := s.funds - s.paid - fee
+	_, changeScript, err := ClassifyAddress(address.String(), s.wallet.network)
+	if err != nil {
+		return err
+	}
+	dust, err := dustThreshold(wire.NewTxOut(0, changeScript), defaultDustFeeRate)
+	if err != nil {
+		return err
+	}
+	if change >= dust {
+		outputs = append(outputs, DraftOutput{Address: address.String(), Amount: change, Change: true})
+	} else {
+		scheme, err := addressScheme(address.Type())
+		if err != nil {
+			return err
+		}
+		vsize -= scheme.OutputWeight() / 4
+	}
+
+	draft, err := NewDraft(s.wallet.network, (*pool)[:s.inputs:s.inputs], outputs)
+	if err != nil {
+		return err
+	}
+	batch := PayoutBatch{Draft: draft, VSize: vsize}
+	if !s.config.Unsigned {
+		if batch.RawTx, err = s.wallet.SignDraft(draft); err != nil {
+			return err
+		}
+	}
+
+	index := len(report.Batches)
+	for vout, i := range s.recipients {
+		report.Lines[i].Batch = index
+		report.Lines[i].Vout = uint32(vout)
+	}
+	report.Batches = append(report.Batches, batch)
+	report.Paid += s.paid
+	report.Fees += draft.Fee
+	*pool = (*pool)[s.inputs:]
+	*s = payoutBatch{wallet: s.wallet, config: s.config}
+	return nil
+}
@@ -0,0 +1,94 @@
+package p2pkh
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadPayoutCSV(t *testing.T) {
+	recipients, err := ReadPayoutCSV(strings.NewReader("address,amount,reference\n" +
+		"1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv,0.001,INV-1\n" +
+		"1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A, 25000 sat\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []PayoutRecipient{
+		{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: 100000, Reference: "INV-1"},
+		{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 25000},
+	}, recipients)
+
+	_, err = ReadPayoutCSV(strings.NewReader("1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv,0.0000000001\n"))
+	assert.ErrorContains(t, err, "line 1")
+	_, err = ReadPayoutCSV(strings.NewReader("1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv\n"))
+	assert.ErrorIs(t, err, ErrPayoutColumns)
+	_, err = ReadPayoutCSV(strings.NewReader("address,amount\n"))
+	assert.ErrorIs(t, err, ErrPayoutEmpty)
+}
+
+func Test_ReadPayoutJSON(t *testing.T) {
+	recipients, err := ReadPayoutJSON(strings.NewReader(`[{"address": "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", "amount": "0.001", "reference": "INV-1"}, {"address": "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", "amount": 25000}]`))
+	assert.NoError(t, err)
+	assert.Len(t, recipients, 2)
+	assert.Equal(t, Amount(100000), recipients[0].Amount)
+
+	_, err = ReadPayoutJSON(strings.NewReader(`[{"address": "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", "amount": 0.001}]`))
+	assert.Error(t, err)
+}
+
+func Test_Wallet_Payout(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	var utxos []DraftInput
+	for i := 0; i < 4; i++ {
+		utxos = append(utxos, DraftInput{UTXO: walletUTXO(t, wallet, uint32(i), 100000)})
+	}
+	var recipients []PayoutRecipient
+	for i := 0; i < 5; i++ {
+		recipients = append(recipients, PayoutRecipient{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: 30000, Reference: fmt.Sprintf("INV-%d", i)})
+	}
+	recipients = append(recipients,
+		PayoutRecipient{Address: "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", Amount: 30000, Reference: "testnet"},
+		PayoutRecipient{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: 100, Reference: "dust"},
+		PayoutRecipient{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: 500000, Reference: "too much"},
+	)
+
+	report, err := wallet.Payout(recipients, utxos, &PayoutConfig{FeeRate: 2, MaxOutputs: 3})
+	assert.NoError(t, err)
+	assert.Len(t, report.Batches, 2)
+	assert.Equal(t, Amount(150000), report.Paid)
+
+	var fees Amount
+	for i, batch := range report.Batches {
+		assert.NotEmpty(t, batch.RawTx)
+		assert.GreaterOrEqual(t, int64(batch.Draft.Fee), batch.VSize*2)
+		fees += batch.Draft.Fee
+		var prevOuts []UTXO
+		for _, in := range batch.Draft.Inputs {
+			prevOuts = append(prevOuts, in.UTXO)
+		}
+		verifyTx(t, batch.RawTx, prevOuts)
+		assert.True(t, batch.Draft.Outputs[len(batch.Draft.Outputs)-1].Change, "batch %d", i)
+	}
+	assert.Equal(t, fees, report.Fees)
+
+	for i, line := range report.Lines[:5] {
+		assert.Equal(t, i/3, line.Batch)
+		assert.Equal(t, uint32(i%3), line.Vout)
+		assert.Equal(t, recipients[i].Amount, report.Batches[line.Batch].Draft.Outputs[line.Vout].Amount)
+	}
+	unpaid := report.Unpaid()
+	assert.Len(t, unpaid, 3)
+	assert.Equal(t, "testnet", unpaid[0].Reference)
+	assert.NotEmpty(t, unpaid[0].Error)
+	assert.ErrorIs(t, unpaid[1].Error, ErrPayoutDust)
+	assert.ErrorIs(t, unpaid[2].Error, ErrInsufficientFunds)
+
+	// Unsigned payouts only carry drafts.
+	report, err = wallet.Payout(recipients[:1], utxos, &PayoutConfig{FeeRate: 1, Unsigned: true})
+	assert.NoError(t, err)
+	assert.Len(t, report.Batches, 1)
+	assert.Empty(t, report.Batches[0].RawTx)
+
+	_, err = wallet.Payout(nil, utxos, nil)
+	assert.ErrorIs(t, err, ErrPayoutEmpty)
+}
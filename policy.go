@@ -0,0 +1,239 @@
+package p2pkh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrPolicyViolation Error = "transaction violates the relay policy"
+
+	// Policy violation codes, named after the Bitcoin Core reject reasons.
+	PolicyVersion       = "version"
+	PolicyTxSize        = "tx-size"
+	PolicyTxSizeSmall   = "tx-size-small"
+	PolicyScriptSigSize = "scriptsig-size"
+	PolicyScriptSigPush = "scriptsig-not-pushonly"
+	PolicyScriptPubKey  = "scriptpubkey"
+	PolicyDust          = "dust"
+	PolicyMultiOpReturn = "multi-op-return"
+	PolicyInBelowOut    = "bad-txns-in-belowout"
+	PolicyMinRelayFee   = "min relay fee not met"
+	PolicyMempoolReject = "mempool-rejected"
+
+	defaultMinRelayFeeRate = 1
+	defaultDustFeeRate     = 3
+	maxStandardTxWeight    = 400000
+	maxStandardVersion     = 3
+	minStandardTxSize      = 65
+	maxScriptSigSize       = 1650
+)
+
+// PolicyConfig configures the policy checks run before a broadcast. Zero
+// values select the Bitcoin Core defaults.
+type PolicyConfig struct {
+	// MinRelayFeeRate is the minimum fee rate, in sat/vB, 1 by default.
+	MinRelayFeeRate FeeRate
+	// DustFeeRate is the fee rate defining dust outputs, 3 sat/vB by default.
+	DustFeeRate FeeRate
+	// SkipMempoolAccept disables the testmempoolaccept check of the backend.
+	SkipMempoolAccept bool
+}
+
+// PolicyViolation is a relay policy rule a transaction does not follow.
+// Index is the input or output concerned, -1 for the whole transaction.
+type PolicyViolation struct {
+	Code    string
+	Index   int
+	Message string
+}
+
+// PolicyError is returned for transactions violating the relay policy.
+type PolicyError struct {
+	Violations []PolicyViolation
+}
+
+// Error lists the violated rules.
+func (s *PolicyError) Error() string {
+	messages := make([]string, len(s.Violations))
+	for i, violation := range s.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrPolicyViolation, strings.Join(messages, "; "))
+}
+
+// Unwrap returns ErrPolicyViolation.
+func (s *PolicyError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// CheckPolicy checks a transaction against the standardness rules of Bitcoin
+// Core: version, size, scripts and dust. The fee rate is checked too when the
+// outputs spent by every input are given, in order.
+func CheckPolicy(tx *wire.MsgTx, prevOuts []UTXO, config *PolicyConfig) []PolicyViolation {
+	if config == nil {
+		config = &PolicyConfig{}
+	}
+	minFeeRate, dustFeeRate := config.MinRelayFeeRate, config.DustFeeRate
+	if minFeeRate == 0 {
+		minFeeRate = defaultMinRelayFeeRate
+	}
+	if dustFeeRate == 0 {
+		dustFeeRate = defaultDustFeeRate
+	}
+
+	var violations []PolicyViolation
+	violate := func(code string, index int, format string, args ...interface{}) {
+		violations = append(violations, PolicyViolation{Code: code, Index: index, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if tx.Version < 1 || tx.Version > maxStandardVersion {
+		violate(PolicyVersion, -1, "version %d is not standard", tx.Version)
+	}
+	weight := int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+	if weight > maxStandardTxWeight {
+		violate(PolicyTxSize, -1, "weight %d exceeds %d", weight, maxStandardTxWeight)
+	}
+	if size := tx.SerializeSizeStripped(); size < minStandardTxSize {
+		violate(PolicyTxSizeSmall, -1, "size %d is below %d bytes", size, minStandardTxSize)
+	}
+
+	for i, in := range tx.TxIn {
+		if len(in.SignatureScript) > maxScriptSigSize {
+			violate(PolicyScriptSigSize, i, "input %d scriptSig is %d bytes", i, len(in.SignatureScript))
+		}
+		if !txscript.IsPushOnlyScript(in.SignatureScript) {
+			violate(PolicyScriptSigPush, i, "input %d scriptSig is not push only", i)
+		}
+	}
+
+	opReturns := 0
+	for i, out := range tx.TxOut {
+		switch txscript.GetScriptClass(out.PkScript) {
+		case txscript.NonStandardTy:
+			violate(PolicyScriptPubKey, i, "output %d script is not standard", i)
+		case txscript.NullDataTy:
+			opReturns++
+			continue
+		}
+		threshold, err := dustThreshold(out, dustFeeRate)
+		if err != nil {
+			violate(PolicyDust, i, "output %d dust threshold at %s: %v", i, dustFeeRate, err)
+		} else if Amount(out.Value) < threshold {
+			violate(PolicyDust, i, "output %d of %d sats is below the dust threshold of %d sats", i, out.Value, threshold)
+		}
+	}
+	if opReturns > 1 {
+		violate(PolicyMultiOpReturn, -1, "%d OP_RETURN outputs", opReturns)
+	}
+
+	if len(prevOuts) == len(tx.TxIn) && len(prevOuts) > 0 {
+		var in, out Amount
+		for _, prevOut := range prevOuts {
+			in += prevOut.Amount
+		}
+		for _, txOut := range tx.TxOut {
+			out += Amount(txOut.Value)
+		}
+		vsize := (weight + 3) / 4
+		minFee, err := minFeeRate.Fee(vsize)
+		switch fee := in - out; {
+		case fee < 0:
+			violate(PolicyInBelowOut, -1, "outputs of %d sats exceed inputs of %d sats", out, in)
+		case err != nil:
+			violate(PolicyMinRelayFee, -1, "minimum fee of %d vB at %s: %v", vsize, minFeeRate, err)
+		case fee < minFee:
+			violate(PolicyMinRelayFee, -1, "fee of %d sats is below %d sats (%d vB at %s)", fee, minFee, vsize, minFeeRate)
+		}
+	}
+	return violations
+}
+
+// dustThreshold returns the value below which an output costs more to spend
+// than it is worth, as computed by Bitcoin Core, or an error on overflow.
+func dustThreshold(out *wire.TxOut, dustFeeRate FeeRate) (Amount, error) {
+	return dustFeeRate.Fee(int64(out.SerializeSize()) + dustSpendSize(txscript.IsWitnessProgram(out.PkScript)))
+}
+
+// addressDustThreshold returns the dust threshold of the outputs paying to
+// an address type.
+func addressDustThreshold(addrType AddressType, dustFeeRate FeeRate) (Amount, error) {
+	scheme, err := addressScheme(addrType)
+	if err != nil {
+		return 0, err
+	}
+	return dustFeeRate.Fee(scheme.OutputWeight()/4 + dustSpendSize(scheme.SigHash() != SigHashLegacy))
+}
+
+// dustSpendSize returns the size of the input spending an output, as
+// assumed by the dust threshold.
+func dustSpendSize(witness bool) int64 {
+	if witness {
+		// Outpoint, sequence and a witness discounted by 4.
+		return 32 + 4 + 1 + 107/4 + 4
+	}
+	return 32 + 4 + 1 + 107 + 4
+}
+
+// PolicyBroadcaster is a Broadcaster checking the relay policy before every
+// broadcast, so that a non-standard transaction is reported with structured
+// violations instead of being rejected by the network.
+type PolicyBroadcaster struct {
+	Broadcaster
+	config PolicyConfig
+}
+
+// NewPolicyBroadcaster returns a Broadcaster checking transactions locally,
+// then with the testmempoolaccept of the backend when it is a MempoolAcceptor.
+func NewPolicyBroadcaster(backend Broadcaster, config *PolicyConfig) *PolicyBroadcaster {
+	broadcaster := &PolicyBroadcaster{Broadcaster: backend}
+	if config != nil {
+		broadcaster.config = *config
+	}
+	return broadcaster
+}
+
+// Check checks a serialized transaction against the relay policy, returning a
+// *PolicyError listing the violations. prevOuts are optional.
+func (s *PolicyBroadcaster) Check(ctx context.Context, rawTx []byte, prevOuts []UTXO) error {
+	if len(rawTx) == 0 {
+		return ErrEmptyRawTx
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		return err
+	}
+	if violations := CheckPolicy(tx, prevOuts, &s.config); len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	acceptor, ok := s.Broadcaster.(MempoolAcceptor)
+	if !ok || s.config.SkipMempoolAccept {
+		return nil
+	}
+	result, err := acceptor.TestMempoolAccept(ctx, rawTx)
+	if err != nil {
+		return err
+	}
+	if !result.Allowed {
+		return &PolicyError{Violations: []PolicyViolation{{
+			Code:    PolicyMempoolReject,
+			Index:   -1,
+			Message: result.RejectReason,
+		}}}
+	}
+	return nil
+}
+
+// Broadcast checks the transaction, then publishes it through the backend.
+func (s *PolicyBroadcaster) Broadcast(ctx context.Context, rawTx []byte) (string, error) {
+	if err := s.Check(ctx, rawTx, nil); err != nil {
+		return "", err
+	}
+	return s.Broadcaster.Broadcast(ctx, rawTx)
+}
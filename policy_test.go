@@ -0,0 +1,111 @@
+package p2pkh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// signedPolicyTx signs a draft paying amount from a 100000 sats wallet UTXO.
+func signedPolicyTx(t *testing.T, amount Amount) ([]byte, []UTXO) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 100000)}
+	draft, err := NewDraft(NetworkMainnet, []DraftInput{{UTXO: utxos[0]}},
+		[]DraftOutput{{Address: "1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", Amount: amount}})
+	assert.NoError(t, err)
+	rawTx, err := wallet.SignDraft(draft)
+	assert.NoError(t, err)
+	return rawTx, utxos
+}
+
+// policyCodes returns the codes of the violations.
+func policyCodes(violations []PolicyViolation) []string {
+	var codes []string
+	for _, violation := range violations {
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+func Test_CheckPolicy(t *testing.T) {
+	rawTx, utxos := signedPolicyTx(t, 99000)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Empty(t, CheckPolicy(tx, utxos, nil))
+
+	// A 192 vB transaction paying 100 sats of fee.
+	rawTx, utxos = signedPolicyTx(t, 99900)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Equal(t, []string{PolicyMinRelayFee}, policyCodes(CheckPolicy(tx, utxos, nil)))
+	assert.Empty(t, CheckPolicy(tx, nil, nil))
+
+	// Fee rates too large for any fee are violated rather than wrapped.
+	huge := &PolicyConfig{MinRelayFeeRate: math.MaxInt64 / 100, DustFeeRate: math.MaxInt64 / 100}
+	assert.Equal(t, []string{PolicyDust, PolicyMinRelayFee}, policyCodes(CheckPolicy(tx, utxos, huge)))
+
+	tests := []struct {
+		name   string
+		mutate func(tx *wire.MsgTx)
+		code   string
+	}{
+		{"Version", func(tx *wire.MsgTx) { tx.Version = 4 }, PolicyVersion},
+		{"Dust", func(tx *wire.MsgTx) { tx.TxOut[0].Value = 545 }, PolicyDust},
+		{"NonStandardScript", func(tx *wire.MsgTx) { tx.TxOut[0].PkScript = []byte{0x51} }, PolicyScriptPubKey},
+		{"ScriptSigNotPushOnly", func(tx *wire.MsgTx) { tx.TxIn[0].SignatureScript = []byte{0x76} }, PolicyScriptSigPush},
+		{"MultiOpReturn", func(tx *wire.MsgTx) {
+			tx.AddTxOut(wire.NewTxOut(0, []byte{0x6a}))
+			tx.AddTxOut(wire.NewTxOut(0, []byte{0x6a}))
+		}, PolicyMultiOpReturn},
+		{"InBelowOut", func(tx *wire.MsgTx) { tx.TxOut[0].Value = 200000 }, PolicyInBelowOut},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rawTx, utxos := signedPolicyTx(t, 99000)
+			tx := wire.NewMsgTx(wire.TxVersion)
+			assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+			test.mutate(tx)
+			assert.Contains(t, policyCodes(CheckPolicy(tx, utxos, nil)), test.code)
+		})
+	}
+}
+
+func Test_PolicyBroadcaster(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeBackend()
+	broadcaster := NewPolicyBroadcaster(backend, nil)
+
+	rawTx, utxos := signedPolicyTx(t, 99000)
+	assert.NoError(t, broadcaster.Check(ctx, rawTx, utxos))
+	_, err := broadcaster.Broadcast(ctx, rawTx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.broadcastCount())
+
+	// Local violations are reported without broadcasting.
+	dust, _ := signedPolicyTx(t, 100)
+	_, err = broadcaster.Broadcast(ctx, dust)
+	var policyErr *PolicyError
+	if assert.True(t, errors.As(err, &policyErr)) {
+		assert.Equal(t, []string{PolicyDust}, policyCodes(policyErr.Violations))
+		assert.Equal(t, 0, policyErr.Violations[0].Index)
+	}
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+
+	// As are the rejections of the backend mempool.
+	backend.reject = "txn-mempool-conflict"
+	_, err = broadcaster.Broadcast(ctx, rawTx)
+	assert.ErrorContains(t, err, "txn-mempool-conflict")
+	assert.Equal(t, 1, backend.broadcastCount())
+
+	broadcaster = NewPolicyBroadcaster(backend, &PolicyConfig{SkipMempoolAccept: true})
+	_, err = broadcaster.Broadcast(ctx, rawTx)
+	assert.NoError(t, err)
+
+	_, err = broadcaster.Broadcast(ctx, nil)
+	assert.ErrorIs(t, err, ErrEmptyRawTx)
+}
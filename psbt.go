@@ -0,0 +1,291 @@
+package p2pkh
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrPSBTPrevTx     Error = "PSBT legacy input requires its previous transaction"
+	ErrPSBTUTXO       Error = "PSBT input has no UTXO"
+	ErrPSBTDerivation Error = "PSBT derivation public key does not match the wallet key"
+	ErrChangePath     Error = "change output path does not derive its address"
+	ErrPSBTInputIndex Error = "PSBT input index out of range"
+)
+
+// CreatePSBT creates an unsigned PSBT of a draft, base64 encoded, with the
+// UTXO and the BIP32 derivation of every input so that signers find their
+// keys. The previous transactions of non-witness inputs are required by
+// BIP174 and must be among prevTxs; witness inputs only need their UTXO.
+func (s *Wallet) CreatePSBT(draft *Draft, prevTxs ...*wire.MsgTx) (string, error) {
+	if draft.Network != s.network {
+		return "", ErrDraftNetwork
+	}
+	tx, err := draft.Tx()
+	if err != nil {
+		return "", err
+	}
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return "", err
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return "", err
+	}
+	fingerprint, err := s.psbtFingerprint()
+	if err != nil {
+		return "", err
+	}
+
+	prevTxByID := make(map[string]*wire.MsgTx, len(prevTxs))
+	for _, prevTx := range prevTxs {
+		prevTxByID[prevTx.TxHash().String()] = prevTx
+	}
+	for i, in := range draft.Inputs {
+		// P2SH inputs of the wallet are P2SH-P2WPKH, witness inputs.
+		if txscript.IsWitnessProgram(in.PkScript) || txscript.IsPayToScriptHash(in.PkScript) {
+			err = updater.AddInWitnessUtxo(wire.NewTxOut(int64(in.Amount), in.PkScript), i)
+		} else if prevTx, ok := prevTxByID[in.TxID]; ok {
+			err = updater.AddInNonWitnessUtxo(prevTx, i)
+		} else {
+			err = fmt.Errorf("%w: %s", ErrPSBTPrevTx, in.TxID)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		path := in.Path
+		if path == "" {
+			path = s.path
+		}
+		if err := s.addPSBTDerivation(path, func(path []uint32, publicKey []byte) error {
+			return updater.AddInBip32Derivation(fingerprint, path, publicKey, i)
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	// The change paid to the wallet address, or to the address of its path,
+	// is recognized by signers.
+	for i, out := range draft.Outputs {
+		if !out.Change || (out.Path == "" && out.Address != s.AddressInfo().String()) {
+			continue
+		}
+		path := out.Path
+		if path == "" {
+			path = s.path
+		}
+		if err := s.addPSBTDerivation(path, func(path []uint32, publicKey []byte) error {
+			key, err := btcec.ParsePubKey(publicKey)
+			if err != nil {
+				return err
+			}
+			addr, err := s.keyAddress(key)
+			if err != nil {
+				return err
+			}
+			if addr.EncodeAddress() != out.Address {
+				return ErrChangePath
+			}
+			return updater.AddOutBip32Derivation(fingerprint, path, publicKey, i)
+		}); err != nil {
+			return "", err
+		}
+	}
+	return packet.B64Encode()
+}
+
+// psbtFingerprint returns the master key fingerprint of the wallet as stored
+// by the psbt package, which reads the fingerprint bytes as little endian.
+func (s *Wallet) psbtFingerprint() (uint32, error) {
+	fingerprint, err := s.masterFingerprint()
+	if err != nil {
+		return 0, err
+	}
+	return bits.ReverseBytes32(fingerprint), nil
+}
+
+// addPSBTDerivation adds the BIP32 derivation of the key at path with add.
+func (s *Wallet) addPSBTDerivation(path string, add func(path []uint32, publicKey []byte) error) error {
+	key, err := s.pathKey(path)
+	if err != nil {
+		return err
+	}
+	publicKey, err := key.ECPubKey()
+	if err != nil {
+		return err
+	}
+	levels, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return add(levels, publicKey.SerializeCompressed())
+}
+
+// SignPSBT adds the partial signatures of the wallet to a PSBT, base64
+// encoded, and returns it base64 encoded. Only the inputs whose BIP32
+// derivation belongs to the wallet, by master fingerprint and path, are
+// signed; the other inputs and the fields the wallet does not know, such as
+// proprietary ones, are left untouched.
+func (s *Wallet) SignPSBT(psbtBase64 string) (string, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(psbtBase64), true)
+	if err != nil {
+		return "", err
+	}
+	for i := range packet.Inputs {
+		if err := s.SignPSBTInput(packet, i); err != nil {
+			return "", err
+		}
+	}
+	return packet.B64Encode()
+}
+
+// SignPSBTInput adds the partial signatures of the wallet to the input i of
+// a PSBT, when its BIP32 derivation belongs to the wallet, as SignPSBT does
+// for every input. It implements Signer.
+func (s *Wallet) SignPSBTInput(packet *psbt.Packet, i int) error {
+	if i < 0 || i >= len(packet.Inputs) {
+		return ErrPSBTInputIndex
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := s.psbtFingerprint()
+	if err != nil {
+		return err
+	}
+
+	prevOut := psbtPrevOut(packet, i)
+	var sigHashes *txscript.TxSigHashes
+	for _, derivation := range packet.Inputs[i].Bip32Derivation {
+		if derivation.MasterKeyFingerprint != fingerprint {
+			continue
+		}
+		levels := Path(derivation.Bip32Path)
+		if !s.descendantLevels(levels) {
+			continue
+		}
+		path := levels.String()
+		if prevOut == nil {
+			return fmt.Errorf("input %d: %w", i, ErrPSBTUTXO)
+		}
+		if sigHashes == nil {
+			sigHashes = psbtSigHashes(packet)
+		}
+		if err := s.signPSBTInput(updater, sigHashes, prevOut, i, path, derivation.PubKey); err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// psbtPrevOut returns the output spent by the input i of a PSBT, or nil when
+// the input has no UTXO.
+func psbtPrevOut(packet *psbt.Packet, i int) *wire.TxOut {
+	in := packet.Inputs[i]
+	outPoint := packet.UnsignedTx.TxIn[i].PreviousOutPoint
+	switch {
+	case in.WitnessUtxo != nil:
+		return in.WitnessUtxo
+	case in.NonWitnessUtxo != nil && in.NonWitnessUtxo.TxHash() == outPoint.Hash &&
+		int(outPoint.Index) < len(in.NonWitnessUtxo.TxOut):
+		return in.NonWitnessUtxo.TxOut[outPoint.Index]
+	}
+	return nil
+}
+
+// psbtSigHashes returns the signature hashes of the transaction of a PSBT,
+// which commit to the outputs spent by its inputs.
+func psbtSigHashes(packet *psbt.Packet) *txscript.TxSigHashes {
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i := range packet.Inputs {
+		if prevOut := psbtPrevOut(packet, i); prevOut != nil {
+			fetcher.AddPrevOut(packet.UnsignedTx.TxIn[i].PreviousOutPoint, prevOut)
+		}
+	}
+	return txscript.NewTxSigHashes(packet.UnsignedTx, fetcher)
+}
+
+// signPSBTInput adds the signature of the key at path to an input spending
+// prevOut, with the signature hash algorithm of its address scheme.
+func (s *Wallet) signPSBTInput(updater *psbt.Updater, sigHashes *txscript.TxSigHashes, prevOut *wire.TxOut, i int, path string, publicKey []byte) error {
+	key, err := s.inputKey(path)
+	if err != nil {
+		return err
+	}
+	privateKey, err := key.ECPrivKey()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(privateKey.PubKey().SerializeCompressed(), publicKey) {
+		return ErrPSBTDerivation
+	}
+
+	addrType, err := ScriptAddressType(prevOut.PkScript)
+	if err != nil {
+		return err
+	}
+	scheme, err := addressScheme(addrType)
+	if err != nil {
+		return err
+	}
+	script, err := scheme.Script(privateKey.PubKey(), s.params)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(script, prevOut.PkScript) {
+		return ErrInputScriptMismatch
+	}
+
+	hashType := updater.Upsbt.Inputs[i].SighashType
+	if hashType == 0 {
+		hashType = txscript.SigHashAll
+	}
+	tx := updater.Upsbt.UnsignedTx
+	var signature, redeemScript []byte
+	switch scheme.SigHash() {
+	case SigHashLegacy:
+		signature, err = s.rawTxInSignature(tx, i, prevOut.PkScript, hashType, privateKey)
+	case SigHashWitnessV0:
+		signature, err = s.witnessV0Signature(tx, sigHashes, i, prevOut.Value, hashType, privateKey)
+		if addrType == AddressTypeP2SH {
+			redeemScript = append([]byte{txscript.OP_0, txscript.OP_DATA_20}, btcutil.Hash160(publicKey)...)
+		}
+	default:
+		return ErrUnsupportedAddressType
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = updater.Sign(i, signature, publicKey, redeemScript, nil); err != nil {
+		return err
+	}
+	s.logSign("psbt_input", path, inputAttrs(tx, i)...)
+	return nil
+}
+
+// witnessV0Signature signs a single-key witness v0 input, whose script code
+// is the P2PKH script of the key.
+func (s *Wallet) witnessV0Signature(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, i int, amount int64, hashType txscript.SigHashType, privateKey *btcec.PrivateKey) ([]byte, error) {
+	scriptCode, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).
+		AddData(btcutil.Hash160(privateKey.PubKey().SerializeCompressed())).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return nil, err
+	}
+	return s.rawTxInWitnessSignature(tx, sigHashes, i, amount, scriptCode, hashType, privateKey)
+}
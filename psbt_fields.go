@@ -0,0 +1,324 @@
+package p2pkh
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrPSBTFieldStandard Error = "PSBT field is defined by BIP174"
+	ErrPSBTFieldIndex    Error = "PSBT field index out of range"
+	ErrPSBTCombine       Error = "PSBTs do not spend the same transaction"
+
+	psbtGlobalUnsignedTx  = 0x00
+	psbtGlobalInputCount  = 0x04
+	psbtGlobalOutputCount = 0x05
+	psbtProprietary       = 0xfc
+)
+
+// psbtStandardKeys are the key types defined by BIP174 and its extensions
+// (v2, taproot, MuSig2), by map. Other key types are unknown fields.
+var psbtStandardKeys = map[PSBTMap]map[uint64]bool{
+	PSBTGlobal: {0x00: true, 0x01: true, 0x02: true, 0x03: true, 0x04: true, 0x05: true, 0x06: true, 0xfb: true},
+	PSBTInput: {0x00: true, 0x01: true, 0x02: true, 0x03: true, 0x04: true, 0x05: true, 0x06: true, 0x07: true,
+		0x08: true, 0x09: true, 0x0a: true, 0x0b: true, 0x0c: true, 0x0d: true, 0x0e: true, 0x0f: true, 0x10: true,
+		0x11: true, 0x12: true, 0x13: true, 0x14: true, 0x15: true, 0x16: true, 0x17: true, 0x18: true, 0x1a: true,
+		0x1b: true, 0x1c: true},
+	PSBTOutput: {0x00: true, 0x01: true, 0x02: true, 0x03: true, 0x04: true, 0x05: true, 0x06: true, 0x07: true, 0x08: true},
+}
+
+// PSBTMap designates a key-value map of a PSBT.
+type PSBTMap int
+
+const (
+	PSBTGlobal PSBTMap = iota
+	PSBTInput
+	PSBTOutput
+)
+
+// PSBTField is a key-value pair of a PSBT map. Index is the input or output
+// index, zero for global fields. Key is the full key, key type included.
+type PSBTField struct {
+	Map   PSBTMap
+	Index int
+	Key   []byte
+	Value []byte
+}
+
+// PSBTProprietaryKey is the key of a proprietary field (BIP174 0xFC): an
+// identifier, usually the name of the application, a subtype and key data.
+type PSBTProprietaryKey struct {
+	Identifier []byte
+	Subtype    uint64
+	KeyData    []byte
+}
+
+// NewPSBTProprietaryField returns a proprietary field of a map.
+func NewPSBTProprietaryField(m PSBTMap, index int, key PSBTProprietaryKey, value []byte) PSBTField {
+	var buf bytes.Buffer
+	buf.WriteByte(psbtProprietary)
+	wire.WriteVarBytes(&buf, 0, key.Identifier)
+	wire.WriteVarInt(&buf, 0, key.Subtype)
+	buf.Write(key.KeyData)
+	return PSBTField{Map: m, Index: index, Key: buf.Bytes(), Value: value}
+}
+
+// KeyType returns the type of the field key.
+func (s *PSBTField) KeyType() uint64 {
+	keyType, _ := wire.ReadVarInt(bytes.NewReader(s.Key), 0)
+	return keyType
+}
+
+// Proprietary returns the key of a proprietary field, false for other fields.
+func (s *PSBTField) Proprietary() (*PSBTProprietaryKey, bool) {
+	r := bytes.NewReader(s.Key)
+	if keyType, err := wire.ReadVarInt(r, 0); err != nil || keyType != psbtProprietary {
+		return nil, false
+	}
+	identifier, err := wire.ReadVarBytes(r, 0, uint32(len(s.Key)), "identifier")
+	if err != nil {
+		return nil, false
+	}
+	subtype, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, false
+	}
+	keyData, _ := io.ReadAll(r)
+	return &PSBTProprietaryKey{Identifier: identifier, Subtype: subtype, KeyData: keyData}, true
+}
+
+// standard reports whether BIP174 defines the field, which is then managed
+// by signers and finalizers rather than by applications.
+func (s *PSBTField) standard() bool {
+	return psbtStandardKeys[s.Map][s.KeyType()]
+}
+
+// PSBTExtraFields returns the proprietary and unknown fields of a PSBT, in
+// the order of their maps.
+func PSBTExtraFields(psbt []byte) ([]PSBTField, error) {
+	packet, err := parsePSBTMaps(psbt)
+	if err != nil {
+		return nil, err
+	}
+	var fields []PSBTField
+	packet.each(func(field PSBTField) {
+		if !field.standard() {
+			fields = append(fields, field)
+		}
+	})
+	return fields, nil
+}
+
+// SetPSBTField adds a proprietary or unknown field to a PSBT, or replaces
+// the value of the field with the same key. Every other field is preserved.
+func SetPSBTField(psbt []byte, field PSBTField) ([]byte, error) {
+	if field.standard() || len(field.Key) == 0 {
+		return nil, ErrPSBTFieldStandard
+	}
+	packet, err := parsePSBTMaps(psbt)
+	if err != nil {
+		return nil, err
+	}
+	if err := packet.set(field); err != nil {
+		return nil, err
+	}
+	return packet.serialize(), nil
+}
+
+// CombinePSBT merges PSBTs of the same transaction, as a BIP174 combiner:
+// the result holds the union of the fields of every PSBT, proprietary and
+// unknown fields included. On conflict, the value of the first PSBT wins.
+func CombinePSBT(psbts ...[]byte) ([]byte, error) {
+	if len(psbts) == 0 {
+		return nil, ErrPSBTInvalid
+	}
+	combined, err := parsePSBTMaps(psbts[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, psbt := range psbts[1:] {
+		packet, err := parsePSBTMaps(psbt)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(packet.get(PSBTGlobal, 0, []byte{psbtGlobalUnsignedTx}), combined.get(PSBTGlobal, 0, []byte{psbtGlobalUnsignedTx})) ||
+			len(packet.inputs) != len(combined.inputs) || len(packet.outputs) != len(combined.outputs) {
+			return nil, ErrPSBTCombine
+		}
+		var setErr error
+		packet.each(func(field PSBTField) {
+			if setErr == nil && combined.get(field.Map, field.Index, field.Key) == nil {
+				setErr = combined.set(field)
+			}
+		})
+		if setErr != nil {
+			return nil, setErr
+		}
+	}
+	return combined.serialize(), nil
+}
+
+// psbtPair is a key-value pair of a serialized PSBT map.
+type psbtPair struct {
+	key, value []byte
+}
+
+// psbtMaps is a PSBT split in its key-value maps, without interpretation
+// of the values, so that serializing it back preserves every field.
+type psbtMaps struct {
+	global  []psbtPair
+	inputs  [][]psbtPair
+	outputs [][]psbtPair
+}
+
+// parsePSBTMaps splits a binary PSBT, version 0 or 2, in its maps.
+func parsePSBTMaps(psbt []byte) (*psbtMaps, error) {
+	if !bytes.HasPrefix(psbt, psbtMagic) {
+		return nil, ErrPSBTInvalid
+	}
+	r := bytes.NewReader(psbt[len(psbtMagic):])
+	packet := &psbtMaps{}
+	var err error
+	if packet.global, err = readPSBTMap(r); err != nil {
+		return nil, err
+	}
+
+	var inputs, outputs uint64
+	for _, pair := range packet.global {
+		switch pair.key[0] {
+		case psbtGlobalUnsignedTx:
+			tx := wire.NewMsgTx(wire.TxVersion)
+			if err := tx.DeserializeNoWitness(bytes.NewReader(pair.value)); err != nil {
+				return nil, ErrPSBTInvalid
+			}
+			inputs, outputs = uint64(len(tx.TxIn)), uint64(len(tx.TxOut))
+		case psbtGlobalInputCount:
+			inputs, err = wire.ReadVarInt(bytes.NewReader(pair.value), 0)
+		case psbtGlobalOutputCount:
+			outputs, err = wire.ReadVarInt(bytes.NewReader(pair.value), 0)
+		}
+		if err != nil {
+			return nil, ErrPSBTInvalid
+		}
+	}
+	// Every map holds at least its separator.
+	if inputs+outputs > uint64(r.Len()) {
+		return nil, ErrPSBTInvalid
+	}
+	for i := uint64(0); i < inputs; i++ {
+		m, err := readPSBTMap(r)
+		if err != nil {
+			return nil, err
+		}
+		packet.inputs = append(packet.inputs, m)
+	}
+	for i := uint64(0); i < outputs; i++ {
+		m, err := readPSBTMap(r)
+		if err != nil {
+			return nil, err
+		}
+		packet.outputs = append(packet.outputs, m)
+	}
+	if r.Len() != 0 {
+		return nil, ErrPSBTInvalid
+	}
+	return packet, nil
+}
+
+// readPSBTMap reads the pairs of a map up to its separator.
+func readPSBTMap(r *bytes.Reader) ([]psbtPair, error) {
+	var pairs []psbtPair
+	seen := make(map[string]bool)
+	for {
+		key, err := wire.ReadVarBytes(r, 0, maxPSBTSize, "key")
+		if err != nil {
+			return nil, ErrPSBTInvalid
+		}
+		if len(key) == 0 {
+			return pairs, nil
+		}
+		value, err := wire.ReadVarBytes(r, 0, maxPSBTSize, "value")
+		if err != nil || seen[string(key)] {
+			return nil, ErrPSBTInvalid
+		}
+		seen[string(key)] = true
+		pairs = append(pairs, psbtPair{key: key, value: value})
+	}
+}
+
+// pairs returns the map designated by m and index.
+func (s *psbtMaps) pairs(m PSBTMap, index int) (*[]psbtPair, error) {
+	switch {
+	case m == PSBTGlobal && index == 0:
+		return &s.global, nil
+	case m == PSBTInput && index >= 0 && index < len(s.inputs):
+		return &s.inputs[index], nil
+	case m == PSBTOutput && index >= 0 && index < len(s.outputs):
+		return &s.outputs[index], nil
+	default:
+		return nil, ErrPSBTFieldIndex
+	}
+}
+
+// get returns the value of a key, nil when absent.
+func (s *psbtMaps) get(m PSBTMap, index int, key []byte) []byte {
+	pairs, err := s.pairs(m, index)
+	if err != nil {
+		return nil
+	}
+	for _, pair := range *pairs {
+		if bytes.Equal(pair.key, key) {
+			return pair.value
+		}
+	}
+	return nil
+}
+
+// set replaces the value of a field, or appends the field to its map.
+func (s *psbtMaps) set(field PSBTField) error {
+	pairs, err := s.pairs(field.Map, field.Index)
+	if err != nil {
+		return err
+	}
+	for i, pair := range *pairs {
+		if bytes.Equal(pair.key, field.Key) {
+			(*pairs)[i].value = field.Value
+			return nil
+		}
+	}
+	*pairs = append(*pairs, psbtPair{key: field.Key, value: field.Value})
+	return nil
+}
+
+// each calls fn for every field, global fields first.
+func (s *psbtMaps) each(fn func(PSBTField)) {
+	for _, pair := range s.global {
+		fn(PSBTField{Map: PSBTGlobal, Key: pair.key, Value: pair.value})
+	}
+	for i, pairs := range s.inputs {
+		for _, pair := range pairs {
+			fn(PSBTField{Map: PSBTInput, Index: i, Key: pair.key, Value: pair.value})
+		}
+	}
+	for i, pairs := range s.outputs {
+		for _, pair := range pairs {
+			fn(PSBTField{Map: PSBTOutput, Index: i, Key: pair.key, Value: pair.value})
+		}
+	}
+}
+
+// serialize returns the binary PSBT.
+func (s *psbtMaps) serialize() []byte {
+	var buf bytes.Buffer
+	buf.Write(psbtMagic)
+	for _, pairs := range append(append([][]psbtPair{s.global}, s.inputs...), s.outputs...) {
+		for _, pair := range pairs {
+			wire.WriteVarBytes(&buf, 0, pair.key)
+			wire.WriteVarBytes(&buf, 0, pair.value)
+		}
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
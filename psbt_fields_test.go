@@ -0,0 +1,100 @@
+package p2pkh
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// unsignedTestPSBT returns a version 0 PSBT of an unsigned transaction with the
+// given number of inputs and outputs, and no other field.
+func unsignedTestPSBT(t *testing.T, inputs, outputs int) []byte {
+	tx := wire.NewMsgTx(2)
+	for i := 0; i < inputs; i++ {
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, uint32(i)), nil, nil))
+	}
+	for i := 0; i < outputs; i++ {
+		tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	}
+	var unsigned bytes.Buffer
+	assert.NoError(t, tx.SerializeNoWitness(&unsigned))
+
+	packet := &psbtMaps{
+		global:  []psbtPair{{key: []byte{psbtGlobalUnsignedTx}, value: unsigned.Bytes()}},
+		inputs:  make([][]psbtPair, inputs),
+		outputs: make([][]psbtPair, outputs),
+	}
+	return packet.serialize()
+}
+
+func Test_PSBTExtraFields(t *testing.T) {
+	psbt := unsignedTestPSBT(t, 2, 1)
+	fields, err := PSBTExtraFields(psbt)
+	assert.NoError(t, err)
+	assert.Empty(t, fields)
+
+	proprietary := NewPSBTProprietaryField(PSBTInput, 1, PSBTProprietaryKey{Identifier: []byte("coordinator"), Subtype: 3, KeyData: []byte{0xaa}}, []byte("session-42"))
+	unknown := PSBTField{Map: PSBTOutput, Index: 0, Key: []byte{0x42, 0x01}, Value: []byte{0x02}}
+	psbt, err = SetPSBTField(psbt, proprietary)
+	assert.NoError(t, err)
+	psbt, err = SetPSBTField(psbt, unknown)
+	assert.NoError(t, err)
+
+	// The fields survive the encodings.
+	encoded, err := EncodePSBT(psbt, PSBTEncodingBase64)
+	assert.NoError(t, err)
+	decoded, _, err := DecodePSBT(encoded)
+	assert.NoError(t, err)
+	fields, err = PSBTExtraFields(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, []PSBTField{proprietary, unknown}, fields)
+
+	key, ok := fields[0].Proprietary()
+	assert.True(t, ok)
+	assert.Equal(t, "coordinator", string(key.Identifier))
+	assert.Equal(t, uint64(3), key.Subtype)
+	assert.Equal(t, []byte{0xaa}, key.KeyData)
+	_, ok = fields[1].Proprietary()
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0x42), fields[1].KeyType())
+
+	// Setting a field again replaces its value.
+	proprietary.Value = []byte("session-43")
+	psbt, err = SetPSBTField(psbt, proprietary)
+	assert.NoError(t, err)
+	fields, err = PSBTExtraFields(psbt)
+	assert.NoError(t, err)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, []byte("session-43"), fields[0].Value)
+
+	_, err = SetPSBTField(psbt, PSBTField{Map: PSBTInput, Key: []byte{0x02, 0x03}})
+	assert.ErrorIs(t, err, ErrPSBTFieldStandard)
+	_, err = SetPSBTField(psbt, PSBTField{Map: PSBTOutput, Index: 1, Key: []byte{0x42}})
+	assert.ErrorIs(t, err, ErrPSBTFieldIndex)
+	_, err = PSBTExtraFields(psbt[:len(psbt)-1])
+	assert.ErrorIs(t, err, ErrPSBTInvalid)
+}
+
+func Test_CombinePSBT(t *testing.T) {
+	base := unsignedTestPSBT(t, 1, 1)
+	first, err := SetPSBTField(base, NewPSBTProprietaryField(PSBTGlobal, 0, PSBTProprietaryKey{Identifier: []byte("a")}, []byte{1}))
+	assert.NoError(t, err)
+	second, err := SetPSBTField(base, NewPSBTProprietaryField(PSBTInput, 0, PSBTProprietaryKey{Identifier: []byte("b")}, []byte{2}))
+	assert.NoError(t, err)
+	second, err = SetPSBTField(second, NewPSBTProprietaryField(PSBTGlobal, 0, PSBTProprietaryKey{Identifier: []byte("a")}, []byte{3}))
+	assert.NoError(t, err)
+
+	combined, err := CombinePSBT(first, second)
+	assert.NoError(t, err)
+	fields, err := PSBTExtraFields(combined)
+	assert.NoError(t, err)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, []byte{1}, fields[0].Value)
+	assert.Equal(t, PSBTInput, fields[1].Map)
+
+	_, err = CombinePSBT(first, unsignedTestPSBT(t, 2, 1))
+	assert.ErrorIs(t, err, ErrPSBTCombine)
+}
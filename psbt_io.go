@@ -0,0 +1,152 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+)
+
+// PSBTEncoding is the serialization of a PSBT in a file or a stream.
+type PSBTEncoding string
+
+const (
+	PSBTEncodingBinary PSBTEncoding = "binary"
+	PSBTEncodingBase64 PSBTEncoding = "base64"
+	PSBTEncodingHex    PSBTEncoding = "hex"
+
+	ErrPSBTInvalid  Error = "invalid PSBT"
+	ErrPSBTEncoding Error = "unsupported PSBT encoding"
+	ErrPSBTTooLarge Error = "PSBT exceeds the maximum size"
+
+	// PSBTFileExt is the extension of binary PSBT files (BIP174).
+	PSBTFileExt = ".psbt"
+
+	// maxPSBTSize bounds the data read from a PSBT stream.
+	maxPSBTSize = 16 << 20
+)
+
+// psbtMagic starts every serialized PSBT.
+var psbtMagic = []byte("psbt\xff")
+
+// DecodePSBT detects the encoding of a PSBT, binary, base64 or hex, and
+// returns its binary serialization. Text encodings may be surrounded by or
+// wrapped with whitespace.
+func DecodePSBT(data []byte) ([]byte, PSBTEncoding, error) {
+	if bytes.HasPrefix(data, psbtMagic) {
+		return data, PSBTEncodingBinary, nil
+	}
+
+	text := strings.Join(strings.Fields(string(data)), "")
+	var psbt []byte
+	var encoding PSBTEncoding
+	var err error
+	switch {
+	case strings.HasPrefix(strings.ToLower(text), hex.EncodeToString(psbtMagic)):
+		psbt, err = hex.DecodeString(text)
+		encoding = PSBTEncodingHex
+	case strings.HasPrefix(text, base64.StdEncoding.EncodeToString(psbtMagic)[:6]):
+		psbt, err = base64.StdEncoding.DecodeString(text)
+		encoding = PSBTEncodingBase64
+	default:
+		return nil, "", ErrPSBTInvalid
+	}
+	if err != nil || !bytes.HasPrefix(psbt, psbtMagic) {
+		return nil, "", ErrPSBTInvalid
+	}
+	return psbt, encoding, nil
+}
+
+// ParsePSBT decodes a PSBT in any of the encodings DecodePSBT detects and
+// parses it. Malformed PSBTs are reported as ErrPSBTInvalid.
+func ParsePSBT(data []byte) (*psbt.Packet, error) {
+	if len(data) > maxPSBTSize {
+		return nil, ErrPSBTTooLarge
+	}
+	raw, _, err := DecodePSBT(data)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(raw), false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPSBTInvalid, err)
+	}
+	return packet, nil
+}
+
+// EncodePSBT serializes a binary PSBT with the given encoding. Text encodings
+// are not terminated by a newline.
+func EncodePSBT(psbt []byte, encoding PSBTEncoding) ([]byte, error) {
+	if !bytes.HasPrefix(psbt, psbtMagic) {
+		return nil, ErrPSBTInvalid
+	}
+	switch encoding {
+	case PSBTEncodingBinary:
+		return psbt, nil
+	case PSBTEncodingBase64:
+		return []byte(base64.StdEncoding.EncodeToString(psbt)), nil
+	case PSBTEncodingHex:
+		return []byte(hex.EncodeToString(psbt)), nil
+	default:
+		return nil, ErrPSBTEncoding
+	}
+}
+
+// ReadPSBT reads a PSBT in any supported encoding and returns its binary
+// serialization along with the detected encoding.
+func ReadPSBT(r io.Reader) ([]byte, PSBTEncoding, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxPSBTSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxPSBTSize {
+		return nil, "", ErrPSBTTooLarge
+	}
+	return DecodePSBT(data)
+}
+
+// WritePSBT writes a binary PSBT with the given encoding. Text encodings are
+// followed by a newline, as expected by command line tools.
+func WritePSBT(w io.Writer, psbt []byte, encoding PSBTEncoding) error {
+	data, err := EncodePSBT(psbt, encoding)
+	if err != nil {
+		return err
+	}
+	if encoding != PSBTEncodingBinary {
+		data = append(data, '\n')
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadPSBTFile reads a PSBT file in any supported encoding.
+func ReadPSBTFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	psbt, _, err := ReadPSBT(f)
+	return psbt, err
+}
+
+// WritePSBTFile writes a PSBT file following the usual conventions: binary
+// for files with the .psbt extension, base64 text otherwise.
+func WritePSBTFile(path string, psbt []byte) error {
+	encoding := PSBTEncodingBase64
+	if strings.EqualFold(filepath.Ext(path), PSBTFileExt) {
+		encoding = PSBTEncodingBinary
+	}
+	var buf bytes.Buffer
+	if err := WritePSBT(&buf, psbt, encoding); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
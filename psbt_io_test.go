@@ -0,0 +1,126 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPSBT is a minimal, structurally meaningless, serialized PSBT.
+var testPSBT = append([]byte("psbt\xff"), 0x01, 0x00, 0x0a, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+
+func Test_ReadPSBT(t *testing.T) {
+	for _, encoding := range []PSBTEncoding{PSBTEncodingBinary, PSBTEncodingBase64, PSBTEncodingHex} {
+		t.Run(string(encoding), func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, WritePSBT(&buf, testPSBT, encoding))
+
+			psbt, detected, err := ReadPSBT(&buf)
+			assert.NoError(t, err)
+			assert.Equal(t, encoding, detected)
+			assert.Equal(t, testPSBT, psbt)
+		})
+	}
+
+	// Wrapped and uppercase text is accepted.
+	encoded, err := EncodePSBT(testPSBT, PSBTEncodingBase64)
+	assert.NoError(t, err)
+	wrapped := "  " + string(encoded[:10]) + "\r\n" + string(encoded[10:]) + "\n"
+	psbt, _, err := DecodePSBT([]byte(wrapped))
+	assert.NoError(t, err)
+	assert.Equal(t, testPSBT, psbt)
+
+	encoded, err = EncodePSBT(testPSBT, PSBTEncodingHex)
+	assert.NoError(t, err)
+	psbt, _, err = DecodePSBT([]byte(strings.ToUpper(string(encoded))))
+	assert.NoError(t, err)
+	assert.Equal(t, testPSBT, psbt)
+
+	_, _, err = ReadPSBT(strings.NewReader("0200000001"))
+	assert.ErrorIs(t, err, ErrPSBTInvalid)
+	_, _, err = ReadPSBT(strings.NewReader("cHNidP8!!!"))
+	assert.ErrorIs(t, err, ErrPSBTInvalid)
+	_, err = EncodePSBT(testPSBT, PSBTEncoding("base58"))
+	assert.ErrorIs(t, err, ErrPSBTEncoding)
+	_, err = EncodePSBT([]byte{0x02}, PSBTEncodingHex)
+	assert.ErrorIs(t, err, ErrPSBTInvalid)
+}
+
+func Test_WritePSBTFile(t *testing.T) {
+	dir := t.TempDir()
+
+	binary := filepath.Join(dir, "tx.psbt")
+	assert.NoError(t, WritePSBTFile(binary, testPSBT))
+	data, err := os.ReadFile(binary)
+	assert.NoError(t, err)
+	assert.Equal(t, testPSBT, data)
+
+	text := filepath.Join(dir, "tx.txt")
+	assert.NoError(t, WritePSBTFile(text, testPSBT))
+	data, err = os.ReadFile(text)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "cHNidP8"))
+
+	for _, path := range []string{binary, text} {
+		psbt, err := ReadPSBTFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, testPSBT, psbt)
+	}
+}
+
+func Test_ParsePSBT(t *testing.T) {
+	raw := newTestPacket(t)
+	for _, encoding := range []PSBTEncoding{PSBTEncodingBinary, PSBTEncodingBase64, PSBTEncodingHex} {
+		encoded, err := EncodePSBT(raw, encoding)
+		assert.NoError(t, err)
+		packet, err := ParsePSBT(encoded)
+		assert.NoError(t, err, encoding)
+		assert.Len(t, packet.Inputs, 1)
+		assert.Len(t, packet.Outputs, 1)
+	}
+
+	_, err := ParsePSBT(raw[:len(raw)-4])
+	assert.ErrorIs(t, err, ErrPSBTInvalid)
+	_, err = ParsePSBT([]byte("cHNidP8="))
+	assert.ErrorIs(t, err, ErrPSBTInvalid)
+	_, err = ParsePSBT(nil)
+	assert.ErrorIs(t, err, ErrPSBTInvalid)
+}
+
+func FuzzParsePSBT(f *testing.F) {
+	raw := newTestPacket(f)
+	f.Add(raw)
+	f.Add([]byte(hex.EncodeToString(raw)))
+	f.Add(testPSBT)
+	f.Add([]byte("cHNidP8BAAoCAAAAAAAAAAAAAA=="))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		packet, err := ParsePSBT(data)
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrPSBTInvalid) || errors.Is(err, ErrPSBTTooLarge), err)
+			return
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, packet.Serialize(&buf))
+		_, err = ParsePSBT(buf.Bytes())
+		assert.NoError(t, err)
+	})
+}
+
+// newTestPacket returns a serialized unsigned PSBT spending one output.
+func newTestPacket(t testing.TB) []byte {
+	script, err := hex.DecodeString("76a914ff6812ef21de2f4f899530808a228931fd6f369588ac")
+	assert.NoError(t, err)
+	packet, err := psbt.New([]*wire.OutPoint{{Index: 1}}, []*wire.TxOut{{Value: 1000, PkScript: script}}, 2, 0, []uint32{0xfffffffd})
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, packet.Serialize(&buf))
+	return buf.Bytes()
+}
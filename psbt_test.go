@@ -0,0 +1,130 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// fundingTx returns a transaction paying the given UTXOs, whose txid and
+// vout it sets.
+func fundingTx(utxos []UTXO) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil, nil))
+	for i := range utxos {
+		tx.AddTxOut(wire.NewTxOut(int64(utxos[i].Amount), utxos[i].PkScript))
+	}
+	for i := range utxos {
+		utxos[i].TxID = tx.TxHash().String()
+		utxos[i].Vout = uint32(i)
+	}
+	return tx
+}
+
+// finalizePSBT finalizes a signed PSBT and returns the raw transaction.
+func finalizePSBT(t *testing.T, signed string) []byte {
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader([]byte(signed)), true)
+	assert.NoError(t, err)
+	assert.NoError(t, psbt.MaybeFinalizeAll(packet))
+	tx, err := psbt.Extract(packet)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, tx.Serialize(&buf))
+	return buf.Bytes()
+}
+
+func Test_SignPSBT(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(7)
+	assert.NoError(t, err)
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 50000), walletUTXO(t, child, 0, 30000)}
+	prevTx := fundingTx(utxos)
+
+	draft, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: utxos[0]}, {UTXO: utxos[1], Path: child.Path()}},
+		[]DraftOutput{
+			{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 60000},
+			{Address: wallet.AddressHex(), Amount: 19000, Change: true},
+		})
+	assert.NoError(t, err)
+	unsigned, err := wallet.CreatePSBT(draft, prevTx)
+	assert.NoError(t, err)
+
+	// The inputs and the change carry the wallet derivations.
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader([]byte(unsigned)), true)
+	assert.NoError(t, err)
+	assert.Len(t, packet.Inputs[1].Bip32Derivation, 1)
+	assert.Equal(t, []uint32{0x8000002c, 0x80000000, 0x80000000, 0, 7}, packet.Inputs[1].Bip32Derivation[0].Bip32Path)
+	assert.Len(t, packet.Outputs[0].Bip32Derivation, 0)
+	assert.Len(t, packet.Outputs[1].Bip32Derivation, 1)
+
+	// A proprietary field of the coordinator survives signing.
+	raw, err := base64.StdEncoding.DecodeString(unsigned)
+	assert.NoError(t, err)
+	field := NewPSBTProprietaryField(PSBTInput, 1, PSBTProprietaryKey{Identifier: []byte("coord"), Subtype: 1}, []byte("note"))
+	raw, err = SetPSBTField(raw, field)
+	assert.NoError(t, err)
+
+	// A wallet of another account signs nothing.
+	other, err := wallet.Builder().WithPath(`m/44'/0'/1'/0`).Build()
+	assert.NoError(t, err)
+	same, err := other.SignPSBT(base64.StdEncoding.EncodeToString(raw))
+	assert.NoError(t, err)
+	packet, err = psbt.NewFromRawBytes(bytes.NewReader([]byte(same)), true)
+	assert.NoError(t, err)
+	assert.Empty(t, packet.Inputs[0].PartialSigs)
+
+	signed, err := wallet.SignPSBT(base64.StdEncoding.EncodeToString(raw))
+	assert.NoError(t, err)
+	raw, err = base64.StdEncoding.DecodeString(signed)
+	assert.NoError(t, err)
+	fields, err := PSBTExtraFields(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, []PSBTField{field}, fields)
+
+	verifyTx(t, finalizePSBT(t, signed), utxos)
+
+	// A wallet whose path marks hardened levels with "h" signs alike.
+	hWallet, err := wallet.Builder().WithPath(`m/44h/0h/0h/0`).Build()
+	assert.NoError(t, err)
+	signed, err = hWallet.SignPSBT(unsigned)
+	assert.NoError(t, err)
+	packet, err = psbt.NewFromRawBytes(bytes.NewReader([]byte(signed)), true)
+	assert.NoError(t, err)
+	assert.Len(t, packet.Inputs[0].PartialSigs, 1)
+	assert.Len(t, packet.Inputs[1].PartialSigs, 1)
+	verifyTx(t, finalizePSBT(t, signed), utxos)
+
+	_, err = wallet.CreatePSBT(draft)
+	assert.ErrorIs(t, err, ErrPSBTPrevTx)
+	_, err = wallet.SignPSBT("not a psbt")
+	assert.Error(t, err)
+}
+
+func Test_SignPSBT_Witness(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	var utxos []UTXO
+	for _, addrType := range []AddressType{AddressTypeP2WPKH, AddressTypeP2SH} {
+		scheme, _ := LookupAddressScheme(addrType)
+		script, err := scheme.Script(wallet.PublicKey(), &chaincfg.MainNetParams)
+		assert.NoError(t, err)
+		utxos = append(utxos, UTXO{Amount: 40000, PkScript: script})
+	}
+	fundingTx(utxos)
+
+	draft, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: utxos[0]}, {UTXO: utxos[1]}},
+		[]DraftOutput{{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 79000}})
+	assert.NoError(t, err)
+	unsigned, err := wallet.CreatePSBT(draft)
+	assert.NoError(t, err)
+	signed, err := wallet.SignPSBT(unsigned)
+	assert.NoError(t, err)
+	verifyTx(t, finalizePSBT(t, signed), utxos)
+}
@@ -0,0 +1,70 @@
+package p2pkh
+
+import (
+	"image"
+	"image/color"
+
+	"rsc.io/qr"
+)
+
+const (
+	ErrQRSize Error = "QR code size too small for its payload"
+
+	// qrQuietZone is the white border around a QR code, in modules, that
+	// scanners need to locate it.
+	qrQuietZone = 4
+)
+
+// QRCode renders the QR code of a payload, e.g. a BIP21 URI, as a square
+// grayscale image of size pixels, with a white border. Modules are drawn
+// with a whole number of pixels, which must be at least 1, so that the code
+// stays sharp once printed or scaled; the image can be encoded with
+// image/png.
+func QRCode(payload string, size int) (image.Image, error) {
+	code, err := qrEncode(payload)
+	if err != nil {
+		return nil, err
+	}
+	modules := code.Size + 2*qrQuietZone
+	scale := size / modules
+	if scale < 1 {
+		return nil, ErrQRSize
+	}
+
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	offset := (size - code.Size*scale) / 2
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; col++ {
+			if !code.Black(col, row) {
+				continue
+			}
+			for y := 0; y < scale; y++ {
+				for x := 0; x < scale; x++ {
+					img.SetGray(offset+col*scale+x, offset+row*scale+y, color.Gray{})
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// AddressQR returns the QR code of the wallet address, as the BIP21 URI
+// wallets scan, of size pixels.
+func (s *Wallet) AddressQR(size int) (image.Image, error) {
+	return QRCode("bitcoin:"+s.AddressInfo().String(), size)
+}
+
+// QR returns the QR code of the BIP21 URI of the request, of size pixels,
+// e.g. for a point of sale or an invoice.
+func (s *PaymentRequest) QR(size int) (image.Image, error) {
+	return QRCode(s.URI(), size)
+}
+
+// qrEncode encodes a payload as a QR code with medium error correction,
+// restoring up to 15% of a damaged code.
+func qrEncode(payload string) (*qr.Code, error) {
+	return qr.Encode(payload, qr.M)
+}
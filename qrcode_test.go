@@ -0,0 +1,62 @@
+package p2pkh
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QRCode(t *testing.T) {
+	payload := "bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr"
+	code, err := qrEncode(payload)
+	assert.NoError(t, err)
+
+	img, err := QRCode(payload, 300)
+	assert.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+
+	// Every module is drawn with scale pixels, centered in the image.
+	scale := 300 / (code.Size + 2*qrQuietZone)
+	offset := (300 - code.Size*scale) / 2
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; col++ {
+			expected := color.Gray{Y: 0xff}
+			if code.Black(col, row) {
+				expected = color.Gray{}
+			}
+			assert.Equal(t, expected, img.At(offset+col*scale+scale-1, offset+row*scale))
+		}
+	}
+	assert.Equal(t, color.Gray{Y: 0xff}, img.At(offset-1, offset))
+	assert.Equal(t, color.Gray{}, img.At(offset, offset))
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	decoded, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds(), decoded.Bounds())
+
+	_, err = QRCode(payload, code.Size+2*qrQuietZone-1)
+	assert.ErrorIs(t, err, ErrQRSize)
+}
+
+func Test_AddressQR(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	addressQR, err := wallet.AddressQR(256)
+	assert.NoError(t, err)
+	expected, err := QRCode("bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", 256)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, addressQR)
+
+	request := wallet.PaymentRequest(100000, "Order 42", "")
+	requestQR, err := request.QR(256)
+	assert.NoError(t, err)
+	expected, err = QRCode(request.URI(), 256)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, requestQR)
+	assert.NotEqual(t, addressQR, requestQR)
+}
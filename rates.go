@@ -0,0 +1,202 @@
+package p2pkh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	ErrRateUnavailable Error = "exchange rate unavailable"
+	ErrRateInvalid     Error = "exchange rate must be positive"
+
+	defaultCoinGeckoURL = "https://api.coingecko.com"
+	defaultKrakenURL    = "https://api.kraken.com"
+)
+
+// Rate is the price of one bitcoin in a fiat currency.
+type Rate struct {
+	// Currency is the ISO 4217 code of the currency, e.g. "USD".
+	Currency string
+	Price    float64
+	Time     time.Time
+}
+
+// Fiat converts an amount to the currency.
+func (s *Rate) Fiat(amount Amount) float64 {
+	return float64(amount) * s.Price / btcutil.SatoshiPerBitcoin
+}
+
+// Sats converts an amount of the currency to satoshis, rounded to the
+// nearest satoshi.
+func (s *Rate) Sats(fiat float64) Amount {
+	return Amount(math.Round(fiat / s.Price * btcutil.SatoshiPerBitcoin))
+}
+
+// Format renders an amount in the currency, e.g. "12.34 USD".
+func (s *Rate) Format(amount Amount) string {
+	return fmt.Sprintf("%.2f %s", s.Fiat(amount), s.Currency)
+}
+
+// PaymentRequest returns a request for a payment of a fiat amount to an
+// address, converted at the rate.
+func (s *Rate) PaymentRequest(address string, fiat float64, label string) (*PaymentRequest, error) {
+	if s.Price <= 0 {
+		return nil, ErrRateInvalid
+	}
+	return &PaymentRequest{Address: address, Amount: s.Sats(fiat), Label: label}, nil
+}
+
+// RateProvider is a source of bitcoin exchange rates.
+type RateProvider interface {
+	// Rate returns the current price of one bitcoin in the given currency.
+	Rate(ctx context.Context, currency string) (*Rate, error)
+}
+
+// CachedRateProvider caches the rates of another provider for a TTL, so that
+// dashboards rendering many amounts query the provider once. It is safe for
+// concurrent use.
+type CachedRateProvider struct {
+	mu       sync.Mutex
+	provider RateProvider
+	ttl      time.Duration
+	rates    map[string]*Rate
+	now      func() time.Time
+}
+
+// NewCachedRateProvider creates a cache of the rates of provider.
+func NewCachedRateProvider(provider RateProvider, ttl time.Duration) *CachedRateProvider {
+	return &CachedRateProvider{provider: provider, ttl: ttl, rates: make(map[string]*Rate), now: time.Now}
+}
+
+// Rate returns the cached rate of the currency, refreshed once older than
+// the TTL.
+func (s *CachedRateProvider) Rate(ctx context.Context, currency string) (*Rate, error) {
+	currency = strings.ToUpper(currency)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rate, ok := s.rates[currency]; ok && s.now().Sub(rate.Time) < s.ttl {
+		copied := *rate
+		return &copied, nil
+	}
+	rate, err := s.provider.Rate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	if rate.Time.IsZero() {
+		rate.Time = s.now()
+	}
+	copied := *rate
+	s.rates[currency] = &copied
+	return rate, nil
+}
+
+// CoinGeckoRateProvider queries the simple price API of CoinGecko.
+type CoinGeckoRateProvider struct {
+	// BaseURL defaults to https://api.coingecko.com.
+	BaseURL string
+	// Client defaults to a client connecting through Proxy.
+	Client *http.Client
+	// Proxy routes the requests, e.g. TorProxy(); nil connects directly.
+	Proxy *ProxyConfig
+}
+
+// Rate returns the price of bitcoin in the currency.
+func (s *CoinGeckoRateProvider) Rate(ctx context.Context, currency string) (*Rate, error) {
+	query := url.Values{"ids": {"bitcoin"}, "vs_currencies": {strings.ToLower(currency)}}
+	var prices map[string]map[string]float64
+	if err := getJSON(ctx, s.Client, s.Proxy, baseURL(s.BaseURL, defaultCoinGeckoURL)+"/api/v3/simple/price?"+query.Encode(), &prices); err != nil {
+		return nil, err
+	}
+	price, ok := prices["bitcoin"][strings.ToLower(currency)]
+	if !ok {
+		return nil, ErrRateUnavailable
+	}
+	return newRate(currency, price)
+}
+
+// KrakenRateProvider queries the public ticker API of Kraken.
+type KrakenRateProvider struct {
+	// BaseURL defaults to https://api.kraken.com.
+	BaseURL string
+	// Client defaults to a client connecting through Proxy.
+	Client *http.Client
+	// Proxy routes the requests, e.g. TorProxy(); nil connects directly.
+	Proxy *ProxyConfig
+}
+
+// Rate returns the price of the last trade of bitcoin in the currency.
+func (s *KrakenRateProvider) Rate(ctx context.Context, currency string) (*Rate, error) {
+	var ticker struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			// Close is the price and volume of the last trade.
+			Close []string `json:"c"`
+		} `json:"result"`
+	}
+	pair := "XBT" + strings.ToUpper(currency)
+	if err := getJSON(ctx, s.Client, s.Proxy, baseURL(s.BaseURL, defaultKrakenURL)+"/0/public/Ticker?pair="+url.QueryEscape(pair), &ticker); err != nil {
+		return nil, err
+	}
+	if len(ticker.Error) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRateUnavailable, strings.Join(ticker.Error, ", "))
+	}
+	for _, result := range ticker.Result {
+		if len(result.Close) == 0 {
+			break
+		}
+		price, err := strconv.ParseFloat(result.Close[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		return newRate(currency, price)
+	}
+	return nil, ErrRateUnavailable
+}
+
+// newRate returns the current rate of a currency.
+func newRate(currency string, price float64) (*Rate, error) {
+	if price <= 0 {
+		return nil, ErrRateInvalid
+	}
+	return &Rate{Currency: strings.ToUpper(currency), Price: price, Time: time.Now()}, nil
+}
+
+// baseURL returns url without its trailing slash, or a default.
+func baseURL(url, defaultURL string) string {
+	if url == "" {
+		return defaultURL
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// getJSON decodes the JSON response of a GET request sent by client, or by
+// a client connecting through the proxy when client is nil.
+func getJSON(ctx context.Context, client *http.Client, config *ProxyConfig, url string, v interface{}) error {
+	if client == nil {
+		client = NewHTTPClient(config, 0)
+		defer client.CloseIdleConnections()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", ErrRateUnavailable, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
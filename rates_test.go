@@ -0,0 +1,95 @@
+package p2pkh
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingRateProvider returns a fixed rate and counts its queries.
+type countingRateProvider struct {
+	price   float64
+	queries int
+}
+
+func (s *countingRateProvider) Rate(_ context.Context, currency string) (*Rate, error) {
+	s.queries++
+	return &Rate{Currency: currency, Price: s.price}, nil
+}
+
+func Test_Rate(t *testing.T) {
+	rate := &Rate{Currency: "USD", Price: 50000}
+	assert.Equal(t, 25.0, rate.Fiat(50000))
+	assert.Equal(t, "25.00 USD", rate.Format(50000))
+	assert.Equal(t, Amount(50000), rate.Sats(25))
+
+	request, err := rate.PaymentRequest("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", 10, "Coffee")
+	assert.NoError(t, err)
+	assert.Equal(t, "bitcoin:1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr?amount=0.0002&label=Coffee", request.URI())
+
+	_, err = (&Rate{Currency: "USD"}).PaymentRequest("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", 10, "")
+	assert.ErrorIs(t, err, ErrRateInvalid)
+}
+
+func Test_CachedRateProvider(t *testing.T) {
+	provider := &countingRateProvider{price: 30000}
+	cache := NewCachedRateProvider(provider, time.Minute)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	rate, err := cache.Rate(context.Background(), "eur")
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", rate.Currency)
+	_, err = cache.Rate(context.Background(), "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, provider.queries)
+
+	now = now.Add(2 * time.Minute)
+	_, err = cache.Rate(context.Background(), "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, provider.queries)
+}
+
+func Test_CoinGeckoRateProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/simple/price", r.URL.Path)
+		assert.Equal(t, "usd", r.URL.Query().Get("vs_currencies"))
+		w.Write([]byte(`{"bitcoin": {"usd": 43210.5}}`))
+	}))
+	defer server.Close()
+
+	provider := &CoinGeckoRateProvider{BaseURL: server.URL}
+	rate, err := provider.Rate(context.Background(), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", rate.Currency)
+	assert.Equal(t, 43210.5, rate.Price)
+}
+
+func Test_KrakenRateProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("pair") {
+		case "XBTEUR":
+			w.Write([]byte(`{"error": [], "result": {"XXBTZEUR": {"c": ["39876.10000", "0.00100000"]}}}`))
+		case "XBTXYZ":
+			w.Write([]byte(`{"error": ["EQuery:Unknown asset pair"]}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	provider := &KrakenRateProvider{BaseURL: server.URL + "/"}
+	rate, err := provider.Rate(context.Background(), "eur")
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", rate.Currency)
+	assert.Equal(t, 39876.1, rate.Price)
+
+	_, err = provider.Rate(context.Background(), "XYZ")
+	assert.EqualError(t, err, ErrRateUnavailable.Error()+": EQuery:Unknown asset pair")
+	_, err = provider.Rate(context.Background(), "GBP")
+	assert.ErrorIs(t, err, ErrRateUnavailable)
+}
@@ -0,0 +1,155 @@
+package p2pkh
+
+import (
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrNotReplaceable Error = "transaction does not signal replaceability"
+	ErrBumpNoChange   Error = "transaction has no change output to pay the fee bump"
+	ErrBumpFeeRate    Error = "fee rate does not exceed the one of the replaced transaction"
+
+	// rbfSequence is the nSequence of the inputs of transactions signaling
+	// replaceability (BIP125), below 0xfffffffe so that the lock time of the
+	// transaction still applies.
+	rbfSequence = wire.MaxTxInSequenceNum - 2
+)
+
+// WithRBF signals that the transaction may be replaced by one paying a
+// higher fee (BIP125), e.g. with Wallet.BumpFee when it is stuck.
+func (s *TxBuilder) WithRBF() *TxBuilder {
+	s.rbf = true
+	return s
+}
+
+// Bump returns the replacement of a draft signaling RBF, spending the same
+// inputs to the same outputs at a higher fee rate, in sat/vbyte. The fee
+// increase is taken from the change output, which is dropped when what
+// remains would be dust. The replacement pays at least the fee of the draft
+// plus its own relay fee, as BIP125 requires.
+func (s *Draft) Bump(feeRate FeeRate) (*Draft, error) {
+	if !s.RBF {
+		return nil, ErrNotReplaceable
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	change := -1
+	for i, out := range s.Outputs {
+		if out.Change {
+			change = i
+			break
+		}
+	}
+	if change < 0 {
+		return nil, ErrBumpNoChange
+	}
+
+	vsize, err := s.vsize(s.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := feeRate.Fee(vsize)
+	if err != nil {
+		return nil, err
+	}
+	if fee <= s.Fee {
+		return nil, ErrBumpFeeRate
+	}
+	minFee, err := s.minReplacementFee(vsize)
+	if err != nil {
+		return nil, err
+	}
+	if fee < minFee {
+		fee = minFee
+	}
+
+	outputs := append([]DraftOutput(nil), s.Outputs...)
+	_, changeScript, err := parseAddressScript(outputs[change].Address, s.Network)
+	if err != nil {
+		return nil, err
+	}
+	remaining := outputs[change].Amount - (fee - s.Fee)
+	dust, err := dustThreshold(wire.NewTxOut(0, changeScript), defaultDustFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	if remaining >= dust {
+		outputs[change].Amount = remaining
+	} else {
+		// Without change, the replacement is smaller but must still pay its
+		// fee.
+		outputs = append(outputs[:change], outputs[change+1:]...)
+		if len(outputs) == 0 {
+			return nil, ErrInsufficientFunds
+		}
+		if vsize, err = s.vsize(outputs); err != nil {
+			return nil, err
+		}
+		funds := s.InputAmount()
+		for _, out := range outputs {
+			funds -= out.Amount
+		}
+		if fee, err = feeRate.Fee(vsize); err != nil {
+			return nil, err
+		}
+		if minFee, err = s.minReplacementFee(vsize); err != nil {
+			return nil, err
+		}
+		if funds < fee || funds < minFee {
+			return nil, ErrInsufficientFunds
+		}
+	}
+
+	draft, err := NewDraft(s.Network, s.Inputs, outputs)
+	if err != nil {
+		return nil, err
+	}
+	draft.Version = s.Version
+	draft.LockTime = s.LockTime
+	draft.RBF = true
+	return draft, nil
+}
+
+// BumpFee rebuilds and signs the replacement of a stuck transaction, given
+// by the draft it was signed from, at a higher fee rate: see Draft.Bump. The
+// draft records the amounts and scripts of the inputs and the change output,
+// which the raw transaction does not.
+func (s *Wallet) BumpFee(old *Draft, feeRate FeeRate) ([]byte, error) {
+	draft, err := old.Bump(feeRate)
+	if err != nil {
+		return nil, err
+	}
+	return s.SignDraft(draft)
+}
+
+// vsize estimates the virtual size of the signed draft with the outputs.
+func (s *Draft) vsize(outputs []DraftOutput) (int64, error) {
+	inputTypes := make([]AddressType, 0, len(s.Inputs))
+	for _, in := range s.Inputs {
+		inputType, err := ScriptAddressType(in.PkScript)
+		if err != nil {
+			return 0, err
+		}
+		inputTypes = append(inputTypes, inputType)
+	}
+	outputTypes := make([]AddressType, 0, len(outputs))
+	for _, out := range outputs {
+		addr, _, err := parseAddressScript(out.Address, s.Network)
+		if err != nil {
+			return 0, err
+		}
+		outputTypes = append(outputTypes, addr.Type())
+	}
+	return EstimateVSize(inputTypes, outputTypes)
+}
+
+// minReplacementFee returns the least fee of a replacement of vsize vbytes:
+// the fee of the draft plus the relay fee of the replacement.
+func (s *Draft) minReplacementFee(vsize int64) (Amount, error) {
+	relayFee, err := FeeRate(defaultMinRelayFeeRate).Fee(vsize)
+	if err != nil {
+		return 0, err
+	}
+	return s.Fee.Add(relayFee)
+}
@@ -0,0 +1,126 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// rbfDraft returns a draft paying 60000 sats out of a 100000 sats UTXO at
+// 2 sat/vB, with change, signaling RBF.
+func rbfDraft(t *testing.T, wallet *Wallet) (*Draft, UTXO) {
+	utxo := walletUTXO(t, wallet, 0, 100000)
+	draft, err := wallet.NewTransaction().
+		AddInput(utxo).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 60000).
+		WithFeeRate(2).
+		WithRBF().
+		Draft()
+	assert.NoError(t, err)
+	return draft, utxo
+}
+
+func Test_WithRBF(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	draft, utxo := rbfDraft(t, wallet)
+	assert.True(t, draft.RBF)
+
+	rawTx, err := wallet.SignDraft(draft)
+	assert.NoError(t, err)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Equal(t, uint32(0xfffffffd), tx.TxIn[0].Sequence)
+	verifyTx(t, rawTx, []UTXO{utxo})
+
+	data, err := json.Marshal(draft)
+	assert.NoError(t, err)
+	parsed, err := ParseDraft(data)
+	assert.NoError(t, err)
+	assert.True(t, parsed.RBF)
+
+	final, err := wallet.NewTransaction().AddInput(utxo).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 60000).Draft()
+	assert.NoError(t, err)
+	assert.False(t, final.RBF)
+	data, err = json.Marshal(final)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "rbf")
+}
+
+func Test_BumpFee(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	old, utxo := rbfDraft(t, wallet)
+
+	bumped, err := old.Bump(10)
+	assert.NoError(t, err)
+	assert.True(t, bumped.RBF)
+	assert.Equal(t, old.Inputs, bumped.Inputs)
+	assert.Len(t, bumped.Outputs, 2)
+	assert.Equal(t, old.Outputs[0], bumped.Outputs[0])
+	vsize, err := bumped.vsize(bumped.Outputs)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(vsize*10), bumped.Fee)
+	assert.Equal(t, old.Outputs[1].Amount-(bumped.Fee-old.Fee), bumped.Outputs[1].Amount)
+
+	rawTx, err := wallet.BumpFee(old, 10)
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, []UTXO{utxo})
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Equal(t, uint32(0xfffffffd), tx.TxIn[0].Sequence)
+}
+
+func Test_BumpFee_MinRelayFee(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	old, _ := rbfDraft(t, wallet)
+
+	// A rate one sat above is not enough to also pay the relay of the
+	// replacement: the fee increases by the vsize at least.
+	vsize, err := old.vsize(old.Outputs)
+	assert.NoError(t, err)
+	bumped, err := old.Bump(3)
+	assert.NoError(t, err)
+	assert.Equal(t, old.Fee+Amount(vsize), bumped.Fee)
+
+	_, err = old.Bump(2)
+	assert.ErrorIs(t, err, ErrBumpFeeRate)
+}
+
+func Test_BumpFee_DropChange(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 100000)
+	old, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: utxo}},
+		[]DraftOutput{
+			{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 99000},
+			{Address: wallet.AddressHex(), Amount: 600, Change: true},
+		})
+	assert.NoError(t, err)
+	old.RBF = true
+
+	bumped, err := old.Bump(3)
+	assert.NoError(t, err)
+	assert.Len(t, bumped.Outputs, 1)
+	assert.Equal(t, Amount(1000), bumped.Fee)
+
+	_, err = old.Bump(10)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func Test_BumpFee_Errors(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 100000)
+	old, err := NewDraft(NetworkMainnet,
+		[]DraftInput{{UTXO: utxo}},
+		[]DraftOutput{{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 99000}})
+	assert.NoError(t, err)
+
+	_, err = wallet.BumpFee(old, 10)
+	assert.ErrorIs(t, err, ErrNotReplaceable)
+
+	old.RBF = true
+	_, err = wallet.BumpFee(old, 10)
+	assert.ErrorIs(t, err, ErrBumpNoChange)
+}
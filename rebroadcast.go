@@ -0,0 +1,275 @@
+package p2pkh
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	ErrTxAlreadyTracked Error = "transaction is already tracked"
+	ErrEmptyRawTx       Error = "raw transaction is required"
+
+	defaultRebroadcastInitialInterval = time.Minute
+	defaultRebroadcastMaxInterval     = time.Hour
+	defaultRebroadcastMultiplier      = 2
+)
+
+// RebroadcastConfig configures a Rebroadcaster. Zero values select sensible
+// defaults.
+type RebroadcastConfig struct {
+	// InitialInterval is the delay before the first rebroadcast.
+	InitialInterval time.Duration
+	// MaxInterval caps the exponential backoff between two rebroadcasts.
+	MaxInterval time.Duration
+	// Multiplier is applied to the interval after every attempt.
+	Multiplier float64
+	// MaxAttempts abandons a transaction after that many rebroadcasts (0 means never).
+	MaxAttempts int
+	// EscalateAfter is the number of attempts after which Escalate is called (0 disables it).
+	EscalateAfter int
+	// Escalate is a hook used to replace a stuck transaction, typically by a
+	// RBF fee bump. It returns the replacement raw transaction, or nil to keep
+	// rebroadcasting the current one.
+	Escalate func(txid string, rawTx []byte, attempts int) ([]byte, error)
+	// OnDone is called once a transaction leaves the scheduler, either because
+	// it confirmed or because it was abandoned. A transaction replaced by
+	// Escalate is not done: it is watched along with its replacement, since
+	// either may confirm. Once one of them confirms, or the replacement is
+	// abandoned, OnDone is called for every version of the transaction,
+	// confirmed for the one that confirmed and not for the others.
+	OnDone func(txid string, confirmed bool)
+}
+
+// pendingTx is a broadcast-but-unconfirmed transaction scheduled for rebroadcast.
+type pendingTx struct {
+	txid     string
+	rawTx    []byte
+	attempts int
+	interval time.Duration
+	next     time.Time
+	// replaced are the txids of the transactions it replaced, oldest first,
+	// watched until one of the versions confirms.
+	replaced []string
+}
+
+// Rebroadcaster tracks broadcast-but-unconfirmed transactions and periodically
+// rebroadcasts them through the backend until they confirm or are abandoned.
+type Rebroadcaster struct {
+	mu      sync.Mutex
+	backend Broadcaster
+	config  RebroadcastConfig
+	pending map[string]*pendingTx
+	now     func() time.Time
+}
+
+// NewRebroadcaster creates a Rebroadcaster publishing through the given backend.
+func NewRebroadcaster(backend Broadcaster, config *RebroadcastConfig) *Rebroadcaster {
+	cfg := RebroadcastConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.InitialInterval <= 0 {
+		cfg.InitialInterval = defaultRebroadcastInitialInterval
+	}
+	if cfg.MaxInterval <= 0 {
+		cfg.MaxInterval = defaultRebroadcastMaxInterval
+	}
+	if cfg.Multiplier < 1 {
+		cfg.Multiplier = defaultRebroadcastMultiplier
+	}
+	return &Rebroadcaster{
+		backend: backend,
+		config:  cfg,
+		pending: make(map[string]*pendingTx),
+		now:     time.Now,
+	}
+}
+
+// Track schedules an already broadcast transaction for rebroadcast.
+func (s *Rebroadcaster) Track(txid string, rawTx []byte) error {
+	if len(rawTx) == 0 {
+		return ErrEmptyRawTx
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pending[txid]; ok {
+		return ErrTxAlreadyTracked
+	}
+	s.pending[txid] = &pendingTx{
+		txid:     txid,
+		rawTx:    append([]byte(nil), rawTx...),
+		interval: s.config.InitialInterval,
+		next:     s.now().Add(s.config.InitialInterval),
+	}
+	return nil
+}
+
+// Abandon stops rebroadcasting a transaction. It reports whether the
+// transaction was tracked.
+func (s *Rebroadcaster) Abandon(txid string) bool {
+	s.mu.Lock()
+	tx, ok := s.pending[txid]
+	s.mu.Unlock()
+
+	return ok && s.finish(tx, "")
+}
+
+// Pending returns the txids currently scheduled for rebroadcast, sorted. The
+// transactions replaced by Escalate, only watched, are not listed.
+func (s *Rebroadcaster) Pending() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txids := make([]string, 0, len(s.pending))
+	for txid := range s.pending {
+		txids = append(txids, txid)
+	}
+	sort.Strings(txids)
+	return txids
+}
+
+// Tick processes every transaction whose rebroadcast is due: confirmed ones
+// are dropped, the others are rebroadcast (or escalated) and rescheduled with
+// exponential backoff. It returns the first backend error encountered.
+func (s *Rebroadcaster) Tick(ctx context.Context) error {
+	now := s.now()
+
+	s.mu.Lock()
+	due := make([]*pendingTx, 0, len(s.pending))
+	for _, tx := range s.pending {
+		if !tx.next.After(now) {
+			due = append(due, tx)
+		}
+	}
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, tx := range due {
+		if err := s.process(ctx, tx, now); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Run calls Tick every pollInterval until the context is cancelled.
+func (s *Rebroadcaster) Run(ctx context.Context, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_ = s.Tick(ctx)
+		}
+	}
+}
+
+// process checks and rebroadcasts a single due transaction.
+func (s *Rebroadcaster) process(ctx context.Context, tx *pendingTx, now time.Time) error {
+	status, err := s.backend.TxStatus(ctx, tx.txid)
+	if err != nil {
+		s.reschedule(tx, now)
+		return err
+	}
+	if status.Confirmed() {
+		s.finish(tx, tx.txid)
+		return nil
+	}
+	for _, txid := range tx.replaced {
+		status, err := s.backend.TxStatus(ctx, txid)
+		if err != nil {
+			s.reschedule(tx, now)
+			return err
+		}
+		if status.Confirmed() {
+			s.finish(tx, txid)
+			return nil
+		}
+	}
+
+	if s.config.MaxAttempts > 0 && tx.attempts >= s.config.MaxAttempts {
+		s.finish(tx, "")
+		return nil
+	}
+
+	if s.config.Escalate != nil && s.config.EscalateAfter > 0 && tx.attempts >= s.config.EscalateAfter {
+		replacement, err := s.config.Escalate(tx.txid, tx.rawTx, tx.attempts)
+		if err != nil {
+			s.reschedule(tx, now)
+			return err
+		}
+		if len(replacement) > 0 {
+			return s.replace(ctx, tx, replacement)
+		}
+	}
+
+	_, err = s.backend.Broadcast(ctx, tx.rawTx)
+	s.mu.Lock()
+	tx.attempts++
+	s.mu.Unlock()
+	s.reschedule(tx, now)
+	return err
+}
+
+// replace broadcasts a replacement transaction and rebroadcasts it instead of
+// the original, which stays watched in case it confirms first.
+func (s *Rebroadcaster) replace(ctx context.Context, tx *pendingTx, rawTx []byte) error {
+	txid, err := s.backend.Broadcast(ctx, rawTx)
+	if err != nil {
+		s.reschedule(tx, s.now())
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.pending, tx.txid)
+	s.pending[txid] = &pendingTx{
+		txid:     txid,
+		rawTx:    append([]byte(nil), rawTx...),
+		interval: s.config.InitialInterval,
+		next:     s.now().Add(s.config.InitialInterval),
+		replaced: append(tx.replaced[:len(tx.replaced):len(tx.replaced)], tx.txid),
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// reschedule computes the next rebroadcast time with exponential backoff.
+func (s *Rebroadcaster) reschedule(tx *pendingTx, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx.next = now.Add(tx.interval)
+	next := time.Duration(float64(tx.interval) * s.config.Multiplier)
+	if next > s.config.MaxInterval {
+		next = s.config.MaxInterval
+	}
+	tx.interval = next
+}
+
+// finish removes a transaction from the scheduler and notifies OnDone of
+// every version of it, confirmedTxid being the one that confirmed, empty
+// when it was abandoned. It reports whether the transaction was still
+// scheduled, so that OnDone is called once.
+func (s *Rebroadcaster) finish(tx *pendingTx, confirmedTxid string) bool {
+	s.mu.Lock()
+	if s.pending[tx.txid] != tx {
+		s.mu.Unlock()
+		return false
+	}
+	delete(s.pending, tx.txid)
+	s.mu.Unlock()
+
+	if s.config.OnDone != nil {
+		for _, txid := range append(tx.replaced[:len(tx.replaced):len(tx.replaced)], tx.txid) {
+			s.config.OnDone(txid, txid == confirmedTxid)
+		}
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced clock used to drive schedulers in tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (s *fakeClock) Now() time.Time {
+	return s.now
+}
+
+func (s *fakeClock) Advance(d time.Duration) {
+	s.now = s.now.Add(d)
+}
+
+func newTestRebroadcaster(backend Broadcaster, config *RebroadcastConfig) (*Rebroadcaster, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	r := NewRebroadcaster(backend, config)
+	r.now = clock.Now
+	return r, clock
+}
+
+func Test_Rebroadcaster_Track(t *testing.T) {
+	r, _ := newTestRebroadcaster(newFakeBackend(), nil)
+
+	assert.NoError(t, r.Track("tx1", []byte{0x01}))
+	assert.ErrorIs(t, r.Track("tx1", []byte{0x01}), ErrTxAlreadyTracked)
+	assert.ErrorIs(t, r.Track("tx2", nil), ErrEmptyRawTx)
+	assert.Equal(t, []string{"tx1"}, r.Pending())
+
+	assert.True(t, r.Abandon("tx1"))
+	assert.False(t, r.Abandon("tx1"))
+	assert.Empty(t, r.Pending())
+}
+
+func Test_Rebroadcaster_Backoff(t *testing.T) {
+	backend := newFakeBackend()
+	r, clock := newTestRebroadcaster(backend, &RebroadcastConfig{
+		InitialInterval: time.Minute,
+		MaxInterval:     3 * time.Minute,
+	})
+	rawTx := []byte{0x01, 0x02}
+	txid := fakeTxID(rawTx)
+	assert.NoError(t, r.Track(txid, rawTx))
+
+	// Nothing is due before the initial interval.
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, 0, backend.broadcastCount())
+
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, 1, backend.broadcastCount())
+
+	// The next attempt is scheduled one interval later, then the interval doubles.
+	clock.Advance(59 * time.Second)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, 1, backend.broadcastCount())
+
+	clock.Advance(time.Second)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, 2, backend.broadcastCount())
+
+	clock.Advance(2 * time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, 3, backend.broadcastCount())
+
+	// The interval is capped by MaxInterval.
+	clock.Advance(3 * time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, 4, backend.broadcastCount())
+}
+
+func Test_Rebroadcaster_Confirmed(t *testing.T) {
+	backend := newFakeBackend()
+	var done []string
+	r, clock := newTestRebroadcaster(backend, &RebroadcastConfig{
+		OnDone: func(txid string, confirmed bool) {
+			assert.True(t, confirmed)
+			done = append(done, txid)
+		},
+	})
+	assert.NoError(t, r.Track("tx1", []byte{0x01}))
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1})
+
+	clock.Advance(time.Hour)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, 0, backend.broadcastCount())
+	assert.Empty(t, r.Pending())
+	assert.Equal(t, []string{"tx1"}, done)
+}
+
+func Test_Rebroadcaster_MaxAttempts(t *testing.T) {
+	backend := newFakeBackend()
+	abandoned := false
+	r, clock := newTestRebroadcaster(backend, &RebroadcastConfig{
+		InitialInterval: time.Minute,
+		MaxInterval:     time.Minute,
+		MaxAttempts:     2,
+		OnDone: func(_ string, confirmed bool) {
+			abandoned = !confirmed
+		},
+	})
+	assert.NoError(t, r.Track("tx1", []byte{0x01}))
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Minute)
+		assert.NoError(t, r.Tick(context.Background()))
+	}
+	assert.Equal(t, 2, backend.broadcastCount())
+	assert.Empty(t, r.Pending())
+	assert.True(t, abandoned)
+}
+
+func Test_Rebroadcaster_Escalate(t *testing.T) {
+	backend := newFakeBackend()
+	replacement := []byte{0x02}
+	r, clock := newTestRebroadcaster(backend, &RebroadcastConfig{
+		InitialInterval: time.Minute,
+		MaxInterval:     time.Minute,
+		EscalateAfter:   1,
+		Escalate: func(txid string, rawTx []byte, attempts int) ([]byte, error) {
+			assert.Equal(t, "tx1", txid)
+			assert.Equal(t, 1, attempts)
+			return replacement, nil
+		},
+	})
+	assert.NoError(t, r.Track("tx1", []byte{0x01}))
+
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+
+	assert.Equal(t, 2, backend.broadcastCount())
+	assert.Equal(t, []string{fakeTxID(replacement)}, r.Pending())
+}
+
+func Test_Rebroadcaster_EscalateOriginalConfirmed(t *testing.T) {
+	backend := newFakeBackend()
+	replacement := []byte{0x02}
+	done := map[string]bool{}
+	r, clock := newTestRebroadcaster(backend, &RebroadcastConfig{
+		InitialInterval: time.Minute,
+		MaxInterval:     time.Minute,
+		EscalateAfter:   1,
+		Escalate: func(string, []byte, int) ([]byte, error) {
+			return replacement, nil
+		},
+		OnDone: func(txid string, confirmed bool) {
+			done[txid] = confirmed
+		},
+	})
+	assert.NoError(t, r.Track("tx1", []byte{0x01}))
+
+	// The replaced transaction is not done: it may still confirm.
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Equal(t, []string{fakeTxID(replacement)}, r.Pending())
+	assert.Empty(t, done)
+
+	// The original confirms instead of its replacement.
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1})
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.Empty(t, r.Pending())
+	assert.Equal(t, map[string]bool{"tx1": true, fakeTxID(replacement): false}, done)
+
+	// Abandoning a replacement abandons the transactions it replaced.
+	done = map[string]bool{}
+	assert.NoError(t, r.Track("tx2", []byte{0x03}))
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	clock.Advance(time.Minute)
+	assert.NoError(t, r.Tick(context.Background()))
+	assert.True(t, r.Abandon(fakeTxID(replacement)))
+	assert.Equal(t, map[string]bool{"tx2": false, fakeTxID(replacement): false}, done)
+}
+
+func Test_Rebroadcaster_BackendError(t *testing.T) {
+	backend := newFakeBackend()
+	backend.err = errors.New("backend down")
+	r, clock := newTestRebroadcaster(backend, nil)
+	assert.NoError(t, r.Track("tx1", []byte{0x01}))
+
+	clock.Advance(time.Hour)
+	assert.EqualError(t, r.Tick(context.Background()), "backend down")
+	assert.Equal(t, []string{"tx1"}, r.Pending())
+}
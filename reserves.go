@@ -0,0 +1,148 @@
+package p2pkh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	ErrReserveProofEmpty   Error = "reserve proof has no entries"
+	ErrReserveProofNetwork Error = "reserve proof network does not match"
+	ErrReserveUTXOScript   Error = "reserve UTXO does not pay to the proven address"
+	ErrReserveUTXOSpent    Error = "reserve UTXO is not unspent"
+)
+
+// ReserveEntry proves control of an address holding UTXOs: Signature is a
+// BIP322 signature of the proof challenge by Address.
+type ReserveEntry struct {
+	Address   string `json:"address"`
+	UTXOs     []UTXO `json:"utxos"`
+	Signature string `json:"signature"`
+}
+
+// ReserveProof is a proof-of-reserves bundle: the wallet addresses holding
+// funds, each signing the same challenge message.
+type ReserveProof struct {
+	Challenge string         `json:"challenge"`
+	Network   Network        `json:"network"`
+	Entries   []ReserveEntry `json:"entries"`
+}
+
+// Amount returns the sum of the UTXOs listed by the proof, verified or not.
+func (s *ReserveProof) Amount() Amount {
+	var amount Amount
+	for _, entry := range s.Entries {
+		for _, utxo := range entry.UTXOs {
+			amount += utxo.Amount
+		}
+	}
+	return amount
+}
+
+// ProveReserves builds a proof of the reserves held by the given inputs,
+// signing challenge with the key of each input path. Inputs sharing a key
+// are grouped in a single entry.
+func (s *Wallet) ProveReserves(challenge string, inputs []DraftInput) (*ReserveProof, error) {
+	if len(inputs) == 0 {
+		return nil, ErrReserveProofEmpty
+	}
+	proof := &ReserveProof{Challenge: challenge, Network: s.network}
+	entries := make(map[string]int)
+	for _, in := range inputs {
+		key, err := s.inputKey(in.Path)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := key.ECPrivKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkP2PKHScript(in.PkScript, privateKey.PubKey(), true); err != nil {
+			return nil, err
+		}
+		addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(privateKey.PubKey().SerializeCompressed()), s.params)
+		if err != nil {
+			return nil, err
+		}
+
+		address := addr.EncodeAddress()
+		i, ok := entries[address]
+		if !ok {
+			signature, err := signMessageBIP322(privateKey, challenge, s.params)
+			if err != nil {
+				return nil, err
+			}
+			s.logSign("reserve_proof", in.Path, slog.String("challenge", challenge))
+			i = len(proof.Entries)
+			entries[address] = i
+			proof.Entries = append(proof.Entries, ReserveEntry{Address: address, Signature: signature})
+		}
+		proof.Entries[i].UTXOs = append(proof.Entries[i].UTXOs, in.UTXO)
+	}
+	return proof, nil
+}
+
+// VerifyReserveProof checks the signatures of a proof for the expected
+// challenge and network, and that every UTXO pays to its entry address. When
+// provider is not nil, the UTXOs must also still be unspent. It returns the
+// proven balance, in satoshis.
+func VerifyReserveProof(ctx context.Context, proof *ReserveProof, challenge string, network Network, provider UTXOProvider) (Amount, error) {
+	if proof.Network != network {
+		return 0, ErrReserveProofNetwork
+	}
+	if len(proof.Entries) == 0 {
+		return 0, ErrReserveProofEmpty
+	}
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return 0, err
+	}
+	if proof.Challenge != challenge {
+		return 0, ErrMessageSignature
+	}
+
+	var balance Amount
+	for _, entry := range proof.Entries {
+		if err := VerifyMessageBIP322(entry.Address, challenge, entry.Signature, network); err != nil {
+			return 0, fmt.Errorf("%s: %w", entry.Address, err)
+		}
+		addr, err := decodeAddress(entry.Address, params)
+		if err != nil {
+			return 0, err
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return 0, err
+		}
+
+		var unspent map[string]bool
+		if provider != nil {
+			utxos, err := provider.AddressUTXOs(ctx, entry.Address)
+			if err != nil {
+				return 0, err
+			}
+			unspent = make(map[string]bool, len(utxos))
+			for _, utxo := range utxos {
+				unspent[utxo.outpoint()] = true
+			}
+		}
+
+		for _, utxo := range entry.UTXOs {
+			if !bytes.Equal(utxo.PkScript, pkScript) {
+				return 0, fmt.Errorf("%w: %s:%d", ErrReserveUTXOScript, utxo.TxID, utxo.Vout)
+			}
+			if unspent != nil && !unspent[utxo.outpoint()] {
+				return 0, fmt.Errorf("%w: %s:%d", ErrReserveUTXOSpent, utxo.TxID, utxo.Vout)
+			}
+			if balance, err = balance.Add(utxo.Amount); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return balance, nil
+}
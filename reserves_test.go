@@ -0,0 +1,64 @@
+package p2pkh
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProveReserves(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(1)
+	assert.NoError(t, err)
+	childUTXO := walletUTXO(t, child, 2, 30000)
+	inputs := []DraftInput{
+		{UTXO: walletUTXO(t, wallet, 0, 10000)},
+		{UTXO: walletUTXO(t, wallet, 1, 20000)},
+		{UTXO: childUTXO, Path: child.Path()},
+	}
+
+	proof, err := wallet.ProveReserves("exchange audit 2026-10", inputs)
+	assert.NoError(t, err)
+	assert.Len(t, proof.Entries, 2)
+	assert.Equal(t, wallet.AddressHex(), proof.Entries[0].Address)
+	assert.Len(t, proof.Entries[0].UTXOs, 2)
+	assert.Equal(t, Amount(60000), proof.Amount())
+
+	// The proof survives a JSON round trip.
+	data, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	var decoded ReserveProof
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+
+	ctx := context.Background()
+	balance, err := VerifyReserveProof(ctx, &decoded, "exchange audit 2026-10", NetworkMainnet, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(60000), balance)
+
+	_, err = VerifyReserveProof(ctx, &decoded, "another challenge", NetworkMainnet, nil)
+	assert.ErrorIs(t, err, ErrMessageSignature)
+	_, err = VerifyReserveProof(ctx, &decoded, "exchange audit 2026-10", NetworkTestnet, nil)
+	assert.ErrorIs(t, err, ErrReserveProofNetwork)
+
+	// With a backend, spent UTXOs are rejected.
+	backend := newFakeBackend()
+	backend.addUTXOs(wallet.AddressHex(), inputs[0].UTXO, inputs[1].UTXO)
+	_, err = VerifyReserveProof(ctx, &decoded, "exchange audit 2026-10", NetworkMainnet, backend)
+	assert.ErrorIs(t, err, ErrReserveUTXOSpent)
+	backend.addUTXOs(child.AddressHex(), childUTXO)
+	balance, err = VerifyReserveProof(ctx, &decoded, "exchange audit 2026-10", NetworkMainnet, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(60000), balance)
+
+	// UTXOs of another address cannot be claimed.
+	decoded.Entries[1].UTXOs = append(decoded.Entries[1].UTXOs, inputs[0].UTXO)
+	_, err = VerifyReserveProof(ctx, &decoded, "exchange audit 2026-10", NetworkMainnet, nil)
+	assert.ErrorIs(t, err, ErrReserveUTXOScript)
+
+	_, err = wallet.ProveReserves("challenge", nil)
+	assert.ErrorIs(t, err, ErrReserveProofEmpty)
+	_, err = wallet.ProveReserves("challenge", []DraftInput{{UTXO: childUTXO}})
+	assert.ErrorIs(t, err, ErrInputScriptMismatch)
+}
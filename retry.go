@@ -0,0 +1,319 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	ErrBackendUnavailable Error = "backend temporarily unavailable"
+
+	defaultRetryMaxRetries       = 3
+	defaultRetryBaseDelay        = 200 * time.Millisecond
+	defaultRetryMaxDelay         = 10 * time.Second
+	defaultRetryFailureThreshold = 5
+	defaultRetryCooldown         = 30 * time.Second
+)
+
+// BackendUnavailableError reports a backend host failing after every retry,
+// or whose circuit is open. Callers can degrade gracefully, e.g. show cached
+// balances, instead of failing.
+type BackendUnavailableError struct {
+	Host string
+	// Err is the last failure, nil when the circuit was already open.
+	Err error
+}
+
+// Error returns ErrBackendUnavailable with the host and last failure.
+func (s *BackendUnavailableError) Error() string {
+	if s.Err == nil {
+		return fmt.Sprintf("%s: %s", ErrBackendUnavailable, s.Host)
+	}
+	return fmt.Sprintf("%s: %s: %s", ErrBackendUnavailable, s.Host, s.Err)
+}
+
+// Unwrap returns the last failure.
+func (s *BackendUnavailableError) Unwrap() error {
+	return s.Err
+}
+
+// Is reports whether target is ErrBackendUnavailable.
+func (s *BackendUnavailableError) Is(target error) bool {
+	return target == ErrBackendUnavailable
+}
+
+// permanentError is a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (s *permanentError) Error() string { return s.err.Error() }
+func (s *permanentError) Unwrap() error { return s.err }
+
+// Permanent marks an error returned to Retrier.Do as not worth retrying,
+// e.g. a transaction rejected by the backend.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// RetryConfig configures a Retrier. Zero values select sensible defaults.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after a failed attempt; a negative
+	// value disables retrying.
+	MaxRetries int
+	// BaseDelay and MaxDelay bound the jittered exponential backoff: the
+	// delay before retry n is random between 0 and BaseDelay*2^n.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// RequestsPerSecond limits the attempts per host (0 means unlimited),
+	// with bursts of up to Burst requests.
+	RequestsPerSecond float64
+	Burst             int
+	// FailureThreshold consecutive failures open the circuit of a host,
+	// failing calls immediately during Cooldown. A negative value disables
+	// the circuit breaker.
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// hostState is the rate limiter and circuit breaker of a host.
+type hostState struct {
+	tokens    float64
+	refilled  time.Time
+	failures  int
+	openUntil time.Time
+}
+
+// Retrier makes the calls of the backend clients resilient: it retries
+// failed calls with jittered exponential backoff, rate limits them per host
+// and opens a circuit breaker on hosts failing repeatedly. It is safe for
+// concurrent use, and meant to be shared by the clients of a host.
+type Retrier struct {
+	mu     sync.Mutex
+	config RetryConfig
+	hosts  map[string]*hostState
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetrier creates a Retrier.
+func NewRetrier(config *RetryConfig) *Retrier {
+	cfg := RetryConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultRetryMaxRetries
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = defaultRetryBaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = defaultRetryMaxDelay
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = defaultRetryFailureThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultRetryCooldown
+	}
+	return &Retrier{
+		config: cfg,
+		hosts:  make(map[string]*hostState),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// Do calls fn until it succeeds, returns a Permanent error, or the retries
+// are exhausted; it then returns a *BackendUnavailableError. Context errors
+// are returned as is.
+func (s *Retrier) Do(ctx context.Context, host string, fn func(ctx context.Context) error) error {
+	var last error
+	for attempt := 0; ; attempt++ {
+		if !s.allow(host) {
+			return &BackendUnavailableError{Host: host, Err: last}
+		}
+		if err := s.wait(ctx, host); err != nil {
+			return err
+		}
+		err := fn(ctx)
+		var permanent *permanentError
+		switch {
+		case err == nil:
+			s.record(host, true)
+			return nil
+		case errors.As(err, &permanent):
+			s.record(host, true)
+			return permanent.err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		}
+		s.record(host, false)
+		last = err
+		if s.config.MaxRetries < 0 || attempt >= s.config.MaxRetries {
+			return &BackendUnavailableError{Host: host, Err: last}
+		}
+		if err := s.sleep(ctx, s.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns the jittered delay before retry n.
+func (s *Retrier) backoff(attempt int) time.Duration {
+	limit := s.config.MaxDelay
+	if attempt < 32 && s.config.BaseDelay<<attempt < limit {
+		limit = s.config.BaseDelay << attempt
+	}
+	return time.Duration(rand.Int63n(int64(limit) + 1))
+}
+
+// allow reports whether the circuit of the host lets a call through. Once
+// the cooldown elapsed, calls are let through again, and a single failure
+// reopens the circuit.
+func (s *Retrier) allow(host string) bool {
+	if s.config.FailureThreshold < 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.host(host)
+	return !state.openUntil.After(s.now())
+}
+
+// record updates the circuit of the host after a call.
+func (s *Retrier) record(host string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.host(host)
+	if ok {
+		state.failures = 0
+		state.openUntil = time.Time{}
+		return
+	}
+	state.failures++
+	if s.config.FailureThreshold > 0 && state.failures >= s.config.FailureThreshold {
+		state.openUntil = s.now().Add(s.config.Cooldown)
+		// Half-open: the next failure reopens the circuit at once.
+		state.failures = s.config.FailureThreshold - 1
+	}
+}
+
+// wait blocks until the rate limiter of the host grants a request.
+func (s *Retrier) wait(ctx context.Context, host string) error {
+	if s.config.RequestsPerSecond <= 0 {
+		return nil
+	}
+	for {
+		s.mu.Lock()
+		state := s.host(host)
+		now := s.now()
+		if state.refilled.IsZero() {
+			state.tokens = float64(s.config.Burst)
+		} else {
+			state.tokens += now.Sub(state.refilled).Seconds() * s.config.RequestsPerSecond
+			if state.tokens > float64(s.config.Burst) {
+				state.tokens = float64(s.config.Burst)
+			}
+		}
+		state.refilled = now
+		if state.tokens >= 1 {
+			state.tokens--
+			s.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - state.tokens) / s.config.RequestsPerSecond * float64(time.Second))
+		s.mu.Unlock()
+		if err := s.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// host returns the state of a host. The lock must be held.
+func (s *Retrier) host(host string) *hostState {
+	state, ok := s.hosts[host]
+	if !ok {
+		state = &hostState{}
+		s.hosts[host] = state
+	}
+	return state
+}
+
+// Client returns a copy of client, http.DefaultClient when nil, whose
+// requests go through the retrier, per host of their URL. Network errors
+// and 429 and 5xx responses are retried.
+func (s *Retrier) Client(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	copied := *client
+	copied.Transport = &retryTransport{retrier: s, base: client.Transport}
+	return &copied
+}
+
+// retryTransport is the RoundTripper of Retrier.Client.
+type retryTransport struct {
+	retrier *Retrier
+	base    http.RoundTripper
+}
+
+// RoundTrip sends the request through the retrier. Requests whose body
+// cannot be replayed are sent once.
+func (s *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := s.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	replayable := req.Body == nil || req.GetBody != nil
+	var resp *http.Response
+	err := s.retrier.Do(req.Context(), req.URL.Host, func(ctx context.Context) error {
+		attempt := req
+		if req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return Permanent(err)
+			}
+			attempt = req.Clone(ctx)
+			attempt.Body = body
+		}
+		r, err := base.RoundTrip(attempt)
+		if err == nil && (r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= http.StatusInternalServerError) {
+			r.Body.Close()
+			err = errors.New(r.Status)
+		}
+		if err != nil && !replayable {
+			return Permanent(err)
+		}
+		resp = r
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sleepContext waits for d or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRetrier returns a retrier with a fake clock, recording its sleeps.
+func newTestRetrier(config *RetryConfig) (*Retrier, *[]time.Duration, *time.Time) {
+	retrier := NewRetrier(config)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	retrier.now = func() time.Time { return now }
+	retrier.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		now = now.Add(d)
+		return nil
+	}
+	return retrier, &slept, &now
+}
+
+func Test_Retrier_Do(t *testing.T) {
+	retrier, slept, _ := newTestRetrier(&RetryConfig{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 3 * time.Second})
+
+	calls := 0
+	err := retrier.Do(context.Background(), "host", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection reset")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, *slept, 2)
+	assert.LessOrEqual(t, (*slept)[0], time.Second)
+	assert.LessOrEqual(t, (*slept)[1], 2*time.Second)
+
+	calls = 0
+	err = retrier.Do(context.Background(), "host", func(context.Context) error {
+		calls++
+		return errors.New("connection reset")
+	})
+	var unavailable *BackendUnavailableError
+	assert.True(t, errors.As(err, &unavailable))
+	assert.Equal(t, "host", unavailable.Host)
+	assert.EqualError(t, err, ErrBackendUnavailable.Error()+": host: connection reset")
+	assert.Equal(t, 4, calls)
+	for _, d := range (*slept)[2:] {
+		assert.LessOrEqual(t, d, 3*time.Second)
+	}
+
+	// Permanent errors are returned at once.
+	calls = 0
+	err = retrier.Do(context.Background(), "host", func(context.Context) error {
+		calls++
+		return Permanent(errors.New("bad-txns-inputs-missingorspent"))
+	})
+	assert.EqualError(t, err, "bad-txns-inputs-missingorspent")
+	assert.Equal(t, 1, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = retrier.Do(ctx, "host", func(context.Context) error { return errors.New("canceled") })
+	assert.Equal(t, context.Canceled, err)
+}
+
+func Test_Retrier_CircuitBreaker(t *testing.T) {
+	retrier, _, now := newTestRetrier(&RetryConfig{MaxRetries: -1, FailureThreshold: 2, Cooldown: time.Minute})
+	fail := func(context.Context) error { return errors.New("timeout") }
+
+	assert.Error(t, retrier.Do(context.Background(), "a", fail))
+	assert.Error(t, retrier.Do(context.Background(), "a", fail))
+	calls := 0
+	err := retrier.Do(context.Background(), "a", func(context.Context) error { calls++; return nil })
+	assert.EqualError(t, err, ErrBackendUnavailable.Error()+": a")
+	assert.Equal(t, 0, calls)
+	// Other hosts are unaffected.
+	assert.NoError(t, retrier.Do(context.Background(), "b", func(context.Context) error { return nil }))
+
+	// Half-open after the cooldown: one failure reopens the circuit.
+	*now = now.Add(time.Minute)
+	assert.Error(t, retrier.Do(context.Background(), "a", fail))
+	assert.EqualError(t, retrier.Do(context.Background(), "a", func(context.Context) error { return nil }), ErrBackendUnavailable.Error()+": a")
+
+	*now = now.Add(time.Minute)
+	assert.NoError(t, retrier.Do(context.Background(), "a", func(context.Context) error { return nil }))
+	assert.Error(t, retrier.Do(context.Background(), "a", fail))
+	assert.NoError(t, retrier.Do(context.Background(), "a", func(context.Context) error { return nil }))
+}
+
+func Test_Retrier_RateLimit(t *testing.T) {
+	retrier, slept, _ := newTestRetrier(&RetryConfig{RequestsPerSecond: 2, Burst: 2})
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, retrier.Do(context.Background(), "host", func(context.Context) error { return nil }))
+	}
+	// The burst passes, then requests are spaced by 500ms.
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, *slept)
+}
+
+func Test_Retrier_Client(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"bitcoin": {"eur": 40000}}`))
+	}))
+	defer server.Close()
+
+	retrier, _, _ := newTestRetrier(nil)
+	provider := &CoinGeckoRateProvider{BaseURL: server.URL, Client: retrier.Client(nil)}
+	rate, err := provider.Rate(context.Background(), "eur")
+	assert.NoError(t, err)
+	assert.Equal(t, 40000.0, rate.Price)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Replayable bodies are sent again.
+	requests.Store(0)
+	resp, err := retrier.Client(nil).Post(server.URL, "text/plain", strings.NewReader("rawtx"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), requests.Load())
+
+	server.Close()
+	_, err = provider.Rate(context.Background(), "eur")
+	var unavailable *BackendUnavailableError
+	assert.True(t, errors.As(err, &unavailable))
+}
@@ -0,0 +1,69 @@
+package p2pkh
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const ErrInputIndex Error = "transaction input index out of range"
+
+// P2PKHScript returns the P2PKH scriptPubKey locking funds to the compressed
+// public key: OP_DUP OP_HASH160 <hash160> OP_EQUALVERIFY OP_CHECKSIG.
+func P2PKHScript(publicKey *btcec.PublicKey) ([]byte, error) {
+	return p2pkhScript(publicKey.SerializeCompressed())
+}
+
+// p2pkhScript returns the P2PKH scriptPubKey of a serialized public key,
+// compressed or not.
+func p2pkhScript(serializedKey []byte) ([]byte, error) {
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).
+		AddData(btcutil.Hash160(serializedKey)).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+}
+
+// P2PKHSignatureScript returns the scriptSig unlocking a P2PKH output of the
+// compressed public key with a DER encoded signature: <signature+hashType>
+// <publicKey>.
+func P2PKHSignatureScript(signature []byte, hashType txscript.SigHashType, publicKey *btcec.PublicKey) ([]byte, error) {
+	return txscript.NewScriptBuilder().
+		AddData(append(signature[:len(signature):len(signature)], byte(hashType))).
+		AddData(publicKey.SerializeCompressed()).
+		Script()
+}
+
+// PkScript returns the P2PKH scriptPubKey of the wallet address, e.g. for the
+// outputs of a wire.MsgTx paying to the wallet.
+func (s *Wallet) PkScript() ([]byte, error) {
+	return p2pkhScript(s.address.ScriptAddress())
+}
+
+// SignatureScript signs the input i of tx, spending a P2PKH output of the
+// wallet address whose scriptPubKey is pkScript, with SIGHASH_ALL, and
+// returns its scriptSig, to be set as tx.TxIn[i].SignatureScript.
+func (s *Wallet) SignatureScript(tx *wire.MsgTx, i int, pkScript []byte) ([]byte, error) {
+	if s.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	if i < 0 || i >= len(tx.TxIn) {
+		return nil, ErrInputIndex
+	}
+	if err := checkP2PKHScript(pkScript, s.publicKey, !s.uncompressed); err != nil {
+		return nil, err
+	}
+	key, err := s.extendedKey.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	sigScript, err := s.p2pkhSignatureScript(tx, i, pkScript, key)
+	if err != nil {
+		return nil, err
+	}
+	s.logSign("input", "", inputAttrs(tx, i)...)
+	return sigScript, nil
+}
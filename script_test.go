@@ -0,0 +1,73 @@
+package p2pkh
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PkScript(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	script, err := wallet.PkScript()
+	assert.NoError(t, err)
+
+	expected, err := txscript.PayToAddrScript(wallet.Address().AddressPubKeyHash())
+	assert.NoError(t, err)
+	assert.Equal(t, expected, script)
+	assert.Equal(t, txscript.PubKeyHashTy, txscript.GetScriptClass(script))
+
+	fromKey, err := P2PKHScript(wallet.PublicKey())
+	assert.NoError(t, err)
+	assert.Equal(t, script, fromKey)
+}
+
+func Test_SignatureScript(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 50000)
+	hash, err := chainhash.NewHashFromStr(utxo.TxID)
+	assert.NoError(t, err)
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, utxo.Vout), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(40000, utxo.PkScript))
+	sigScript, err := wallet.SignatureScript(tx, 0, utxo.PkScript)
+	assert.NoError(t, err)
+	tx.TxIn[0].SignatureScript = sigScript
+
+	var buf bytes.Buffer
+	assert.NoError(t, tx.Serialize(&buf))
+	verifyTx(t, buf.Bytes(), []UTXO{utxo})
+
+	pushes, err := txscript.PushedData(sigScript)
+	assert.NoError(t, err)
+	assert.Len(t, pushes, 2)
+	rebuilt, err := P2PKHSignatureScript(pushes[0][:len(pushes[0])-1], txscript.SigHashAll, wallet.PublicKey())
+	assert.NoError(t, err)
+	assert.Equal(t, sigScript, rebuilt)
+}
+
+func Test_SignatureScript_Errors(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 50000)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil, nil))
+
+	_, err := wallet.SignatureScript(tx, 1, utxo.PkScript)
+	assert.ErrorIs(t, err, ErrInputIndex)
+
+	other, err := wallet.Derive(1)
+	assert.NoError(t, err)
+	_, err = other.SignatureScript(tx, 0, utxo.PkScript)
+	assert.ErrorIs(t, err, ErrInputScriptMismatch)
+
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	_, err = watch.SignatureScript(tx, 0, utxo.PkScript)
+	assert.ErrorIs(t, err, ErrWatchOnly)
+}
@@ -0,0 +1,66 @@
+package p2pkh
+
+import (
+	"crypto/sha256"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// Scripts returns the P2PKH scriptPubKeys of count consecutive non-hardened
+// children of the wallet from start, those of the addresses Addresses
+// returns, or of the uncompressed keys for wallets of uncompressed keys.
+// These are the items SPV clients watch, e.g. in a bloom or compact filter.
+func (s *Wallet) Scripts(start, count uint32) ([][]byte, error) {
+	if uint64(start)+uint64(count) > hdkeychain.HardenedKeyStart {
+		return nil, ErrIndexRange
+	}
+	xpub, err := s.extendedKey.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	scripts := make([][]byte, count)
+	for i := range scripts {
+		child, err := deriveKey(xpub, start+uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := child.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		serialized := publicKey.SerializeCompressed()
+		if s.uncompressed {
+			serialized = publicKey.SerializeUncompressed()
+		}
+		if scripts[i], err = p2pkhScript(serialized); err != nil {
+			return nil, err
+		}
+	}
+	return scripts, nil
+}
+
+// ScriptHashes returns the Electrum scripthashes of the scripts of count
+// consecutive children of the wallet from start, see Scripts, e.g. to
+// subscribe to them on an Electrum server. Their hex encodings are the
+// strings of the Electrum protocol.
+func (s *Wallet) ScriptHashes(start, count uint32) ([][32]byte, error) {
+	scripts, err := s.Scripts(start, count)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([][32]byte, len(scripts))
+	for i, script := range scripts {
+		hashes[i] = ElectrumScriptHash(script)
+	}
+	return hashes, nil
+}
+
+// ElectrumScriptHash returns the Electrum scripthash of a scriptPubKey: its
+// SHA-256 hash, byte-reversed.
+func ElectrumScriptHash(script []byte) [32]byte {
+	hash := sha256.Sum256(script)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hash
+}
@@ -0,0 +1,47 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ScriptHashes(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	addresses, err := wallet.Addresses(3, 4)
+	assert.NoError(t, err)
+	scripts, err := wallet.Scripts(3, 4)
+	assert.NoError(t, err)
+	hashes, err := wallet.ScriptHashes(3, 4)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 4)
+	for i, address := range addresses {
+		_, script, err := parseAddressScript(address, NetworkMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, script, scripts[i])
+		assert.Equal(t, ElectrumScriptHash(script), hashes[i])
+	}
+
+	// The scripthash of the genesis output script, from the Electrum
+	// protocol documentation.
+	_, script, err := parseAddressScript("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", NetworkMainnet)
+	assert.NoError(t, err)
+	hash := ElectrumScriptHash(script)
+	assert.Equal(t, "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161", hex.EncodeToString(hash[:]))
+
+	// Wallets of uncompressed keys watch the scripts of their uncompressed
+	// keys.
+	uncompressed, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, UncompressedKeys: true})
+	assert.NoError(t, err)
+	child, err := uncompressed.Derive(2)
+	assert.NoError(t, err)
+	scripts, err = uncompressed.Scripts(2, 1)
+	assert.NoError(t, err)
+	pkScript, err := child.PkScript()
+	assert.NoError(t, err)
+	assert.Equal(t, pkScript, scripts[0])
+
+	_, err = wallet.ScriptHashes(1<<31-1, 2)
+	assert.ErrorIs(t, err, ErrIndexRange)
+}
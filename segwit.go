@@ -0,0 +1,53 @@
+package p2pkh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ErrSegwitV0Checksum Error = "witness v0 addresses must use the bech32 checksum"
+	ErrSegwitV1Checksum Error = "witness v1+ addresses must use the bech32m checksum"
+	ErrSegwitVersion    Error = "invalid witness version"
+	ErrSegwitProgram    Error = "invalid witness program length"
+)
+
+// checkSegwitAddress checks the encoding of a segwit address of the network:
+// BIP173 bech32 for witness version 0, BIP350 bech32m for versions 1 and
+// above. Other addresses are left to btcutil.DecodeAddress.
+func checkSegwitAddress(address string, params *chaincfg.Params) error {
+	if !strings.HasPrefix(strings.ToLower(address), params.Bech32HRPSegwit+"1") {
+		return nil
+	}
+	_, data, variant, err := bech32.DecodeGeneric(address)
+	if err != nil {
+		var checksum bech32.ErrInvalidChecksum
+		if errors.As(err, &checksum) {
+			return &AddressError{Kind: AddressErrorChecksum, Address: address, Err: err}
+		}
+		return &AddressError{Kind: AddressErrorMalformed, Address: address, Err: err}
+	}
+	if len(data) == 0 || data[0] > 16 {
+		return addressError(AddressErrorWitnessVersion, address, ErrSegwitVersion)
+	}
+	version := data[0]
+	program, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return &AddressError{Kind: AddressErrorMalformed, Address: address, Err: err}
+	}
+	if len(program) < 2 || len(program) > 40 || (version == 0 && len(program) != 20 && len(program) != 32) {
+		return &AddressError{Kind: AddressErrorMalformed, Address: address, Err: fmt.Errorf("%w: %d bytes", ErrSegwitProgram, len(program))}
+	}
+
+	switch {
+	case version == 0 && variant != bech32.Version0:
+		return addressError(AddressErrorChecksum, address, ErrSegwitV0Checksum)
+	case version > 0 && variant != bech32.VersionM:
+		return addressError(AddressErrorChecksum, address, ErrSegwitV1Checksum)
+	}
+	return nil
+}
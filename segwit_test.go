@@ -0,0 +1,58 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckSegwitAddress(t *testing.T) {
+	// Test vectors of BIP350.
+	tests := []struct {
+		name    string
+		address string
+		err     error
+	}{
+		{"V0", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", nil},
+		{"V1", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", nil},
+		{"V1Long", "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", nil},
+		{"V16", "BC1SW50QGDZ25J", nil},
+		{"V2", "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", nil},
+		{"Base58", "1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", nil},
+		{"V1Bech32", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", ErrSegwitV1Checksum},
+		{"V16Bech32", "BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", ErrSegwitV1Checksum},
+		{"V0Bech32m", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", ErrSegwitV0Checksum},
+		{"ShortProgram", "bc1pw5dgrnzv", ErrSegwitProgram},
+		{"V0Length", "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", ErrSegwitProgram},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkSegwitAddress(test.address, &chaincfg.MainNetParams)
+			if test.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+
+	assert.Error(t, checkSegwitAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", &chaincfg.MainNetParams))
+}
+
+func Test_ValidateAddress_Segwit(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	valid, err := wallet.ValidateAddress("bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = wallet.ValidateAddress("bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd")
+	assert.ErrorIs(t, err, ErrSegwitV1Checksum)
+	assert.False(t, valid)
+
+	book, err := NewAddressBook(NetworkMainnet, NewMemoryStorage())
+	assert.NoError(t, err)
+	err = book.Add("alice", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh")
+	assert.ErrorIs(t, err, ErrSegwitV0Checksum)
+}
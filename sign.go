@@ -0,0 +1,53 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// Sign signs a 32-byte digest with the wallet key and returns the DER encoded
+// ECDSA signature, low-S and deterministic (RFC6979), e.g. to answer a login
+// challenge or an LNURL-auth request without exporting the key. The digest
+// is signed as is: callers hash their messages, with domain separation.
+// Wallets created with Config.LowRSignatures grind it to a low R value.
+func (s *Wallet) Sign(digest [32]byte) ([]byte, error) {
+	if s.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	key, err := s.extendedKey.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	s.logSign("digest", "", slog.String("digest", hex.EncodeToString(digest[:])))
+	return signDigest(key, digest[:], s.lowR), nil
+}
+
+// SignCompact signs a digest like Sign, but returns the 65-byte compact
+// signature whose header byte lets verifiers recover the public key.
+func (s *Wallet) SignCompact(digest [32]byte) ([]byte, error) {
+	if s.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	key, err := s.extendedKey.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	s.logSign("digest", "", slog.String("digest", hex.EncodeToString(digest[:])))
+	return ecdsa.SignCompact(key, digest[:], true), nil
+}
+
+// Verify reports whether sig, DER encoded or compact, is a signature of the
+// 32-byte digest by the wallet key. Watch-only wallets verify signatures too.
+func (s *Wallet) Verify(digest, sig []byte) bool {
+	if len(digest) != 32 {
+		return false
+	}
+	if len(sig) == compactSigSize {
+		publicKey, _, err := ecdsa.RecoverCompact(sig, digest)
+		return err == nil && publicKey.IsEqual(s.publicKey)
+	}
+	signature, err := ecdsa.ParseDERSignature(sig)
+	return err == nil && signature.Verify(digest, s.publicKey)
+}
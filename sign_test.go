@@ -0,0 +1,49 @@
+package p2pkh
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wallet_Sign(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	digest := sha256.Sum256([]byte("login challenge"))
+
+	sig, err := wallet.Sign(digest)
+	assert.NoError(t, err)
+	again, err := wallet.Sign(digest)
+	assert.NoError(t, err)
+	assert.Equal(t, sig, again, "RFC6979 signatures are deterministic")
+	parsed, err := ecdsa.ParseDERSignature(sig)
+	assert.NoError(t, err)
+	assert.True(t, parsed.Verify(digest[:], wallet.PublicKey()))
+	assert.True(t, wallet.Verify(digest[:], sig))
+
+	compact, err := wallet.SignCompact(digest)
+	assert.NoError(t, err)
+	assert.Len(t, compact, compactSigSize)
+	assert.True(t, wallet.Verify(digest[:], compact))
+
+	// Watch-only wallets verify but do not sign.
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.True(t, watch.Verify(digest[:], sig))
+	_, err = watch.Sign(digest)
+	assert.ErrorIs(t, err, ErrWatchOnly)
+	_, err = watch.SignCompact(digest)
+	assert.ErrorIs(t, err, ErrWatchOnly)
+
+	other, err := wallet.Derive(1)
+	assert.NoError(t, err)
+	assert.False(t, other.Verify(digest[:], sig))
+	assert.False(t, other.Verify(digest[:], compact))
+	tampered := sha256.Sum256([]byte("another challenge"))
+	assert.False(t, wallet.Verify(tampered[:], sig))
+	assert.False(t, wallet.Verify(digest[:16], sig))
+	assert.False(t, wallet.Verify(digest[:], []byte{0x30, 0x00}))
+}
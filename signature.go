@@ -0,0 +1,107 @@
+package p2pkh
+
+import (
+	"encoding/binary"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// signDigest returns the DER encoded ECDSA signature of a 32-byte digest.
+// Its nonce is derived from the key and the digest following RFC6979, so
+// that signing twice yields the same signature and no weak random number
+// generator can leak the key, and its S value is the low one BIP62 and the
+// standardness rules of Bitcoin Core require.
+//
+// With lowR, the signature is ground like Bitcoin Core does until its R
+// value is below 2^255, so that its DER encoding takes 70 bytes at most
+// instead of 71 half of the time: the nonce of the attempt n > 0 is the
+// RFC6979 nonce with the extra data n, as a 32-byte little-endian number.
+// The signatures are then still deterministic.
+func signDigest(key *btcec.PrivateKey, digest []byte, lowR bool) []byte {
+	if !lowR {
+		return ecdsa.Sign(key, digest).Serialize()
+	}
+	privateKey := key.Key.Bytes()
+	defer zero(privateKey[:])
+	var extra [32]byte
+	for counter := uint32(0); ; counter++ {
+		var extraData []byte
+		if counter > 0 {
+			binary.LittleEndian.PutUint32(extra[:], counter)
+			extraData = extra[:]
+		}
+		nonce := secp256k1.NonceRFC6979(privateKey[:], digest, extraData, nil, 0)
+		r, sig, ok := signWithNonce(&key.Key, digest, nonce)
+		nonce.Zero()
+		if ok && r.Bytes()[0] < 0x80 {
+			return sig.Serialize()
+		}
+	}
+}
+
+// signWithNonce returns the low-S ECDSA signature of a digest with a nonce
+// and its R value, or false when the nonce yields no valid signature.
+func signWithNonce(key *secp256k1.ModNScalar, digest []byte, nonce *secp256k1.ModNScalar) (*secp256k1.ModNScalar, *ecdsa.Signature, bool) {
+	// R = kG, r = R.x mod N.
+	var point secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(nonce, &point)
+	point.ToAffine()
+	var r secp256k1.ModNScalar
+	r.SetBytes(point.X.Bytes())
+	if r.IsZero() {
+		return nil, nil, false
+	}
+
+	// s = k^-1(e + dr) mod N, negated when above N/2.
+	var e secp256k1.ModNScalar
+	e.SetByteSlice(digest)
+	kInv := new(secp256k1.ModNScalar).InverseValNonConst(nonce)
+	s := new(secp256k1.ModNScalar).Mul2(key, &r).Add(&e).Mul(kInv)
+	if s.IsZero() {
+		return nil, nil, false
+	}
+	if s.IsOverHalfOrder() {
+		s.Negate()
+	}
+	return &r, ecdsa.NewSignature(&r, s), true
+}
+
+// rawTxInSignature signs the legacy input i of tx, whose script code is
+// script, and returns the signature followed by its sighash type.
+func (s *Wallet) rawTxInSignature(tx *wire.MsgTx, i int, script []byte, hashType txscript.SigHashType, key *btcec.PrivateKey) ([]byte, error) {
+	hash, err := txscript.CalcSignatureHash(script, hashType, tx, i)
+	if err != nil {
+		return nil, err
+	}
+	return append(signDigest(key, hash, s.lowR), byte(hashType)), nil
+}
+
+// rawTxInWitnessSignature signs the witness v0 input i of tx, spending
+// amount, whose script code is script, and returns the signature followed
+// by its sighash type.
+func (s *Wallet) rawTxInWitnessSignature(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, i int, amount int64, script []byte, hashType txscript.SigHashType, key *btcec.PrivateKey) ([]byte, error) {
+	hash, err := txscript.CalcWitnessSigHash(script, sigHashes, hashType, tx, i, amount)
+	if err != nil {
+		return nil, err
+	}
+	return append(signDigest(key, hash, s.lowR), byte(hashType)), nil
+}
+
+// p2pkhSignatureScript signs the P2PKH input i of tx, spending pkScript,
+// with SIGHASH_ALL and returns its scriptSig, pushing the compressed or
+// uncompressed public key of the wallet.
+func (s *Wallet) p2pkhSignatureScript(tx *wire.MsgTx, i int, pkScript []byte, key *btcec.PrivateKey) ([]byte, error) {
+	signature, err := s.rawTxInSignature(tx, i, pkScript, txscript.SigHashAll, key)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := key.PubKey().SerializeCompressed()
+	if s.uncompressed {
+		publicKey = key.PubKey().SerializeUncompressed()
+	}
+	return txscript.NewScriptBuilder().AddData(signature).AddData(publicKey).Script()
+}
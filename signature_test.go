@@ -0,0 +1,90 @@
+package p2pkh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SignDigest(t *testing.T) {
+	var secret [32]byte
+	secret[31] = 1
+	key, _ := btcec.PrivKeyFromBytes(secret[:])
+	digest := sha256.Sum256([]byte("Satoshi Nakamoto"))
+
+	// The RFC6979 signature of the private key 1, a widely published vector,
+	// whose R value is high.
+	sig := signDigest(key, digest[:], false)
+	assert.Equal(t, "3045022100934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"+
+		"02202442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5", hex.EncodeToString(sig))
+	assert.Equal(t, ecdsa.Sign(key, digest[:]).Serialize(), sig)
+
+	// Grinding retries with the extra data 1, as Bitcoin Core does.
+	lowR := signDigest(key, digest[:], true)
+	assert.Equal(t, "304402203311d51d1326e30774b2fb1fbfd5e199ebccb43be1db2ce41051eb2d75e4b68f"+
+		"022044d2ea67486df31a242363de1f835d583620fea148ee422c8c80b904b53f5ac3", hex.EncodeToString(lowR))
+	parsed, err := ecdsa.ParseDERSignature(lowR)
+	assert.NoError(t, err)
+	assert.True(t, parsed.Verify(digest[:], key.PubKey()))
+
+	// Low-R signatures whose first attempt succeeds are the plain ones, and
+	// all of them are deterministic, low-S and at most 70 bytes long.
+	for i := 0; i < 32; i++ {
+		digest := sha256.Sum256([]byte{byte(i)})
+		plain := signDigest(key, digest[:], false)
+		lowR := signDigest(key, digest[:], true)
+		assert.Equal(t, lowR, signDigest(key, digest[:], true))
+		assert.LessOrEqual(t, len(lowR), 70)
+		assert.Less(t, lowR[4], byte(0x80))
+		if plain[3] == 0x20 {
+			assert.Equal(t, plain, lowR)
+		}
+		for _, sig := range [][]byte{plain, lowR} {
+			parsed, err := ecdsa.ParseDERSignature(sig)
+			assert.NoError(t, err)
+			assert.True(t, parsed.Verify(digest[:], key.PubKey()))
+			s := parsed.Serialize()
+			assert.True(t, bytes.Equal(sig, s))
+			assert.Less(t, s[len(s)-32], byte(0x80), "S is low")
+		}
+	}
+}
+
+func Test_LowRSignatures(t *testing.T) {
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, LowRSignatures: true})
+	assert.NoError(t, err)
+	child, err := wallet.Derive(0)
+	assert.NoError(t, err)
+	assert.True(t, child.lowR)
+	assert.True(t, wallet.Builder().config.LowRSignatures)
+
+	var utxos []UTXO
+	builder := child.NewTransaction()
+	for vout := uint32(0); vout < 16; vout++ {
+		utxo := walletUTXO(t, child, vout, 10000)
+		utxos = append(utxos, utxo)
+		builder.AddInput(utxo)
+	}
+	rawTx, err := builder.AddOutput("1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv", 150000).Sign()
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	for _, in := range tx.TxIn {
+		// The scriptSig pushes the signature with its sighash type first.
+		assert.LessOrEqual(t, int(in.SignatureScript[0]), 71)
+	}
+
+	digest := sha256.Sum256([]byte("login challenge"))
+	sig, err := child.Sign(digest)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(sig), 70)
+	assert.True(t, child.Verify(digest[:], sig))
+}
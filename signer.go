@@ -0,0 +1,87 @@
+package p2pkh
+
+import (
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrSignerPath      Error = "external signer only signs inputs paying to its key"
+	ErrSignerSignature Error = "external signer returned an invalid signature"
+)
+
+// Signer holds a key signing transactions, keeping key custody apart from
+// transaction construction: a Wallet, or an adapter to a hardware wallet
+// such as a Ledger or a Trezor, whose keys never leave the device.
+type Signer interface {
+	// PublicKey returns the public key of the signer.
+	PublicKey() *btcec.PublicKey
+	// Sign signs a 32-byte digest and returns the DER encoded signature.
+	Sign(digest [32]byte) ([]byte, error)
+	// SignPSBTInput adds the partial signatures of the signer to the input i
+	// of a PSBT, leaving it untouched when the signer holds none of its keys.
+	SignPSBTInput(packet *psbt.Packet, i int) error
+}
+
+// SignPSBTWith adds the partial signatures of signers to every input of a
+// PSBT, base64 encoded, and returns it base64 encoded.
+func SignPSBTWith(psbtBase64 string, signers ...Signer) (string, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(psbtBase64), true)
+	if err != nil {
+		return "", err
+	}
+	for i := range packet.Inputs {
+		for _, signer := range signers {
+			if err := signer.SignPSBTInput(packet, i); err != nil {
+				return "", err
+			}
+		}
+	}
+	return packet.B64Encode()
+}
+
+// WithSigner signs the single-key inputs with an external signer instead of
+// the wallet, which may then be watch-only, e.g. built from the xpub of a
+// hardware wallet. Such inputs must pay to the key of the signer.
+func (s *TxBuilder) WithSigner(signer Signer) *TxBuilder {
+	s.signer = signer
+	return s
+}
+
+// signerInput signs the P2PKH input i of tx, spending the draft input in,
+// with the external signer, checking the signature it returns.
+func (s *TxBuilder) signerInput(tx *wire.MsgTx, i int, in DraftInput) error {
+	if in.Path != "" && in.Path != s.wallet.path {
+		return ErrSignerPath
+	}
+	publicKey := s.signer.PublicKey()
+	if err := checkP2PKHScript(in.PkScript, publicKey, true); err != nil {
+		return err
+	}
+	hash, err := txscript.CalcSignatureHash(in.PkScript, txscript.SigHashAll, tx, i)
+	if err != nil {
+		return err
+	}
+	var digest [32]byte
+	copy(digest[:], hash)
+	der, err := s.signer.Sign(digest)
+	if err != nil {
+		return err
+	}
+	signature, err := ecdsa.ParseDERSignature(der)
+	if err != nil || !signature.Verify(hash, publicKey) {
+		return ErrSignerSignature
+	}
+
+	sigScript, err := P2PKHSignatureScript(der, txscript.SigHashAll, publicKey)
+	if err != nil {
+		return err
+	}
+	tx.TxIn[i].SignatureScript = sigScript
+	return nil
+}
@@ -0,0 +1,104 @@
+package p2pkh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSigner is an external signer, such as a hardware wallet adapter,
+// holding the key of a wallet.
+type testSigner struct {
+	wallet  *Wallet
+	corrupt bool
+	signed  int
+}
+
+func (s *testSigner) PublicKey() *btcec.PublicKey {
+	return s.wallet.PublicKey()
+}
+
+func (s *testSigner) Sign(digest [32]byte) ([]byte, error) {
+	s.signed++
+	if s.corrupt {
+		digest[0] ^= 0xff
+	}
+	return s.wallet.Sign(digest)
+}
+
+func (s *testSigner) SignPSBTInput(packet *psbt.Packet, i int) error {
+	s.signed++
+	return s.wallet.SignPSBTInput(packet, i)
+}
+
+func Test_TxBuilder_WithSigner(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+	watch, err := NewFromExtendedPublicKey(xpub, NetworkMainnet)
+	assert.NoError(t, err)
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 60000), walletUTXO(t, wallet, 1, 40000)}
+
+	// The watch-only wallet builds the transaction, the signer signs it.
+	signer := &testSigner{wallet: wallet}
+	rawTx, err := watch.NewTransaction().
+		AddInput(utxos[0]).
+		AddInput(utxos[1]).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 99000).
+		WithSigner(signer).
+		Sign()
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+	assert.Equal(t, 2, signer.signed)
+
+	_, err = watch.NewTransaction().
+		AddInput(utxos[0]).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 59000).
+		WithSigner(&testSigner{wallet: wallet, corrupt: true}).
+		Sign()
+	assert.ErrorIs(t, err, ErrSignerSignature)
+
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	_, err = watch.NewTransaction().
+		AddInput(walletUTXO(t, child, 0, 60000)).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 59000).
+		WithSigner(signer).
+		Sign()
+	assert.ErrorIs(t, err, ErrInputScriptMismatch)
+	_, err = watch.NewTransaction().
+		AddInputAt(walletUTXO(t, child, 0, 60000), child.Path()).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 59000).
+		WithSigner(signer).
+		Sign()
+	assert.ErrorIs(t, err, ErrSignerPath)
+}
+
+func Test_SignPSBTWith(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 50000)}
+	prevTx := fundingTx(utxos)
+	draft, err := NewDraft(NetworkMainnet, []DraftInput{{UTXO: utxos[0]}},
+		[]DraftOutput{{Address: "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", Amount: 49000}})
+	assert.NoError(t, err)
+	unsigned, err := wallet.CreatePSBT(draft, prevTx)
+	assert.NoError(t, err)
+
+	signer := &testSigner{wallet: wallet}
+	signed, err := SignPSBTWith(unsigned, signer)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, signer.signed)
+	expected, err := wallet.SignPSBT(unsigned)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, signed)
+	verifyTx(t, finalizePSBT(t, signed), utxos)
+
+	_, err = SignPSBTWith("not a psbt", signer)
+	assert.Error(t, err)
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(unsigned), true)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, wallet.SignPSBTInput(packet, 1), ErrPSBTInputIndex)
+}
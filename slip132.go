@@ -0,0 +1,103 @@
+package p2pkh
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const (
+	ErrSLIP132Mismatch Error = "extended key version implies another address type"
+	ErrExtendedKey     Error = "invalid extended key"
+)
+
+// slip132Versions maps the SLIP-132 version bytes of extended keys, as used
+// by Electrum, to the network and script type they designate.
+var slip132Versions = map[uint32]struct {
+	network  Network
+	addrType AddressType
+}{
+	0x0488b21e: {NetworkMainnet, AddressTypeP2PKH},  // xpub
+	0x0488ade4: {NetworkMainnet, AddressTypeP2PKH},  // xprv
+	0x049d7cb2: {NetworkMainnet, AddressTypeP2SH},   // ypub
+	0x049d7878: {NetworkMainnet, AddressTypeP2SH},   // yprv
+	0x04b24746: {NetworkMainnet, AddressTypeP2WPKH}, // zpub
+	0x04b2430c: {NetworkMainnet, AddressTypeP2WPKH}, // zprv
+	0x02aa7ed3: {NetworkMainnet, AddressTypeP2WSH},  // Zpub
+	0x02aa7a99: {NetworkMainnet, AddressTypeP2WSH},  // Zprv
+	0x043587cf: {NetworkTestnet, AddressTypeP2PKH},  // tpub
+	0x04358394: {NetworkTestnet, AddressTypeP2PKH},  // tprv
+	0x044a5262: {NetworkTestnet, AddressTypeP2SH},   // upub
+	0x044a4e28: {NetworkTestnet, AddressTypeP2SH},   // uprv
+	0x045f1cf6: {NetworkTestnet, AddressTypeP2WPKH}, // vpub
+	0x045f18bc: {NetworkTestnet, AddressTypeP2WPKH}, // vprv
+	0x02575483: {NetworkTestnet, AddressTypeP2WSH},  // Vpub
+	0x02575048: {NetworkTestnet, AddressTypeP2WSH},  // Vprv
+}
+
+// ExtendedKeyInfo is a parsed extended key: the key, with the standard
+// version bytes of its network, and the network and script type its
+// original version designates.
+type ExtendedKeyInfo struct {
+	Key         *hdkeychain.ExtendedKey
+	Network     Network
+	AddressType AddressType
+}
+
+// ParseExtendedKey parses an extended public or private key, with standard
+// (xpub, tprv...) or SLIP-132 (ypub, zprv, Vpub...) version bytes, ignoring
+// surrounding whitespace. Failures are reported as ErrExtendedKey.
+func ParseExtendedKey(encoded string) (*ExtendedKeyInfo, error) {
+	key, network, addrType, err := parseSLIP132Key(strings.TrimSpace(encoded))
+	if err != nil {
+		if !errors.Is(err, ErrExtendedKey) {
+			err = fmt.Errorf("%w: %w", ErrExtendedKey, err)
+		}
+		return nil, err
+	}
+	return &ExtendedKeyInfo{Key: key, Network: network, AddressType: addrType}, nil
+}
+
+// parseSLIP132Key parses an extended key with SLIP-132 version bytes and
+// returns it with the standard version bytes of its network, along with the
+// network and the script type designated by the original version.
+func parseSLIP132Key(encoded string) (*hdkeychain.ExtendedKey, Network, AddressType, error) {
+	key, err := hdkeychain.NewKeyFromString(encoded)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("%w: %w", ErrExtendedKey, err)
+	}
+	info, ok := slip132Versions[binary.BigEndian.Uint32(base58.Decode(encoded)[:4])]
+	if !ok {
+		return nil, "", "", ErrUnsupportedAddressType
+	}
+	params, err := selectNetworkParams(info.network)
+	if err != nil {
+		return nil, "", "", err
+	}
+	version := params.HDPublicKeyID[:]
+	if key.IsPrivate() {
+		version = params.HDPrivateKeyID[:]
+	}
+	if key, err = key.CloneWithVersion(version); err != nil {
+		return nil, "", "", err
+	}
+	return key, info.network, info.addrType, nil
+}
+
+// parseExtendedKey parses an extended key used for addresses of addrType. A
+// SLIP-132 version (ypub, zpub, Zpub...) must designate addrType, while the
+// standard xpub and tpub versions are accepted for every address type.
+func parseExtendedKey(encoded string, addrType AddressType) (*hdkeychain.ExtendedKey, Network, error) {
+	key, network, implied, err := parseSLIP132Key(encoded)
+	if err != nil {
+		return nil, "", err
+	}
+	if implied != AddressTypeP2PKH && implied != addrType {
+		return nil, "", ErrSLIP132Mismatch
+	}
+	return key, network, nil
+}
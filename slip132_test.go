@@ -0,0 +1,104 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/stretchr/testify/assert"
+)
+
+// bip84ZPub is the BIP84 account key of the "abandon ... about" mnemonic.
+const bip84ZPub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+func Test_parseSLIP132Key(t *testing.T) {
+	key, network, addrType, err := parseSLIP132Key(bip84ZPub)
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkMainnet, network)
+	assert.Equal(t, AddressTypeP2WPKH, addrType)
+	assert.Equal(t, "xpub", key.String()[:4])
+
+	zpub, err := key.CloneWithVersion([]byte{0x04, 0xb2, 0x47, 0x46})
+	assert.NoError(t, err)
+	assert.Equal(t, bip84ZPub, zpub.String())
+
+	_, _, _, err = parseSLIP132Key("zpub")
+	assert.Error(t, err)
+}
+
+func Test_parseExtendedKey(t *testing.T) {
+	_, _, err := parseExtendedKey(bip84ZPub, AddressTypeP2WPKH)
+	assert.NoError(t, err)
+	_, _, err = parseExtendedKey(bip84ZPub, AddressTypeP2PKH)
+	assert.ErrorIs(t, err, ErrSLIP132Mismatch)
+
+	xpub, err := createKnownWallet(t, NetworkMainnet).ExtendedPublicKey()
+	assert.NoError(t, err)
+	for _, addrType := range []AddressType{AddressTypeP2PKH, AddressTypeP2SH, AddressTypeP2WPKH, AddressTypeP2TR} {
+		_, _, err = parseExtendedKey(xpub, addrType)
+		assert.NoError(t, err)
+	}
+}
+
+func Test_SLIP132_Imports(t *testing.T) {
+	// A zpub cannot silently become a P2PKH descriptor.
+	_, err := DescriptorAddress("pkh("+bip84ZPub+"/0/*)", NetworkMainnet, 0)
+	assert.ErrorIs(t, err, ErrSLIP132Mismatch)
+	address, err := DescriptorAddress("wpkh("+bip84ZPub+"/0/*)", NetworkMainnet, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", address)
+
+	// Multisig cosigners keys must match the wallet script type.
+	cosigners := createTestCosigners(t, 2, NetworkMainnet, `m/48'/0'/0'/2'`)
+	key, err := hdkeychain.NewKeyFromString(cosigners[0].XPub)
+	assert.NoError(t, err)
+	zpub, err := key.CloneWithVersion([]byte{0x02, 0xaa, 0x7e, 0xd3})
+	assert.NoError(t, err)
+	slip132 := append([]Cosigner(nil), cosigners...)
+	slip132[0].XPub = zpub.String()
+
+	_, err = NewMultisigWallet("Vault", 2, slip132, AddressTypeP2SH, NetworkMainnet)
+	assert.ErrorIs(t, err, ErrSLIP132Mismatch)
+	wallet, err := NewMultisigWallet("Vault", 2, slip132, AddressTypeP2WSH, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, cosigners, wallet.Cosigners())
+}
+
+func Test_ParseExtendedKey(t *testing.T) {
+	info, err := ParseExtendedKey(" " + bip84ZPub + "\n")
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkMainnet, info.Network)
+	assert.Equal(t, AddressTypeP2WPKH, info.AddressType)
+	assert.False(t, info.Key.IsPrivate())
+
+	xprv, err := createKnownWallet(t, NetworkTestnet).ExtendedPrivateKey()
+	assert.NoError(t, err)
+	info, err = ParseExtendedKey(xprv)
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkTestnet, info.Network)
+	assert.Equal(t, AddressTypeP2PKH, info.AddressType)
+	assert.True(t, info.Key.IsPrivate())
+	assert.Equal(t, xprv, info.Key.String())
+
+	_, err = ParseExtendedKey(bip84ZPub[:len(bip84ZPub)-1])
+	assert.ErrorIs(t, err, ErrExtendedKey)
+	_, err = ParseExtendedKey("")
+	assert.ErrorIs(t, err, ErrExtendedKey)
+}
+
+func FuzzParseExtendedKey(f *testing.F) {
+	f.Add(bip84ZPub)
+	f.Add("xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8")
+	f.Add("tprv8ZgxMBicQKsPd7Uf69XL1XwhmjHopUGep8GuEiJDZmbQz6o58LninorQAfcKZWARbtRtfnLcJ5MQ2AtHcQJCCRUcMRvmDUjyEmNUWwx8UbK")
+	f.Add("")
+	f.Fuzz(func(t *testing.T, encoded string) {
+		info, err := ParseExtendedKey(encoded)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrExtendedKey)
+			return
+		}
+		again, err := ParseExtendedKey(info.Key.String())
+		assert.NoError(t, err)
+		assert.Equal(t, info.Key.String(), again.Key.String())
+		assert.Equal(t, info.Network, again.Network)
+	})
+}
@@ -0,0 +1,582 @@
+package p2pkh
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"strings"
+
+	bip39 "github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	ErrShareInvalid     Error = "invalid SLIP-39 share"
+	ErrShareChecksum    Error = "invalid SLIP-39 share checksum"
+	ErrShareMismatch    Error = "SLIP-39 shares belong to different backups"
+	ErrShareThreshold   Error = "not enough SLIP-39 shares to recover the backup"
+	ErrShareDigest      Error = "SLIP-39 shares do not recover the backup"
+	ErrBackupThreshold  Error = "threshold must be between 2 and the number of shares, at most 16, or 1 of 1"
+	ErrBackupNoMnemonic Error = "wallet has no mnemonic to back up"
+
+	// slip39WordBits is the number of bits each word of a share encodes.
+	slip39WordBits = 10
+	// slip39PrefixWords encode the identifier, iteration exponent, group
+	// and member fields of a share; slip39ChecksumWords its checksum.
+	slip39PrefixWords   = 4
+	slip39ChecksumWords = 3
+	// slip39MinWords is the length of the shares of a 128 bits secret.
+	slip39MinWords  = 20
+	slip39MaxShares = 16
+	// slip39DigestIndex and slip39SecretIndex are the x coordinates of the
+	// digest and the secret in the Shamir sharing.
+	slip39DigestIndex    = 254
+	slip39SecretIndex    = 255
+	slip39DigestLength   = 4
+	slip39BaseIterations = 10000
+	slip39Rounds         = 4
+	// slip39IterationExponent is the iteration exponent of the shares of
+	// SplitBackup, 20000 PBKDF2 iterations in total as Trezor does.
+	slip39IterationExponent = 1
+)
+
+// slip39WordList is the SLIP-39 word list, in index order.
+const slip39WordList = "" +
+	"academic acid acne acquire acrobat activity actress adapt " +
+	"adequate adjust admit adorn adult advance advocate afraid again " +
+	"agency agree aide aircraft airline airport ajar alarm album " +
+	"alcohol alien alive alpha already alto aluminum always amazing " +
+	"ambition amount amuse analysis anatomy ancestor ancient angel " +
+	"angry animal answer antenna anxiety apart aquatic arcade arena " +
+	"argue armed artist artwork aspect auction august aunt average " +
+	"aviation avoid award away axis axle beam beard beaver become " +
+	"bedroom behavior being believe belong benefit best beyond bike " +
+	"biology birthday bishop black blanket blessing blimp blind blue " +
+	"body bolt boring born both boundary bracelet branch brave " +
+	"breathe briefing broken brother browser bucket budget building " +
+	"bulb bulge bumpy bundle burden burning busy buyer cage calcium " +
+	"camera campus canyon capacity capital capture carbon cards " +
+	"careful cargo carpet carve category cause ceiling center " +
+	"ceramic champion change charity check chemical chest chew " +
+	"chubby cinema civil class clay cleanup client climate clinic " +
+	"clock clogs closet clothes club cluster coal coastal coding " +
+	"column company corner costume counter course cover cowboy " +
+	"cradle craft crazy credit cricket criminal crisis critical " +
+	"crowd crucial crunch crush crystal cubic cultural curious curly " +
+	"custody cylinder daisy damage dance darkness database daughter " +
+	"deadline deal debris debut decent decision declare decorate " +
+	"decrease deliver demand density deny depart depend depict " +
+	"deploy describe desert desire desktop destroy detailed detect " +
+	"device devote diagnose dictate diet dilemma diminish dining " +
+	"diploma disaster discuss disease dish dismiss display distance " +
+	"dive divorce document domain domestic dominant dough downtown " +
+	"dragon dramatic dream dress drift drink drove drug dryer " +
+	"duckling duke duration dwarf dynamic early earth easel easy " +
+	"echo eclipse ecology edge editor educate either elbow elder " +
+	"election elegant element elephant elevator elite else email " +
+	"emerald emission emperor emphasis employer empty ending endless " +
+	"endorse enemy energy enforce engage enjoy enlarge entrance " +
+	"envelope envy epidemic episode equation equip eraser erode " +
+	"escape estate estimate evaluate evening evidence evil evoke " +
+	"exact example exceed exchange exclude excuse execute exercise " +
+	"exhaust exotic expand expect explain express extend extra " +
+	"eyebrow facility fact failure faint fake false family famous " +
+	"fancy fangs fantasy fatal fatigue favorite fawn fiber fiction " +
+	"filter finance findings finger firefly firm fiscal fishing " +
+	"fitness flame flash flavor flea flexible flip float floral " +
+	"fluff focus forbid force forecast forget formal fortune forward " +
+	"founder fraction fragment frequent freshman friar fridge " +
+	"friendly frost froth frozen fumes funding furl fused galaxy " +
+	"game garbage garden garlic gasoline gather general genius genre " +
+	"genuine geology gesture glad glance glasses glen glimpse goat " +
+	"golden graduate grant grasp gravity gray greatest grief grill " +
+	"grin grocery gross group grownup grumpy guard guest guilt " +
+	"guitar gums hairy hamster hand hanger harvest have havoc hawk " +
+	"hazard headset health hearing heat helpful herald herd hesitate " +
+	"hobo holiday holy home hormone hospital hour huge human " +
+	"humidity hunting husband hush husky hybrid idea identify idle " +
+	"image impact imply improve impulse include income increase " +
+	"index indicate industry infant inform inherit injury inmate " +
+	"insect inside install intend intimate invasion involve iris " +
+	"island isolate item ivory jacket jerky jewelry join judicial " +
+	"juice jump junction junior junk jury justice kernel keyboard " +
+	"kidney kind kitchen knife knit laden ladle ladybug lair lamp " +
+	"language large laser laundry lawsuit leader leaf learn leaves " +
+	"lecture legal legend legs lend length level liberty library " +
+	"license lift likely lilac lily lips liquid listen literary " +
+	"living lizard loan lobe location losing loud loyalty luck lunar " +
+	"lunch lungs luxury lying lyrics machine magazine maiden mailman " +
+	"main makeup making mama manager mandate mansion manual marathon " +
+	"march market marvel mason material math maximum mayor meaning " +
+	"medal medical member memory mental merchant merit method metric " +
+	"midst mild military mineral minister miracle mixed mixture " +
+	"mobile modern modify moisture moment morning mortgage mother " +
+	"mountain mouse move much mule multiple muscle museum music " +
+	"mustang nail national necklace negative nervous network news " +
+	"nuclear numb numerous nylon oasis obesity object observe obtain " +
+	"ocean often olympic omit oral orange orbit order ordinary " +
+	"organize ounce oven overall owner paces pacific package paid " +
+	"painting pajamas pancake pants papa paper parcel parking party " +
+	"patent patrol payment payroll peaceful peanut peasant pecan " +
+	"penalty pencil percent perfect permit petition phantom pharmacy " +
+	"photo phrase physics pickup picture piece pile pink pipeline " +
+	"pistol pitch plains plan plastic platform playoff pleasure plot " +
+	"plunge practice prayer preach predator pregnant premium prepare " +
+	"presence prevent priest primary priority prisoner privacy prize " +
+	"problem process profile program promise prospect provide prune " +
+	"public pulse pumps punish puny pupal purchase purple python " +
+	"quantity quarter quick quiet race racism radar railroad rainbow " +
+	"raisin random ranked rapids raspy reaction realize rebound " +
+	"rebuild recall receiver recover regret regular reject relate " +
+	"remember remind remove render repair repeat replace require " +
+	"rescue research resident response result retailer retreat " +
+	"reunion revenue review reward rhyme rhythm rich rival river " +
+	"robin rocky romantic romp roster round royal ruin ruler rumor " +
+	"sack safari salary salon salt satisfy satoshi saver says " +
+	"scandal scared scatter scene scholar science scout scramble " +
+	"screw script scroll seafood season secret security segment " +
+	"senior shadow shaft shame shaped sharp shelter sheriff short " +
+	"should shrimp sidewalk silent silver similar simple single " +
+	"sister skin skunk slap slavery sled slice slim slow slush smart " +
+	"smear smell smirk smith smoking smug snake snapshot sniff " +
+	"society software soldier solution soul source space spark speak " +
+	"species spelling spend spew spider spill spine spirit spit " +
+	"spray sprinkle square squeeze stadium staff standard starting " +
+	"station stay steady step stick stilt story strategy strike " +
+	"style subject submit sugar suitable sunlight superior surface " +
+	"surprise survive sweater swimming swing switch symbolic " +
+	"sympathy syndrome system tackle tactics tadpole talent task " +
+	"taste taught taxi teacher teammate teaspoon temple tenant " +
+	"tendency tension terminal testify texture thank that theater " +
+	"theory therapy thorn threaten thumb thunder ticket tidy timber " +
+	"timely ting tofu together tolerate total toxic tracks traffic " +
+	"training transfer trash traveler treat trend trial tricycle " +
+	"trip triumph trouble true trust twice twin type typical ugly " +
+	"ultimate umbrella uncover undergo unfair unfold unhappy union " +
+	"universe unkind unknown unusual unwrap upgrade upstairs " +
+	"username usher usual valid valuable vampire vanish various " +
+	"vegan velvet venture verdict verify very veteran vexed victim " +
+	"video view vintage violence viral visitor visual vitamins vocal " +
+	"voice volume voter voting walnut warmth warn watch wavy wealthy " +
+	"weapon webcam welcome welfare western width wildlife window " +
+	"wine wireless wisdom withdraw wits wolf woman work worthy wrap " +
+	"wrist writing wrote year yelp yield yoga zero"
+
+// slip39Words maps each word of the list to its index.
+var slip39Words = func() map[string]int {
+	words := strings.Fields(slip39WordList)
+	m := make(map[string]int, len(words))
+	for i, word := range words {
+		m[word] = i
+	}
+	return m
+}()
+
+// slip39WordAt returns the words of the list by index.
+var slip39WordAt = strings.Fields(slip39WordList)
+
+// SplitBackup splits the seed of the wallet into SLIP-39 shares, mnemonics
+// of 59 words any threshold of which recover the wallet with
+// RecoverFromShares, e.g. 2 of 3 shares kept by different custodians. Fewer
+// shares reveal nothing about the seed. The seed includes the BIP39
+// passphrase, so that none is needed to recover; the wallet must hold its
+// mnemonic.
+func (s *Wallet) SplitBackup(threshold, shares int) ([]string, error) {
+	if s.mnemonic == "" {
+		return nil, ErrBackupNoMnemonic
+	}
+	if shares < 1 || shares > slip39MaxShares || threshold < 1 || threshold > shares || threshold == 1 && shares > 1 {
+		return nil, ErrBackupThreshold
+	}
+	seed := bip39.NewSeed(mnemonicSeedPhrase(s.mnemonic), s.passphrase)
+	defer zero(seed)
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	share := slip39Share{
+		id:             (uint16(id[0])<<8 | uint16(id[1])) & 0x7fff,
+		extendable:     true,
+		iterationExp:   slip39IterationExponent,
+		groupThreshold: 1,
+		groupCount:     1,
+		threshold:      threshold,
+	}
+	encrypted := slip39Encrypt(seed, "", share)
+	defer zero(encrypted)
+	values, err := slip39Split(threshold, shares, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	mnemonics := make([]string, shares)
+	for i, value := range values {
+		share.memberIndex = i
+		share.value = value
+		mnemonics[i] = share.mnemonic()
+		zero(value)
+	}
+	return mnemonics, nil
+}
+
+// RecoverFromShares recovers the wallet of SLIP-39 shares, e.g. of
+// SplitBackup or of a Trezor, at the path and network of a configuration
+// whose mnemonic is ignored, as NewFromSeed does: the recovered wallet has
+// no mnemonic. Its passphrase is the SLIP-39 passphrase the shares were
+// created with, none for SplitBackup.
+func RecoverFromShares(shares []string, config *Config) (*Wallet, error) {
+	seed, err := recoverSLIP39(shares, config.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(seed)
+	return NewFromSeed(seed, config)
+}
+
+// slip39Share is a decoded SLIP-39 share: a member share of a group share
+// of the encrypted master secret.
+type slip39Share struct {
+	id             uint16
+	extendable     bool
+	iterationExp   int
+	groupIndex     int
+	groupThreshold int
+	groupCount     int
+	memberIndex    int
+	threshold      int
+	value          []byte
+}
+
+// customization returns the customization string of the share checksum.
+func (s *slip39Share) customization() string {
+	if s.extendable {
+		return "shamir_extendable"
+	}
+	return "shamir"
+}
+
+// mnemonic encodes the share as words.
+func (s *slip39Share) mnemonic() string {
+	var extendable uint64
+	if s.extendable {
+		extendable = 1
+	}
+	prefix := uint64(s.id)<<25 | extendable<<24 | uint64(s.iterationExp)<<20 |
+		uint64(s.groupIndex)<<16 | uint64(s.groupThreshold-1)<<12 | uint64(s.groupCount-1)<<8 |
+		uint64(s.memberIndex)<<4 | uint64(s.threshold-1)
+	indexes := make([]int, 0, slip39MinWords)
+	for i := slip39PrefixWords - 1; i >= 0; i-- {
+		indexes = append(indexes, int(prefix>>(i*slip39WordBits)&1023))
+	}
+
+	// The value is left padded with zeros to a multiple of the word size.
+	valueWords := (len(s.value)*8 + slip39WordBits - 1) / slip39WordBits
+	value := new(big.Int).SetBytes(s.value)
+	word := new(big.Int)
+	for i := valueWords - 1; i >= 0; i-- {
+		indexes = append(indexes, int(word.Rsh(value, uint(i*slip39WordBits)).Uint64()&1023))
+	}
+	indexes = append(indexes, slip39Checksum(s.customization(), indexes)...)
+
+	words := make([]string, len(indexes))
+	for i, index := range indexes {
+		words[i] = slip39WordAt[index]
+	}
+	return strings.Join(words, " ")
+}
+
+// parseSLIP39Share decodes a share, checking its checksum.
+func parseSLIP39Share(mnemonic string) (*slip39Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < slip39MinWords {
+		return nil, ErrShareInvalid
+	}
+	indexes := make([]int, len(words))
+	for i, word := range words {
+		index, ok := slip39Words[word]
+		if !ok {
+			return nil, ErrShareInvalid
+		}
+		indexes[i] = index
+	}
+
+	var prefix uint64
+	for _, index := range indexes[:slip39PrefixWords] {
+		prefix = prefix<<slip39WordBits | uint64(index)
+	}
+	share := &slip39Share{
+		id:             uint16(prefix >> 25),
+		extendable:     prefix>>24&1 == 1,
+		iterationExp:   int(prefix >> 20 & 15),
+		groupIndex:     int(prefix >> 16 & 15),
+		groupThreshold: int(prefix>>12&15) + 1,
+		groupCount:     int(prefix>>8&15) + 1,
+		memberIndex:    int(prefix >> 4 & 15),
+		threshold:      int(prefix&15) + 1,
+	}
+	if !slip39VerifyChecksum(share.customization(), indexes) {
+		return nil, ErrShareChecksum
+	}
+	if share.groupThreshold > share.groupCount {
+		return nil, ErrShareInvalid
+	}
+
+	// The value bits are padded to a multiple of 16 with at most 8 zeros.
+	valueWords := indexes[slip39PrefixWords : len(indexes)-slip39ChecksumWords]
+	padding := len(valueWords) * slip39WordBits % 16
+	if padding > 8 {
+		return nil, ErrShareInvalid
+	}
+	value := new(big.Int)
+	for _, index := range valueWords {
+		value.Lsh(value, slip39WordBits).Or(value, big.NewInt(int64(index)))
+	}
+	length := (len(valueWords)*slip39WordBits - padding) / 8
+	if value.BitLen() > length*8 {
+		return nil, ErrShareInvalid
+	}
+	share.value = value.FillBytes(make([]byte, length))
+	return share, nil
+}
+
+// recoverSLIP39 recovers the master secret of shares, decrypting it with
+// the passphrase.
+func recoverSLIP39(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, ErrShareThreshold
+	}
+	var first *slip39Share
+	groups := make(map[int]map[int]*slip39Share)
+	for _, mnemonic := range mnemonics {
+		share, err := parseSLIP39Share(mnemonic)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = share
+		}
+		if share.id != first.id || share.extendable != first.extendable || share.iterationExp != first.iterationExp ||
+			share.groupThreshold != first.groupThreshold || share.groupCount != first.groupCount ||
+			len(share.value) != len(first.value) {
+			return nil, ErrShareMismatch
+		}
+		members := groups[share.groupIndex]
+		if members == nil {
+			members = make(map[int]*slip39Share)
+			groups[share.groupIndex] = members
+		}
+		for _, member := range members {
+			if member.threshold != share.threshold {
+				return nil, ErrShareMismatch
+			}
+		}
+		members[share.memberIndex] = share
+	}
+
+	// Each complete group recovers its group share.
+	groupValues := make(map[int][]byte)
+	for groupIndex, members := range groups {
+		var threshold int
+		values := make(map[int][]byte, len(members))
+		for memberIndex, member := range members {
+			threshold = member.threshold
+			values[memberIndex] = member.value
+		}
+		if len(values) < threshold {
+			continue
+		}
+		value, err := slip39Recover(threshold, values)
+		if err != nil {
+			return nil, err
+		}
+		groupValues[groupIndex] = value
+	}
+	if len(groupValues) < first.groupThreshold {
+		return nil, ErrShareThreshold
+	}
+	encrypted, err := slip39Recover(first.groupThreshold, groupValues)
+	if err != nil {
+		return nil, err
+	}
+	return slip39Decrypt(encrypted, passphrase, *first), nil
+}
+
+// slip39Checksum returns the RS1024 checksum words of data.
+func slip39Checksum(customization string, data []int) []int {
+	values := make([]int, 0, len(customization)+len(data)+slip39ChecksumWords)
+	for _, c := range []byte(customization) {
+		values = append(values, int(c))
+	}
+	values = append(append(values, data...), 0, 0, 0)
+	polymod := slip39Polymod(values) ^ 1
+	checksum := make([]int, slip39ChecksumWords)
+	for i := range checksum {
+		checksum[i] = polymod >> (slip39WordBits * (slip39ChecksumWords - 1 - i)) & 1023
+	}
+	return checksum
+}
+
+// slip39VerifyChecksum checks the RS1024 checksum ending data.
+func slip39VerifyChecksum(customization string, data []int) bool {
+	values := make([]int, 0, len(customization)+len(data))
+	for _, c := range []byte(customization) {
+		values = append(values, int(c))
+	}
+	return slip39Polymod(append(values, data...)) == 1
+}
+
+// slip39Polymod is the RS1024 checksum polynomial of SLIP-39.
+func slip39Polymod(values []int) int {
+	generator := [10]int{0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009,
+		0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120}
+	checksum := 1
+	for _, value := range values {
+		b := checksum >> 20
+		checksum = (checksum&0xfffff)<<10 ^ value
+		for i, g := range generator {
+			if b>>i&1 == 1 {
+				checksum ^= g
+			}
+		}
+	}
+	return checksum
+}
+
+// slip39Encrypt encrypts the master secret of a share with the passphrase,
+// in a four rounds Feistel network whose round function is PBKDF2.
+func slip39Encrypt(secret []byte, passphrase string, share slip39Share) []byte {
+	left := append([]byte(nil), secret[:len(secret)/2]...)
+	right := append([]byte(nil), secret[len(secret)/2:]...)
+	for round := 0; round < slip39Rounds; round++ {
+		left, right = right, slip39Round(round, passphrase, share, left, right)
+	}
+	return append(right, left...)
+}
+
+// slip39Decrypt decrypts the master secret of a share with the passphrase.
+func slip39Decrypt(encrypted []byte, passphrase string, share slip39Share) []byte {
+	left := append([]byte(nil), encrypted[:len(encrypted)/2]...)
+	right := append([]byte(nil), encrypted[len(encrypted)/2:]...)
+	for round := slip39Rounds - 1; round >= 0; round-- {
+		left, right = right, slip39Round(round, passphrase, share, left, right)
+	}
+	return append(right, left...)
+}
+
+// slip39Round returns the left half xored with the round function of the
+// right half.
+func slip39Round(round int, passphrase string, share slip39Share, left, right []byte) []byte {
+	salt := []byte{}
+	if !share.extendable {
+		salt = []byte{'s', 'h', 'a', 'm', 'i', 'r', byte(share.id >> 8), byte(share.id)}
+	}
+	iterations := (slip39BaseIterations << share.iterationExp) / slip39Rounds
+	key := pbkdf2.Key(append([]byte{byte(round)}, passphrase...), append(salt, right...), iterations, len(right), sha256.New)
+	for i := range key {
+		key[i] ^= left[i]
+	}
+	return key
+}
+
+// slip39Split shares a secret among count shares, threshold of which
+// recover it, as SLIP-39 does: the shares at x 0 to threshold-3 are random,
+// the polynomial also passing by the digest of the secret at x 254 and the
+// secret at x 255.
+func slip39Split(threshold, count int, secret []byte) ([][]byte, error) {
+	if threshold == 1 {
+		shares := make([][]byte, count)
+		for i := range shares {
+			shares[i] = append([]byte(nil), secret...)
+		}
+		return shares, nil
+	}
+
+	points := make(map[int][]byte, threshold)
+	shares := make([][]byte, count)
+	for i := 0; i < threshold-2; i++ {
+		shares[i] = make([]byte, len(secret))
+		if _, err := rand.Read(shares[i]); err != nil {
+			return nil, err
+		}
+		points[i] = shares[i]
+	}
+	random := make([]byte, len(secret)-slip39DigestLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	points[slip39DigestIndex] = append(slip39Digest(random, secret), random...)
+	points[slip39SecretIndex] = secret
+	for i := threshold - 2; i < count; i++ {
+		shares[i] = slip39Interpolate(points, i)
+	}
+	return shares, nil
+}
+
+// slip39Recover recovers the secret of threshold shares, checking its
+// digest.
+func slip39Recover(threshold int, shares map[int][]byte) ([]byte, error) {
+	if threshold == 1 {
+		for _, share := range shares {
+			return share, nil
+		}
+	}
+	secret := slip39Interpolate(shares, slip39SecretIndex)
+	digest := slip39Interpolate(shares, slip39DigestIndex)
+	if !hmac.Equal(digest[:slip39DigestLength], slip39Digest(digest[slip39DigestLength:], secret)) {
+		return nil, ErrShareDigest
+	}
+	return secret, nil
+}
+
+// slip39Digest returns the digest of a secret, keyed with random bytes.
+func slip39Digest(random, secret []byte) []byte {
+	mac := hmac.New(sha256.New, random)
+	mac.Write(secret)
+	return mac.Sum(nil)[:slip39DigestLength]
+}
+
+// gf256Exp and gf256Log are the exponentials and logarithms of 3 in
+// GF(256), modulo the Rijndael polynomial.
+var gf256Exp, gf256Log = func() ([255]byte, [256]byte) {
+	var exp [255]byte
+	var log [256]byte
+	poly := 1
+	for i := range exp {
+		exp[i] = byte(poly)
+		log[poly] = byte(i)
+		poly = poly<<1 ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11b
+		}
+	}
+	return exp, log
+}()
+
+// slip39Interpolate returns the value at x of the polynomial passing by the
+// points, byte by byte in GF(256).
+func slip39Interpolate(points map[int][]byte, x int) []byte {
+	if value, ok := points[x]; ok {
+		return append([]byte(nil), value...)
+	}
+	var length, logProduct int
+	for xi, value := range points {
+		length = len(value)
+		logProduct += int(gf256Log[xi^x])
+	}
+	result := make([]byte, length)
+	for xi, value := range points {
+		logBasis := logProduct - int(gf256Log[xi^x])
+		for xj := range points {
+			logBasis -= int(gf256Log[xi^xj])
+		}
+		logBasis = (logBasis%255 + 255) % 255
+		for i, b := range value {
+			if b != 0 {
+				result[i] ^= gf256Exp[(int(gf256Log[b])+logBasis)%255]
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_recoverSLIP39(t *testing.T) {
+	// Test vectors of SLIP-39, with the passphrase "TREZOR".
+	tests := []struct {
+		shares []string
+		secret string
+	}{
+		{
+			[]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+			"bb54aac4b89dc868ba37d9cc21b2cece",
+		},
+		{
+			[]string{
+				"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+				"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+			},
+			"b43ceb7e57a0ea8766221624d01b0864",
+		},
+	}
+	for _, test := range tests {
+		secret, err := recoverSLIP39(test.shares, "TREZOR")
+		assert.NoError(t, err)
+		assert.Equal(t, test.secret, hex.EncodeToString(secret))
+	}
+
+	_, err := recoverSLIP39(tests[1].shares[:1], "TREZOR")
+	assert.ErrorIs(t, err, ErrShareThreshold)
+	_, err = recoverSLIP39([]string{tests[0].shares[0], tests[1].shares[0]}, "TREZOR")
+	assert.ErrorIs(t, err, ErrShareMismatch)
+	_, err = recoverSLIP39([]string{strings.Replace(tests[0].shares[0], "kidney", "keyboard", 1)}, "TREZOR")
+	assert.ErrorIs(t, err, ErrShareChecksum)
+	_, err = recoverSLIP39([]string{"duckling enlarge academic"}, "TREZOR")
+	assert.ErrorIs(t, err, ErrShareInvalid)
+}
+
+func Test_SplitBackup(t *testing.T) {
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Passphrase: "extra", Network: NetworkMainnet})
+	assert.NoError(t, err)
+
+	shares, err := wallet.SplitBackup(2, 3)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 3)
+	for _, share := range shares {
+		assert.Len(t, strings.Fields(share), 59)
+	}
+
+	// Any 2 shares recover the wallet, without its passphrase.
+	for _, pair := range [][]string{{shares[0], shares[1]}, {shares[2], shares[0]}, {shares[1], shares[2]}} {
+		recovered, err := RecoverFromShares(pair, &Config{Network: NetworkMainnet})
+		assert.NoError(t, err)
+		assert.Equal(t, wallet.AddressHex(), recovered.AddressHex())
+	}
+	_, err = RecoverFromShares(shares[:1], &Config{Network: NetworkMainnet})
+	assert.ErrorIs(t, err, ErrShareThreshold)
+
+	// Each backup has its own shares, which do not mix.
+	other, err := wallet.SplitBackup(2, 3)
+	assert.NoError(t, err)
+	assert.NotEqual(t, shares[0], other[0])
+	_, err = RecoverFromShares([]string{shares[0], other[1]}, &Config{Network: NetworkMainnet})
+	assert.Error(t, err)
+
+	single, err := wallet.SplitBackup(1, 1)
+	assert.NoError(t, err)
+	recovered, err := RecoverFromShares(single, &Config{Network: NetworkMainnet})
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.AddressHex(), recovered.AddressHex())
+
+	for _, invalid := range [][2]int{{0, 3}, {4, 3}, {1, 3}, {2, 17}} {
+		_, err = wallet.SplitBackup(invalid[0], invalid[1])
+		assert.ErrorIs(t, err, ErrBackupThreshold)
+	}
+	_, err = recovered.SplitBackup(2, 3)
+	assert.ErrorIs(t, err, ErrBackupNoMnemonic)
+}
@@ -0,0 +1,56 @@
+package p2pkh
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SparrowExport is the wallet file imported by Sparrow Wallet through its
+// "Specter Desktop" importer: a label, the birth height used to limit the
+// rescan, and the output descriptor carrying the key origin and script type.
+type SparrowExport struct {
+	Label       string `json:"label"`
+	BlockHeight int64  `json:"blockheight"`
+	Descriptor  string `json:"descriptor"`
+}
+
+// ExportSparrow returns the JSON document Sparrow Wallet imports to open a
+// watch-only view of the wallet. birthHeight is the height of the first block
+// that may contain wallet transactions (0 when unknown).
+func (s *Wallet) ExportSparrow(label string, birthHeight int64) ([]byte, error) {
+	desc, err := s.Descriptor()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&SparrowExport{
+		Label:       label,
+		BlockHeight: birthHeight,
+		Descriptor:  desc,
+	}, "", "  ")
+}
+
+// SparrowExporter is the Exporter producing SparrowExport documents.
+type SparrowExporter struct {
+	Label       string
+	BlockHeight int64
+}
+
+// Name implements Exporter.
+func (s *SparrowExporter) Name() string {
+	return "sparrow"
+}
+
+// MIMEType implements Exporter.
+func (s *SparrowExporter) MIMEType() string {
+	return "application/json"
+}
+
+// Export implements Exporter.
+func (s *SparrowExporter) Export(wallet *Wallet, w io.Writer) error {
+	data, err := wallet.ExportSparrow(s.Label, s.BlockHeight)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,36 @@
+package p2pkh
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wallet_ExportSparrow(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+
+	data, err := wallet.ExportSparrow("Savings", 840000)
+	assert.NoError(t, err)
+
+	var export SparrowExport
+	assert.NoError(t, json.Unmarshal(data, &export))
+	assert.Equal(t, "Savings", export.Label)
+	assert.Equal(t, int64(840000), export.BlockHeight)
+
+	desc, err := wallet.Descriptor()
+	assert.NoError(t, err)
+	assert.Equal(t, desc, export.Descriptor)
+	assert.Regexp(t, `^pkh\(\[[0-9a-f]{8}/44'/0'/0'\]xpub[1-9A-HJ-NP-Za-km-z]+/0/\*\)#[a-z0-9]{8}$`, export.Descriptor)
+}
+
+func Test_SparrowExporter(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	expected, err := wallet.ExportSparrow("Cold", 100)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, (&SparrowExporter{Label: "Cold", BlockHeight: 100}).Export(wallet, &buf))
+	assert.Equal(t, expected, buf.Bytes())
+}
@@ -0,0 +1,182 @@
+package p2pkh
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	ErrStorageNotFound Error = "storage key not found"
+	ErrStorageKey      Error = "invalid storage key"
+)
+
+// Storage is a key-value store persisting wallet data such as keystores and
+// address books. Implementations must be safe for concurrent use.
+type Storage interface {
+	// Get returns the value of a key, or ErrStorageNotFound.
+	Get(key string) ([]byte, error)
+	// Put creates or replaces the value of a key.
+	Put(key string, value []byte) error
+	// Delete removes a key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// Keys returns the sorted keys starting with prefix.
+	Keys(prefix string) ([]string, error)
+}
+
+// validStorageKey checks that a key can safely be used as a file name: it
+// only uses letters, digits, '.', '_' and '-', and does not start with '.'.
+func validStorageKey(key string) bool {
+	if key == "" || key[0] == '.' {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && !strings.ContainsRune("._-", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// MemoryStorage is a Storage keeping values in memory.
+type MemoryStorage struct {
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+// NewMemoryStorage creates an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{values: make(map[string][]byte)}
+}
+
+// Get implements Storage.
+func (s *MemoryStorage) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	if !ok {
+		return nil, ErrStorageNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Put implements Storage.
+func (s *MemoryStorage) Put(key string, value []byte) error {
+	if !validStorageKey(key) {
+		return ErrStorageKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete implements Storage.
+func (s *MemoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// Keys implements Storage.
+func (s *MemoryStorage) Keys(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []string
+	for key := range s.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// FileStorage is a Storage keeping each value in a file of a directory,
+// readable by the current user only.
+type FileStorage struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStorage creates a FileStorage in dir, creating the directory if needed.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStorage{dir: dir}, nil
+}
+
+// Get implements Storage.
+func (s *FileStorage) Get(key string) ([]byte, error) {
+	if !validStorageKey(key) {
+		return nil, ErrStorageKey
+	}
+	value, err := os.ReadFile(filepath.Join(s.dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrStorageNotFound
+	}
+	return value, err
+}
+
+// Put implements Storage. Values are written to a temporary file renamed
+// over the previous one, so a crash never leaves a truncated value.
+func (s *FileStorage) Put(key string, value []byte) error {
+	if !validStorageKey(key) {
+		return ErrStorageKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.CreateTemp(s.dir, "."+key+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(s.dir, key))
+}
+
+// Delete implements Storage.
+func (s *FileStorage) Delete(key string) error {
+	if !validStorageKey(key) {
+		return ErrStorageKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(filepath.Join(s.dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Keys implements Storage.
+func (s *FileStorage) Keys(prefix string) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && validStorageKey(name) && strings.HasPrefix(name, prefix) {
+			keys = append(keys, name)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,41 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Storage(t *testing.T) {
+	fileStorage, err := NewFileStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	for name, storage := range map[string]Storage{"memory": NewMemoryStorage(), "file": fileStorage} {
+		t.Run(name, func(t *testing.T) {
+			_, err := storage.Get("missing")
+			assert.ErrorIs(t, err, ErrStorageNotFound)
+
+			assert.NoError(t, storage.Put("wallet-b.json", []byte("b")))
+			assert.NoError(t, storage.Put("wallet-a.json", []byte("a")))
+			assert.NoError(t, storage.Put("other", []byte("o")))
+			assert.NoError(t, storage.Put("wallet-a.json", []byte("a2")))
+
+			value, err := storage.Get("wallet-a.json")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("a2"), value)
+
+			keys, err := storage.Keys("wallet-")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"wallet-a.json", "wallet-b.json"}, keys)
+
+			assert.NoError(t, storage.Delete("wallet-a.json"))
+			assert.NoError(t, storage.Delete("wallet-a.json"))
+			_, err = storage.Get("wallet-a.json")
+			assert.ErrorIs(t, err, ErrStorageNotFound)
+
+			for _, key := range []string{"", "../escape", "a/b", ".hidden"} {
+				assert.ErrorIs(t, storage.Put(key, nil), ErrStorageKey, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package p2pkh
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// maxSuggestions bounds the number of corrections returned for an address.
+const maxSuggestions = 5
+
+// base58Confusions are the characters commonly mistyped or misread for base58
+// ones, including the characters excluded from the base58 alphabet.
+var base58Confusions = map[rune]string{
+	'0': "o",
+	'O': "o",
+	'o': "",
+	'I': "1i",
+	'l': "1i",
+	'1': "i",
+	'i': "1",
+	'5': "S",
+	'S': "5",
+	'2': "Z",
+	'Z': "2",
+	'8': "B",
+	'B': "8",
+	'u': "v",
+	'v': "u",
+}
+
+// AddressSuggestion is a valid address close to a mistyped one. It is only a
+// guess: it must be confirmed by the user before any payment.
+type AddressSuggestion struct {
+	Address string
+	// Positions are the indexes of the corrected characters.
+	Positions []int
+}
+
+// SuggestAddresses returns the valid addresses of the network close to a
+// base58 address failing validation, the likely intended ones first. It tries
+// the common confusions (O/0, l/1...) then every single-character correction.
+// It returns nothing for valid addresses, and for bech32 addresses whose
+// errors are already located by their checksum.
+func SuggestAddresses(address string, network Network) ([]AddressSuggestion, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	if validateNetworkAddress(address, params) == nil ||
+		strings.HasPrefix(strings.ToLower(address), params.Bech32HRPSegwit+"1") {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var suggestions []AddressSuggestion
+	add := func(candidate []rune, positions []int) {
+		addr := string(candidate)
+		if seen[addr] || !validBase58Address(addr, params) {
+			return
+		}
+		seen[addr] = true
+		suggestions = append(suggestions, AddressSuggestion{Address: addr, Positions: append([]int(nil), positions...)})
+	}
+
+	// Replace every confusable character at once, then one at a time.
+	runes := []rune(address)
+	fixed := append([]rune(nil), runes...)
+	var positions []int
+	for i, r := range runes {
+		if !strings.ContainsRune(base58Alphabet, r) && base58Confusions[r] != "" {
+			fixed[i] = rune(base58Confusions[r][0])
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) > 0 {
+		add(fixed, positions)
+	}
+	for i, r := range runes {
+		for _, c := range base58Confusions[r] {
+			candidate := append([]rune(nil), runes...)
+			candidate[i] = c
+			add(candidate, []int{i})
+		}
+	}
+
+	// Then every single-character substitution.
+	if len(suggestions) == 0 {
+		for i := range runes {
+			for _, c := range base58Alphabet {
+				if c == runes[i] {
+					continue
+				}
+				candidate := append([]rune(nil), runes...)
+				candidate[i] = c
+				add(candidate, []int{i})
+			}
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return len(suggestions[i].Positions) < len(suggestions[j].Positions)
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions, nil
+}
+
+// SuggestAddresses returns the valid addresses of the wallet network close to
+// a mistyped one. See SuggestAddresses.
+func (s *Wallet) SuggestAddresses(address string) ([]AddressSuggestion, error) {
+	return SuggestAddresses(address, s.network)
+}
+
+// validBase58Address reports whether address is a valid base58 address of the network.
+func validBase58Address(address string, params *chaincfg.Params) bool {
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil || !addr.IsForNet(params) {
+		return false
+	}
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package p2pkh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SuggestAddresses(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	address := wallet.AddressHex()
+
+	suggestions, err := SuggestAddresses(address, NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	// A character replaced by another one.
+	i := len(address) / 2
+	c := "2"
+	if address[i] == '2' {
+		c = "3"
+	}
+	mistyped := address[:i] + c + address[i+1:]
+	suggestions, err = wallet.SuggestAddresses(mistyped)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, suggestions) {
+		found := false
+		for _, suggestion := range suggestions {
+			found = found || suggestion.Address == address
+		}
+		assert.True(t, found, "%v", suggestions)
+	}
+
+	// Characters excluded from base58 replaced by their look-alikes.
+	if j := strings.IndexAny(address[1:], "o1i"); j >= 0 {
+		j++
+		confused := map[byte]string{'o': "0", '1': "l", 'i': "I"}[address[j]]
+		suggestions, err = SuggestAddresses(address[:j]+confused+address[j+1:], NetworkMainnet)
+		assert.NoError(t, err)
+		if assert.NotEmpty(t, suggestions) {
+			assert.Equal(t, address, suggestions[0].Address)
+			assert.Equal(t, []int{j}, suggestions[0].Positions)
+		}
+	}
+
+	// Bech32 addresses are left to their own error detection.
+	suggestions, err = SuggestAddresses("bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	_, err = SuggestAddresses(address, "regtest")
+	assert.ErrorIs(t, err, ErrUnsupportedNet)
+}
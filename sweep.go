@@ -0,0 +1,160 @@
+package p2pkh
+
+import (
+	"context"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrSweepEmpty Error = "no funds to sweep"
+	ErrSweepDust  Error = "swept funds do not cover the fee"
+)
+
+// SweepConfig selects the addresses Sweep gathers the funds of. Zero values
+// select sensible defaults.
+type SweepConfig struct {
+	// GapLimit ends the discovery of the used addresses of each chain of the
+	// account after as many consecutive unused ones, 20 by default.
+	GapLimit int
+	// Count, when set, sweeps the addresses at indexes 0 to Count-1 of both
+	// chains of the account instead of discovering the used ones.
+	Count uint32
+}
+
+// Sweep moves all the funds of the wallet account to an address, e.g. when
+// retiring a wallet: it gathers the unspent outputs of the used addresses of
+// both chains through the backend, spends them all in one transaction paying
+// the fee rate, in sat/vB, with no change, and broadcasts it. It returns the
+// txid of the transaction. Wallets whose path has no account level sweep
+// their own address only.
+func (s *Wallet) Sweep(ctx context.Context, backend ChainBackend, destAddress string, feeRate FeeRate) (string, error) {
+	return s.SweepWith(ctx, backend, destAddress, feeRate, nil)
+}
+
+// SweepWith is Sweep over the addresses the config selects.
+func (s *Wallet) SweepWith(ctx context.Context, backend ChainBackend, destAddress string, feeRate FeeRate, config *SweepConfig) (string, error) {
+	rawTx, err := s.SweepTx(ctx, backend, destAddress, feeRate, config)
+	if err != nil {
+		return "", err
+	}
+	return s.WithBackend(backend).Broadcast(ctx, rawTx)
+}
+
+// SweepTx returns the signed transaction SweepWith broadcasts, so that it
+// can be reviewed or published later.
+func (s *Wallet) SweepTx(ctx context.Context, backend ChainBackend, destAddress string, feeRate FeeRate, config *SweepConfig) ([]byte, error) {
+	cfg := SweepConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if feeRate < 0 {
+		return nil, ErrInvalidFeeRate
+	}
+
+	builder, total, err := s.sweepInputs(ctx, backend, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, ErrSweepEmpty
+	}
+
+	// The fee is estimated from a transaction paying everything to the
+	// destination, which has the size of the final one.
+	vsize, err := builder.AddOutput(destAddress, total).VSize()
+	if err != nil {
+		return nil, err
+	}
+	fee, err := feeRate.Fee(vsize)
+	if err != nil {
+		return nil, err
+	}
+	destScript, _, err := builder.addressScript(destAddress)
+	if err != nil {
+		return nil, err
+	}
+	dust, err := dustThreshold(wire.NewTxOut(0, destScript), defaultDustFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	amount := total - fee
+	if total < fee || amount < dust {
+		return nil, ErrSweepDust
+	}
+	builder.outputs[len(builder.outputs)-1].Amount = amount
+	return builder.Sign()
+}
+
+// sweepInputs returns a builder spending the unspent outputs of the
+// addresses the config selects, and their total.
+func (s *Wallet) sweepInputs(ctx context.Context, backend ChainBackend, cfg SweepConfig) (*TxBuilder, Amount, error) {
+	account, err := NewAccount(s)
+	if err != nil {
+		// Without an account, only the wallet address holds funds.
+		builder := s.WithBackend(backend).NewTransaction()
+		utxos, err := backend.AddressUTXOs(ctx, s.AddressInfo().String())
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := sweepAdd(builder, utxos, "")
+		return builder, total, err
+	}
+
+	wallet := account.wallet.WithBackend(backend)
+	var addresses []*Wallet
+	if cfg.Count > 0 {
+		for chain := uint32(0); chain < 2; chain++ {
+			for index := uint32(0); index < cfg.Count; index++ {
+				child, err := wallet.At(chain, index)
+				if err != nil {
+					return nil, 0, err
+				}
+				addresses = append(addresses, child)
+			}
+		}
+	} else {
+		discovery, err := wallet.Discover(ctx, cfg.GapLimit)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, used := range discovery.Used {
+			child, err := wallet.At(used.Chain, used.Index)
+			if err != nil {
+				return nil, 0, err
+			}
+			addresses = append(addresses, child)
+		}
+	}
+
+	builder := wallet.NewTransaction()
+	var total Amount
+	for _, child := range addresses {
+		utxos, err := backend.AddressUTXOs(ctx, child.AddressInfo().String())
+		if err != nil {
+			return nil, 0, err
+		}
+		sum, err := sweepAdd(builder, utxos, child.Path())
+		if err != nil {
+			return nil, 0, err
+		}
+		if total, err = total.Add(sum); err != nil {
+			return nil, 0, err
+		}
+	}
+	return builder, total, nil
+}
+
+// sweepAdd adds the UTXOs of the key at path as inputs of the builder, and
+// returns their total.
+func sweepAdd(builder *TxBuilder, utxos []UTXO, path string) (Amount, error) {
+	var total Amount
+	for _, utxo := range utxos {
+		var err error
+		if total, err = total.Add(utxo.Amount); err != nil {
+			return 0, err
+		}
+		builder.AddInputAt(utxo, path)
+	}
+	return total, nil
+}
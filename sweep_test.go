@@ -0,0 +1,62 @@
+package p2pkh
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sweep(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	backend := newFakeBackend()
+	ctx := context.Background()
+	const dest = "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A"
+
+	_, err := wallet.Sweep(ctx, backend, dest, 10)
+	assert.ErrorIs(t, err, ErrSweepEmpty)
+
+	// Funds on a receive and a change address are swept together.
+	receive, err := wallet.At(0, 2)
+	assert.NoError(t, err)
+	change, err := wallet.At(1, 4)
+	assert.NoError(t, err)
+	utxos := []UTXO{walletUTXO(t, receive, 0, 40000), walletUTXO(t, receive, 1, 10000), walletUTXO(t, change, 2, 25000)}
+	backend.addUTXOs(receive.AddressInfo().String(), utxos[:2]...)
+	backend.addHistory(receive.AddressInfo().String(), testTxID)
+	backend.addUTXOs(change.AddressInfo().String(), utxos[2])
+	backend.addHistory(change.AddressInfo().String(), testTxID)
+
+	txid, err := wallet.Sweep(ctx, backend, dest, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.broadcastCount())
+	rawTx := backend.broadcasts[0]
+	assert.Equal(t, fakeTxID(rawTx), txid)
+	verifyTx(t, rawTx, utxos)
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Len(t, tx.TxIn, 3)
+	assert.Len(t, tx.TxOut, 1)
+	fee := int64(75000) - tx.TxOut[0].Value
+	// The fee is estimated from signatures of the largest size.
+	assert.GreaterOrEqual(t, fee, int64(10)*int64(tx.SerializeSize()))
+	assert.Less(t, fee, int64(10)*int64(tx.SerializeSize()+10))
+
+	// Beyond the gap limit, the change address is only swept with a count.
+	rawTx, err = wallet.SweepTx(ctx, backend, dest, 10, &SweepConfig{GapLimit: 3})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Len(t, tx.TxIn, 2)
+	rawTx, err = wallet.SweepTx(ctx, backend, dest, 10, &SweepConfig{GapLimit: 3, Count: 5})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Len(t, tx.TxIn, 3)
+
+	_, err = wallet.SweepTx(ctx, backend, dest, 1000, nil)
+	assert.ErrorIs(t, err, ErrSweepDust)
+	_, err = wallet.SweepTx(ctx, backend, dest, -1, nil)
+	assert.ErrorIs(t, err, ErrInvalidFeeRate)
+}
@@ -0,0 +1,72 @@
+package p2pkh
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// testNet4Magic is the network magic of testnet4, as set by BIP94.
+	testNet4Magic wire.BitcoinNet = 0x283f161c
+	// testNet4GenesisMessage is the message of the coinbase of the testnet4
+	// genesis block.
+	testNet4GenesisMessage = "03/May/2024 000000000000000000001ebd58c244970b3aa9d783bb001011fbe8ea8e98e00e"
+	// testNet4GenesisHash is the hash of the testnet4 genesis block.
+	testNet4GenesisHash = "00000000da84f2bafbbc53dee25a72ae507ff4914b867c565be350b0da8bf043"
+)
+
+// testNet4Params are the parameters of testnet4 (BIP94), which replaces
+// testnet3. Its addresses and keys have the prefixes of testnet3 (m/n, 2,
+// tb1, tpub) and its coin type is 1, so that they are already known to
+// chaincfg; only its chain differs.
+var testNet4Params = newTestNet4Params()
+
+// newTestNet4Params derives the testnet4 parameters from the testnet3 ones.
+func newTestNet4Params() chaincfg.Params {
+	params := chaincfg.TestNet3Params
+	params.Name = "testnet4"
+	params.Net = testNet4Magic
+	params.DefaultPort = "48333"
+	params.DNSSeeds = []chaincfg.DNSSeed{
+		{Host: "seed.testnet4.bitcoin.sprovoost.nl", HasFiltering: true},
+		{Host: "seed.testnet4.wiz.biz", HasFiltering: true},
+	}
+	params.GenesisBlock = newTestNet4GenesisBlock()
+	params.GenesisHash, _ = chainhash.NewHashFromStr(testNet4GenesisHash)
+	// BIP34, BIP65 and BIP66 are active from the first block.
+	params.BIP0034Height = 1
+	params.BIP0065Height = 1
+	params.BIP0066Height = 1
+	params.Checkpoints = nil
+	return params
+}
+
+// newTestNet4GenesisBlock returns the testnet4 genesis block, whose coinbase
+// pays 50 BTC to an unspendable key.
+func newTestNet4GenesisBlock() *wire.MsgBlock {
+	signatureScript := append([]byte{0x04, 0xff, 0xff, 0x00, 0x1d, 0x01, 0x04, 0x4c, byte(len(testNet4GenesisMessage))},
+		testNet4GenesisMessage...)
+	pkScript := append(append([]byte{0x21}, make([]byte, 33)...), 0xac)
+	coinbase := &wire.MsgTx{
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+			SignatureScript:  signatureScript,
+			Sequence:         wire.MaxTxInSequenceNum,
+		}},
+		TxOut: []*wire.TxOut{{Value: 50 * 1e8, PkScript: pkScript}},
+	}
+	return &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			MerkleRoot: coinbase.TxHash(),
+			Timestamp:  time.Unix(1714777860, 0),
+			Bits:       0x1d00ffff,
+			Nonce:      393743547,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+}
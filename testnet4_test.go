@@ -0,0 +1,37 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TestNet4Params(t *testing.T) {
+	params, err := NetworkTestnet4.Params()
+	assert.NoError(t, err)
+	assert.Equal(t, "testnet4", params.Name)
+	assert.Equal(t, testNet4GenesisHash, params.GenesisBlock.BlockHash().String())
+	assert.Equal(t, testNet4GenesisHash, params.GenesisHash.String())
+	assert.Equal(t, "7aa0a7ae1e223414cb807e40cd57e667b718e42aaf9306db9102fe28912b7b4e",
+		params.GenesisBlock.Header.MerkleRoot.String())
+	assert.NotEqual(t, chaincfg.TestNet3Params.Net, params.Net)
+}
+
+func Test_NetworkTestnet4(t *testing.T) {
+	// Testnet4 keys and addresses are those of testnet3, at the same paths.
+	testnet := createKnownWallet(t, NetworkTestnet)
+	wallet := createKnownWallet(t, NetworkTestnet4)
+	assert.Equal(t, `m/44'/1'/0'/0`, wallet.Path())
+	assert.Equal(t, testnet.Address().EncodeAddress(), wallet.Address().EncodeAddress())
+
+	xpub, err := wallet.ExtendedPublicKey()
+	assert.NoError(t, err)
+	assert.Regexp(t, "^tpub", xpub)
+
+	addrType, _, err := ClassifyAddress(wallet.Address().EncodeAddress(), NetworkTestnet4)
+	assert.NoError(t, err)
+	assert.Equal(t, AddressTypeP2PKH, addrType)
+	_, _, err = ClassifyAddress("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", NetworkTestnet4)
+	assert.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package p2pkh
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+const (
+	ErrProxyRequired Error = ".onion endpoints require a Tor proxy"
+
+	// DefaultTorProxy is the SOCKS5 address of a local Tor daemon.
+	DefaultTorProxy = "127.0.0.1:9050"
+
+	defaultDialTimeout = 30 * time.Second
+)
+
+// ProxyConfig routes the connections of the network clients through a
+// SOCKS5 proxy, such as Tor. Host names, .onion ones included, are resolved
+// by the proxy so that no DNS request leaks. With Tor, distinct credentials
+// isolate the circuits of distinct clients.
+type ProxyConfig struct {
+	// Address is the host:port of the proxy, DefaultTorProxy for Tor.
+	Address  string
+	Username string
+	Password string
+}
+
+// TorProxy returns the configuration of a local Tor daemon.
+func TorProxy() *ProxyConfig {
+	return &ProxyConfig{Address: DefaultTorProxy}
+}
+
+// DialContext connects to address through the proxy, or directly when config
+// is nil. It is the transport of the TCP clients, e.g. Electrum servers.
+func (s *ProxyConfig) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if s == nil {
+		if isOnion(address) {
+			return nil, ErrProxyRequired
+		}
+		dialer := &net.Dialer{Timeout: defaultDialTimeout}
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	var auth *proxy.Auth
+	if s.Username != "" || s.Password != "" {
+		auth = &proxy.Auth{User: s.Username, Password: s.Password}
+	}
+	dialer, err := proxy.SOCKS5("tcp", s.Address, auth, &net.Dialer{Timeout: defaultDialTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return dialer.(proxy.ContextDialer).DialContext(ctx, network, address)
+}
+
+// NewHTTPClient returns an HTTP client connecting through the proxy, or
+// directly when config is nil, the transport of the REST clients. Proxies
+// set by the environment are ignored, so that a Tor configuration is never
+// bypassed.
+func NewHTTPClient(config *ProxyConfig, timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = config.DialContext
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// isOnion reports whether a host:port address is a Tor onion service.
+func isOnion(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return strings.HasSuffix(strings.ToLower(strings.TrimSuffix(host, ".")), ".onion")
+}
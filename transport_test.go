@@ -0,0 +1,130 @@
+package p2pkh
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSOCKS5 is a SOCKS5 proxy connecting every request to target, and
+// recording the requested addresses and credentials.
+type fakeSOCKS5 struct {
+	listener  net.Listener
+	target    string
+	requested chan string
+	users     chan string
+}
+
+func newFakeSOCKS5(t *testing.T, target string) *fakeSOCKS5 {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	proxy := &fakeSOCKS5{listener: listener, target: target, requested: make(chan string, 10), users: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go proxy.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return proxy
+}
+
+func (s *fakeSOCKS5) serve(conn net.Conn) {
+	defer conn.Close()
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		return
+	}
+	methods := make([]byte, greeting[1])
+	io.ReadFull(conn, methods)
+	if methods[len(methods)-1] == 2 {
+		// Username/password authentication (RFC 1929).
+		conn.Write([]byte{5, 2})
+		head := make([]byte, 2)
+		io.ReadFull(conn, head)
+		user := make([]byte, head[1])
+		io.ReadFull(conn, user)
+		length := make([]byte, 1)
+		io.ReadFull(conn, length)
+		io.ReadFull(conn, make([]byte, length[0]))
+		s.users <- string(user)
+		conn.Write([]byte{1, 0})
+	} else {
+		conn.Write([]byte{5, 0})
+	}
+
+	request := make([]byte, 5)
+	io.ReadFull(conn, request)
+	host := make([]byte, request[4])
+	io.ReadFull(conn, host)
+	port := make([]byte, 2)
+	io.ReadFull(conn, port)
+	s.requested <- net.JoinHostPort(string(host), strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	upstream, err := net.Dial("tcp", s.target)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
+
+func Test_ProxyConfig_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"bitcoin": {"usd": 50000}}`))
+	}))
+	defer server.Close()
+	proxy := newFakeSOCKS5(t, server.Listener.Addr().String())
+
+	// The onion host is resolved by the proxy.
+	config := &ProxyConfig{Address: proxy.listener.Addr().String(), Username: "isolated"}
+	provider := &CoinGeckoRateProvider{BaseURL: "http://explorerzydxu5ecjrkwceayqybizmpjjznk5izmitf2modhcusuqlid.onion", Proxy: config}
+	rate, err := provider.Rate(context.Background(), "usd")
+	assert.NoError(t, err)
+	assert.Equal(t, 50000.0, rate.Price)
+	assert.Equal(t, "explorerzydxu5ecjrkwceayqybizmpjjznk5izmitf2modhcusuqlid.onion:80", <-proxy.requested)
+	assert.Equal(t, "isolated", <-proxy.users)
+
+	// Onion services are never reached without a proxy.
+	provider.Proxy = nil
+	_, err = provider.Rate(context.Background(), "usd")
+	assert.ErrorIs(t, err, ErrProxyRequired)
+}
+
+func Test_ProxyConfig_DialContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Write([]byte("electrum"))
+			conn.Close()
+		}
+	}()
+	proxy := newFakeSOCKS5(t, listener.Addr().String())
+
+	conn, err := (&ProxyConfig{Address: proxy.listener.Addr().String()}).DialContext(context.Background(), "tcp", "electrum.example.onion:50001")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "electrum", string(data))
+	assert.Equal(t, "electrum.example.onion:50001", <-proxy.requested)
+
+	var direct *ProxyConfig
+	_, err = direct.DialContext(context.Background(), "tcp", "electrum.example.onion:50001")
+	assert.ErrorIs(t, err, ErrProxyRequired)
+	assert.Equal(t, DefaultTorProxy, TorProxy().Address)
+}
@@ -0,0 +1,455 @@
+package p2pkh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	ErrDustOutput Error = "output amount is below the dust threshold"
+	ErrAbsurdFee  Error = "fee exceeds the maximum fee"
+
+	// defaultMaxFee is the fee above which transactions are rejected as a
+	// likely mistake, 0.1 BTC as the maxtxfee of Bitcoin Core.
+	defaultMaxFee Amount = 10000000
+)
+
+// TxBuilder composes a transaction spending UTXOs of a wallet, e.g.
+// wallet.NewTransaction().AddInput(utxo).AddOutput(address, amount).Sign().
+// Errors are reported when the transaction is built. A builder is not safe
+// for concurrent use.
+type TxBuilder struct {
+	wallet        *Wallet
+	inputs        []DraftInput
+	multisigs     map[string]*Multisig
+	outputs       []DraftOutput
+	lockTime      uint32
+	feeRate       FeeRate
+	changeAddress string
+	changeIndex   *uint32
+	coins         []DraftInput
+	selector      CoinSelector
+	signer        Signer
+	rbf           bool
+	allowDust     bool
+	maxFee        Amount
+	bip69         bool
+	batchLimits   *BatchLimits
+	err           error
+}
+
+// NewTransaction returns an empty transaction builder signing with the
+// wallet keys.
+func (s *Wallet) NewTransaction() *TxBuilder {
+	return &TxBuilder{wallet: s}
+}
+
+// AddInput spends an UTXO paying to the wallet address.
+func (s *TxBuilder) AddInput(utxo UTXO) *TxBuilder {
+	return s.AddInputAt(utxo, "")
+}
+
+// AddInputAt spends an UTXO paying to the key at path, a descendant of the
+// wallet path.
+func (s *TxBuilder) AddInputAt(utxo UTXO, path string) *TxBuilder {
+	s.inputs = append(s.inputs, DraftInput{UTXO: utxo, Path: path})
+	return s
+}
+
+// AddCoins adds UTXOs paying to the wallet address to the coins the
+// transaction may spend: the coin selector picks among them the inputs
+// funding the outputs and the fee, on top of the inputs added explicitly.
+func (s *TxBuilder) AddCoins(utxos ...UTXO) *TxBuilder {
+	return s.AddCoinsAt("", utxos...)
+}
+
+// AddCoinsAt adds UTXOs paying to the key at path, a descendant of the
+// wallet path, to the coins the transaction may spend.
+func (s *TxBuilder) AddCoinsAt(path string, utxos ...UTXO) *TxBuilder {
+	for _, utxo := range utxos {
+		s.coins = append(s.coins, DraftInput{UTXO: utxo, Path: path})
+	}
+	return s
+}
+
+// WithCoinSelector sets the strategy selecting the coins to spend. It
+// defaults to a BranchAndBound search avoiding change, falling back to
+// LargestFirst.
+func (s *TxBuilder) WithCoinSelector(selector CoinSelector) *TxBuilder {
+	s.selector = selector
+	return s
+}
+
+// AddMultisigInput spends an UTXO paying to a P2SH multisig address. Such
+// inputs are signed by SignMultisig.
+func (s *TxBuilder) AddMultisigInput(utxo UTXO, multisig *Multisig) *TxBuilder {
+	if s.multisigs == nil {
+		s.multisigs = make(map[string]*Multisig)
+	}
+	s.multisigs[utxo.outpoint()] = multisig
+	s.inputs = append(s.inputs, DraftInput{UTXO: utxo})
+	return s
+}
+
+// AddOutput pays amount satoshis to an address.
+func (s *TxBuilder) AddOutput(address string, amount Amount) *TxBuilder {
+	s.outputs = append(s.outputs, DraftOutput{Address: address, Amount: amount})
+	return s
+}
+
+// PayTo pays amount satoshis to a named address of an address book.
+func (s *TxBuilder) PayTo(book *AddressBook, name string, amount Amount) *TxBuilder {
+	output, err := book.PayTo(name, amount)
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return s
+	}
+	s.outputs = append(s.outputs, output)
+	return s
+}
+
+// WithLockTime sets the lock time of the transaction.
+func (s *TxBuilder) WithLockTime(lockTime uint32) *TxBuilder {
+	s.lockTime = lockTime
+	return s
+}
+
+// WithFeeRate sets the fee rate, in sat/vbyte, and sends the remaining funds
+// back to the change address. Without fee rate, the fee is whatever the
+// inputs provide on top of the outputs and there is no change.
+func (s *TxBuilder) WithFeeRate(feeRate FeeRate) *TxBuilder {
+	s.feeRate = feeRate
+	return s
+}
+
+// WithChangeAddress sets the change address. By default, the change goes to
+// the change chain of the wallet account, see WithChangeIndex.
+func (s *TxBuilder) WithChangeAddress(address string) *TxBuilder {
+	s.changeAddress = address
+	return s
+}
+
+// WithChangeIndex sets the index of the change address on the change chain
+// of the wallet account, e.g. Discovery.NextInternal. It defaults to the
+// index of the wallet address. Wallets whose path has no account levels keep
+// the change on their address.
+func (s *TxBuilder) WithChangeIndex(index uint32) *TxBuilder {
+	s.changeIndex = &index
+	return s
+}
+
+// AllowDust lets the transaction pay outputs below the dust threshold of
+// their type, which the network does not relay and which are otherwise
+// rejected with ErrDustOutput.
+func (s *TxBuilder) AllowDust() *TxBuilder {
+	s.allowDust = true
+	return s
+}
+
+// WithMaxFee sets the fee above which the transaction is rejected with
+// ErrAbsurdFee, 0.1 BTC by default, e.g. to catch an amount or fee rate
+// given in the wrong unit. A negative fee disables the check.
+func (s *TxBuilder) WithMaxFee(fee Amount) *TxBuilder {
+	s.maxFee = fee
+	return s
+}
+
+// Draft builds the unsigned transaction, which can be reviewed before being
+// signed with Wallet.SignDraft. A change output below the dust threshold is
+// left to the fee. Outputs below the dust threshold, fees above the maximum
+// fee and transactions beyond the batch limits are rejected, see AllowDust,
+// WithMaxFee and WithBatchLimits.
+func (s *TxBuilder) Draft() (*Draft, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.feeRate < 0 {
+		return nil, ErrInvalidFeeRate
+	}
+	inputs := s.inputs[:len(s.inputs):len(s.inputs)]
+	if len(s.coins) > 0 {
+		selected, err := s.selectCoins()
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, selected...)
+	}
+	outputs := s.outputs[:len(s.outputs):len(s.outputs)]
+	if s.feeRate > 0 {
+		change, err := s.change(inputs)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			outputs = append(outputs, *change)
+		}
+	}
+
+	draft, err := NewDraft(s.wallet.network, inputs, outputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDraft(draft); err != nil {
+		return nil, err
+	}
+	if s.batchLimits != nil {
+		if err := s.checkBatch(draft); err != nil {
+			return nil, err
+		}
+	}
+	if s.bip69 {
+		if err := s.sortBIP69(draft); err != nil {
+			return nil, err
+		}
+	}
+	draft.LockTime = s.lockTime
+	draft.RBF = s.rbf
+	return draft, nil
+}
+
+// checkDraft checks the outputs of a draft against the dust threshold of
+// their type, and its fee against the maximum fee.
+func (s *TxBuilder) checkDraft(draft *Draft) error {
+	if !s.allowDust {
+		for i, out := range draft.Outputs {
+			script, _, err := s.addressScript(out.Address)
+			if err != nil {
+				return err
+			}
+			threshold, err := dustThreshold(wire.NewTxOut(0, script), defaultDustFeeRate)
+			if err != nil {
+				return err
+			}
+			if out.Amount < threshold {
+				return fmt.Errorf("%w: output %d pays %d sats, below %d", ErrDustOutput, i, out.Amount, threshold)
+			}
+		}
+	}
+	maxFee := s.maxFee
+	if maxFee == 0 {
+		maxFee = defaultMaxFee
+	}
+	if maxFee > 0 && draft.Fee > maxFee {
+		return fmt.Errorf("%w: %s above %s", ErrAbsurdFee, draft.Fee, maxFee)
+	}
+	return nil
+}
+
+// VSize estimates the virtual size, in vbytes, of the signed transaction,
+// from the script types of its inputs and outputs: the witness of SegWit
+// inputs is discounted, the signature script of P2PKH ones is not.
+func (s *TxBuilder) VSize() (int64, error) {
+	draft, err := s.Draft()
+	if err != nil {
+		return 0, err
+	}
+	return s.draftVSize(draft)
+}
+
+// draftVSize estimates the virtual size of a draft of the builder once
+// signed.
+func (s *TxBuilder) draftVSize(draft *Draft) (int64, error) {
+	outputs := make([]AddressType, 0, len(draft.Outputs))
+	for _, out := range draft.Outputs {
+		_, outputType, err := s.addressScript(out.Address)
+		if err != nil {
+			return 0, err
+		}
+		outputs = append(outputs, outputType)
+	}
+	return s.vsize(draft.Inputs, outputs)
+}
+
+// EstimateFee returns the fee the transaction will pay once signed. With a
+// fee rate, it is the rate times the estimated virtual size, plus the change
+// left to the fee when it would be dust; without, whatever the inputs
+// provide on top of the outputs.
+func (s *TxBuilder) EstimateFee() (Amount, error) {
+	draft, err := s.Draft()
+	if err != nil {
+		return 0, err
+	}
+	return draft.Fee, nil
+}
+
+// Sign builds the transaction and signs every input, returning the
+// serialized transaction ready to be broadcast. Multisig inputs are signed
+// as by SignMultisig without cosigners, and single-key inputs by the signer
+// set with WithSigner, if any.
+func (s *TxBuilder) Sign() ([]byte, error) {
+	if len(s.multisigs) > 0 || s.signer != nil {
+		return s.SignMultisig()
+	}
+	draft, err := s.Draft()
+	if err != nil {
+		return nil, err
+	}
+	return s.wallet.SignDraft(draft)
+}
+
+// SignMultisig builds the transaction and signs every input: single-key
+// inputs with the wallet, or the signer set with WithSigner, multisig inputs
+// with the wallet and the cosigners holding their keys, e.g. the wallets of
+// the other mnemonics of a 2-of-3. Each multisig input needs as many signers
+// as its threshold.
+func (s *TxBuilder) SignMultisig(cosigners ...*Wallet) ([]byte, error) {
+	draft, err := s.Draft()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := draft.Tx()
+	if err != nil {
+		return nil, err
+	}
+
+	signers := append([]*Wallet{s.wallet}, cosigners...)
+	sigHashes := draftSigHashes(tx, draft.Inputs)
+	for i, in := range draft.Inputs {
+		if multisig, ok := s.multisigs[in.outpoint()]; ok {
+			err = multisig.signInput(tx, i, in.PkScript, signers)
+		} else if s.signer != nil {
+			err = s.signerInput(tx, i, in)
+		} else {
+			err = s.wallet.signDraftInput(tx, sigHashes, i, in)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// change returns the change output paying the remaining funds at the fee
+// rate, or nil when the change would be dust.
+func (s *TxBuilder) change(spent []DraftInput) (*DraftOutput, error) {
+	address, path, err := s.changeDestination()
+	if err != nil {
+		return nil, err
+	}
+	changeScript, changeType, err := s.addressScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var funds Amount
+	for _, in := range spent {
+		if funds, err = funds.Add(in.Amount); err != nil {
+			return nil, err
+		}
+	}
+	outputs := make([]AddressType, 0, len(s.outputs)+1)
+	for _, out := range s.outputs {
+		_, outputType, err := s.addressScript(out.Address)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, outputType)
+		if funds, err = funds.Sub(out.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	vsize, err := s.vsize(spent, append(outputs, changeType))
+	if err != nil {
+		return nil, err
+	}
+	fee, err := s.feeRate.Fee(vsize)
+	if err != nil {
+		return nil, err
+	}
+	dust, err := dustThreshold(wire.NewTxOut(0, changeScript), defaultDustFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	change := funds - fee
+	if change >= dust {
+		return &DraftOutput{Address: address, Amount: change, Change: true, Path: path}, nil
+	}
+
+	// Without change, the transaction is smaller but must still pay its fee.
+	if vsize, err = s.vsize(spent, outputs); err != nil {
+		return nil, err
+	}
+	if fee, err = s.feeRate.Fee(vsize); err != nil {
+		return nil, err
+	}
+	if funds < fee {
+		return nil, ErrInsufficientFunds
+	}
+	return nil, nil
+}
+
+// vsize estimates the virtual size of the signed transaction spending the
+// inputs to outputs of the given types.
+func (s *TxBuilder) vsize(spent []DraftInput, outputs []AddressType) (int64, error) {
+	inputs := make([]AddressType, 0, len(spent))
+	var extraWeight int64
+	for _, in := range spent {
+		if multisig, ok := s.multisigs[in.outpoint()]; ok {
+			extraWeight += multisig.inputWeight()
+			continue
+		}
+		inputType, err := ScriptAddressType(in.PkScript)
+		if err != nil {
+			return 0, err
+		}
+		inputs = append(inputs, inputType)
+		extraWeight += s.uncompressedKeyWeight(inputType)
+	}
+	vsize, err := EstimateVSize(inputs, outputs)
+	if err != nil {
+		return 0, err
+	}
+	return vsize + (extraWeight+3)/4, nil
+}
+
+// addressScript returns the scriptPubKey paying to an address of the wallet
+// network, and its type.
+func (s *TxBuilder) addressScript(address string) ([]byte, AddressType, error) {
+	addr, err := decodeAddress(address, s.wallet.params)
+	if err != nil {
+		return nil, "", err
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	addrType, err := ScriptAddressType(script)
+	if err != nil {
+		return nil, "", err
+	}
+	return script, addrType, nil
+}
+
+// changeDestination returns the change address and, when it is not the
+// wallet address, its derivation path.
+func (s *TxBuilder) changeDestination() (string, string, error) {
+	if s.changeAddress != "" {
+		return s.changeAddress, "", nil
+	}
+	var index uint32
+	if s.changeIndex != nil {
+		index = *s.changeIndex
+	} else {
+		index, _ = s.wallet.Index()
+	}
+	change, err := s.wallet.DeriveChange(index)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPath) {
+			return s.wallet.AddressInfo().String(), "", nil
+		}
+		return "", "", err
+	}
+	return change.AddressInfo().String(), change.Path(), nil
+}
@@ -0,0 +1,198 @@
+package p2pkh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TxBuilder(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(3)
+	assert.NoError(t, err)
+	utxos := []UTXO{walletUTXO(t, wallet, 0, 60000), walletUTXO(t, child, 1, 40000)}
+
+	// Without fee rate, the fee is what the outputs leave.
+	rawTx, err := wallet.NewTransaction().
+		AddInput(utxos[0]).
+		AddInputAt(utxos[1], child.Path()).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 99000).
+		WithLockTime(800000).
+		Sign()
+	assert.NoError(t, err)
+	verifyTx(t, rawTx, utxos)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(rawTx)))
+	assert.Len(t, tx.TxOut, 1)
+	assert.Equal(t, uint32(800000), tx.LockTime)
+
+	// With a fee rate, the rest returns to the wallet.
+	book, err := NewAddressBook(NetworkMainnet, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, book.Add("alice", "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A"))
+	draft, err := wallet.NewTransaction().
+		AddInput(utxos[0]).
+		PayTo(book, "alice", 20000).
+		WithFeeRate(10).
+		Draft()
+	assert.NoError(t, err)
+	assert.Len(t, draft.Outputs, 2)
+	assert.True(t, draft.Outputs[1].Change)
+	change, err := wallet.DeriveChange(0)
+	assert.NoError(t, err)
+	assert.Equal(t, change.AddressHex(), draft.Outputs[1].Address)
+	assert.Equal(t, `m/44'/0'/0'/1/0`, draft.Outputs[1].Path)
+	vsize, err := EstimateVSize([]AddressType{AddressTypeP2PKH}, []AddressType{AddressTypeP2PKH, AddressTypeP2PKH})
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(vsize*10), draft.Fee)
+
+	// A dust change is left to the fee.
+	draft, err = wallet.NewTransaction().
+		AddInput(utxos[0]).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 57800).
+		WithFeeRate(10).
+		WithChangeAddress("1HzaSoLT8kM13M35HgRuigMUYGP2h8PMCv").
+		Draft()
+	assert.NoError(t, err)
+	assert.Len(t, draft.Outputs, 1)
+	assert.Equal(t, Amount(2200), draft.Fee)
+
+	_, err = wallet.NewTransaction().AddInput(utxos[0]).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 59000).WithFeeRate(10).Draft()
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = wallet.NewTransaction().AddInput(utxos[0]).PayTo(book, "bob", 1000).Draft()
+	assert.ErrorIs(t, err, ErrAddressBookNotFound)
+	_, err = wallet.NewTransaction().AddInput(utxos[0]).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 1000).WithFeeRate(-1).Draft()
+	assert.ErrorIs(t, err, ErrInvalidFeeRate)
+	_, err = wallet.NewTransaction().AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 1000).Sign()
+	assert.ErrorIs(t, err, ErrDraftNoInputs)
+
+	// Inputs of another key are not signed.
+	_, err = wallet.NewTransaction().AddInput(utxos[1]).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 1000).Sign()
+	assert.ErrorIs(t, err, ErrInputScriptMismatch)
+}
+
+func Test_TxBuilder_Change(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	receive, err := wallet.At(0, 7)
+	assert.NoError(t, err)
+	utxos := []UTXO{walletUTXO(t, receive, 0, 60000)}
+	prevTx := fundingTx(utxos)
+	utxo := utxos[0]
+
+	// The change index follows the wallet index unless set.
+	draft, err := receive.NewTransaction().AddInput(utxo).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).WithFeeRate(5).Draft()
+	assert.NoError(t, err)
+	assert.Equal(t, `m/44'/0'/0'/1/7`, draft.Outputs[1].Path)
+	assert.NotEqual(t, receive.AddressHex(), draft.Outputs[1].Address)
+
+	draft, err = receive.NewTransaction().AddInput(utxo).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).WithFeeRate(5).WithChangeIndex(2).Draft()
+	assert.NoError(t, err)
+	change, err := receive.DeriveChange(2)
+	assert.NoError(t, err)
+	assert.Equal(t, change.AddressHex(), draft.Outputs[1].Address)
+
+	// The PSBT carries the derivation of the change key.
+	packet, err := receive.CreatePSBT(draft, prevTx)
+	assert.NoError(t, err)
+	parsed, err := psbt.NewFromRawBytes(strings.NewReader(packet), true)
+	assert.NoError(t, err)
+	assert.Len(t, parsed.Outputs[1].Bip32Derivation, 1)
+	assert.Equal(t, change.PublicKey().SerializeCompressed(), parsed.Outputs[1].Bip32Derivation[0].PubKey)
+
+	draft.Outputs[1].Path = `m/44'/0'/0'/1/3`
+	_, err = receive.CreatePSBT(draft, prevTx)
+	assert.ErrorIs(t, err, ErrChangePath)
+
+	// Wallets without account levels keep the change on their address.
+	watch, err := NewFromExtendedPublicKey(wallet.root.String(), NetworkMainnet)
+	assert.NoError(t, err)
+	draft, err = watch.NewTransaction().AddInput(walletUTXO(t, watch, 0, 60000)).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).WithFeeRate(5).Draft()
+	assert.NoError(t, err)
+	assert.Equal(t, watch.AddressHex(), draft.Outputs[1].Address)
+	assert.Empty(t, draft.Outputs[1].Path)
+}
+
+func Test_TxBuilder_EstimateFee(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 60000)
+	builder := wallet.NewTransaction().
+		AddInput(utxo).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).
+		WithFeeRate(12)
+
+	vsize, err := builder.VSize()
+	assert.NoError(t, err)
+	expected, err := EstimateVSize([]AddressType{AddressTypeP2PKH}, []AddressType{AddressTypeP2PKH, AddressTypeP2PKH})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, vsize)
+	fee, err := builder.EstimateFee()
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(vsize*12), fee)
+
+	// The estimate covers the signed transaction.
+	rawTx, err := builder.Sign()
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, int64(len(rawTx)), vsize)
+
+	// SegWit inputs weigh less than P2PKH ones.
+	segwitUTXO := utxo
+	segwitAddr, err := decodeAddress(wallet.AddressP2WPKH().String(), wallet.params)
+	assert.NoError(t, err)
+	segwitUTXO.PkScript = payToAddrScript(t, segwitAddr)
+	segwitVSize, err := wallet.NewTransaction().
+		AddInput(segwitUTXO).
+		AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 20000).
+		WithFeeRate(12).
+		VSize()
+	assert.NoError(t, err)
+	assert.Less(t, segwitVSize, vsize)
+
+	// Without fee rate, the fee is what the outputs leave.
+	fee, err = wallet.NewTransaction().AddInput(utxo).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 59000).EstimateFee()
+	assert.NoError(t, err)
+	assert.Equal(t, Amount(1000), fee)
+
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput("1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A", 59000).WithFeeRate(12).EstimateFee()
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func Test_TxBuilder_Sanity(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	utxo := walletUTXO(t, wallet, 0, 60000)
+	const p2pkhAddress = "1MnyeMkdKFWyheVTbJhKmxa1NFoGb5kB1A"
+	const p2wpkhAddress = "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"
+
+	// The dust threshold depends on the output type: 546 sats for P2PKH,
+	// 294 for P2WPKH.
+	_, err := wallet.NewTransaction().AddInput(utxo).AddOutput(p2pkhAddress, 545).AddOutput(p2pkhAddress, 50000).Draft()
+	assert.ErrorIs(t, err, ErrDustOutput)
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput(p2pkhAddress, 546).Draft()
+	assert.NoError(t, err)
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput(p2wpkhAddress, 293).Draft()
+	assert.ErrorIs(t, err, ErrDustOutput)
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput(p2wpkhAddress, 294).Draft()
+	assert.NoError(t, err)
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput(p2pkhAddress, 545).AllowDust().Draft()
+	assert.NoError(t, err)
+
+	// Negative and overflowing amounts are rejected.
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput(p2pkhAddress, -1000).Draft()
+	assert.ErrorIs(t, err, ErrDraftInvalidAmount)
+	_, err = wallet.NewTransaction().AddInput(walletUTXO(t, wallet, 1, MaxAmount+1)).AddOutput(p2pkhAddress, 1000).WithMaxFee(-1).Draft()
+	assert.ErrorIs(t, err, ErrAmountRange)
+
+	// Fees above 0.1 BTC, or the maximum fee set, are rejected.
+	big := walletUTXO(t, wallet, 2, 20000000)
+	_, err = wallet.NewTransaction().AddInput(big).AddOutput(p2pkhAddress, 1000).Draft()
+	assert.ErrorIs(t, err, ErrAbsurdFee)
+	_, err = wallet.NewTransaction().AddInput(big).AddOutput(p2pkhAddress, 1000).WithMaxFee(-1).Draft()
+	assert.NoError(t, err)
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput(p2pkhAddress, 50000).WithMaxFee(5000).Draft()
+	assert.ErrorIs(t, err, ErrAbsurdFee)
+	_, err = wallet.NewTransaction().AddInput(utxo).AddOutput(p2pkhAddress, 50000).WithMaxFee(10000).Draft()
+	assert.NoError(t, err)
+}
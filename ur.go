@@ -0,0 +1,566 @@
+package p2pkh
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+const (
+	ErrURInvalid      Error = "invalid UR"
+	ErrURType         Error = "invalid UR type"
+	ErrURPartMismatch Error = "UR part does not belong to the message being decoded"
+	ErrURIncomplete   Error = "UR message is incomplete"
+	ErrURChecksum     Error = "UR message checksum mismatch"
+	ErrURFragmentLen  Error = "UR fragment length must be at least 10 bytes"
+
+	urScheme             = "ur:"
+	urMinFragmentLen     = 10
+	DefaultURFragmentLen = 200
+
+	// urMaxSeqLen bounds the number of fragments accepted by the decoder.
+	urMaxSeqLen = 10000
+)
+
+// EncodeUR encodes a CBOR payload as a single-part Uniform Resource
+// (BCR-2020-005), e.g. "ur:crypto-psbt/...".
+func EncodeUR(urType string, cbor []byte) (string, error) {
+	if !validURType(urType) {
+		return "", ErrURType
+	}
+	return urScheme + urType + "/" + encodeBytewordsMinimal(cbor), nil
+}
+
+// DecodeUR decodes a single-part Uniform Resource and returns its type and
+// CBOR payload. Multi-part URs are decoded with an URDecoder.
+func DecodeUR(ur string) (string, []byte, error) {
+	urType, components, err := parseUR(ur)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(components) != 1 {
+		return "", nil, ErrURInvalid
+	}
+	cbor, err := decodeBytewordsMinimal(components[0])
+	if err != nil {
+		return "", nil, err
+	}
+	return urType, cbor, nil
+}
+
+// parseUR splits an UR into its type and path components.
+func parseUR(ur string) (string, []string, error) {
+	ur = strings.ToLower(ur)
+	if !strings.HasPrefix(ur, urScheme) {
+		return "", nil, ErrURInvalid
+	}
+	components := strings.Split(ur[len(urScheme):], "/")
+	if len(components) < 2 || len(components) > 3 {
+		return "", nil, ErrURInvalid
+	}
+	if !validURType(components[0]) {
+		return "", nil, ErrURType
+	}
+	return components[0], components[1:], nil
+}
+
+// validURType checks that an UR type only uses lowercase letters, digits and hyphens.
+func validURType(urType string) bool {
+	if urType == "" {
+		return false
+	}
+	for _, r := range urType {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// UREncoder splits a message into an endless sequence of UR parts using the
+// fountain codes of BCR-2020-005: the first parts carry the fragments of the
+// message in order, the following ones random combinations of fragments, so a
+// QR animation can be scanned from any point and with missed frames.
+type UREncoder struct {
+	urType      string
+	message     []byte
+	checksum    uint32
+	fragmentLen int
+	fragments   [][]byte
+	seqNum      uint32
+}
+
+// NewUREncoder creates an encoder for a CBOR payload, with fragments of at
+// most maxFragmentLen bytes.
+func NewUREncoder(urType string, cbor []byte, maxFragmentLen int) (*UREncoder, error) {
+	if !validURType(urType) {
+		return nil, ErrURType
+	}
+	if maxFragmentLen < urMinFragmentLen {
+		return nil, ErrURFragmentLen
+	}
+
+	fragmentLen := nominalFragmentLen(len(cbor), urMinFragmentLen, maxFragmentLen)
+	count := (len(cbor) + fragmentLen - 1) / fragmentLen
+	padded := make([]byte, count*fragmentLen)
+	copy(padded, cbor)
+
+	fragments := make([][]byte, count)
+	for i := range fragments {
+		fragments[i] = padded[i*fragmentLen : (i+1)*fragmentLen]
+	}
+
+	return &UREncoder{
+		urType:      urType,
+		message:     append([]byte(nil), cbor...),
+		checksum:    crc32.ChecksumIEEE(cbor),
+		fragmentLen: fragmentLen,
+		fragments:   fragments,
+	}, nil
+}
+
+// SeqLen returns the number of fragments of the message.
+func (s *UREncoder) SeqLen() int {
+	return len(s.fragments)
+}
+
+// IsSinglePart reports whether the message fits in a single part.
+func (s *UREncoder) IsSinglePart() bool {
+	return len(s.fragments) == 1
+}
+
+// NextPart returns the next UR part. Single-part messages always return the
+// same complete UR.
+func (s *UREncoder) NextPart() string {
+	if s.IsSinglePart() {
+		ur, _ := EncodeUR(s.urType, s.message)
+		return ur
+	}
+
+	s.seqNum++
+	seqLen := uint32(len(s.fragments))
+	data := make([]byte, s.fragmentLen)
+	for _, index := range chooseFragments(s.seqNum, seqLen, s.checksum) {
+		for i, c := range s.fragments[index] {
+			data[i] ^= c
+		}
+	}
+
+	part, _ := cborEncode([]interface{}{
+		uint64(s.seqNum), uint64(seqLen), uint64(len(s.message)), uint64(s.checksum), data,
+	})
+	return fmt.Sprintf("%s%s/%d-%d/%s", urScheme, s.urType, s.seqNum, seqLen, encodeBytewordsMinimal(part))
+}
+
+// urPart is a decoded part of a multi-part UR.
+type urPart struct {
+	indexes map[int]bool
+	data    []byte
+}
+
+// URDecoder reassembles the message of a multi-part UR from its parts,
+// received in any order, with duplicates and missing parts.
+type URDecoder struct {
+	urType     string
+	seqLen     int
+	messageLen int
+	checksum   uint32
+	fragments  map[int][]byte
+	mixed      []*urPart
+	result     []byte
+}
+
+// NewURDecoder creates an empty decoder.
+func NewURDecoder() *URDecoder {
+	return &URDecoder{fragments: make(map[int][]byte)}
+}
+
+// Receive processes a part, single or multi-part. It returns an error if the
+// part is invalid or belongs to another message.
+func (s *URDecoder) Receive(ur string) error {
+	urType, components, err := parseUR(ur)
+	if err != nil {
+		return err
+	}
+	if s.urType != "" && s.urType != urType {
+		return ErrURPartMismatch
+	}
+
+	if len(components) == 1 {
+		cbor, err := decodeBytewordsMinimal(components[0])
+		if err != nil {
+			return err
+		}
+		s.urType, s.result = urType, cbor
+		return nil
+	}
+
+	seq := strings.SplitN(components[0], "-", 2)
+	if len(seq) != 2 {
+		return ErrURInvalid
+	}
+	seqNum, err1 := strconv.ParseUint(seq[0], 10, 32)
+	seqLen, err2 := strconv.ParseUint(seq[1], 10, 32)
+	if err1 != nil || err2 != nil || seqNum == 0 || seqLen == 0 {
+		return ErrURInvalid
+	}
+
+	raw, err := decodeBytewordsMinimal(components[1])
+	if err != nil {
+		return err
+	}
+	part, err := decodeURPart(raw, uint32(seqNum), uint32(seqLen))
+	if err != nil {
+		return err
+	}
+
+	if s.urType == "" {
+		if part.seqLen > urMaxSeqLen || part.messageLen == 0 ||
+			part.seqLen != (part.messageLen+len(part.data)-1)/len(part.data) {
+			return ErrURInvalid
+		}
+		s.urType, s.seqLen, s.messageLen, s.checksum = urType, part.seqLen, part.messageLen, part.checksum
+	} else if part.seqLen != s.seqLen || part.messageLen != s.messageLen || part.checksum != s.checksum {
+		return ErrURPartMismatch
+	}
+	if s.result != nil {
+		return nil
+	}
+	if fragLen := s.fragmentLen(); fragLen != 0 && len(part.data) != fragLen {
+		return ErrURPartMismatch
+	}
+
+	indexes := make(map[int]bool)
+	for _, index := range chooseFragments(uint32(seqNum), uint32(seqLen), part.checksum) {
+		indexes[index] = true
+	}
+	s.process(&urPart{indexes: indexes, data: part.data})
+	return s.assemble()
+}
+
+// Complete reports whether the whole message has been received.
+func (s *URDecoder) Complete() bool {
+	return s.result != nil
+}
+
+// Progress returns the fraction of fragments already recovered.
+func (s *URDecoder) Progress() float64 {
+	if s.result != nil {
+		return 1
+	}
+	if s.seqLen == 0 {
+		return 0
+	}
+	return float64(len(s.fragments)) / float64(s.seqLen)
+}
+
+// Result returns the type and CBOR payload of the decoded message.
+func (s *URDecoder) Result() (string, []byte, error) {
+	if s.result == nil {
+		return "", nil, ErrURIncomplete
+	}
+	return s.urType, s.result, nil
+}
+
+// fragmentLen returns the length of the fragments already received, if any.
+func (s *URDecoder) fragmentLen() int {
+	for _, data := range s.fragments {
+		return len(data)
+	}
+	for _, part := range s.mixed {
+		return len(part.data)
+	}
+	return 0
+}
+
+// process reduces a part with the known fragments, records it as a fragment
+// when it is simple, and propagates new fragments to the mixed parts.
+func (s *URDecoder) process(part *urPart) {
+	queue := []*urPart{part}
+	for len(queue) > 0 {
+		part, queue = queue[0], queue[1:]
+		s.reduce(part)
+
+		switch len(part.indexes) {
+		case 0:
+			continue
+		case 1:
+			var index int
+			for index = range part.indexes {
+			}
+			if _, ok := s.fragments[index]; ok {
+				continue
+			}
+			s.fragments[index] = part.data
+
+			// The new fragment may reduce mixed parts to simple ones.
+			remaining := s.mixed[:0]
+			for _, mixed := range s.mixed {
+				if mixed.indexes[index] {
+					queue = append(queue, mixed)
+				} else {
+					remaining = append(remaining, mixed)
+				}
+			}
+			s.mixed = remaining
+		default:
+			if !s.hasMixed(part) {
+				s.mixed = append(s.mixed, part)
+			}
+		}
+	}
+}
+
+// reduce removes the known fragments from a mixed part.
+func (s *URDecoder) reduce(part *urPart) {
+	if len(part.indexes) < 2 {
+		return
+	}
+	data := append([]byte(nil), part.data...)
+	for index := range part.indexes {
+		fragment, ok := s.fragments[index]
+		if !ok {
+			continue
+		}
+		for i, c := range fragment {
+			data[i] ^= c
+		}
+		delete(part.indexes, index)
+	}
+	part.data = data
+}
+
+// hasMixed reports whether a mixed part with the same fragments is known.
+func (s *URDecoder) hasMixed(part *urPart) bool {
+	for _, mixed := range s.mixed {
+		if len(mixed.indexes) != len(part.indexes) {
+			continue
+		}
+		same := true
+		for index := range part.indexes {
+			same = same && mixed.indexes[index]
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+// assemble joins the fragments once all of them are known.
+func (s *URDecoder) assemble() error {
+	if len(s.fragments) < s.seqLen {
+		return nil
+	}
+	message := make([]byte, 0, s.seqLen*s.fragmentLen())
+	for i := 0; i < s.seqLen; i++ {
+		message = append(message, s.fragments[i]...)
+	}
+	message = message[:s.messageLen]
+	if crc32.ChecksumIEEE(message) != s.checksum {
+		return ErrURChecksum
+	}
+	s.result = message
+	return nil
+}
+
+// decodedURPart is the CBOR content of a multi-part UR part.
+type decodedURPart struct {
+	seqLen     int
+	messageLen int
+	checksum   uint32
+	data       []byte
+}
+
+// decodeURPart decodes the CBOR array [seqNum, seqLen, messageLen, checksum, data].
+func decodeURPart(raw []byte, seqNum, seqLen uint32) (*decodedURPart, error) {
+	v, err := cborDecode(raw)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := v.([]interface{})
+	if !ok || len(items) != 5 {
+		return nil, ErrURInvalid
+	}
+	n, ok1 := items[0].(uint64)
+	l, ok2 := items[1].(uint64)
+	messageLen, ok3 := items[2].(uint64)
+	checksum, ok4 := items[3].(uint64)
+	data, ok5 := items[4].([]byte)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || n != uint64(seqNum) || l != uint64(seqLen) ||
+		checksum > math.MaxUint32 || messageLen > math.MaxInt32 || len(data) == 0 {
+		return nil, ErrURInvalid
+	}
+	return &decodedURPart{
+		seqLen:     int(l),
+		messageLen: int(messageLen),
+		checksum:   uint32(checksum),
+		data:       data,
+	}, nil
+}
+
+// nominalFragmentLen returns the smallest fragment length not exceeding
+// maxFragmentLen that splits the message in equal fragments.
+func nominalFragmentLen(messageLen, minFragmentLen, maxFragmentLen int) int {
+	maxFragmentCount := messageLen / minFragmentLen
+	fragmentLen := messageLen
+	for count := 1; count <= maxFragmentCount; count++ {
+		fragmentLen = (messageLen + count - 1) / count
+		if fragmentLen <= maxFragmentLen {
+			break
+		}
+	}
+	if fragmentLen == 0 {
+		fragmentLen = 1
+	}
+	return fragmentLen
+}
+
+// chooseFragments returns the indexes of the fragments mixed in a part. The
+// first seqLen parts are the fragments themselves, the following ones are
+// pseudo-random combinations seeded by the part number and checksum.
+func chooseFragments(seqNum, seqLen, checksum uint32) []int {
+	if seqNum <= seqLen {
+		return []int{int(seqNum - 1)}
+	}
+
+	seed := make([]byte, 8)
+	binary.BigEndian.PutUint32(seed[:4], seqNum)
+	binary.BigEndian.PutUint32(seed[4:], checksum)
+	rng := newXoshiro256(seed)
+
+	degree := chooseDegree(int(seqLen), rng)
+	remaining := make([]int, seqLen)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	shuffled := make([]int, 0, seqLen)
+	for len(remaining) > 0 {
+		i := rng.nextInt(0, uint64(len(remaining)-1))
+		shuffled = append(shuffled, remaining[i])
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return shuffled[:degree]
+}
+
+// chooseDegree picks the number of fragments mixed in a part, following the
+// distribution of probability 1/i for degree i.
+func chooseDegree(seqLen int, rng *xoshiro256) int {
+	probs := make([]float64, seqLen)
+	for i := range probs {
+		probs[i] = 1 / float64(i+1)
+	}
+	return newAliasSampler(probs).next(rng) + 1
+}
+
+// xoshiro256 is the xoshiro256** generator used by UR fountain codes.
+type xoshiro256 struct {
+	s [4]uint64
+}
+
+// newXoshiro256 seeds the generator with the SHA-256 digest of the seed.
+func newXoshiro256(seed []byte) *xoshiro256 {
+	digest := sha256.Sum256(seed)
+	rng := &xoshiro256{}
+	for i := range rng.s {
+		rng.s[i] = binary.BigEndian.Uint64(digest[i*8:])
+	}
+	return rng
+}
+
+// next returns the next 64 bits of the sequence.
+func (s *xoshiro256) next() uint64 {
+	result := bits.RotateLeft64(s.s[1]*5, 7) * 9
+	t := s.s[1] << 17
+
+	s.s[2] ^= s.s[0]
+	s.s[3] ^= s.s[1]
+	s.s[1] ^= s.s[2]
+	s.s[0] ^= s.s[3]
+	s.s[2] ^= t
+	s.s[3] = bits.RotateLeft64(s.s[3], 45)
+	return result
+}
+
+// nextDouble returns a float in [0, 1).
+func (s *xoshiro256) nextDouble() float64 {
+	return float64(s.next()) / (float64(math.MaxUint64) + 1)
+}
+
+// nextInt returns an integer in [low, high].
+func (s *xoshiro256) nextInt(low, high uint64) int {
+	n := uint64(s.nextDouble()*float64(high-low+1)) + low
+	if n > high {
+		// nextDouble rounds to 1 for the very last values of the sequence.
+		n = high
+	}
+	return int(n)
+}
+
+// aliasSampler draws indexes following a discrete distribution (Vose alias method).
+type aliasSampler struct {
+	probs   []float64
+	aliases []int
+}
+
+// newAliasSampler builds a sampler for the given (unnormalized) weights.
+func newAliasSampler(weights []float64) *aliasSampler {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	n := len(weights)
+	p := make([]float64, n)
+	for i, w := range weights {
+		p[i] = w * float64(n) / sum
+	}
+
+	var small, large []int
+	for i := n - 1; i >= 0; i-- {
+		if p[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	probs := make([]float64, n)
+	aliases := make([]int, n)
+	for len(small) > 0 && len(large) > 0 {
+		a := small[len(small)-1]
+		small = small[:len(small)-1]
+		g := large[len(large)-1]
+		large = large[:len(large)-1]
+
+		probs[a] = p[a]
+		aliases[a] = g
+		p[g] += p[a] - 1
+		if p[g] < 1 {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+	for _, i := range large {
+		probs[i] = 1
+	}
+	for _, i := range small {
+		probs[i] = 1
+	}
+	return &aliasSampler{probs: probs, aliases: aliases}
+}
+
+// next draws an index.
+func (s *aliasSampler) next(rng *xoshiro256) int {
+	r1, r2 := rng.nextDouble(), rng.nextDouble()
+	i := int(float64(len(s.probs)) * r1)
+	if r2 < s.probs[i] {
+		return i
+	}
+	return s.aliases[i]
+}
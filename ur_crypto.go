@@ -0,0 +1,253 @@
+package p2pkh
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ErrCryptoAccountInvalid Error = "invalid crypto-account"
+	ErrCryptoPSBTInvalid    Error = "invalid crypto-psbt"
+
+	URTypeCryptoAccount = "crypto-account"
+	URTypeCryptoPSBT    = "crypto-psbt"
+
+	// Tags of the Blockchain Commons registry (BCR-2020-006).
+	cborTagHDKey    = 303
+	cborTagKeypath  = 304
+	cborTagCoinInfo = 305
+	cborTagSH       = 400
+	cborTagPKH      = 403
+	cborTagWPKH     = 404
+	cborTagTR       = 409
+)
+
+// CryptoOutput is an account output descriptor carried by a crypto-account.
+type CryptoOutput struct {
+	AddressType AddressType
+	// Path is the derivation path of XPub, e.g. "m/44'/0'/0'".
+	Path string
+	XPub string
+}
+
+// CryptoAccount is the content of a crypto-account UR (BCR-2020-015): the
+// master fingerprint and the account xpubs of a wallet, as exchanged with
+// QR-only hardware signers.
+type CryptoAccount struct {
+	MasterFingerprint uint32
+	Outputs           []CryptoOutput
+}
+
+// AccountUREncoder returns an encoder of the wallet account as a
+// crypto-account UR.
+func (s *Wallet) AccountUREncoder(maxFragmentLen int) (*UREncoder, error) {
+	origin, err := s.keyOrigin()
+	if err != nil {
+		return nil, err
+	}
+	hdkey, err := cryptoHDKey(origin, s.network)
+	if err != nil {
+		return nil, err
+	}
+
+	cbor, err := cborEncode(cborMap{
+		1: uint64(origin.fingerprint),
+		2: []interface{}{cborTag{Number: cborTagPKH, Content: hdkey}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewUREncoder(URTypeCryptoAccount, cbor, maxFragmentLen)
+}
+
+// PSBTUREncoder returns an encoder of a serialized PSBT as a crypto-psbt UR.
+func PSBTUREncoder(psbt []byte, maxFragmentLen int) (*UREncoder, error) {
+	cbor, err := cborEncode(psbt)
+	if err != nil {
+		return nil, err
+	}
+	return NewUREncoder(URTypeCryptoPSBT, cbor, maxFragmentLen)
+}
+
+// DecodeCryptoPSBT returns the serialized PSBT carried by a crypto-psbt payload.
+func DecodeCryptoPSBT(cbor []byte) ([]byte, error) {
+	v, err := cborDecode(cbor)
+	if err != nil {
+		return nil, err
+	}
+	psbt, ok := v.([]byte)
+	if !ok {
+		return nil, ErrCryptoPSBTInvalid
+	}
+	return psbt, nil
+}
+
+// DecodeCryptoAccount decodes a crypto-account payload.
+func DecodeCryptoAccount(cbor []byte) (*CryptoAccount, error) {
+	v, err := cborDecode(cbor)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(cborMap)
+	if !ok {
+		return nil, ErrCryptoAccountInvalid
+	}
+	fingerprint, ok1 := m[1].(uint64)
+	outputs, ok2 := m[2].([]interface{})
+	if !ok1 || !ok2 || fingerprint > 0xffffffff {
+		return nil, ErrCryptoAccountInvalid
+	}
+
+	account := &CryptoAccount{MasterFingerprint: uint32(fingerprint)}
+	for _, output := range outputs {
+		decoded, err := decodeCryptoOutput(output)
+		if err != nil {
+			return nil, err
+		}
+		account.Outputs = append(account.Outputs, *decoded)
+	}
+	return account, nil
+}
+
+// cryptoHDKey encodes the key of an origin as a tagged crypto-hdkey (BCR-2020-007).
+func cryptoHDKey(origin *keyOrigin, network Network) (cborTag, error) {
+	publicKey, err := origin.key.ECPubKey()
+	if err != nil {
+		return cborTag{}, err
+	}
+
+	components := make([]interface{}, 0, 2*len(origin.path))
+	for _, n := range origin.path {
+		hardened := n >= hdkeychain.HardenedKeyStart
+		if hardened {
+			n -= hdkeychain.HardenedKeyStart
+		}
+		components = append(components, uint64(n), hardened)
+	}
+
+	hdkey := cborMap{
+		3: publicKey.SerializeCompressed(),
+		4: origin.key.ChainCode(),
+		6: cborTag{Number: cborTagKeypath, Content: cborMap{
+			1: components,
+			2: uint64(origin.fingerprint),
+			3: uint64(len(origin.path)),
+		}},
+	}
+	if network != NetworkMainnet {
+		hdkey[5] = cborTag{Number: cborTagCoinInfo, Content: cborMap{2: uint64(1)}}
+	}
+	if parent := origin.key.ParentFingerprint(); parent != 0 {
+		hdkey[8] = uint64(parent)
+	}
+	return cborTag{Number: cborTagHDKey, Content: hdkey}, nil
+}
+
+// decodeCryptoOutput decodes a tagged script expression wrapping a crypto-hdkey.
+func decodeCryptoOutput(v interface{}) (*CryptoOutput, error) {
+	tag, ok := v.(cborTag)
+	if !ok {
+		return nil, ErrCryptoAccountInvalid
+	}
+
+	var addrType AddressType
+	switch tag.Number {
+	case cborTagPKH:
+		addrType = AddressTypeP2PKH
+	case cborTagWPKH:
+		addrType = AddressTypeP2WPKH
+	case cborTagTR:
+		addrType = AddressTypeP2TR
+	case cborTagSH:
+		inner, ok := tag.Content.(cborTag)
+		if !ok || inner.Number != cborTagWPKH {
+			return nil, ErrCryptoAccountInvalid
+		}
+		addrType, tag = AddressTypeP2SH, inner
+	default:
+		return nil, ErrCryptoAccountInvalid
+	}
+
+	hdkey, ok := tag.Content.(cborTag)
+	if !ok || hdkey.Number != cborTagHDKey {
+		return nil, ErrCryptoAccountInvalid
+	}
+	xpub, path, err := decodeCryptoHDKey(hdkey.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &CryptoOutput{AddressType: addrType, Path: path, XPub: xpub}, nil
+}
+
+// decodeCryptoHDKey rebuilds the extended public key and origin path of a crypto-hdkey.
+func decodeCryptoHDKey(v interface{}) (string, string, error) {
+	m, ok := v.(cborMap)
+	if !ok {
+		return "", "", ErrCryptoAccountInvalid
+	}
+	keyData, ok1 := m[3].([]byte)
+	chainCode, ok2 := m[4].([]byte)
+	if !ok1 || !ok2 || len(keyData) != 33 || len(chainCode) != 32 {
+		return "", "", ErrCryptoAccountInvalid
+	}
+	if private, _ := m[2].(bool); private {
+		return "", "", ErrCryptoAccountInvalid
+	}
+
+	params := &chaincfg.MainNetParams
+	if info, ok := m[5].(cborTag); ok {
+		if infoMap, ok := info.Content.(cborMap); ok && infoMap[2] == uint64(1) {
+			params = &chaincfg.TestNet3Params
+		}
+	}
+
+	path := "m"
+	var depth uint8
+	var childNum uint32
+	if keypath, ok := m[6].(cborTag); ok {
+		keypathMap, ok := keypath.Content.(cborMap)
+		if !ok {
+			return "", "", ErrCryptoAccountInvalid
+		}
+		components, _ := keypathMap[1].([]interface{})
+		if len(components)%2 != 0 || len(components) > 2*255 {
+			return "", "", ErrCryptoAccountInvalid
+		}
+		var b strings.Builder
+		b.WriteString("m")
+		for i := 0; i < len(components); i += 2 {
+			index, ok1 := components[i].(uint64)
+			hardened, ok2 := components[i+1].(bool)
+			if !ok1 || !ok2 || index >= hdkeychain.HardenedKeyStart {
+				return "", "", ErrCryptoAccountInvalid
+			}
+			childNum = uint32(index)
+			if hardened {
+				childNum += hdkeychain.HardenedKeyStart
+				fmt.Fprintf(&b, "/%d'", index)
+			} else {
+				fmt.Fprintf(&b, "/%d", index)
+			}
+		}
+		path = b.String()
+		depth = uint8(len(components) / 2)
+		if d, ok := keypathMap[3].(uint64); ok && d <= 255 {
+			depth = uint8(d)
+		}
+	}
+
+	parentFP := make([]byte, 4)
+	if parent, ok := m[8].(uint64); ok && parent <= 0xffffffff {
+		binary.BigEndian.PutUint32(parentFP, uint32(parent))
+	}
+
+	key := hdkeychain.NewExtendedKey(params.HDPublicKeyID[:], keyData, chainCode, parentFP, depth, childNum, false)
+	if _, err := key.ECPubKey(); err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrCryptoAccountInvalid, err)
+	}
+	return key.String(), path, nil
+}
@@ -0,0 +1,107 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wallet_AccountUREncoder(t *testing.T) {
+	for _, network := range []Network{NetworkMainnet, NetworkTestnet} {
+		wallet := createKnownWallet(t, network)
+		origin, err := wallet.keyOrigin()
+		assert.NoError(t, err)
+		xpub, err := origin.key.Neuter()
+		assert.NoError(t, err)
+
+		encoder, err := wallet.AccountUREncoder(DefaultURFragmentLen)
+		assert.NoError(t, err)
+		assert.True(t, encoder.IsSinglePart())
+
+		urType, cbor, err := DecodeUR(encoder.NextPart())
+		assert.NoError(t, err)
+		assert.Equal(t, URTypeCryptoAccount, urType)
+
+		account, err := DecodeCryptoAccount(cbor)
+		assert.NoError(t, err)
+		assert.Equal(t, origin.fingerprint, account.MasterFingerprint)
+		assert.Equal(t, []CryptoOutput{{
+			AddressType: AddressTypeP2PKH,
+			Path:        "m" + formatPathLevels(origin.path),
+			XPub:        xpub.String(),
+		}}, account.Outputs)
+	}
+}
+
+func Test_Wallet_AccountUREncoder_Vector(t *testing.T) {
+	// The seed of the crypto-account example of BCR-2020-015, whose first
+	// output is the pkh account m/44'/0'/0'.
+	wallet, err := New(&Config{
+		Mnemonic: "shield group erode awake lock sausage cash glare wave crew flame glove",
+		Path:     `m/44'/0'/0'`,
+		Network:  NetworkMainnet,
+	})
+	assert.NoError(t, err)
+	encoder, err := wallet.AccountUREncoder(DefaultURFragmentLen)
+	assert.NoError(t, err)
+	ur := encoder.NextPart()
+
+	// The example lists more outputs: past the array header, the encoding of
+	// the first one matches up to its key origin.
+	assert.True(t, strings.HasPrefix(ur, "ur:crypto-account/oeadcyemrewytyaol"))
+	assert.Contains(t, ur, "taadmutaaddloxaxhdclaxwmfmdeiamecsdsemgtvsjzcncygrkowtrontzschgezokstswkkscfmklrtauteyaahdcx"+
+		"iehfonurdppfyntapejpproypegrdawkgmaewejlsfdtsrfybdehcaflmtrlbdhpamtaaddy")
+
+	_, cbor, err := DecodeUR(ur)
+	assert.NoError(t, err)
+	v, err := cborDecode(cbor)
+	assert.NoError(t, err)
+	outputs := v.(cborMap)[2].([]interface{})
+	assert.Len(t, outputs, 1)
+	pkh := outputs[0].(cborTag)
+	assert.Equal(t, uint64(cborTagPKH), pkh.Number)
+	hdkey := pkh.Content.(cborTag)
+	assert.Equal(t, uint64(cborTagHDKey), hdkey.Number)
+	keyMap := hdkey.Content.(cborMap)
+	assert.Equal(t, "03eb3e2863911826374de86c231a4b76f0b89dfa174afb78d7f478199884d9dd32", hex.EncodeToString(keyMap[3].([]byte)))
+	origin := keyMap[6].(cborTag)
+	assert.Equal(t, uint64(cborTagKeypath), origin.Number)
+	assert.Equal(t, []interface{}{uint64(44), true, uint64(0), true, uint64(0), true}, origin.Content.(cborMap)[1])
+
+	account, err := DecodeCryptoAccount(cbor)
+	assert.NoError(t, err)
+	assert.Equal(t, &CryptoAccount{
+		MasterFingerprint: 0x37b5eed4,
+		Outputs: []CryptoOutput{{
+			AddressType: AddressTypeP2PKH,
+			Path:        "m/44'/0'/0'",
+			XPub:        "xpub6CnQkivUEH9bSbWVWfDLCtigKKgnSWGaVSRyCbN2QNBJzuvHT1vUQpgSpY1NiVvoeNEuVwk748Cn9G3NtbQB1aGGsEL7aYEnjVWgjj9tefu",
+		}},
+	}, account)
+}
+
+func Test_PSBTUREncoder(t *testing.T) {
+	psbt := append([]byte("psbt\xff"), make([]byte, 500)...)
+	encoder, err := PSBTUREncoder(psbt, 100)
+	assert.NoError(t, err)
+	assert.False(t, encoder.IsSinglePart())
+
+	decoder := NewURDecoder()
+	for !decoder.Complete() {
+		assert.NoError(t, decoder.Receive(encoder.NextPart()))
+	}
+	urType, cbor, err := decoder.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, URTypeCryptoPSBT, urType)
+
+	decoded, err := DecodeCryptoPSBT(cbor)
+	assert.NoError(t, err)
+	assert.Equal(t, psbt, decoded)
+
+	_, err = DecodeCryptoPSBT([]byte{0x01})
+	assert.ErrorIs(t, err, ErrCryptoPSBTInvalid)
+	_, err = DecodeCryptoAccount([]byte{0x01})
+	assert.ErrorIs(t, err, ErrCryptoAccountInvalid)
+}
@@ -0,0 +1,154 @@
+package p2pkh
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Xoshiro256(t *testing.T) {
+	rng := newXoshiro256([]byte("Wolf"))
+	var values []uint64
+	for i := 0; i < 10; i++ {
+		values = append(values, rng.next()%100)
+	}
+	assert.Equal(t, []uint64{42, 81, 85, 8, 82, 84, 76, 73, 70, 88}, values)
+}
+
+func Test_EncodeUR(t *testing.T) {
+	cbor, err := cborEncode([]byte("hello"))
+	assert.NoError(t, err)
+
+	ur, err := EncodeUR("bytes", cbor)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^ur:bytes/[a-z]+$`, ur)
+
+	urType, decoded, err := DecodeUR(ur)
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes", urType)
+	assert.Equal(t, cbor, decoded)
+
+	_, err = EncodeUR("Bytes!", cbor)
+	assert.ErrorIs(t, err, ErrURType)
+
+	_, _, err = DecodeUR("bytes/aeadaolazmjendeoti")
+	assert.ErrorIs(t, err, ErrURInvalid)
+}
+
+func Test_UREncoder_MultiPart(t *testing.T) {
+	message, err := cborEncode(bytes.Repeat([]byte("p2pkh"), 200))
+	assert.NoError(t, err)
+
+	encoder, err := NewUREncoder("bytes", message, 100)
+	assert.NoError(t, err)
+	assert.False(t, encoder.IsSinglePart())
+	seqLen := encoder.SeqLen()
+	assert.Greater(t, seqLen, 1)
+
+	// Every other part is lost: the fountain parts following the simple ones
+	// must still allow the decoder to recover the message.
+	decoder := NewURDecoder()
+	for i := 0; !decoder.Complete() && i < 10*seqLen; i++ {
+		part := encoder.NextPart()
+		if i%2 == 0 && i < seqLen {
+			continue
+		}
+		assert.NoError(t, decoder.Receive(part))
+	}
+	assert.True(t, decoder.Complete())
+	assert.Equal(t, 1.0, decoder.Progress())
+
+	urType, decoded, err := decoder.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes", urType)
+	assert.Equal(t, message, decoded)
+}
+
+// urTestParts are the first 20 parts of the 256 bytes message of the seed
+// "Wolf" with fragments of 30 bytes, from the reference implementation of
+// BCR-2020-005: 9 simple parts, then mixed ones.
+var urTestParts = []string{
+	"ur:bytes/1-9/lpadascfadaxcywenbpljkhdcahkadaemejtswhhylkepmykhhtsytsnoyoyaxaedsuttydmmhhpktpmsrjtdkgslpgh",
+	"ur:bytes/2-9/lpaoascfadaxcywenbpljkhdcagwdpfnsboxgwlbaawzuefywkdplrsrjynbvygabwjldapfcsgmghhkhstlrdcxaefz",
+	"ur:bytes/3-9/lpaxascfadaxcywenbpljkhdcahelbknlkuejnbadmssfhfrdpsbiegecpasvssovlgeykssjykklronvsjksopdzmol",
+	"ur:bytes/4-9/lpaaascfadaxcywenbpljkhdcasotkhemthydawydtaxneurlkosgwcekonertkbrlwmplssjtammdplolsbrdzcrtas",
+	"ur:bytes/5-9/lpahascfadaxcywenbpljkhdcatbbdfmssrkzmcwnezelennjpfzbgmuktrhtejscktelgfpdlrkfyfwdajldejokbwf",
+	"ur:bytes/6-9/lpamascfadaxcywenbpljkhdcackjlhkhybssklbwefectpfnbbectrljectpavyrolkzczcpkmwidmwoxkilghdsowp",
+	"ur:bytes/7-9/lpatascfadaxcywenbpljkhdcavszmwnjkwtclrtvaynhpahrtoxmwvwatmedibkaegdosftvandiodagdhthtrlnnhy",
+	"ur:bytes/8-9/lpayascfadaxcywenbpljkhdcadmsponkkbbhgsoltjntegepmttmoonftnbuoiyrehfrtsabzsttorodklubbuyaetk",
+	"ur:bytes/9-9/lpasascfadaxcywenbpljkhdcajskecpmdckihdyhphfotjojtfmlnwmadspaxrkytbztpbauotbgtgtaeaevtgavtny",
+	"ur:bytes/10-9/lpbkascfadaxcywenbpljkhdcahkadaemejtswhhylkepmykhhtsytsnoyoyaxaedsuttydmmhhpktpmsrjtwdkiplzs",
+	"ur:bytes/11-9/lpbdascfadaxcywenbpljkhdcahelbknlkuejnbadmssfhfrdpsbiegecpasvssovlgeykssjykklronvsjkvetiiapk",
+	"ur:bytes/12-9/lpbnascfadaxcywenbpljkhdcarllaluzmdmgstospeyiefmwejlwtpedamktksrvlcygmzemovovllarodtmtbnptrs",
+	"ur:bytes/13-9/lpbtascfadaxcywenbpljkhdcamtkgtpknghchchyketwsvwgwfdhpgmgtylctotzopdrpayoschcmhplffziachrfgd",
+	"ur:bytes/14-9/lpbaascfadaxcywenbpljkhdcapazewnvonnvdnsbyleynwtnsjkjndeoldydkbkdslgjkbbkortbelomueekgvstegt",
+	"ur:bytes/15-9/lpbsascfadaxcywenbpljkhdcaynmhpddpzmversbdqdfyrehnqzlugmjzmnmtwmrouohtstgsbsahpawkditkckynwt",
+	"ur:bytes/16-9/lpbeascfadaxcywenbpljkhdcawygekobamwtlihsnpalnsghenskkiynthdzotsimtojetprsttmukirlrsbtamjtpd",
+	"ur:bytes/17-9/lpbyascfadaxcywenbpljkhdcamklgftaxykpewyrtqzhydntpnytyisincxmhtbceaykolduortotiaiaiafhiaoyce",
+	"ur:bytes/18-9/lpbgascfadaxcywenbpljkhdcahkadaemejtswhhylkepmykhhtsytsnoyoyaxaedsuttydmmhhpktpmsrjtntwkbkwy",
+	"ur:bytes/19-9/lpbwascfadaxcywenbpljkhdcadekicpaajootjzpsdrbalpeywllbdsnbinaerkurspbncxgslgftvtsrjtksplcpeo",
+	"ur:bytes/20-9/lpbbascfadaxcywenbpljkhdcayapmrleeleaxpasfrtrdkncffwjyjzgyetdmlewtkpktgllepfrltataztksmhkbot",
+}
+
+// urTestMessage returns the CBOR byte string of the message of the reference
+// tests: length bytes drawn from the seed "Wolf".
+func urTestMessage(t *testing.T, length int) []byte {
+	rng := newXoshiro256([]byte("Wolf"))
+	message := make([]byte, length)
+	for i := range message {
+		message[i] = byte(rng.nextInt(0, 255))
+	}
+	cbor, err := cborEncode(message)
+	assert.NoError(t, err)
+	return cbor
+}
+
+func Test_UREncoder_Vectors(t *testing.T) {
+	message := urTestMessage(t, 256)
+	encoder, err := NewUREncoder("bytes", message, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, encoder.SeqLen())
+	parts := make([]string, 0, len(urTestParts))
+	for range urTestParts {
+		parts = append(parts, encoder.NextPart())
+	}
+	assert.Equal(t, urTestParts, parts)
+
+	// The mixed parts recover the simple parts lost, here the first, fourth
+	// and eighth.
+	decoder := NewURDecoder()
+	for i, part := range urTestParts {
+		if i == 0 || i == 3 || i == 7 {
+			continue
+		}
+		assert.NoError(t, decoder.Receive(part))
+	}
+	assert.True(t, decoder.Complete())
+	urType, decoded, err := decoder.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes", urType)
+	assert.Equal(t, message, decoded)
+
+	// A part altered in transit fails the checksum of its bytewords.
+	altered := []byte(urTestParts[0])
+	altered[len(altered)-5] = 'a'
+	assert.ErrorIs(t, NewURDecoder().Receive(string(altered)), ErrBytewordsChecksum)
+}
+
+func Test_URDecoder_Errors(t *testing.T) {
+	decoder := NewURDecoder()
+	_, _, err := decoder.Result()
+	assert.ErrorIs(t, err, ErrURIncomplete)
+
+	first, err := NewUREncoder("bytes", bytes.Repeat([]byte{1}, 100), 20)
+	assert.NoError(t, err)
+	second, err := NewUREncoder("bytes", bytes.Repeat([]byte{2}, 100), 20)
+	assert.NoError(t, err)
+
+	assert.NoError(t, decoder.Receive(first.NextPart()))
+	assert.ErrorIs(t, decoder.Receive(second.NextPart()), ErrURPartMismatch)
+
+	_, err = NewUREncoder("bytes", []byte{1}, 5)
+	assert.ErrorIs(t, err, ErrURFragmentLen)
+}
@@ -0,0 +1,307 @@
+package p2pkh
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const (
+	ErrVanityPrefix  Error = "vanity prefix is not a valid address prefix for the network"
+	ErrVanityMatcher Error = "vanity matcher is required"
+	ErrVanityPattern Error = "invalid vanity pattern"
+	ErrVanityNoMatch Error = "vanity search ended without match"
+
+	// base58Alphabet is the alphabet of base58 addresses.
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+// VanityMatcher selects the addresses wanted by a vanity search. It is called
+// concurrently by the search workers.
+type VanityMatcher interface {
+	Match(address string) bool
+}
+
+// VanityMatcherFunc adapts a function to the VanityMatcher interface.
+type VanityMatcherFunc func(address string) bool
+
+// Match implements VanityMatcher.
+func (f VanityMatcherFunc) Match(address string) bool {
+	return f(address)
+}
+
+// NewPrefixMatcher returns a matcher of the P2PKH addresses of a network
+// starting with prefix, e.g. "1Kid". The prefix must include the leading
+// character of the network addresses.
+func NewPrefixMatcher(prefix string, network Network, ignoreCase bool) (VanityMatcher, error) {
+	leading := "1"
+	if network == NetworkTestnet || network == NetworkTestnet4 {
+		leading = "mn"
+	} else if network != NetworkMainnet {
+		return nil, ErrUnsupportedNet
+	}
+	if prefix == "" || !strings.ContainsRune(leading, rune(prefix[0])) {
+		return nil, ErrVanityPrefix
+	}
+	for _, r := range prefix {
+		if !strings.ContainsRune(base58Alphabet, r) {
+			return nil, ErrVanityPrefix
+		}
+	}
+
+	if ignoreCase {
+		lower := strings.ToLower(prefix)
+		return VanityMatcherFunc(func(address string) bool {
+			return len(address) >= len(lower) && strings.ToLower(address[:len(lower)]) == lower
+		}), nil
+	}
+	return VanityMatcherFunc(func(address string) bool {
+		return strings.HasPrefix(address, prefix)
+	}), nil
+}
+
+// NewRegexpMatcher returns a matcher of the addresses matching a regular
+// expression, e.g. "^1Kid|999$".
+func NewRegexpMatcher(pattern string) (VanityMatcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrVanityPattern, err)
+	}
+	return VanityMatcherFunc(re.MatchString), nil
+}
+
+// VanityOptions configures a vanity search.
+type VanityOptions struct {
+	// Wallet, when set, is searched for children whose address matches,
+	// starting at StartIndex. Otherwise fresh random keys are generated.
+	Wallet     *Wallet
+	StartIndex uint32
+	// Network is the network of random keys, mainnet by default.
+	Network Network
+	// Workers is the number of parallel workers, the number of CPUs by default.
+	Workers int
+	// MaxMatches stops the search after that many matches, if positive.
+	MaxMatches int
+	// MaxAttempts stops the search after that many addresses, if positive.
+	MaxAttempts uint64
+}
+
+// VanityMatch is an address found by a vanity search.
+type VanityMatch struct {
+	Address string
+	// Index and Path designate the matching child of the searched wallet.
+	Index uint32
+	Path  string
+	// PrivateKey is the WIF private key of a random key, empty when
+	// searching a wallet.
+	PrivateKey string
+}
+
+// VanityStats are the statistics of a vanity search.
+type VanityStats struct {
+	Attempts uint64
+	Matches  uint64
+	Elapsed  time.Duration
+}
+
+// Rate returns the number of addresses tried per second.
+func (s VanityStats) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Attempts) / s.Elapsed.Seconds()
+}
+
+// String formats the statistics for progress reports.
+func (s VanityStats) String() string {
+	return fmt.Sprintf("%d addresses, %d matches in %s (%.0f/s)", s.Attempts, s.Matches, s.Elapsed.Round(time.Millisecond), s.Rate())
+}
+
+// VanitySearch is a running vanity search.
+type VanitySearch struct {
+	matches  chan VanityMatch
+	cancel   context.CancelFunc
+	done     chan struct{}
+	started  time.Time
+	ended    atomic.Int64
+	attempts atomic.Uint64
+	found    atomic.Uint64
+	next     atomic.Uint64
+}
+
+// SearchVanity starts searching, in parallel, addresses accepted by matcher
+// among the children of a wallet or fresh random keys. Matches are sent on
+// the Matches channel, which is closed when the search ends: on context
+// cancellation, Stop, or when a limit of the options is reached.
+func SearchVanity(ctx context.Context, matcher VanityMatcher, opts *VanityOptions) (*VanitySearch, error) {
+	if matcher == nil {
+		return nil, ErrVanityMatcher
+	}
+	if opts == nil {
+		opts = &VanityOptions{}
+	}
+	network := opts.Network
+	if opts.Wallet != nil {
+		network = opts.Wallet.network
+	} else if network == "" {
+		network = NetworkMainnet
+	}
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	search := &VanitySearch{
+		matches: make(chan VanityMatch, workers),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		started: time.Now(),
+	}
+	search.next.Store(uint64(opts.StartIndex))
+
+	// try derives the next candidate. It returns nil for the rare keys that
+	// cannot be used, and false once the wallet indexes are exhausted.
+	try := func() (*VanityMatch, bool) {
+		if opts.Wallet != nil {
+			index := search.next.Add(1) - 1
+			if index >= hdkeychain.HardenedKeyStart {
+				return nil, false
+			}
+			child, err := opts.Wallet.Derive(uint32(index))
+			if err != nil {
+				return nil, true
+			}
+			return &VanityMatch{Address: child.AddressInfo().String(), Index: uint32(index), Path: child.Path()}, true
+		}
+
+		key, err := btcec.NewPrivateKey()
+		if err != nil {
+			return nil, true
+		}
+		addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(key.PubKey().SerializeCompressed()), params)
+		if err != nil {
+			return nil, true
+		}
+		wif, err := btcutil.NewWIF(key, params, true)
+		if err != nil {
+			return nil, true
+		}
+		return &VanityMatch{Address: addr.EncodeAddress(), PrivateKey: wif.String()}, true
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				// Workers end by themselves once the attempts or the indexes
+				// are exhausted, so that the candidates in flight are not lost.
+				if attempt := search.attempts.Add(1); opts.MaxAttempts > 0 && attempt > opts.MaxAttempts {
+					search.attempts.Add(^uint64(0))
+					return
+				}
+				candidate, ok := try()
+				if !ok {
+					search.attempts.Add(^uint64(0))
+					return
+				}
+				if candidate == nil || !matcher.Match(candidate.Address) {
+					continue
+				}
+
+				found := search.found.Add(1)
+				if opts.MaxMatches > 0 && found > uint64(opts.MaxMatches) {
+					search.found.Add(^uint64(0))
+					return
+				}
+				select {
+				case search.matches <- *candidate:
+				case <-ctx.Done():
+					search.found.Add(^uint64(0))
+					return
+				}
+				if opts.MaxMatches > 0 && found == uint64(opts.MaxMatches) {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		search.ended.Store(int64(time.Since(search.started)))
+		cancel()
+		close(search.matches)
+		close(search.done)
+	}()
+	return search, nil
+}
+
+// Matches returns the channel of matches, closed when the search ends.
+func (s *VanitySearch) Matches() <-chan VanityMatch {
+	return s.matches
+}
+
+// Stop stops the search. Matches already found remain readable.
+func (s *VanitySearch) Stop() {
+	s.cancel()
+}
+
+// Done returns a channel closed when the search has ended.
+func (s *VanitySearch) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stats returns the statistics of the search so far.
+func (s *VanitySearch) Stats() VanityStats {
+	elapsed := time.Duration(s.ended.Load())
+	if elapsed == 0 {
+		elapsed = time.Since(s.started)
+	}
+	return VanityStats{
+		Attempts: s.attempts.Load(),
+		Matches:  s.found.Load(),
+		Elapsed:  elapsed,
+	}
+}
+
+// SearchVanity searches, with workers in parallel, a child of the wallet
+// whose address matches pattern: an address prefix such as "1Kid", or else a
+// regular expression. It blocks until a child is found, whose Index is the
+// one matching, or ctx is done. With several workers, the child found is not
+// necessarily the first matching one.
+func (s *Wallet) SearchVanity(ctx context.Context, pattern string, workers int) (*Wallet, error) {
+	matcher, err := NewPrefixMatcher(pattern, s.network, false)
+	if err != nil {
+		if matcher, err = NewRegexpMatcher(pattern); err != nil {
+			return nil, err
+		}
+	}
+	search, err := SearchVanity(ctx, matcher, &VanityOptions{Wallet: s, Workers: workers, MaxMatches: 1})
+	if err != nil {
+		return nil, err
+	}
+	match, ok := <-search.Matches()
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrVanityNoMatch
+	}
+	return s.Derive(match.Index)
+}
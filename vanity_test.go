@@ -0,0 +1,116 @@
+package p2pkh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewPrefixMatcher(t *testing.T) {
+	matcher, err := NewPrefixMatcher("1Ab", NetworkMainnet, false)
+	assert.NoError(t, err)
+	assert.True(t, matcher.Match("1AbcDEF"))
+	assert.False(t, matcher.Match("1abcDEF"))
+
+	matcher, err = NewPrefixMatcher("1Ab", NetworkMainnet, true)
+	assert.NoError(t, err)
+	assert.True(t, matcher.Match("1abcDEF"))
+	assert.False(t, matcher.Match("1a"))
+
+	for _, prefix := range []string{"", "1O", "3Ab", "bc1q"} {
+		_, err = NewPrefixMatcher(prefix, NetworkMainnet, false)
+		assert.ErrorIs(t, err, ErrVanityPrefix, prefix)
+	}
+	_, err = NewPrefixMatcher("1Ab", NetworkTestnet, false)
+	assert.ErrorIs(t, err, ErrVanityPrefix)
+	_, err = NewPrefixMatcher("mAb", NetworkTestnet, false)
+	assert.NoError(t, err)
+}
+
+func Test_SearchVanity_Wallet(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	matcher := VanityMatcherFunc(func(address string) bool {
+		return strings.ContainsAny(address[1:2], "ABCDEFGH")
+	})
+
+	search, err := SearchVanity(context.Background(), matcher, &VanityOptions{Wallet: wallet, Workers: 4, MaxMatches: 3})
+	assert.NoError(t, err)
+	var matches []VanityMatch
+	for match := range search.Matches() {
+		matches = append(matches, match)
+	}
+	assert.Len(t, matches, 3)
+	for _, match := range matches {
+		assert.True(t, matcher.Match(match.Address))
+		child, err := wallet.Derive(match.Index)
+		assert.NoError(t, err)
+		assert.Equal(t, child.AddressHex(), match.Address)
+		assert.Equal(t, child.Path(), match.Path)
+		assert.Empty(t, match.PrivateKey)
+	}
+
+	stats := search.Stats()
+	assert.Equal(t, uint64(3), stats.Matches)
+	assert.GreaterOrEqual(t, stats.Attempts, uint64(3))
+}
+
+func Test_SearchVanity_Random(t *testing.T) {
+	matcher, err := NewPrefixMatcher("1", NetworkMainnet, false)
+	assert.NoError(t, err)
+
+	search, err := SearchVanity(context.Background(), matcher, &VanityOptions{Workers: 2, MaxAttempts: 50})
+	assert.NoError(t, err)
+	count := 0
+	for match := range search.Matches() {
+		wif, err := btcutil.DecodeWIF(match.PrivateKey)
+		assert.NoError(t, err)
+		addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), &chaincfg.MainNetParams)
+		assert.NoError(t, err)
+		assert.Equal(t, addr.EncodeAddress(), match.Address)
+		count++
+	}
+	<-search.Done()
+	assert.Equal(t, 50, count)
+	assert.Equal(t, uint64(50), search.Stats().Attempts)
+}
+
+func Test_SearchVanity_Stop(t *testing.T) {
+	never := VanityMatcherFunc(func(string) bool { return false })
+	search, err := SearchVanity(context.Background(), never, &VanityOptions{Workers: 2})
+	assert.NoError(t, err)
+	search.Stop()
+	<-search.Done()
+	_, open := <-search.Matches()
+	assert.False(t, open)
+
+	_, err = SearchVanity(context.Background(), nil, nil)
+	assert.ErrorIs(t, err, ErrVanityMatcher)
+}
+
+func Test_Wallet_SearchVanity(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+
+	child, err := wallet.SearchVanity(context.Background(), "1A", 4)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(child.AddressHex(), "1A"))
+	index, ok := child.Index()
+	assert.True(t, ok)
+	expected, err := wallet.Derive(index)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.AddressHex(), child.AddressHex())
+
+	child, err = wallet.SearchVanity(context.Background(), "[xyz]$", 2)
+	assert.NoError(t, err)
+	assert.Regexp(t, "[xyz]$", child.AddressHex())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = wallet.SearchVanity(ctx, "^never0$", 2)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = wallet.SearchVanity(context.Background(), "1(", 2)
+	assert.ErrorIs(t, err, ErrVanityPattern)
+}
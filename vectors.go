@@ -0,0 +1,219 @@
+package p2pkh
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	ErrVectorSeed     Error = "invalid test vector seed"
+	ErrVectorMismatch Error = "derivation does not match the test vector"
+)
+
+// VectorKeys are the extended keys of a test vector chain.
+type VectorKeys struct {
+	ExtendedPublicKey  string
+	ExtendedPrivateKey string
+}
+
+// DeriveFromVector derives the extended keys at path of the master key of a
+// hex encoded seed, as the BIP32 test vectors describe them, e.g. seed
+// 000102030405060708090a0b0c0d0e0f and path m/0'/1 for the third chain of
+// the first vector.
+func DeriveFromVector(seedHex, path string, network Network) (*VectorKeys, error) {
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrVectorSeed, err)
+	}
+	defer zero(seed)
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := generateMasterKey(seed, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, level := range levels {
+		if key, err = deriveKey(key, level); err != nil {
+			return nil, err
+		}
+	}
+	neutered, err := key.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	return &VectorKeys{ExtendedPublicKey: neutered.String(), ExtendedPrivateKey: key.String()}, nil
+}
+
+// knownVector is a published test vector: a chain of a BIP32 seed, or a key
+// of a BIP39 mnemonic at a BIP44, BIP49, BIP84 or BIP86 path.
+type knownVector struct {
+	name     string
+	seed     string
+	mnemonic string
+	path     string
+	network  Network
+	xpub     string
+	xprv     string
+	// address is the address of the purpose of the path.
+	address string
+}
+
+const (
+	bip32Vector1Seed = "000102030405060708090a0b0c0d0e0f"
+	bip32Vector2Seed = "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542"
+	bip32Vector3Seed = "4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be"
+	bip39VectorWords = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+)
+
+// knownVectors are the test vectors of BIP32, and the ones of BIP44, BIP49,
+// BIP84 and BIP86 for the mnemonic of their specifications.
+var knownVectors = []knownVector{
+	{
+		name: "BIP32 vector 1 m", seed: bip32Vector1Seed, path: "m", network: NetworkMainnet,
+		xpub: "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		xprv: "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+	},
+	{
+		name: "BIP32 vector 1 m/0'", seed: bip32Vector1Seed, path: "m/0'", network: NetworkMainnet,
+		xpub: "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		xprv: "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+	},
+	{
+		name: "BIP32 vector 1 m/0'/1", seed: bip32Vector1Seed, path: "m/0'/1", network: NetworkMainnet,
+		xpub: "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+		xprv: "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+	},
+	{
+		name: "BIP32 vector 1 m/0'/1/2'", seed: bip32Vector1Seed, path: "m/0'/1/2'", network: NetworkMainnet,
+		xpub: "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+		xprv: "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM",
+	},
+	{
+		name: "BIP32 vector 1 m/0'/1/2'/2", seed: bip32Vector1Seed, path: "m/0'/1/2'/2", network: NetworkMainnet,
+		xpub: "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+		xprv: "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334",
+	},
+	{
+		name: "BIP32 vector 1 m/0'/1/2'/2/1000000000", seed: bip32Vector1Seed, path: "m/0'/1/2'/2/1000000000", network: NetworkMainnet,
+		xpub: "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+		xprv: "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+	},
+	{
+		name: "BIP32 vector 2 m", seed: bip32Vector2Seed, path: "m", network: NetworkMainnet,
+		xpub: "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+		xprv: "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U",
+	},
+	{
+		name: "BIP32 vector 2 m/0", seed: bip32Vector2Seed, path: "m/0", network: NetworkMainnet,
+		xpub: "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+		xprv: "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt",
+	},
+	{
+		name: "BIP32 vector 2 m/0/2147483647'", seed: bip32Vector2Seed, path: "m/0/2147483647'", network: NetworkMainnet,
+		xpub: "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a",
+		xprv: "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9",
+	},
+	{
+		name: "BIP32 vector 2 m/0/2147483647'/1", seed: bip32Vector2Seed, path: "m/0/2147483647'/1", network: NetworkMainnet,
+		xpub: "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon",
+		xprv: "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef",
+	},
+	{
+		name: "BIP32 vector 2 m/0/2147483647'/1/2147483646'", seed: bip32Vector2Seed, path: "m/0/2147483647'/1/2147483646'", network: NetworkMainnet,
+		xpub: "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL",
+		xprv: "xprvA1RpRA33e1JQ7ifknakTFpgNXPmW2YvmhqLQYMmrj4xJXXWYpDPS3xz7iAxn8L39njGVyuoseXzU6rcxFLJ8HFsTjSyQbLYnMpCqE2VbFWc",
+	},
+	{
+		name: "BIP32 vector 2 m/0/2147483647'/1/2147483646'/2", seed: bip32Vector2Seed, path: "m/0/2147483647'/1/2147483646'/2", network: NetworkMainnet,
+		xpub: "xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt",
+		xprv: "xprvA2nrNbFZABcdryreWet9Ea4LvTJcGsqrMzxHx98MMrotbir7yrKCEXw7nadnHM8Dq38EGfSh6dqA9QWTyefMLEcBYJUuekgW4BYPJcr9E7j",
+	},
+	{
+		// The master private key has leading zeros, which derivation must retain.
+		name: "BIP32 vector 3 m", seed: bip32Vector3Seed, path: "m", network: NetworkMainnet,
+		xpub: "xpub661MyMwAqRbcEZVB4dScxMAdx6d4nFc9nvyvH3v4gJL378CSRZiYmhRoP7mBy6gSPSCYk6SzXPTf3ND1cZAceL7SfJ1Z3GC8vBgp2epUt13",
+		xprv: "xprv9s21ZrQH143K25QhxbucbDDuQ4naNntJRi4KUfWT7xo4EKsHt2QJDu7KXp1A3u7Bi1j8ph3EGsZ9Xvz9dGuVrtHHs7pXeTzjuxBrCmmhgC6",
+	},
+	{
+		name: "BIP32 vector 3 m/0'", seed: bip32Vector3Seed, path: "m/0'", network: NetworkMainnet,
+		xpub: "xpub68NZiKmJWnxxS6aaHmn81bvJeTESw724CRDs6HbuccFQN9Ku14VQrADWgqbhhTHBaohPX4CjNLf9fq9MYo6oDaPPLPxSb7gwQN3ih19Zm4Y",
+		xprv: "xprv9uPDJpEQgRQfDcW7BkF7eTya6RPxXeJCqCJGHuCJ4GiRVLzkTXBAJMu2qaMWPrS7AANYqdq6vcBcBUdJCVVFceUvJFjaPdGZ2y9WACViL4L",
+	},
+	{
+		name: "BIP32 vector 1 m testnet", seed: bip32Vector1Seed, path: "m", network: NetworkTestnet,
+		xpub: "tpubD6NzVbkrYhZ4XgiXtGrdW5XDAPFCL9h7we1vwNCpn8tGbBcgfVYjXyhWo4E1xkh56hjod1RhGjxbaTLV3X4FyWuejifB9jusQ46QzG87VKp",
+		xprv: "tprv8ZgxMBicQKsPeDgjzdC36fs6bMjGApWDNLR9erAXMs5skhMv36j9MV5ecvfavji5khqjWaWSFhN3YcCUUdiKH6isR4Pwy3U5y5egddBr16m",
+	},
+	{
+		name: "BIP44 account", mnemonic: bip39VectorWords, path: "m/44'/0'/0'", network: NetworkMainnet,
+		xpub: "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj",
+	},
+	{
+		name: "BIP44 first address", mnemonic: bip39VectorWords, path: "m/44'/0'/0'/0/0", network: NetworkMainnet,
+		address: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA",
+	},
+	{
+		name: "BIP49 first address", mnemonic: bip39VectorWords, path: "m/49'/1'/0'/0/0", network: NetworkTestnet,
+		address: "2Mww8dCYPUpKHofjgcXcBCEGmniw9CoaiD2",
+	},
+	{
+		name: "BIP84 first address", mnemonic: bip39VectorWords, path: "m/84'/0'/0'/0/0", network: NetworkMainnet,
+		address: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+	},
+	{
+		name: "BIP86 first address", mnemonic: bip39VectorWords, path: "m/86'/0'/0'/0/0", network: NetworkMainnet,
+		address: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+	},
+}
+
+// VerifyKnownVectors derives the published BIP32 test vectors, and the keys
+// of the BIP44, BIP49, BIP84 and BIP86 test mnemonic, and reports the first
+// one not matching its specification. Integrators may call it at startup to
+// assert that derivation on their platform, and with their dependencies,
+// follows the specifications.
+func VerifyKnownVectors() error {
+	for _, vector := range knownVectors {
+		if err := vector.verify(); err != nil {
+			return fmt.Errorf("%s: %w", vector.name, err)
+		}
+	}
+	return nil
+}
+
+// verify derives the keys of the vector and compares them to the expected
+// ones.
+func (s *knownVector) verify() error {
+	keys := &VectorKeys{}
+	var address string
+	if s.mnemonic != "" {
+		wallet, err := New(&Config{Mnemonic: s.mnemonic, Path: s.path, Network: s.network, ForgetMnemonic: true})
+		if err != nil {
+			return err
+		}
+		if keys.ExtendedPublicKey, err = wallet.ExtendedPublicKey(); err != nil {
+			return err
+		}
+		address = wallet.PurposeAddress().String()
+	} else {
+		var err error
+		if keys, err = DeriveFromVector(s.seed, s.path, s.network); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case s.xpub != "" && keys.ExtendedPublicKey != s.xpub:
+		return fmt.Errorf("%w: extended public key %s, expected %s", ErrVectorMismatch, keys.ExtendedPublicKey, s.xpub)
+	case s.xprv != "" && keys.ExtendedPrivateKey != s.xprv:
+		return fmt.Errorf("%w: extended private key does not match", ErrVectorMismatch)
+	case s.address != "" && address != s.address:
+		return fmt.Errorf("%w: address %s, expected %s", ErrVectorMismatch, address, s.address)
+	}
+	return nil
+}
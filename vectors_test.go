@@ -0,0 +1,41 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VerifyKnownVectors(t *testing.T) {
+	assert.NoError(t, VerifyKnownVectors())
+
+	for _, vector := range knownVectors {
+		assert.NoError(t, vector.verify(), vector.name)
+	}
+}
+
+func Test_knownVector_verify(t *testing.T) {
+	vector := knownVectors[1]
+	vector.xpub = knownVectors[0].xpub
+	assert.ErrorIs(t, vector.verify(), ErrVectorMismatch)
+
+	vector = knownVectors[len(knownVectors)-1]
+	vector.address = "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"
+	assert.ErrorIs(t, vector.verify(), ErrVectorMismatch)
+}
+
+func Test_DeriveFromVector(t *testing.T) {
+	keys, err := DeriveFromVector(bip32Vector1Seed, "m/0'/1", NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, knownVectors[2].xpub, keys.ExtendedPublicKey)
+	assert.Equal(t, knownVectors[2].xprv, keys.ExtendedPrivateKey)
+
+	_, err = DeriveFromVector("zz", "m", NetworkMainnet)
+	assert.ErrorIs(t, err, ErrVectorSeed)
+	_, err = DeriveFromVector(bip32Vector1Seed, "m/x", NetworkMainnet)
+	assert.Error(t, err)
+	_, err = DeriveFromVector("0001", "m", NetworkMainnet)
+	assert.Error(t, err)
+	_, err = DeriveFromVector(bip32Vector1Seed, "m", "unknown")
+	assert.ErrorIs(t, err, ErrUnsupportedNet)
+}
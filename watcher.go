@@ -0,0 +1,415 @@
+package p2pkh
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	ErrAddressHistoryUnsupported Error = "backend cannot list address transactions"
+	ErrInvalidThreshold          Error = "thresholds require a positive depth and a callback"
+
+	defaultWatcherPollInterval        = 30 * time.Second
+	defaultWatcherTargetConfirmations = 6
+)
+
+// TxEvent is the kind of change reported by a TxUpdate.
+type TxEvent int
+
+const (
+	// TxEventMempool is emitted when the transaction is first seen in the mempool.
+	TxEventMempool TxEvent = iota + 1
+	// TxEventConfirmation is emitted each time the confirmation count changes.
+	TxEventConfirmation
+	// TxEventConflicted is emitted when a conflicting transaction was accepted instead.
+	TxEventConflicted
+	// TxEventError is emitted when the backend could not be queried.
+	TxEventError
+	// TxEventEvicted is emitted when an unconfirmed transaction disappears
+	// from the mempool without being confirmed or conflicted.
+	TxEventEvicted
+	// TxEventReorged is emitted when a confirmed transaction is no longer in
+	// the block it was confirmed in, because of a chain reorganization.
+	TxEventReorged
+)
+
+// String returns a human readable name for the event.
+func (e TxEvent) String() string {
+	switch e {
+	case TxEventMempool:
+		return "mempool"
+	case TxEventConfirmation:
+		return "confirmation"
+	case TxEventConflicted:
+		return "conflicted"
+	case TxEventError:
+		return "error"
+	case TxEventEvicted:
+		return "evicted"
+	case TxEventReorged:
+		return "reorged"
+	default:
+		return "unknown"
+	}
+}
+
+// Alarming reports whether the event means a payment may be lost, so that
+// fulfillment should be halted: evicted, conflicted or reorged transactions.
+func (e TxEvent) Alarming() bool {
+	return e == TxEventEvicted || e == TxEventConflicted || e == TxEventReorged
+}
+
+// TxUpdate is a status change of a tracked transaction.
+type TxUpdate struct {
+	TxID   string
+	Event  TxEvent
+	Status TxStatus
+	Err    error
+}
+
+// WatcherConfig configures a Watcher. Zero values select sensible defaults.
+type WatcherConfig struct {
+	// PollInterval is the delay between two backend queries.
+	PollInterval time.Duration
+	// TargetConfirmations is the depth after which tracking stops.
+	TargetConfirmations int
+	// OnAlarm is called by Check when a transaction watched for thresholds is
+	// evicted, conflicted or reorged.
+	OnAlarm func(TxUpdate)
+}
+
+// Watcher monitors transactions and addresses through a chain backend so
+// applications can drive order-fulfillment state machines from a stream of
+// status updates or from confirmation callbacks.
+type Watcher struct {
+	mu        sync.Mutex
+	backend   TxStatusProvider
+	config    WatcherConfig
+	txs       map[string]*txSubscription
+	addresses map[string]*addressSubscription
+}
+
+// NewWatcher creates a Watcher querying the given backend.
+func NewWatcher(backend TxStatusProvider, config *WatcherConfig) *Watcher {
+	cfg := WatcherConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultWatcherPollInterval
+	}
+	if cfg.TargetConfirmations <= 0 {
+		cfg.TargetConfirmations = defaultWatcherTargetConfirmations
+	}
+	return &Watcher{
+		backend:   backend,
+		config:    cfg,
+		txs:       make(map[string]*txSubscription),
+		addresses: make(map[string]*addressSubscription),
+	}
+}
+
+// TrackTx polls the backend for the given transaction and returns a stream of
+// status updates: seen in mempool, confirmations 1..N, and the alarming
+// evicted, reorged and conflicted events. The channel is closed once the
+// target depth is reached, the transaction is conflicted, or the context is
+// cancelled.
+func (s *Watcher) TrackTx(ctx context.Context, txid string) <-chan TxUpdate {
+	updates := make(chan TxUpdate)
+
+	go func() {
+		defer close(updates)
+
+		ticker := time.NewTicker(s.config.PollInterval)
+		defer ticker.Stop()
+
+		var last TxStatus
+		for {
+			done, ok := s.poll(ctx, txid, &last, updates)
+			if done || !ok {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return updates
+}
+
+// poll queries the backend once and emits an update if the status changed.
+// It reports whether tracking is done and whether the update was delivered.
+func (s *Watcher) poll(ctx context.Context, txid string, last *TxStatus, updates chan<- TxUpdate) (bool, bool) {
+	status, err := s.backend.TxStatus(ctx, txid)
+	if err != nil {
+		if ctx.Err() != nil {
+			return true, false
+		}
+		return false, s.emit(ctx, updates, TxUpdate{TxID: txid, Event: TxEventError, Err: err})
+	}
+
+	event, changed := transition(last, status)
+	*last = *status
+	if !changed {
+		return false, true
+	}
+
+	ok := s.emit(ctx, updates, TxUpdate{TxID: txid, Event: event, Status: *status})
+	done := event == TxEventConflicted ||
+		(event == TxEventConfirmation && status.Confirmations >= s.config.TargetConfirmations)
+	return done, ok
+}
+
+// transition returns the event describing the change from the last known
+// status of a transaction to the current one, if any.
+func transition(last, status *TxStatus) (TxEvent, bool) {
+	switch {
+	case status.Conflicted:
+		return TxEventConflicted, !last.Conflicted
+	case last.Confirmed() && !status.Confirmed():
+		return TxEventReorged, true
+	case last.Confirmed() && last.BlockHash != "" && status.BlockHash != "" && last.BlockHash != status.BlockHash:
+		return TxEventReorged, true
+	case last.InMempool && !status.Known():
+		return TxEventEvicted, true
+	case status.Confirmed() && status.Confirmations != last.Confirmations:
+		return TxEventConfirmation, true
+	case status.InMempool && !last.InMempool:
+		return TxEventMempool, true
+	}
+	return 0, false
+}
+
+// emit delivers an update unless the context is cancelled first.
+func (s *Watcher) emit(ctx context.Context, updates chan<- TxUpdate, update TxUpdate) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case updates <- update:
+		return true
+	}
+}
+
+// AddressHistoryProvider is the part of a chain backend able to list the
+// transactions involving an address.
+type AddressHistoryProvider interface {
+	// AddressTxIDs returns the txids of every transaction paying to or
+	// spending from the given address, mempool included.
+	AddressTxIDs(ctx context.Context, address string) ([]string, error)
+}
+
+// Threshold registers a callback fired when a transaction reaches the given
+// confirmation depth.
+type Threshold struct {
+	Depth    int
+	Callback func(TxUpdate)
+}
+
+// txSubscription is the state of a transaction watched for thresholds.
+type txSubscription struct {
+	thresholds []Threshold
+	fired      map[int]bool
+	last       TxStatus
+}
+
+// addressSubscription is the state of an address watched for thresholds.
+type addressSubscription struct {
+	thresholds []Threshold
+	seen       map[string]bool
+}
+
+// NotifyTx registers callbacks fired when the transaction reaches each
+// threshold depth. Thresholds are re-armed when a reorg lowers the depth of
+// the transaction, so callbacks may fire again once it is reconfirmed.
+func (s *Watcher) NotifyTx(txid string, thresholds ...Threshold) error {
+	if err := validateThresholds(thresholds); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addTxSubscription(txid, thresholds)
+	return nil
+}
+
+// NotifyAddress registers callbacks fired for every transaction involving the
+// address when it reaches each threshold depth. The backend must implement
+// AddressHistoryProvider.
+func (s *Watcher) NotifyAddress(address string, thresholds ...Threshold) error {
+	if _, ok := s.backend.(AddressHistoryProvider); !ok {
+		return ErrAddressHistoryUnsupported
+	}
+	if err := validateThresholds(thresholds); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addresses[address] = &addressSubscription{
+		thresholds: thresholds,
+		seen:       make(map[string]bool),
+	}
+	return nil
+}
+
+// Forget stops watching the given transaction or address.
+func (s *Watcher) Forget(txidOrAddress string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.txs, txidOrAddress)
+	delete(s.addresses, txidOrAddress)
+}
+
+// Check polls the backend once for every watched address and transaction and
+// fires the callbacks of the thresholds reached. It returns the first backend
+// error encountered.
+func (s *Watcher) Check(ctx context.Context) error {
+	var firstErr error
+
+	s.mu.Lock()
+	addresses := make(map[string]*addressSubscription, len(s.addresses))
+	for address, sub := range s.addresses {
+		addresses[address] = sub
+	}
+	s.mu.Unlock()
+
+	for address, sub := range addresses {
+		txids, err := s.backend.(AddressHistoryProvider).AddressTxIDs(ctx, address)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		s.mu.Lock()
+		for _, txid := range txids {
+			if !sub.seen[txid] {
+				sub.seen[txid] = true
+				s.addTxSubscription(txid, sub.thresholds)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	txids := make([]string, 0, len(s.txs))
+	for txid := range s.txs {
+		txids = append(txids, txid)
+	}
+	s.mu.Unlock()
+	sort.Strings(txids)
+
+	for _, txid := range txids {
+		status, err := s.backend.TxStatus(ctx, txid)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, fire := range s.updateTxSubscription(txid, status) {
+			fire()
+		}
+	}
+	return firstErr
+}
+
+// Run calls Check every poll interval until the context is cancelled.
+func (s *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		_ = s.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// addTxSubscription merges thresholds into the subscription of a transaction.
+// The caller must hold the lock.
+func (s *Watcher) addTxSubscription(txid string, thresholds []Threshold) {
+	sub, ok := s.txs[txid]
+	if !ok {
+		sub = &txSubscription{fired: make(map[int]bool)}
+		s.txs[txid] = sub
+	}
+	sub.thresholds = append(sub.thresholds, thresholds...)
+}
+
+// updateTxSubscription records the latest status of a transaction, re-arms
+// thresholds on reorg and returns the callbacks to fire.
+func (s *Watcher) updateTxSubscription(txid string, status *TxStatus) []func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.txs[txid]
+	if !ok {
+		return nil
+	}
+
+	event, changed := transition(&sub.last, status)
+	if status.Confirmations < sub.last.Confirmations || event == TxEventReorged {
+		for i, threshold := range sub.thresholds {
+			if threshold.Depth > status.Confirmations {
+				delete(sub.fired, i)
+			}
+		}
+	}
+	sub.last = *status
+
+	var callbacks []func()
+	if changed && event.Alarming() && s.config.OnAlarm != nil {
+		alarm, onAlarm := TxUpdate{TxID: txid, Event: event, Status: *status}, s.config.OnAlarm
+		callbacks = append(callbacks, func() { onAlarm(alarm) })
+	}
+	if event == TxEventConflicted {
+		delete(s.txs, txid)
+		return callbacks
+	}
+
+	update := TxUpdate{TxID: txid, Event: TxEventConfirmation, Status: *status}
+	done := true
+	for i, threshold := range sub.thresholds {
+		if sub.fired[i] {
+			continue
+		}
+		if status.Confirmations >= threshold.Depth {
+			sub.fired[i] = true
+			callback := threshold.Callback
+			callbacks = append(callbacks, func() { callback(update) })
+			continue
+		}
+		done = false
+	}
+	if done {
+		delete(s.txs, txid)
+	}
+	return callbacks
+}
+
+// validateThresholds checks that every threshold has a callback and a positive depth.
+func validateThresholds(thresholds []Threshold) error {
+	if len(thresholds) == 0 {
+		return ErrInvalidThreshold
+	}
+	for _, threshold := range thresholds {
+		if threshold.Depth <= 0 || threshold.Callback == nil {
+			return ErrInvalidThreshold
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package p2pkh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nextUpdate waits for the next update on the channel.
+func nextUpdate(t *testing.T, updates <-chan TxUpdate) (TxUpdate, bool) {
+	t.Helper()
+	select {
+	case update, ok := <-updates:
+		return update, ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for update")
+		return TxUpdate{}, false
+	}
+}
+
+func newTestWatcher(backend TxStatusProvider) *Watcher {
+	return NewWatcher(backend, &WatcherConfig{
+		PollInterval:        time.Millisecond,
+		TargetConfirmations: 2,
+	})
+}
+
+func Test_TxEvent_String(t *testing.T) {
+	assert.Equal(t, "mempool", TxEventMempool.String())
+	assert.Equal(t, "confirmation", TxEventConfirmation.String())
+	assert.Equal(t, "conflicted", TxEventConflicted.String())
+	assert.Equal(t, "error", TxEventError.String())
+	assert.Equal(t, "evicted", TxEventEvicted.String())
+	assert.Equal(t, "reorged", TxEventReorged.String())
+	assert.Equal(t, "unknown", TxEvent(0).String())
+}
+
+func Test_Watcher_TrackTx(t *testing.T) {
+	backend := newFakeBackend()
+	backend.setStatus("tx1", &TxStatus{InMempool: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := newTestWatcher(backend).TrackTx(ctx, "tx1")
+
+	update, ok := nextUpdate(t, updates)
+	assert.True(t, ok)
+	assert.Equal(t, TxEventMempool, update.Event)
+	assert.Equal(t, "tx1", update.TxID)
+
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1, BlockHeight: 100})
+	update, ok = nextUpdate(t, updates)
+	assert.True(t, ok)
+	assert.Equal(t, TxEventConfirmation, update.Event)
+	assert.Equal(t, 1, update.Status.Confirmations)
+
+	backend.setStatus("tx1", &TxStatus{Confirmations: 2, BlockHeight: 100})
+	update, ok = nextUpdate(t, updates)
+	assert.True(t, ok)
+	assert.Equal(t, TxEventConfirmation, update.Event)
+	assert.Equal(t, 2, update.Status.Confirmations)
+
+	// The target depth is reached: the stream is closed.
+	_, ok = nextUpdate(t, updates)
+	assert.False(t, ok)
+}
+
+func Test_Watcher_TrackTx_Conflicted(t *testing.T) {
+	backend := newFakeBackend()
+	backend.setStatus("tx1", &TxStatus{Conflicted: true})
+
+	updates := newTestWatcher(backend).TrackTx(context.Background(), "tx1")
+
+	update, ok := nextUpdate(t, updates)
+	assert.True(t, ok)
+	assert.Equal(t, TxEventConflicted, update.Event)
+
+	_, ok = nextUpdate(t, updates)
+	assert.False(t, ok)
+}
+
+func Test_TxEvent_Alarming(t *testing.T) {
+	assert.True(t, TxEventEvicted.Alarming())
+	assert.True(t, TxEventConflicted.Alarming())
+	assert.True(t, TxEventReorged.Alarming())
+	assert.False(t, TxEventMempool.Alarming())
+	assert.False(t, TxEventConfirmation.Alarming())
+	assert.False(t, TxEventError.Alarming())
+}
+
+func Test_Transition(t *testing.T) {
+	tests := []struct {
+		name    string
+		last    TxStatus
+		status  TxStatus
+		event   TxEvent
+		changed bool
+	}{
+		{"Unchanged unknown", TxStatus{}, TxStatus{}, 0, false},
+		{"Seen in mempool", TxStatus{}, TxStatus{InMempool: true}, TxEventMempool, true},
+		{"Still in mempool", TxStatus{InMempool: true}, TxStatus{InMempool: true}, 0, false},
+		{"Evicted", TxStatus{InMempool: true}, TxStatus{}, TxEventEvicted, true},
+		{"Conflicted", TxStatus{InMempool: true}, TxStatus{Conflicted: true}, TxEventConflicted, true},
+		{"Still conflicted", TxStatus{Conflicted: true}, TxStatus{Conflicted: true}, TxEventConflicted, false},
+		{"Confirmed", TxStatus{InMempool: true}, TxStatus{Confirmations: 1, BlockHash: "a"}, TxEventConfirmation, true},
+		{"Deeper", TxStatus{Confirmations: 1, BlockHash: "a"}, TxStatus{Confirmations: 2, BlockHash: "a"}, TxEventConfirmation, true},
+		{"Back to mempool", TxStatus{Confirmations: 1, BlockHash: "a"}, TxStatus{InMempool: true}, TxEventReorged, true},
+		{"Disappeared", TxStatus{Confirmations: 1, BlockHash: "a"}, TxStatus{}, TxEventReorged, true},
+		{"Other block", TxStatus{Confirmations: 2, BlockHash: "a"}, TxStatus{Confirmations: 1, BlockHash: "b"}, TxEventReorged, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, changed := transition(&test.last, &test.status)
+			assert.Equal(t, test.changed, changed)
+			if test.changed {
+				assert.Equal(t, test.event, event)
+			}
+		})
+	}
+}
+
+func Test_Watcher_TrackTx_EvictedAndReorged(t *testing.T) {
+	backend := newFakeBackend()
+	backend.setStatus("tx1", &TxStatus{InMempool: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := newTestWatcher(backend).TrackTx(ctx, "tx1")
+
+	update, _ := nextUpdate(t, updates)
+	assert.Equal(t, TxEventMempool, update.Event)
+
+	backend.setStatus("tx1", &TxStatus{})
+	update, _ = nextUpdate(t, updates)
+	assert.Equal(t, TxEventEvicted, update.Event)
+
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1, BlockHash: "a"})
+	update, _ = nextUpdate(t, updates)
+	assert.Equal(t, TxEventConfirmation, update.Event)
+
+	backend.setStatus("tx1", &TxStatus{InMempool: true})
+	update, _ = nextUpdate(t, updates)
+	assert.Equal(t, TxEventReorged, update.Event)
+}
+
+func Test_Watcher_TrackTx_Error(t *testing.T) {
+	backend := newFakeBackend()
+	backend.err = errors.New("backend down")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := newTestWatcher(backend).TrackTx(ctx, "tx1")
+
+	update, ok := nextUpdate(t, updates)
+	assert.True(t, ok)
+	assert.Equal(t, TxEventError, update.Event)
+	assert.EqualError(t, update.Err, "backend down")
+
+	cancel()
+	for range updates {
+	}
+}
+
+// statusOnlyBackend only implements TxStatusProvider.
+type statusOnlyBackend struct{}
+
+func (statusOnlyBackend) TxStatus(context.Context, string) (*TxStatus, error) {
+	return &TxStatus{}, nil
+}
+
+func Test_Watcher_NotifyTx(t *testing.T) {
+	backend := newFakeBackend()
+	w := newTestWatcher(backend)
+
+	var small, large []int
+	err := w.NotifyTx("tx1",
+		Threshold{Depth: 1, Callback: func(u TxUpdate) { small = append(small, u.Status.Confirmations) }},
+		Threshold{Depth: 6, Callback: func(u TxUpdate) { large = append(large, u.Status.Confirmations) }},
+	)
+	assert.NoError(t, err)
+
+	backend.setStatus("tx1", &TxStatus{InMempool: true})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Empty(t, small)
+
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1, BlockHash: "a"})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []int{1}, small)
+
+	// Already fired thresholds are not fired twice.
+	backend.setStatus("tx1", &TxStatus{Confirmations: 2, BlockHash: "a"})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []int{1}, small)
+	assert.Empty(t, large)
+
+	backend.setStatus("tx1", &TxStatus{Confirmations: 6, BlockHash: "a"})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []int{6}, large)
+
+	// Every threshold fired: the transaction is no longer watched.
+	backend.setStatus("tx1", &TxStatus{Confirmations: 7, BlockHash: "a"})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []int{1}, small)
+	assert.Equal(t, []int{6}, large)
+}
+
+func Test_Watcher_NotifyTx_Reorg(t *testing.T) {
+	backend := newFakeBackend()
+	w := newTestWatcher(backend)
+
+	var fired []string
+	err := w.NotifyTx("tx1",
+		Threshold{Depth: 1, Callback: func(u TxUpdate) { fired = append(fired, u.Status.BlockHash) }},
+		Threshold{Depth: 3, Callback: func(TxUpdate) {}},
+	)
+	assert.NoError(t, err)
+
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1, BlockHash: "a"})
+	assert.NoError(t, w.Check(context.Background()))
+
+	// The block was reorged out: the threshold is re-armed.
+	backend.setStatus("tx1", &TxStatus{InMempool: true})
+	assert.NoError(t, w.Check(context.Background()))
+
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1, BlockHash: "b"})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []string{"a", "b"}, fired)
+}
+
+func Test_Watcher_NotifyAddress(t *testing.T) {
+	backend := newFakeBackend()
+	w := newTestWatcher(backend)
+
+	var fired []string
+	err := w.NotifyAddress("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr",
+		Threshold{Depth: 1, Callback: func(u TxUpdate) { fired = append(fired, u.TxID) }},
+	)
+	assert.NoError(t, err)
+
+	backend.addHistory("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", "tx1", "tx2")
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1})
+	backend.setStatus("tx2", &TxStatus{InMempool: true})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []string{"tx1"}, fired)
+
+	backend.setStatus("tx2", &TxStatus{Confirmations: 1})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []string{"tx1", "tx2"}, fired)
+
+	w.Forget("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr")
+	backend.addHistory("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", "tx3")
+	backend.setStatus("tx3", &TxStatus{Confirmations: 1})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, []string{"tx1", "tx2"}, fired)
+}
+
+func Test_Watcher_NotifyErrors(t *testing.T) {
+	w := newTestWatcher(newFakeBackend())
+	assert.ErrorIs(t, w.NotifyTx("tx1"), ErrInvalidThreshold)
+	assert.ErrorIs(t, w.NotifyTx("tx1", Threshold{Depth: 0, Callback: func(TxUpdate) {}}), ErrInvalidThreshold)
+	assert.ErrorIs(t, w.NotifyTx("tx1", Threshold{Depth: 1}), ErrInvalidThreshold)
+
+	w = newTestWatcher(statusOnlyBackend{})
+	err := w.NotifyAddress("1QHTz6wMURLy8DT6aeGAVbF2UvtuWZKozr", Threshold{Depth: 1, Callback: func(TxUpdate) {}})
+	assert.ErrorIs(t, err, ErrAddressHistoryUnsupported)
+}
+
+func Test_Watcher_OnAlarm(t *testing.T) {
+	backend := newFakeBackend()
+	var alarms []TxEvent
+	w := NewWatcher(backend, &WatcherConfig{
+		OnAlarm: func(u TxUpdate) { alarms = append(alarms, u.Event) },
+	})
+	assert.NoError(t, w.NotifyTx("tx1", Threshold{Depth: 6, Callback: func(TxUpdate) {}}))
+
+	backend.setStatus("tx1", &TxStatus{InMempool: true})
+	assert.NoError(t, w.Check(context.Background()))
+	backend.setStatus("tx1", &TxStatus{Confirmations: 1, BlockHash: "a"})
+	assert.NoError(t, w.Check(context.Background()))
+	backend.setStatus("tx1", &TxStatus{})
+	assert.NoError(t, w.Check(context.Background()))
+	backend.setStatus("tx1", &TxStatus{Conflicted: true})
+	assert.NoError(t, w.Check(context.Background()))
+
+	assert.Equal(t, []TxEvent{TxEventReorged, TxEventConflicted}, alarms)
+
+	// A conflicted transaction is no longer watched.
+	backend.setStatus("tx1", &TxStatus{InMempool: true})
+	assert.NoError(t, w.Check(context.Background()))
+	assert.Len(t, alarms, 2)
+}
@@ -0,0 +1,92 @@
+package p2pkh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	ErrWIFInvalid     Error = "invalid WIF private key"
+	ErrWIFCompression Error = "WIF compression flag does not match the address"
+	ErrWIFAddress     Error = "WIF key does not control the address"
+	ErrWIFNetwork     Error = "WIF key does not belong to the network"
+)
+
+// ParseWIF decodes a WIF private key of the network, ignoring surrounding
+// whitespace. Malformed keys are reported as ErrWIFInvalid, keys of another
+// network as ErrWIFNetwork.
+func ParseWIF(wif string, network Network) (*btcutil.WIF, error) {
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+	key, err := btcutil.DecodeWIF(strings.TrimSpace(wif))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWIFInvalid, err)
+	}
+	if !key.IsForNet(params) {
+		return nil, ErrWIFNetwork
+	}
+	return key, nil
+}
+
+// CheckWIFAddress checks that a WIF key, imported by another software, yields
+// the given address of the network. A key whose compression flag does not
+// match the one used to derive the address is reported as ErrWIFCompression:
+// such a key is valid but appears empty once imported. Segwit addresses
+// require compressed keys.
+func CheckWIFAddress(wif, address string, network Network) error {
+	key, err := ParseWIF(wif, network)
+	if err != nil {
+		return err
+	}
+	params, err := selectNetworkParams(network)
+	if err != nil {
+		return err
+	}
+	addr, err := decodeAddress(address, params)
+	if err != nil {
+		return err
+	}
+
+	publicKey := key.PrivKey.PubKey()
+	for _, compressed := range []bool{key.CompressPubKey, !key.CompressPubKey} {
+		serialized := publicKey.SerializeUncompressed()
+		if compressed {
+			serialized = publicKey.SerializeCompressed()
+		}
+		hash := btcutil.Hash160(serialized)
+		candidates := []btcutil.Address{}
+		if p2pkh, err := btcutil.NewAddressPubKeyHash(hash, params); err == nil {
+			candidates = append(candidates, p2pkh)
+		}
+		if compressed {
+			for _, addrType := range AddressSchemes() {
+				if addrType == AddressTypeP2PKH {
+					continue
+				}
+				if candidate, err := publicKeyAddress(publicKey, addrType, params); err == nil {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+		for _, candidate := range candidates {
+			if candidate.EncodeAddress() != addr.EncodeAddress() {
+				continue
+			}
+			if compressed != key.CompressPubKey {
+				return ErrWIFCompression
+			}
+			return nil
+		}
+	}
+	return ErrWIFAddress
+}
+
+// ValidateWIF checks that a WIF key, e.g. exported by PrivateKeyWIF, imports
+// as the wallet address in other software.
+func (s *Wallet) ValidateWIF(wif string) error {
+	return CheckWIFAddress(wif, s.AddressInfo().String(), s.network)
+}
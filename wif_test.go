@@ -0,0 +1,75 @@
+package p2pkh
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckWIFAddress(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	compressed, err := wallet.PrivateKeyWIF(true)
+	assert.NoError(t, err)
+	uncompressed, err := wallet.PrivateKeyWIF(false)
+	assert.NoError(t, err)
+
+	assert.NoError(t, wallet.ValidateWIF(compressed))
+	assert.ErrorIs(t, wallet.ValidateWIF(uncompressed), ErrWIFCompression)
+
+	// The uncompressed key matches the address of the uncompressed public key.
+	legacy, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wallet.PublicKey().SerializeUncompressed()), &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	assert.NoError(t, CheckWIFAddress(uncompressed, legacy.EncodeAddress(), NetworkMainnet))
+	assert.ErrorIs(t, CheckWIFAddress(compressed, legacy.EncodeAddress(), NetworkMainnet), ErrWIFCompression)
+
+	// Segwit addresses require compressed keys.
+	segwit, err := publicKeyAddress(wallet.PublicKey(), AddressTypeP2WPKH, &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	assert.NoError(t, CheckWIFAddress(compressed, segwit.EncodeAddress(), NetworkMainnet))
+	assert.ErrorIs(t, CheckWIFAddress(uncompressed, segwit.EncodeAddress(), NetworkMainnet), ErrWIFCompression)
+
+	other, err := wallet.Derive(1)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, other.ValidateWIF(compressed), ErrWIFAddress)
+
+	testnet := createKnownWallet(t, NetworkTestnet)
+	assert.ErrorIs(t, testnet.ValidateWIF(compressed), ErrWIFNetwork)
+}
+
+func Test_ParseWIF(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	wif, err := wallet.PrivateKeyWIF(true)
+	assert.NoError(t, err)
+
+	key, err := ParseWIF(" "+wif+"\n", NetworkMainnet)
+	assert.NoError(t, err)
+	assert.True(t, key.CompressPubKey)
+	assert.Equal(t, wif, key.String())
+
+	_, err = ParseWIF(wif, NetworkTestnet)
+	assert.ErrorIs(t, err, ErrWIFNetwork)
+	_, err = ParseWIF(wif[:len(wif)-1]+"x", NetworkMainnet)
+	assert.ErrorIs(t, err, ErrWIFInvalid)
+	_, err = ParseWIF("", NetworkMainnet)
+	assert.ErrorIs(t, err, ErrWIFInvalid)
+}
+
+func FuzzParseWIF(f *testing.F) {
+	f.Add("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	f.Add("5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ")
+	f.Add("cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA")
+	f.Add("")
+	f.Fuzz(func(t *testing.T, wif string) {
+		key, err := ParseWIF(wif, NetworkMainnet)
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrWIFInvalid) || errors.Is(err, ErrWIFNetwork), err)
+			return
+		}
+		again, err := ParseWIF(key.String(), NetworkMainnet)
+		assert.NoError(t, err)
+		assert.Equal(t, key.String(), again.String())
+	})
+}
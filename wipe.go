@@ -0,0 +1,45 @@
+package p2pkh
+
+import (
+	"runtime"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// Wipe zeroes the private material of the wallet in memory: its extended
+// key, its root key and the child keys it cached, and drops its mnemonic and
+// passphrase. Go strings being immutable, the mnemonic itself cannot be
+// zeroed; create the wallet with Config.ForgetMnemonic to never retain it.
+//
+// The root key is shared by every wallet derived from the same root, e.g.
+// with Derive or At, which Wipe therefore disables too. A wiped wallet must
+// not be used any more, and Wipe must not run concurrently with other
+// methods of the wallets sharing its keys.
+func (s *Wallet) Wipe() {
+	s.mnemonic = ""
+	s.passphrase = ""
+	if s.extendedKey.IsPrivate() {
+		s.extendedKey.Zero()
+	}
+	if s.root != nil && s.root.IsPrivate() {
+		s.root.Zero()
+	}
+	if s.children != nil {
+		s.children.wipe()
+	}
+}
+
+// zeroOnCollect zeroes a private extended key once the garbage collector
+// reclaims it, which happens when no wallet refers to it any more.
+func zeroOnCollect(key *hdkeychain.ExtendedKey) {
+	if key.IsPrivate() {
+		runtime.SetFinalizer(key, (*hdkeychain.ExtendedKey).Zero)
+	}
+}
+
+// zero overwrites a secret byte slice.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,71 @@
+package p2pkh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wallet_Wipe(t *testing.T) {
+	wallet := createKnownWallet(t, NetworkMainnet)
+	child, err := wallet.Derive(1)
+	assert.NoError(t, err)
+	sibling, err := wallet.At(0, 2)
+	assert.NoError(t, err)
+	assert.False(t, child.IsWatchOnly())
+
+	wallet.Wipe()
+	assert.Empty(t, wallet.Mnemonic())
+	assert.True(t, wallet.IsWatchOnly())
+	assert.Equal(t, 0, wallet.children.len())
+	_, err = wallet.PrivateKey()
+	assert.ErrorIs(t, err, ErrWatchOnly)
+	// The child key was cached by the wallet, the root is shared.
+	assert.True(t, child.IsWatchOnly())
+	assert.False(t, sibling.root.IsPrivate())
+	_, err = sibling.At(0, 3)
+	assert.Error(t, err)
+
+	// Wiping a watch-only wallet is harmless.
+	watch, err := NewFromExtendedPublicKey(sibling.extendedKey.String(), NetworkMainnet)
+	assert.NoError(t, err)
+	address := watch.AddressHex()
+	watch.Wipe()
+	assert.Equal(t, address, watch.AddressHex())
+}
+
+func Test_Config_ForgetMnemonic(t *testing.T) {
+	expected := createKnownWallet(t, NetworkMainnet)
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, ForgetMnemonic: true})
+	assert.NoError(t, err)
+	assert.Empty(t, wallet.Mnemonic())
+	assert.Equal(t, expected.AddressHex(), wallet.AddressHex())
+	child, err := wallet.At(1, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, child.Mnemonic())
+	_, err = child.PrivateKey()
+	assert.NoError(t, err)
+}
+
+func Test_Config_WipeOnCollect(t *testing.T) {
+	expected := createKnownWallet(t, NetworkMainnet)
+	wallet, err := New(&Config{Mnemonic: testMnemonic, Network: NetworkMainnet, WipeOnCollect: true})
+	assert.NoError(t, err)
+	assert.Equal(t, expected.AddressHex(), wallet.AddressHex())
+
+	child, err := wallet.Derive(4)
+	assert.NoError(t, err)
+	assert.True(t, child.wipeOnCollect)
+	change, err := child.DeriveChange(0)
+	assert.NoError(t, err)
+	assert.True(t, change.wipeOnCollect)
+	// Deriving a cached key again does not set its finalizer twice.
+	_, err = wallet.Derive(4)
+	assert.NoError(t, err)
+}
+
+func Test_zero(t *testing.T) {
+	secret := []byte{1, 2, 3}
+	zero(secret)
+	assert.Equal(t, []byte{0, 0, 0}, secret)
+}